@@ -2,17 +2,23 @@ package api
 
 import (
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/lbryio/lbrytv-player/pkg/paid"
 	"github.com/lbryio/lbrytv/app/auth"
 	"github.com/lbryio/lbrytv/app/proxy"
 	"github.com/lbryio/lbrytv/app/publish"
+	"github.com/lbryio/lbrytv/app/query"
 	"github.com/lbryio/lbrytv/app/query/cache"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/app/wallet/deadletter"
+	"github.com/lbryio/lbrytv/app/wallet/export"
+	"github.com/lbryio/lbrytv/app/wallet/sessions"
+	"github.com/lbryio/lbrytv/app/walletevents"
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/canary"
 	"github.com/lbryio/lbrytv/internal/ip"
 	"github.com/lbryio/lbrytv/internal/metrics"
 	"github.com/lbryio/lbrytv/internal/middleware"
@@ -20,6 +26,7 @@ import (
 	"github.com/lbryio/lbrytv/internal/status"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/tus/tusd/pkg/filelocker"
@@ -34,10 +41,11 @@ var logger = monitor.NewModuleLogger("api")
 // emptyHandler can be used when you just need to let middlewares do their job and no actual response is needed.
 func emptyHandler(_ http.ResponseWriter, _ *http.Request) {}
 
-// InstallRoutes sets up global API handlers
-func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router) {
+// InstallRoutes sets up global API handlers. It returns the SDK response cache shared by the handlers.
+func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router) *cache.Cache {
 	uploadPath := config.GetPublishSourceDir()
 	authProvider := auth.NewIAPIProvider(sdkRouter, config.GetInternalAPIHost())
+	queryCache := newQueryCache()
 
 	upHandler := &publish.Handler{UploadPath: uploadPath}
 	r.Use(methodTimer)
@@ -48,23 +56,53 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router) {
 	r.HandleFunc("", emptyHandler)
 
 	v1Router := r.PathPrefix("/api/v1").Subrouter()
-	v1Router.Use(defaultMiddlewares(sdkRouter, authProvider))
+	v1Router.Use(defaultMiddlewares(sdkRouter, authProvider, queryCache))
 
 	v1Router.HandleFunc("/proxy", upHandler.Handle).MatcherFunc(publish.CanHandle)
 	v1Router.HandleFunc("/proxy", proxy.Handle).Methods(http.MethodPost)
 	v1Router.HandleFunc("/proxy", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/proxy/envelope", proxy.HandleEnvelope).Methods(http.MethodPost)
+	v1Router.HandleFunc("/proxy/envelope", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/proxy/batch", proxy.HandleBatch).Methods(http.MethodPost)
+	v1Router.HandleFunc("/proxy/batch", emptyHandler).Methods(http.MethodOptions)
 
 	v1Router.HandleFunc("/metric/ui", metrics.TrackUIMetric).Methods(http.MethodPost)
 	v1Router.HandleFunc("/metric/ui", emptyHandler).Methods(http.MethodOptions)
 
 	v1Router.HandleFunc("/status", status.GetStatus).Methods(http.MethodGet)
+	v1Router.HandleFunc("/history", audit.HandleHistory).Methods(http.MethodGet)
+	v1Router.HandleFunc("/history", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/rate-limits", proxy.HandleRateLimits).Methods(http.MethodGet)
+	v1Router.HandleFunc("/rate-limits", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/methods", proxy.HandleMethods).Methods(http.MethodGet)
+	v1Router.HandleFunc("/methods", emptyHandler).Methods(http.MethodOptions)
+
+	v1Router.HandleFunc("/admin/dead-letters", deadletter.HandleList).Methods(http.MethodGet)
+	v1Router.HandleFunc("/admin/dead-letters", deadletter.HandlePurge).Methods(http.MethodDelete)
+	v1Router.HandleFunc("/admin/dead-letters/{id:[0-9]+}", deadletter.HandlePurge).Methods(http.MethodDelete)
+
+	v1Router.HandleFunc("/admin/sdk/status", status.HandleSDKStatus).Methods(http.MethodGet)
+	v1Router.HandleFunc("/admin/sdk", status.HandleSDKServers).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	v1Router.HandleFunc("/admin/breakers", status.HandleCircuitBreakers).Methods(http.MethodGet)
+
+	walletEvents := walletevents.NewHub(walletevents.SDKFetcher, config.GetWalletEventsPollInterval())
+	v1Router.HandleFunc("/wallet/events", walletEvents.Handle).Methods(http.MethodGet)
+	v1Router.HandleFunc("/wallet/events", emptyHandler).Methods(http.MethodOptions)
+	walletExporter := export.NewExporter(config.GetWalletExportLimit(), config.GetWalletExportLimitPeriod())
+	v1Router.HandleFunc("/wallet/export", walletExporter.Handle).Methods(http.MethodPost)
+	v1Router.HandleFunc("/wallet/export", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/sessions", sessions.HandleList).Methods(http.MethodGet)
+	v1Router.HandleFunc("/sessions", sessions.HandleIssue).Methods(http.MethodPost)
+	v1Router.HandleFunc("/sessions", emptyHandler).Methods(http.MethodOptions)
+	v1Router.HandleFunc("/sessions/{id:[0-9]+}", sessions.HandleRevoke).Methods(http.MethodDelete)
+	v1Router.HandleFunc("/sessions/{id:[0-9]+}", emptyHandler).Methods(http.MethodOptions)
 	v1Router.HandleFunc("/paid/pubkey", paid.HandlePublicKeyRequest).Methods(http.MethodGet)
 
 	internalRouter := r.PathPrefix("/internal").Subrouter()
-	internalRouter.Handle("/metrics", promhttp.Handler())
+	internalRouter.Handle("/metrics", metricsHandler())
 
 	v2Router := r.PathPrefix("/api/v2").Subrouter()
-	v2Router.Use(defaultMiddlewares(sdkRouter, authProvider))
+	v2Router.Use(defaultMiddlewares(sdkRouter, authProvider, queryCache))
 	v2Router.HandleFunc("/status", status.GetStatusV2).Methods(http.MethodGet)
 	v2Router.HandleFunc("/status", emptyHandler).Methods(http.MethodOptions)
 
@@ -83,6 +121,9 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router) {
 	if err != nil {
 		logger.Log().WithError(err).Fatal(err)
 	}
+	if interval := config.GetPublishUploadSweepInterval(); interval > 0 {
+		go tusHandler.SweepAbandoned(interval, config.GetPublishUploadAbandonedAfter())
+	}
 
 	tusRouter := v2Router.PathPrefix("/publish").Subrouter()
 	tusRouter.Use(tusHandler.Middleware)
@@ -92,20 +133,73 @@ func InstallRoutes(r *mux.Router, sdkRouter *sdkrouter.Router) {
 	tusRouter.HandleFunc("/{id}", tusHandler.DelFile).Methods(http.MethodDelete)
 	tusRouter.HandleFunc("/{id}/notify", tusHandler.Notify).Methods(http.MethodPost)
 	tusRouter.PathPrefix("/").HandlerFunc(emptyHandler).Methods(http.MethodOptions)
+
+	return queryCache
 }
 
-func defaultMiddlewares(rt *sdkrouter.Router, authProvider auth.Provider) mux.MiddlewareFunc {
-	queryCache, err := cache.New(cache.DefaultConfig())
+// newQueryCache creates the SDK response cache, warming it from the snapshot if snapshots are on.
+func newQueryCache() *cache.Cache {
+	// TTLs are read along with the list of cacheable methods so both follow config reloads.
+	cacheConfig := cache.DefaultConfig().MethodTTLs(config.GetCacheableMethods)
+	if config.IsAdaptiveCacheTTLEnabled() {
+		ttlFunc := cache.ClaimAgeTTL(config.GetAdaptiveCacheTTLMin(), config.GetAdaptiveCacheTTLMax())
+		cacheConfig.AdaptiveTTL(query.MethodResolve, ttlFunc).AdaptiveTTL(query.MethodClaimSearch, ttlFunc)
+	}
+	cacheConfig.NotFoundTTL(query.MethodResolve, config.GetResolveNotFoundTTL())
+	if priorities := config.GetCacheMethodPriorities(); len(priorities) > 0 {
+		cacheConfig.MethodPriorities(priorities)
+	}
+	if s := config.GetCacheShadow(); s.Shadow != "" {
+		cacheConfig.Shadow(s.Primary, s.Shadow, s.QueueSize, s.Workers)
+	}
+	path := config.GetCacheSnapshotPath()
+	if path != "" {
+		cacheConfig.Snapshots()
+	}
+	queryCache, err := cache.New(cacheConfig)
 	if err != nil {
 		panic(err)
 	}
+	if path != "" {
+		n, err := queryCache.LoadSnapshot(path)
+		if err != nil {
+			logger.Log().Errorf("cannot load cache snapshot from %v: %v", path, err)
+		} else {
+			logger.Log().Infof("loaded %v cache entries from %v", n, path)
+		}
+	}
+	return queryCache
+}
+
+// metricsHandler serves Prometheus metrics, in OpenMetrics format to scrapers which ask for it
+// when exemplars are on, since other formats don't carry them.
+func metricsHandler() http.Handler {
+	if !config.GetMetricsExemplars().Enabled {
+		return promhttp.Handler()
+	}
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
+
+func defaultMiddlewares(rt *sdkrouter.Router, authProvider auth.Provider, queryCache *cache.Cache) mux.MiddlewareFunc {
 	defaultHeaders := []string{
-		wallet.TokenHeader, "X-Requested-With", "Content-Type", "Accept",
+		wallet.TokenHeader, "X-Requested-With", "Content-Type", "Accept", proxy.ResponseFormatHeader,
+		proxy.AcceptVersionHeader, proxy.CacheBypassHeader, proxy.RequestIDHeader, proxy.TraceParentHeader,
+	}
+	// Browsers only let clients read response headers which are not CORS-safelisted when they're exposed
+	exposedHeaders := []string{
+		"Retry-After", proxy.DeprecationHeader, proxy.SunsetHeader, proxy.ContentVersionHeader,
+		proxy.RateLimitLimitHeader, proxy.RateLimitRemainingHeader, proxy.RequestCostHeader, proxy.ServerLoadHeader,
+		proxy.SDKWarningsHeader, proxy.DegradedResponseHeader, proxy.RequestIDHeader, proxy.SDKNodeHeader,
+		proxy.CacheStatusHeader, proxy.CacheTTLHeader, proxy.CacheKeyHeader, canary.BuildHeader, canary.VariantHeader,
 	}
 	c := cors.New(cors.Options{
 		AllowedOrigins:   config.GetCORSDomains(),
 		AllowCredentials: true,
 		AllowedHeaders:   append(defaultHeaders, publish.TusHeaders...),
+		ExposedHeaders:   exposedHeaders,
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodHead, http.MethodDelete},
 		MaxAge:           preflightDuration,
 	})
@@ -116,7 +210,9 @@ func defaultMiddlewares(rt *sdkrouter.Router, authProvider auth.Provider) mux.Mi
 		c.Handler,
 		ip.Middleware,
 		sdkrouter.Middleware(rt),
+		auth.ServiceMiddleware,
 		auth.Middleware(authProvider),
+		canary.Middleware,
 		cache.Middleware(queryCache),
 	)
 }
@@ -127,10 +223,24 @@ func methodTimer(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 
-		path := r.URL.Path
-		if r.URL.RawQuery != "" && !strings.HasPrefix(path, "/api/v1/metric") {
-			path += "?" + r.URL.RawQuery
-		}
-		metrics.LbrytvCallDurations.WithLabelValues(path).Observe(time.Since(start).Seconds())
+		metrics.LbrytvCallDurations.WithLabelValues(timerLabel(r)).Observe(time.Since(start).Seconds())
 	})
 }
+
+// timerLabel names the route which has served the request by its path template, so requests for different
+// resources (like /api/v1/sessions/{id}) share a label. Query is left out as it's up to the client, except for
+// proxy calls tagged with a method the proxy knows (/api/v1/proxy?m=resolve).
+func timerLabel(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	if m := r.URL.Query().Get("m"); path == "/api/v1/proxy" && query.IsSupportedMethod(m) {
+		return path + "?m=" + m
+	}
+	return path
+}
@@ -121,6 +121,38 @@ func TestCORS(t *testing.T) {
 	}
 }
 
+func TestCORSClientHeaders(t *testing.T) {
+	config.Override("CORSDomains", []string{"https://odysee.com"})
+	defer config.RestoreOverridden()
+	r := mux.NewRouter()
+	InstallRoutes(r, sdkrouter.New(config.GetLbrynetServers()))
+
+	requestHeaders := "Accept-Version, Content-Type, X-Bypass-Cache, X-Lbry-Auth-Token, X-Request-Id"
+	req, err := http.NewRequest(http.MethodOptions, "/api/v1/proxy", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://odysee.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", requestHeaders)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://odysee.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, requestHeaders, rr.Header().Get("Access-Control-Allow-Headers"))
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/methods", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://odysee.com")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	exposed := strings.Split(rr.Header().Get("Access-Control-Expose-Headers"), ", ")
+	for _, h := range []string{
+		"Retry-After", "Deprecation", "Sunset", "X-Ratelimit-Limit", "X-Ratelimit-Remaining",
+		"X-Sdk-Warnings", "X-Cache", "X-Cache-Ttl-Remaining", "X-Request-Id", "Content-Version",
+	} {
+		assert.Contains(t, exposed, h)
+	}
+}
+
 func TestMiddlewareOrder(t *testing.T) {
 	handler := func(i int) mux.MiddlewareFunc {
 		return func(next http.Handler) http.Handler {
@@ -152,3 +184,37 @@ func TestMiddlewareOrder(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "12345", string(body))
 }
+
+func TestTimerLabel(t *testing.T) {
+	var label string
+	record := func(w http.ResponseWriter, r *http.Request) { label = timerLabel(r) }
+	r := mux.NewRouter()
+	r.HandleFunc("/", record)
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	for _, p := range []string{
+		"/proxy", "/metric/ui", "/history", "/wallet/events", "/sessions/{id:[0-9]+}", "/rate-limits", "/admin/dead-letters/{id:[0-9]+}",
+	} {
+		v1.HandleFunc(p, record)
+	}
+
+	cases := []struct {
+		url, label string
+	}{
+		{"/", "/"},
+		{"/api/v1/proxy", "/api/v1/proxy"},
+		{"/api/v1/proxy?m=resolve", "/api/v1/proxy?m=resolve"},
+		{"/api/v1/proxy?m=whatever&x=1", "/api/v1/proxy"},
+		{"/api/v1/metric/ui?name=player&value=1", "/api/v1/metric/ui"},
+		{"/api/v1/history?page=3&page_size=20", "/api/v1/history"},
+		{"/api/v1/wallet/events?auth_token=abc", "/api/v1/wallet/events"},
+		{"/api/v1/sessions/123", "/api/v1/sessions/{id:[0-9]+}"},
+		{"/api/v1/rate-limits?user=1", "/api/v1/rate-limits"},
+		{"/api/v1/admin/dead-letters/12", "/api/v1/admin/dead-letters/{id:[0-9]+}"},
+	}
+	for _, c := range cases {
+		label = ""
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, c.url, nil))
+		assert.Equal(t, c.label, label, c.url)
+	}
+	assert.Equal(t, "unmatched", timerLabel(httptest.NewRequest(http.MethodGet, "/nowhere", nil)))
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// AdminTokenHeader is the name of HTTP header which should contain the admin token for administrative requests.
+const AdminTokenHeader = "X-Admin-Token"
+
+// IsAdmin returns true if the request carries a valid admin token.
+// It always returns false when no admin token is configured.
+func IsAdmin(r *http.Request) bool {
+	adminToken := config.GetAdminToken()
+	if adminToken == "" {
+		return false
+	}
+	token := r.Header.Get(AdminTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAdmin(t *testing.T) {
+	r, err := http.NewRequest("GET", "/api/proxy", nil)
+	require.NoError(t, err)
+
+	config.Override("AdminToken", "")
+	defer config.RestoreOverridden()
+
+	assert.False(t, IsAdmin(r))
+	r.Header.Set(AdminTokenHeader, "")
+	assert.False(t, IsAdmin(r))
+
+	config.Override("AdminToken", "admin-secret")
+	assert.False(t, IsAdmin(r))
+	r.Header.Set(AdminTokenHeader, "wrong-secret")
+	assert.False(t, IsAdmin(r))
+	r.Header.Set(AdminTokenHeader, "admin-secret")
+	assert.True(t, IsAdmin(r))
+}
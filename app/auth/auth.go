@@ -36,6 +36,33 @@ func FromRequest(r *http.Request) (*models.User, error) {
 	return res.user, res.err
 }
 
+// States of the auth token of a request, see GetTokenState.
+const (
+	// TokenValid means the request is authenticated.
+	TokenValid = "valid"
+	// TokenAbsent means the request has no auth token, the client should sign in.
+	TokenAbsent = "absent"
+	// TokenExpired means the token used to work but doesn't anymore, the client can try refreshing it before asking to sign in.
+	TokenExpired = "expired"
+	// TokenInvalid means the token is unknown or can't be used, e.g. when the user hasn't verified their email.
+	TokenInvalid = "invalid"
+)
+
+// GetTokenState returns the state of the auth token for the result of FromRequest.
+// Errors other than those of missing or expired tokens, like internal-apis failing, count as invalid tokens.
+func GetTokenState(user *models.User, err error) string {
+	switch {
+	case err == nil && user != nil:
+		return TokenValid
+	case errors.Is(err, ErrNoAuthInfo):
+		return TokenAbsent
+	case errors.Is(err, wallet.ErrSessionExpired), errors.Is(err, wallet.ErrSessionRevoked):
+		return TokenExpired
+	default:
+		return TokenInvalid
+	}
+}
+
 // Provider tries to authenticate using the provided auth token
 type Provider func(token, metaRemoteIP string) (*models.User, error)
 
@@ -105,6 +105,17 @@ func TestMiddleware_Error(t *testing.T) {
 	assert.Equal(t, "something broke", string(body))
 }
 
+func TestGetTokenState(t *testing.T) {
+	user := &models.User{ID: 1}
+	assert.Equal(t, TokenValid, GetTokenState(user, nil))
+	assert.Equal(t, TokenAbsent, GetTokenState(nil, errors.Err(ErrNoAuthInfo)))
+	assert.Equal(t, TokenExpired, GetTokenState(nil, errors.Err(wallet.ErrSessionExpired)))
+	assert.Equal(t, TokenExpired, GetTokenState(nil, errors.Err(wallet.ErrSessionRevoked)))
+	assert.Equal(t, TokenInvalid, GetTokenState(nil, errors.Err("api error: could not authenticate user")))
+	// Users who haven't verified their email
+	assert.Equal(t, TokenInvalid, GetTokenState(nil, nil))
+}
+
 func TestFromRequestSuccess(t *testing.T) {
 	expected := result{nil, errors.Base("a test")}
 	ctx := context.WithValue(context.Background(), contextKey, expected)
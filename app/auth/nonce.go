@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// nonceSweepInterval is how often expired nonces are dropped.
+const nonceSweepInterval = time.Minute
+
+// nonceCache remembers nonces until they expire.
+type nonceCache struct {
+	mu        sync.Mutex
+	expiry    map[string]time.Time
+	nextSweep time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{expiry: map[string]time.Time{}}
+}
+
+// add records the nonce as seen until expires, returning false if it's already been seen and hasn't expired.
+func (c *nonceCache) add(nonce string, now, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.After(c.nextSweep) {
+		for n, e := range c.expiry {
+			if now.After(e) {
+				delete(c.expiry, n)
+			}
+		}
+		c.nextSweep = now.Add(nonceSweepInterval)
+	}
+
+	if e, ok := c.expiry[nonce]; ok && !now.After(e) {
+		return false
+	}
+	c.expiry[nonce] = expires
+	return true
+}
+
+// dbNonceStore remembers nonces in the database so they're shared by all API instances using it.
+type dbNonceStore struct {
+	// db is the database nonces are kept in, boil.GetDB() is used if it's nil.
+	db        boil.Executor
+	mu        sync.Mutex
+	nextSweep time.Time
+}
+
+// add works like nonceCache.add.
+func (s *dbNonceStore) add(nonce string, now, expires time.Time) (bool, error) {
+	db := s.db
+	if db == nil {
+		db = boil.GetDB()
+	}
+	if db == nil {
+		return false, errors.Err("database is not configured")
+	}
+
+	s.mu.Lock()
+	sweep := now.After(s.nextSweep)
+	if sweep {
+		s.nextSweep = now.Add(nonceSweepInterval)
+	}
+	s.mu.Unlock()
+	if sweep {
+		if _, err := db.Exec(`DELETE FROM "service_nonces" WHERE "expires_at" < $1`, now); err != nil {
+			logger.Log().Warnf("cannot drop expired service nonces: %v", err)
+		}
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO "service_nonces" ("nonce", "expires_at") VALUES ($1, $2)
+		ON CONFLICT ("nonce") DO UPDATE SET "expires_at" = EXCLUDED."expires_at"
+		WHERE "service_nonces"."expires_at" < $3`,
+		nonce, expires, now,
+	)
+	if err != nil {
+		return false, errors.Err(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Err(err)
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Headers which backend services use to sign their requests.
+const (
+	ServiceNameHeader      = "X-Service-Name"
+	ServiceTimestampHeader = "X-Service-Timestamp"
+	ServiceSignatureHeader = "X-Service-Signature"
+	ServiceNonceHeader     = "X-Service-Nonce"
+
+	// CacheDirectiveHeader lets services control caching of responses to their requests, the directive has to be signed
+	// in CacheDirectiveSignatureHeader (see SignCacheDirective).
+	CacheDirectiveHeader          = "X-Cache-Directive"
+	CacheDirectiveSignatureHeader = "X-Cache-Directive-Signature"
+)
+
+const serviceContextKey ctxKey = 1
+
+// maxServiceBodySize is the largest request body which is read for signature verification.
+const maxServiceBodySize = 1 << 22
+
+// maxServiceNonceLength is the longest nonce accepted in ServiceNonceHeader.
+const maxServiceNonceLength = 128
+
+var (
+	ErrNoServiceSignature   = errors.Base("service signature missing")
+	ErrUnknownService       = errors.Base("unknown service")
+	ErrServiceSignatureAged = errors.Base("service signature timestamp is too far off")
+	ErrBadServiceSignature  = errors.Base("service signature mismatch")
+	ErrNoServiceNonce       = errors.Base("service request nonce missing")
+	ErrServiceNonceReused   = errors.Base("service request nonce has already been used")
+
+	ErrBadCacheDirectiveSignature = errors.Base("cache directive signature mismatch")
+)
+
+var (
+	serviceNonces       = newNonceCache()
+	sharedServiceNonces = &dbNonceStore{}
+)
+
+// Service is a trusted backend principal which has authenticated itself with a request signature.
+type Service struct {
+	Name string
+}
+
+type serviceResult struct {
+	service *Service
+	err     error
+}
+
+// SignServiceRequest returns a hex-encoded HMAC-SHA256 signature of HTTP method, URL path, raw query string (if not empty),
+// unix timestamp, nonce (if not empty) and request body made with the secret shared between the service and the API.
+func SignServiceRequest(secret, method, path, rawQuery string, timestamp int64, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(path))
+	mac.Write([]byte("\n"))
+	if rawQuery != "" {
+		mac.Write([]byte("?" + rawQuery))
+		mac.Write([]byte("\n"))
+	}
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("\n"))
+	if nonce != "" {
+		mac.Write([]byte(nonce))
+		mac.Write([]byte("\n"))
+	}
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignCacheDirective returns a hex-encoded HMAC-SHA256 signature of the cache directive made with the service secret.
+// The directive is bound to the request it's sent with by its signature (see SignServiceRequest), so it cannot be replayed.
+func SignCacheDirective(secret, directive, requestSignature string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(directive))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(strings.ToLower(requestSignature)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyServiceRequest checks the request signature, returning the service which has signed it.
+// Signed nonces are remembered for as long as their timestamp is acceptable so the request cannot be replayed,
+// see useServiceNonce.
+// Request body is read and put back so it can still be consumed by handlers.
+func VerifyServiceRequest(r *http.Request, now time.Time) (*Service, error) {
+	name := r.Header.Get(ServiceNameHeader)
+	if name == "" {
+		return nil, errors.Err(ErrNoServiceSignature)
+	}
+	secret, ok := config.GetServiceSecrets()[strings.ToLower(name)]
+	if !ok || secret == "" {
+		return nil, errors.Err(ErrUnknownService)
+	}
+
+	ts, err := strconv.ParseInt(r.Header.Get(ServiceTimestampHeader), 10, 64)
+	if err != nil {
+		return nil, errors.Err(ErrBadServiceSignature)
+	}
+	maxAge := config.GetServiceSignatureMaxAge()
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxAge {
+		return nil, errors.Err(ErrServiceSignatureAged)
+	}
+
+	nonce := r.Header.Get(ServiceNonceHeader)
+	if nonce == "" && config.IsServiceNonceRequired() {
+		return nil, errors.Err(ErrNoServiceNonce)
+	}
+	if len(nonce) > maxServiceNonceLength {
+		return nil, errors.Err(ErrBadServiceSignature)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, errors.Err(err)
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := SignServiceRequest(secret, r.Method, r.URL.Path, r.URL.RawQuery, ts, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(r.Header.Get(ServiceSignatureHeader)))) {
+		return nil, errors.Err(ErrBadServiceSignature)
+	}
+	if nonce != "" && !useServiceNonce(strings.ToLower(name)+"\n"+nonce, now, time.Unix(ts, 0).Add(maxAge)) {
+		return nil, errors.Err(ErrServiceNonceReused)
+	}
+	return &Service{Name: name}, nil
+}
+
+// useServiceNonce records the nonce as seen until expires, returning false if it's already been seen.
+// Nonces are remembered by this instance and, when ServiceNonceShared is on, in the database so they cannot be
+// replayed against other API instances either. If the database is unavailable, only this instance is protected.
+func useServiceNonce(nonce string, now, expires time.Time) bool {
+	if !serviceNonces.add(nonce, now, expires) {
+		return false
+	}
+	if !config.IsServiceNonceShared() {
+		return true
+	}
+	fresh, err := sharedServiceNonces.add(nonce, now, expires)
+	if err != nil {
+		logger.Log().Warnf("cannot check service nonce in the database, it's only checked on this instance: %v", err)
+		return true
+	}
+	return fresh
+}
+
+// ServiceMiddleware verifies signatures of requests coming from backend services.
+// Requests without ServiceNameHeader are passed through untouched, signed requests
+// with bodies larger than maxServiceBodySize are rejected.
+func ServiceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(ServiceNameHeader) == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxServiceBodySize)
+		}
+		service, err := VerifyServiceRequest(r, time.Now())
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"ip":      ip.FromRequest(r),
+				"service": r.Header.Get(ServiceNameHeader),
+			}).Infof("service signature rejected: %v", err)
+		}
+		next.ServeHTTP(w, r.Clone(context.WithValue(r.Context(), serviceContextKey, serviceResult{service, err})))
+	})
+}
+
+// ServiceFromRequest returns the service which has signed the request.
+// Both values are nil if the request is not claiming to come from a service.
+func ServiceFromRequest(r *http.Request) (*Service, error) {
+	v := r.Context().Value(serviceContextKey)
+	if v == nil {
+		return nil, nil
+	}
+	res := v.(serviceResult)
+	return res.service, res.err
+}
+
+// IsService returns true if the request has been signed by a trusted backend service.
+// Such requests are exempt from user-level rate limits.
+func IsService(r *http.Request) bool {
+	s, err := ServiceFromRequest(r)
+	return s != nil && err == nil
+}
+
+// CacheDirectiveFromRequest returns the cache directive sent along with a request signed by a trusted service,
+// it's empty if there's none. The directive must be signed by the same service.
+func CacheDirectiveFromRequest(r *http.Request) (string, error) {
+	directive := r.Header.Get(CacheDirectiveHeader)
+	if directive == "" {
+		return "", nil
+	}
+	service, err := ServiceFromRequest(r)
+	if err != nil {
+		return "", err
+	}
+	if service == nil {
+		return "", errors.Err(ErrNoServiceSignature)
+	}
+	secret := config.GetServiceSecrets()[strings.ToLower(service.Name)]
+	expected := SignCacheDirective(secret, directive, r.Header.Get(ServiceSignatureHeader))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(r.Header.Get(CacheDirectiveSignatureHeader)))) {
+		return "", errors.Err(ErrBadCacheDirectiveSignature)
+	}
+	return directive, nil
+}
@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nonceSeq makes nonces of signedRequest unique.
+var nonceSeq int64
+
+func signedRequest(t *testing.T, name, secret string, ts time.Time, body string) *http.Request {
+	return signedRequestWithNonce(t, name, secret, ts, fmt.Sprintf("req-%v", atomic.AddInt64(&nonceSeq, 1)), body)
+}
+
+func signedRequestWithNonce(t *testing.T, name, secret string, ts time.Time, nonce, body string) *http.Request {
+	r, err := http.NewRequest(http.MethodPost, "/api/v1/proxy", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	r.Header.Set(ServiceNameHeader, name)
+	r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	if nonce != "" {
+		r.Header.Set(ServiceNonceHeader, nonce)
+	}
+	r.Header.Set(ServiceSignatureHeader, SignServiceRequest(secret, http.MethodPost, "/api/v1/proxy", "", ts.Unix(), nonce, []byte(body)))
+	return r
+}
+
+func TestVerifyServiceRequest(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	config.Override("ServiceSignatureMaxAge", "5m")
+	defer config.RestoreOverridden()
+
+	now := time.Now()
+	body := `{"method": "resolve", "params": {"urls": ["what"]}}`
+
+	t.Run("valid", func(t *testing.T) {
+		r := signedRequest(t, "Comments", "comment-secret", now.Add(-time.Minute), body)
+		s, err := VerifyServiceRequest(r, now)
+		require.NoError(t, err)
+		assert.Equal(t, "Comments", s.Name)
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(b))
+	})
+
+	cases := []struct {
+		name     string
+		request  func() *http.Request
+		expected error
+	}{
+		{"expired", func() *http.Request {
+			return signedRequest(t, "comments", "comment-secret", now.Add(-6*time.Minute), body)
+		}, ErrServiceSignatureAged},
+		{"stale", func() *http.Request {
+			return signedRequest(t, "comments", "comment-secret", now.Add(-time.Hour), body)
+		}, ErrServiceSignatureAged},
+		{"from the future", func() *http.Request {
+			return signedRequest(t, "comments", "comment-secret", now.Add(6*time.Minute), body)
+		}, ErrServiceSignatureAged},
+		{"tampered body", func() *http.Request {
+			r := signedRequest(t, "comments", "comment-secret", now, body)
+			r.Body = ioutil.NopCloser(bytes.NewBufferString(`{"method": "wallet_send"}`))
+			return r
+		}, ErrBadServiceSignature},
+		{"tampered timestamp", func() *http.Request {
+			r := signedRequest(t, "comments", "comment-secret", now, body)
+			r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(now.Unix()+1, 10))
+			return r
+		}, ErrBadServiceSignature},
+		{"tampered method", func() *http.Request {
+			r := signedRequest(t, "comments", "comment-secret", now, body)
+			r.Method = http.MethodPut
+			return r
+		}, ErrBadServiceSignature},
+		{"tampered path", func() *http.Request {
+			r := signedRequest(t, "comments", "comment-secret", now, body)
+			r.URL.Path = "/api/v1/wallet/export"
+			return r
+		}, ErrBadServiceSignature},
+		{"tampered nonce", func() *http.Request {
+			r := signedRequestWithNonce(t, "comments", "comment-secret", now, "n1", body)
+			r.Header.Set(ServiceNonceHeader, "n2")
+			return r
+		}, ErrBadServiceSignature},
+		{"nonce removed", func() *http.Request {
+			r := signedRequestWithNonce(t, "comments", "comment-secret", now, "n1", body)
+			r.Header.Del(ServiceNonceHeader)
+			return r
+		}, ErrNoServiceNonce},
+		{"oversized nonce", func() *http.Request {
+			return signedRequestWithNonce(t, "comments", "comment-secret", now, strings.Repeat("n", maxServiceNonceLength+1), body)
+		}, ErrBadServiceSignature},
+		{"wrong secret", func() *http.Request {
+			return signedRequest(t, "comments", "other-secret", now, body)
+		}, ErrBadServiceSignature},
+		{"unknown service", func() *http.Request {
+			return signedRequest(t, "search", "comment-secret", now, body)
+		}, ErrUnknownService},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := VerifyServiceRequest(c.request(), now)
+			assert.Nil(t, s)
+			assert.True(t, errors.Is(err, c.expected), fmt.Sprintf("unexpected error: %v", err))
+		})
+	}
+}
+
+func TestVerifyServiceRequest_Skew(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	config.Override("ServiceSignatureMaxAge", "5m")
+	defer config.RestoreOverridden()
+
+	now := time.Now().Truncate(time.Second)
+	for _, skew := range []time.Duration{0, -4 * time.Minute, 4 * time.Minute, -5 * time.Minute, 5 * time.Minute} {
+		_, err := VerifyServiceRequest(signedRequest(t, "comments", "comment-secret", now.Add(skew), "{}"), now)
+		assert.NoError(t, err, skew.String())
+	}
+	for _, skew := range []time.Duration{-5*time.Minute - time.Second, 5*time.Minute + time.Second, -24 * time.Hour} {
+		_, err := VerifyServiceRequest(signedRequest(t, "comments", "comment-secret", now.Add(skew), "{}"), now)
+		assert.True(t, errors.Is(err, ErrServiceSignatureAged), skew.String())
+	}
+
+	config.Override("ServiceSignatureMaxAge", "30s")
+	_, err := VerifyServiceRequest(signedRequest(t, "comments", "comment-secret", now.Add(-time.Minute), "{}"), now)
+	assert.True(t, errors.Is(err, ErrServiceSignatureAged))
+}
+
+func TestVerifyServiceRequest_Nonce(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret", "search": "search-secret"})
+	config.Override("ServiceSignatureMaxAge", "5m")
+	config.Override("ServiceNonceShared", false)
+	defer config.RestoreOverridden()
+	defer func() { serviceNonces = newNonceCache() }()
+
+	now := time.Now()
+	nonce := fmt.Sprintf("nonce-%v", now.UnixNano())
+
+	_, err := VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, nonce, "{}"), now)
+	require.NoError(t, err)
+
+	// Exact replay within the window
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, nonce, "{}"), now.Add(time.Minute))
+	assert.True(t, errors.Is(err, ErrServiceNonceReused), fmt.Sprintf("unexpected error: %v", err))
+
+	// The same nonce of another service is fine
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "search", "search-secret", now, nonce, "{}"), now)
+	assert.NoError(t, err)
+
+	// A request with a bad signature doesn't use up its nonce
+	bad := signedRequestWithNonce(t, "comments", "wrong-secret", now, "fresh", "{}")
+	_, err = VerifyServiceRequest(bad, now)
+	assert.True(t, errors.Is(err, ErrBadServiceSignature))
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, "fresh", "{}"), now)
+	assert.NoError(t, err)
+
+	// Once the timestamp is out of the window the replay is rejected as stale and the nonce is forgotten
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, nonce, "{}"), now.Add(6*time.Minute))
+	assert.True(t, errors.Is(err, ErrServiceSignatureAged))
+	assert.True(t, serviceNonces.add("comments\n"+nonce, now.Add(6*time.Minute), now.Add(10*time.Minute)))
+
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, "", "{}"), now)
+	assert.True(t, errors.Is(err, ErrNoServiceNonce))
+	config.Override("ServiceNonceRequired", false)
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, "", "{}"), now)
+	assert.NoError(t, err)
+}
+
+// nonceDB imitates the service_nonces table.
+type nonceDB struct {
+	expiry map[string]time.Time
+	fail   bool
+}
+
+type rowsAffected int64
+
+func (r rowsAffected) LastInsertId() (int64, error) { return 0, nil }
+func (r rowsAffected) RowsAffected() (int64, error) { return int64(r), nil }
+
+func (db *nonceDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.fail {
+		return nil, errors.Err("connection refused")
+	}
+	if strings.HasPrefix(query, "DELETE") {
+		return rowsAffected(0), nil
+	}
+	nonce, expires, now := args[0].(string), args[1].(time.Time), args[2].(time.Time)
+	if e, ok := db.expiry[nonce]; ok && !e.Before(now) {
+		return rowsAffected(0), nil
+	}
+	db.expiry[nonce] = expires
+	return rowsAffected(1), nil
+}
+
+func (db *nonceDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.Err("not implemented")
+}
+
+func (db *nonceDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestVerifyServiceRequest_SharedNonce(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	config.Override("ServiceSignatureMaxAge", "5m")
+	config.Override("ServiceNonceShared", true)
+	defer config.RestoreOverridden()
+	db := &nonceDB{expiry: map[string]time.Time{}}
+	sharedServiceNonces = &dbNonceStore{db: db}
+	defer func() {
+		serviceNonces = newNonceCache()
+		sharedServiceNonces = &dbNonceStore{}
+	}()
+
+	now := time.Now()
+	nonce := fmt.Sprintf("nonce-%v", now.UnixNano())
+	_, err := VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, nonce, "{}"), now)
+	require.NoError(t, err)
+
+	// Replayed against another instance, which hasn't seen the nonce itself
+	serviceNonces = newNonceCache()
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, nonce, "{}"), now.Add(time.Minute))
+	assert.True(t, errors.Is(err, ErrServiceNonceReused), fmt.Sprintf("unexpected error: %v", err))
+
+	// Without the database nonces are still checked by the instance
+	db.fail = true
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, "fresh", "{}"), now)
+	assert.NoError(t, err)
+	_, err = VerifyServiceRequest(signedRequestWithNonce(t, "comments", "comment-secret", now, "fresh", "{}"), now)
+	assert.True(t, errors.Is(err, ErrServiceNonceReused), fmt.Sprintf("unexpected error: %v", err))
+}
+
+func TestVerifyServiceRequest_Query(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	defer config.RestoreOverridden()
+
+	now := time.Now()
+	signed := func(query string) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, "/api/v1/proxy?"+query, bytes.NewBufferString("{}"))
+		require.NoError(t, err)
+		nonce := fmt.Sprintf("query-%v", atomic.AddInt64(&nonceSeq, 1))
+		r.Header.Set(ServiceNameHeader, "comments")
+		r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		r.Header.Set(ServiceNonceHeader, nonce)
+		r.Header.Set(ServiceSignatureHeader, SignServiceRequest("comment-secret", http.MethodPost, "/api/v1/proxy", query, now.Unix(), nonce, []byte("{}")))
+		return r
+	}
+
+	_, err := VerifyServiceRequest(signed("m=resolve"), now)
+	assert.NoError(t, err)
+
+	r := signed("m=resolve")
+	r.URL.RawQuery = "m=wallet_send"
+	_, err = VerifyServiceRequest(r, now)
+	assert.True(t, errors.Is(err, ErrBadServiceSignature), fmt.Sprintf("unexpected error: %v", err))
+
+	r = signed("")
+	r.URL.RawQuery = "m=wallet_send"
+	_, err = VerifyServiceRequest(r, now)
+	assert.True(t, errors.Is(err, ErrBadServiceSignature), fmt.Sprintf("unexpected error: %v", err))
+}
+
+func TestServiceMiddleware(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	defer config.RestoreOverridden()
+
+	var isService bool
+	var serviceErr error
+	handler := ServiceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isService = IsService(r)
+		_, serviceErr = ServiceFromRequest(r)
+	}))
+
+	r, err := http.NewRequest(http.MethodPost, "/api/v1/proxy", bytes.NewBufferString("{}"))
+	require.NoError(t, err)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.False(t, isService)
+	assert.NoError(t, serviceErr)
+
+	handler.ServeHTTP(httptest.NewRecorder(), signedRequest(t, "comments", "comment-secret", time.Now(), "{}"))
+	assert.True(t, isService)
+	assert.NoError(t, serviceErr)
+
+	handler.ServeHTTP(httptest.NewRecorder(), signedRequest(t, "comments", "comment-secret", time.Now().Add(-time.Hour), "{}"))
+	assert.False(t, isService)
+	assert.True(t, errors.Is(serviceErr, ErrServiceSignatureAged))
+
+	large := strings.Repeat("x", maxServiceBodySize+1)
+	handler.ServeHTTP(httptest.NewRecorder(), signedRequest(t, "comments", "comment-secret", time.Now(), large))
+	assert.False(t, isService)
+	assert.Error(t, serviceErr)
+}
+
+func TestCacheDirectiveFromRequest(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret", "other": "other-secret"})
+	defer config.RestoreOverridden()
+
+	var directive string
+	var directiveErr error
+	handler := ServiceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		directive, directiveErr = CacheDirectiveFromRequest(r)
+	}))
+	signed := func(name, secret, directiveSecret, d string) *http.Request {
+		r := signedRequest(t, name, secret, time.Now(), "{}")
+		r.Header.Set(CacheDirectiveHeader, d)
+		r.Header.Set(CacheDirectiveSignatureHeader, SignCacheDirective(directiveSecret, d, r.Header.Get(ServiceSignatureHeader)))
+		return r
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), signed("comments", "comment-secret", "comment-secret", "ttl=30s"))
+	require.NoError(t, directiveErr)
+	assert.Equal(t, "ttl=30s", directive)
+
+	r := signedRequest(t, "comments", "comment-secret", time.Now(), "{}")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	require.NoError(t, directiveErr)
+	assert.Equal(t, "", directive)
+
+	cases := []struct {
+		name     string
+		request  func() *http.Request
+		expected error
+	}{
+		{"unsigned request", func() *http.Request {
+			r, err := http.NewRequest(http.MethodPost, "/api/v1/proxy", bytes.NewBufferString("{}"))
+			require.NoError(t, err)
+			r.Header.Set(CacheDirectiveHeader, "ttl=30s")
+			return r
+		}, ErrNoServiceSignature},
+		{"unsigned directive", func() *http.Request {
+			r := signedRequest(t, "comments", "comment-secret", time.Now(), "{}")
+			r.Header.Set(CacheDirectiveHeader, "ttl=30s")
+			return r
+		}, ErrBadCacheDirectiveSignature},
+		{"directive signed by another service", func() *http.Request {
+			return signed("comments", "comment-secret", "other-secret", "ttl=30s")
+		}, ErrBadCacheDirectiveSignature},
+		{"tampered directive", func() *http.Request {
+			r := signed("comments", "comment-secret", "comment-secret", "ttl=30s")
+			r.Header.Set(CacheDirectiveHeader, "ttl=3000s")
+			return r
+		}, ErrBadCacheDirectiveSignature},
+		{"replayed directive", func() *http.Request {
+			r := signed("comments", "comment-secret", "comment-secret", "ttl=30s")
+			other := signedRequest(t, "comments", "comment-secret", time.Now(), `{"method": "resolve"}`)
+			other.Header.Set(CacheDirectiveHeader, "ttl=30s")
+			other.Header.Set(CacheDirectiveSignatureHeader, r.Header.Get(CacheDirectiveSignatureHeader))
+			return other
+		}, ErrBadCacheDirectiveSignature},
+		{"invalid request signature", func() *http.Request {
+			r := signed("comments", "comment-secret", "comment-secret", "ttl=30s")
+			r.Header.Set(ServiceTimestampHeader, "1")
+			return r
+		}, ErrServiceSignatureAged},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), c.request())
+			assert.True(t, errors.Is(directiveErr, c.expected), directiveErr)
+			assert.Equal(t, "", directive)
+		})
+	}
+}
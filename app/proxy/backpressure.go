@@ -0,0 +1,32 @@
+package proxy
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/scheduler"
+)
+
+// ServerLoadHeader carries scheduler utilization for clients to slow down before queries get rejected, see setBackpressure.
+const ServerLoadHeader = "X-Server-Load"
+
+// setBackpressure signals clients to back off when utilization of s is at or above the Backpressure threshold.
+// The response is served as usual, only the headers are added.
+func setBackpressure(w http.ResponseWriter, s *scheduler.Scheduler) {
+	b := config.GetBackpressure()
+	if !b.Enabled || b.Threshold <= 0 {
+		return
+	}
+	u := s.Utilization()
+	if u < b.Threshold {
+		return
+	}
+	w.Header().Set(ServerLoadHeader, strconv.FormatFloat(u, 'f', 2, 64))
+	if b.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(b.RetryAfter.Seconds()))))
+	}
+	metrics.ProxyBackpressureSignals.Inc()
+}
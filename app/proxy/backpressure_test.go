@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/scheduler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBackpressure(t *testing.T) {
+	s := scheduler.New(4, time.Hour)
+	for i := 0; i < 3; i++ {
+		defer s.Acquire(scheduler.PriorityNormal)()
+	}
+
+	// Off by default
+	rr := httptest.NewRecorder()
+	setBackpressure(rr, s)
+	assert.Empty(t, rr.Header().Get(ServerLoadHeader))
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+
+	config.Override("Backpressure", map[string]interface{}{"Enabled": true, "Threshold": 0.8, "RetryAfter": "1500ms"})
+	defer config.RestoreOverridden()
+
+	// Below threshold
+	rr = httptest.NewRecorder()
+	setBackpressure(rr, s)
+	assert.Empty(t, rr.Header().Get(ServerLoadHeader))
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+
+	// At threshold
+	config.Override("Backpressure", map[string]interface{}{"Enabled": true, "Threshold": 0.75, "RetryAfter": "1500ms"})
+	rr = httptest.NewRecorder()
+	setBackpressure(rr, s)
+	assert.Equal(t, "0.75", rr.Header().Get(ServerLoadHeader))
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+
+	// Above threshold, without Retry-After
+	defer s.Acquire(scheduler.PriorityNormal)()
+	config.Override("Backpressure", map[string]interface{}{"Enabled": true, "Threshold": 0.8, "RetryAfter": 0})
+	rr = httptest.NewRecorder()
+	setBackpressure(rr, s)
+	assert.Equal(t, "1.00", rr.Header().Get(ServerLoadHeader))
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+
+	// No limit means no load signal
+	rr = httptest.NewRecorder()
+	setBackpressure(rr, scheduler.New(0, time.Hour))
+	assert.Empty(t, rr.Header().Get(ServerLoadHeader))
+}
@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// batchResponseWriter collects a response to a single request of a batch.
+type batchResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func newBatchResponseWriter() *batchResponseWriter {
+	return &batchResponseWriter{header: http.Header{}}
+}
+
+func (w *batchResponseWriter) Header() http.Header         { return w.header }
+func (w *batchResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *batchResponseWriter) WriteHeader(int)             {}
+
+// batchKey returns the key identical requests of a batch share. Only safe reads are coalesced,
+// every other request gets a key of its own and is performed on its own.
+func batchKey(i int, raw json.RawMessage) string {
+	var req jsonrpc.RPCRequest
+	if err := unmarshalRequest(raw, &req); err != nil || !query.IsSafeReadMethod(req.Method) {
+		return "#" + strconv.Itoa(i)
+	}
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return "#" + strconv.Itoa(i)
+	}
+	return req.Method + "\n" + string(params)
+}
+
+// HandleBatch takes a JSON array of JSON-RPC requests and responds with an array of responses in the same order.
+// Every request is handled as if it came to Handle on its own, except that identical safe reads are performed
+// once and their response is given to all of them, with request IDs preserved.
+func HandleBatch(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	setNoStore(w)
+
+	if r.Body == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeResponse(w, rpcerrors.NewJSONParseError(errors.Err("empty request body")).JSON())
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeResponse(w, rpcerrors.NewJSONParseError(errors.Err("error reading request body")).JSON())
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := responses.UnmarshalJSON(body, &batch); err != nil {
+		writeResponse(w, rpcerrors.NewJSONParseError(err).JSON())
+		return
+	}
+	if len(batch) == 0 {
+		writeResponse(w, rpcerrors.NewInvalidParamsError(errors.Err("batch is empty")).JSON())
+		return
+	}
+	if max := config.GetBatchMaxSize(); max > 0 && len(batch) > max {
+		writeResponse(w, rpcerrors.NewInvalidParamsError(errors.Err("batch is larger than %v requests", max)).JSON())
+		return
+	}
+
+	results := map[string]json.RawMessage{}
+	out := make([]json.RawMessage, len(batch))
+	for i, raw := range batch {
+		k := batchKey(i, raw)
+		res, ok := results[k]
+		if ok {
+			var req jsonrpc.RPCRequest
+			responses.UnmarshalJSON(raw, &req)
+			metrics.ProxyBatchCoalescedCount.WithLabelValues(req.Method).Inc()
+		} else {
+			res = handleBatched(r, raw)
+			results[k] = res
+		}
+		out[i] = withRequestID(res, raw)
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		writeResponse(w, rpcerrors.NewInternalError(err).JSON())
+		return
+	}
+	writeResponse(w, b)
+}
+
+// handleBatched performs a single request of a batch with Handle, the batch request providing its context and headers.
+func handleBatched(r *http.Request, raw json.RawMessage) json.RawMessage {
+	sub := r.Clone(r.Context())
+	sub.Header.Del(ResponseFormatHeader)
+	sub.Header.Del("Accept-Encoding")
+	sub.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	sub.ContentLength = int64(len(raw))
+
+	bw := newBatchResponseWriter()
+	Handle(bw, sub)
+
+	res := bytes.TrimSpace(bw.body.Bytes())
+	if !json.Valid(res) {
+		return rpcerrors.NewInternalError(errors.Err("invalid response")).JSON()
+	}
+	return res
+}
+
+// withRequestID returns res with its id set to the id of req, res is returned as is if either can't be parsed.
+func withRequestID(res, req json.RawMessage) json.RawMessage {
+	var reqFields, resFields map[string]json.RawMessage
+	if json.Unmarshal(req, &reqFields) != nil || json.Unmarshal(res, &resFields) != nil {
+		return res
+	}
+	id, ok := reqFields["id"]
+	if !ok {
+		id = json.RawMessage("0")
+	}
+	resFields["id"] = id
+	b, err := json.Marshal(resFields)
+	if err != nil {
+		return res
+	}
+	return b
+}
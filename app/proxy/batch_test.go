@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+// countingSDK responds to every call with its method and sequence number.
+type countingSDK struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []string
+}
+
+func newCountingSDK(t *testing.T) *countingSDK {
+	s := &countingSDK{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		s.mu.Lock()
+		s.calls = append(s.calls, req.Method)
+		n := len(s.calls)
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{
+			"method": req.Method, "call": n, "items": []interface{}{},
+		}})
+	}))
+	return s
+}
+
+func callBatch(t *testing.T, handler http.Handler, batch string) []map[string]interface{} {
+	r, err := http.NewRequest(http.MethodPost, "", bytes.NewBufferString(batch))
+	require.NoError(t, err)
+	r.Header.Set(wallet.TokenHeader, "abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var res []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+	return res
+}
+
+func batchHandler(sdk *countingSDK) http.Handler {
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 992}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: sdk.URL}
+		return u, nil
+	}
+	return middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.Middleware(provider),
+	), HandleBatch)
+}
+
+func TestHandleBatch_Duplicates(t *testing.T) {
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+
+	res := callBatch(t, batchHandler(sdk), `[
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"claim_ids": ["a"], "page": 1}, "id": 1},
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1, "claim_ids": ["a"]}, "id": 2},
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"claim_ids": ["a"], "page": 1}, "id": 3}
+	]`)
+
+	assert.Equal(t, []string{"claim_search"}, sdk.calls)
+	require.Len(t, res, 3)
+	for i, id := range []interface{}{1.0, 2.0, 3.0} {
+		assert.Equal(t, id, res[i]["id"])
+		assert.Equal(t, map[string]interface{}{"method": "claim_search", "call": 1.0, "items": []interface{}{}}, res[i]["result"])
+	}
+}
+
+func TestHandleBatch_Mixed(t *testing.T) {
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+
+	res := callBatch(t, batchHandler(sdk), `[
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"claim_ids": ["a"]}, "id": 1},
+		{"jsonrpc": "2.0", "method": "support_create", "params": {"claim_id": "a", "amount": "1.0"}, "id": 2},
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"claim_ids": ["b"]}, "id": 3},
+		{"jsonrpc": "2.0", "method": "support_create", "params": {"claim_id": "a", "amount": "1.0"}, "id": 4},
+		{"jsonrpc": "2.0", "method": "claim_search", "params": {"claim_ids": ["a"]}, "id": 5},
+		{"jsonrpc": "2.0", "method": "version", "id": 6},
+		"not a request"
+	]`)
+
+	// Writes are never coalesced, reads with different params aren't either
+	assert.Equal(t, []string{"claim_search", "support_create", "claim_search", "support_create", "version"}, sdk.calls)
+	require.Len(t, res, 7)
+	results := []interface{}{}
+	for _, r := range res[:6] {
+		results = append(results, r["result"].(map[string]interface{})["call"])
+	}
+	assert.Equal(t, []interface{}{1.0, 2.0, 3.0, 4.0, 1.0, 5.0}, results)
+	for i, r := range res[:6] {
+		assert.Equal(t, float64(i+1), r["id"])
+	}
+	assert.NotNil(t, res[6]["error"])
+}
+
+func TestHandleBatch_Invalid(t *testing.T) {
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+	config.Override("BatchMaxSize", 2)
+	defer config.RestoreOverridden()
+
+	for _, body := range []string{
+		`{"jsonrpc": "2.0", "method": "status", "id": 1}`,
+		`[]`,
+		`[{"jsonrpc": "2.0", "method": "status", "id": 1}, {"jsonrpc": "2.0", "method": "status", "id": 2},
+			{"jsonrpc": "2.0", "method": "status", "id": 3}]`,
+	} {
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		batchHandler(sdk).ServeHTTP(rr, r)
+
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), body)
+		assert.NotNil(t, res.Error, body)
+	}
+	assert.Empty(t, sdk.calls)
+}
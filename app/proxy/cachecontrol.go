@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	CacheControlHeader     = "Cache-Control"
+	SurrogateControlHeader = "Surrogate-Control"
+
+	// Query cache details, see setCacheInfo.
+	CacheStatusHeader = "X-Cache"
+	CacheTTLHeader    = "X-Cache-TTL-Remaining"
+	CacheKeyHeader    = "X-Cache-Key"
+
+	noStore = "no-store"
+)
+
+// setNoStore keeps CDN and browsers from caching the response.
+func setNoStore(w http.ResponseWriter) {
+	w.Header().Set(CacheControlHeader, noStore)
+	w.Header().Set(SurrogateControlHeader, noStore)
+}
+
+// setCachePolicy sets caching headers for a successful response of method according to CachePolicies config.
+// Responses to authenticated users and of methods requiring a wallet are never cacheable.
+func setCachePolicy(w http.ResponseWriter, method string, userID int) {
+	p, ok := config.GetCachePolicies()[method]
+	if !ok || p.CacheControl == "" || userID != 0 || query.MethodRequiresWallet(method, nil) {
+		setNoStore(w)
+		return
+	}
+	w.Header().Set(CacheControlHeader, p.CacheControl)
+	if p.SurrogateControl != "" {
+		w.Header().Set(SurrogateControlHeader, p.SurrogateControl)
+	} else {
+		w.Header().Del(SurrogateControlHeader)
+	}
+}
+
+// setCacheInfo tells how the response has been served by the query cache: status and remaining TTL in seconds.
+// They're sent to admins (see auth.IsAdmin) or everyone with ExposeCacheInfo on, the cache key is sent to admins only.
+func setCacheInfo(w http.ResponseWriter, r *http.Request, info *cache.Info) {
+	if info == nil {
+		return
+	}
+	isAdmin := auth.IsAdmin(r)
+	if !isAdmin && !config.ShouldExposeCacheInfo() {
+		return
+	}
+	w.Header().Set(CacheStatusHeader, info.Status)
+	if info.TTL > 0 {
+		w.Header().Set(CacheTTLHeader, strconv.Itoa(int(info.TTL.Seconds())))
+	}
+	if isAdmin {
+		w.Header().Set(CacheKeyHeader, info.Key)
+	}
+}
+
+// cacheDirective returns the cache directive sent with a request of a trusted service (see auth.CacheDirectiveFromRequest).
+// Directives of anyone else, or invalid ones, are ignored so regular clients cannot influence caching.
+func cacheDirective(r *http.Request) *query.CacheDirective {
+	d, err := auth.CacheDirectiveFromRequest(r)
+	if err == nil && d == "" {
+		return nil
+	}
+	var cd *query.CacheDirective
+	if err == nil {
+		cd, err = query.ParseCacheDirective(d)
+	}
+	if err != nil {
+		metrics.ProxyQueryCacheDirectives.WithLabelValues("rejected").Inc()
+		logger.WithFields(logrus.Fields{"ip": ip.FromRequest(r), "service": r.Header.Get(auth.ServiceNameHeader)}).
+			Infof("cache directive ignored: %v", err)
+		return nil
+	}
+	return cd
+}
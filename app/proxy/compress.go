@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+const (
+	compressAlways = "always"
+	compressNever  = "never"
+)
+
+// shouldCompress decides if a response of size bytes to method gets compressed.
+// Negative size stands for a streamed response of unknown size, which is considered large.
+func shouldCompress(c config.ResponseCompression, method string, size int) bool {
+	if !c.Enabled {
+		return false
+	}
+	threshold := c.Threshold
+	if v, ok := c.Methods[strings.ToLower(method)]; ok {
+		switch v {
+		case compressAlways:
+			return true
+		case compressNever:
+			return false
+		default:
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				logger.Log().Warnf("invalid compression setting for %v: %q", method, v)
+				break
+			}
+			threshold = t
+		}
+	}
+	return size < 0 || size >= threshold
+}
+
+// acceptsGzip checks if the client has listed gzip in Accept-Encoding without rejecting it with q=0.
+func acceptsGzip(r *http.Request) bool {
+	for _, e := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(e, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		for _, p := range parts[1:] {
+			if q := strings.TrimSpace(p); strings.HasPrefix(q, "q=") {
+				if v, err := strconv.ParseFloat(q[2:], 64); err == nil && v == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += n
+	return n, err
+}
+
+// compressingWriter gzips the response if the method settings allow it for the response size.
+// The decision is made on the first write, so the method has to be set by then. Responses written
+// after WriteHeader, which is only called for errors, are never compressed.
+type compressingWriter struct {
+	http.ResponseWriter
+	method   string
+	accepted bool
+	streamed bool
+	decided  bool
+	gz       *gzip.Writer
+	out      *countingWriter
+	// written is the number of bytes written before compression
+	written int
+}
+
+func newCompressingWriter(w http.ResponseWriter, r *http.Request) *compressingWriter {
+	return &compressingWriter{ResponseWriter: w, accepted: acceptsGzip(r)}
+}
+
+func (w *compressingWriter) decide(size int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	c := config.GetResponseCompression()
+	if !c.Enabled {
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if w.streamed {
+		size = -1
+	}
+	if !w.accepted || !shouldCompress(c, w.method, size) {
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.out = &countingWriter{w: w.ResponseWriter}
+	w.gz = gzip.NewWriter(w.out)
+}
+
+func (w *compressingWriter) WriteHeader(code int) {
+	w.decided = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressingWriter) Write(b []byte) (int, error) {
+	w.written += len(b)
+	w.decide(len(b))
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// Close flushes the compressed response and records the compression ratio. It should be deferred.
+func (w *compressingWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	if w.written > 0 {
+		metrics.ProxyResponseCompressionRatio.WithLabelValues(w.method).Observe(float64(w.out.n) / float64(w.written))
+	}
+	return err
+}
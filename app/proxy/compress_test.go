@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestShouldCompress(t *testing.T) {
+	c := config.ResponseCompression{
+		Enabled:   true,
+		Threshold: 1000,
+		Methods:   map[string]string{"claim_search": "always", "wallet_balance": "never", "resolve": "100", "status": "x"},
+	}
+	cases := []struct {
+		method   string
+		size     int
+		expected bool
+	}{
+		{"claim_search", 10, true},
+		{"Claim_Search", 10, true},
+		{"wallet_balance", 100000, false},
+		{"wallet_balance", -1, false},
+		{"resolve", 99, false},
+		{"resolve", 100, true},
+		{"status", 999, false},
+		{"status", 1000, true},
+		{"version", 999, false},
+		{"version", 1000, true},
+		{"version", -1, true},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, shouldCompress(c, tc.method, tc.size), "%v of %v bytes", tc.method, tc.size)
+	}
+
+	c.Enabled = false
+	assert.False(t, shouldCompress(c, "claim_search", 10))
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"deflate, gzip;q=1.0": true,
+		"br;q=1.0, *;q=0.5":   true,
+		"GZIP":                true,
+		"gzip;q=0":            false,
+		"deflate, br":         false,
+	}
+	for header, expected := range cases {
+		r, _ := http.NewRequest(http.MethodPost, "", nil)
+		r.Header.Set("Accept-Encoding", header)
+		assert.Equal(t, expected, acceptsGzip(r), header)
+	}
+}
+
+func callCompressed(t *testing.T, handler http.HandlerFunc, method, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	srv.QueueResponses(`{"jsonrpc": "2.0", "result": {"items": [], "page": 1, "blocked": {"total": 0}, "fill": "` +
+		string(bytes.Repeat([]byte("a"), 2000)) + `"}, "id": 0}`)
+
+	raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"page": 1}))
+	require.NoError(t, err)
+	r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+	require.NoError(t, err)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+	sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL}))(handler).ServeHTTP(rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+	return rr
+}
+
+func gunzip(t *testing.T, b []byte) []byte {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	d, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	return d
+}
+
+func TestProxyCompression(t *testing.T) {
+	config.Override("ResponseCompression", map[string]interface{}{
+		"Enabled": true, "Threshold": 100000, "Methods": map[string]string{"claim_search": "always", "status": "never"},
+	})
+	defer config.RestoreOverridden()
+
+	rr := callCompressed(t, Handle, "claim_search", "gzip")
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(gunzip(t, rr.Body.Bytes()), &res))
+	assert.Len(t, res.Result.(map[string]interface{})["fill"], 2000)
+	assert.Greater(t, testCompressionObservations(t, "claim_search"), uint64(0))
+
+	// Envelopes are compressed after they're built
+	rr = callCompressed(t, HandleEnvelope, "claim_search", "gzip")
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Contains(t, string(gunzip(t, rr.Body.Bytes())), `"success": true`)
+
+	// Client doesn't accept compressed responses
+	rr = callCompressed(t, Handle, "claim_search", "")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Contains(t, rr.Body.String(), `"page": 1`)
+
+	// Below default threshold
+	rr = callCompressed(t, Handle, "resolve", "gzip")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+
+	rr = callCompressed(t, Handle, "status", "gzip")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+
+	// Settings are picked up without a restart
+	config.Override("ResponseCompression", map[string]interface{}{"Enabled": true, "Threshold": 100})
+	rr = callCompressed(t, Handle, "status", "gzip")
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	config.Override("ResponseCompression", map[string]interface{}{"Enabled": false})
+	rr = callCompressed(t, Handle, "claim_search", "gzip")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Empty(t, rr.Header().Get("Vary"))
+}
+
+func testCompressionObservations(t *testing.T, method string) uint64 {
+	t.Helper()
+	m := metrics.GetMetric(metrics.ProxyResponseCompressionRatio.WithLabelValues(method).(prometheus.Histogram))
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,50 @@
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// RequestCostHeader carries the cost of the request charged to the user (see config.GetRequestCosts).
+const RequestCostHeader = "X-Request-Cost"
+
+// addCost accumulates the cost of a request for the user, replaced in tests.
+var addCost = func(userID int, cost int64) (int64, error) {
+	return audit.AddCost(boil.GetDB(), userID, cost)
+}
+
+// requestCost returns the cost of a method according to RequestCosts config.
+func requestCost(method string) int64 {
+	costs := config.GetRequestCosts()
+	if c, ok := costs.Methods[method]; ok {
+		return c
+	}
+	if query.IsWalletMutation(method) {
+		return costs.Write
+	}
+	return costs.Read
+}
+
+// chargeRequest adds the cost of a successful request to the user total and reports it to the client.
+// Cached responses are charged the same as fresh ones. Failures to record the cost don't fail the request.
+func chargeRequest(w http.ResponseWriter, user *models.User, method string) {
+	if user == nil {
+		return
+	}
+	cost := requestCost(method)
+	if cost <= 0 {
+		return
+	}
+	if _, err := addCost(user.ID, cost); err != nil {
+		logger.Log().Errorf("cannot charge %v of user %v: %v", method, user.ID, err)
+		return
+	}
+	w.Header().Set(RequestCostHeader, strconv.FormatInt(cost, 10))
+}
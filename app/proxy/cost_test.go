@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestRequestCost(t *testing.T) {
+	config.Override("RequestCosts", map[string]interface{}{
+		"Read": 1, "Write": 5, "Methods": map[string]interface{}{query.MethodClaimSearch: 3},
+	})
+	defer config.RestoreOverridden()
+
+	assert.EqualValues(t, 1, requestCost(query.MethodResolve))
+	assert.EqualValues(t, 3, requestCost(query.MethodClaimSearch))
+	assert.EqualValues(t, 5, requestCost(query.MethodWalletSend))
+}
+
+func TestProxyChargesRequests(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	config.Override("RequestCosts", map[string]interface{}{"Read": 2, "Write": 0})
+	defer config.RestoreOverridden()
+
+	charged := map[int]int64{}
+	origAddCost := addCost
+	addCost = func(userID int, cost int64) (int64, error) {
+		charged[userID] += cost
+		return charged[userID], nil
+	}
+	defer func() { addCost = origAddCost }()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 993}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: srv.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(sdkrouter.Middleware(rt), auth.Middleware(provider)), Handle)
+
+	call := func(authenticated bool) *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodClaimSearch, map[string]interface{}{"name": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if authenticated {
+			r.Header.Set(wallet.TokenHeader, "abc")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	rr := call(true)
+	assert.Equal(t, "2", rr.Header().Get(RequestCostHeader))
+	assert.EqualValues(t, 2, charged[993])
+
+	// Anonymous requests and failed ones are free
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	rr = call(false)
+	assert.Empty(t, rr.Header().Get(RequestCostHeader))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "failed"}, "id": 0}`
+	rr = call(true)
+	assert.Empty(t, rr.Header().Get(RequestCostHeader))
+	assert.EqualValues(t, 2, charged[993])
+
+	// Failing to record the cost doesn't fail the request
+	addCost = func(int, int64) (int64, error) { return 0, errors.Err("db is down") }
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	rr = call(true)
+	assert.Empty(t, rr.Header().Get(RequestCostHeader))
+	assert.Contains(t, rr.Body.String(), "items")
+}
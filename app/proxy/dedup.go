@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// Actions taken on duplicate requests (see config.DuplicateRequest).
+const (
+	DuplicateActionCoalesce = "coalesce"
+	DuplicateActionReject   = "reject"
+)
+
+// duplicates holds recent requests to methods listed in DuplicateRequests config.
+var duplicates = newDedupStore()
+
+// dedupEntry is a request later identical ones are compared to. Its response is recorded as it's written,
+// so coalesced duplicates can be given the same one once done is closed.
+type dedupEntry struct {
+	started time.Time
+	done    chan struct{}
+	status  int
+	body    bytes.Buffer
+	// response is kept instead of body for streamed responses, which can be written past the recording writer.
+	response *jsonrpc.RPCResponse
+}
+
+func (e *dedupEntry) finish() {
+	close(e.done)
+}
+
+type dedupStore struct {
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+func newDedupStore() *dedupStore {
+	return &dedupStore{entries: map[string]*dedupEntry{}}
+}
+
+// check returns the entry of an identical request made within window before now and true.
+// If there's none, it registers the request and returns its own entry, which must be finished when it's been answered.
+func (s *dedupStore) check(key string, window time.Duration, now time.Time) (*dedupEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && now.Sub(e.started) < window {
+		return e, true
+	}
+	e := &dedupEntry{started: now, done: make(chan struct{})}
+	s.entries[key] = e
+	time.AfterFunc(window, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.entries[key] == e {
+			delete(s.entries, key)
+		}
+	})
+	return e, false
+}
+
+// duplicateKey identifies requests of the user with the same method and params.
+// Request ID is left out as clients number requests they send.
+func duplicateKey(userID int, req *jsonrpc.RPCRequest) (string, error) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return "", errors.Err(err)
+	}
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write(params)
+	return strconv.Itoa(userID) + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordingWriter passes the response on, keeping a copy of it in entry.
+type recordingWriter struct {
+	http.ResponseWriter
+	entry *dedupEntry
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	w.entry.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.entry.response == nil {
+		w.entry.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// recordStreamed keeps the response about to be streamed to w for duplicates of the request, if w is recording it.
+func recordStreamed(w http.ResponseWriter, res *jsonrpc.RPCResponse) {
+	if rw, ok := w.(*recordingWriter); ok {
+		rw.entry.response = res
+	}
+}
+
+// dedupe checks if the request is a duplicate of one the user has just made, as set in DuplicateRequests config.
+// Duplicates are answered here and true is returned. Otherwise the request is registered for later ones
+// to be compared to and the returned writer, which has to be used for the response, must be finished by calling
+// the returned function once the response has been written. Only authenticated calls are checked.
+func dedupe(w http.ResponseWriter, r *http.Request, user *models.User, req *jsonrpc.RPCRequest, obs *callObserver) (http.ResponseWriter, func(), bool) {
+	dup, ok := config.GetDuplicateRequests()[req.Method]
+	if !ok || dup.Window <= 0 || user == nil {
+		return w, func() {}, false
+	}
+	key, err := duplicateKey(user.ID, req)
+	if err != nil {
+		logger.Log().Errorf("cannot check %v for duplicates: %v", req.Method, err)
+		return w, func() {}, false
+	}
+	first, isDup := duplicates.check(key, dup.Window, time.Now())
+	if !isDup {
+		return &recordingWriter{ResponseWriter: w, entry: first}, first.finish, false
+	}
+
+	if dup.Action == DuplicateActionReject {
+		metrics.ProxyDuplicateRequests.WithLabelValues(req.Method, DuplicateActionReject).Inc()
+		logger.Log().Infof("rejected duplicate %v of user %v", req.Method, user.ID)
+		writeResponse(w, rpcerrors.NewDuplicateRequestError().JSON())
+		obs.failure(metrics.FailureKindDuplicate)
+		return w, nil, true
+	}
+
+	metrics.ProxyDuplicateRequests.WithLabelValues(req.Method, DuplicateActionCoalesce).Inc()
+	logger.Log().Infof("coalescing duplicate %v of user %v", req.Method, user.ID)
+	select {
+	case <-first.done:
+	case <-r.Context().Done():
+		obs.failure(metrics.FailureKindClient)
+		return w, nil, true
+	}
+	if first.response != nil {
+		res := *first.response
+		res.ID = req.ID
+		if first.status != 0 {
+			w.WriteHeader(first.status)
+		}
+		streamResponse(w, &res)
+		obs.success()
+		return w, nil, true
+	}
+	res := withResponseID(first.body.Bytes(), req.ID)
+	if res == nil {
+		writeResponse(w, rpcerrors.NewInternalError(errors.Err("duplicated request got no response")).JSON())
+		obs.failure(metrics.FailureKindInternal)
+		return w, nil, true
+	}
+	if first.status != 0 {
+		w.WriteHeader(first.status)
+	}
+	writeResponse(w, res)
+	obs.success()
+	return w, nil, true
+}
+
+// withResponseID returns a copy of the serialized JSON-RPC response with its ID set to id.
+// It returns nil if b is not a JSON-RPC response.
+func withResponseID(b []byte, id int) []byte {
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(b, &res); err != nil || res == nil {
+		return nil
+	}
+	res["id"] = json.RawMessage(strconv.Itoa(id))
+	rb, err := json.Marshal(res)
+	if err != nil {
+		return nil
+	}
+	return rb
+}
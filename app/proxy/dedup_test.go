@@ -0,0 +1,154 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandle_DuplicateRequests(t *testing.T) {
+	config.Override("DuplicateRequests", map[string]interface{}{
+		"claim_search": map[string]interface{}{"Window": "1m", "Action": DuplicateActionCoalesce},
+		"claim_list":   map[string]interface{}{"Window": "1m", "Action": DuplicateActionReject},
+	})
+	defer config.RestoreOverridden()
+	duplicates = newDedupStore()
+
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 993}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: sdk.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.Middleware(provider),
+	), Handle)
+
+	call := func(body string) map[string]interface{} {
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		r.Header.Set(wallet.TokenHeader, "abc")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+		return res
+	}
+	result := func(res map[string]interface{}) map[string]interface{} {
+		require.Nil(t, res["error"])
+		return res["result"].(map[string]interface{})
+	}
+
+	coalesced := metrics.GetCounterValue(metrics.ProxyDuplicateRequests.WithLabelValues("claim_search", DuplicateActionCoalesce))
+	first := call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1}, "id": 1}`)
+	dup := call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1}, "id": 2}`)
+	assert.EqualValues(t, 2, dup["id"])
+	assert.Equal(t, result(first), result(dup))
+	assert.Equal(t, coalesced+1, metrics.GetCounterValue(metrics.ProxyDuplicateRequests.WithLabelValues("claim_search", DuplicateActionCoalesce)))
+
+	other := call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 2}, "id": 3}`)
+	assert.EqualValues(t, 2, result(other)["call"])
+
+	rejected := metrics.GetCounterValue(metrics.ProxyDuplicateRequests.WithLabelValues("claim_list", DuplicateActionReject))
+	result(call(`{"jsonrpc": "2.0", "method": "claim_list", "params": {}, "id": 4}`))
+	res := call(`{"jsonrpc": "2.0", "method": "claim_list", "params": {}, "id": 5}`)
+	require.NotNil(t, res["error"])
+	assert.EqualValues(t, -32098, res["error"].(map[string]interface{})["code"])
+	assert.Equal(t, rejected+1, metrics.GetCounterValue(metrics.ProxyDuplicateRequests.WithLabelValues("claim_list", DuplicateActionReject)))
+
+	// Methods which aren't listed are always sent on
+	call(`{"jsonrpc": "2.0", "method": "txo_list", "params": {}, "id": 6}`)
+	call(`{"jsonrpc": "2.0", "method": "txo_list", "params": {}, "id": 7}`)
+	assert.Equal(t, []string{"claim_search", "claim_search", "claim_list", "txo_list", "txo_list"}, sdk.calls)
+}
+
+func TestHandle_DuplicateStreamedRequests(t *testing.T) {
+	config.Override("DuplicateRequests", map[string]interface{}{
+		"claim_search": map[string]interface{}{"Window": "1m", "Action": DuplicateActionCoalesce},
+	})
+	config.Override("ResponseStreamingThreshold", 2)
+	defer config.RestoreOverridden()
+	duplicates = newDedupStore()
+
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 994}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: sdk.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.Middleware(provider),
+	), Handle)
+
+	call := func(body string, envelope bool) map[string]interface{} {
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		r.Header.Set(wallet.TokenHeader, "abc")
+		if envelope {
+			r.Header.Set(ResponseFormatHeader, ResponseFormatEnvelope)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "\n", "response should be streamed")
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+		return res
+	}
+
+	first := call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1}, "id": 1}`, true)
+	assert.Equal(t, true, first["success"])
+	assert.NotContains(t, first, "jsonrpc")
+	assert.EqualValues(t, 1, first["data"].(map[string]interface{})["call"])
+
+	dup := call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1}, "id": 2}`, false)
+	assert.EqualValues(t, 2, dup["id"])
+	assert.Equal(t, first["data"], dup["result"])
+
+	dup = call(`{"jsonrpc": "2.0", "method": "claim_search", "params": {"page": 1}, "id": 3}`, true)
+	assert.Equal(t, true, dup["success"])
+	assert.Equal(t, first["data"], dup["data"])
+	assert.Equal(t, []string{"claim_search"}, sdk.calls)
+}
+
+func TestDedupStore_Window(t *testing.T) {
+	s := newDedupStore()
+	now := time.Now()
+
+	e, dup := s.check("k", time.Second, now)
+	assert.False(t, dup)
+	e2, dup := s.check("k", time.Second, now.Add(500*time.Millisecond))
+	assert.True(t, dup)
+	assert.Same(t, e, e2)
+
+	e3, dup := s.check("k", time.Second, now.Add(time.Second))
+	assert.False(t, dup)
+	assert.NotSame(t, e, e3)
+}
+
+func TestWithResponseID(t *testing.T) {
+	b := withResponseID([]byte(`{"jsonrpc": "2.0", "result": {"a": 12345678901234567890}, "id": 1}`), 7)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"a": 12345678901234567890}, "id": 7}`, string(b))
+	assert.Nil(t, withResponseID([]byte("not json"), 7))
+}
@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// Headers announcing deprecated methods, see RFC 8594 and the Deprecation HTTP header field draft.
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// WarningTypeDeprecated is the type of warnings added to results of deprecated methods.
+const WarningTypeDeprecated = "deprecated"
+
+const sunsetDateLayout = "2006-01-02"
+
+// deprecation returns deprecation settings of method, if it's deprecated.
+func deprecation(method string) (config.DeprecatedMethod, bool) {
+	d, ok := config.GetDeprecatedMethods()[strings.ToLower(method)]
+	return d, ok
+}
+
+func deprecationMessage(method string, d config.DeprecatedMethod) string {
+	msg := fmt.Sprintf("%v is deprecated", method)
+	if d.Sunset != "" {
+		msg += fmt.Sprintf(" and will be removed after %v", d.Sunset)
+	}
+	if d.Replacement != "" {
+		msg += fmt.Sprintf(", use %v instead", d.Replacement)
+	}
+	return msg
+}
+
+// setDeprecationHeaders announces the deprecation to the client and counts the call.
+func setDeprecationHeaders(w http.ResponseWriter, method string, d config.DeprecatedMethod) {
+	metrics.ProxyDeprecatedCalls.WithLabelValues(method).Inc()
+	w.Header().Set(DeprecationHeader, "true")
+	if d.Sunset == "" {
+		return
+	}
+	sunset, err := time.Parse(sunsetDateLayout, d.Sunset)
+	if err != nil {
+		logger.Log().Warnf("invalid sunset date of %v: %q", method, d.Sunset)
+		return
+	}
+	w.Header().Set(SunsetHeader, sunset.UTC().Format(http.TimeFormat))
+}
+
+// addDeprecationWarning returns a copy of a successful response with a deprecation warning added to the result.
+func addDeprecationWarning(r *jsonrpc.RPCResponse, method string, d config.DeprecatedMethod) *jsonrpc.RPCResponse {
+	return query.WithWarnings(r, query.Warning{Type: WarningTypeDeprecated, Message: deprecationMessage(method, d)})
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxyDeprecatedMethods(t *testing.T) {
+	config.Override("DeprecatedMethods", map[string]interface{}{
+		"Claim_Search": map[string]interface{}{"Sunset": "2021-09-01", "Replacement": "claim_search_v2"},
+		"version":      map[string]interface{}{},
+	})
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	c, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})),
+		cache.Middleware(c),
+	), Handle)
+
+	call := func(method string) (*httptest.ResponseRecorder, *jsonrpc.RPCResponse) {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"page": 1}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		return rr, &res
+	}
+	before := metrics.GetCounterValue(metrics.ProxyDeprecatedCalls.WithLabelValues(query.MethodClaimSearch))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": [], "warnings": ["b"]}, "id": 0}`
+	rr, res := call(query.MethodClaimSearch)
+	assert.Equal(t, "true", rr.Header().Get(DeprecationHeader))
+	assert.Equal(t, "Wed, 01 Sep 2021 00:00:00 GMT", rr.Header().Get(SunsetHeader))
+	assert.Equal(t, "generic, deprecated", rr.Header().Get(SDKWarningsHeader))
+	require.Nil(t, res.Error)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "generic", "message": "b"},
+		map[string]interface{}{
+			"type":    "deprecated",
+			"message": "claim_search is deprecated and will be removed after 2021-09-01, use claim_search_v2 instead",
+		},
+	}, res.Result.(map[string]interface{})[query.WarningsField])
+	c.Wait()
+
+	// The warning is not saved with the cached response, so it's not added twice
+	rr, res = call(query.MethodClaimSearch)
+	assert.Equal(t, "true", rr.Header().Get(DeprecationHeader))
+	assert.Len(t, res.Result.(map[string]interface{})[query.WarningsField], 2)
+	assert.Equal(t, 2.0, metrics.GetCounterValue(metrics.ProxyDeprecatedCalls.WithLabelValues(query.MethodClaimSearch))-before)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": "0.1.2", "id": 0}`
+	rr, res = call("version")
+	assert.Equal(t, "true", rr.Header().Get(DeprecationHeader))
+	assert.Empty(t, rr.Header().Get(SunsetHeader))
+	assert.Equal(t, "0.1.2", res.Result)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"is_running": true}, "id": 0}`
+	rr, res = call(query.MethodStatus)
+	assert.Empty(t, rr.Header().Get(DeprecationHeader))
+	assert.NotContains(t, res.Result, query.WarningsField)
+}
+
+func TestDeprecationMessage(t *testing.T) {
+	assert.Equal(t, "a is deprecated", deprecationMessage("a", config.DeprecatedMethod{}))
+	assert.Equal(t, "a is deprecated, use b instead", deprecationMessage("a", config.DeprecatedMethod{Replacement: "b"}))
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"bytes"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/internal/locale"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// localizeSerialized translates the error message or result warnings of a serialized JSON-RPC response.
+// b is returned as is if it's not a JSON-RPC response or there was nothing to translate.
+func localizeSerialized(b []byte, loc string) []byte {
+	var res *jsonrpc.RPCResponse
+	if err := responses.DecodeJSON(bytes.NewReader(b), &res); err != nil || res == nil {
+		return b
+	}
+	lres, ok := localizeResponse(res, loc)
+	if !ok {
+		return b
+	}
+	lb, err := responses.JSONRPCSerialize(lres)
+	if err != nil {
+		return b
+	}
+	return lb
+}
+
+// localizeResponse returns a copy of the response with its error message or result warnings translated,
+// and false if there was nothing to translate. The response may be cached, so it's never modified.
+func localizeResponse(res *jsonrpc.RPCResponse, loc string) (*jsonrpc.RPCResponse, bool) {
+	if res.Error != nil {
+		msg := locale.Translate(loc, res.Error.Message)
+		if msg == res.Error.Message {
+			return res, false
+		}
+		rpcErr := *res.Error
+		rpcErr.Message = msg
+		lres := *res
+		lres.Error = &rpcErr
+		return &lres, true
+	}
+	result, ok := res.Result.(map[string]interface{})
+	if !ok {
+		return res, false
+	}
+	warnings := query.ResponseWarnings(res)
+	translated := make([]query.Warning, len(warnings))
+	var changed bool
+	for i, w := range warnings {
+		translated[i] = w
+		if t := locale.Translate(loc, w.Message); t != w.Message {
+			translated[i].Message = t
+			changed = true
+		}
+	}
+	if !changed {
+		return res, false
+	}
+	newResult := make(map[string]interface{}, len(result))
+	for k, v := range result {
+		newResult[k] = v
+	}
+	newResult[query.WarningsField] = translated
+	lres := *res
+	lres.Result = newResult
+	return &lres, true
+}
@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/locale"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxyLocalizesMessages(t *testing.T) {
+	locale.SetTables(map[string]map[string]string{
+		"es": {
+			"couldn't find claim": "no se encontró el reclamo",
+			"stale result":        "resultado obsoleto",
+		},
+	})
+	defer locale.SetTables(nil)
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	handler := middleware.Apply(sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})), Handle)
+
+	call := func(acceptLanguage string) (*httptest.ResponseRecorder, *jsonrpc.RPCResponse) {
+		raw, err := json.Marshal(jsonrpc.NewRequest("version"))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		r.Header.Set("Accept-Language", acceptLanguage)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+		return rr, &res
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "couldn't find claim"}, "id": 0}`
+	rr, res := call("es-MX,es;q=0.9,en;q=0.5")
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "no se encontró el reclamo", res.Error.Message)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "couldn't find claim"}, "id": 0}`
+	_, res = call("en-US,es;q=0.9")
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "couldn't find claim", res.Error.Message)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"warnings": ["stale result", "other"]}, "id": 0}`
+	_, res = call("es")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "generic", "message": "resultado obsoleto"},
+		map[string]interface{}{"type": "generic", "message": "other"},
+	}, res.Result.(map[string]interface{})["warnings"])
+
+	// Unsupported locales fall back to English
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"warnings": ["stale result"]}, "id": 0}`
+	_, res = call("de")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "generic", "message": "stale result"},
+	}, res.Result.(map[string]interface{})["warnings"])
+}
+
+func TestLocalizeResponse(t *testing.T) {
+	locale.SetTables(map[string]map[string]string{"es": {"a": "b"}})
+	defer locale.SetTables(nil)
+
+	res := &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "a"}}
+	lres, ok := localizeResponse(res, "es")
+	assert.True(t, ok)
+	assert.Equal(t, "b", lres.Error.Message)
+	assert.Equal(t, "a", res.Error.Message, "original response must stay intact")
+
+	res = &jsonrpc.RPCResponse{Result: map[string]interface{}{
+		"warnings": []query.Warning{{Type: query.WarningTypeGeneric, Message: "a"}},
+	}}
+	lres, ok = localizeResponse(res, "es")
+	assert.True(t, ok)
+	assert.Equal(t, []query.Warning{{Type: query.WarningTypeGeneric, Message: "b"}}, lres.Result.(map[string]interface{})["warnings"])
+	assert.Equal(t, "a", res.Result.(map[string]interface{})["warnings"].([]query.Warning)[0].Message)
+
+	_, ok = localizeResponse(&jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "c"}}, "es")
+	assert.False(t, ok)
+	_, ok = localizeResponse(&jsonrpc.RPCResponse{Result: "a"}, "es")
+	assert.False(t, ok)
+	_, ok = localizeResponse(&jsonrpc.RPCResponse{Result: map[string]interface{}{"warnings": []interface{}{"c"}}}, "es")
+	assert.False(t, ok)
+}
+
+func TestProxyLocalizesStreamedEnvelope(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	config.Override("ResponseStreamingThreshold", 2)
+	defer config.RestoreOverridden()
+	locale.SetTables(map[string]map[string]string{"es": {"stale result": "resultado obsoleto"}})
+	defer locale.SetTables(nil)
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	handler := middleware.Apply(sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})), Handle)
+
+	raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+	require.NoError(t, err)
+	r.Header.Set("Accept-Language", "es")
+	r.Header.Set(ResponseFormatHeader, ResponseFormatEnvelope)
+	rr := httptest.NewRecorder()
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {"name": "what", "tags": ["a", "b"]}, "warnings": ["stale result"]}, "id": 0}`
+	handler.ServeHTTP(rr, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "\n", "response should be streamed")
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &env), rr.Body.String())
+	assert.Equal(t, true, env["success"])
+	assert.NotContains(t, env, "jsonrpc")
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "generic", "message": "resultado obsoleto"},
+	}, data["warnings"])
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/responses"
+)
+
+// MethodInfo describes how a method is handled by the proxy.
+type MethodInfo struct {
+	Name string `json:"name"`
+	// RequiresWallet is true when the method can only be called by signed in users.
+	RequiresWallet bool `json:"requires_wallet"`
+	// AcceptsWallet is true when the method is called with the wallet of a signed in user.
+	AcceptsWallet bool `json:"accepts_wallet"`
+	Cacheable     bool `json:"cacheable"`
+	RateLimited   bool `json:"rate_limited"`
+}
+
+// MethodsDocument is the response of HandleMethods.
+type MethodsDocument struct {
+	Methods []MethodInfo `json:"methods"`
+}
+
+// methodsDocCache keeps the last generated discovery document along with the config it was generated from,
+// so it's only regenerated when that config changes.
+type methodsDocCache struct {
+	mu   sync.Mutex
+	key  string
+	body []byte
+}
+
+var methodsDoc = &methodsDocCache{}
+
+// methodsDocInputs is the runtime config the discovery document depends on.
+type methodsDocInputs struct {
+	Cacheable  map[string]bool
+	RateLimits config.RateLimits
+	Hidden     []string
+}
+
+func currentMethodsDocInputs() methodsDocInputs {
+	cacheable := map[string]bool{}
+	for m := range config.GetCacheableMethods() {
+		cacheable[strings.ToLower(m)] = true
+	}
+	return methodsDocInputs{
+		Cacheable:  cacheable,
+		RateLimits: config.GetRateLimits(),
+		Hidden:     config.GetHiddenMethods(),
+	}
+}
+
+// buildMethodsDocument lists supported methods which aren't hidden, with their properties under the given config.
+func buildMethodsDocument(in methodsDocInputs) MethodsDocument {
+	hidden := map[string]bool{}
+	for _, m := range in.Hidden {
+		hidden[strings.ToLower(m)] = true
+	}
+	doc := MethodsDocument{Methods: []MethodInfo{}}
+	for _, m := range query.SupportedMethods() {
+		if hidden[m] {
+			continue
+		}
+		limit, ok := in.RateLimits.Methods[m]
+		if !ok {
+			limit = in.RateLimits.Default
+		}
+		doc.Methods = append(doc.Methods, MethodInfo{
+			Name:           m,
+			RequiresWallet: query.MethodRequiresWallet(m, nil),
+			AcceptsWallet:  query.MethodAcceptsWallet(m),
+			Cacheable:      in.Cacheable[m],
+			RateLimited:    in.RateLimits.Enabled && limit.Burst > 0,
+		})
+	}
+	return doc
+}
+
+// get returns the discovery document for the current config, regenerating it if the config has changed.
+func (c *methodsDocCache) get() []byte {
+	in := currentMethodsDocInputs()
+	key, _ := json.Marshal(in)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.body != nil && c.key == string(key) {
+		return c.body
+	}
+	c.body, _ = json.Marshal(buildMethodsDocument(in))
+	c.key = string(key)
+	return c.body
+}
+
+// HandleMethods serves the method discovery document, listing methods which can be called through the proxy
+// and whether they take a wallet, are cached and rate limited.
+func HandleMethods(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	w.Write(methodsDoc.get())
+}
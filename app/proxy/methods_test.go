@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getMethods(t *testing.T) map[string]MethodInfo {
+	rr := httptest.NewRecorder()
+	HandleMethods(rr, httptest.NewRequest(http.MethodGet, "/api/v1/methods", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var doc MethodsDocument
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	methods := map[string]MethodInfo{}
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+	}
+	return methods
+}
+
+func TestHandleMethods(t *testing.T) {
+	config.Override("CacheableMethods", map[string]string{"resolve": "3m"})
+	config.Override("RateLimits", map[string]interface{}{
+		"Enabled": true,
+		"Default": map[string]interface{}{"Rate": 1, "Burst": 0},
+		"Methods": map[string]interface{}{"Claim_Search": map[string]interface{}{"Rate": 1, "Burst": 5}},
+	})
+	config.Override("HiddenMethods", []string{"wallet_encrypt"})
+	defer config.RestoreOverridden()
+
+	methods := getMethods(t)
+	assert.Len(t, methods, len(query.SupportedMethods())-1)
+	assert.NotContains(t, methods, "wallet_encrypt")
+	for name, m := range methods {
+		assert.Equal(t, query.MethodRequiresWallet(name, nil), m.RequiresWallet, name)
+		assert.Equal(t, query.MethodAcceptsWallet(name), m.AcceptsWallet, name)
+	}
+
+	assert.Equal(t, MethodInfo{
+		Name: "resolve", RequiresWallet: false, AcceptsWallet: true, Cacheable: true, RateLimited: false,
+	}, methods["resolve"])
+	assert.Equal(t, MethodInfo{
+		Name: "claim_search", RequiresWallet: false, AcceptsWallet: true, Cacheable: false, RateLimited: true,
+	}, methods["claim_search"])
+	assert.Equal(t, MethodInfo{
+		Name: "wallet_balance", RequiresWallet: true, AcceptsWallet: true, Cacheable: false, RateLimited: false,
+	}, methods["wallet_balance"])
+	assert.Equal(t, MethodInfo{
+		Name: "version", RequiresWallet: false, AcceptsWallet: false, Cacheable: false, RateLimited: false,
+	}, methods["version"])
+}
+
+func TestHandleMethodsConfigChange(t *testing.T) {
+	config.Override("CacheableMethods", map[string]string{})
+	config.Override("RateLimits", map[string]interface{}{"Enabled": false})
+	defer config.RestoreOverridden()
+
+	methods := getMethods(t)
+	assert.False(t, methods["claim_search"].Cacheable)
+	assert.False(t, methods["claim_search"].RateLimited)
+	cached := methodsDoc.get()
+	assert.Same(t, &cached[0], &methodsDoc.get()[0])
+
+	config.RestoreOverridden()
+	config.Override("CacheableMethods", map[string]string{"claim_search": "1m"})
+	config.Override("RateLimits", map[string]interface{}{
+		"Enabled": true, "Default": map[string]interface{}{"Rate": 1, "Burst": 10},
+	})
+	methods = getMethods(t)
+	assert.True(t, methods["claim_search"].Cacheable)
+	assert.True(t, methods["claim_search"].RateLimited)
+	assert.True(t, methods["wallet_balance"].RateLimited)
+}
@@ -7,10 +7,12 @@ package proxy
 // remote clients.
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/lbryio/lbrytv/app/auth"
@@ -18,21 +20,74 @@ import (
 	"github.com/lbryio/lbrytv/app/query/cache"
 	"github.com/lbryio/lbrytv/app/rpcerrors"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/app/wallet/deadletter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
 	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/canary"
+	"github.com/lbryio/lbrytv/internal/cdnrewrite"
+	"github.com/lbryio/lbrytv/internal/clientinfo"
+	"github.com/lbryio/lbrytv/internal/entitlements"
 	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/geoblock"
 	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/killswitch"
 	"github.com/lbryio/lbrytv/internal/lbrynext"
+	"github.com/lbryio/lbrytv/internal/locale"
+	"github.com/lbryio/lbrytv/internal/maintenance"
 	"github.com/lbryio/lbrytv/internal/metrics"
 	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/internal/readmodel"
 	"github.com/lbryio/lbrytv/internal/responses"
+	"github.com/lbryio/lbrytv/internal/rules"
+	"github.com/lbryio/lbrytv/internal/sampling"
+	"github.com/lbryio/lbrytv/internal/scheduler"
 	"github.com/lbryio/lbrytv/models"
 	"github.com/sirupsen/logrus"
 
+	"github.com/volatiletech/sqlboiler/boil"
 	"github.com/ybbus/jsonrpc"
 )
 
 var logger = monitor.NewModuleLogger("proxy")
 
+// sdkScheduler keeps interactive queries responsive when SDK capacity is constrained.
+var sdkScheduler = scheduler.New(config.GetSchedulerConcurrency(), config.GetSchedulerAging())
+
+var maintenanceMode = maintenance.NewSwitch(config.IsMaintenanceMode, func(on bool) {
+	if on {
+		logger.Log().Warn("entering maintenance mode")
+	} else {
+		logger.Log().Warn("leaving maintenance mode")
+	}
+})
+
+// CacheBypassHeader can be set by admins to get a fresh SDK response instead of a cached one.
+// Cache-Control: no-cache request header has the same effect.
+const CacheBypassHeader = "X-Bypass-Cache"
+
+// ResponseFormatHeader set to ResponseFormatEnvelope makes the proxy wrap responses in responses.Envelope
+// instead of returning them in JSON-RPC format. Requests to HandleEnvelope get the same.
+const (
+	ResponseFormatHeader   = "X-Response-Format"
+	ResponseFormatEnvelope = "envelope"
+)
+
+// SDKWarningsHeader lists types of warnings SDK has returned along with a successful result,
+// the warnings themselves are in the "warnings" field of the result (see query.Warning).
+const SDKWarningsHeader = "X-SDK-Warnings"
+
+// RequestIDHeader identifies the request, it's generated when the client or the load balancer doesn't supply one
+// and is sent back with the response.
+const RequestIDHeader = "X-Request-Id"
+
+// TraceParentHeader carries W3C trace context of the request. Its trace ID is attached to exemplars
+// of latency metrics for slow calls, the request ID is used instead if it's missing.
+const TraceParentHeader = "traceparent"
+
+// DegradedResponseHeader is set on best-effort responses given when the SDK is unable to answer (see query.DegradedHandler).
+const DegradedResponseHeader = "X-Degraded-Response"
+
 const (
 	orgOdysee  = "odysee"
 	orgLbrytv  = "lbrytv"
@@ -40,34 +95,197 @@ const (
 	orgiOS     = "ios"
 )
 
-// observeFailure requires metrics.MeasureMiddleware middleware to be present on the request
-func observeFailure(d float64, method, kind string) {
-	metrics.ProxyE2ECallDurations.WithLabelValues(method).Observe(d)
-	metrics.ProxyE2ECallFailedDurations.WithLabelValues(method, kind).Observe(d)
-	metrics.ProxyE2ECallCounter.WithLabelValues(method).Inc()
-	metrics.ProxyE2ECallFailedCounter.WithLabelValues(method, kind).Inc()
+// callObserver records end-to-end metrics for a proxied call. Every call should be observed exactly once,
+// observations after the first one are ignored.
+// It requires metrics.MeasureMiddleware middleware to be present on the request.
+// Body sizes are recorded when the call finishes: the request body size once it's been read
+// and the size of the response written to the client.
+type callObserver struct {
+	r        *http.Request
+	method   string
+	observed bool
+	// traceID links slow calls to their traces in exemplars of latency metrics.
+	traceID string
+
+	requestSize int
+	response    *compressingWriter
+}
+
+func newCallObserver(r *http.Request, response *compressingWriter) *callObserver {
+	return &callObserver{r: r, requestSize: -1, response: response}
 }
 
-// observeSuccess requires metrics.MeasureMiddleware middleware to be present on the request
-func observeSuccess(d float64, method string) {
-	metrics.ProxyE2ECallDurations.WithLabelValues(method).Observe(d)
-	metrics.ProxyE2ECallCounter.WithLabelValues(method).Inc()
+func (o *callObserver) failure(kind string) {
+	if o.observe() {
+		d := metrics.GetDuration(o.r)
+		metrics.ObserveWithTrace(metrics.ProxyE2ECallDurations.WithLabelValues(o.method), d, o.traceID)
+		metrics.ObserveWithTrace(metrics.ProxyE2ECallFailedDurations.WithLabelValues(o.method, kind), d, o.traceID)
+		metrics.ProxyE2ECallCounter.WithLabelValues(o.method).Inc()
+		metrics.ProxyE2ECallFailedCounter.WithLabelValues(o.method, kind).Inc()
+		metrics.ProxyE2ECallErrorRate.Observe(o.method, true, config.GetErrorRateWindow())
+	}
+}
+
+func (o *callObserver) success() {
+	if o.observe() {
+		metrics.ObserveWithTrace(metrics.ProxyE2ECallDurations.WithLabelValues(o.method), metrics.GetDuration(o.r), o.traceID)
+		metrics.ProxyE2ECallCounter.WithLabelValues(o.method).Inc()
+		metrics.ProxyE2ECallErrorRate.Observe(o.method, false, config.GetErrorRateWindow())
+	}
+}
+
+// finish should be deferred, it records the call as dropped if it hasn't been observed by then,
+// which means a code path is missing its observation or the handler has panicked.
+func (o *callObserver) finish() {
+	if !o.observed {
+		logger.Log().Warnf("call to %q finished without being observed", o.method)
+		o.failure(metrics.FailureKindDropped)
+	}
+	if o.requestSize >= 0 {
+		metrics.ProxyE2ERequestSizes.WithLabelValues(o.method).Observe(float64(o.requestSize))
+	}
+	if o.response != nil {
+		metrics.ProxyE2EResponseSizes.WithLabelValues(o.method).Observe(float64(o.response.written))
+	}
+}
+
+func (o *callObserver) observe() bool {
+	if o.observed {
+		logger.Log().Warnf("call to %q has already been observed", o.method)
+		return false
+	}
+	o.observed = true
+	return true
+}
+
+func setWarningsHeader(w http.ResponseWriter, r *jsonrpc.RPCResponse) {
+	warnings := query.ResponseWarnings(r)
+	if len(warnings) == 0 {
+		return
+	}
+	types := []string{}
+	seen := map[string]bool{}
+	for _, wr := range warnings {
+		if !seen[wr.Type] {
+			seen[wr.Type] = true
+			types = append(types, wr.Type)
+		}
+	}
+	w.Header().Set(SDKWarningsHeader, strings.Join(types, ", "))
 }
 
 func writeResponse(w http.ResponseWriter, b []byte) {
 	w.Write(b)
 }
 
+// unmarshalRequest decodes a JSON-RPC request, rejecting unknown and repeated envelope fields with StrictRequestJSON on.
+func unmarshalRequest(data []byte, v interface{}) error {
+	if config.IsStrictRequestJSON() {
+		return responses.UnmarshalJSONStrict(data, v)
+	}
+	return responses.UnmarshalJSON(data, v)
+}
+
+// traceID returns the ID of the trace the request is part of, taken from its W3C traceparent header,
+// or requestID if it doesn't have a valid one.
+func traceID(r *http.Request, requestID string) string {
+	parts := strings.Split(r.Header.Get(TraceParentHeader), "-")
+	if len(parts) == 4 && len(parts[1]) == 32 {
+		if _, err := hex.DecodeString(parts[1]); err == nil {
+			return parts[1]
+		}
+	}
+	return requestID
+}
+
+// newRequestID generates a random request identifier.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// redactRequest returns a copy of req with sensitive params masked, for error reports.
+func redactRequest(req *jsonrpc.RPCRequest) jsonrpc.RPCRequest {
+	redacted := *req
+	redacted.Params = monitor.Redact(req.Params)
+	return redacted
+}
+
+// redactResponse returns a copy of res with sensitive fields of the result masked, for error reports.
+func redactResponse(res *jsonrpc.RPCResponse) *jsonrpc.RPCResponse {
+	if res == nil {
+		return nil
+	}
+	redacted := *res
+	redacted.Result = monitor.Redact(res.Result)
+	return &redacted
+}
+
+// HandleEnvelope is like Handle but always responds in envelope format.
+func HandleEnvelope(w http.ResponseWriter, r *http.Request) {
+	r = r.Clone(r.Context())
+	r.Header.Set(ResponseFormatHeader, ResponseFormatEnvelope)
+	Handle(w, r)
+}
+
 // Handle forwards client JSON-RPC request to proxy.
 func Handle(w http.ResponseWriter, r *http.Request) {
+	// Compression goes under the envelope so it applies to what's actually sent
+	cw := newCompressingWriter(w, r)
+	defer cw.Close()
+	w = cw
+	if strings.EqualFold(r.Header.Get(ResponseFormatHeader), ResponseFormatEnvelope) {
+		w = responses.NewEnvelopeWriter(w)
+	}
+	if locale.Available() {
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	loc := locale.FromHeader(r.Header.Get("Accept-Language"))
+	r = locale.AddToRequest(r, loc)
 	responses.AddJSONContentType(w)
+	setNoStore(w)
+	obs := newCallObserver(r, cw)
+	defer obs.finish()
+
+	requestID := r.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	w.Header().Set(RequestIDHeader, requestID)
+	obs.traceID = traceID(r, requestID)
+
 	origin := getDevice(r)
+	client := clientinfo.FromRequest(r)
+	r = clientinfo.AddToRequest(r, client)
+	metrics.ProxyClientCallCounter.WithLabelValues(client.App, client.VersionBucket()).Inc()
+
+	if maintenanceMode.IsOn() && !auth.IsAdmin(r) {
+		retryAfter := config.GetMaintenanceRetryAfter()
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		writeResponse(w, rpcerrors.NewMaintenanceError().JSON())
+
+		obs.failure(metrics.FailureKindMaintenance)
+		return
+	}
+
+	service, err := auth.ServiceFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeResponse(w, rpcerrors.NewForbiddenError(err).JSON())
+
+		obs.failure(metrics.FailureKindAuth)
+		return
+	}
 
 	if r.Body == nil {
 		w.WriteHeader(http.StatusBadRequest)
 		writeResponse(w, rpcerrors.NewJSONParseError(errors.Err("empty request body")).JSON())
 
-		observeFailure(metrics.GetDuration(r), "", metrics.FailureKindClient)
+		obs.failure(metrics.FailureKindClient)
 		logger.Log().Debugf("empty request body")
 		return
 	}
@@ -77,23 +295,52 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		writeResponse(w, rpcerrors.NewJSONParseError(errors.Err("error reading request body")).JSON())
 
-		observeFailure(metrics.GetDuration(r), "", metrics.FailureKindClient)
+		obs.failure(metrics.FailureKindClient)
 		logger.Log().Debugf("error reading request body: %v", err.Error())
 
 		return
 	}
+	obs.requestSize = len(body)
 
 	var rpcReq *jsonrpc.RPCRequest
-	err = json.Unmarshal(body, &rpcReq)
+	err = unmarshalRequest(body, &rpcReq)
 	if err != nil {
 		writeResponse(w, rpcerrors.NewJSONParseError(err).JSON())
 
-		observeFailure(metrics.GetDuration(r), "", metrics.FailureKindClientJSON)
+		obs.failure(metrics.FailureKindClientJSON)
 		logger.Log().Debugf("error unmarshaling request body: %v", err)
 
 		return
 	}
 
+	if rpcReq == nil {
+		writeResponse(w, rpcerrors.NewJSONParseError(errors.Err("empty request")).JSON())
+
+		obs.failure(metrics.FailureKindClientJSON)
+		logger.Log().Debugf("request body is null")
+
+		return
+	}
+	// Deprecation concerns the name the client has called, everything else goes by the current one
+	dep, deprecated := deprecation(rpcReq.Method)
+	if deprecated {
+		setDeprecationHeaders(w, rpcReq.Method, dep)
+	}
+	if m, ok := query.ResolveAlias(rpcReq.Method); ok {
+		metrics.ProxyAliasedCalls.WithLabelValues(rpcReq.Method).Inc()
+		logger.Log().Debugf("method %v is an alias of %v", rpcReq.Method, m)
+		rpcReq.Method = m
+	}
+	obs.method = rpcReq.Method
+	cw.method = rpcReq.Method
+
+	version, ok := negotiateVersion(w, r, rpcReq.Method)
+	if !ok {
+		obs.failure(metrics.FailureKindClient)
+		logger.Log().Debugf("unsupported response version of %v requested: %q", rpcReq.Method, r.Header.Get(AcceptVersionHeader))
+		return
+	}
+
 	logger.Log().Tracef("call to method %s", rpcReq.Method)
 
 	user, err := auth.FromRequest(r)
@@ -101,12 +348,32 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		authErr := GetAuthError(user, err)
 		if authErr != nil {
 			writeResponse(w, rpcerrors.ErrorToJSON(authErr))
-			observeFailure(metrics.GetDuration(r), rpcReq.Method, metrics.FailureKindAuth)
+			obs.failure(metrics.FailureKindAuth)
 
 			return
 		}
 	}
 
+	// Duplicates are answered before rate limiting so a double-submit doesn't cost the client twice
+	w, finishDedup, handled := dedupe(w, r, user, rpcReq, obs)
+	if handled {
+		return
+	}
+	defer finishDedup()
+
+	if !allowCall(w, r, user, rpcReq.Method) {
+		obs.failure(metrics.FailureKindRateLimited)
+		logger.Log().Debugf("throttled call to %v", rpcReq.Method)
+		return
+	}
+	uc, ok := allowUserCall(w, r, user, rpcReq.Method)
+	if !ok {
+		obs.failure(metrics.FailureKindUserCircuitOpen)
+		logger.Log().Debugf("refused call to %v of user %v with open circuit breaker", rpcReq.Method, user.ID)
+		return
+	}
+	defer uc.release()
+
 	var userID int
 	if query.MethodAcceptsWallet(rpcReq.Method) && user != nil {
 		userID = user.ID
@@ -115,7 +382,20 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	sdkAddress := sdkrouter.GetSDKAddress(user)
 	if sdkAddress == "" {
 		rt := sdkrouter.FromRequest(r)
-		sdkAddress = rt.RandomServer().Address
+		if sdkAddress = canary.SDKServer(r, config.GetCanary(), rt); sdkAddress == "" {
+			sdkAddress = rt.ServerFor(rpcReq.Method, query.AffinityKey(rpcReq, userID)).Address
+		}
+	}
+
+	if userID != 0 && query.IsWalletMutation(rpcReq.Method) {
+		release, err := wallet.Lock(userID)
+		if err != nil {
+			writeResponse(w, rpcerrors.NewWalletBusyError(err).JSON())
+			obs.failure(metrics.FailureKindWalletBusy)
+			logger.Log().Infof("rejected concurrent %v of user %v", rpcReq.Method, userID)
+			return
+		}
+		defer release()
 	}
 
 	var qCache *cache.Cache
@@ -123,79 +403,233 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		qCache = cache.FromRequest(r)
 	}
 	c := query.NewCaller(sdkAddress, userID)
+	c.PayloadSize = int64(len(body))
 
 	remoteIP := ip.FromRequest(r)
+	c.Request = query.RequestInfo{User: user, RemoteIP: remoteIP, Origin: origin, RequestID: requestID}
 	// Logging remote IP with query
 	c.AddPostflightHook("wallet_", func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
-		hctx.AddLogField("remote_ip", remoteIP)
+		hctx.AddLogField("remote_ip", hctx.RemoteIP)
 		return nil, nil
 	}, "")
 	c.AddPostflightHook(query.MethodWalletSend, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
-		audit.LogQuery(userID, remoteIP, query.MethodWalletSend, body)
+		audit.LogQuery(userID, remoteIP, query.MethodWalletSend, body, audit.OutcomeFromResponse(hctx.Response))
 		return nil, nil
 	}, "")
 
+	if service != nil {
+		c.AddPostflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+			hctx.AddLogField("service", service.Name)
+			return nil, nil
+		}, "")
+	}
+
+	rules.InstallHook(c)
+	killswitch.InstallHook(c, origin)
+	geoblock.InstallHook(c, remoteIP, userID, body)
+	entitlements.InstallHook(c, userID)
+	sampling.InstallHook(c)
+	readmodel.InstallHook(c)
 	lbrynext.InstallHooks(c)
 	c.Cache = qCache
+	c.Client = client
+	if sdkrouter.IsOnRequest(r) {
+		c.Router = sdkrouter.FromRequest(r)
+	}
+	c.Scheduler = sdkScheduler
+	c.QueueTimeout = config.GetSchedulerQueueTimeout()
+	c.Headers = query.ForwardedHeaders(r)
+	if dm := config.GetClaimSearchDegradedMode(); dm.Enabled {
+		c.SetDegradedHandler(query.MethodClaimSearch, dm.Timeout, query.ReducedClaimSearch(dm.PageSize))
+	}
+	c.BypassCache = cacheBypassRequested(r) && canBypassCache(r, remoteIP)
+	c.CacheDirective = cacheDirective(r)
 
 	rpcRes, err := c.Call(rpcReq)
-	metrics.ProxyCallDurations.WithLabelValues(rpcReq.Method, c.Endpoint(), origin).Observe(c.Duration)
+	setSDKNodeHeader(w, r, c.ServedBy())
+	setBackpressure(w, sdkScheduler)
+	metrics.ObserveWithTrace(metrics.ProxyCallDurations.WithLabelValues(rpcReq.Method, c.Endpoint(), origin), c.Duration, obs.traceID)
 	metrics.ProxyCallCounter.WithLabelValues(rpcReq.Method, c.Endpoint(), origin).Inc()
 
 	if err != nil {
-		monitor.ErrorToSentry(err, map[string]string{"request": fmt.Sprintf("%+v", rpcReq), "response": fmt.Sprintf("%+v", rpcRes)})
-		writeResponse(w, rpcerrors.ToJSON(err))
+		if rpcerrors.IsClientError(err) {
+			uc.failure()
+		}
+		monitor.ErrorToSentry(err, map[string]string{"request": fmt.Sprintf("%+v", redactRequest(rpcReq)), "response": fmt.Sprintf("%+v", redactResponse(rpcRes))})
+		if queued := queueForRetry(userID, rpcReq, err); queued != nil {
+			writeResponse(w, queued)
+		} else {
+			writeResponse(w, localizeSerialized(rpcerrors.ToJSON(err), loc))
+		}
 
-		logger.Log().Errorf("error calling lbrynet: %v, request: %+v", err, rpcReq)
-		observeFailure(metrics.GetDuration(r), rpcReq.Method, metrics.FailureKindNet)
-		metrics.ProxyCallFailedDurations.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, metrics.FailureKindNet).Observe(c.Duration)
-		metrics.ProxyCallFailedCounter.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, metrics.FailureKindNet).Inc()
+		logger.WithFields(logrus.Fields{"endpoint": c.ServedBy()}).Errorf("error calling lbrynet: %v, request: %+v", err, rpcReq)
+		kind := metrics.FailureKindNet
+		if rpcerrors.IsUpstreamInvalid(err) {
+			kind = metrics.FailureKindUpstreamInvalid
+		}
+		obs.failure(kind)
+		metrics.ProxyCallFailedDurations.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, kind).Observe(c.Duration)
+		metrics.ProxyCallFailedCounter.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, kind).Inc()
 		return
 	}
 
-	serialized, err := responses.JSONRPCSerialize(rpcRes)
-	if err != nil {
-		monitor.ErrorToSentry(err)
+	rpcRes = cdnrewrite.Response(rpcRes, remoteIP)
+	rpcRes = query.VersionResponse(rpcRes, rpcReq.Method, version)
+	if deprecated {
+		rpcRes = addDeprecationWarning(rpcRes, rpcReq.Method, dep)
+	}
+	// Localized before serializing so streamed responses get translated too
+	rpcRes, _ = localizeResponse(rpcRes, loc)
 
-		writeResponse(w, rpcerrors.NewInternalError(err).JSON())
+	// Large responses are streamed to the client as they're encoded instead of being serialized upfront
+	stream := responses.IsLarge(rpcRes, config.GetResponseStreamingThreshold())
+	var serialized []byte
+	if !stream {
+		serialized, err = responses.JSONRPCSerialize(rpcRes)
+		if err != nil {
+			monitor.ErrorToSentry(err)
 
-		logger.Log().Errorf("error marshaling response: %v", err)
-		observeFailure(metrics.GetDuration(r), rpcReq.Method, metrics.FailureKindRPCJSON)
+			writeResponse(w, rpcerrors.NewInternalError(err).JSON())
 
-		return
+			logger.Log().Errorf("error marshaling response: %v", err)
+			obs.failure(metrics.FailureKindRPCJSON)
+
+			return
+		}
 	}
 
+	setCacheInfo(w, r, c.CacheInfo)
+
 	if rpcRes.Error != nil {
-		observeFailure(metrics.GetDuration(r), rpcReq.Method, metrics.FailureKindRPC)
+		// SDK and wallet failures are not the user's fault, the call is released without an outcome
+		if rpcerrors.IsClientErrorCode(rpcRes.Error.Code) {
+			uc.failure()
+		}
+		obs.failure(metrics.FailureKindRPC)
 		metrics.ProxyCallFailedDurations.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, metrics.FailureKindRPC).Observe(c.Duration)
 		metrics.ProxyCallFailedCounter.WithLabelValues(rpcReq.Method, c.Endpoint(), origin, metrics.FailureKindRPC).Inc()
 
 		logger.WithFields(logrus.Fields{
 			"method":   rpcReq.Method,
-			"endpoint": sdkAddress,
+			"endpoint": c.Endpoint(),
 			"response": rpcRes.Error,
 		}).Errorf("proxy handler got rpc error: %v", rpcRes.Error)
 	} else {
-		observeSuccess(metrics.GetDuration(r), rpcReq.Method)
+		uc.success()
+		obs.success()
+		if query.IsDegraded(rpcRes) {
+			w.Header().Set(DegradedResponseHeader, "true")
+		} else {
+			setCachePolicy(w, rpcReq.Method, userID)
+		}
+		setWarningsHeader(w, rpcRes)
+		chargeRequest(w, user, rpcReq.Method)
 	}
 
+	if stream {
+		cw.streamed = true
+		recordStreamed(w, rpcRes)
+		streamResponse(w, rpcRes)
+		return
+	}
 	writeResponse(w, serialized)
 }
 
-func GetAuthError(user *models.User, err error) error {
-	if err == nil && user != nil {
+// streamResponse writes a large response without buffering it. Encoding errors can only be reported
+// to the client if nothing has been sent yet, otherwise the client gets a truncated response.
+func streamResponse(w http.ResponseWriter, r *jsonrpc.RPCResponse) {
+	flushed, err := responses.StreamJSONRPC(w, r)
+	if err == nil {
+		return
+	}
+	monitor.ErrorToSentry(err)
+	logger.Log().Errorf("error streaming response (flushed: %v): %v", flushed, err)
+	if !flushed {
+		writeResponse(w, rpcerrors.NewInternalError(err).JSON())
+	}
+}
+
+// queueForRetry stores wallet operations which couldn't reach the SDK for retrying later (see DeadLetterMethods config)
+// and returns a response telling the client so. It returns nil if the operation is not eligible for retrying.
+func queueForRetry(userID int, rpcReq *jsonrpc.RPCRequest, err error) []byte {
+	if userID == 0 || !deadletter.IsTransient(err) || !isDeadLetterMethod(rpcReq.Method) {
 		return nil
 	}
+	l, qerr := deadletter.Enqueue(boil.GetDB(), userID, rpcReq, err, config.GetDeadLetterRetryInterval())
+	if qerr != nil {
+		logger.Log().Errorf("cannot queue %v for retrying: %v", rpcReq.Method, qerr)
+		return nil
+	}
+	res, serr := responses.JSONRPCSerialize(&jsonrpc.RPCResponse{
+		Error: &jsonrpc.RPCError{
+			Code:    rpcerrors.NewQueuedError(nil).Code(),
+			Message: "wallet server is unavailable, operation has been queued for retrying",
+			Data:    map[string]int{"dead_letter_id": l.ID},
+		},
+		JSONRPC: "2.0",
+		ID:      rpcReq.ID,
+	})
+	if serr != nil {
+		return nil
+	}
+	return res
+}
+
+func isDeadLetterMethod(method string) bool {
+	for _, m := range config.GetDeadLetterMethods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
 
-	if errors.Is(err, auth.ErrNoAuthInfo) {
+// cacheBypassRequested checks if the client is asking for a fresh response from the SDK.
+func cacheBypassRequested(r *http.Request) bool {
+	if v := strings.ToLower(r.Header.Get(CacheBypassHeader)); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	for _, d := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		if strings.ToLower(strings.TrimSpace(d)) == "no-cache" {
+			return true
+		}
+	}
+	return false
+}
+
+// canBypassCache limits cache bypassing to admins and allowlisted IPs
+// so regular clients cannot push their load directly onto the SDK.
+func canBypassCache(r *http.Request, remoteIP string) bool {
+	if auth.IsAdmin(r) {
+		return true
+	}
+	if remoteIP != "" {
+		for _, a := range config.GetCacheBypassAllowlist() {
+			if a == remoteIP {
+				return true
+			}
+		}
+	}
+	logger.Log().Debugf("cache bypass requested from %v but not allowed", remoteIP)
+	return false
+}
+
+// GetAuthError returns an error telling the client to sign in, refresh its token or that the token can't be used,
+// depending on the auth token state. It returns nil if the user is authenticated.
+func GetAuthError(user *models.User, err error) error {
+	switch auth.GetTokenState(user, err) {
+	case auth.TokenValid:
+		return nil
+	case auth.TokenAbsent:
 		return rpcerrors.NewAuthRequiredError()
-	} else if err != nil {
-		return rpcerrors.NewForbiddenError(err)
-	} else if user == nil {
-		return rpcerrors.NewForbiddenError(errors.Err("must authenticate"))
+	case auth.TokenExpired:
+		return rpcerrors.NewSessionExpiredError(err)
 	}
 
-	return errors.Err("unknown auth error")
+	if err != nil {
+		return rpcerrors.NewForbiddenError(err)
+	}
+	return rpcerrors.NewForbiddenError(errors.Err("must authenticate"))
 }
 
 func getDevice(r *http.Request) string {
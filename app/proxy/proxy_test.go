@@ -5,14 +5,26 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/canary"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/metrics"
 	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/ybbus/jsonrpc"
@@ -47,6 +59,43 @@ func TestProxyInvalidQuery(t *testing.T) {
 	assert.Contains(t, parsedResponse.Error.Message, "invalid character 'y' looking for beginning of value")
 }
 
+func TestProxyStrictRequestJSON(t *testing.T) {
+	defer config.RestoreOverridden()
+	call := func(body string) *jsonrpc.RPCResponse {
+		srv := test.MockHTTPServer(nil)
+		defer srv.Close()
+		srv.QueueResponses(`{"jsonrpc": "2.0", "result": {"ok": true}, "id": 0}`)
+
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL}))(http.HandlerFunc(Handle)).ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		return &res
+	}
+	duplicate := `{"jsonrpc": "2.0", "method": "status", "method": "status", "id": 1}`
+	unknown := `{"jsonrpc": "2.0", "method": "status", "wallet_id": "x", "id": 1}`
+
+	// Lenient by default
+	assert.Nil(t, call(duplicate).Error)
+	assert.Nil(t, call(unknown).Error)
+
+	config.Override("StrictRequestJSON", true)
+	res := call(duplicate)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32700, res.Error.Code)
+	assert.Contains(t, res.Error.Message, `duplicate key "method"`)
+
+	res = call(unknown)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32700, res.Error.Code)
+	assert.Contains(t, res.Error.Message, `unknown field "wallet_id"`)
+
+	assert.Nil(t, call(`{"jsonrpc": "2.0", "method": "status", "params": {}, "id": 1}`).Error)
+}
+
 func TestProxyDontAuthRelaxedMethods(t *testing.T) {
 	var apiCalls int
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -98,3 +147,766 @@ func Test_getDevice(t *testing.T) {
 	r.Header.Add("User-Agent", "Odysee")
 	assert.Equal(t, orgiOS, getDevice(r))
 }
+
+func TestGetAuthError(t *testing.T) {
+	code := func(err error) int {
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rpcerrors.ErrorToJSON(err), &res))
+		return res.Error.Code
+	}
+	assert.Nil(t, GetAuthError(&models.User{ID: 1}, nil))
+	assert.Equal(t, -32084, code(GetAuthError(nil, errors.Err(auth.ErrNoAuthInfo))))
+	assert.Equal(t, -32097, code(GetAuthError(nil, errors.Err(wallet.ErrSessionExpired))))
+	assert.Equal(t, -32097, code(GetAuthError(nil, errors.Err(wallet.ErrSessionRevoked))))
+	assert.Equal(t, -32085, code(GetAuthError(nil, errors.Err("api error: could not authenticate user"))))
+	err := GetAuthError(nil, nil)
+	assert.Equal(t, -32085, code(err))
+	assert.EqualError(t, err, "must authenticate")
+}
+
+func TestProxyMaintenanceMode(t *testing.T) {
+	config.Override("MaintenanceMode", true)
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	raw, err := json.Marshal(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+
+	rt := sdkrouter.New(config.GetLbrynetServers())
+	handler := sdkrouter.Middleware(rt)(http.HandlerFunc(Handle))
+
+	r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "300", rr.Header().Get("Retry-After"))
+	var parsedResponse jsonrpc.RPCResponse
+	err = json.Unmarshal(rr.Body.Bytes(), &parsedResponse)
+	require.NoError(t, err)
+	assert.Equal(t, -32090, parsedResponse.Error.Code)
+
+	r, err = http.NewRequest("POST", "", bytes.NewBuffer(raw))
+	require.NoError(t, err)
+	r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var adminResponse jsonrpc.RPCResponse
+	err = json.Unmarshal(rr.Body.Bytes(), &adminResponse)
+	require.NoError(t, err)
+	assert.Nil(t, adminResponse.Error)
+}
+
+func TestProxyPreservesNumbers(t *testing.T) {
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+	srv.QueueResponses(`{"jsonrpc": "2.0", "result": {"amount": 123456789012345678901234567890, "fee": 0.000000000000000001}, "id": 0}`)
+
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	handler := sdkrouter.Middleware(rt)(http.HandlerFunc(Handle))
+
+	raw := `{"jsonrpc": "2.0", "method": "claim_search", "params": {"fee_amount": 9007199254740993, "page": 1}, "id": 1}`
+	r, err := http.NewRequest("POST", "", bytes.NewBufferString(raw))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	sdkReq := <-reqChan
+	assert.Contains(t, sdkReq.Body, `"fee_amount":9007199254740993`)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"amount": 123456789012345678901234567890`)
+	assert.Contains(t, rr.Body.String(), `"fee": 0.000000000000000001`)
+}
+
+func TestProxyCacheBypass(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	config.Override("CacheBypassAllowlist", []string{"8.8.8.8"})
+	// Experimental resolve calls would hit the mock server too
+	config.Override("LbrynetXPercentage", 0)
+	defer config.RestoreOverridden()
+
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(rt),
+		ip.Middleware,
+		cache.Middleware(qCache),
+	), Handle)
+
+	raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+
+	call := func(headers map[string]string) string {
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 1}, "id": 0}`
+	assert.Contains(t, call(nil), `"n": 1`)
+	<-reqChan
+	qCache.Wait()
+
+	// Not allowed to bypass, cached response is returned
+	assert.Contains(t, call(map[string]string{CacheBypassHeader: "1"}), `"n": 1`)
+	assert.Contains(t, call(map[string]string{"Cache-Control": "no-cache"}), `"n": 1`)
+	assert.Len(t, reqChan, 0)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 2}, "id": 0}`
+	assert.Contains(t, call(map[string]string{CacheBypassHeader: "true", auth.AdminTokenHeader: "admin-secret"}), `"n": 2`)
+	<-reqChan
+	qCache.Wait()
+
+	// Fresh response has been cached
+	assert.Contains(t, call(nil), `"n": 2`)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 3}, "id": 0}`
+	assert.Contains(t, call(map[string]string{"Cache-Control": "max-age=0, no-cache", "X-Forwarded-For": "8.8.8.8"}), `"n": 3`)
+	<-reqChan
+}
+
+func TestProxyCacheDirective(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	config.Override("ServiceSecrets", map[string]string{"content": "content-secret"})
+	config.Override("LbrynetXPercentage", 0)
+	defer config.RestoreOverridden()
+
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(rt),
+		ip.Middleware,
+		auth.ServiceMiddleware,
+		cache.Middleware(qCache),
+	), Handle)
+
+	raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "directive"}))
+	require.NoError(t, err)
+
+	call := func(headers map[string]string) *httptest.ResponseRecorder {
+		r, err := http.NewRequest("POST", "/api/v1/proxy", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr
+	}
+	signed := func(directive, secret string) map[string]string {
+		ts := time.Now().UnixNano()
+		nonce := strconv.FormatInt(ts, 10)
+		sig := auth.SignServiceRequest("content-secret", http.MethodPost, "/api/v1/proxy", "", ts/int64(time.Second), nonce, raw)
+		return map[string]string{
+			auth.ServiceNameHeader:             "content",
+			auth.ServiceTimestampHeader:        strconv.FormatInt(ts/int64(time.Second), 10),
+			auth.ServiceNonceHeader:            nonce,
+			auth.ServiceSignatureHeader:        sig,
+			auth.CacheDirectiveHeader:          directive,
+			auth.CacheDirectiveSignatureHeader: auth.SignCacheDirective(secret, directive, sig),
+		}
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 1}, "id": 0}`
+	assert.Contains(t, call(nil).Body.String(), `"n": 1`)
+	<-reqChan
+	qCache.Wait()
+
+	// Directives without a valid signature are ignored
+	assert.Contains(t, call(map[string]string{auth.CacheDirectiveHeader: "ttl=50ms"}).Body.String(), `"n": 1`)
+	assert.Contains(t, call(signed("ttl=50ms", "wrong-secret")).Body.String(), `"n": 1`)
+	assert.Len(t, reqChan, 0)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 2}, "id": 0}`
+	assert.Contains(t, call(signed("ttl=50ms", "content-secret")).Body.String(), `"n": 2`)
+	<-reqChan
+	qCache.Wait()
+	assert.Contains(t, call(nil).Body.String(), `"n": 2`)
+	assert.Len(t, reqChan, 0)
+
+	// Shortened TTL has run out
+	time.Sleep(100 * time.Millisecond)
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 3}, "id": 0}`
+	assert.Contains(t, call(nil).Body.String(), `"n": 3`)
+	<-reqChan
+	qCache.Wait()
+
+	key := call(map[string]string{auth.AdminTokenHeader: "admin-secret"}).Header().Get(CacheKeyHeader)
+	require.NotEmpty(t, key)
+	assert.Contains(t, call(map[string]string{auth.CacheDirectiveHeader: "invalidate=" + key}).Body.String(), `"n": 3`)
+	assert.Contains(t, call(signed("invalidate="+key, "wrong-secret")).Body.String(), `"n": 3`)
+	assert.Len(t, reqChan, 0)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"n": 4}, "id": 0}`
+	assert.Contains(t, call(signed("invalidate="+key, "content-secret")).Body.String(), `"n": 4`)
+	<-reqChan
+}
+
+func TestProxyRejectsConcurrentWalletMutations(t *testing.T) {
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 991}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: srv.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(rt),
+		auth.Middleware(provider),
+	), Handle)
+
+	call := func(method string) string {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"amount": "1.0"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		r.Header.Set(wallet.TokenHeader, "abc")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr.Body.String()
+	}
+
+	first := make(chan string)
+	go func() { first <- call("support_create") }()
+	<-reqChan
+
+	assert.Contains(t, call(query.MethodWalletSend), "another operation is in progress for this user")
+	assert.Contains(t, call("support_create"), "another operation is in progress for this user")
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"txid": "first"}, "id": 0}`
+	assert.Contains(t, <-first, `"txid": "first"`)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"txid": "second"}, "id": 0}`
+	go func() { <-reqChan }()
+	assert.Contains(t, call("stream_abandon"), `"txid": "second"`)
+}
+
+func TestProxyEnvelope(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	call := func(h http.HandlerFunc, headers map[string]string, raw string) string {
+		r, err := http.NewRequest("POST", "", bytes.NewBufferString(raw))
+		require.NoError(t, err)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		sdkrouter.Middleware(rt)(h).ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+	req := `{"jsonrpc": "2.0", "method": "resolve", "params": {"urls": "what"}, "id": 1}`
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {"amount": 9007199254740993}}, "id": 1}`
+	assert.JSONEq(t,
+		`{"success": true, "data": {"what": {"amount": 9007199254740993}}}`,
+		call(Handle, map[string]string{ResponseFormatHeader: ResponseFormatEnvelope}, req))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "sdk failure"}, "id": 1}`
+	assert.JSONEq(t,
+		`{"success": false, "data": null, "error": {"code": -32500, "message": "sdk failure"}}`,
+		call(HandleEnvelope, nil, req))
+
+	// Errors originating in the proxy are translated too
+	assert.JSONEq(t,
+		`{"success": false, "data": null, "error": {"code": -32700, "message": "invalid character 'y' looking for beginning of value"}}`,
+		call(HandleEnvelope, nil, "yo"))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {}}, "id": 1}`
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"what": {}}, "id": 1}`, call(Handle, nil, req))
+}
+
+func TestProxyObservesEveryCallOnce(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	downRt := sdkrouter.New(map[string]string{"a": down.URL})
+	withRouter := func(rt *sdkrouter.Router) http.Handler {
+		return middleware.Apply(middleware.Chain(sdkrouter.Middleware(rt), auth.ServiceMiddleware), Handle)
+	}
+	resolve := `{"jsonrpc": "2.0", "method": "resolve", "params": {"urls": "what"}, "id": 1}`
+
+	cases := []struct {
+		name     string
+		handler  http.Handler
+		body     string
+		prepare  func(r *http.Request)
+		method   string
+		kind     string
+		panics   bool
+		response string
+	}{
+		{
+			name: "maintenance", handler: withRouter(rt), body: resolve, kind: metrics.FailureKindMaintenance,
+			prepare: func(r *http.Request) { config.Override("MaintenanceMode", true) },
+		},
+		{
+			name: "bad service signature", handler: withRouter(rt), body: resolve, kind: metrics.FailureKindAuth,
+			prepare: func(r *http.Request) { r.Header.Set(auth.ServiceNameHeader, "unknown") },
+		},
+		{name: "no body", handler: withRouter(rt), kind: metrics.FailureKindClient},
+		{name: "invalid json", handler: withRouter(rt), body: "yo", kind: metrics.FailureKindClientJSON},
+		{name: "null", handler: withRouter(rt), body: "null", kind: metrics.FailureKindClientJSON},
+		{
+			name: "auth", handler: withRouter(rt), method: query.MethodWalletBalance, kind: metrics.FailureKindAuth,
+			body: `{"jsonrpc": "2.0", "method": "wallet_balance", "id": 1}`,
+		},
+		{name: "net", handler: withRouter(downRt), body: resolve, method: query.MethodResolve, kind: metrics.FailureKindNet},
+		{
+			name: "rpc", handler: withRouter(rt), body: resolve, method: query.MethodResolve, kind: metrics.FailureKindRPC,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "sdk failure"}, "id": 1}`,
+		},
+		{
+			name: "upstream invalid", handler: withRouter(rt), body: resolve, method: query.MethodResolve,
+			kind: metrics.FailureKindUpstreamInvalid, response: `<html>Bad Gateway</html>`,
+		},
+		{
+			name: "success", handler: withRouter(rt), body: resolve, method: query.MethodResolve,
+			response: `{"jsonrpc": "2.0", "result": {}, "id": 1}`,
+		},
+		{
+			name: "panic", handler: http.HandlerFunc(Handle), body: resolve, method: query.MethodResolve,
+			kind: metrics.FailureKindDropped, panics: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer config.RestoreOverridden()
+			config.Override("LbrynetXPercentage", 0)
+
+			total := metrics.GetCounterValue(metrics.ProxyE2ECallCounter.WithLabelValues(c.method))
+			var failed float64
+			if c.kind != "" {
+				failed = metrics.GetCounterValue(metrics.ProxyE2ECallFailedCounter.WithLabelValues(c.method, c.kind))
+			}
+
+			var r *http.Request
+			if c.body != "" {
+				r = httptest.NewRequest(http.MethodPost, "/api/v1/proxy", bytes.NewBufferString(c.body))
+			} else {
+				r = httptest.NewRequest(http.MethodPost, "/api/v1/proxy", nil)
+				r.Body = nil
+			}
+			if c.prepare != nil {
+				c.prepare(r)
+			}
+			if c.response != "" {
+				srv.NextResponse <- c.response
+			}
+			serve := func() { c.handler.ServeHTTP(httptest.NewRecorder(), r) }
+			if c.panics {
+				assert.Panics(t, serve)
+			} else {
+				serve()
+			}
+
+			assert.Equal(t, total+1, metrics.GetCounterValue(metrics.ProxyE2ECallCounter.WithLabelValues(c.method)))
+			if c.kind != "" {
+				assert.Equal(t, failed+1, metrics.GetCounterValue(metrics.ProxyE2ECallFailedCounter.WithLabelValues(c.method, c.kind)))
+			}
+		})
+	}
+}
+
+func TestProxyInvalidUpstreamResponse(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/proxy",
+		bytes.NewBufferString(`{"jsonrpc": "2.0", "method": "resolve", "params": {"urls": "what"}, "id": 1}`))
+	rr := httptest.NewRecorder()
+	srv.NextResponse <- "<html><body>502 Bad Gateway</body></html>"
+	sdkrouter.Middleware(rt)(http.HandlerFunc(Handle)).ServeHTTP(rr, r)
+
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32101, res.Error.Code)
+	assert.Contains(t, res.Error.Message, "upstream returned invalid response (status 200")
+	assert.Contains(t, res.Error.Message, "<html><body>502 Bad Gateway</body></html>")
+}
+
+func TestCallObserver_OnlyOnce(t *testing.T) {
+	total := metrics.ProxyE2ECallCounter.WithLabelValues("observer_test")
+	rpcFailed := metrics.ProxyE2ECallFailedCounter.WithLabelValues("observer_test", metrics.FailureKindRPC)
+	dropped := metrics.ProxyE2ECallFailedCounter.WithLabelValues("observer_test", metrics.FailureKindDropped)
+	before := []float64{metrics.GetCounterValue(total), metrics.GetCounterValue(rpcFailed), metrics.GetCounterValue(dropped)}
+
+	o := newCallObserver(httptest.NewRequest(http.MethodPost, "/api/v1/proxy", nil), nil)
+	o.method = "observer_test"
+	o.success()
+	o.failure(metrics.FailureKindRPC)
+	o.finish()
+
+	assert.Equal(t, before[0]+1, metrics.GetCounterValue(total))
+	assert.Equal(t, before[1], metrics.GetCounterValue(rpcFailed))
+	assert.Equal(t, before[2], metrics.GetCounterValue(dropped))
+}
+
+func TestProxyCachePolicy(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	config.Override("CachePolicies", map[string]interface{}{
+		"resolve":        map[string]interface{}{"CacheControl": "public, max-age=60", "SurrogateControl": "max-age=600"},
+		"claim_search":   map[string]interface{}{"CacheControl": "public, max-age=30"},
+		"wallet_balance": map[string]interface{}{"CacheControl": "public, max-age=30"},
+	})
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 992}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: srv.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(rt),
+		auth.Middleware(provider),
+	), Handle)
+
+	call := func(method string, authenticated bool) http.Header {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"urls": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if authenticated {
+			r.Header.Set(wallet.TokenHeader, "abc")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr.Header()
+	}
+	ok := `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+
+	srv.NextResponse <- ok
+	h := call(query.MethodResolve, false)
+	assert.Equal(t, "public, max-age=60", h.Get(CacheControlHeader))
+	assert.Equal(t, "max-age=600", h.Get(SurrogateControlHeader))
+
+	srv.NextResponse <- ok
+	h = call(query.MethodClaimSearch, false)
+	assert.Equal(t, "public, max-age=30", h.Get(CacheControlHeader))
+	assert.Empty(t, h.Get(SurrogateControlHeader))
+
+	// Anything wallet-scoped is never cacheable
+	srv.NextResponse <- ok
+	h = call(query.MethodResolve, true)
+	assert.Equal(t, noStore, h.Get(CacheControlHeader))
+	assert.Equal(t, noStore, h.Get(SurrogateControlHeader))
+
+	srv.NextResponse <- ok
+	h = call(query.MethodWalletBalance, true)
+	assert.Equal(t, noStore, h.Get(CacheControlHeader))
+
+	srv.NextResponse <- ok
+	h = call("txo_list", true)
+	assert.Equal(t, noStore, h.Get(CacheControlHeader))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "sdk failure"}, "id": 0}`
+	h = call(query.MethodResolve, false)
+	assert.Equal(t, noStore, h.Get(CacheControlHeader))
+	assert.Equal(t, noStore, h.Get(SurrogateControlHeader))
+
+	// Policies are re-read on every request
+	config.Override("CachePolicies", map[string]interface{}{})
+	srv.NextResponse <- ok
+	h = call(query.MethodResolve, false)
+	assert.Equal(t, noStore, h.Get(CacheControlHeader))
+}
+
+func TestProxySDKWarnings(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	handler := sdkrouter.Middleware(rt)(http.HandlerFunc(Handle))
+
+	call := func() *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {}, "warnings": [
+		{"type": "deprecated", "message": "a"}, "b", {"type": "deprecated", "message": "c"}]}, "id": 0}`
+	rr := call()
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "deprecated, generic", rr.Header().Get(SDKWarningsHeader))
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Nil(t, res.Error)
+	assert.Len(t, res.Result.(map[string]interface{})[query.WarningsField], 3)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {}}, "id": 0}`
+	rr = call()
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get(SDKWarningsHeader))
+}
+
+func TestProxyStreamsLargeResponses(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	config.Override("ResponseStreamingThreshold", 2)
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	handler := sdkrouter.Middleware(rt)(http.HandlerFunc(Handle))
+
+	call := func() *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {"name": "what", "tags": ["a", "b"]}}, "id": 0}`
+	rr := call()
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("content-type"))
+	assert.NotContains(t, rr.Body.String(), "\n", "streamed responses are not indented")
+	var res jsonrpc.RPCResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Nil(t, res.Error)
+	assert.Equal(t, []interface{}{"a", "b"}, res.Result.(map[string]interface{})["what"].(map[string]interface{})["tags"])
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {}}, "id": 0}`
+	rr = call()
+	assert.Contains(t, rr.Body.String(), "\n")
+}
+
+func TestProxyCacheInfo(t *testing.T) {
+	config.Override("LbrynetXPercentage", 0)
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.New(map[string]string{"a": srv.URL})
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	handler := sdkrouter.Middleware(rt)(cache.AddToRequest(qCache, Handle))
+
+	call := func(urls string, admin bool) http.Header {
+		raw, err := json.Marshal(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": urls}))
+		require.NoError(t, err)
+		r, err := http.NewRequest("POST", "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Header()
+	}
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"one": {}}, "id": 0}`
+	h := call("one", false)
+	assert.Empty(t, h.Get(CacheStatusHeader))
+	qCache.Wait()
+
+	h = call("one", true)
+	assert.Equal(t, cache.StatusHit, h.Get(CacheStatusHeader))
+	assert.NotEmpty(t, h.Get(CacheTTLHeader))
+	assert.NotEmpty(t, h.Get(CacheKeyHeader))
+
+	config.Override("ExposeCacheInfo", true)
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"two": {}}, "id": 0}`
+	h = call("two", false)
+	assert.Equal(t, cache.StatusMiss, h.Get(CacheStatusHeader))
+	assert.Equal(t, "180", h.Get(CacheTTLHeader))
+	assert.Empty(t, h.Get(CacheKeyHeader))
+}
+
+func TestProxyRequestID(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	handler := middleware.Apply(sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})), Handle)
+
+	call := func(id string) *httptest.ResponseRecorder {
+		srv.NextResponse <- `{"jsonrpc": "2.0", "result": "0.1.2", "id": 0}`
+		raw, err := json.Marshal(jsonrpc.NewRequest("version"))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if id != "" {
+			r.Header.Set(RequestIDHeader, id)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr
+	}
+
+	assert.Equal(t, "lb-123", call("lb-123").Header().Get(RequestIDHeader))
+	generated := call("").Header().Get(RequestIDHeader)
+	assert.Len(t, generated, 16)
+	assert.NotEqual(t, generated, call("").Header().Get(RequestIDHeader))
+}
+
+func TestProxyCanarySDKServers(t *testing.T) {
+	sdk := func(result string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": "` + result + `", "id": 0}`))
+		}))
+	}
+	stable, canarySDK := sdk("stable"), sdk("canary")
+	defer stable.Close()
+	defer canarySDK.Close()
+	config.Override("Canary", map[string]interface{}{"Enabled": true, "Percentage": 100, "SDKServers": []string{canarySDK.URL}})
+	defer config.RestoreOverridden()
+
+	rt := sdkrouter.New(map[string]string{"a": stable.URL})
+	call := func(handler http.Handler) string {
+		raw, err := json.Marshal(jsonrpc.NewRequest("version"))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr.Body.String()
+	}
+
+	withVariant := middleware.Apply(middleware.Chain(sdkrouter.Middleware(rt), ip.Middleware, canary.Middleware), Handle)
+	assert.Contains(t, call(withVariant), `"canary"`)
+	// Clients without a variant are stable ones
+	assert.Contains(t, call(middleware.Apply(sdkrouter.Middleware(rt), Handle)), `"stable"`)
+
+	rt.Quarantine(canarySDK.URL)
+	assert.Contains(t, call(withVariant), `"stable"`)
+}
+
+func TestProxyObservesBodySizes(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	handler := middleware.Apply(sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})), Handle)
+	sizes := func(method string) (float64, uint64, float64, uint64) {
+		req := metrics.GetMetric(metrics.ProxyE2ERequestSizes.WithLabelValues(method).(prometheus.Histogram)).Histogram
+		res := metrics.GetMetric(metrics.ProxyE2EResponseSizes.WithLabelValues(method).(prometheus.Histogram)).Histogram
+		return req.GetSampleSum(), req.GetSampleCount(), res.GetSampleSum(), res.GetSampleCount()
+	}
+	reqSum, reqCount, resSum, resCount := sizes("version")
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": "0.1.2", "id": 0}`
+	raw, err := json.Marshal(jsonrpc.NewRequest("version"))
+	require.NoError(t, err)
+	r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	newReqSum, newReqCount, newResSum, newResCount := sizes("version")
+	assert.Equal(t, reqCount+1, newReqCount)
+	assert.Equal(t, float64(len(raw)), newReqSum-reqSum)
+	assert.Equal(t, resCount+1, newResCount)
+	assert.Equal(t, float64(rr.Body.Len()), newResSum-resSum)
+
+	// Unparseable requests still have their body size recorded
+	_, reqCount, _, resCount = sizes("")
+	r, err = http.NewRequest(http.MethodPost, "", bytes.NewBufferString("{"))
+	require.NoError(t, err)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	_, newReqCount, _, newResCount = sizes("")
+	assert.Equal(t, reqCount+1, newReqCount)
+	assert.Equal(t, resCount+1, newResCount)
+}
+
+func TestProxyMethodAliases(t *testing.T) {
+	config.Override("MethodAliases", map[string]string{
+		"balance_v0": query.MethodWalletBalance,
+		"resolve_v0": query.MethodResolve,
+	})
+	defer config.RestoreOverridden()
+
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL})),
+		auth.NilMiddleware,
+	), Handle)
+	call := func(method string) *jsonrpc.RPCResponse {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"urls": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		return &res
+	}
+
+	// Wallet method called by its alias requires auth just the same
+	res := call("balance_v0")
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32084, res.Error.Code)
+
+	// Relaxed method called by its alias doesn't
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"what": {}}, "id": 0}`
+	res = call("resolve_v0")
+	require.Nil(t, res.Error)
+	req := <-reqChan
+	assert.Contains(t, req.Body, `"method":"resolve"`)
+}
+
+func TestTraceID(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPost, "", nil)
+	assert.Equal(t, "req1", traceID(r, "req1"))
+
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID(r, "req1"))
+
+	r.Header.Set(TraceParentHeader, "00-not-a-trace-id")
+	assert.Equal(t, "req1", traceID(r, "req1"))
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/ratelimit"
+	"github.com/lbryio/lbrytv/internal/responses"
+	"github.com/lbryio/lbrytv/models"
+)
+
+// Rate limit headers let clients pace themselves (see RateLimits config).
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+)
+
+var rateLimiter = ratelimit.New()
+
+// rateLimitBucket returns the bucket key and limit for a call. Empty key means the call is not limited.
+func rateLimitBucket(limits config.RateLimits, user *models.User, remoteIP, method string) (string, config.RateLimit) {
+	var client string
+	if user != nil {
+		client = fmt.Sprintf("user:%v", user.ID)
+	} else if remoteIP != "" {
+		client = "ip:" + remoteIP
+	} else {
+		return "", config.RateLimit{}
+	}
+	method = strings.ToLower(method)
+	if l, ok := limits.Methods[method]; ok {
+		return client + "\n" + method, l
+	}
+	return client, limits.Default
+}
+
+// allowCall takes a token for the call from the rate limiter, setting rate limit headers.
+// If the call is over the limit, it responds with an error and returns false.
+func allowCall(w http.ResponseWriter, r *http.Request, user *models.User, method string) bool {
+	limits := config.GetRateLimits()
+	if !limits.Enabled || auth.IsAdmin(r) || auth.IsService(r) {
+		return true
+	}
+	key, limit := rateLimitBucket(limits, user, ip.FromRequest(r), method)
+	if key == "" {
+		return true
+	}
+	res := rateLimiter.Allow(key, limit, time.Now())
+	if limit.Burst > 0 {
+		w.Header().Set(RateLimitLimitHeader, strconv.Itoa(limit.Burst))
+		w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+	}
+	if res.Allowed {
+		return true
+	}
+
+	metrics.ProxyRateLimitedCalls.WithLabelValues(method).Inc()
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryIn.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	writeResponse(w, rpcerrors.NewRateLimitedError(errors.Err("too many requests, retry later")).JSON())
+	return false
+}
+
+// defaultRateLimitName is the key the bucket shared by methods without limits of their own is reported under.
+const defaultRateLimitName = "default"
+
+// RateLimitStatus describes the state of a rate limit bucket of the client.
+type RateLimitStatus struct {
+	// Limit is the number of calls which can be made in a burst.
+	Limit int `json:"limit"`
+	// Rate is the number of calls per second the bucket refills with.
+	Rate      float64 `json:"rate"`
+	Remaining int     `json:"remaining"`
+	// ResetAt is when the bucket is full again.
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// RateLimitsStatus is the response of HandleRateLimits.
+type RateLimitsStatus struct {
+	Enabled bool `json:"enabled"`
+	// Exempt is true for admins and services, which are never limited.
+	Exempt bool `json:"exempt"`
+	// Limits are keyed by method, methods which aren't listed share the "default" bucket.
+	Limits map[string]RateLimitStatus `json:"limits"`
+}
+
+// HandleRateLimits reports rate limits of the authenticated user and how much of them is left, so clients can pace
+// themselves. It's read from the same buckets limits are enforced with, without taking from them.
+// A method param narrows the response to the bucket of that method.
+func HandleRateLimits(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	user, err := auth.FromRequest(r)
+	if err != nil || user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		respByte, _ := json.Marshal(map[string]string{"error": "authentication required"})
+		w.Write(respByte)
+		return
+	}
+
+	limits := config.GetRateLimits()
+	st := RateLimitsStatus{
+		Enabled: limits.Enabled,
+		Exempt:  auth.IsAdmin(r) || auth.IsService(r),
+		Limits:  map[string]RateLimitStatus{},
+	}
+	if st.Enabled && !st.Exempt {
+		methods := []string{defaultRateLimitName}
+		for m := range limits.Methods {
+			methods = append(methods, m)
+		}
+		if m := r.FormValue("method"); m != "" {
+			methods = []string{m}
+		}
+		now := time.Now()
+		for _, m := range methods {
+			name := strings.ToLower(m)
+			if _, ok := limits.Methods[name]; !ok {
+				name = defaultRateLimitName
+			}
+			key, limit := rateLimitBucket(limits, user, "", m)
+			if limit.Burst <= 0 {
+				continue
+			}
+			bs := rateLimiter.Status(key, limit, now)
+			st.Limits[name] = RateLimitStatus{
+				Limit:     limit.Burst,
+				Rate:      limit.Rate,
+				Remaining: bs.Remaining,
+				ResetAt:   now.Add(bs.ResetIn).UTC(),
+			}
+		}
+	}
+	respByte, _ := json.Marshal(st)
+	w.Write(respByte)
+}
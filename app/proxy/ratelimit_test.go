@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/ratelimit"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxyRateLimits(t *testing.T) {
+	config.Override("RateLimits", map[string]interface{}{
+		"Enabled": true,
+		"Default": map[string]interface{}{"Rate": 0.001, "Burst": 3},
+		"Methods": map[string]interface{}{"claim_search": map[string]interface{}{"Rate": 0.001, "Burst": 1}},
+	})
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+	rateLimiter = ratelimit.New()
+
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+	handler := middleware.Apply(middleware.Chain(
+		ip.Middleware,
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.NilMiddleware,
+	), Handle)
+
+	call := func(method, remoteIP string, admin bool) *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		r.RemoteAddr = remoteIP + ":12345"
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	for _, remaining := range []string{"2", "1", "0"} {
+		rr := call("status", "8.8.8.8", false)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "3", rr.Header().Get(RateLimitLimitHeader))
+		assert.Equal(t, remaining, rr.Header().Get(RateLimitRemainingHeader))
+	}
+	rr := call("version", "8.8.8.8", false)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get(RateLimitRemainingHeader))
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "too many requests")
+
+	// Methods with limits of their own don't share the default bucket
+	assert.Equal(t, http.StatusOK, call("claim_search", "8.8.8.8", false).Code)
+	assert.Equal(t, http.StatusTooManyRequests, call("claim_search", "8.8.8.8", false).Code)
+
+	// Other clients and admins are not affected
+	assert.Equal(t, http.StatusOK, call("status", "1.1.1.1", false).Code)
+	rr = call("status", "8.8.8.8", true)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get(RateLimitRemainingHeader))
+
+	// Throttled calls never reach the SDK
+	var claimSearches int
+	for _, m := range sdk.calls {
+		if m == "claim_search" {
+			claimSearches++
+		}
+	}
+	assert.Equal(t, 1, claimSearches)
+}
+
+func TestRateLimitBucket(t *testing.T) {
+	limits := config.RateLimits{
+		Default: config.RateLimit{Rate: 1, Burst: 10},
+		Methods: map[string]config.RateLimit{"claim_search": {Rate: 2, Burst: 5}},
+	}
+	key, l := rateLimitBucket(limits, &models.User{ID: 7}, "8.8.8.8", "status")
+	assert.Equal(t, "user:7", key)
+	assert.Equal(t, limits.Default, l)
+
+	key, l = rateLimitBucket(limits, nil, "8.8.8.8", "Claim_Search")
+	assert.Equal(t, "ip:8.8.8.8\nclaim_search", key)
+	assert.Equal(t, limits.Methods["claim_search"], l)
+
+	key, _ = rateLimitBucket(limits, nil, "", "status")
+	assert.Empty(t, key)
+}
+
+func TestHandleRateLimits(t *testing.T) {
+	config.Override("RateLimits", map[string]interface{}{
+		"Enabled": true,
+		"Default": map[string]interface{}{"Rate": 0.001, "Burst": 3},
+		"Methods": map[string]interface{}{"claim_search": map[string]interface{}{"Rate": 0.5, "Burst": 2}},
+	})
+	defer config.RestoreOverridden()
+	rateLimiter = ratelimit.New()
+
+	sdk := newCountingSDK(t)
+	defer sdk.Close()
+	provider := func(token, ip string) (*models.User, error) {
+		if token == "" {
+			return nil, nil
+		}
+		u := &models.User{ID: 992}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: sdk.URL}
+		return u, nil
+	}
+	mw := middleware.Chain(
+		ip.Middleware,
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.Middleware(provider),
+	)
+	proxyHandler := middleware.Apply(mw, Handle)
+	statusHandler := middleware.Apply(mw, HandleRateLimits)
+
+	call := func(method string) int {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		r.Header.Set(wallet.TokenHeader, "abc")
+		rr := httptest.NewRecorder()
+		proxyHandler.ServeHTTP(rr, r)
+		return rr.Code
+	}
+	status := func(token, method string) (int, RateLimitsStatus) {
+		r, err := http.NewRequest(http.MethodGet, "/api/v1/rate-limits?method="+method, nil)
+		require.NoError(t, err)
+		if token != "" {
+			r.Header.Set(wallet.TokenHeader, token)
+		}
+		rr := httptest.NewRecorder()
+		statusHandler.ServeHTTP(rr, r)
+		st := RateLimitsStatus{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &st))
+		}
+		return rr.Code, st
+	}
+
+	code, _ := status("", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, st := status("abc", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, st.Enabled)
+	assert.False(t, st.Exempt)
+	require.Len(t, st.Limits, 2)
+	assert.Equal(t, 3, st.Limits["default"].Limit)
+	assert.Equal(t, 3, st.Limits["default"].Remaining)
+	assert.Equal(t, 2, st.Limits["claim_search"].Remaining)
+	assert.Equal(t, 0.5, st.Limits["claim_search"].Rate)
+
+	// Remaining follows enforcement and checking doesn't use it up
+	for remaining := 2; remaining >= 0; remaining-- {
+		require.Equal(t, http.StatusOK, call("status"))
+		_, st = status("abc", "version")
+		require.Len(t, st.Limits, 1)
+		assert.Equal(t, remaining, st.Limits["default"].Remaining)
+		assert.True(t, st.Limits["default"].ResetAt.After(time.Now()))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, call("version"))
+	_, st = status("abc", "")
+	assert.Equal(t, 0, st.Limits["default"].Remaining)
+	assert.Equal(t, 2, st.Limits["claim_search"].Remaining)
+
+	require.Equal(t, http.StatusOK, call("claim_search"))
+	require.Equal(t, http.StatusOK, call("claim_search"))
+	_, st = status("abc", "Claim_Search")
+	assert.Equal(t, 0, st.Limits["claim_search"].Remaining)
+	assert.WithinDuration(t, time.Now().Add(4*time.Second), st.Limits["claim_search"].ResetAt, time.Second)
+	assert.Equal(t, http.StatusTooManyRequests, call("claim_search"))
+}
@@ -0,0 +1,27 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// SDKNodeHeader names the SDK server which has served the request, for debugging node-specific issues.
+// It's only sent to admins unless ExposeSDKNode is on, as it reveals the SDK topology.
+const SDKNodeHeader = "X-SDK-Node"
+
+// setSDKNodeHeader tells which SDK server address has been contacted, by its name if the router knows it.
+func setSDKNodeHeader(w http.ResponseWriter, r *http.Request, address string) {
+	if address == "" || (!auth.IsAdmin(r) && !config.ShouldExposeSDKNode()) {
+		return
+	}
+	node := address
+	if sdkrouter.IsOnRequest(r) {
+		if s := sdkrouter.FromRequest(r).FindServer(address); s != nil && s.Name != "" {
+			node = s.Name
+		}
+	}
+	w.Header().Set(SDKNodeHeader, node)
+}
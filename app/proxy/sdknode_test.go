@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxySDKNodeHeader(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	srv := newCountingSDK(t)
+	defer srv.Close()
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	c, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	handler := func(address string) http.Handler {
+		return middleware.Apply(middleware.Chain(
+			sdkrouter.Middleware(sdkrouter.New(map[string]string{"node-1": address})),
+			cache.Middleware(c),
+		), Handle)
+	}
+	call := func(h http.Handler, method string, admin bool) *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest(method, map[string]interface{}{"urls": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	assert.Equal(t, "node-1", call(handler(srv.URL), "claim_search", true).Header().Get(SDKNodeHeader))
+	c.Wait()
+	// Cached responses don't involve any node
+	assert.Empty(t, call(handler(srv.URL), "claim_search", true).Header().Get(SDKNodeHeader))
+	assert.Len(t, srv.calls, 1)
+
+	assert.Empty(t, call(handler(srv.URL), "version", false).Header().Get(SDKNodeHeader))
+
+	// Failed calls name the node too
+	rr := call(handler(down.URL), "version", true)
+	assert.Contains(t, rr.Body.String(), "error")
+	assert.Equal(t, "node-1", rr.Header().Get(SDKNodeHeader))
+
+	config.Override("ExposeSDKNode", true)
+	assert.Equal(t, "node-1", call(handler(srv.URL), "version", false).Header().Get(SDKNodeHeader))
+	assert.Equal(t, []string{"claim_search", "version", "version"}, srv.calls)
+}
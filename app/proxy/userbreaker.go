@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/breaker"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+)
+
+// userBreakerBuckets is the number of buckets users are put in by their ID when reporting trips of their breakers.
+const userBreakerBuckets = 16
+
+// userBreakers are only created for users whose calls have failed and dropped once they're idle again.
+var userBreakers = breaker.NewRegistry()
+
+var userBreakersPrune = struct {
+	sync.Mutex
+	last time.Time
+}{}
+
+// userCall reports the outcome of a call to the circuit breaker of the user making it.
+// Only the first outcome reported counts, so release can be deferred.
+type userCall struct {
+	user     *models.User
+	settings config.CircuitBreaker
+	b        *breaker.Breaker
+	done     bool
+}
+
+// allowUserCall checks the circuit breaker of the signed in user making the call. While it's open, it responds
+// with an error and returns false.
+func allowUserCall(w http.ResponseWriter, r *http.Request, user *models.User, method string) (*userCall, bool) {
+	settings := config.GetCircuitBreakers().User
+	if user == nil || settings.Threshold <= 0 || auth.IsAdmin(r) || auth.IsService(r) {
+		return &userCall{}, true
+	}
+	uc := &userCall{user: user, settings: settings}
+	name := strconv.Itoa(user.ID)
+	if userBreakers.Lookup(breaker.KindUser, name) == nil {
+		return uc, true
+	}
+	b := userBreakers.Get(breaker.KindUser, name, settings)
+	now := time.Now()
+	if b.Allow(now) {
+		uc.b = b
+		return uc, true
+	}
+
+	metrics.ProxyUserCircuitBreakerRejected.WithLabelValues(method).Inc()
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(b.RetryIn(now).Seconds())))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	writeResponse(w, rpcerrors.NewUserCircuitOpenError().JSON())
+	return nil, false
+}
+
+// failure counts the call against the user, opening their breaker if they've had too many failures.
+// It's only for failures caused by the user, see rpcerrors.IsClientErrorCode.
+func (c *userCall) failure() {
+	if c.done || c.user == nil {
+		return
+	}
+	c.done = true
+	now := time.Now()
+	b := c.b
+	if b == nil {
+		b = userBreakers.Get(breaker.KindUser, strconv.Itoa(c.user.ID), c.settings)
+	}
+	if b.Failure(now) {
+		metrics.ProxyUserCircuitBreakerTrips.WithLabelValues(strconv.Itoa(c.user.ID % userBreakerBuckets)).Inc()
+	}
+	pruneUserBreakers(now, c.settings.Window)
+}
+
+// success closes the breaker of the user if the call was a trial one.
+func (c *userCall) success() {
+	if c.done {
+		return
+	}
+	c.done = true
+	if c.b != nil {
+		c.b.Success()
+	}
+}
+
+// release is for calls which have finished without an outcome the user can be judged by.
+func (c *userCall) release() {
+	if c.done {
+		return
+	}
+	c.done = true
+	if c.b != nil {
+		c.b.Release()
+	}
+}
+
+// pruneUserBreakers drops idle user breakers, at most once in every period.
+func pruneUserBreakers(now time.Time, every time.Duration) {
+	userBreakersPrune.Lock()
+	if now.Sub(userBreakersPrune.last) < every {
+		userBreakersPrune.Unlock()
+		return
+	}
+	userBreakersPrune.last = now
+	userBreakersPrune.Unlock()
+	userBreakers.Prune(now)
+}
@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/breaker"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxyUserCircuitBreaker(t *testing.T) {
+	config.Override("CircuitBreakers", map[string]interface{}{
+		"User": map[string]interface{}{"Threshold": 3, "Window": "1m", "Cooldown": "200ms"},
+	})
+	defer config.RestoreOverridden()
+	userBreakers = breaker.NewRegistry()
+
+	var sdkCalls int
+	sdk := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		sdkCalls++
+		res := jsonrpc.RPCResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{"items": []interface{}{}}}
+		switch params, _ := req.Params.(map[string]interface{}); params["text"] {
+		case "fail":
+			res = jsonrpc.RPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &jsonrpc.RPCError{Code: -32602, Message: "bad query"}}
+		case "sdk failure":
+			res = jsonrpc.RPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &jsonrpc.RPCError{Code: -32500, Message: "wallet server timed out"}}
+		case "internal failure":
+			res = jsonrpc.RPCResponse{JSONRPC: "2.0", ID: req.ID, Error: &jsonrpc.RPCError{Code: -32603, Message: "internal error"}}
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer sdk.Close()
+
+	users := map[string]int{"abusive": 1001, "normal": 1002}
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: users[token]}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Name: "a", Address: sdk.URL}
+		return u, nil
+	}
+	handler := middleware.Apply(middleware.Chain(
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": sdk.URL})),
+		auth.Middleware(provider),
+	), Handle)
+
+	call := func(token, text string) *httptest.ResponseRecorder {
+		raw, err := json.Marshal(jsonrpc.NewRequest("claim_search", map[string]interface{}{"text": text}))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if token != "" {
+			r.Header.Set(wallet.TokenHeader, token)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+	trips := func() float64 {
+		return testutil.ToFloat64(metrics.ProxyUserCircuitBreakerTrips.WithLabelValues("9"))
+	}
+	tripsBefore := trips()
+
+	// SDK failures are not the user's fault
+	for i := 0; i < 3; i++ {
+		assert.Contains(t, call("abusive", "sdk failure").Body.String(), "wallet server timed out")
+		assert.Contains(t, call("abusive", "internal failure").Body.String(), "internal error")
+	}
+	assert.Equal(t, 0.0, trips()-tripsBefore)
+	assert.Nil(t, userBreakers.Lookup(breaker.KindUser, "1001"))
+
+	// Failures of a normal user interleaved with those of the abusive one don't add up
+	assert.Equal(t, http.StatusOK, call("normal", "fail").Code)
+	for i := 0; i < 3; i++ {
+		rr := call("abusive", "fail")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "bad query")
+		assert.Equal(t, http.StatusOK, call("normal", "ok").Code)
+	}
+	assert.Equal(t, 1.0, trips()-tripsBefore)
+
+	calls := sdkCalls
+	rr := call("abusive", "ok")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "too many of your requests have failed")
+	assert.Equal(t, calls, sdkCalls)
+
+	// Others keep being served
+	assert.Equal(t, http.StatusOK, call("normal", "fail").Code)
+	assert.Equal(t, http.StatusOK, call("normal", "ok").Code)
+	assert.Equal(t, http.StatusOK, call("", "ok").Code)
+
+	// A failed trial call after the cooldown opens the breaker again, a successful one closes it
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, call("abusive", "fail").Code)
+	assert.Equal(t, http.StatusTooManyRequests, call("abusive", "ok").Code)
+	assert.Equal(t, 2.0, trips()-tripsBefore)
+
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, call("abusive", "ok").Code)
+	assert.Equal(t, http.StatusOK, call("abusive", "fail").Code)
+	assert.Equal(t, http.StatusOK, call("abusive", "ok").Code)
+}
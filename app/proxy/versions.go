@@ -0,0 +1,32 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+)
+
+// Response version negotiation headers, see query.NegotiateResponseVersion.
+const (
+	AcceptVersionHeader  = "Accept-Version"
+	ContentVersionHeader = "Content-Version"
+)
+
+// negotiateVersion picks the version of method's response for the client and announces it in the response headers.
+// If none of the versions the client has asked for is available, the client is told so and false is returned.
+func negotiateVersion(w http.ResponseWriter, r *http.Request, method string) (string, bool) {
+	if !query.HasResponseVersions(method) {
+		return "", true
+	}
+	w.Header().Add("Vary", AcceptVersionHeader)
+	v, err := query.NegotiateResponseVersion(method, r.Header.Get(AcceptVersionHeader))
+	if err != nil {
+		writeResponse(w, rpcerrors.NewInvalidParamsError(err).JSON())
+		return "", false
+	}
+	if v != "" {
+		w.Header().Set(ContentVersionHeader, v)
+	}
+	return v, true
+}
@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProxyResponseVersions(t *testing.T) {
+	query.RegisterResponseVersion("stream_cost_estimate", "1", nil)
+	query.RegisterResponseVersion("stream_cost_estimate", "2", func(result interface{}) interface{} {
+		return map[string]interface{}{"cost": result}
+	})
+	defer config.RestoreOverridden()
+
+	call := func(acceptVersion string) (*httptest.ResponseRecorder, *jsonrpc.RPCResponse) {
+		srv := test.MockHTTPServer(nil)
+		defer srv.Close()
+		srv.QueueResponses(`{"jsonrpc": "2.0", "result": 1.5, "id": 0}`)
+
+		raw, err := json.Marshal(jsonrpc.NewRequest("stream_cost_estimate", map[string]interface{}{"uri": "what"}))
+		require.NoError(t, err)
+		r, err := http.NewRequest(http.MethodPost, "", bytes.NewBuffer(raw))
+		require.NoError(t, err)
+		if acceptVersion != "" {
+			r.Header.Set(AcceptVersionHeader, acceptVersion)
+		}
+		rr := httptest.NewRecorder()
+		sdkrouter.Middleware(sdkrouter.New(map[string]string{"a": srv.URL}))(http.HandlerFunc(Handle)).ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res jsonrpc.RPCResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		return rr, &res
+	}
+
+	rr, res := call("2")
+	require.Nil(t, res.Error)
+	assert.Equal(t, map[string]interface{}{"cost": 1.5}, res.Result)
+	assert.Equal(t, "2", rr.Header().Get(ContentVersionHeader))
+	assert.Equal(t, AcceptVersionHeader, rr.Header().Get("Vary"))
+
+	rr, res = call("1")
+	require.Nil(t, res.Error)
+	assert.Equal(t, 1.5, res.Result)
+	assert.Equal(t, "1", rr.Header().Get(ContentVersionHeader))
+
+	rr, res = call("3")
+	require.NotNil(t, res.Error)
+	assert.Contains(t, res.Error.Message, "unsupported response version")
+	assert.Empty(t, rr.Header().Get(ContentVersionHeader))
+
+	// Unversioned requests get the default version
+	rr, res = call("")
+	require.Nil(t, res.Error)
+	assert.Equal(t, 1.5, res.Result)
+	assert.Empty(t, rr.Header().Get(ContentVersionHeader))
+
+	config.Override("ResponseVersionDefaults", map[string]string{"stream_cost_estimate": "2"})
+	rr, res = call("")
+	require.Nil(t, res.Error)
+	assert.Equal(t, map[string]interface{}{"cost": 1.5}, res.Result)
+	assert.Equal(t, "2", rr.Header().Get(ContentVersionHeader))
+}
@@ -13,13 +13,16 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lbryio/lbrytv/app/auth"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
 	"github.com/lbryio/lbrytv/internal/test"
 	"github.com/lbryio/lbrytv/models"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/ybbus/jsonrpc"
@@ -343,3 +346,109 @@ func Test_fetchFileWithRetries(t *testing.T) {
 		assert.Contains(t, err.Error(), "connect: connection refused")
 	})
 }
+
+type readSpy struct {
+	io.Reader
+	read bool
+}
+
+func (r *readSpy) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestHandler_ExpectContinueRejectedBeforeUpload(t *testing.T) {
+	config.Override("PublishMaxSize", 100)
+	defer config.RestoreOverridden()
+
+	handler := &Handler{UploadPath: os.TempDir()}
+	provider := func(token, ip string) (*models.User, error) {
+		if token == "uPldrToken" {
+			u := &models.User{ID: 20404}
+			u.R = u.R.NewStruct()
+			u.R.LbrynetServer = &models.LbrynetServer{Address: "http://sdk"}
+			return u, nil
+		}
+		return nil, nil
+	}
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(provider))
+	router.HandleFunc("/api/v1/proxy", handler.Handle).MatcherFunc(CanHandle)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: 5 * time.Second}}
+
+	cases := []struct {
+		name, token    string
+		size           int
+		expectedStatus int
+		expectedError  string
+	}{
+		{"unauthenticated", "", 50, http.StatusOK, "authentication required"},
+		{"too large", "uPldrToken", 1000, http.StatusRequestEntityTooLarge, "upload is larger than 100 bytes"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pr := CreatePublishRequest(t, bytes.Repeat([]byte("a"), c.size))
+			body, err := ioutil.ReadAll(pr.Body)
+			require.NoError(t, err)
+			spy := &readSpy{Reader: bytes.NewReader(body)}
+
+			r, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/proxy", spy)
+			require.NoError(t, err)
+			r.ContentLength = int64(len(body))
+			r.Header.Set("Content-Type", pr.Header.Get("Content-Type"))
+			r.Header.Set("Expect", "100-continue")
+			if c.token != "" {
+				r.Header.Set(wallet.TokenHeader, c.token)
+			}
+
+			resp, err := client.Do(r)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			respBody, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expectedStatus, resp.StatusCode)
+			assert.Equal(t, c.expectedError, test.StrToRes(t, string(respBody)).Error.Message)
+			assert.False(t, spy.read, "request body should not have been sent")
+		})
+	}
+}
+
+func TestHandler_ChunkedUploadOverLimit(t *testing.T) {
+	config.Override("PublishMaxSize", 100)
+	defer config.RestoreOverridden()
+
+	handler := &Handler{UploadPath: os.TempDir()}
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 20404}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Address: "http://sdk"}
+		return u, nil
+	}
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(provider))
+	router.HandleFunc("/api/v1/proxy", handler.Handle).MatcherFunc(CanHandle)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	pr := CreatePublishRequest(t, bytes.Repeat([]byte("a"), 1000))
+	body, err := ioutil.ReadAll(pr.Body)
+	require.NoError(t, err)
+	r, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/proxy", ioutil.NopCloser(bytes.NewReader(body)))
+	require.NoError(t, err)
+	r.ContentLength = -1
+	r.Header.Set("Content-Type", pr.Header.Get("Content-Type"))
+	r.Header.Set(wallet.TokenHeader, "uPldrToken")
+
+	resp, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "upload is larger than 100 bytes", test.StrToRes(t, string(respBody)).Error.Message)
+}
@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+var errUploadTooLarge = errors.Base("upload is too large")
+
+// limitedBody fails reading an upload once it goes over the maximum size, so uploads without Content-Length
+// (like chunked ones) are limited too. It remembers it, as multipart parsing doesn't keep the original error.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errUploadTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+	n = int(b.remaining)
+	b.remaining = 0
+	b.exceeded = true
+	return n, errUploadTooLarge
+}
+
+// limitUpload makes reading the request body fail past PublishMaxSize. It has to be applied before anything
+// reads the body and it returns nil if there's no limit.
+func limitUpload(r *http.Request) *limitedBody {
+	maxSize := config.GetPublishMaxSize()
+	if maxSize <= 0 || r.Body == nil {
+		return nil
+	}
+	if b, ok := r.Body.(*limitedBody); ok {
+		return b
+	}
+	b := &limitedBody{ReadCloser: r.Body, remaining: maxSize}
+	r.Body = b
+	return b
+}
+
+// uploadTooLarge checks if the upload is over PublishMaxSize by its Content-Length or by what's been read of it.
+func uploadTooLarge(r *http.Request) bool {
+	maxSize := config.GetPublishMaxSize()
+	if maxSize <= 0 {
+		return false
+	}
+	b, ok := r.Body.(*limitedBody)
+	return r.ContentLength > maxSize || ok && b.exceeded
+}
+
+// rejectTooLarge responds to uploads over PublishMaxSize with 413 and returns true.
+func rejectTooLarge(w http.ResponseWriter, r *http.Request) bool {
+	if !uploadTooLarge(r) {
+		return false
+	}
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Write(rpcerrors.NewInvalidParamsError(errors.Err("upload is larger than %d bytes", config.GetPublishMaxSize())).JSON())
+	observeFailure(metrics.GetDuration(r), metrics.FailureKindClient)
+	return true
+}
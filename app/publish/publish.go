@@ -2,13 +2,14 @@ package publish
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
@@ -68,13 +69,32 @@ func observeSuccess(d float64) {
 // CanHandle checks if http.Request contains POSTed data in an accepted format.
 // Supposed to be used in gorilla mux router MatcherFunc.
 func CanHandle(r *http.Request, _ *mux.RouteMatch) bool {
+	if expectsContinue(r) {
+		// Reading the body would make the server send 100 Continue before the request is authenticated,
+		// so requests expecting it are matched by content type only.
+		return isMultipart(r)
+	}
+	// The body is read here already, so uploads have to be limited before that
+	var body *limitedBody
+	if isMultipart(r) {
+		body = limitUpload(r)
+	}
 	err := r.ParseMultipartForm(32 << 20)
+	if body != nil && body.exceeded {
+		// Handle rejects it
+		return true
+	}
 	if err != nil {
 		return false
 	}
 	return r.FormValue(jsonRPCFieldName) != ""
 }
 
+func isMultipart(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mt == "multipart/form-data"
+}
+
 // Handle is where HTTP upload is handled and passed on to Publisher.
 // It should be wrapped with users.Authenticator.Wrap before it can be used
 // in a mux.Router.
@@ -92,6 +112,14 @@ func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Everything above is checked on headers only so clients sending Expect: 100-continue
+	// are rejected before they upload anything.
+	// Uploads without Content-Length which go over the limit are only found out about when they're read.
+	if rejectTooLarge(w, r) {
+		return
+	}
+	limitUpload(r)
+
 	log := logger.WithFields(logrus.Fields{"user_id": user.ID, "method_handler": method})
 
 	tries := 1
@@ -105,6 +133,9 @@ retry:
 	if err != nil {
 		switch err.(type) {
 		case *RequestError:
+			if rejectTooLarge(w, r) {
+				return
+			}
 			w.Write(rpcerrors.NewInternalError(err).JSON())
 			observeFailure(metrics.GetDuration(r), metrics.FailureKindInternal)
 			return
@@ -112,6 +143,9 @@ retry:
 		case *FetchError:
 			if errors.Is(err, ErrEmptyRemoteURL) {
 				f, err = h.saveFile(r, user.ID)
+				if err != nil && rejectTooLarge(w, r) {
+					return
+				}
 				if err != nil {
 					log.Error(err)
 					monitor.ErrorToSentry(err)
@@ -168,7 +202,7 @@ retry:
 	}
 
 	var rpcReq *jsonrpc.RPCRequest
-	err = json.Unmarshal([]byte(r.FormValue(jsonRPCFieldName)), &rpcReq)
+	err = responses.UnmarshalJSON([]byte(r.FormValue(jsonRPCFieldName)), &rpcReq)
 	if err != nil {
 		w.Write(rpcerrors.NewJSONParseError(err).JSON())
 		observeFailure(metrics.GetDuration(r), metrics.FailureKindClientJSON)
@@ -207,9 +241,18 @@ retry:
 	observeSuccess(metrics.GetDuration(r))
 }
 
+// expectsContinue checks if client is waiting for 100 Continue before sending request body.
+func expectsContinue(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Expect"), "100-continue")
+}
+
 func getCaller(sdkAddress, filename string, userID int, qCache *cache.Cache) *query.Caller {
 	c := query.NewCaller(sdkAddress, userID)
 	c.Cache = qCache
+	// Publish timeout depends on the size of the file (see SizedRPCTimeouts)
+	if fi, err := os.Stat(filename); err == nil {
+		c.PayloadSize = fi.Size()
+	}
 	c.AddPreflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
 		params := hctx.Query.ParamsAsMap()
 		params[fileNameParam] = filename
@@ -0,0 +1,94 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+// countUploads records uploads starting and finishing, tusd sends notifications about them
+// to its channels which have to be drained for uploads to proceed.
+func (h *TusHandler) countUploads() {
+	for {
+		select {
+		case <-h.CreatedUploads:
+			metrics.PublishUploads.WithLabelValues(metrics.UploadStarted).Inc()
+		case <-h.CompleteUploads:
+			metrics.PublishUploads.WithLabelValues(metrics.UploadCompleted).Inc()
+		}
+	}
+}
+
+// SweepAbandoned removes uploads abandoned for longer than olderThan every interval.
+func (h *TusHandler) SweepAbandoned(interval, olderThan time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for range t.C {
+		if n := h.RemoveAbandoned(olderThan, time.Now()); n > 0 {
+			h.logger.Log().Infof("removed %v abandoned uploads", n)
+		}
+	}
+}
+
+// RemoveAbandoned removes staged files of uploads which haven't been touched for olderThan before now,
+// whether they've been left incomplete or the publish referencing them has failed.
+// Uploads which are locked by a request in progress are skipped. It returns the number of uploads removed.
+func (h *TusHandler) RemoveAbandoned(olderThan time.Duration, now time.Time) int {
+	infos, err := filepath.Glob(filepath.Join(h.uploadPath, "*.info"))
+	if err != nil {
+		h.logger.Log().Errorf("cannot list uploads: %v", err)
+		return 0
+	}
+	removed := 0
+	for _, info := range infos {
+		id := strings.TrimSuffix(filepath.Base(info), ".info")
+		if now.Sub(h.lastActivity(id)) < olderThan {
+			continue
+		}
+		if h.removeUpload(id) {
+			removed++
+			metrics.PublishUploads.WithLabelValues(metrics.UploadAbandoned).Inc()
+		}
+	}
+	return removed
+}
+
+// lastActivity returns the time files of the upload have last been modified.
+// Uploads which have been moved for publishing (see Notify) are in a directory of their user named after their ID.
+func (h *TusHandler) lastActivity(id string) time.Time {
+	paths := []string{filepath.Join(h.uploadPath, id), filepath.Join(h.uploadPath, id+".info")}
+	if moved, err := filepath.Glob(filepath.Join(h.uploadPath, "*", id, "*")); err == nil {
+		paths = append(paths, moved...)
+	}
+	var last time.Time
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil && fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	return last
+}
+
+func (h *TusHandler) removeUpload(id string) bool {
+	if h.composer.UsesLocker {
+		lock, err := h.lockUpload(id)
+		if err != nil {
+			return false
+		}
+		defer lock.Unlock()
+	}
+	paths := []string{filepath.Join(h.uploadPath, id), filepath.Join(h.uploadPath, id+".info")}
+	if moved, err := filepath.Glob(filepath.Join(h.uploadPath, "*", id)); err == nil {
+		paths = append(paths, moved...)
+	}
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			h.logger.Log().Errorf("cannot remove abandoned upload file %v: %v", p, err)
+			return false
+		}
+	}
+	return true
+}
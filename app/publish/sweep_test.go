@@ -0,0 +1,59 @@
+package publish
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTusHandler_RemoveAbandoned(t *testing.T) {
+	h := newTestTusHandler(t)
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	touch := func(name string, mtime time.Time) string {
+		p := filepath.Join(h.uploadPath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(p, []byte("x"), 0644))
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+		return p
+	}
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+
+	incomplete := []string{touch("incomplete", old), touch("incomplete.info", old)}
+	// Failed publish, the file has been moved to the user directory
+	failed := []string{touch("failed.info", old), touch("42/failed/video.mp4", old)}
+	// Still receiving data
+	active := []string{touch("active", now.Add(-time.Minute)), touch("active.info", old)}
+	locked := []string{touch("locked", old), touch("locked.info", old)}
+	lock, err := h.lockUpload("locked")
+	require.NoError(t, err)
+
+	before := metrics.GetCounterValue(metrics.PublishUploads.WithLabelValues(metrics.UploadAbandoned))
+	assert.Equal(t, 2, h.RemoveAbandoned(24*time.Hour, now))
+	assert.Equal(t, before+2, metrics.GetCounterValue(metrics.PublishUploads.WithLabelValues(metrics.UploadAbandoned)))
+
+	for _, p := range append(incomplete, failed...) {
+		assert.False(t, exists(p), p)
+	}
+	assert.False(t, exists(filepath.Join(h.uploadPath, "42", "failed")))
+	for _, p := range append(active, locked...) {
+		assert.True(t, exists(p), p)
+	}
+
+	require.NoError(t, lock.Unlock())
+	assert.Equal(t, 1, h.RemoveAbandoned(24*time.Hour, now))
+	for _, p := range locked {
+		assert.False(t, exists(p), p)
+	}
+}
@@ -1,7 +1,6 @@
 package publish
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -36,6 +35,7 @@ type TusHandler struct {
 	logger       monitor.ModuleLogger
 	composer     *tusd.StoreComposer
 	authProvider auth.Provider
+	uploadPath   string
 }
 
 // NewTusHandler creates a new publish handler.
@@ -55,6 +55,8 @@ func NewTusHandler(authProvider auth.Provider, cfg tusd.Config, uploadPath strin
 	}
 
 	cfg.PreUploadCreateCallback = h.preCreateHook
+	cfg.NotifyCreatedUploads = true
+	cfg.NotifyCompleteUploads = true
 	// allow client to set location response protocol
 	// via X-Forwarded-Proto
 	cfg.RespectForwardedHeaders = true
@@ -68,6 +70,8 @@ func NewTusHandler(authProvider auth.Provider, cfg tusd.Config, uploadPath strin
 	h.logger = monitor.NewModuleLogger(module)
 	h.authProvider = authProvider
 	h.composer = cfg.StoreComposer
+	h.uploadPath = uploadPath
+	go h.countUploads()
 
 	return h, nil
 }
@@ -199,7 +203,7 @@ func (h TusHandler) Notify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var rpcReq jsonrpc.RPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&rpcReq); err != nil {
+	if err := responses.DecodeJSON(r.Body, &rpcReq); err != nil {
 		w.Write(rpcerrors.NewJSONParseError(err).JSON())
 		observeFailure(metrics.GetDuration(r), metrics.FailureKindClientJSON)
 		return
@@ -248,6 +252,7 @@ func (h TusHandler) Notify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Write(serialized)
+	metrics.PublishUploads.WithLabelValues(metrics.UploadPublished).Inc()
 	observeSuccess(metrics.GetDuration(r))
 }
 
@@ -0,0 +1,189 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// AdaptFunc converts a result returned by the SDK to another shape. Results are fresh from the SDK
+// and not shared with anything yet, so they may be modified in place.
+type AdaptFunc func(result interface{}) interface{}
+
+// ResponseAdapter normalizes results returned by SDK servers of some versions, e.g. older servers
+// still running during a rollout, to the shape current servers return.
+type ResponseAdapter struct {
+	Name   string
+	Method string
+	// MinVersion (inclusive) and MaxVersion (exclusive) bound lbrynet versions the adapter applies to, empty means unbounded.
+	MinVersion string
+	MaxVersion string
+	Adapt      AdaptFunc
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   []ResponseAdapter
+)
+
+// RegisterAdapter makes callers apply the adapter to responses of matching SDK servers.
+// Adapters defined in SDKResponseAdapters config are applied after registered ones.
+func RegisterAdapter(a ResponseAdapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	adapters = append(adapters, a)
+}
+
+func responseAdapters() []ResponseAdapter {
+	adaptersMu.RLock()
+	list := append([]ResponseAdapter{}, adapters...)
+	adaptersMu.RUnlock()
+
+	for _, a := range config.GetSDKResponseAdapters() {
+		adapt := Chain(FillDefaults(a.Defaults), RenameFields(a.Renames))
+		if a.Items {
+			adapt = EachItem(adapt)
+		}
+		list = append(list, ResponseAdapter{Name: a.Name, Method: a.Method, MinVersion: a.MinVersion, MaxVersion: a.MaxVersion, Adapt: adapt})
+	}
+	return list
+}
+
+func (a ResponseAdapter) matches(method, version string) bool {
+	if a.Method != method {
+		return false
+	}
+	if a.MinVersion != "" && compareVersions(version, a.MinVersion) < 0 {
+		return false
+	}
+	if a.MaxVersion != "" && compareVersions(version, a.MaxVersion) >= 0 {
+		return false
+	}
+	return true
+}
+
+// postflightHookAdaptResponse applies response adapters matching the method and version of the SDK server
+// which has responded. Nothing is done when the version is not known.
+func postflightHookAdaptResponse(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	r := hctx.Response
+	if r == nil || r.Error != nil || hctx.SDKVersion == "" {
+		return nil, nil
+	}
+	var applied []string
+	for _, a := range responseAdapters() {
+		if !a.matches(hctx.Query.Method(), hctx.SDKVersion) {
+			continue
+		}
+		r.Result = a.Adapt(r.Result)
+		applied = append(applied, a.Name)
+		metrics.ProxySDKResponsesAdapted.WithLabelValues(hctx.Query.Method(), a.Name).Inc()
+	}
+	if len(applied) > 0 {
+		hctx.AddLogField("adapters", applied)
+	}
+	return nil, nil
+}
+
+// FillDefaults sets fields missing from a map result to default values.
+func FillDefaults(defaults map[string]interface{}) AdaptFunc {
+	return func(result interface{}) interface{} {
+		m, ok := result.(map[string]interface{})
+		if !ok {
+			return result
+		}
+		for k, v := range defaults {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+		return m
+	}
+}
+
+// RenameFields renames fields of a map result, from old to new names. Fields which are present under the new name are left alone.
+func RenameFields(renames map[string]string) AdaptFunc {
+	return func(result interface{}) interface{} {
+		m, ok := result.(map[string]interface{})
+		if !ok {
+			return result
+		}
+		for from, to := range renames {
+			v, ok := m[from]
+			if !ok {
+				continue
+			}
+			if _, ok := m[to]; !ok {
+				m[to] = v
+			}
+			delete(m, from)
+		}
+		return m
+	}
+}
+
+// EachItem applies adapt to every item of a paginated result, such as the one of claim_search.
+func EachItem(adapt AdaptFunc) AdaptFunc {
+	return func(result interface{}) interface{} {
+		m, ok := result.(map[string]interface{})
+		if !ok {
+			return result
+		}
+		items, ok := m["items"].([]interface{})
+		if !ok {
+			return result
+		}
+		for i, item := range items {
+			items[i] = adapt(item)
+		}
+		return m
+	}
+}
+
+// Chain applies adapters one after another.
+func Chain(fs ...AdaptFunc) AdaptFunc {
+	return func(result interface{}) interface{} {
+		for _, f := range fs {
+			result = f(result)
+		}
+		return result
+	}
+}
+
+// compareVersions compares dotted versions like "0.99.0" numerically, returning -1, 0 or 1.
+// Missing parts are treated as zeros, anything after the numbers in a part (like "-rc1") is ignored.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		if pa[i] < pb[i] {
+			return -1
+		}
+		if pa[i] > pb[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	var parts []int
+	for _, p := range strings.Split(strings.TrimPrefix(v, "v"), ".") {
+		end := 0
+		for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+			end++
+		}
+		n, _ := strconv.Atoi(p[:end])
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"0.99.0", "0.99.0", 0},
+		{"0.99", "0.99.0", 0},
+		{"0.99.0", "0.100.0", -1},
+		{"v0.101.1", "0.100.9", 1},
+		{"0.100.0rc1", "0.100.0", 0},
+		{"1.0.0", "0.200.0", 1},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, compareVersions(c.a, c.b), "%v vs %v", c.a, c.b)
+	}
+
+	a := ResponseAdapter{Method: MethodClaimSearch, MinVersion: "0.90.0", MaxVersion: "0.100.0"}
+	assert.True(t, a.matches(MethodClaimSearch, "0.90.0"))
+	assert.True(t, a.matches(MethodClaimSearch, "0.99.1"))
+	assert.False(t, a.matches(MethodClaimSearch, "0.100.0"))
+	assert.False(t, a.matches(MethodClaimSearch, "0.89.9"))
+	assert.False(t, a.matches(MethodResolve, "0.95.0"))
+}
+
+func TestAdaptFuncs(t *testing.T) {
+	adapt := EachItem(Chain(
+		FillDefaults(map[string]interface{}{"reposted": 0.0}),
+		RenameFields(map[string]string{"old_name": "new_name"}),
+	))
+	result := adapt(map[string]interface{}{
+		"page": 1.0,
+		"items": []interface{}{
+			map[string]interface{}{"old_name": "a"},
+			map[string]interface{}{"old_name": "b", "new_name": "c", "reposted": 5.0},
+			"not a claim",
+		},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"page": 1.0,
+		"items": []interface{}{
+			map[string]interface{}{"new_name": "a", "reposted": 0.0},
+			map[string]interface{}{"new_name": "c", "reposted": 5.0},
+			"not a claim",
+		},
+	}, result)
+
+	assert.Equal(t, "text", adapt("text"))
+	assert.Equal(t, map[string]interface{}{"total": 1.0}, adapt(map[string]interface{}{"total": 1.0}))
+}
+
+func TestCaller_AdaptResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {"items": [{"name": "one", "short_url": "lbry://one"}]}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	config.Override("SDKResponseAdapters", []map[string]interface{}{
+		{"name": "old-search", "method": MethodClaimSearch, "MaxVersion": "0.100.0", "items": true,
+			"defaults": map[string]interface{}{"reposted": 0}, "renames": map[string]string{"short_url": "url"}},
+	})
+	defer config.RestoreOverridden()
+
+	rt := sdkrouter.NewWithServers(&models.LbrynetServer{Name: "old", Address: srv.URL})
+	counter := metrics.ProxySDKResponsesAdapted.WithLabelValues(MethodClaimSearch, "old-search")
+	before := metrics.GetCounterValue(counter)
+
+	// Version is not known yet
+	c := NewCaller(srv.URL, 0)
+	c.Router = rt
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "one", "short_url": "lbry://one"}, res.Result.(map[string]interface{})["items"].([]interface{})[0])
+
+	rt.SetSDKVersion(srv.URL, "0.99.0")
+	res, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one"}))
+	require.NoError(t, err)
+	assert.Equal(t, "0.99.0", c.SDKVersion())
+	assert.Equal(t, map[string]interface{}{"name": "one", "url": "lbry://one", "reposted": 0}, res.Result.(map[string]interface{})["items"].([]interface{})[0])
+	assert.Equal(t, before+1, metrics.GetCounterValue(counter))
+
+	rt.SetSDKVersion(srv.URL, "0.100.0")
+	res, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "one", "short_url": "lbry://one"}, res.Result.(map[string]interface{})["items"].([]interface{})[0])
+	assert.Equal(t, before+1, metrics.GetCounterValue(counter))
+}
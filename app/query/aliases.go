@@ -0,0 +1,23 @@
+package query
+
+import (
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// maxAliasHops limits following aliases pointing to other aliases, so a misconfigured cycle can't loop forever.
+const maxAliasHops = 5
+
+// ResolveAlias returns the current name of method if it's an old name listed in MethodAliases.
+// Aliases of aliases are followed. ok is false if method is not an alias.
+func ResolveAlias(method string) (resolved string, ok bool) {
+	aliases := config.GetMethodAliases()
+	resolved = method
+	for i := 0; i < maxAliasHops; i++ {
+		next, found := aliases[resolved]
+		if !found || next == "" || next == resolved {
+			break
+		}
+		resolved = next
+	}
+	return resolved, resolved != method
+}
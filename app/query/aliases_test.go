@@ -0,0 +1,60 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestResolveAlias(t *testing.T) {
+	config.Override("MethodAliases", map[string]string{
+		"resolve_v0": "resolve",
+		"search_v0":  "search_v1",
+		"search_v1":  "claim_search",
+		"loop_a":     "loop_b",
+		"loop_b":     "loop_a",
+	})
+	defer config.RestoreOverridden()
+
+	m, ok := ResolveAlias("resolve_v0")
+	assert.True(t, ok)
+	assert.Equal(t, MethodResolve, m)
+
+	m, ok = ResolveAlias("search_v0")
+	assert.True(t, ok)
+	assert.Equal(t, MethodClaimSearch, m)
+
+	m, ok = ResolveAlias(MethodResolve)
+	assert.False(t, ok)
+	assert.Equal(t, MethodResolve, m)
+
+	// Cycles give up after a few hops instead of looping
+	_, ok = ResolveAlias("loop_a")
+	assert.True(t, ok)
+}
+
+func TestCaller_CallResolvesAliases(t *testing.T) {
+	config.Override("MethodAliases", map[string]string{"resolve_v0": "resolve"})
+	defer config.RestoreOverridden()
+
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received <- req.Method
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("resolve_v0", map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Equal(t, MethodResolve, <-received)
+}
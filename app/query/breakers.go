@@ -0,0 +1,52 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/breaker"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/scheduler"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ErrCircuitOpen is returned instead of calling the SDK while a circuit breaker guarding the call is open.
+var ErrCircuitOpen = errors.Base("circuit breaker is open")
+
+// Breakers keeps circuit breakers of SDK servers and methods configured in CircuitBreakers.
+var Breakers = breaker.NewRegistry()
+
+// sendThroughBreakers sends the query unless the breaker of the SDK server or the method is open.
+// Transport failures and timeouts count against both breakers, JSON-RPC errors and queue timeouts don't.
+func (c *Caller) sendThroughBreakers(q *Query) (*jsonrpc.RPCResponse, error) {
+	cfg := config.GetCircuitBreakers()
+	bs := []*breaker.Breaker{}
+	if s, ok := cfg.Methods[q.Method()]; ok && s.Threshold > 0 {
+		bs = append(bs, Breakers.Get(breaker.KindMethod, q.Method(), s))
+	}
+	if cfg.Endpoint.Threshold > 0 {
+		bs = append(bs, Breakers.Get(breaker.KindEndpoint, c.endpoint, cfg.Endpoint))
+	}
+	if len(bs) == 0 {
+		return c.SendQuery(q)
+	}
+
+	if !breaker.AllowAll(bs, time.Now()) {
+		metrics.ProxyCircuitBreakerRejected.WithLabelValues(q.Method()).Inc()
+		return nil, errors.Err(ErrCircuitOpen)
+	}
+	res, err := c.SendQuery(q)
+	for _, b := range bs {
+		if errors.Is(err, scheduler.ErrQueueTimeout) {
+			// The SDK hasn't been called, so there's nothing to judge it by
+			b.Release()
+		} else if err != nil {
+			b.Failure(time.Now())
+		} else {
+			b.Success()
+		}
+	}
+	return res, err
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/breaker"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_CircuitBreakers(t *testing.T) {
+	Breakers = breaker.NewRegistry()
+	defer func() { Breakers = breaker.NewRegistry() }()
+	config.Override("CircuitBreakers", map[string]interface{}{
+		"Endpoint": map[string]interface{}{"Threshold": 3, "Window": "1m", "Cooldown": "1m"},
+		"Methods": map[string]interface{}{
+			MethodClaimSearch: map[string]interface{}{"Threshold": 2, "Window": "1m", "Cooldown": "1m"},
+		},
+	})
+	defer config.RestoreOverridden()
+
+	var calls int32
+	var failing int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`)
+	}))
+	defer srv.Close()
+	c := NewCaller(srv.URL, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	// The method breaker is open now, other methods still get through
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+	assert.True(t, errors.Is(err, ErrCircuitOpen), fmt.Sprintf("unexpected error: %v", err))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&failing, 0)
+	res, err := c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// A third failure of the endpoint opens its breaker for all methods
+	atomic.StoreInt32(&failing, 1)
+	_, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.Error(t, err)
+	_, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	assert.True(t, errors.Is(err, ErrCircuitOpen), fmt.Sprintf("unexpected error: %v", err))
+
+	st := Breakers.Status(time.Now())
+	require.Len(t, st, 2)
+	assert.Equal(t, breaker.Status{Kind: breaker.KindEndpoint, Name: srv.URL, State: "open", OpenedAt: st[0].OpenedAt}, st[0])
+	assert.Equal(t, MethodClaimSearch, st[1].Name)
+	assert.Equal(t, "open", st[1].State)
+}
+
+func TestCaller_CircuitBreakerDegraded(t *testing.T) {
+	Breakers = breaker.NewRegistry()
+	defer func() { Breakers = breaker.NewRegistry() }()
+	config.Override("CircuitBreakers", map[string]interface{}{
+		"Methods": map[string]interface{}{
+			MethodClaimSearch: map[string]interface{}{"Threshold": 1, "Window": "1m", "Cooldown": "1m"},
+		},
+	})
+	defer config.RestoreOverridden()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+	c.SetDegradedHandler(MethodClaimSearch, 0, func(c *Caller, q *Query) (*jsonrpc.RPCResponse, error) {
+		return &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"items": []interface{}{}}}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+		require.NoError(t, err)
+		assert.True(t, IsDegraded(res))
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// Backend stores cached responses. Cache takes care of instrumenting backend operations
+// so implementations should only deal with storage.
+type Backend interface {
+	// Name is used as a metrics label.
+	Name() string
+	Get(key string) (interface{}, bool, error)
+	Set(key string, value interface{}, cost int64, ttl time.Duration) error
+	Delete(key string) error
+	Clear()
+	// Wait blocks until pending writes are applied.
+	Wait()
+}
+
+// TTLBackend is implemented by backends which can tell how long a stored value has left to live.
+type TTLBackend interface {
+	TTL(key string) (time.Duration, bool)
+}
+
+// memoryBackend keeps responses in process memory.
+// When snapshots are enabled it also keeps an index of stored keys as ristretto cannot list them.
+type memoryBackend struct {
+	*ristretto.Cache
+
+	indexMu sync.Mutex
+	index   map[string]indexEntry
+}
+
+type indexEntry struct {
+	cost    int64
+	expires time.Time
+}
+
+func newMemoryBackend(config *CacheConfig) (*memoryBackend, error) {
+	rc, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1e7,
+		MaxCost:     config.size,
+		BufferItems: 64, // number of keys per Get buffer
+		Metrics:     config.ristrettoMetrics,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b := &memoryBackend{Cache: rc}
+	if config.snapshots {
+		b.index = map[string]indexEntry{}
+	}
+	return b, nil
+}
+
+func (b *memoryBackend) Name() string {
+	return "memory"
+}
+
+func (b *memoryBackend) Get(key string) (interface{}, bool, error) {
+	v, ok := b.Cache.Get(key)
+	return v, ok, nil
+}
+
+func (b *memoryBackend) Set(key string, value interface{}, cost int64, ttl time.Duration) error {
+	b.Cache.SetWithTTL(key, value, cost, ttl)
+	if b.index != nil {
+		e := indexEntry{cost: cost}
+		if ttl > 0 {
+			e.expires = time.Now().Add(ttl)
+		}
+		b.indexMu.Lock()
+		b.index[key] = e
+		b.indexMu.Unlock()
+	}
+	return nil
+}
+
+func (b *memoryBackend) Delete(key string) error {
+	b.Cache.Del(key)
+	if b.index != nil {
+		b.indexMu.Lock()
+		delete(b.index, key)
+		b.indexMu.Unlock()
+	}
+	return nil
+}
+
+func (b *memoryBackend) Clear() {
+	b.Cache.Clear()
+	if b.index != nil {
+		b.indexMu.Lock()
+		b.index = map[string]indexEntry{}
+		b.indexMu.Unlock()
+	}
+}
+
+func (b *memoryBackend) TTL(key string) (time.Duration, bool) {
+	return b.Cache.GetTTL(key)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingBackend struct {
+	sets int
+}
+
+func (b *failingBackend) Name() string { return "failing" }
+func (b *failingBackend) Get(string) (interface{}, bool, error) {
+	return nil, false, errors.Err("connection lost")
+}
+func (b *failingBackend) Set(string, interface{}, int64, time.Duration) error {
+	b.sets++
+	return errors.Err("connection lost")
+}
+func (b *failingBackend) Delete(string) error {
+	return errors.Err("connection lost")
+}
+func (b *failingBackend) Clear() {}
+func (b *failingBackend) Wait()  {}
+
+func TestCacheBackendMetrics(t *testing.T) {
+	cacheLogger.Disable()
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+
+	params := map[string]interface{}{"urls": "backend-metrics"}
+	retriever := func() (interface{}, error) { return "ok", nil }
+
+	misses := metrics.GetCounterValue(metrics.ProxyQueryCacheMissCount.WithLabelValues("resolve", "memory"))
+	hits := metrics.GetCounterValue(metrics.ProxyQueryCacheHitCount.WithLabelValues("resolve", "memory"))
+
+	_, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	_, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+
+	assert.Equal(t, misses+1, metrics.GetCounterValue(metrics.ProxyQueryCacheMissCount.WithLabelValues("resolve", "memory")))
+	assert.Equal(t, hits+1, metrics.GetCounterValue(metrics.ProxyQueryCacheHitCount.WithLabelValues("resolve", "memory")))
+
+	m := metrics.GetMetric(metrics.ProxyQueryCacheOperationDurations.WithLabelValues("memory", "get").(prometheus.Histogram))
+	assert.GreaterOrEqual(t, m.Histogram.GetSampleCount(), uint64(2))
+}
+
+func TestCacheFailingBackend(t *testing.T) {
+	cacheLogger.Disable()
+	b := &failingBackend{}
+	c := NewWithBackend(DefaultConfig(), b)
+
+	retrievals := 0
+	retriever := func() (interface{}, error) {
+		retrievals++
+		return "ok", nil
+	}
+	for i := 0; i < 2; i++ {
+		res, err := c.Retrieve("resolve", nil, retriever)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", res)
+	}
+	assert.Equal(t, 2, retrievals)
+	assert.Equal(t, 2, b.sets)
+	assert.EqualValues(t, 4, metrics.GetCounterValue(metrics.ProxyQueryCacheErrorCount.WithLabelValues("resolve", "failing")))
+}
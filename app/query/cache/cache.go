@@ -12,23 +12,47 @@ import (
 	"github.com/lbryio/lbrytv/internal/monitor"
 	"github.com/ybbus/jsonrpc"
 
-	"github.com/dgraph-io/ristretto"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/singleflight"
 )
 
 type Retriever func() (interface{}, error)
 
+// Cache statuses reported in Info.
+const (
+	StatusHit  = "HIT"
+	StatusMiss = "MISS"
+)
+
+// Info describes how a response has been served by the cache.
+type Info struct {
+	Status string
+	// Key is the hash the response is stored under.
+	Key string
+	// TTL is how long the response stays cached, zero if unknown.
+	TTL time.Duration
+}
+
 type CacheConfig struct {
 	size             int64
 	ristrettoMetrics bool
+	ttls             map[string]time.Duration
+	ttlSource        func() map[string]time.Duration
+	ttlFuncs         map[string]TTLFunc
+	notFoundTTLs     map[string]time.Duration
+	priorities       map[string]float64
+	snapshots        bool
+	shadow           *shadowConfig
 }
 
 // Cache manages SDK query responses.
 type Cache struct {
 	*CacheConfig
-	cache *ristretto.Cache
-	sf    *singleflight.Group
+	backend Backend
+	sf      *singleflight.Group
+	salt    string
+	maxTTL  time.Duration
+	pins    *pinSet
 }
 
 var cacheLogger = monitor.NewModuleLogger("cache")
@@ -39,22 +63,58 @@ func DefaultConfig() *CacheConfig {
 	}
 }
 
+// New creates a cache keeping responses in memory, or in backends set in the shadow config.
 func New(config *CacheConfig) (*Cache, error) {
-	rc, err := ristretto.NewCache(&ristretto.Config{
-		NumCounters: 1e7,
-		MaxCost:     config.size,
-		BufferItems: 64, // number of keys per Get buffer
-		Metrics:     config.ristrettoMetrics,
-	})
+	primary := "memory"
+	if config.priorities != nil {
+		primary = "priority"
+	}
+	if config.shadow == nil {
+		b, err := newBackend(primary, config)
+		if err != nil {
+			return nil, err
+		}
+		return NewWithBackend(config, b), nil
+	}
+
+	if config.shadow.primary != "" {
+		primary = config.shadow.primary
+	}
+	pb, err := newBackend(primary, config)
 	if err != nil {
 		return nil, err
 	}
-	c := Cache{
+	sb, err := newBackend(config.shadow.shadow, config)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithBackend(config, newShadowBackend(pb, sb, config.shadow.queueSize, config.shadow.workers)), nil
+}
+
+// NewWithBackend creates a cache keeping responses in the supplied backend.
+func NewWithBackend(config *CacheConfig, backend Backend) *Cache {
+	return &Cache{
 		CacheConfig: config,
-		cache:       rc,
+		backend:     backend,
 		sf:          &singleflight.Group{},
+		pins:        newPinSet(),
 	}
-	return &c, nil
+}
+
+// WithSalt returns a view of the cache where keys also depend on salt, so the same query
+// saved with different salts, or without one, is kept apart. Storage is shared with the original cache.
+func (c *Cache) WithSalt(salt string) *Cache {
+	cc := *c
+	cc.salt = salt
+	return &cc
+}
+
+// WithMaxTTL returns a view of the cache where responses it stores expire after ttl at the latest,
+// even if they'd be kept longer otherwise. Storage is shared with the original cache.
+func (c *Cache) WithMaxTTL(ttl time.Duration) *Cache {
+	cc := *c
+	cc.maxTTL = ttl
+	return &cc
 }
 
 func (c *CacheConfig) Size(size int64) *CacheConfig {
@@ -62,51 +122,197 @@ func (c *CacheConfig) Size(size int64) *CacheConfig {
 	return c
 }
 
+// Snapshots makes the in-memory cache keep track of its keys so it can be saved with SaveSnapshot.
+func (c *CacheConfig) Snapshots() *CacheConfig {
+	c.snapshots = true
+	return c
+}
+
 // Retrieve earlier saved server response by method and query params.
 func (c *Cache) Retrieve(method string, params interface{}, retriever Retriever) (interface{}, error) {
+	res, _, err := c.RetrieveWithInfo(method, params, retriever)
+	return res, err
+}
+
+// RetrieveWithInfo is like Retrieve but also tells if the response came from the cache.
+func (c *Cache) RetrieveWithInfo(method string, params interface{}, retriever Retriever) (interface{}, *Info, error) {
 	k, err := c.hash(method, params)
 	l := cacheLogger.WithFields(logrus.Fields{"key": k})
 
 	if err != nil {
 		l.Error("unable to produce cache key", "params", params, "err", err)
-		return nil, err
+		return nil, nil, err
 	}
-	res, ok := c.cache.Get(k)
+	res, ok := c.get(method, k, l)
 	if !ok {
-		metrics.ProxyQueryCacheMissCount.WithLabelValues(method).Inc()
+		metrics.ProxyQueryCacheMissCount.WithLabelValues(method, c.backend.Name()).Inc()
 		l.Debug("cache miss")
 		if retriever == nil {
-			return nil, errors.New("retriever is nil")
-		}
-		res, err, _ = c.sf.Do(k, retriever)
-		if err != nil {
-			l.Error("retriever failed", "err", err)
-			return nil, err
+			return nil, nil, errors.New("retriever is nil")
 		}
+		return c.retrieveAndSet(method, k, retriever, l)
+	}
+	metrics.ProxyQueryCacheHitCount.WithLabelValues(method, c.backend.Name()).Inc()
+	if _, ok := c.notFoundTTLs[method]; ok && isNotFound(res) {
+		metrics.ProxyQueryCacheNotFoundHitCount.WithLabelValues(method).Inc()
+	}
+	l.Debug("cache hit")
+	info := &Info{Status: StatusHit, Key: k}
+	if b, ok := c.backend.(TTLBackend); ok {
+		info.TTL, _ = b.TTL(k)
+	}
+	return res, info, nil
+}
 
-		resp, ok := res.(jsonrpc.RPCResponse)
-		if ok && resp.Error != nil {
-			l.Debug("rpc error reponse received, not caching")
-			return res, nil
-		}
+// Get returns a value saved for method and params, without retrieving it when it's missing.
+func (c *Cache) Get(method string, params interface{}) (interface{}, bool) {
+	k, err := c.hash(method, params)
+	if err != nil {
+		return nil, false
+	}
+	l := cacheLogger.WithFields(logrus.Fields{"key": k})
+	res, ok := c.get(method, k, l)
+	if !ok {
+		metrics.ProxyQueryCacheMissCount.WithLabelValues(method, c.backend.Name()).Inc()
+		return nil, false
+	}
+	metrics.ProxyQueryCacheHitCount.WithLabelValues(method, c.backend.Name()).Inc()
+	return res, true
+}
+
+// Set saves a value for method and params, it expires after TTL configured for the method.
+func (c *Cache) Set(method string, params interface{}, value interface{}) error {
+	k, err := c.hash(method, params)
+	if err != nil {
+		return err
+	}
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.set(method, k, value, int64(len(enc)), c.entryTTL(method, value), cacheLogger.WithFields(logrus.Fields{"key": k}))
+	return nil
+}
+
+// Refresh skips looking up the saved response and calls retriever straight away,
+// replacing the saved response with the fresh one.
+func (c *Cache) Refresh(method string, params interface{}, retriever Retriever) (interface{}, error) {
+	res, _, err := c.RefreshWithInfo(method, params, retriever)
+	return res, err
+}
 
-		enc, err := json.Marshal(res)
+// RefreshWithInfo is like Refresh but also describes how the response has been cached.
+func (c *Cache) RefreshWithInfo(method string, params interface{}, retriever Retriever) (interface{}, *Info, error) {
+	k, err := c.hash(method, params)
+	l := cacheLogger.WithFields(logrus.Fields{"key": k})
+
+	if err != nil {
+		l.Error("unable to produce cache key", "params", params, "err", err)
+		return nil, nil, err
+	}
+	if retriever == nil {
+		return nil, nil, errors.New("retriever is nil")
+	}
+	metrics.ProxyQueryCacheBypassCount.WithLabelValues(method).Inc()
+	l.Debug("cache bypass")
+	return c.retrieveAndSet(method, k, retriever, l)
+}
+
+func (c *Cache) retrieveAndSet(method, k string, retriever Retriever, l *logrus.Entry) (interface{}, *Info, error) {
+	info := &Info{Status: StatusMiss, Key: k}
+	res, err, _ := c.sf.Do(k, retriever)
+	if err != nil {
+		l.Error("retriever failed", "err", err)
+		return nil, nil, err
+	}
+
+	resp, ok := res.(jsonrpc.RPCResponse)
+	if ok && resp.Error != nil {
+		l.Debug("rpc error reponse received, not caching")
+		return res, info, nil
+	}
+
+	enc, err := json.Marshal(res)
+	if err != nil {
+		l.Error("failed to measure response size for cache", "err", err)
+		return nil, nil, err
+	}
+	ttl := c.entryTTL(method, res)
+	metrics.ProxyQueryCacheTTL.WithLabelValues(method).Observe(ttl.Seconds())
+	l.WithFields(logrus.Fields{"size": len(enc), "ttl": ttl}).Debug("caching value")
+	c.set(method, k, res, int64(len(enc)), ttl, l)
+	info.TTL = ttl
+	return res, info, nil
+}
+
+// get fetches a value from pinned entries or the backend, treating backend errors as misses.
+func (c *Cache) get(method, k string, l *logrus.Entry) (interface{}, bool) {
+	if v, ok := c.pins.get(k); ok {
+		return v, true
+	}
+	start := time.Now()
+	res, ok, err := c.backend.Get(k)
+	c.observeOperation("get", start)
+	if err != nil {
+		metrics.ProxyQueryCacheErrorCount.WithLabelValues(method, c.backend.Name()).Inc()
+		l.Error("cache backend get failed", "err", err)
+		return nil, false
+	}
+	return res, ok
+}
+
+func (c *Cache) set(method, k string, res interface{}, cost int64, ttl time.Duration, l *logrus.Entry) {
+	if c.pins.set(k, res) {
+		return
+	}
+	var err error
+	start := time.Now()
+	if pb, ok := c.backend.(PriorityBackend); ok {
+		err = pb.SetWithPriority(k, res, cost, ttl, c.getPriority(method))
+	} else {
+		err = c.backend.Set(k, res, cost, ttl)
+	}
+	c.observeOperation("set", start)
+	if err != nil {
+		metrics.ProxyQueryCacheErrorCount.WithLabelValues(method, c.backend.Name()).Inc()
+		l.Error("cache backend set failed", "err", err)
+	}
+}
+
+// entryTTL returns TTL for a response of method, limited to maxTTL if it's set.
+func (c *Cache) entryTTL(method string, res interface{}) time.Duration {
+	ttl := c.getTTL(method, res)
+	if c.maxTTL > 0 && c.maxTTL < ttl {
+		return c.maxTTL
+	}
+	return ttl
+}
+
+// Invalidate removes responses stored under keys (see Info.Key), so they're retrieved again when next requested.
+// Pinned entries are kept, they get refreshed on their own.
+func (c *Cache) Invalidate(keys ...string) error {
+	for _, k := range keys {
+		start := time.Now()
+		err := c.backend.Delete(k)
+		c.observeOperation("delete", start)
 		if err != nil {
-			l.Error("failed to measure response size for cache", "err", err)
-			return nil, err
+			return err
 		}
-		l.WithFields(logrus.Fields{"size": len(enc)}).Debug("caching value")
-		c.cache.SetWithTTL(k, res, int64(len(enc)), 3*time.Minute)
-		return res, nil
 	}
-	metrics.ProxyQueryCacheHitCount.WithLabelValues(method).Inc()
-	l.Debug("cache hit")
-	return res, nil
+	return nil
+}
+
+func (c *Cache) observeOperation(op string, start time.Time) {
+	metrics.ProxyQueryCacheOperationDurations.WithLabelValues(c.backend.Name(), op).Observe(time.Since(start).Seconds())
 }
 
 func (c *Cache) hash(method string, params interface{}) (string, error) {
+	prefix := method
+	if c.salt != "" {
+		prefix = fmt.Sprintf("%v|%v", method, c.salt)
+	}
 	if params == nil {
-		return fmt.Sprintf("%v|nil", method), nil
+		return fmt.Sprintf("%v|nil", prefix), nil
 	}
 	h := sha256.New()
 	enc, err := json.Marshal(params)
@@ -118,18 +324,13 @@ func (c *Cache) hash(method string, params interface{}) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("%v|%v", method, hex.EncodeToString(h.Sum(nil))), nil
+	return fmt.Sprintf("%v|%v", prefix, hex.EncodeToString(h.Sum(nil))), nil
 }
 
 func (c *Cache) Flush() {
-	c.cache.Clear()
+	c.backend.Clear()
 }
 
 func (c *Cache) Wait() {
-	c.cache.Wait()
-}
-
-// count returns the number of non-expired items stored in cache.
-func (c *Cache) count() uint64 {
-	return c.cache.Metrics.KeysAdded() - c.cache.Metrics.KeysEvicted()
+	c.backend.Wait()
 }
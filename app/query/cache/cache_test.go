@@ -57,8 +57,8 @@ func TestCache(t *testing.T) {
 	}
 	wg.Wait()
 
-	c.cache.Wait()
-	assert.EqualValues(t, 1, c.cache.Metrics.KeysAdded())
+	c.Wait()
+	assert.EqualValues(t, 1, c.backend.(*memoryBackend).Metrics.KeysAdded())
 	assert.EqualValues(t, 1, retrievals)
 }
 
@@ -100,7 +100,162 @@ func TestCacheNoError(t *testing.T) {
 	}
 	wg.Wait()
 
-	c.cache.Wait()
-	assert.EqualValues(t, 0, c.cache.Metrics.KeysAdded())
+	c.Wait()
+	assert.EqualValues(t, 0, c.backend.(*memoryBackend).Metrics.KeysAdded())
 	assert.EqualValues(t, 1, retrievals)
 }
+
+func TestCacheRefresh(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+
+	params := map[string]interface{}{"urls": "what"}
+	retrievals := 0
+	retriever := func() (interface{}, error) {
+		retrievals++
+		return retrievals, nil
+	}
+
+	res, err := c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res)
+	c.Wait()
+
+	res, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res)
+
+	res, err = c.Refresh("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res)
+	c.Wait()
+
+	res, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res)
+	assert.Equal(t, 2, retrievals)
+}
+
+func TestCacheWithSalt(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+
+	params := map[string]interface{}{"urls": "what"}
+	retrieverFor := func(v string) Retriever {
+		return func() (interface{}, error) { return v, nil }
+	}
+
+	res, err := c.Retrieve("resolve", params, retrieverFor("plain"))
+	require.NoError(t, err)
+	assert.Equal(t, "plain", res)
+	res, err = c.WithSalt("a").Retrieve("resolve", params, retrieverFor("variant a"))
+	require.NoError(t, err)
+	assert.Equal(t, "variant a", res)
+	res, err = c.WithSalt("b").Retrieve("resolve", params, retrieverFor("variant b"))
+	require.NoError(t, err)
+	assert.Equal(t, "variant b", res)
+	c.Wait()
+
+	for salt, expected := range map[string]string{"": "plain", "a": "variant a", "b": "variant b"} {
+		sc := c
+		if salt != "" {
+			sc = c.WithSalt(salt)
+		}
+		res, err = sc.Retrieve("resolve", params, retrieverFor("fresh"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, res, "salt %q", salt)
+	}
+
+	k, err := c.hash("resolve", params)
+	require.NoError(t, err)
+	sk, err := c.WithSalt("a").hash("resolve", params)
+	require.NoError(t, err)
+	assert.NotEqual(t, k, sk)
+	assert.Equal(t, "", c.salt)
+}
+
+func TestCacheRetrieveWithInfo(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+	params := map[string]interface{}{"urls": "what"}
+	retriever := func() (interface{}, error) { return &jsonrpc.RPCResponse{Result: "ok"}, nil }
+
+	_, info, err := c.RetrieveWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, StatusMiss, info.Status)
+	assert.Equal(t, DefaultTTL, info.TTL)
+	assert.NotEmpty(t, info.Key)
+	c.Wait()
+
+	_, hitInfo, err := c.RetrieveWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, StatusHit, hitInfo.Status)
+	assert.Equal(t, info.Key, hitInfo.Key)
+	assert.Greater(t, int64(hitInfo.TTL), int64(0))
+	assert.LessOrEqual(t, int64(hitInfo.TTL), int64(DefaultTTL))
+
+	_, info, err = c.RefreshWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, StatusMiss, info.Status)
+}
+
+func TestCacheGetSet(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+
+	_, ok := c.Get("resolve_claim_ids", "abc")
+	assert.False(t, ok)
+	require.NoError(t, c.Set("resolve_claim_ids", "abc", map[string]interface{}{"claim_id": "abc"}))
+	c.Wait()
+	v, ok := c.Get("resolve_claim_ids", "abc")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"claim_id": "abc"}, v)
+
+	_, ok = c.WithSalt("x").Get("resolve_claim_ids", "abc")
+	assert.False(t, ok)
+}
+
+func TestCacheWithMaxTTL(t *testing.T) {
+	c, err := New(DefaultConfig().MethodTTL("resolve", time.Minute))
+	require.NoError(t, err)
+	params := map[string]interface{}{"urls": "what"}
+	retriever := func() (interface{}, error) { return "ok", nil }
+
+	_, info, err := c.WithMaxTTL(10*time.Second).RefreshWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, info.TTL)
+
+	// Longer TTLs are not extended
+	_, info, err = c.WithMaxTTL(time.Hour).RefreshWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, info.TTL)
+
+	_, info, err = c.RefreshWithInfo("resolve", params, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, info.TTL)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	for _, config := range []*CacheConfig{DefaultConfig(), DefaultConfig().MethodPriorities(map[string]float64{"resolve": 2})} {
+		c, err := New(config)
+		require.NoError(t, err)
+		retrieverFor := func(v string) Retriever {
+			return func() (interface{}, error) { return v, nil }
+		}
+
+		_, info, err := c.RetrieveWithInfo("resolve", map[string]interface{}{"urls": "what"}, retrieverFor("what"))
+		require.NoError(t, err)
+		_, err = c.Retrieve("resolve", map[string]interface{}{"urls": "other"}, retrieverFor("other"))
+		require.NoError(t, err)
+		c.Wait()
+
+		require.NoError(t, c.Invalidate(info.Key, "resolve|nonexistent"))
+		c.Wait()
+		res, err := c.Retrieve("resolve", map[string]interface{}{"urls": "what"}, retrieverFor("fresh"))
+		require.NoError(t, err)
+		assert.Equal(t, "fresh", res)
+		res, err = c.Retrieve("resolve", map[string]interface{}{"urls": "other"}, retrieverFor("fresh"))
+		require.NoError(t, err)
+		assert.Equal(t, "other", res)
+	}
+}
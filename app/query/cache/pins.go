@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// Pin identifies a cache entry which is kept until unpinned.
+type Pin struct {
+	Method string
+	Params interface{}
+}
+
+// pinSet keeps values of pinned entries apart from the backend, so they're never evicted or expired.
+// Values of pinned keys are nil until they're set for the first time.
+type pinSet struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+func newPinSet() *pinSet {
+	return &pinSet{values: map[string]interface{}{}}
+}
+
+// SetPins replaces the list of pinned entries. Values of entries which stay pinned are kept,
+// newly pinned entries are filled in when they're next set or retrieved. Unpinned entries are dropped.
+func (c *Cache) SetPins(pins []Pin) error {
+	keys := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		k, err := c.hash(p.Method, p.Params)
+		if err != nil {
+			return err
+		}
+		keys[k] = true
+	}
+
+	c.pins.mu.Lock()
+	defer c.pins.mu.Unlock()
+	values := make(map[string]interface{}, len(keys))
+	for k := range keys {
+		values[k] = c.pins.values[k]
+	}
+	c.pins.values = values
+	metrics.ProxyQueryCachePinned.Set(float64(c.pins.filled()))
+	return nil
+}
+
+// Pinned returns the number of pinned entries which have a value.
+func (c *Cache) Pinned() int {
+	c.pins.mu.RLock()
+	defer c.pins.mu.RUnlock()
+	return c.pins.filled()
+}
+
+func (s *pinSet) filled() int {
+	n := 0
+	for _, v := range s.values {
+		if v != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *pinSet) get(k string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v := s.values[k]
+	return v, v != nil
+}
+
+// set stores the value if k is pinned and returns false otherwise.
+// Error responses don't replace the value, so a failed refresh leaves the pinned entry as it was.
+func (s *pinSet) set(k string, v interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[k]; !ok {
+		return false
+	}
+	if r, ok := v.(*jsonrpc.RPCResponse); ok && r.Error != nil {
+		return true
+	}
+	s.values[k] = v
+	metrics.ProxyQueryCachePinned.Set(float64(s.filled()))
+	return true
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestPins(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+	featured := map[string]interface{}{"urls": []interface{}{"lbry://featured"}}
+	other := map[string]interface{}{"urls": []interface{}{"lbry://other"}}
+	require.NoError(t, c.SetPins([]Pin{{Method: "resolve", Params: featured}}))
+	assert.Equal(t, 0, c.Pinned())
+
+	res := &jsonrpc.RPCResponse{Result: "featured"}
+	require.NoError(t, c.Set("resolve", featured, res))
+	require.NoError(t, c.Set("resolve", other, &jsonrpc.RPCResponse{Result: "other"}))
+	c.Wait()
+	assert.Equal(t, 1, c.Pinned())
+	assert.Equal(t, 1.0, metrics.GetGaugeValue(metrics.ProxyQueryCachePinned))
+
+	// Pinned entries are kept when the backend drops everything
+	c.Flush()
+	v, ok := c.Get("resolve", featured)
+	require.True(t, ok)
+	assert.Equal(t, res, v)
+	_, ok = c.Get("resolve", other)
+	assert.False(t, ok)
+
+	// Error responses don't replace pinned ones
+	require.NoError(t, c.Set("resolve", featured, &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "sdk down"}}))
+	v, _ = c.Get("resolve", featured)
+	assert.Equal(t, res, v)
+
+	// Values survive pin list reloads while they stay pinned
+	require.NoError(t, c.SetPins([]Pin{{Method: "resolve", Params: featured}, {Method: "resolve", Params: other}}))
+	assert.Equal(t, 1, c.Pinned())
+	_, ok = c.WithSalt("x").Get("resolve", featured)
+	assert.False(t, ok)
+
+	require.NoError(t, c.SetPins(nil))
+	assert.Equal(t, 0, c.Pinned())
+	_, ok = c.Get("resolve", featured)
+	assert.False(t, ok)
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// expiredSweepInterval limits how often the priority backend looks for expired entries when it runs out of room.
+const expiredSweepInterval = time.Second
+
+// PriorityBackend is implemented by backends which take into account how valuable entries are
+// when deciding which ones to evict.
+type PriorityBackend interface {
+	SetWithPriority(key string, value interface{}, cost int64, ttl time.Duration, priority float64) error
+}
+
+// MethodPriorities makes the in-memory cache evict entries using GreedyDual-Size-Frequency instead of
+// the default admission policy. Priority is how expensive a response of the method is to get from the SDK,
+// so entries which are costlier, smaller and requested more often are kept longer. Methods not listed have priority 1.
+// Such cache cannot be saved with SaveSnapshot.
+func (c *CacheConfig) MethodPriorities(priorities map[string]float64) *CacheConfig {
+	c.priorities = priorities
+	return c
+}
+
+func (c *CacheConfig) getPriority(method string) float64 {
+	if p, ok := c.priorities[method]; ok && p > 0 {
+		return p
+	}
+	return 1
+}
+
+type priorityEntry struct {
+	key     string
+	value   interface{}
+	cost    int64
+	weight  float64
+	hits    float64
+	h       float64
+	expires time.Time
+	index   int
+}
+
+// priorityQueue is a min-heap of entries by their GDSF value.
+type priorityQueue []*priorityEntry
+
+func (q priorityQueue) Len() int           { return len(q) }
+func (q priorityQueue) Less(i, j int) bool { return q[i].h < q[j].h }
+func (q priorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *priorityQueue) Push(x interface{}) {
+	e := x.(*priorityEntry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return e
+}
+
+// priorityBackend keeps responses in process memory, evicting the ones with the lowest
+// GDSF value L + hits * priority / cost first, where L is the value of the last evicted entry.
+// L makes entries which haven't been requested for a while lose to the newer ones.
+type priorityBackend struct {
+	mu        sync.Mutex
+	maxCost   int64
+	used      int64
+	inflation float64
+	lastSweep time.Time
+	entries   map[string]*priorityEntry
+	queue     priorityQueue
+}
+
+func newPriorityBackend(maxCost int64) *priorityBackend {
+	return &priorityBackend{maxCost: maxCost, entries: map[string]*priorityEntry{}}
+}
+
+func (b *priorityBackend) Name() string {
+	return "memory"
+}
+
+func (b *priorityBackend) Get(key string) (interface{}, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(time.Now()) {
+		b.remove(e)
+		return nil, false, nil
+	}
+	e.hits++
+	b.score(e)
+	heap.Fix(&b.queue, e.index)
+	return e.value, true, nil
+}
+
+func (b *priorityBackend) Set(key string, value interface{}, cost int64, ttl time.Duration) error {
+	return b.SetWithPriority(key, value, cost, ttl, 1)
+}
+
+func (b *priorityBackend) SetWithPriority(key string, value interface{}, cost int64, ttl time.Duration, priority float64) error {
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > b.maxCost {
+		return nil
+	}
+	now := time.Now()
+	e := &priorityEntry{key: key, value: value, cost: cost, weight: priority, hits: 1}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if old, ok := b.entries[key]; ok {
+		e.hits += old.hits
+		b.remove(old)
+	}
+	if b.used+cost > b.maxCost && now.Sub(b.lastSweep) >= expiredSweepInterval {
+		b.lastSweep = now
+		for _, old := range b.entries {
+			if old.expired(now) {
+				b.remove(old)
+			}
+		}
+	}
+	for b.used+cost > b.maxCost {
+		victim := b.queue[0]
+		b.inflation = victim.h
+		b.remove(victim)
+	}
+	b.score(e)
+	b.entries[key] = e
+	heap.Push(&b.queue, e)
+	b.used += cost
+	return nil
+}
+
+func (b *priorityBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.entries[key]; ok {
+		b.remove(e)
+	}
+	return nil
+}
+
+func (b *priorityBackend) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = map[string]*priorityEntry{}
+	b.queue = nil
+	b.used = 0
+	b.inflation = 0
+}
+
+// Wait returns right away as writes are applied synchronously.
+func (b *priorityBackend) Wait() {}
+
+func (b *priorityBackend) TTL(key string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return 0, false
+	}
+	if e.expires.IsZero() {
+		return 0, true
+	}
+	return time.Until(e.expires), true
+}
+
+func (b *priorityBackend) score(e *priorityEntry) {
+	e.h = b.inflation + e.hits*e.weight/float64(e.cost)
+}
+
+func (b *priorityBackend) remove(e *priorityEntry) {
+	heap.Remove(&b.queue, e.index)
+	delete(b.entries, e.key)
+	b.used -= e.cost
+}
+
+func (e *priorityEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lruBackend is a plain LRU cache to compare the priority backend against.
+type lruBackend struct {
+	maxCost, used int64
+	order         *list.List
+	entries       map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	cost int64
+}
+
+func newLRUBackend(maxCost int64) *lruBackend {
+	return &lruBackend{maxCost: maxCost, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (b *lruBackend) Get(key string) bool {
+	el, ok := b.entries[key]
+	if ok {
+		b.order.MoveToFront(el)
+	}
+	return ok
+}
+
+func (b *lruBackend) Set(key string, cost int64) {
+	for b.used+cost > b.maxCost {
+		el := b.order.Back()
+		e := el.Value.(*lruEntry)
+		b.order.Remove(el)
+		delete(b.entries, e.key)
+		b.used -= e.cost
+	}
+	b.entries[key] = b.order.PushFront(&lruEntry{key, cost})
+	b.used += cost
+}
+
+func TestPriorityBackendEviction(t *testing.T) {
+	b := newPriorityBackend(100)
+	require.NoError(t, b.SetWithPriority("expensive", 1, 40, 0, 10))
+	require.NoError(t, b.SetWithPriority("cheap1", 2, 30, 0, 1))
+	require.NoError(t, b.SetWithPriority("cheap2", 3, 30, 0, 1))
+	require.NoError(t, b.SetWithPriority("cheap3", 4, 30, 0, 1))
+
+	_, ok, _ := b.Get("expensive")
+	assert.True(t, ok, "expensive entry is kept even though it's the oldest")
+	_, ok, _ = b.Get("cheap1")
+	assert.False(t, ok)
+	_, ok, _ = b.Get("cheap3")
+	assert.True(t, ok)
+	assert.EqualValues(t, 100, b.used)
+
+	// Oversized entries are not stored
+	require.NoError(t, b.SetWithPriority("huge", 5, 101, 0, 100))
+	_, ok, _ = b.Get("huge")
+	assert.False(t, ok)
+
+	require.NoError(t, b.Set("expiring", 6, 10, time.Millisecond))
+	ttl, ok := b.TTL("expiring")
+	assert.True(t, ok)
+	assert.LessOrEqual(t, int64(ttl), int64(time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+	_, ok, _ = b.Get("expiring")
+	assert.False(t, ok)
+
+	b.Clear()
+	_, ok, _ = b.Get("expensive")
+	assert.False(t, ok)
+	assert.EqualValues(t, 0, b.used)
+}
+
+func TestPriorityBackendMixedWorkload(t *testing.T) {
+	type request struct {
+		key      string
+		cost     int64
+		priority float64
+	}
+	// Many small cheap resolves and fewer large expensive claim searches, both with a popular head
+	rnd := rand.New(rand.NewSource(1))
+	resolves := rand.NewZipf(rnd, 1.1, 1, 999)
+	searches := rand.NewZipf(rnd, 1.1, 1, 199)
+	workload := make([]request, 50000)
+	for i := range workload {
+		if rnd.Float64() < 0.8 {
+			workload[i] = request{fmt.Sprintf("resolve:%v", resolves.Uint64()), 1000, 1}
+		} else {
+			workload[i] = request{fmt.Sprintf("claim_search:%v", searches.Uint64()), 5000, 20}
+		}
+	}
+
+	const size = 200000
+	lru := newLRUBackend(size)
+	gdsf := newPriorityBackend(size)
+	var lruMissCost, gdsfMissCost float64
+	for _, r := range workload {
+		if !lru.Get(r.key) {
+			lruMissCost += r.priority
+			lru.Set(r.key, r.cost)
+		}
+		if _, ok, _ := gdsf.Get(r.key); !ok {
+			gdsfMissCost += r.priority
+			require.NoError(t, gdsf.SetWithPriority(r.key, r.key, r.cost, 0, r.priority))
+		}
+	}
+	t.Logf("cost of misses: lru %.0f, gdsf %.0f", lruMissCost, gdsfMissCost)
+	assert.Less(t, gdsfMissCost, lruMissCost*0.9)
+	assert.LessOrEqual(t, gdsf.used, int64(size))
+}
+
+func TestCacheMethodPriorities(t *testing.T) {
+	c, err := New(DefaultConfig().Size(10000).MethodPriorities(map[string]float64{"claim_search": 10}))
+	require.NoError(t, err)
+	_, ok := c.backend.(*priorityBackend)
+	require.True(t, ok)
+
+	search := map[string]interface{}{"text": "priority"}
+	require.NoError(t, c.Set("claim_search", search, strings.Repeat("a", 3000)))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.Set("resolve", map[string]interface{}{"urls": i}, strings.Repeat("a", 3000)))
+	}
+	_, ok = c.Get("claim_search", search)
+	assert.True(t, ok)
+	_, ok = c.Get("resolve", map[string]interface{}{"urls": 0})
+	assert.False(t, ok)
+	_, ok = c.Get("resolve", map[string]interface{}{"urls": 4})
+	assert.True(t, ok)
+
+	_, err = c.SaveSnapshot(t.TempDir() + "/snapshot")
+	assert.Equal(t, ErrSnapshotsUnsupported, err)
+}
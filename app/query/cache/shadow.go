@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Results of shadow cache operations recorded in metrics.
+const (
+	ShadowMatch    = "match"
+	ShadowMismatch = "mismatch"
+	// ShadowMissing is recorded when the shadow backend doesn't have a value the primary one has.
+	ShadowMissing = "missing"
+	// ShadowExtra is recorded when the shadow backend has a value the primary one doesn't.
+	ShadowExtra   = "extra"
+	ShadowError   = "error"
+	ShadowDropped = "dropped"
+	ShadowOK      = "ok"
+)
+
+// BackendFactory creates a cache backend according to the cache config.
+type BackendFactory func(config *CacheConfig) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{
+		"memory": func(config *CacheConfig) (Backend, error) {
+			return newMemoryBackend(config)
+		},
+		"priority": func(config *CacheConfig) (Backend, error) {
+			return newPriorityBackend(config.size), nil
+		},
+	}
+)
+
+// RegisterBackend makes a backend available to New under name, for use as a primary or shadow one.
+func RegisterBackend(name string, f BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = f
+}
+
+func newBackend(name string, config *CacheConfig) (Backend, error) {
+	backendsMu.RLock()
+	f, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, errors.Err("unknown cache backend: %v", name)
+	}
+	return f(config)
+}
+
+type shadowConfig struct {
+	primary   string
+	shadow    string
+	queueSize int
+	workers   int
+}
+
+// Shadow makes the cache mirror its operations to the shadow backend, e.g. to validate a new backend
+// under real traffic before switching to it. Responses are always served from the primary backend,
+// an empty primary name keeps the default one. Operations on the shadow backend are run by workers in the background,
+// with up to queueSize of them waiting, so they never slow down queries. Operations which don't fit are dropped.
+// Values read from both backends are compared and mismatches are logged and counted in metrics.
+func (c *CacheConfig) Shadow(primary, shadow string, queueSize, workers int) *CacheConfig {
+	c.shadow = &shadowConfig{primary: primary, shadow: shadow, queueSize: queueSize, workers: workers}
+	return c
+}
+
+// shadowBackend serves values from the primary backend and repeats every operation on the shadow one asynchronously.
+type shadowBackend struct {
+	Backend
+	shadow Backend
+
+	tasks chan func()
+
+	// pending counts operations queued or running on the shadow backend.
+	pendingMu   sync.Mutex
+	pendingDone *sync.Cond
+	pending     int
+}
+
+func newShadowBackend(primary, shadow Backend, queueSize, workers int) *shadowBackend {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	b := &shadowBackend{Backend: primary, shadow: shadow, tasks: make(chan func(), queueSize)}
+	b.pendingDone = sync.NewCond(&b.pendingMu)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range b.tasks {
+				task()
+				b.addPending(-1)
+			}
+		}()
+	}
+	return b
+}
+
+func (b *shadowBackend) addPending(n int) {
+	b.pendingMu.Lock()
+	b.pending += n
+	if b.pending == 0 {
+		b.pendingDone.Broadcast()
+	}
+	b.pendingMu.Unlock()
+}
+
+// enqueue schedules an operation on the shadow backend, dropping it if the queue is full.
+func (b *shadowBackend) enqueue(op string, task func()) {
+	b.addPending(1)
+	select {
+	case b.tasks <- task:
+	default:
+		b.addPending(-1)
+		b.record(op, ShadowDropped)
+	}
+}
+
+func (b *shadowBackend) record(op, result string) {
+	metrics.ProxyQueryCacheShadowResults.WithLabelValues(b.shadow.Name(), op, result).Inc()
+}
+
+func (b *shadowBackend) Get(key string) (interface{}, bool, error) {
+	v, ok, err := b.Backend.Get(key)
+	if err == nil {
+		b.enqueue("get", func() { b.compare(key, v, ok) })
+	}
+	return v, ok, err
+}
+
+// compare reads the key from the shadow backend and records how its value relates to the one from the primary backend.
+func (b *shadowBackend) compare(key string, pv interface{}, pok bool) {
+	start := time.Now()
+	sv, sok, err := b.shadow.Get(key)
+	b.observeOperation("get", start)
+	l := cacheLogger.WithFields(logrus.Fields{"key": key, "shadow": b.shadow.Name()})
+	switch {
+	case err != nil:
+		b.record("get", ShadowError)
+		l.Error("shadow cache get failed", "err", err)
+	case pok && !sok:
+		b.record("get", ShadowMissing)
+	case !pok && sok:
+		b.record("get", ShadowExtra)
+	case !pok && !sok:
+		b.record("get", ShadowMatch)
+	case equalValues(pv, sv):
+		b.record("get", ShadowMatch)
+	default:
+		b.record("get", ShadowMismatch)
+		l.Warn("shadow cache value mismatch")
+	}
+}
+
+// equalValues compares values by their JSON encoding as backends may keep them in different forms.
+func equalValues(a, b interface{}) bool {
+	ea, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	eb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ea, eb)
+}
+
+func (b *shadowBackend) Set(key string, value interface{}, cost int64, ttl time.Duration) error {
+	err := b.Backend.Set(key, value, cost, ttl)
+	b.enqueue("set", func() {
+		b.setShadow("set", key, func() error { return b.shadow.Set(key, value, cost, ttl) })
+	})
+	return err
+}
+
+func (b *shadowBackend) SetWithPriority(key string, value interface{}, cost int64, ttl time.Duration, priority float64) error {
+	var err error
+	if pb, ok := b.Backend.(PriorityBackend); ok {
+		err = pb.SetWithPriority(key, value, cost, ttl, priority)
+	} else {
+		err = b.Backend.Set(key, value, cost, ttl)
+	}
+	b.enqueue("set", func() {
+		b.setShadow("set", key, func() error {
+			if pb, ok := b.shadow.(PriorityBackend); ok {
+				return pb.SetWithPriority(key, value, cost, ttl, priority)
+			}
+			return b.shadow.Set(key, value, cost, ttl)
+		})
+	})
+	return err
+}
+
+// setShadow runs a write operation op on the shadow backend.
+func (b *shadowBackend) setShadow(op, key string, write func() error) {
+	start := time.Now()
+	err := write()
+	b.observeOperation(op, start)
+	if err != nil {
+		b.record(op, ShadowError)
+		cacheLogger.WithFields(logrus.Fields{"key": key, "shadow": b.shadow.Name()}).Errorf("shadow cache %v failed: %v", op, err)
+		return
+	}
+	b.record(op, ShadowOK)
+}
+
+func (b *shadowBackend) observeOperation(op string, start time.Time) {
+	metrics.ProxyQueryCacheOperationDurations.WithLabelValues(b.shadow.Name(), op).Observe(time.Since(start).Seconds())
+}
+
+func (b *shadowBackend) Delete(key string) error {
+	err := b.Backend.Delete(key)
+	b.enqueue("delete", func() {
+		b.setShadow("delete", key, func() error { return b.shadow.Delete(key) })
+	})
+	return err
+}
+
+func (b *shadowBackend) Clear() {
+	b.Backend.Clear()
+	b.enqueue("clear", func() {
+		b.shadow.Clear()
+		b.record("clear", ShadowOK)
+	})
+}
+
+// Wait blocks until pending writes are applied to both backends.
+func (b *shadowBackend) Wait() {
+	b.Backend.Wait()
+	b.pendingMu.Lock()
+	for b.pending > 0 {
+		b.pendingDone.Wait()
+	}
+	b.pendingMu.Unlock()
+	b.shadow.Wait()
+}
+
+func (b *shadowBackend) TTL(key string) (time.Duration, bool) {
+	if tb, ok := b.Backend.(TTLBackend); ok {
+		return tb.TTL(key)
+	}
+	return 0, false
+}
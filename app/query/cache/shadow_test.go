@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapBackend is a shadow backend which can be made to fail or block.
+type mapBackend struct {
+	mu      sync.Mutex
+	values  map[string]interface{}
+	failing bool
+	block   chan struct{}
+}
+
+func (b *mapBackend) Name() string { return "map" }
+func (b *mapBackend) Get(key string) (interface{}, bool, error) {
+	if b.block != nil {
+		<-b.block
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failing {
+		return nil, false, errors.Err("connection lost")
+	}
+	v, ok := b.values[key]
+	return v, ok, nil
+}
+func (b *mapBackend) Set(key string, value interface{}, _ int64, _ time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values[key] = value
+	return nil
+}
+func (b *mapBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.values, key)
+	return nil
+}
+func (b *mapBackend) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values = map[string]interface{}{}
+}
+func (b *mapBackend) Wait() {}
+
+func (b *mapBackend) update(f func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f()
+}
+
+func shadowResult(op, result string) float64 {
+	return metrics.GetCounterValue(metrics.ProxyQueryCacheShadowResults.WithLabelValues("map", op, result))
+}
+
+func TestCacheShadow(t *testing.T) {
+	cacheLogger.Disable()
+	shadow := &mapBackend{values: map[string]interface{}{}}
+	RegisterBackend("map", func(*CacheConfig) (Backend, error) { return shadow, nil })
+	c, err := New(DefaultConfig().Shadow("", "map", 10, 2))
+	require.NoError(t, err)
+	assert.Equal(t, "memory", c.backend.Name())
+
+	params := map[string]interface{}{"urls": "shadow"}
+	retrievals := 0
+	retriever := func() (interface{}, error) {
+		retrievals++
+		return map[string]interface{}{"claim_id": "abc"}, nil
+	}
+
+	sets := shadowResult("set", ShadowOK)
+	_, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	assert.Equal(t, sets+1, shadowResult("set", ShadowOK))
+	key, err := c.hash("resolve", params)
+	require.NoError(t, err)
+	assert.Contains(t, shadow.values, key)
+
+	matches := shadowResult("get", ShadowMatch)
+	_, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	assert.Equal(t, 1, retrievals)
+	assert.Equal(t, matches+1, shadowResult("get", ShadowMatch))
+
+	// Mismatching values are only counted, responses come from the primary backend
+	shadow.update(func() { shadow.values[key] = map[string]interface{}{"claim_id": "def"} })
+	mismatches := shadowResult("get", ShadowMismatch)
+	res, err := c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	assert.Equal(t, map[string]interface{}{"claim_id": "abc"}, res)
+	assert.Equal(t, mismatches+1, shadowResult("get", ShadowMismatch))
+
+	shadow.update(func() { delete(shadow.values, key) })
+	missing := shadowResult("get", ShadowMissing)
+	_, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	assert.Equal(t, missing+1, shadowResult("get", ShadowMissing))
+
+	shadow.update(func() { shadow.failing = true })
+	errs := shadowResult("get", ShadowError)
+	res, err = c.Retrieve("resolve", params, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	assert.Equal(t, map[string]interface{}{"claim_id": "abc"}, res)
+	assert.Equal(t, errs+1, shadowResult("get", ShadowError))
+	assert.Equal(t, 1, retrievals)
+}
+
+func TestCacheShadowNeverBlocks(t *testing.T) {
+	cacheLogger.Disable()
+	shadow := &mapBackend{values: map[string]interface{}{}, block: make(chan struct{})}
+	RegisterBackend("map", func(*CacheConfig) (Backend, error) { return shadow, nil })
+	c, err := New(DefaultConfig().Shadow("priority", "map", 2, 1))
+	require.NoError(t, err)
+
+	dropped := shadowResult("get", ShadowDropped)
+	start := time.Now()
+	_, ok := c.Get("resolve", map[string]interface{}{"urls": "blocked"})
+	assert.False(t, ok)
+	// Wait for the worker to get stuck on the first one
+	require.Eventually(t, func() bool { return len(c.backend.(*shadowBackend).tasks) == 0 }, time.Second, time.Millisecond)
+	for i := 0; i < 4; i++ {
+		_, ok := c.Get("resolve", map[string]interface{}{"urls": "blocked"})
+		assert.False(t, ok)
+	}
+	assert.Less(t, time.Since(start).Milliseconds(), int64(500))
+	// Two are queued behind the stuck one
+	assert.Equal(t, dropped+2, shadowResult("get", ShadowDropped))
+	close(shadow.block)
+	c.Wait()
+}
+
+func TestCacheShadowUnknownBackend(t *testing.T) {
+	_, err := New(DefaultConfig().Shadow("", "nonexistent", 10, 1))
+	assert.EqualError(t, err, "unknown cache backend: nonexistent")
+	_, err = New(DefaultConfig().Shadow("nonexistent", "memory", 10, 1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+const snapshotVersion = 1
+
+// ErrSnapshotsUnsupported is returned when the cache backend cannot be snapshotted or snapshots are not enabled.
+var ErrSnapshotsUnsupported = errors.New("cache backend does not support snapshots")
+
+type snapshotHeader struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+type snapshotEntry struct {
+	Key string `json:"key"`
+	// RPC is true for JSON-RPC responses, other values are restored as plain JSON.
+	RPC bool `json:"rpc,omitempty"`
+	// Expires is zero for entries without a TTL.
+	Expires time.Time       `json:"expires"`
+	Cost    int64           `json:"cost"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// SaveSnapshot writes all live cache entries to the file at path, replacing it atomically,
+// and returns the number of entries saved. Snapshots must be enabled in the cache config.
+func (c *Cache) SaveSnapshot(path string) (int, error) {
+	backend := c.backend
+	if sb, ok := backend.(*shadowBackend); ok {
+		backend = sb.Backend
+	}
+	b, ok := backend.(*memoryBackend)
+	if !ok || b.index == nil {
+		return 0, ErrSnapshotsUnsupported
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := b.writeSnapshot(tmp, time.Now())
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// LoadSnapshot fills the cache with entries from the snapshot at path, skipping the ones which have expired
+// since it was saved, and returns the number of entries loaded. A missing snapshot is not an error.
+func (c *Cache) LoadSnapshot(path string) (int, error) {
+	return c.loadSnapshot(path, time.Now())
+}
+
+// RunSnapshots saves the cache to path every interval until stop is closed.
+func (c *Cache) RunSnapshots(path string, interval time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			start := time.Now()
+			n, err := c.SaveSnapshot(path)
+			if err != nil {
+				cacheLogger.Log().Errorf("cannot save cache snapshot to %v: %v", path, err)
+				continue
+			}
+			cacheLogger.Log().Infof("saved %v cache entries to %v in %v", n, path, time.Since(start))
+		}
+	}
+}
+
+func (c *Cache) loadSnapshot(path string, now time.Time) (int, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var h snapshotHeader
+	if err := dec.Decode(&h); err != nil {
+		return 0, err
+	}
+	if h.Version != snapshotVersion {
+		return 0, errors.New("unsupported cache snapshot version")
+	}
+
+	n := 0
+	for {
+		var e snapshotEntry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return n, err
+		}
+		var ttl time.Duration
+		if !e.Expires.IsZero() {
+			ttl = e.Expires.Sub(now)
+			if ttl <= 0 {
+				continue
+			}
+		}
+		value, err := decodeSnapshotValue(e)
+		if err != nil {
+			return n, err
+		}
+		if err := c.backend.Set(e.Key, value, e.Cost, ttl); err != nil {
+			return n, err
+		}
+		n++
+	}
+	c.backend.Wait()
+	return n, nil
+}
+
+func decodeSnapshotValue(e snapshotEntry) (interface{}, error) {
+	if e.RPC {
+		r := &jsonrpc.RPCResponse{}
+		if err := responses.UnmarshalJSON(e.Value, r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	var v interface{}
+	if err := responses.UnmarshalJSON(e.Value, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// writeSnapshot writes the header and live entries, one JSON document per line.
+// Keys which are no longer in the cache are dropped from the index.
+func (b *memoryBackend) writeSnapshot(w io.Writer, now time.Time) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, SavedAt: now}); err != nil {
+		return 0, err
+	}
+
+	b.indexMu.Lock()
+	keys := make(map[string]indexEntry, len(b.index))
+	for k, e := range b.index {
+		keys[k] = e
+	}
+	b.indexMu.Unlock()
+
+	n := 0
+	for k, ie := range keys {
+		v, ok := b.Cache.Get(k)
+		if !ok || (!ie.expires.IsZero() && !now.Before(ie.expires)) {
+			b.indexMu.Lock()
+			if cur, ok := b.index[k]; ok && cur == ie {
+				delete(b.index, k)
+			}
+			b.indexMu.Unlock()
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			cacheLogger.Log().Warnf("cannot save cache entry %v to snapshot: %v", k, err)
+			continue
+		}
+		_, isRPC := v.(*jsonrpc.RPCResponse)
+		if err := enc.Encode(snapshotEntry{Key: k, RPC: isRPC, Expires: ie.expires, Cost: ie.cost, Value: raw}); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, bw.Flush()
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	cacheLogger.Disable()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	c, err := New(DefaultConfig().Snapshots())
+	require.NoError(t, err)
+	resolveParams := map[string]interface{}{"urls": []interface{}{"lbry://one"}}
+	require.NoError(t, c.Set("resolve", resolveParams, &jsonrpc.RPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{"lbry://one": map[string]interface{}{"name": "one", "height": 1000}},
+	}))
+	require.NoError(t, c.Set("status", nil, map[string]interface{}{"is_running": true}))
+	c.Wait()
+
+	n, err := c.SaveSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, files, 1, "temporary snapshot files should not be left behind")
+
+	restored, err := New(DefaultConfig().Snapshots())
+	require.NoError(t, err)
+	n, err = restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	v, ok := restored.Get("resolve", resolveParams)
+	require.True(t, ok)
+	res, ok := v.(*jsonrpc.RPCResponse)
+	require.True(t, ok)
+	stream := res.Result.(map[string]interface{})["lbry://one"].(map[string]interface{})
+	assert.Equal(t, "one", stream["name"])
+	assert.EqualValues(t, "1000", stream["height"])
+
+	v, ok = restored.Get("status", nil)
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"is_running": true}, v)
+
+	// Restored entries are saved again by the next snapshot
+	n, err = restored.SaveSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestSnapshot_TTLExpiry(t *testing.T) {
+	cacheLogger.Disable()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	c, err := New(DefaultConfig().Snapshots().MethodTTL("resolve", time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, c.Set("resolve", "short", &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: "short"}))
+	require.NoError(t, c.Set("status", "default", "default"))
+	c.Wait()
+
+	n, err := c.SaveSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	restored, err := New(DefaultConfig().Snapshots())
+	require.NoError(t, err)
+	n, err = restored.loadSnapshot(path, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, ok := restored.Get("resolve", "short")
+	assert.False(t, ok)
+	v, ok := restored.Get("status", "default")
+	require.True(t, ok)
+	assert.Equal(t, "default", v)
+
+	// Remaining TTL carries over to the restored cache
+	restored, err = New(DefaultConfig().Snapshots())
+	require.NoError(t, err)
+	n, err = restored.loadSnapshot(path, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	ttl, ok := restored.backend.(TTLBackend).TTL(restored.mustHash("resolve", "short"))
+	require.True(t, ok)
+	assert.InDelta(t, 30*time.Second, ttl, float64(2*time.Second))
+}
+
+func TestSnapshot_Missing(t *testing.T) {
+	c, err := New(DefaultConfig().Snapshots())
+	require.NoError(t, err)
+	n, err := c.LoadSnapshot(filepath.Join(t.TempDir(), "none"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestSnapshot_Unsupported(t *testing.T) {
+	c, err := New(DefaultConfig())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	_, err = c.SaveSnapshot(path)
+	assert.Equal(t, ErrSnapshotsUnsupported, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func (c *Cache) mustHash(method string, params interface{}) string {
+	k, err := c.hash(method, params)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// DefaultTTL is how long responses are cached for when no TTL is set for the method.
+const DefaultTTL = 3 * time.Minute
+
+// claimErrorNotFound is the name of the error the SDK returns in place of claims which don't exist.
+const claimErrorNotFound = "NOT_FOUND"
+
+// claimAgeTTLRatio is the share of time since the last claim update which the claim is cached for.
+const claimAgeTTLRatio = 0.01
+
+// TTLFunc derives cache TTL for a response. Returning zero or a negative value makes the cache fall back to DefaultTTL.
+type TTLFunc func(res interface{}) time.Duration
+
+// MethodTTL sets a fixed TTL for responses of the method.
+func (c *CacheConfig) MethodTTL(method string, ttl time.Duration) *CacheConfig {
+	if c.ttls == nil {
+		c.ttls = map[string]time.Duration{}
+	}
+	c.ttls[method] = ttl
+	return c
+}
+
+// MethodTTLs sets a source of fixed TTLs which is consulted for every response, so they can be changed
+// at runtime together with the list of cacheable methods. TTL set with MethodTTL takes precedence.
+func (c *CacheConfig) MethodTTLs(source func() map[string]time.Duration) *CacheConfig {
+	c.ttlSource = source
+	return c
+}
+
+// AdaptiveTTL sets a function which will determine TTL for each response of the method.
+// It takes precedence over TTL set with MethodTTL, which is used when the function returns zero.
+func (c *CacheConfig) AdaptiveTTL(method string, f TTLFunc) *CacheConfig {
+	if c.ttlFuncs == nil {
+		c.ttlFuncs = map[string]TTLFunc{}
+	}
+	c.ttlFuncs[method] = f
+	return c
+}
+
+// NotFoundTTL sets a TTL for responses of the method which consist solely of NOT_FOUND claim errors.
+// It takes precedence over other TTLs so nonexistent claims are looked up again soon. Zero TTL is ignored.
+func (c *CacheConfig) NotFoundTTL(method string, ttl time.Duration) *CacheConfig {
+	if c.notFoundTTLs == nil {
+		c.notFoundTTLs = map[string]time.Duration{}
+	}
+	c.notFoundTTLs[method] = ttl
+	return c
+}
+
+func (c *CacheConfig) getTTL(method string, res interface{}) time.Duration {
+	if ttl := c.notFoundTTLs[method]; ttl > 0 && isNotFound(res) {
+		return ttl
+	}
+	if f, ok := c.ttlFuncs[method]; ok {
+		if ttl := f(res); ttl > 0 {
+			return ttl
+		}
+	}
+	if ttl := c.ttls[method]; ttl > 0 {
+		return ttl
+	}
+	if c.ttlSource != nil {
+		if ttl := c.ttlSource()[method]; ttl > 0 {
+			return ttl
+		}
+	}
+	return DefaultTTL
+}
+
+// ClaimAgeTTL caches resolve and claim_search responses longer when the claims in them haven't been updated for a while.
+// TTL is proportional to the time since the most recent update among claims in the response and is kept between min and max.
+// Responses with claims that lack an update timestamp are cached for min,
+// responses without any claims fall back to the method TTL.
+func ClaimAgeTTL(min, max time.Duration) TTLFunc {
+	return func(res interface{}) time.Duration {
+		claims := responseClaims(res)
+		if len(claims) == 0 {
+			return 0
+		}
+		ttl := max
+		for _, c := range claims {
+			ts, ok := claimTimestamp(c)
+			if !ok {
+				return min
+			}
+			t := time.Duration(float64(time.Since(time.Unix(ts, 0))) * claimAgeTTLRatio)
+			if t < ttl {
+				ttl = t
+			}
+		}
+		if ttl < min {
+			return min
+		}
+		return ttl
+	}
+}
+
+// responseClaims extracts claims from resolve (map of urls to claims) and claim_search (list of items) results.
+func responseClaims(res interface{}) []interface{} {
+	var result interface{}
+	switch r := res.(type) {
+	case *jsonrpc.RPCResponse:
+		if r == nil || r.Error != nil {
+			return nil
+		}
+		result = r.Result
+	case jsonrpc.RPCResponse:
+		if r.Error != nil {
+			return nil
+		}
+		result = r.Result
+	default:
+		return nil
+	}
+
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if items, ok := m["items"].([]interface{}); ok {
+		return items
+	}
+	claims := make([]interface{}, 0, len(m))
+	for _, c := range m {
+		claims = append(claims, c)
+	}
+	return claims
+}
+
+// isNotFound is true for resolve results where every claim is a NOT_FOUND error.
+// Other errors might be transient so they don't count.
+func isNotFound(res interface{}) bool {
+	claims := responseClaims(res)
+	if len(claims) == 0 {
+		return false
+	}
+	for _, c := range claims {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		e, ok := m["error"].(map[string]interface{})
+		if !ok || e["name"] != claimErrorNotFound {
+			return false
+		}
+	}
+	return true
+}
+
+func claimTimestamp(claim interface{}) (int64, bool) {
+	c, ok := claim.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch ts := c["timestamp"].(type) {
+	case json.Number:
+		v, err := ts.Int64()
+		return v, err == nil && v > 0
+	case float64:
+		return int64(ts), ts > 0
+	case int64:
+		return ts, ts > 0
+	case int:
+		return int64(ts), ts > 0
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func claimWithAge(age time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"timestamp": json.Number(fmt.Sprintf("%d", time.Now().Add(-age).Unix())),
+	}
+}
+
+func TestClaimAgeTTL(t *testing.T) {
+	f := ClaimAgeTTL(time.Minute, 30*time.Minute)
+
+	cases := []struct {
+		name     string
+		result   interface{}
+		expected time.Duration
+	}{
+		{"fresh claim", map[string]interface{}{"lbry://one": claimWithAge(time.Hour)}, time.Minute},
+		{"day old claim", map[string]interface{}{"lbry://one": claimWithAge(24 * time.Hour)}, 864 * time.Second},
+		{"stale claim", map[string]interface{}{"lbry://one": claimWithAge(365 * 24 * time.Hour)}, 30 * time.Minute},
+		{"most recent claim wins", map[string]interface{}{
+			"lbry://one": claimWithAge(365 * 24 * time.Hour),
+			"lbry://two": claimWithAge(24 * time.Hour),
+		}, 864 * time.Second},
+		{"claim search", map[string]interface{}{"items": []interface{}{
+			claimWithAge(365 * 24 * time.Hour), claimWithAge(48 * time.Hour),
+		}}, 1728 * time.Second},
+		{"claim without timestamp", map[string]interface{}{
+			"lbry://one": claimWithAge(365 * 24 * time.Hour),
+			"lbry://two": map[string]interface{}{"error": map[string]interface{}{"name": "NOT_FOUND"}},
+		}, time.Minute},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ttl := f(&jsonrpc.RPCResponse{Result: c.result})
+			assert.InDelta(t, c.expected.Seconds(), ttl.Seconds(), 1)
+		})
+	}
+
+	assert.Zero(t, f(&jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "error"}}))
+	assert.Zero(t, f(&jsonrpc.RPCResponse{Result: "ok"}))
+	assert.Zero(t, f(&jsonrpc.RPCResponse{Result: map[string]interface{}{"items": []interface{}{}, "total_items": 0}}))
+	assert.Zero(t, f(&jsonrpc.RPCResponse{Result: map[string]interface{}{}}))
+	assert.Zero(t, f(nil))
+}
+
+func TestCacheAdaptiveTTL(t *testing.T) {
+	cfg := DefaultConfig().AdaptiveTTL("resolve", func(interface{}) time.Duration { return 50 * time.Millisecond })
+	assert.Equal(t, DefaultTTL, cfg.getTTL("claim_search", nil))
+	assert.Equal(t, DefaultTTL, DefaultConfig().AdaptiveTTL("resolve", func(interface{}) time.Duration { return 0 }).getTTL("resolve", nil))
+	assert.Equal(t, time.Minute, DefaultConfig().MethodTTL("resolve", time.Minute).getTTL("resolve", nil))
+	assert.Equal(t, time.Minute, DefaultConfig().
+		MethodTTL("resolve", time.Minute).
+		AdaptiveTTL("resolve", func(interface{}) time.Duration { return 0 }).
+		getTTL("resolve", nil))
+
+	ttls := map[string]time.Duration{"resolve": time.Minute}
+	live := DefaultConfig().MethodTTLs(func() map[string]time.Duration { return ttls })
+	assert.Equal(t, time.Minute, live.getTTL("resolve", nil))
+	ttls = map[string]time.Duration{"resolve": time.Hour}
+	assert.Equal(t, time.Hour, live.getTTL("resolve", nil))
+	assert.Equal(t, DefaultTTL, live.getTTL("claim_search", nil))
+	assert.Equal(t, time.Second, live.MethodTTL("resolve", time.Second).getTTL("resolve", nil))
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+
+	retrievals := 0
+	retriever := func() (interface{}, error) {
+		retrievals++
+		return &jsonrpc.RPCResponse{Result: "ok"}, nil
+	}
+	_, err = c.Retrieve("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	c.Wait()
+	_, err = c.Retrieve("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 1, retrievals)
+
+	time.Sleep(1100 * time.Millisecond)
+	_, err = c.Retrieve("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 2, retrievals)
+}
+
+func TestCacheNotFoundTTL(t *testing.T) {
+	notFound := map[string]interface{}{"error": map[string]interface{}{"name": "NOT_FOUND", "text": "not found"}}
+	cfg := DefaultConfig().
+		MethodTTL("resolve", time.Hour).
+		AdaptiveTTL("resolve", ClaimAgeTTL(time.Minute, 30*time.Minute)).
+		NotFoundTTL("resolve", time.Second)
+
+	assert.Equal(t, time.Second, cfg.getTTL("resolve", &jsonrpc.RPCResponse{Result: map[string]interface{}{
+		"lbry://one": notFound, "lbry://two": notFound,
+	}}))
+	assert.Equal(t, time.Minute, cfg.getTTL("resolve", &jsonrpc.RPCResponse{Result: map[string]interface{}{
+		"lbry://one": notFound, "lbry://two": claimWithAge(time.Hour),
+	}}))
+	assert.Equal(t, time.Minute, cfg.getTTL("resolve", &jsonrpc.RPCResponse{Result: map[string]interface{}{
+		"lbry://one": map[string]interface{}{"error": map[string]interface{}{"name": "RESOLVE_ERROR"}},
+	}}))
+	assert.Equal(t, time.Hour, cfg.getTTL("resolve", &jsonrpc.RPCResponse{Result: map[string]interface{}{}}))
+	assert.Equal(t, DefaultTTL, cfg.getTTL("claim_search", &jsonrpc.RPCResponse{Result: map[string]interface{}{
+		"lbry://one": notFound,
+	}}))
+	assert.Equal(t, time.Hour, DefaultConfig().MethodTTL("resolve", time.Hour).NotFoundTTL("resolve", 0).
+		getTTL("resolve", &jsonrpc.RPCResponse{Result: map[string]interface{}{"lbry://one": notFound}}))
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	retrievals := 0
+	retriever := func() (interface{}, error) {
+		retrievals++
+		return &jsonrpc.RPCResponse{Result: map[string]interface{}{"lbry://one": notFound}}, nil
+	}
+	before := metrics.GetCounterValue(metrics.ProxyQueryCacheNotFoundHitCount.WithLabelValues("resolve"))
+	_, info, err := c.RetrieveWithInfo("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, info.TTL)
+	c.Wait()
+	_, info, err = c.RetrieveWithInfo("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, StatusHit, info.Status)
+	assert.Equal(t, 1, retrievals)
+	assert.Equal(t, 1.0, metrics.GetCounterValue(metrics.ProxyQueryCacheNotFoundHitCount.WithLabelValues("resolve"))-before)
+
+	time.Sleep(1100 * time.Millisecond)
+	_, err = c.Retrieve("resolve", map[string]interface{}{"urls": "one"}, retriever)
+	require.NoError(t, err)
+	assert.Equal(t, 2, retrievals)
+}
@@ -0,0 +1,65 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CacheDirective lets trusted callers control freshness of cached responses, e.g. to have content updates
+// show up right away. It's parsed from a comma-separated list like "ttl=30s, invalidate=<key>, invalidate=<key>".
+type CacheDirective struct {
+	// MaxTTL makes a cacheable query skip the cache lookup and have its fresh response cached for MaxTTL at most.
+	// It can only shorten TTL configured for the method.
+	MaxTTL time.Duration
+	// Invalidate lists cache keys (as sent to admins in X-Cache-Key) to remove before the query is performed.
+	Invalidate []string
+}
+
+// ParseCacheDirective parses a cache directive, see CacheDirective.
+func ParseCacheDirective(s string) (*CacheDirective, error) {
+	d := &CacheDirective{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			return nil, errors.Err("invalid cache directive: %v", part)
+		}
+		v := strings.TrimSpace(kv[1])
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "ttl":
+			ttl, err := time.ParseDuration(v)
+			if err != nil || ttl <= 0 {
+				return nil, errors.Err("invalid cache directive ttl: %v", v)
+			}
+			d.MaxTTL = ttl
+		case "invalidate":
+			d.Invalidate = append(d.Invalidate, v)
+		default:
+			return nil, errors.Err("unknown cache directive: %v", kv[0])
+		}
+	}
+	return d, nil
+}
+
+// invalidateCached removes responses listed in the cache directive from the cache.
+func (c *Caller) invalidateCached(q *Query) {
+	if c.CacheDirective == nil || len(c.CacheDirective.Invalidate) == 0 || c.Cache == nil {
+		return
+	}
+	keys := c.CacheDirective.Invalidate
+	log := logger.WithFields(logrus.Fields{"method": q.Method(), "keys": keys})
+	if err := c.Cache.Invalidate(keys...); err != nil {
+		log.Errorf("cache invalidation failed: %v", err)
+		return
+	}
+	metrics.ProxyQueryCacheDirectives.WithLabelValues("invalidate").Add(float64(len(keys)))
+	log.Info("cache entries invalidated on request")
+}
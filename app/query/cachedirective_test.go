@@ -0,0 +1,24 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCacheDirective(t *testing.T) {
+	d, err := ParseCacheDirective("ttl=30s, invalidate=resolve|abc ,Invalidate=claim_search|def,")
+	require.NoError(t, err)
+	assert.Equal(t, &CacheDirective{MaxTTL: 30 * time.Second, Invalidate: []string{"resolve|abc", "claim_search|def"}}, d)
+
+	d, err = ParseCacheDirective("")
+	require.NoError(t, err)
+	assert.Equal(t, &CacheDirective{}, d)
+
+	for _, s := range []string{"ttl=0s", "ttl=-1m", "ttl=soon", "ttl", "invalidate=", "no-cache", "max-age=10"} {
+		_, err := ParseCacheDirective(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -1,7 +1,9 @@
 package query
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"strings"
@@ -12,20 +14,23 @@ import (
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/clientinfo"
 	"github.com/lbryio/lbrytv/internal/errors"
 	"github.com/lbryio/lbrytv/internal/lbrynet"
 	"github.com/lbryio/lbrytv/internal/metrics"
 	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/internal/scheduler"
+	"github.com/lbryio/lbrytv/models"
 
 	"github.com/sirupsen/logrus"
 	"github.com/ybbus/jsonrpc"
 )
 
 const (
-	walletLoadRetries   = 3
-	walletLoadRetryWait = 100 * time.Millisecond
-	builtinHookName     = "builtin"
-	defaultRPCTimeout   = 240 * time.Second
+	walletLoadRetries  = 3
+	builtinHookName    = "builtin"
+	defaultRPCTimeout  = 240 * time.Second
+	maxRestartReroutes = 2
 
 	// AllMethodsHook is used as the first argument to Add*Hook to make it apply to all methods
 	AllMethodsHook = ""
@@ -46,11 +51,31 @@ type hookEntry struct {
 	name     string
 }
 
+// RequestInfo is request-scoped data about the client a query is performed for.
+// It's filled in by the proxy handler so hooks don't have to derive it from the raw request.
+type RequestInfo struct {
+	// User is the authenticated user, nil for anonymous requests.
+	User *models.User
+	// RemoteIP is the client address.
+	RemoteIP string
+	// Origin is the device the request has been sent from, as reported in metrics.
+	Origin string
+	// RequestID identifies the request in logs, it's taken from the X-Request-Id header or generated.
+	RequestID string
+}
+
 // HookContext contains data about the query being performed.
 // When supplied in the postflight stage, it will contain Response and LogEntry, otherwise those will be nil.
+// Fields of RequestInfo are empty for queries made outside of client requests.
 type HookContext struct {
 	Query    *Query
 	Response *jsonrpc.RPCResponse
+	// Client identifies the app which has sent the query, hooks can use it to gate features by client version.
+	Client clientinfo.Info
+	// SDKVersion is the lbrynet version of the SDK server which has responded, empty if it's not known.
+	// It's only set in the postflight stage.
+	SDKVersion string
+	RequestInfo
 	logEntry *logrus.Entry
 }
 
@@ -61,6 +86,16 @@ func (hc *HookContext) AddLogField(key string, value interface{}) {
 	}
 }
 
+// Set stores a value for hooks called later for the same query, e.g. a preflight hook can pass data to a postflight one.
+func (hc *HookContext) Set(key string, value interface{}) {
+	hc.Query.setValue(key, value)
+}
+
+// Value returns a value stored with Set for the query, nil if there's none.
+func (hc *HookContext) Value(key string) interface{} {
+	return hc.Query.values[key]
+}
+
 // Caller patches through JSON-RPC requests from clients, doing pre/post-processing,
 // account processing and validation.
 type Caller struct {
@@ -71,40 +106,105 @@ type Caller struct {
 
 	// Cache stores cacheable queries to improve performance
 	Cache *cache.Cache
+	// BypassCache makes cacheable queries skip the cache lookup, their fresh responses are still cached.
+	BypassCache bool
+	// CacheInfo describes how the response of the last call has been served by the cache, it's nil if the cache wasn't used.
+	CacheInfo *cache.Info
+	// CacheDirective, which only trusted callers may set, shortens TTL of fresh responses or invalidates cache entries.
+	CacheDirective *CacheDirective
+
+	// Client is the app which has originated the query, it's passed on to hooks.
+	Client clientinfo.Info
+	// Request describes the client request the query is performed for, it's passed on to hooks.
+	Request RequestInfo
+
+	// Router, when set, gets restarting SDK servers quarantined and provides healthy servers to reroute safe reads to.
+	// It also routes methods which have dedicated SDK pools.
+	Router *sdkrouter.Router
+
+	degraded map[string]degradedEntry
+
+	// Headers are sent to the SDK along with queries, see ForwardedHeaders.
+	Headers map[string]string
+
+	// Scheduler, when set, limits how many queries are sent to the SDK at once, letting higher priority methods go first.
+	Scheduler *scheduler.Scheduler
+	// QueueTimeout limits how long a query waits for a Scheduler slot, zero means no limit.
+	QueueTimeout time.Duration
+
+	// Context, when set, aborts SDK requests in flight once it's done.
+	Context context.Context
+
+	// PayloadSize is the size in bytes of data sent along with queries, like an uploaded file,
+	// which timeouts of methods listed in SizedRPCTimeouts grow with.
+	PayloadSize int64
 
 	Duration float64
 
 	userID   int
 	endpoint string
+	// servedBy is the SDK server the last query has been sent to
+	servedBy string
 }
 
 func NewCaller(endpoint string, userID int) *Caller {
 	caller := &Caller{
 		endpoint: endpoint,
 		userID:   userID,
+		Client:   clientinfo.UnknownClient,
 	}
 	caller.addDefaultHooks()
 	return caller
 }
 
-func (c *Caller) newRPCClient(timeout time.Duration) jsonrpc.RPCClient {
+// newRPCClient creates a client for calling the SDK. Responses it gets are recorded by sniffer if it's not nil.
+func (c *Caller) newRPCClient(timeout time.Duration, sniffer *responseSniffer) jsonrpc.RPCClient {
+	headers := map[string]string{}
+	if ua := config.GetSDKUserAgent(); ua != "" {
+		headers["User-Agent"] = ua
+	}
+	for h, v := range c.Headers {
+		headers[h] = v
+	}
+	var transport http.RoundTripper = &http.Transport{
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 120 * time.Second,
+		}).Dial,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if c.Context != nil {
+		transport = contextTransport{ctx: c.Context, next: transport}
+	}
+	if sniffer != nil {
+		sniffer.next = transport
+		transport = sniffer
+	}
 	client := jsonrpc.NewClientWithOpts(c.endpoint, &jsonrpc.RPCClientOpts{
+		CustomHeaders: headers,
 		HTTPClient: &http.Client{
-			Timeout: sdkrouter.RPCTimeout + timeout,
-			Transport: &http.Transport{
-				Dial: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 120 * time.Second,
-				}).Dial,
-				ResponseHeaderTimeout: timeout,
-				ExpectContinueTimeout: 1 * time.Second,
-			},
+			Timeout:   sdkrouter.RPCTimeout + timeout,
+			Transport: transport,
 		},
 	})
 	return client
 }
 
+// contextTransport binds outgoing requests to a context since the JSON-RPC client doesn't take one.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(r.WithContext(t.ctx))
+}
+
 func (c *Caller) getRPCTimeout(method string) time.Duration {
+	if s, ok := config.GetSizedRPCTimeouts()[method]; ok {
+		return sizedTimeout(s, c.PayloadSize)
+	}
 	t := config.GetRPCTimeout(method)
 	if t != nil {
 		return *t
@@ -112,8 +212,27 @@ func (c *Caller) getRPCTimeout(method string) time.Duration {
 	return defaultRPCTimeout
 }
 
-func (c *Caller) getRPCClient(method string) jsonrpc.RPCClient {
-	var client jsonrpc.RPCClient = c.newRPCClient(c.getRPCTimeout(method))
+// sizedTimeout returns the timeout for a call carrying size bytes of data.
+func sizedTimeout(s config.SizedRPCTimeout, size int64) time.Duration {
+	t := float64(s.Base)
+	if size > 0 {
+		t += float64(s.PerMB) * float64(size) / (1 << 20)
+	}
+	if s.Max > 0 && t > float64(s.Max) {
+		return s.Max
+	}
+	if t > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(t)
+}
+
+func (c *Caller) getRPCClient(method string, sniffer *responseSniffer) jsonrpc.RPCClient {
+	timeout := c.getRPCTimeout(method)
+	if d, ok := c.degraded[method]; ok && d.timeout > 0 && d.timeout < timeout {
+		timeout = d.timeout
+	}
+	var client jsonrpc.RPCClient = c.newRPCClient(timeout, sniffer)
 	return client
 }
 
@@ -126,6 +245,13 @@ func (c *Caller) AddPreflightHook(method string, hf Hook, name string) {
 	logger.Log().Debugf("added a preflight hook for method %v", method)
 }
 
+// PrependPreflightHook is like AddPreflightHook but makes the hook run before the ones added earlier,
+// including builtin hooks which might return early responses.
+func (c *Caller) PrependPreflightHook(method string, hf Hook, name string) {
+	c.preflightHooks = append([]hookEntry{{method, hf, name}}, c.preflightHooks...)
+	logger.Log().Debugf("prepended a preflight hook for method %v", method)
+}
+
 // AddPostflightHook adds query postflight hook function,
 // allowing to amend the response before it gets sent back to the client
 // or to modify log entry fields.
@@ -135,14 +261,41 @@ func (c *Caller) AddPostflightHook(method string, hf Hook, name string) {
 }
 
 func (c *Caller) addDefaultHooks() {
+	c.AddPreflightHook(AllMethodsHook, preflightHookTransformRequest, builtinHookName)
+	c.AddPreflightHook(AllMethodsHook, preflightHookSanitizeParams, builtinHookName)
+	c.AddPreflightHook(AllMethodsHook, preflightHookParamDefaults, builtinHookName)
+	c.AddPreflightHook(AllMethodsHook, preflightHookForcedParams, builtinHookName)
+	c.AddPreflightHook(AllMethodsHook, preflightHookMaxPageSize, builtinHookName)
 	c.AddPreflightHook("status", getStatusResponse, builtinHookName)
+	c.AddPreflightHook(MethodResolve, preflightHookNormalizeURIs, builtinHookName)
+	c.AddPreflightHook(MethodGet, preflightHookNormalizeURIs, builtinHookName)
 	c.AddPreflightHook("get", preflightHookGet, builtinHookName)
+	c.AddPreflightHook(MethodResolveClaimIDs, preflightHookResolveClaimIDs, builtinHookName)
+	for _, m := range projectedMethods {
+		c.AddPreflightHook(m, preflightHookProjection, builtinHookName)
+		c.AddPostflightHook(m, postflightHookProjection, builtinHookName)
+	}
+	c.AddPostflightHook(MethodClaimSearch, postflightHookEnrichClaimSearch, builtinHookName)
+	c.AddPostflightHook(AllMethodsHook, postflightHookAdaptResponse, builtinHookName)
+	c.AddPostflightHook(AllMethodsHook, postflightHookWarnings, builtinHookName)
 }
 
 func (c *Caller) CloneWithoutHook(endpoint, method, name string) *Caller {
 	cc := NewCaller(endpoint, c.userID)
+	cc.Client = c.Client
+	cc.Request = c.Request
+	cc.Router = c.Router
+	cc.Scheduler = c.Scheduler
+	cc.QueueTimeout = c.QueueTimeout
+	cc.Headers = c.Headers
+	cc.Context = c.Context
+	cc.PayloadSize = c.PayloadSize
+	for m, d := range c.degraded {
+		cc.SetDegradedHandler(m, d.timeout, d.handler)
+	}
 	for _, h := range c.postflightHooks {
-		if h.method == method && h.name == name {
+		// Builtin postflight hooks have already been added by NewCaller
+		if (h.method == method && h.name == name) || h.name == builtinHookName {
 			continue
 		}
 		cc.AddPostflightHook(h.method, h.function, h.name)
@@ -156,10 +309,25 @@ func (c *Caller) CloneWithoutHook(endpoint, method, name string) *Caller {
 	return cc
 }
 
+// SDKVersion returns the lbrynet version of the SDK server the last call has been sent to, empty if it's not known.
+// Versions are reported by Router.
+func (c *Caller) SDKVersion() string {
+	if c.Router == nil || c.servedBy == "" {
+		return ""
+	}
+	return c.Router.SDKVersion(c.servedBy)
+}
+
 func (c *Caller) Endpoint() string {
 	return c.endpoint
 }
 
+// ServedBy returns the address of the SDK server the last call has been sent to, whether it has succeeded or not.
+// It's empty if the call has been answered without contacting the SDK, e.g. from the cache.
+func (c *Caller) ServedBy() string {
+	return c.servedBy
+}
+
 // Call method forwards a JSON-RPC request to the lbrynet server.
 // It returns a response that is ready to be sent back to the JSON-RPC client as is.
 func (c *Caller) Call(req *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
@@ -167,6 +335,10 @@ func (c *Caller) Call(req *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
 		return nil, errors.Err("cannot call blank endpoint")
 	}
 
+	if m, ok := ResolveAlias(req.Method); ok {
+		req.Method = m
+	}
+
 	walletID := ""
 	if c.userID != 0 {
 		walletID = sdkrouter.WalletID(c.userID)
@@ -176,17 +348,20 @@ func (c *Caller) Call(req *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.CacheInfo = nil
+	c.servedBy = ""
+	c.invalidateCached(q)
 
 	// Applying preflight hooks
 	var res *jsonrpc.RPCResponse
 	for _, hook := range c.preflightHooks {
 		if isMatchingHook(q.Method(), hook) {
-			res, err = hook.function(c, &HookContext{Query: q})
+			res, err = hook.function(c, &HookContext{Query: q, Client: c.Client, RequestInfo: c.Request})
 			if err != nil {
 				return nil, rpcerrors.NewSDKError(err)
 			}
 			if res != nil {
-				return res, nil
+				return q.clientResponse(res), nil
 			}
 		}
 	}
@@ -195,23 +370,55 @@ func (c *Caller) Call(req *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
 		// Attempt to retrieve the result from cache, retrieving and setting it if it's missing,
 		// and only send the query directly if it's still missing after the cache call somehow.
 		var ires interface{}
-		retriever := func() (interface{}, error) { return c.SendQuery(q) }
+		retriever := func() (interface{}, error) {
+			if _, ok := c.degraded[q.Method()]; ok && c.isUnhealthy() {
+				return nil, errEndpointUnhealthy
+			}
+			return c.sendThroughBreakers(q)
+		}
 		if q.IsCacheable() && c.Cache != nil {
-			ires, err = c.Cache.Retrieve(q.Method(), q.Params(), retriever)
-			if err != nil {
-				return nil, rpcerrors.NewSDKError(err)
+			qCache := c.Cache
+			if salt := q.CacheSalt(); salt != "" {
+				qCache = c.Cache.WithSalt(salt)
+			}
+			if d := c.CacheDirective; d != nil && d.MaxTTL > 0 {
+				metrics.ProxyQueryCacheDirectives.WithLabelValues("ttl").Inc()
+				ires, c.CacheInfo, err = qCache.WithMaxTTL(d.MaxTTL).RefreshWithInfo(q.Method(), q.Params(), retriever)
+			} else if c.BypassCache {
+				ires, c.CacheInfo, err = qCache.RefreshWithInfo(q.Method(), q.Params(), retriever)
+			} else {
+				ires, c.CacheInfo, err = qCache.RetrieveWithInfo(q.Method(), q.Params(), retriever)
+			}
+			if err == nil {
+				res, _ = ires.(*jsonrpc.RPCResponse)
 			}
-			res, _ = ires.(*jsonrpc.RPCResponse)
 		}
-		if res == nil {
-			res, err = c.SendQuery(q)
+		if res == nil && err == nil {
+			ires, err = retriever()
+			res, _ = ires.(*jsonrpc.RPCResponse)
 		}
 		if err != nil {
+			if dres, ok := c.degrade(q, err); ok {
+				return dres, nil
+			}
+			if fres, ok := c.fallback(q, err); ok {
+				return q.clientResponse(fres), nil
+			}
+			if errors.Is(err, scheduler.ErrQueueTimeout) {
+				return nil, rpcerrors.NewOverloadedError()
+			}
+			if ores, ok := c.walletOutage(q, err); ok {
+				return q.clientResponse(ores), nil
+			}
+			if rpcerrors.IsUpstreamInvalid(err) {
+				return nil, err
+			}
 			return nil, rpcerrors.NewSDKError(err)
 		}
+		rememberResult(q, res)
 	}
 
-	return res, nil
+	return q.clientResponse(res), nil
 }
 
 func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
@@ -222,11 +429,9 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 	op := metrics.StartOperation("sdk", "send_query")
 	defer op.End()
 
+	c.routeToPool(q)
 	for i := 0; i < walletLoadRetries; i++ {
-		start := time.Now()
-		client := c.getRPCClient(q.Method())
-		r, err = client.CallRaw(q.Request)
-		c.Duration = time.Since(start).Seconds()
+		r, err = c.callRPC(q)
 
 		// Generally a HTTP transport failure (connect error etc)
 		if err != nil {
@@ -237,7 +442,7 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 		// This checks if LbrynetServer responded with missing wallet error and tries to reload it,
 		// then repeats the request again
 		if isErrWalletNotLoaded(r) {
-			time.Sleep(walletLoadRetryWait)
+			waitBeforeRetry(q.Method(), retryReasonWalletNotLoaded, i)
 			// Using LBRY JSON-RPC client here for easier request/response processing
 			err := wallet.LoadWallet(c.endpoint, c.userID)
 			// Alert sentry on the last failed wallet load attempt
@@ -260,15 +465,29 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 		}
 	}
 
+	if err := validateResponse(q, r); err != nil {
+		return nil, err
+	}
+
 	logFields := logrus.Fields{
 		"method":   q.Method(),
 		"endpoint": c.endpoint,
 		"user_id":  c.userID,
 		"duration": c.Duration,
 	}
+	if c.Request.RequestID != "" {
+		logFields["request_id"] = c.Request.RequestID
+	}
+	sdkVersion := c.SDKVersion()
+	if sdkVersion != "" {
+		logFields["sdk_version"] = sdkVersion
+	}
+	logBody := methodInList(q.Method(), config.GetBodyLoggedMethods())
 	// Don't log query params for "sync_apply" method,
 	// and also log only some entries of lists to avoid clogging
-	if q.Method() != MethodSyncApply {
+	if logBody {
+		logFields["params"] = monitor.RedactParams(q.ParamsAsMap())
+	} else if q.Method() != MethodSyncApply {
 		paramMap := q.ParamsAsMap()
 		paramCut := cutSublistsToSize(paramMap, maxListSizeLogged)
 		logFields["params"] = paramCut
@@ -277,7 +496,7 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 
 	// Applying postflight hooks
 	var hookResp *jsonrpc.RPCResponse
-	hctx := &HookContext{Query: q, Response: r, logEntry: logEntry}
+	hctx := &HookContext{Query: q, Response: r, Client: c.Client, SDKVersion: sdkVersion, RequestInfo: c.Request, logEntry: logEntry}
 	for _, hook := range c.postflightHooks {
 		if isMatchingHook(q.Method(), hook) {
 			hookResp, err = hook.function(c, hctx)
@@ -291,11 +510,12 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 	}
 
 	if err != nil || (r != nil && r.Error != nil) {
-		logFields["response"] = r.Error
-		logEntry.Errorf("rpc call error: %v", r.Error.Message)
+		logEntry.WithField("response", r.Error).Errorf("rpc call error: %v", r.Error.Message)
+	} else if logBody {
+		logEntry.WithField("response", monitor.Redact(r.Result)).Info("rpc call processed")
 	} else {
 		if config.ShouldLogResponses() {
-			logFields["response"] = r
+			logEntry = logEntry.WithField("response", r)
 		}
 		logEntry.Log(getLogLevel(q.Method()), "rpc call processed")
 	}
@@ -303,9 +523,85 @@ func (c *Caller) SendQuery(q *Query) (*jsonrpc.RPCResponse, error) {
 	return r, err
 }
 
+// routeToPool sends methods which have a dedicated SDK pool there, falling back to the original endpoint
+// when the whole pool is quarantined. Queries performed on behalf of users stay on the server their wallet is assigned to.
+func (c *Caller) routeToPool(q *Query) {
+	if c.Router == nil || q.IsAuthenticated() {
+		return
+	}
+	if s := c.Router.PoolServer(q.Method(), ""); s != nil {
+		c.endpoint = s.Address
+	}
+}
+
+// callRPC sends the query to the SDK. If the SDK server turns out to be restarting,
+// it gets quarantined and safe reads are immediately rerouted to another healthy server.
+func (c *Caller) callRPC(q *Query) (*jsonrpc.RPCResponse, error) {
+	if c.Scheduler != nil {
+		release, err := c.Scheduler.AcquireTimeout(methodPriority(q.Method()), c.QueueTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	for reroutes := 0; ; reroutes++ {
+		start := time.Now()
+		c.servedBy = c.endpoint
+		sniffer := &responseSniffer{}
+		r, err := c.getRPCClient(q.Method(), sniffer).CallRaw(q.Request)
+		c.Duration = time.Since(start).Seconds()
+		if ierr := sniffer.invalidResponseError(err); ierr != nil {
+			logger.WithFields(logrus.Fields{"method": q.Method(), "endpoint": c.endpoint}).Warnf("%v (%v)", ierr, err)
+			err = ierr
+		}
+
+		if err == nil || c.Router == nil || !sdkrouter.IsRestartError(err) {
+			return r, err
+		}
+		c.Router.Quarantine(c.endpoint)
+		if !q.IsSafeRead() || reroutes >= maxRestartReroutes {
+			return r, err
+		}
+		var s *models.LbrynetServer
+		if c.Router.IsPooled(q.Method()) {
+			s = c.Router.PoolServer(q.Method(), c.endpoint)
+		} else {
+			s = c.Router.HealthyServer(c.endpoint)
+		}
+		if s == nil {
+			return r, err
+		}
+		logger.WithFields(logrus.Fields{
+			"method":   q.Method(),
+			"endpoint": c.endpoint,
+		}).Infof("sdk server is restarting, rerouting query to %v", s.Address)
+		metrics.ProxySDKRerouteCount.WithLabelValues(q.Method()).Inc()
+		waitBeforeRetry(q.Method(), retryReasonRestart, reroutes)
+		c.endpoint = s.Address
+	}
+}
+
+// methodPriority returns the scheduling priority of method as configured in MethodPriorities.
+func methodPriority(method string) scheduler.Priority {
+	for name, methods := range config.GetMethodPriorities() {
+		if !methodInList(method, methods) {
+			continue
+		}
+		p, err := scheduler.ParsePriority(name)
+		if err != nil {
+			logger.Log().Warnf("invalid method priority config: %v", err)
+			continue
+		}
+		return p
+	}
+	return scheduler.PriorityNormal
+}
+
 // IsCacheable returns true if this query can be cached.
+// Only methods listed in CacheableMethods config are cached so new methods are not cached by accident.
 func (q *Query) IsCacheable() bool {
-	return q.Method() == MethodResolve || q.Method() == MethodClaimSearch
+	_, ok := config.GetCacheableMethods()[q.Method()]
+	return ok
 }
 
 func getLogLevel(m string) logrus.Level {
@@ -2,7 +2,12 @@ package query
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,8 +17,11 @@ import (
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/clientinfo"
 	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/scheduler"
 	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
 
 	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
 
@@ -43,7 +51,7 @@ func TestCaller_CallRelaxedMethods(t *testing.T) {
 	config.Override("LbrynetXPercentage", 0)
 	defer config.RestoreOverridden()
 	for _, m := range relaxedMethods {
-		if m == MethodStatus || m == MethodGet {
+		if m == MethodStatus || m == MethodGet || m == MethodResolveClaimIDs {
 			continue
 		}
 		t.Run(m, func(t *testing.T) {
@@ -333,6 +341,85 @@ func TestCaller_AddPostflightHook_LogField(t *testing.T) {
 	assert.Equal(t, "8.8.8.8", logHook.LastEntry().Data["remote_ip"])
 }
 
+func TestCaller_BodyLoggedMethods(t *testing.T) {
+	config.Override("BodyLoggedMethods", []string{MethodClaimSearch})
+	defer config.RestoreOverridden()
+	logHook := logrusTest.NewLocal(logger.Entry.Logger)
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	c := NewCaller(srv.URL, 0)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": [{"name": "one", "api_key": "abc"}]}, "id": 0}`
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one", "auth_token": "secret"}))
+	require.NoError(t, err)
+	entry := logHook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, map[string]interface{}{"name": "one", "auth_token": "****"}, entry.Data["params"])
+	assert.Equal(t,
+		map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "one", "api_key": "****"}}},
+		entry.Data["response"])
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	_, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "one"}))
+	require.NoError(t, err)
+	assert.NotContains(t, logHook.LastEntry().Data, "response")
+}
+
+func TestCaller_HooksReceiveClient(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {}, "id": 0}`
+
+	c := NewCaller(srv.URL, 0)
+	assert.Equal(t, clientinfo.UnknownClient, c.Client)
+	c.Client = clientinfo.Info{App: "odysee-web", Version: "1.4.2"}
+
+	var preflightClient, postflightClient clientinfo.Info
+	c.AddPreflightHook(MethodResolve, func(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+		preflightClient = hctx.Client
+		return nil, nil
+	}, "")
+	c.AddPostflightHook(MethodResolve, func(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+		postflightClient = hctx.Client
+		return nil, nil
+	}, "")
+
+	_, err := c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Equal(t, c.Client, preflightClient)
+	assert.Equal(t, c.Client, postflightClient)
+}
+
+func TestCaller_HooksReceiveRequestInfo(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {}, "id": 0}`
+
+	c := NewCaller(srv.URL, 0)
+	c.Request = RequestInfo{User: &models.User{ID: 42}, RemoteIP: "8.8.8.8", Origin: "odysee", RequestID: "abc"}
+
+	var preflight, postflight RequestInfo
+	var passed interface{}
+	c.AddPreflightHook(MethodResolve, func(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+		preflight = hctx.RequestInfo
+		assert.Nil(t, hctx.Value("started"))
+		hctx.Set("started", 1)
+		return nil, nil
+	}, "")
+	c.AddPostflightHook(MethodResolve, func(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+		postflight = hctx.RequestInfo
+		passed = hctx.Value("started")
+		return nil, nil
+	}, "")
+
+	_, err := c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Equal(t, c.Request, preflight)
+	assert.Equal(t, c.Request, postflight)
+	assert.Equal(t, 1, passed)
+	assert.Equal(t, c.Request, c.CloneWithoutHook(srv.URL, "", "").Request)
+}
+
 func TestCaller_CloneWithoutHook(t *testing.T) {
 	timesCalled := 0
 	call := func() {
@@ -585,6 +672,37 @@ func TestCaller_timeouts(t *testing.T) {
 	require.Regexp(t, `timeout awaiting response headers`, err.Error())
 }
 
+func TestSizedTimeout(t *testing.T) {
+	s := config.SizedRPCTimeout{Base: time.Minute, PerMB: 500 * time.Millisecond, Max: 20 * time.Minute}
+	assert.Equal(t, time.Minute, sizedTimeout(s, 0))
+	assert.Equal(t, time.Minute+500*time.Millisecond, sizedTimeout(s, 1<<20))
+	assert.Equal(t, time.Minute+250*time.Millisecond, sizedTimeout(s, 1<<19))
+	assert.Equal(t, time.Minute+50*time.Second, sizedTimeout(s, 100<<20))
+	assert.Equal(t, time.Minute+512*time.Second, sizedTimeout(s, 1<<30))
+	assert.Equal(t, 20*time.Minute, sizedTimeout(s, 10<<30))
+
+	s.Max = 0
+	assert.Equal(t, time.Minute+5120*time.Second, sizedTimeout(s, 10<<30))
+	assert.Equal(t, time.Duration(math.MaxInt64), sizedTimeout(s, math.MaxInt64))
+}
+
+func TestCaller_getRPCTimeoutSized(t *testing.T) {
+	config.Override("RPCTimeouts", map[string]string{"publish": "4m", "txo_list": "2m"})
+	config.Override("SizedRPCTimeouts", map[string]interface{}{
+		"publish": map[string]interface{}{"Base": "10s", "PerMB": "1s", "Max": "1h"},
+	})
+	defer config.RestoreOverridden()
+
+	c := NewCaller("", 0)
+	assert.Equal(t, 10*time.Second, c.getRPCTimeout("publish"))
+	c.PayloadSize = 1 << 20
+	assert.Equal(t, 11*time.Second, c.getRPCTimeout("publish"))
+	c.PayloadSize = 1 << 30
+	assert.Equal(t, 10*time.Second+1024*time.Second, c.getRPCTimeout("publish"))
+	assert.Equal(t, 2*time.Minute, c.getRPCTimeout("txo_list"))
+	assert.Equal(t, defaultRPCTimeout, c.getRPCTimeout("resolve"))
+}
+
 func TestCaller_DontReloadWalletAfterOtherErrors(t *testing.T) {
 	rand.Seed(time.Now().UnixNano())
 	walletID := sdkrouter.WalletID(rand.Intn(100))
@@ -734,8 +852,10 @@ func TestCaller_GetInvalidURLAuthenticated(t *testing.T) {
 	)
 	request := jsonrpc.NewRequest(MethodGet, map[string]interface{}{"uri": uri})
 	resp, err := NewCaller(srv.URL, dummyUserID).Call(request)
-	assert.EqualError(t, err, "could not find a corresponding entry in the resolve response")
-	assert.Nil(t, resp)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, rpcerrors.NewInvalidParamsError(nil).Code(), resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, ErrInvalidURI.Error())
 }
 
 func TestCaller_GetPaidCannotPurchase(t *testing.T) {
@@ -1010,3 +1130,161 @@ func TestCaller_JSONRPCNotCut(t *testing.T) {
 	assert.Equal(t, channelIdscpy, req.Params.(map[string]interface{})["channel_ids"])
 	assert.Equal(t, req.Params.(map[string]interface{})["urls"], "what")
 }
+
+func TestCaller_ReroutesSafeReadsFromRestartingServer(t *testing.T) {
+	down := httptest.NewServer(nil)
+	down.Close()
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "down", Address: down.URL},
+		&models.LbrynetServer{Name: "up", Address: srv.URL},
+	)
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	c := NewCaller(down.URL, 0)
+	c.Router = rt
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "x"}))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, srv.URL, c.Endpoint())
+	assert.True(t, rt.IsQuarantined(down.URL))
+
+	// Wallet queries are bound to the server and are not rerouted
+	c = NewCaller(down.URL, 1)
+	c.Router = rt
+	_, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.Error(t, err)
+	assert.Equal(t, down.URL, c.Endpoint())
+}
+
+func TestCaller_RoutesMethodsToPools(t *testing.T) {
+	def := test.MockHTTPServer(nil)
+	defer def.Close()
+	search := test.MockHTTPServer(nil)
+	defer search.Close()
+
+	rt := sdkrouter.NewWithServers(&models.LbrynetServer{Name: "default", Address: def.URL})
+	require.NoError(t, rt.SetMethodPools([]sdkrouter.MethodPool{
+		{Name: "search", Methods: []string{MethodClaimSearch, MethodWalletBalance}, Servers: []string{search.URL}},
+	}))
+
+	search.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	c := NewCaller(def.URL, 0)
+	c.Router = rt
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "x"}))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, search.URL, c.Endpoint())
+
+	def.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	c = NewCaller(def.URL, 0)
+	c.Router = rt
+	_, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Equal(t, def.URL, c.Endpoint())
+
+	// Wallet queries stay on the server the wallet is assigned to
+	def.NextResponse <- `{"jsonrpc": "2.0", "result": {"available": "1.0"}, "id": 0}`
+	c = NewCaller(def.URL, 1)
+	c.Router = rt
+	_, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.NoError(t, err)
+	assert.Equal(t, def.URL, c.Endpoint())
+}
+
+func TestMethodPriority(t *testing.T) {
+	config.Override("MethodPriorities", map[string][]string{"high": {"resolve"}, "low": {"claim_search"}, "urgent": {"get"}})
+	defer config.RestoreOverridden()
+
+	assert.Equal(t, scheduler.PriorityHigh, methodPriority("resolve"))
+	assert.Equal(t, scheduler.PriorityLow, methodPriority("claim_search"))
+	assert.Equal(t, scheduler.PriorityNormal, methodPriority("get"))
+	assert.Equal(t, scheduler.PriorityNormal, methodPriority("wallet_balance"))
+}
+
+func TestCaller_WaitsForScheduler(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+
+	s := scheduler.New(1, time.Hour)
+	release := s.Acquire(scheduler.PriorityHigh)
+
+	done := make(chan struct{})
+	go func() {
+		c := NewCaller(srv.URL, 0)
+		c.Scheduler = s
+		_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "x"}))
+		assert.NoError(t, err)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return s.Queued() == 1 }, time.Second, time.Millisecond)
+
+	release()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("query was not sent after the slot was released")
+	}
+}
+
+func TestCaller_SchedulerQueueTimeout(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	s := scheduler.New(1, time.Hour)
+	release := s.Acquire(scheduler.PriorityHigh)
+	defer release()
+
+	c := NewCaller(srv.URL, 0)
+	c.Scheduler = s
+	c.QueueTimeout = 50 * time.Millisecond
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "x"}))
+	require.Error(t, err)
+	assert.Contains(t, string(rpcerrors.ToJSON(err)), `"code": -32096`)
+	assert.Equal(t, 0, s.Queued())
+}
+
+func TestCaller_CacheSaltIsolatesVariants(t *testing.T) {
+	var (
+		hits    int32
+		serving atomic.Value
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "result": {"items": [], "variant": "%v"}, "id": 0}`, serving.Load())
+	}))
+	defer srv.Close()
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+
+	call := func(variant string) string {
+		c := NewCaller(srv.URL, 0)
+		c.Cache = qCache
+		c.AddPreflightHook(MethodClaimSearch, func(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+			if variant != "" {
+				hctx.Query.AddCacheSalt("experiment:" + variant)
+			}
+			return nil, nil
+		}, "experiment")
+		res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "what"}))
+		require.NoError(t, err)
+		qCache.Wait()
+		return res.Result.(map[string]interface{})["variant"].(string)
+	}
+
+	for _, v := range []string{"", "a", "b"} {
+		serving.Store(v)
+		assert.Equal(t, v, call(v))
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&hits))
+
+	// Each variant is served its own cached response from now on.
+	serving.Store("none")
+	for _, v := range []string{"b", "", "a"} {
+		assert.Equal(t, v, call(v))
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&hits))
+}
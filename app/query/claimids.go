@@ -0,0 +1,166 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// Names of per-claim errors in resolve_claim_ids results, they follow the format of resolve errors.
+const (
+	claimErrorNotFound = "NOT_FOUND"
+	claimErrorSDK      = "SDK_ERROR"
+)
+
+// preflightHookResolveClaimIDs answers resolve_claim_ids queries, which return claims by their IDs
+// in the same shape as resolve does by URLs: a map of ID to claim or to an error entry.
+// Claims are taken from the cache when possible, the rest are fetched with batched claim_search calls.
+func preflightHookResolveClaimIDs(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	res := q.newResponse()
+	ids, err := claimIDsParam(q)
+	if err != nil {
+		res.Error = &jsonrpc.RPCError{Code: rpcerrors.NewInvalidParamsError(err).Code(), Message: err.Error()}
+		return res, nil
+	}
+
+	result := map[string]interface{}{}
+	missing := []string{}
+	for _, id := range ids {
+		if _, ok := result[id]; ok {
+			continue
+		}
+		if claim, ok := c.cachedClaim(id); ok {
+			result[id] = claim
+		} else {
+			result[id] = nil
+			missing = append(missing, id)
+		}
+	}
+	if len(result) > 0 {
+		metrics.ProxyClaimIDsCacheHitRatio.Observe(float64(len(result)-len(missing)) / float64(len(result)))
+	}
+
+	batchSize := config.GetClaimIDsBatchSize()
+	if batchSize <= 0 {
+		batchSize = len(missing)
+	}
+	for start := 0; start < len(missing); start += batchSize {
+		end := start + batchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		batch := missing[start:end]
+		claims, err := c.searchClaimIDs(batch)
+		for _, id := range batch {
+			switch claim, ok := claims[id]; {
+			case err != nil:
+				result[id] = claimError(claimErrorSDK, err.Error())
+			case !ok:
+				result[id] = claimError(claimErrorNotFound, fmt.Sprintf("claim %v not found", id))
+			default:
+				result[id] = claim
+				c.cacheClaim(id, claim)
+			}
+		}
+	}
+
+	res.Result = result
+	return res, nil
+}
+
+// claimIDsParam returns claim IDs requested by the query.
+func claimIDsParam(q *Query) ([]string, error) {
+	raw, ok := q.ParamsAsMap()[ParamClaimIDs]
+	if !ok {
+		return nil, errors.Err("%v param is required", ParamClaimIDs)
+	}
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, i := range v {
+			items = append(items, i)
+		}
+	case string:
+		items = []interface{}{v}
+	default:
+		return nil, errors.Err("%v must be a list of strings", ParamClaimIDs)
+	}
+	ids := make([]string, 0, len(items))
+	for _, i := range items {
+		id, ok := i.(string)
+		if !ok || id == "" {
+			return nil, errors.Err("%v must be a list of strings", ParamClaimIDs)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// searchClaimIDs fetches claims with a single claim_search and returns them by ID.
+// Claims which don't exist are missing from the map.
+func (c *Caller) searchClaimIDs(ids []string) (map[string]interface{}, error) {
+	q, err := NewQuery(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{
+		ParamClaimIDs: ids,
+		"page_size":   len(ids),
+		"no_totals":   true,
+	}), "")
+	if err != nil {
+		return nil, err
+	}
+	q.setValue(skipEnrichmentKey, true)
+	res, err := c.SendQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.Err(res.Error.Message)
+	}
+	claims := map[string]interface{}{}
+	for _, i := range responseItems(res) {
+		claim, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := claim["claim_id"].(string); ok {
+			claims[id] = claim
+		}
+	}
+	return claims, nil
+}
+
+func responseItems(res *jsonrpc.RPCResponse) []interface{} {
+	result, ok := res.Result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	items, _ := result["items"].([]interface{})
+	return items
+}
+
+func (c *Caller) cachedClaim(id string) (interface{}, bool) {
+	if c.Cache == nil || c.BypassCache {
+		return nil, false
+	}
+	return c.Cache.Get(MethodResolveClaimIDs, id)
+}
+
+func (c *Caller) cacheClaim(id string, claim interface{}) {
+	if c.Cache == nil {
+		return
+	}
+	if err := c.Cache.Set(MethodResolveClaimIDs, id, claim); err != nil {
+		logger.Log().Warnf("cannot cache claim %v: %v", id, err)
+	}
+}
+
+func claimError(name, text string) map[string]interface{} {
+	return map[string]interface{}{"error": map[string]interface{}{"name": name, "text": text}}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func claimSearchServer(t *testing.T, batches chan []interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, MethodClaimSearch, req.Method)
+		ids := req.Params.(map[string]interface{})[ParamClaimIDs].([]interface{})
+		batches <- ids
+		items := []interface{}{}
+		for _, id := range ids {
+			if id != "missing" {
+				items = append(items, map[string]interface{}{"claim_id": id, "name": "claim-" + id.(string)})
+			}
+		}
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"items": items}})
+	}))
+}
+
+func TestCaller_ResolveClaimIDs(t *testing.T) {
+	config.Override("ClaimIDsBatchSize", 2)
+	defer config.RestoreOverridden()
+
+	batches := make(chan []interface{}, 10)
+	srv := claimSearchServer(t, batches)
+	defer srv.Close()
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+
+	c := NewCaller(srv.URL, 0)
+	c.Cache = qCache
+	res, err := c.Call(jsonrpc.NewRequest(MethodResolveClaimIDs, map[string]interface{}{
+		ParamClaimIDs: []interface{}{"a", "b", "missing", "a"},
+	}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	result := res.Result.(map[string]interface{})
+	require.Len(t, result, 3)
+	assert.Equal(t, "claim-a", result["a"].(map[string]interface{})["name"])
+	assert.Equal(t, "claim-b", result["b"].(map[string]interface{})["name"])
+	assert.Equal(t, claimErrorNotFound, result["missing"].(map[string]interface{})["error"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{"a", "b"}, <-batches)
+	assert.Equal(t, []interface{}{"missing"}, <-batches)
+	qCache.Wait()
+
+	res, err = c.Call(jsonrpc.NewRequest(MethodResolveClaimIDs, map[string]interface{}{
+		ParamClaimIDs: []interface{}{"b", "c"},
+	}))
+	require.NoError(t, err)
+	result = res.Result.(map[string]interface{})
+	assert.Equal(t, "claim-b", result["b"].(map[string]interface{})["name"])
+	assert.Equal(t, "claim-c", result["c"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{"c"}, <-batches)
+	assert.Empty(t, batches)
+}
+
+func TestCaller_ResolveClaimIDsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Error: &jsonrpc.RPCError{Code: -32500, Message: "boom"}})
+	}))
+	defer srv.Close()
+	c := NewCaller(srv.URL, 0)
+
+	for _, params := range []interface{}{nil, map[string]interface{}{ParamClaimIDs: 1}, map[string]interface{}{ParamClaimIDs: []interface{}{1}}} {
+		res, err := c.Call(jsonrpc.NewRequest(MethodResolveClaimIDs, params))
+		require.NoError(t, err)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, -32602, res.Error.Code)
+	}
+
+	res, err := c.Call(jsonrpc.NewRequest(MethodResolveClaimIDs, map[string]interface{}{ParamClaimIDs: []interface{}{"a"}}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	e := res.Result.(map[string]interface{})["a"].(map[string]interface{})["error"].(map[string]interface{})
+	assert.Equal(t, claimErrorSDK, e["name"])
+	assert.Equal(t, "boom", e["text"])
+}
@@ -15,6 +15,9 @@ const (
 	MethodWalletSend       = "wallet_send"
 	MethodSyncApply        = "sync_apply"
 	MethodCommentReactList = "comment_react_list"
+	MethodResolveClaimIDs  = "resolve_claim_ids"
+	MethodTransactionList  = "transaction_list"
+	MethodClaimList        = "claim_list"
 
 	ParamStreamingUrl    = "streaming_url"
 	ParamPurchaseReceipt = "purchase_receipt"
@@ -23,6 +26,7 @@ const (
 	ParamUrls            = "urls"
 	ParamNewSDKServer    = "new_sdk_server"
 	ParamChannelID       = "channel_id"
+	ParamClaimIDs        = "claim_ids"
 )
 
 var forbiddenParams = []string{ParamAccountID, ParamNewSDKServer}
@@ -39,10 +43,46 @@ var relaxedMethods = []string{
 	"comment_list",
 	"collection_resolve",
 	MethodCommentReactList,
+	MethodResolveClaimIDs,
 	"version",
 	"routing_table_get",
 }
 
+// safeReadMethods are read-only methods which don't depend on the state of a particular SDK server.
+var safeReadMethods = []string{
+	MethodStatus,
+	MethodResolve,
+	MethodClaimSearch,
+	"transaction_show",
+	"stream_cost_estimate",
+	"comment_list",
+	"collection_resolve",
+	MethodCommentReactList,
+	"version",
+}
+
+// walletMutationMethods spend funds or change claims, running them concurrently for the same user
+// risks doing the same thing twice.
+var walletMutationMethods = []string{
+	"account_send",
+	"channel_abandon",
+	"channel_create",
+	"channel_update",
+	"collection_abandon",
+	"collection_create",
+	"collection_update",
+	"publish",
+	MethodPurchaseCreate,
+	"stream_abandon",
+	"stream_create",
+	"stream_repost",
+	"stream_update",
+	"support_abandon",
+	"support_create",
+	"txo_spend",
+	MethodWalletSend,
+}
+
 // walletSpecificMethods are methods which require wallet_id.
 // This list will inevitably turn stale sooner or later as new methods
 // are added to the SDK so relaxedMethods should be used for strict validation
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// preflightHookParamDefaults fills in params configured in ParamDefaults for the query method
+// which the client hasn't supplied. Config is read on every query so changes apply without a restart.
+func preflightHookParamDefaults(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	defaults, ok := config.GetParamDefaults()[hctx.Query.Method()]
+	if !ok || len(defaults) == 0 {
+		return nil, nil
+	}
+	applyParamDefaults(hctx.Query, defaults)
+	return nil, nil
+}
+
+// applyParamDefaults merges defaults into query params without overriding any of them.
+// Positional (list) params are left alone as there's no telling which of them are omitted.
+func applyParamDefaults(q *Query, defaults map[string]interface{}) {
+	if q.Params() == nil {
+		q.Request.Params = map[string]interface{}{}
+	}
+	params := q.ParamsAsMap()
+	if params == nil {
+		return
+	}
+	for k, v := range defaults {
+		if _, ok := params[k]; !ok {
+			params[k] = v
+		}
+	}
+}
+
+// preflightHookForcedParams sets params configured in ForcedParams for the query method,
+// replacing values supplied by the client. Replaced values are logged.
+func preflightHookForcedParams(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	forced, ok := config.GetForcedParams()[hctx.Query.Method()]
+	if !ok || len(forced) == 0 {
+		return nil, nil
+	}
+	if overridden := applyForcedParams(hctx.Query, forced); len(overridden) > 0 {
+		logger.WithFields(logrus.Fields{
+			"method":     hctx.Query.Method(),
+			"params":     overridden,
+			"request_id": hctx.RequestID,
+		}).Info("forced params have overridden client values")
+	}
+	return nil, nil
+}
+
+// applyForcedParams sets forced values in query params. It returns names of params the client has supplied
+// different values for. Positional (list) params are left alone as there's no telling which value is which param.
+func applyForcedParams(q *Query, forced map[string]interface{}) []string {
+	if q.Params() == nil {
+		q.Request.Params = map[string]interface{}{}
+	}
+	params := q.ParamsAsMap()
+	if params == nil {
+		return nil
+	}
+	var overridden []string
+	for k, v := range forced {
+		if cv, ok := params[k]; ok && !reflect.DeepEqual(cv, v) {
+			overridden = append(overridden, k)
+		}
+		params[k] = v
+	}
+	sort.Strings(overridden)
+	return overridden
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_ParamDefaults(t *testing.T) {
+	config.Override("ParamDefaults", map[string]interface{}{
+		"claim_search": map[string]interface{}{"page_size": 20, "no_totals": true},
+	})
+	defer config.RestoreOverridden()
+
+	received := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params, _ := req.Params.(map[string]interface{})
+		received <- params
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 5}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 5.0, "no_totals": true}, <-received)
+
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 20.0, "no_totals": true}, <-received)
+
+	_, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "one"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"urls": "lbry://one"}, <-received)
+}
+
+func TestCaller_ParamDefaultsReload(t *testing.T) {
+	defer config.RestoreOverridden()
+
+	q, err := NewQuery(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "one"}), "")
+	require.NoError(t, err)
+	config.Override("ParamDefaults", map[string]interface{}{})
+	_, err = preflightHookParamDefaults(nil, &HookContext{Query: q})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"urls": "one"}, q.ParamsAsMap())
+
+	config.Override("ParamDefaults", map[string]interface{}{
+		"resolve": map[string]interface{}{"include_purchase_receipt": true},
+	})
+	_, err = preflightHookParamDefaults(nil, &HookContext{Query: q})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"urls": "one", "include_purchase_receipt": true}, q.ParamsAsMap())
+}
+
+func TestCaller_ForcedParams(t *testing.T) {
+	config.Override("ParamDefaults", map[string]interface{}{
+		"claim_search": map[string]interface{}{"page_size": 20},
+	})
+	config.Override("ForcedParams", map[string]interface{}{
+		"claim_search": map[string]interface{}{"page_size": 10, "new_sdk_server": "http://sdk2"},
+	})
+	defer config.RestoreOverridden()
+
+	received := make(chan interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received <- req.Params
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+
+	// Forced params win over both client values and defaults
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 50, "name": "x"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 10.0, "new_sdk_server": "http://sdk2", "name": "x"}, <-received)
+
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 10.0, "new_sdk_server": "http://sdk2"}, <-received)
+
+	// Clients still can't supply params reserved for the proxy themselves
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"new_sdk_server": "http://sdk1"}))
+	assert.Error(t, err)
+
+	// Positional params are left alone
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, "x", 50))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"x", 50.0}, <-received)
+}
+
+func TestApplyForcedParams(t *testing.T) {
+	q, err := NewQuery(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"blocking": true, "page_size": 5}), "")
+	require.NoError(t, err)
+	overridden := applyForcedParams(q, map[string]interface{}{"blocking": true, "page_size": 10, "no_totals": true})
+	assert.Equal(t, []string{"page_size"}, overridden)
+	assert.Equal(t, map[string]interface{}{"blocking": true, "page_size": 10, "no_totals": true}, q.ParamsAsMap())
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// DegradedField is set to true in results of responses produced by a degraded mode handler.
+const DegradedField = "degraded"
+
+// errEndpointUnhealthy is returned instead of sending a query to an SDK server which is known to be down.
+var errEndpointUnhealthy = errors.Base("sdk server is unhealthy")
+
+// DegradedHandler produces a best-effort response to the query when the SDK cannot answer it in time.
+// It should return an error if it cannot come up with a response either.
+type DegradedHandler func(c *Caller, q *Query) (*jsonrpc.RPCResponse, error)
+
+type degradedEntry struct {
+	handler DegradedHandler
+	timeout time.Duration
+}
+
+// SetDegradedHandler turns on degraded mode for method: SDK calls for it are given at most timeout,
+// and when they fail, time out or the SDK server is unhealthy, the response is produced by handler instead.
+// Zero timeout leaves the usual method timeout in place.
+func (c *Caller) SetDegradedHandler(method string, timeout time.Duration, handler DegradedHandler) {
+	if c.degraded == nil {
+		c.degraded = map[string]degradedEntry{}
+	}
+	c.degraded[method] = degradedEntry{handler: handler, timeout: timeout}
+}
+
+// IsDegraded returns true if the response has been produced by a degraded mode handler.
+func IsDegraded(r *jsonrpc.RPCResponse) bool {
+	if r == nil {
+		return false
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	d, _ := result[DegradedField].(bool)
+	return d
+}
+
+// isUnhealthy returns true when the caller's SDK server is known to be down, making waiting on it pointless.
+func (c *Caller) isUnhealthy() bool {
+	return c.Router != nil && c.Router.IsQuarantined(c.endpoint)
+}
+
+// degrade returns the response of the degraded mode handler for q if there's one, cause is the reason
+// the SDK response is unavailable. ok is false if degraded mode is not on for the method.
+func (c *Caller) degrade(q *Query, cause error) (res *jsonrpc.RPCResponse, ok bool) {
+	d, ok := c.degraded[q.Method()]
+	if !ok {
+		return nil, false
+	}
+	l := logger.WithFields(logrus.Fields{"method": q.Method(), "endpoint": c.endpoint, "cause": cause})
+	res, err := d.handler(c, q)
+	if err != nil {
+		l.Warnf("degraded mode handler failed: %v", err)
+		return nil, false
+	}
+	if res == nil {
+		return nil, false
+	}
+	if result, isMap := res.Result.(map[string]interface{}); isMap {
+		result[DegradedField] = true
+	}
+	metrics.ProxyDegradedResponses.WithLabelValues(q.Method()).Inc()
+	l.Info("responding in degraded mode")
+	return res, true
+}
+
+// ReducedClaimSearch is a degraded mode handler for claim_search which repeats the query asking for
+// at most pageSize results and no totals, preferably on another healthy SDK server.
+func ReducedClaimSearch(pageSize int) DegradedHandler {
+	return func(c *Caller, q *Query) (*jsonrpc.RPCResponse, error) {
+		params := q.CopyParamsAsMap()
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+		current := intValue(params["page_size"])
+		if current <= 0 || current > pageSize {
+			params["page_size"] = pageSize
+		}
+		params["no_totals"] = true
+
+		endpoint := c.endpoint
+		if c.Router != nil {
+			if s := c.Router.HealthyServer(c.endpoint); s != nil {
+				endpoint = s.Address
+			}
+		}
+		rq, err := NewQuery(jsonrpc.NewRequest(q.Method(), params), q.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.CloneWithoutHook(endpoint, "", "").SendQuery(rq)
+		if err != nil {
+			return nil, err
+		}
+		if res.Error != nil {
+			return nil, errors.Err(res.Error.Message)
+		}
+		return res, nil
+	}
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+// degradableSDK responds slowly to full claim_search queries and quickly to the reduced ones.
+func degradableSDK(t *testing.T, fullCalls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params := req.Params.(map[string]interface{})
+		if params["no_totals"] != true {
+			atomic.AddInt32(fullCalls, 1)
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte(`{"jsonrpc": "2.0", "result": {"items": [], "total_pages": 10}, "id": 0}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      0,
+			"result":  map[string]interface{}{"items": []interface{}{}, "page_size": params["page_size"]},
+		})
+	}))
+}
+
+func TestCaller_DegradedSlow(t *testing.T) {
+	var fullCalls int32
+	srv := degradableSDK(t, &fullCalls)
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+	c.SetDegradedHandler(MethodClaimSearch, 50*time.Millisecond, ReducedClaimSearch(5))
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 50}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.True(t, IsDegraded(res))
+	assert.Equal(t, json.Number("5"), res.Result.(map[string]interface{})["page_size"])
+	assert.EqualValues(t, 1, atomic.LoadInt32(&fullCalls))
+
+	// Other methods are not affected
+	c = NewCaller(srv.URL, 0)
+	c.SetDegradedHandler(MethodResolve, 50*time.Millisecond, ReducedClaimSearch(5))
+	res, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 50}))
+	require.NoError(t, err)
+	assert.False(t, IsDegraded(res))
+	assert.Equal(t, json.Number("10"), res.Result.(map[string]interface{})["total_pages"])
+}
+
+func TestCaller_DegradedUnhealthy(t *testing.T) {
+	var fullCalls int32
+	srv := degradableSDK(t, &fullCalls)
+	defer srv.Close()
+
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "down", Address: "http://down"},
+		&models.LbrynetServer{Name: "up", Address: srv.URL},
+	)
+	rt.Quarantine("http://down")
+
+	c := NewCaller("http://down", 0)
+	c.Router = rt
+	c.SetDegradedHandler(MethodClaimSearch, 0, ReducedClaimSearch(5))
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 2}))
+	require.NoError(t, err)
+	assert.True(t, IsDegraded(res))
+	assert.Equal(t, json.Number("2"), res.Result.(map[string]interface{})["page_size"])
+	assert.EqualValues(t, 0, atomic.LoadInt32(&fullCalls))
+}
+
+func TestCaller_DegradedHandlerFails(t *testing.T) {
+	c := NewCaller("http://down", 0)
+	c.SetDegradedHandler(MethodClaimSearch, 0, ReducedClaimSearch(5))
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// skipEnrichmentKey marks claim_search queries made to resolve claims by their IDs, their results are the ones
+// which get cached for enrichment so there's nothing to enrich them with.
+const skipEnrichmentKey = "skip_enrichment"
+
+// postflightHookEnrichClaimSearch merges claims cached by resolve_claim_ids into claim_search results,
+// so search shows the same data as resolve does. A cached claim at a later height replaces the item
+// while one at the same height only adds fields the item is missing. Only the first MaxItems items are looked up
+// and nothing is done when SDK calls are queueing up or the SDK server is unhealthy (see ClaimSearchEnrichment config).
+func postflightHookEnrichClaimSearch(c *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	cfg := config.GetClaimSearchEnrichment()
+	r := hctx.Response
+	if !cfg.Enabled || c.Cache == nil || r == nil || r.Error != nil || hctx.Value(skipEnrichmentKey) != nil {
+		return nil, nil
+	}
+	items := responseItems(r)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if c.isUnhealthy() || (c.Scheduler != nil && c.Scheduler.Queued() > cfg.MaxQueued) {
+		metrics.ProxyClaimSearchEnrichmentSkipped.WithLabelValues("load").Inc()
+		return nil, nil
+	}
+	if cfg.MaxItems > 0 && len(items) > cfg.MaxItems {
+		items = items[:cfg.MaxItems]
+	}
+
+	var looked, hits, enriched int
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, ok := item["claim_id"].(string)
+		if !ok {
+			continue
+		}
+		looked++
+		cached, ok := c.cachedClaim(id)
+		if !ok {
+			continue
+		}
+		claim, ok := cached.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hits++
+		if mergeClaim(item, claim) {
+			enriched++
+		}
+	}
+	if looked > 0 {
+		metrics.ProxyClaimSearchEnrichmentHitRatio.Observe(float64(hits) / float64(looked))
+	}
+	if enriched > 0 {
+		metrics.ProxyClaimSearchEnrichedItems.Add(float64(enriched))
+		hctx.AddLogField("enriched_claims", enriched)
+	}
+	return nil, nil
+}
+
+// mergeClaim copies fields of the cached claim into item, all of them if the cached claim is at a later height,
+// missing ones if it's at the same height. It reports whether item has been changed.
+// Copies are made so the cached claim is never shared with responses.
+func mergeClaim(item, cached map[string]interface{}) bool {
+	ih, ch := intValue(item["height"]), intValue(cached["height"])
+	if ch < ih {
+		return false
+	}
+	var changed bool
+	for k, v := range cached {
+		if _, ok := item[k]; ok && ch == ih {
+			continue
+		}
+		item[k] = copyValue(v)
+		changed = true
+	}
+	return changed
+}
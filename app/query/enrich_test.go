@@ -0,0 +1,120 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func searchItems() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"claim_id": "fresh", "height": 100, "name": "old-name"},
+		map[string]interface{}{"claim_id": "partial", "height": 100, "name": "partial"},
+		map[string]interface{}{"claim_id": "stale", "height": 100, "name": "new-name"},
+		map[string]interface{}{"claim_id": "uncached", "height": 100},
+	}
+}
+
+func cacheClaims(t *testing.T, c *cache.Cache) {
+	require.NoError(t, c.Set(MethodResolveClaimIDs, "fresh", map[string]interface{}{
+		"claim_id": "fresh", "height": 110, "name": "new-name", "value": map[string]interface{}{"title": "New"},
+	}))
+	require.NoError(t, c.Set(MethodResolveClaimIDs, "partial", map[string]interface{}{
+		"claim_id": "partial", "height": 100, "name": "other", "meta": map[string]interface{}{"reposted": 1},
+	}))
+	require.NoError(t, c.Set(MethodResolveClaimIDs, "stale", map[string]interface{}{
+		"claim_id": "stale", "height": 90, "name": "old-name",
+	}))
+	c.Wait()
+}
+
+func TestCaller_EnrichClaimSearch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"items": searchItems()}})
+	}))
+	defer srv.Close()
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	cacheClaims(t, qCache)
+
+	enriched := metrics.GetCounterValue(metrics.ProxyClaimSearchEnrichedItems)
+	c := NewCaller(srv.URL, 0)
+	c.Cache = qCache
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"channel": "@enrich"}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	items := res.Result.(map[string]interface{})["items"].([]interface{})
+	require.Len(t, items, 4)
+
+	fresh := items[0].(map[string]interface{})
+	assert.Equal(t, "new-name", fresh["name"])
+	assert.EqualValues(t, 110, intValue(fresh["height"]))
+	assert.Equal(t, "New", fresh["value"].(map[string]interface{})["title"])
+
+	partial := items[1].(map[string]interface{})
+	assert.Equal(t, "partial", partial["name"])
+	assert.NotNil(t, partial["meta"])
+
+	assert.Equal(t, "new-name", items[2].(map[string]interface{})["name"])
+	assert.NotContains(t, items[3].(map[string]interface{}), "name")
+	assert.Equal(t, enriched+2, metrics.GetCounterValue(metrics.ProxyClaimSearchEnrichedItems))
+
+	// Cached claims are not shared with responses
+	fresh["value"].(map[string]interface{})["title"] = "Changed"
+	cached, ok := qCache.Get(MethodResolveClaimIDs, "fresh")
+	require.True(t, ok)
+	assert.Equal(t, "New", cached.(map[string]interface{})["value"].(map[string]interface{})["title"])
+}
+
+func TestEnrichClaimSearchLimits(t *testing.T) {
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	cacheClaims(t, qCache)
+
+	enrich := func(c *Caller) []interface{} {
+		q, err := NewQuery(jsonrpc.NewRequest(MethodClaimSearch), "")
+		require.NoError(t, err)
+		res := &jsonrpc.RPCResponse{Result: map[string]interface{}{"items": searchItems()}}
+		hres, err := postflightHookEnrichClaimSearch(c, &HookContext{Query: q, Response: res})
+		require.NoError(t, err)
+		require.Nil(t, hres)
+		return responseItems(res)
+	}
+	c := NewCaller("", 0)
+	c.Cache = qCache
+
+	config.Override("ClaimSearchEnrichment", map[string]interface{}{"Enabled": true, "MaxItems": 1})
+	items := enrich(c)
+	assert.Equal(t, "new-name", items[0].(map[string]interface{})["name"])
+	assert.NotContains(t, items[1].(map[string]interface{}), "meta")
+
+	config.Override("ClaimSearchEnrichment", map[string]interface{}{"Enabled": false})
+	items = enrich(c)
+	assert.Equal(t, "old-name", items[0].(map[string]interface{})["name"])
+	config.RestoreOverridden()
+
+	c.Scheduler = scheduler.New(1, time.Second)
+	release := c.Scheduler.Acquire(scheduler.PriorityNormal)
+	go func() { c.Scheduler.Acquire(scheduler.PriorityNormal)() }()
+	require.Eventually(t, func() bool { return c.Scheduler.Queued() == 1 }, time.Second, time.Millisecond)
+	skipped := metrics.GetCounterValue(metrics.ProxyClaimSearchEnrichmentSkipped.WithLabelValues("load"))
+	items = enrich(c)
+	assert.Equal(t, "old-name", items[0].(map[string]interface{})["name"])
+	assert.Equal(t, skipped+1, metrics.GetCounterValue(metrics.ProxyClaimSearchEnrichmentSkipped.WithLabelValues("load")))
+	release()
+
+	require.Eventually(t, func() bool { return c.Scheduler.Queued() == 0 }, time.Second, time.Millisecond)
+	items = enrich(c)
+	assert.Equal(t, "new-name", items[0].(map[string]interface{})["name"])
+}
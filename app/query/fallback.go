@@ -0,0 +1,39 @@
+package query
+
+import (
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// fallback returns the static result configured in OutageFallbacks for q when its SDK call has failed
+// and none of the SDK servers is healthy, cause is the reason the call failed. Fallbacks are only ever given
+// for anonymous safe reads, the result is marked as degraded. ok is false if there's no fallback for q.
+func (c *Caller) fallback(q *Query, cause error) (res *jsonrpc.RPCResponse, ok bool) {
+	if c.Router == nil || q.IsAuthenticated() || !q.IsSafeRead() {
+		return nil, false
+	}
+	result, ok := config.GetOutageFallbacks()[q.Method()]
+	if !ok {
+		return nil, false
+	}
+	if c.Router.HealthyServer("") != nil {
+		return nil, false
+	}
+
+	// Configured result is shared between calls so it's copied before being marked
+	fbResult := make(map[string]interface{}, len(result)+1)
+	for k, v := range result {
+		fbResult[k] = v
+	}
+	fbResult[DegradedField] = true
+	res = q.newResponse()
+	res.Result = fbResult
+
+	metrics.ProxyFallbackResponses.WithLabelValues(q.Method()).Inc()
+	logger.WithFields(logrus.Fields{"method": q.Method(), "endpoint": c.endpoint, "cause": cause}).
+		Warn("all sdk servers are down, responding with fallback")
+	return res, true
+}
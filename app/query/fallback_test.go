@@ -0,0 +1,88 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_OutageFallback(t *testing.T) {
+	config.Override("OutageFallbacks", map[string]interface{}{
+		"claim_search": map[string]interface{}{"items": []interface{}{}, "total_pages": 0},
+		"wallet_send":  map[string]interface{}{"txid": ""},
+	})
+	defer config.RestoreOverridden()
+
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "a", Address: "http://down-a"},
+		&models.LbrynetServer{Name: "b", Address: "http://down-b"},
+	)
+	rt.Quarantine("http://down-a")
+
+	call := func(method string, userID int) (*jsonrpc.RPCResponse, error) {
+		c := NewCaller("http://down-a", userID)
+		c.Router = rt
+		return c.Call(jsonrpc.NewRequest(method, map[string]interface{}{"page": 1}))
+	}
+
+	// Another server is still up
+	_, err := call(MethodClaimSearch, 0)
+	assert.Error(t, err)
+
+	rt.Quarantine("http://down-b")
+	before := metrics.GetCounterValue(metrics.ProxyFallbackResponses.WithLabelValues(MethodClaimSearch))
+	res, err := call(MethodClaimSearch, 0)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.True(t, IsDegraded(res))
+	assert.Equal(t, []interface{}{}, res.Result.(map[string]interface{})["items"])
+	assert.Equal(t, 1.0, metrics.GetCounterValue(metrics.ProxyFallbackResponses.WithLabelValues(MethodClaimSearch))-before)
+	// Configured result is not marked
+	assert.NotContains(t, config.GetOutageFallbacks()[MethodClaimSearch], DegradedField)
+
+	_, err = call(MethodResolve, 0)
+	assert.Error(t, err, "methods without a fallback fail")
+	_, err = call(MethodClaimSearch, 1)
+	assert.Error(t, err, "authenticated queries never get a fallback")
+	_, err = call(MethodWalletSend, 1)
+	assert.Error(t, err, "wallet methods never get a fallback")
+}
+
+func TestCaller_OutageFallbackPrefersCache(t *testing.T) {
+	config.Override("OutageFallbacks", map[string]interface{}{
+		"claim_search": map[string]interface{}{"items": []interface{}{}},
+	})
+	defer config.RestoreOverridden()
+
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": [{"name": "cached"}]}, "id": 0}`
+	rt := sdkrouter.NewWithServers(&models.LbrynetServer{Name: "a", Address: srv.URL})
+	qCache, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+
+	call := func() *jsonrpc.RPCResponse {
+		c := NewCaller(srv.URL, 0)
+		c.Router = rt
+		c.Cache = qCache
+		res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page": 1}))
+		require.NoError(t, err)
+		return res
+	}
+	call()
+	qCache.Wait()
+
+	rt.Quarantine(srv.URL)
+	res := call()
+	assert.False(t, IsDegraded(res))
+	assert.Len(t, res.Result.(map[string]interface{})["items"], 1)
+}
@@ -0,0 +1,141 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ErrFanOutTimeout is returned for endpoints which haven't responded within the fan-out timeout.
+var ErrFanOutTimeout = errors.Base("endpoint timed out")
+
+// FanOutResult contains responses from endpoints which have answered a fanned out query
+// and errors for the ones which have failed or timed out.
+// Endpoints which have responded with a JSON-RPC error are considered to have answered.
+type FanOutResult struct {
+	Responses map[string]*jsonrpc.RPCResponse
+	Failed    map[string]error
+}
+
+// Partial returns true if some but not all of the endpoints have failed.
+func (r *FanOutResult) Partial() bool {
+	return len(r.Failed) > 0 && len(r.Responses) > 0
+}
+
+// FailedEndpoints returns addresses of endpoints which haven't answered.
+func (r *FanOutResult) FailedEndpoints() []string {
+	endpoints := make([]string, 0, len(r.Failed))
+	for e := range r.Failed {
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+type fanOutResponse struct {
+	endpoint string
+	res      *jsonrpc.RPCResponse
+	err      error
+}
+
+// FanOut sends an anonymous query to every endpoint concurrently. Each endpoint gets its own timeout
+// so a slow or failing endpoint doesn't affect results collected from the others.
+func FanOut(endpoints []string, req *jsonrpc.RPCRequest, timeout time.Duration) *FanOutResult {
+	return fanOut(endpoints, req, timeout, false)
+}
+
+// FanOutDirect is like FanOut but sends the query to endpoints as is, skipping hooks and the cache,
+// so methods answered locally (like status) get the actual response of each endpoint.
+func FanOutDirect(endpoints []string, req *jsonrpc.RPCRequest, timeout time.Duration) *FanOutResult {
+	return fanOut(endpoints, req, timeout, true)
+}
+
+func fanOut(endpoints []string, req *jsonrpc.RPCRequest, timeout time.Duration, direct bool) *FanOutResult {
+	result := &FanOutResult{
+		Responses: map[string]*jsonrpc.RPCResponse{},
+		Failed:    map[string]error{},
+	}
+	responses := make(chan fanOutResponse, len(endpoints))
+
+	wg := sync.WaitGroup{}
+	for _, e := range endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			responses <- callWithTimeout(endpoint, req, timeout, direct)
+		}(e)
+	}
+	wg.Wait()
+	close(responses)
+
+	for r := range responses {
+		if r.err != nil {
+			result.Failed[r.endpoint] = r.err
+			logger.Log().Infof("fan-out query %v to %v failed: %v", req.Method, r.endpoint, r.err)
+			continue
+		}
+		result.Responses[r.endpoint] = r.res
+	}
+	return result
+}
+
+func callWithTimeout(endpoint string, req *jsonrpc.RPCRequest, timeout time.Duration, direct bool) fanOutResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan fanOutResponse, 1)
+	go func() {
+		// Queries get amended by the caller so each endpoint needs its own copy, params included
+		c := NewCaller(endpoint, 0)
+		c.Context = ctx
+		var (
+			res *jsonrpc.RPCResponse
+			err error
+		)
+		if direct {
+			res, err = c.SendQuery(&Query{Request: copyRequest(req)})
+		} else {
+			res, err = c.Call(copyRequest(req))
+		}
+		done <- fanOutResponse{endpoint, res, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r
+	case <-ctx.Done():
+		return fanOutResponse{endpoint: endpoint, err: errors.Err(fmt.Errorf("%w after %v", ErrFanOutTimeout, timeout))}
+	}
+}
+
+// copyRequest returns a copy of req which shares no maps or slices with it.
+func copyRequest(req *jsonrpc.RPCRequest) *jsonrpc.RPCRequest {
+	reqCopy := *req
+	reqCopy.Params = copyValue(req.Params)
+	return &reqCopy
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, i := range v {
+			c[k] = copyValue(i)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for n, i := range v {
+			c[n] = copyValue(i)
+		}
+		return c
+	case []string:
+		return append([]string(nil), v...)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestFanOut_PartialResults(t *testing.T) {
+	healthy := test.MockHTTPServer(nil)
+	defer healthy.Close()
+	healthy.NextResponse <- `{"jsonrpc": "2.0", "result": {"blockchain_headers": {"height": 1}}, "id": 0}`
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer slow.Close()
+
+	failing := httptest.NewServer(nil)
+	failing.Close()
+
+	start := time.Now()
+	res := FanOut([]string{healthy.URL, slow.URL, failing.URL}, jsonrpc.NewRequest("version"), 200*time.Millisecond)
+	assert.Less(t, time.Since(start).Seconds(), 0.9)
+
+	require.Len(t, res.Responses, 1)
+	assert.NotNil(t, res.Responses[healthy.URL].Result)
+	assert.True(t, res.Partial())
+	assert.ElementsMatch(t, []string{slow.URL, failing.URL}, res.FailedEndpoints())
+	assert.True(t, errors.Is(res.Failed[slow.URL], ErrFanOutTimeout))
+	assert.False(t, errors.Is(res.Failed[failing.URL], ErrFanOutTimeout))
+}
+
+func TestFanOut_AllAnswered(t *testing.T) {
+	srv1 := test.MockHTTPServer(nil)
+	defer srv1.Close()
+	srv2 := test.MockHTTPServer(nil)
+	defer srv2.Close()
+	srv1.NextResponse <- `{"jsonrpc": "2.0", "result": {}, "id": 0}`
+	srv2.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "oops"}, "id": 0}`
+
+	res := FanOut([]string{srv1.URL, srv2.URL}, jsonrpc.NewRequest("version"), time.Second)
+	assert.Len(t, res.Responses, 2)
+	assert.Empty(t, res.Failed)
+	assert.False(t, res.Partial())
+	assert.NotNil(t, res.Responses[srv2.URL].Error)
+}
+
+func TestFanOut_CopiesParams(t *testing.T) {
+	config.Override("ParamDefaults", map[string]interface{}{"claim_search": map[string]interface{}{"page_size": 20}})
+	defer config.RestoreOverridden()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	params := map[string]interface{}{"claim_ids": []interface{}{"abc"}}
+	res := FanOut([]string{srv.URL, srv.URL + "/", srv.URL + "/x"}, jsonrpc.NewRequest(MethodClaimSearch, params), time.Second)
+	assert.Len(t, res.Responses, 3)
+	assert.Equal(t, map[string]interface{}{"claim_ids": []interface{}{"abc"}}, params)
+}
+
+func TestFanOut_CancelsTimedOutCalls(t *testing.T) {
+	cancelled := make(chan struct{}, 1)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	res := FanOut([]string{slow.URL}, jsonrpc.NewRequest("version"), 100*time.Millisecond)
+	assert.True(t, errors.Is(res.Failed[slow.URL], ErrFanOutTimeout))
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sdk request has not been cancelled")
+	}
+}
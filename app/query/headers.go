@@ -0,0 +1,45 @@
+package query
+
+import (
+	"net/http"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// sensitiveHeaders carry client credentials, they are forwarded to the SDK
+// only when listed in ForwardedSensitiveHeaders config on top of ForwardedHeaders.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	wallet.TokenHeader,
+	auth.AdminTokenHeader,
+	auth.ServiceSignatureHeader,
+}
+
+// ForwardedHeaders returns headers of the client request which should be passed on to the SDK
+// according to ForwardedHeaders config.
+func ForwardedHeaders(r *http.Request) map[string]string {
+	allowedSensitive := map[string]bool{}
+	for _, h := range config.GetForwardedSensitiveHeaders() {
+		allowedSensitive[http.CanonicalHeaderKey(h)] = true
+	}
+	sensitive := map[string]bool{}
+	for _, h := range sensitiveHeaders {
+		sensitive[http.CanonicalHeaderKey(h)] = true
+	}
+
+	headers := map[string]string{}
+	for _, h := range config.GetForwardedHeaders() {
+		h = http.CanonicalHeaderKey(h)
+		if sensitive[h] && !allowedSensitive[h] {
+			continue
+		}
+		if v := r.Header.Get(h); v != "" {
+			headers[h] = v
+		}
+	}
+	return headers
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestForwardedHeaders(t *testing.T) {
+	config.Override("ForwardedHeaders", []string{"x-request-id", "X-Client-Version", wallet.TokenHeader, "Cookie", "X-Missing"})
+	defer config.RestoreOverridden()
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("X-Request-Id", "abc")
+	r.Header.Set("X-Client-Version", "1.2.3")
+	r.Header.Set("X-Other", "no")
+	r.Header.Set(wallet.TokenHeader, "secret")
+	r.Header.Set("Cookie", "session=secret")
+
+	assert.Equal(t, map[string]string{"X-Request-Id": "abc", "X-Client-Version": "1.2.3"}, ForwardedHeaders(r))
+
+	config.Override("ForwardedSensitiveHeaders", []string{"cookie"})
+	assert.Equal(t,
+		map[string]string{"X-Request-Id": "abc", "X-Client-Version": "1.2.3", "Cookie": "session=secret"},
+		ForwardedHeaders(r))
+}
+
+func TestCaller_SendsHeaders(t *testing.T) {
+	config.Override("SDKUserAgent", "lbrytv-test")
+	defer config.RestoreOverridden()
+
+	received := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+	c.Headers = map[string]string{"X-Request-Id": "abc"}
+	_, err := c.Call(jsonrpc.NewRequest("version"))
+	require.NoError(t, err)
+	h := <-received
+	assert.Equal(t, "abc", h.Get("X-Request-Id"))
+	assert.Equal(t, "lbrytv-test", h.Get("User-Agent"))
+	assert.Empty(t, h.Get(wallet.TokenHeader))
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ParamPageSize is the number of results per page of list methods.
+const ParamPageSize = "page_size"
+
+// WarningTypePageSizeLimited is the type of warnings returned when the requested page_size has been reduced.
+const WarningTypePageSizeLimited = "page_size_limited"
+
+// preflightHookMaxPageSize reduces page_size exceeding the maximum configured in MaxPageSizes for the query method,
+// keeping other params intact. Clients get smaller pages and can request the following ones as usual.
+// Queries without page_size get the SDK default and are left alone.
+func preflightHookMaxPageSize(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	max, ok := config.GetMaxPageSizes()[q.Method()]
+	if !ok || max <= 0 {
+		return nil, nil
+	}
+	params := q.ParamsAsMap()
+	raw, ok := params[ParamPageSize]
+	if !ok {
+		return nil, nil
+	}
+	requested := intValue(raw)
+	if requested <= max {
+		return nil, nil
+	}
+
+	params[ParamPageSize] = max
+	metrics.ProxyPageSizeLimitedCount.WithLabelValues(q.Method()).Inc()
+	if config.ShouldWarnOnPageSizeLimit() {
+		q.AddWarning(Warning{
+			Type:    WarningTypePageSizeLimited,
+			Message: fmt.Sprintf("page_size %v exceeds the maximum, %v results per page returned", requested, max),
+		})
+	}
+	return nil, nil
+}
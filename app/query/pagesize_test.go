@@ -0,0 +1,94 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_MaxPageSize(t *testing.T) {
+	config.Override("MaxPageSizes", map[string]interface{}{"claim_search": 50})
+	defer config.RestoreOverridden()
+
+	received := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params, _ := req.Params.(map[string]interface{})
+		received <- params
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name     string
+		params   map[string]interface{}
+		expected map[string]interface{}
+		warned   bool
+	}{
+		{"over limit", map[string]interface{}{"page_size": 1000, "page": 3, "channel": "@a"},
+			map[string]interface{}{"page_size": 50.0, "page": 3.0, "channel": "@a"}, true},
+		{"at limit", map[string]interface{}{"page_size": 50}, map[string]interface{}{"page_size": 50.0}, false},
+		{"under limit", map[string]interface{}{"page_size": 5}, map[string]interface{}{"page_size": 5.0}, false},
+		{"unset", map[string]interface{}{"page": 2}, map[string]interface{}{"page": 2.0}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch, c.params))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, <-received)
+			warnings := ResponseWarnings(res)
+			if c.warned {
+				require.Len(t, warnings, 1)
+				assert.Equal(t, WarningTypePageSizeLimited, warnings[0].Type)
+				assert.Equal(t, "page_size 1000 exceeds the maximum, 50 results per page returned", warnings[0].Message)
+			} else {
+				assert.Empty(t, warnings)
+			}
+		})
+	}
+
+	_, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("comment_list", map[string]interface{}{"page_size": 1000}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 1000.0}, <-received)
+
+	config.Override("MaxPageSizeWarning", false)
+	res, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": 100}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"page_size": 50.0}, <-received)
+	assert.Empty(t, ResponseWarnings(res))
+}
+
+func TestCaller_MaxPageSizeWarningNotCached(t *testing.T) {
+	config.Override("MaxPageSizes", map[string]interface{}{"claim_search": 50})
+	defer config.RestoreOverridden()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`)
+	}))
+	defer srv.Close()
+	c, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+
+	call := func(pageSize int) *jsonrpc.RPCResponse {
+		caller := NewCaller(srv.URL, 0)
+		caller.Cache = c
+		res, err := caller.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"page_size": pageSize}))
+		require.NoError(t, err)
+		return res
+	}
+	assert.Len(t, ResponseWarnings(call(500)), 1)
+	c.Wait()
+	// Same query after clamping, served from the cache
+	assert.Empty(t, ResponseWarnings(call(50)))
+	assert.Len(t, ResponseWarnings(call(500)), 1)
+}
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ErrNotPaginated is returned when a list method response doesn't look like a page of results.
+var ErrNotPaginated = errors.Base("response is not paginated")
+
+// Pages contains items collected from all pages of a list method.
+type Pages struct {
+	Items []interface{}
+	// TotalItems is the number of items reported by the SDK, zero if it doesn't report totals.
+	TotalItems int
+	// Fetched is the number of pages fetched.
+	Fetched int
+	// Truncated is true if some results have been left out due to pagination limits.
+	Truncated bool
+}
+
+type page struct {
+	items      []interface{}
+	totalPages int
+	totalItems int
+}
+
+// FetchAllPages collects items from all pages of a list method like claim_search or txo_list for internal use.
+// When the SDK reports totals, pages after the first one are fetched concurrently, otherwise they're fetched
+// one by one until a short page comes back. Zero MaxPages or MaxItems don't limit results.
+// Pages are not cached and any failed page fails the whole fetch.
+func FetchAllPages(endpoint string, userID int, method string, params map[string]interface{}, limits config.PaginationLimits) (*Pages, error) {
+	if limits.PageSize <= 0 {
+		return nil, errors.Err("page size must be positive")
+	}
+	maxPages := limits.MaxPages
+	if limits.MaxItems > 0 {
+		if byItems := (limits.MaxItems + limits.PageSize - 1) / limits.PageSize; maxPages <= 0 || byItems < maxPages {
+			maxPages = byItems
+		}
+	}
+
+	first, err := fetchPage(endpoint, userID, method, params, 1, limits.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &Pages{TotalItems: first.totalItems}
+	pages := []*page{first}
+
+	if first.totalPages > 0 {
+		last := first.totalPages
+		if maxPages > 0 && last > maxPages {
+			last = maxPages
+			result.Truncated = true
+		}
+		rest, err := fetchPages(endpoint, userID, method, params, 2, last, limits)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, rest...)
+	} else {
+		for n := 2; len(pages[len(pages)-1].items) >= limits.PageSize; n++ {
+			if maxPages > 0 && n > maxPages {
+				result.Truncated = true
+				break
+			}
+			p, err := fetchPage(endpoint, userID, method, params, n, limits.PageSize)
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, p)
+		}
+	}
+
+	result.Fetched = len(pages)
+	for _, p := range pages {
+		result.Items = append(result.Items, p.items...)
+	}
+	if limits.MaxItems > 0 && len(result.Items) > limits.MaxItems {
+		result.Items = result.Items[:limits.MaxItems]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// fetchPages fetches pages from first to last, at most limits.Concurrency at a time, returning them in order.
+func fetchPages(endpoint string, userID int, method string, params map[string]interface{}, first, last int, limits config.PaginationLimits) ([]*page, error) {
+	if last < first {
+		return nil, nil
+	}
+	concurrency := limits.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	pages := make([]*page, last-first+1)
+	errs := make([]error, len(pages))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i := range pages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			pages[i], errs[i] = fetchPage(endpoint, userID, method, params, first+i, limits.PageSize)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+func fetchPage(endpoint string, userID int, method string, params map[string]interface{}, n, pageSize int) (*page, error) {
+	// Every page gets its own copy of params as they're amended by the caller
+	pageParams, _ := copyValue(params).(map[string]interface{})
+	if pageParams == nil {
+		pageParams = map[string]interface{}{}
+	}
+	pageParams["page"] = n
+	pageParams["page_size"] = pageSize
+
+	res, err := NewCaller(endpoint, userID).Call(jsonrpc.NewRequest(method, pageParams))
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.Err("%v page %v failed: %v", method, n, res.Error.Message)
+	}
+	result, ok := res.Result.(map[string]interface{})
+	if !ok {
+		return nil, errors.Err(ErrNotPaginated)
+	}
+	items, ok := result["items"].([]interface{})
+	if !ok {
+		return nil, errors.Err(ErrNotPaginated)
+	}
+	return &page{items: items, totalPages: intValue(result["total_pages"]), totalItems: intValue(result["total_items"])}, nil
+}
+
+// intValue returns v as int if it's a number decoded from JSON or an int, zero otherwise.
+func intValue(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	}
+	return 0
+}
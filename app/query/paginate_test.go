@@ -0,0 +1,153 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+// pagedServer serves total items split into pages, reporting totals unless no_totals is set.
+type pagedServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	requested   []int
+	inFlight    int
+	maxInFlight int
+	failPage    int
+}
+
+func newPagedServer(t *testing.T, total int) *pagedServer {
+	s := &pagedServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params := req.Params.(map[string]interface{})
+		n, size := intValue(params["page"]), intValue(params["page_size"])
+
+		s.mu.Lock()
+		s.requested = append(s.requested, n)
+		s.inFlight++
+		if s.inFlight > s.maxInFlight {
+			s.maxInFlight = s.inFlight
+		}
+		s.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		defer func() {
+			s.mu.Lock()
+			s.inFlight--
+			s.mu.Unlock()
+		}()
+
+		if n == s.failPage {
+			json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Error: &jsonrpc.RPCError{Code: -32500, Message: "boom"}})
+			return
+		}
+		items := []interface{}{}
+		for i := (n - 1) * size; i < n*size && i < total; i++ {
+			items = append(items, fmt.Sprintf("item%v", i))
+		}
+		result := map[string]interface{}{"items": items, "page": n, "page_size": size}
+		if params["no_totals"] != true {
+			result["total_items"] = total
+			result["total_pages"] = (total + size - 1) / size
+		}
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: result})
+	}))
+	return s
+}
+
+func sequence(n int) []interface{} {
+	s := make([]interface{}, n)
+	for i := range s {
+		s[i] = fmt.Sprintf("item%v", i)
+	}
+	return s
+}
+
+func TestFetchAllPages(t *testing.T) {
+	srv := newPagedServer(t, 23)
+	defer srv.Close()
+
+	limits := config.PaginationLimits{PageSize: 5, MaxPages: 10, MaxItems: 100, Concurrency: 2}
+	params := map[string]interface{}{"channel_ids": []interface{}{"abc"}}
+	pages, err := FetchAllPages(srv.URL, 0, MethodClaimSearch, params, limits)
+	require.NoError(t, err)
+	assert.Equal(t, sequence(23), pages.Items)
+	assert.Equal(t, 23, pages.TotalItems)
+	assert.Equal(t, 5, pages.Fetched)
+	assert.False(t, pages.Truncated)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, srv.requested)
+	assert.LessOrEqual(t, srv.maxInFlight, 2)
+	assert.Equal(t, map[string]interface{}{"channel_ids": []interface{}{"abc"}}, params)
+}
+
+func TestFetchAllPages_NoTotals(t *testing.T) {
+	srv := newPagedServer(t, 15)
+	defer srv.Close()
+
+	limits := config.PaginationLimits{PageSize: 5, MaxPages: 10, MaxItems: 100, Concurrency: 4}
+	pages, err := FetchAllPages(srv.URL, 0, MethodClaimSearch, map[string]interface{}{"no_totals": true}, limits)
+	require.NoError(t, err)
+	assert.Equal(t, sequence(15), pages.Items)
+	assert.Equal(t, 0, pages.TotalItems)
+	// The fourth page comes back empty and ends the fetch
+	assert.Equal(t, []int{1, 2, 3, 4}, srv.requested)
+	assert.Equal(t, 1, srv.maxInFlight)
+	assert.False(t, pages.Truncated)
+}
+
+func TestFetchAllPages_Caps(t *testing.T) {
+	srv := newPagedServer(t, 100)
+	defer srv.Close()
+
+	pages, err := FetchAllPages(srv.URL, 0, MethodClaimSearch, nil, config.PaginationLimits{PageSize: 10, MaxPages: 3, MaxItems: 1000, Concurrency: 4})
+	require.NoError(t, err)
+	assert.Equal(t, sequence(30), pages.Items)
+	assert.Equal(t, 100, pages.TotalItems)
+	assert.True(t, pages.Truncated)
+
+	srv.requested = nil
+	pages, err = FetchAllPages(srv.URL, 0, MethodClaimSearch, nil, config.PaginationLimits{PageSize: 10, MaxPages: 100, MaxItems: 25, Concurrency: 4})
+	require.NoError(t, err)
+	assert.Equal(t, sequence(25), pages.Items)
+	assert.True(t, pages.Truncated)
+	assert.ElementsMatch(t, []int{1, 2, 3}, srv.requested)
+
+	srv.requested = nil
+	pages, err = FetchAllPages(srv.URL, 0, MethodClaimSearch, map[string]interface{}{"no_totals": true}, config.PaginationLimits{PageSize: 10, MaxItems: 35})
+	require.NoError(t, err)
+	assert.Equal(t, sequence(35), pages.Items)
+	assert.True(t, pages.Truncated)
+	assert.Equal(t, []int{1, 2, 3, 4}, srv.requested)
+}
+
+func TestFetchAllPages_Errors(t *testing.T) {
+	srv := newPagedServer(t, 50)
+	defer srv.Close()
+	srv.failPage = 3
+
+	_, err := FetchAllPages(srv.URL, 0, MethodClaimSearch, nil, config.PaginationLimits{PageSize: 10, Concurrency: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claim_search page 3 failed: boom")
+
+	_, err = FetchAllPages(srv.URL, 0, MethodClaimSearch, nil, config.PaginationLimits{})
+	assert.Error(t, err)
+
+	notPaged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"available": "1.0"}})
+	}))
+	defer notPaged.Close()
+	_, err = FetchAllPages(notPaged.URL, 0, MethodClaimSearch, nil, config.PaginationLimits{PageSize: 10})
+	assert.True(t, errors.Is(err, ErrNotPaginated))
+}
@@ -115,12 +115,12 @@ func preflightHookGet(caller *Caller, hctx *HookContext) (*jsonrpc.RPCResponse,
 		}
 		contentURL = fmt.Sprintf(
 			"%v%s/%s/%s/%s",
-			config.Config.Viper.GetString("PaidContentURL"), claim.Name, claim.ClaimID, sdHash, token)
+			config.Config.Viper().GetString("PaidContentURL"), claim.Name, claim.ClaimID, sdHash, token)
 		responseResult[ParamPurchaseReceipt] = claim.PurchaseReceipt
 	} else {
 		contentURL = fmt.Sprintf(
 			"%v%s/%s/%s",
-			config.Config.Viper.GetString("FreeContentURL"), claim.Name, claim.ClaimID, sdHash)
+			config.Config.Viper().GetString("FreeContentURL"), claim.Name, claim.ClaimID, sdHash)
 	}
 
 	responseResult[ParamStreamingUrl] = contentURL
@@ -0,0 +1,127 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ParamFields lists fields of list items the client wants returned, other fields are removed from the response.
+// Nested fields are separated by dots, e.g. "value.title". It's handled by the proxy and not sent to the SDK.
+const ParamFields = "fields"
+
+const projectionValueKey = "projection"
+
+// projectedMethods are methods listing wallet data of the user which support ParamFields.
+var projectedMethods = []string{MethodTransactionList, MethodClaimList}
+
+// preflightHookProjection takes ParamFields out of the query, passing requested fields on to postflightHookProjection.
+// Only queries on users' own wallets are projected, ParamFields is dropped from the rest.
+func preflightHookProjection(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	params := q.ParamsAsMap()
+	raw, ok := params[ParamFields]
+	if !ok {
+		return nil, nil
+	}
+	delete(params, ParamFields)
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return invalidFieldsResponse(q), nil
+	}
+	fields := make([]string, 0, len(list))
+	for _, f := range list {
+		s, ok := f.(string)
+		if !ok || s == "" {
+			return invalidFieldsResponse(q), nil
+		}
+		fields = append(fields, s)
+	}
+	if q.IsAuthenticated() && !q.IsCacheable() && len(fields) > 0 {
+		hctx.Set(projectionValueKey, fields)
+	}
+	return nil, nil
+}
+
+func invalidFieldsResponse(q *Query) *jsonrpc.RPCResponse {
+	err := rpcerrors.NewInvalidParamsError(nil)
+	res := q.newResponse()
+	res.Error = &jsonrpc.RPCError{Code: err.Code(), Message: ParamFields + " must be a list of field names"}
+	return res
+}
+
+// postflightHookProjection removes fields the client hasn't asked for from items of a paginated result.
+// Fields which items don't have are ignored.
+func postflightHookProjection(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	fields, ok := hctx.Value(projectionValueKey).([]string)
+	if !ok {
+		return nil, nil
+	}
+	r := hctx.Response
+	if r == nil || r.Error != nil {
+		return nil, nil
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	items, ok := result["items"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	paths := make([][]string, len(fields))
+	for i, f := range fields {
+		paths[i] = strings.Split(f, ".")
+	}
+	before := jsonSize(items)
+	for i, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			items[i] = project(m, paths)
+		}
+	}
+	if saved := before - jsonSize(items); saved > 0 {
+		metrics.ProxyProjectionBytesSaved.WithLabelValues(hctx.Query.Method()).Add(float64(saved))
+	}
+	return nil, nil
+}
+
+// project returns a copy of m with only the fields at paths.
+func project(m map[string]interface{}, paths [][]string) map[string]interface{} {
+	nested := map[string][][]string{}
+	projected := map[string]interface{}{}
+	for _, p := range paths {
+		v, ok := m[p[0]]
+		if !ok {
+			continue
+		}
+		if len(p) == 1 {
+			projected[p[0]] = v
+			continue
+		}
+		nested[p[0]] = append(nested[p[0]], p[1:])
+	}
+	for k, ps := range nested {
+		if _, ok := projected[k]; ok {
+			// The whole field has been requested as well
+			continue
+		}
+		if sub, ok := m[k].(map[string]interface{}); ok {
+			projected[k] = project(sub, ps)
+		}
+	}
+	return projected
+}
+
+func jsonSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
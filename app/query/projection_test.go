@@ -0,0 +1,76 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestProject(t *testing.T) {
+	item := map[string]interface{}{
+		"txid":   "abc",
+		"amount": "1.0",
+		"value":  map[string]interface{}{"title": "t", "description": "d"},
+		"meta":   "m",
+	}
+	assert.Equal(t,
+		map[string]interface{}{"txid": "abc", "value": map[string]interface{}{"title": "t"}},
+		project(item, [][]string{{"txid"}, {"value", "title"}, {"unknown"}, {"meta", "nested"}, {"value", "missing"}}),
+	)
+	assert.Equal(t,
+		map[string]interface{}{"value": map[string]interface{}{"title": "t", "description": "d"}},
+		project(item, [][]string{{"value", "title"}, {"value"}}),
+	)
+	assert.Len(t, item, 4)
+}
+
+func TestCaller_Projection(t *testing.T) {
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		sent = string(b)
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "id": 0, "result": {"page": 1, "total_pages": 1, "items": [
+			{"txid": "a", "amount": "1.0", "value": {"title": "one", "description": "long one"}},
+			{"txid": "b", "amount": "2.0"},
+			"odd"
+		]}}`)
+	}))
+	defer srv.Close()
+
+	counter := metrics.ProxyProjectionBytesSaved.WithLabelValues(MethodTransactionList)
+	before := metrics.GetCounterValue(counter)
+
+	res, err := NewCaller(srv.URL, 1).Call(jsonrpc.NewRequest(MethodTransactionList, map[string]interface{}{
+		"page": 1, ParamFields: []interface{}{"txid", "value.title", "nonexistent"},
+	}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.NotContains(t, sent, ParamFields)
+	result := res.Result.(map[string]interface{})
+	assert.Equal(t, json.Number("1"), result["page"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"txid": "a", "value": map[string]interface{}{"title": "one"}},
+		map[string]interface{}{"txid": "b"},
+		"odd",
+	}, result["items"])
+	assert.Greater(t, metrics.GetCounterValue(counter), before)
+
+	// Responses are not projected without the param
+	res, err = NewCaller(srv.URL, 1).Call(jsonrpc.NewRequest(MethodTransactionList, map[string]interface{}{"page": 1}))
+	require.NoError(t, err)
+	assert.Len(t, res.Result.(map[string]interface{})["items"].([]interface{})[0], 3)
+
+	res, err = NewCaller(srv.URL, 1).Call(jsonrpc.NewRequest(MethodClaimList, map[string]interface{}{ParamFields: "txid"}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "fields must be a list of field names", res.Error.Message)
+}
@@ -1,10 +1,13 @@
 package query
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/internal/errors"
 	"github.com/lbryio/lbrytv/internal/monitor"
 
@@ -17,6 +20,14 @@ var logger = monitor.NewModuleLogger("query")
 type Query struct {
 	Request  *jsonrpc.RPCRequest
 	WalletID string
+
+	cacheSalt []string
+	// originalURLs are resolve URLs supplied by the client, by their canonical forms.
+	originalURLs map[string][]string
+	// warnings are added to the response after it has been cached as they concern this particular query.
+	warnings []Warning
+	// values are stored by hooks for the hooks called after them.
+	values map[string]interface{}
 }
 
 // NewQuery initializes Query object with JSON-RPC request.
@@ -57,6 +68,35 @@ func NewQuery(req *jsonrpc.RPCRequest, walletID string) (*Query, error) {
 	return q, nil
 }
 
+// AddCacheSalt makes the query response cached separately from the same query with a different salt or none.
+// Preflight hooks changing the response, e.g. depending on experiment variant, should salt the query with the variant.
+func (q *Query) AddCacheSalt(salt string) {
+	q.cacheSalt = append(q.cacheSalt, salt)
+}
+
+// CacheSalt returns the salts added to the query joined together, or an empty string if there are none.
+func (q *Query) CacheSalt() string {
+	return strings.Join(q.cacheSalt, ",")
+}
+
+// AddWarning makes the warning returned to the client along with a successful result.
+// It's not cached with the response, so hooks can warn about things specific to the client's query.
+func (q *Query) AddWarning(w Warning) {
+	q.warnings = append(q.warnings, w)
+}
+
+func (q *Query) setValue(key string, value interface{}) {
+	if q.values == nil {
+		q.values = map[string]interface{}{}
+	}
+	q.values[key] = value
+}
+
+// clientResponse prepares a response, possibly shared through the cache, for sending back to the client.
+func (q *Query) clientResponse(r *jsonrpc.RPCResponse) *jsonrpc.RPCResponse {
+	return WithWarnings(q.restoreURLs(r), q.warnings...)
+}
+
 // Method is a shortcut for query method.
 func (q *Query) Method() string {
 	return q.Request.Method
@@ -72,6 +112,17 @@ func (q *Query) IsAuthenticated() bool {
 	return q.WalletID != ""
 }
 
+// IsSafeRead returns true if the query doesn't change anything and can be served by any SDK server.
+// Queries performed on behalf of users are never safe reads as their wallets are loaded on a specific server.
+func (q *Query) IsSafeRead() bool {
+	return !q.IsAuthenticated() && methodInList(q.Method(), safeReadMethods)
+}
+
+// IsSafeReadMethod returns true if method doesn't change anything, so identical calls to it can share a response.
+func IsSafeReadMethod(method string) bool {
+	return methodInList(method, safeReadMethods)
+}
+
 // ParamsAsMap returns query params converted to a plain map.
 // Warning: will not copy the map so not concurrency-friendly.
 func (q *Query) ParamsAsMap() map[string]interface{} {
@@ -110,6 +161,46 @@ func MethodAcceptsWallet(method string) bool {
 	return methodInList(method, walletSpecificMethods)
 }
 
+// SupportedMethods returns sorted names of all methods which can be called through the proxy.
+func SupportedMethods() []string {
+	seen := map[string]bool{}
+	methods := []string{}
+	for _, list := range [][]string{relaxedMethods, walletSpecificMethods} {
+		for _, m := range list {
+			if !seen[m] {
+				seen[m] = true
+				methods = append(methods, m)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// IsSupportedMethod returns true if method can be called through the proxy.
+func IsSupportedMethod(method string) bool {
+	return methodInList(method, relaxedMethods) || methodInList(method, walletSpecificMethods)
+}
+
+// IsWalletMutation returns true for methods which spend funds or change claims in user's wallet.
+func IsWalletMutation(method string) bool {
+	return methodInList(method, walletMutationMethods)
+}
+
+// AffinityKey identifies the resource a request is about, for routing requests for the same resource
+// to the same SDK server: the wallet for user requests, requested URLs for resolve and all params otherwise.
+func AffinityKey(req *jsonrpc.RPCRequest, userID int) string {
+	if userID != 0 {
+		return sdkrouter.WalletID(userID)
+	}
+	params, _ := req.Params.(map[string]interface{})
+	if urls, ok := params[ParamUrls]; ok && req.Method == MethodResolve {
+		return fmt.Sprintf("%v", urls)
+	}
+	enc, _ := json.Marshal(req.Params)
+	return req.Method + "|" + string(enc)
+}
+
 func methodInList(method string, checkMethods []string) bool {
 	for _, m := range checkMethods {
 		if m == method {
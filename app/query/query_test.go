@@ -3,6 +3,8 @@ package query
 import (
 	"testing"
 
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
 	"github.com/lbryio/lbrytv/internal/errors"
 
 	"github.com/stretchr/testify/assert"
@@ -73,3 +75,35 @@ func TestMethodAcceptsWallet(t *testing.T) {
 		assert.True(t, MethodAcceptsWallet(m), m)
 	}
 }
+
+func TestQueryIsCacheable(t *testing.T) {
+	for _, m := range []string{MethodResolve, MethodClaimSearch} {
+		q, err := NewQuery(jsonrpc.NewRequest(m), "")
+		require.NoError(t, err)
+		assert.True(t, q.IsCacheable(), m)
+	}
+	q, err := NewQuery(jsonrpc.NewRequest("comment_list"), "")
+	require.NoError(t, err)
+	assert.False(t, q.IsCacheable())
+
+	config.Override("CacheableMethods", map[string]string{"comment_list": "30s", "claim_search": "invalid"})
+	defer config.RestoreOverridden()
+
+	assert.True(t, q.IsCacheable())
+	for _, m := range []string{MethodResolve, MethodClaimSearch} {
+		q, err := NewQuery(jsonrpc.NewRequest(m), "")
+		require.NoError(t, err)
+		assert.False(t, q.IsCacheable(), m)
+	}
+}
+
+func TestAffinityKey(t *testing.T) {
+	resolve := jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": []string{"lbry://one"}})
+	assert.Equal(t, AffinityKey(resolve, 0), AffinityKey(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": []string{"lbry://one"}, "include_purchase_receipt": true}), 0))
+	assert.NotEqual(t, AffinityKey(resolve, 0), AffinityKey(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": []string{"lbry://two"}}), 0))
+	assert.Equal(t, sdkrouter.WalletID(42), AffinityKey(resolve, 42))
+
+	search := jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one"})
+	assert.Equal(t, AffinityKey(search, 0), AffinityKey(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "one"}), 0))
+	assert.NotEqual(t, AffinityKey(search, 0), AffinityKey(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "two"}), 0))
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+// Reasons of SDK query retries, see waitBeforeRetry.
+const (
+	retryReasonRestart         = "restart"
+	retryReasonWalletNotLoaded = "wallet_not_loaded"
+)
+
+// sleep is replaced in tests to avoid waiting for retries.
+var sleep = time.Sleep
+
+// retryBackoff returns how long to wait before retry attempt (counting from zero): a random duration up to base
+// doubled with every attempt, capped at max. Randomizing the whole delay ("full jitter") keeps clients which have
+// failed at the same time, e.g. during an SDK outage, from retrying in lockstep and overwhelming it again.
+func retryBackoff(base, max time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt && (max <= 0 || d < max); i++ {
+		d *= 2
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// waitBeforeRetry counts the retry of a query and waits before it according to SDKRetryBackoff config.
+func waitBeforeRetry(method, reason string, attempt int) {
+	metrics.ProxySDKRetries.WithLabelValues(method, reason, strconv.Itoa(attempt+1)).Inc()
+	sleep(retryBackoff(config.GetSDKRetryBackoff(), config.GetSDKRetryMaxBackoff(), attempt))
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	bounds := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, bound := range bounds {
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 100; i++ {
+			d := retryBackoff(base, max, attempt)
+			assert.GreaterOrEqual(t, int64(d), int64(0))
+			assert.LessOrEqual(t, int64(d), int64(bound), "attempt %v", attempt)
+			seen[d] = true
+		}
+		assert.Greater(t, len(seen), 50, "attempt %v backoff is not randomized", attempt)
+	}
+
+	// Huge attempt numbers don't overflow
+	assert.LessOrEqual(t, int64(retryBackoff(base, max, 100)), int64(max))
+	assert.Equal(t, time.Duration(0), retryBackoff(0, max, 3))
+	assert.LessOrEqual(t, int64(retryBackoff(base, 0, 3)), int64(800*time.Millisecond))
+}
+
+func TestCaller_RetryWaitsWithBackoff(t *testing.T) {
+	config.Override("SDKRetryBackoff", "1s")
+	config.Override("SDKRetryMaxBackoff", "1s")
+	defer config.RestoreOverridden()
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	down := httptest.NewServer(nil)
+	down.Close()
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "down", Address: down.URL},
+		&models.LbrynetServer{Name: "up", Address: srv.URL},
+	)
+	retries := testutil.ToFloat64(metrics.ProxySDKRetries.WithLabelValues(MethodClaimSearch, retryReasonRestart, "1"))
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": []}, "id": 0}`
+	c := NewCaller(down.URL, 0)
+	c.Router = rt
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "x"}))
+	require.NoError(t, err)
+
+	require.Len(t, waits, 1)
+	assert.LessOrEqual(t, int64(waits[0]), int64(time.Second))
+	assert.Equal(t, retries+1, testutil.ToFloat64(metrics.ProxySDKRetries.WithLabelValues(MethodClaimSearch, retryReasonRestart, "1")))
+}
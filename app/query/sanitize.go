@@ -0,0 +1,180 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// Actions on params not allowed by sanitization rules (see ParamSanitization config).
+const (
+	SanitizeReject = "reject"
+	SanitizeDrop   = "drop"
+)
+
+// Actions recorded in sanitization metrics.
+const (
+	sanitizedRejected  = "rejected"
+	sanitizedDropped   = "dropped"
+	sanitizedStripped  = "stripped"
+	sanitizedTruncated = "truncated"
+)
+
+// patterns keeps compiled sanitization patterns as rules are read from config on every query.
+var patterns sync.Map
+
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(expr, re)
+	return re, nil
+}
+
+// preflightHookSanitizeParams checks params of the query against rules configured in ParamSanitization for its method.
+// It runs before defaults are applied so only values supplied by the client are checked.
+func preflightHookSanitizeParams(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	rules, ok := config.GetParamSanitization()[q.Method()]
+	params := q.ParamsAsMap()
+	if !ok || len(rules) == 0 || params == nil {
+		return nil, nil
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v, ok := params[name]
+		if !ok {
+			continue
+		}
+		rule := rules[name]
+		sanitized, actions, err := sanitizeParam(v, rule)
+		if err != nil {
+			logger.Log().Errorf("invalid sanitization rule for %v of %v: %v", name, q.Method(), err)
+			continue
+		}
+		for _, a := range actions {
+			metrics.ProxyParamsSanitized.WithLabelValues(q.Method(), name, a).Inc()
+		}
+		if len(actions) == 0 {
+			continue
+		}
+		logger.WithFields(logrus.Fields{
+			"method":     q.Method(),
+			"param":      name,
+			"actions":    actions,
+			"request_id": hctx.RequestID,
+		}).Info("param sanitized")
+		if actions[len(actions)-1] == sanitizedRejected {
+			return invalidParamResponse(q, name), nil
+		}
+		if sanitized == nil {
+			delete(params, name)
+		} else {
+			params[name] = sanitized
+		}
+	}
+	return nil, nil
+}
+
+func invalidParamResponse(q *Query, name string) *jsonrpc.RPCResponse {
+	res := q.newResponse()
+	res.Error = &jsonrpc.RPCError{
+		Code:    rpcerrors.NewInvalidParamsError(nil).Code(),
+		Message: fmt.Sprintf("%v contains values which are not allowed", name),
+	}
+	return res
+}
+
+// sanitizeParam applies rule to a param value, returning the value to send to the SDK and actions taken.
+// Values of lists are checked one by one. The value is nil if the param is to be removed, which happens
+// when no values are left after dropping those not allowed. Rejection is always the last action, the value is not to be used then.
+func sanitizeParam(v interface{}, rule config.ParamRule) (interface{}, []string, error) {
+	var allow, strip *regexp.Regexp
+	var err error
+	if rule.Allow != "" {
+		if allow, err = compilePattern("^(?:" + rule.Allow + ")$"); err != nil {
+			return nil, nil, err
+		}
+	}
+	if rule.Strip != "" {
+		if strip, err = compilePattern(rule.Strip); err != nil {
+			return nil, nil, err
+		}
+	}
+	reject := rule.Action != SanitizeDrop
+
+	var actions []string
+	var list []interface{}
+	isList := true
+	switch l := v.(type) {
+	case []interface{}:
+		list = l
+	case []string:
+		for _, i := range l {
+			list = append(list, i)
+		}
+	default:
+		list = []interface{}{v}
+		isList = false
+	}
+	clean := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		s, isString := item.(string)
+		if isString && strip != nil {
+			if stripped := strip.ReplaceAllString(s, ""); stripped != s {
+				s = stripped
+				item = s
+				actions = appendAction(actions, sanitizedStripped)
+			}
+		}
+		if allow != nil && (!isString || !allow.MatchString(s)) {
+			if reject {
+				return nil, append(actions, sanitizedRejected), nil
+			}
+			actions = appendAction(actions, sanitizedDropped)
+			continue
+		}
+		clean = append(clean, item)
+	}
+	if isList && rule.MaxItems > 0 && len(clean) > rule.MaxItems {
+		if reject {
+			return nil, append(actions, sanitizedRejected), nil
+		}
+		clean = clean[:rule.MaxItems]
+		actions = appendAction(actions, sanitizedTruncated)
+	}
+
+	if len(clean) == 0 {
+		return nil, actions, nil
+	}
+	if isList {
+		return clean, actions, nil
+	}
+	return clean[0], actions, nil
+}
+
+func appendAction(actions []string, a string) []string {
+	for _, x := range actions {
+		if x == a {
+			return actions
+		}
+	}
+	return append(actions, a)
+}
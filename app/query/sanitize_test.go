@@ -0,0 +1,93 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestSanitizeParam(t *testing.T) {
+	tags := config.ParamRule{Allow: `[a-z0-9 -]{1,64}`, MaxItems: 3, Action: SanitizeDrop}
+	cases := []struct {
+		name     string
+		value    interface{}
+		rule     config.ParamRule
+		expected interface{}
+		actions  []string
+	}{
+		{"allowed", "lbry", config.ParamRule{Allow: `[a-z]+`}, "lbry", nil},
+		{"wildcard rejected", "*", config.ParamRule{Allow: `[^*%]{1,200}`}, nil, []string{sanitizedRejected}},
+		{"partial match rejected", "ok' OR 1=1", config.ParamRule{Allow: `[a-z]+`}, nil, []string{sanitizedRejected}},
+		{"object rejected", map[string]interface{}{"$ne": ""}, config.ParamRule{Allow: `.*`}, nil, []string{sanitizedRejected}},
+		{"control chars stripped", "title\x00\x1b[31m", config.ParamRule{Strip: `[\x00-\x1f]`, Allow: `[^*]{1,200}`},
+			"title[31m", []string{sanitizedStripped}},
+		{"list items dropped", []interface{}{"music", "<script>", "art", 1}, tags,
+			[]interface{}{"music", "art"}, []string{sanitizedDropped}},
+		{"list truncated", []interface{}{"a", "b", "c", "d", "e"}, tags,
+			[]interface{}{"a", "b", "c"}, []string{sanitizedTruncated}},
+		{"nothing left", []interface{}{"../../etc"}, tags, nil, []string{sanitizedDropped}},
+		{"too many rejected", []interface{}{"a", "b"}, config.ParamRule{MaxItems: 1}, nil, []string{sanitizedRejected}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, actions, err := sanitizeParam(c.value, c.rule)
+			require.NoError(t, err)
+			assert.Equal(t, c.actions, actions)
+			if c.actions == nil || actions[len(actions)-1] != sanitizedRejected {
+				assert.Equal(t, c.expected, v)
+			}
+		})
+	}
+
+	_, _, err := sanitizeParam("x", config.ParamRule{Allow: `(`})
+	assert.Error(t, err)
+}
+
+func TestCaller_ParamSanitization(t *testing.T) {
+	config.Override("ParamSanitization", map[string]interface{}{
+		"claim_search": map[string]interface{}{
+			"text":     map[string]interface{}{"Allow": `[^*]{1,20}`},
+			"any_tags": map[string]interface{}{"Allow": `[a-z]+`, "Action": SanitizeDrop},
+		},
+	})
+	defer config.RestoreOverridden()
+
+	received := make(chan interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received <- req.Params
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+	c := NewCaller(srv.URL, 0)
+
+	rejected := metrics.GetCounterValue(metrics.ProxyParamsSanitized.WithLabelValues(MethodClaimSearch, "text", sanitizedRejected))
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"text": "*"}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "text contains values which are not allowed", res.Error.Message)
+	assert.Equal(t, rejected+1, metrics.GetCounterValue(metrics.ProxyParamsSanitized.WithLabelValues(MethodClaimSearch, "text", sanitizedRejected)))
+
+	dropped := metrics.GetCounterValue(metrics.ProxyParamsSanitized.WithLabelValues(MethodClaimSearch, "any_tags", sanitizedDropped))
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{
+		"text": "cats", "any_tags": []string{"music", "'; DROP TABLE claims; --"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "cats", "any_tags": []interface{}{"music"}}, <-received)
+	assert.Equal(t, dropped+1, metrics.GetCounterValue(metrics.ProxyParamsSanitized.WithLabelValues(MethodClaimSearch, "any_tags", sanitizedDropped)))
+
+	// Params without rules are not checked
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"name": "*"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "*"}, <-received)
+}
@@ -0,0 +1,163 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// TransformFunc rewrites params of a request made in an old schema of the method into the one SDK servers expect now.
+// It reports whether params were in the old schema, those already in the current one should come back as they are.
+// Params may be modified in place.
+type TransformFunc func(params map[string]interface{}) (map[string]interface{}, bool, error)
+
+// RequestTransform keeps clients sending requests in an old schema working after the SDK has changed it.
+// Unlike ParamDefaults, which fill in values, transforms restructure what the client has sent.
+type RequestTransform struct {
+	Name      string
+	Method    string
+	Transform TransformFunc
+}
+
+var (
+	transformsMu sync.RWMutex
+	transforms   []RequestTransform
+)
+
+// RegisterRequestTransform makes callers apply the transform to requests of its method.
+// Transforms defined in SDKRequestTransforms config are applied after registered ones.
+func RegisterRequestTransform(t RequestTransform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms = append(transforms, t)
+}
+
+func requestTransforms(method string) []RequestTransform {
+	var list []RequestTransform
+	transformsMu.RLock()
+	for _, t := range transforms {
+		if t.Method == method {
+			list = append(list, t)
+		}
+	}
+	transformsMu.RUnlock()
+
+	for _, t := range config.GetSDKRequestTransforms() {
+		if t.Method == method {
+			list = append(list, RequestTransform{Name: t.Name, Method: t.Method, Transform: MoveParams(t.Moves)})
+		}
+	}
+	return list
+}
+
+// preflightHookTransformRequest applies request transforms of the query method. It runs before other hooks
+// so they all see params in the current schema. Positional (list) params are left alone.
+func preflightHookTransformRequest(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	list := requestTransforms(q.Method())
+	params := q.ParamsAsMap()
+	if len(list) == 0 || params == nil {
+		return nil, nil
+	}
+	var applied []string
+	for _, t := range list {
+		p, changed, err := t.Transform(params)
+		if err != nil {
+			res := q.newResponse()
+			res.Error = &jsonrpc.RPCError{
+				Code:    rpcerrors.NewInvalidParamsError(nil).Code(),
+				Message: fmt.Sprintf("cannot convert params to the current schema: %v", err),
+			}
+			return res, nil
+		}
+		params = p
+		if !changed {
+			continue
+		}
+		applied = append(applied, t.Name)
+		metrics.ProxyRequestsTransformed.WithLabelValues(q.Method(), t.Name).Inc()
+	}
+	q.Request.Params = params
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	logger.WithFields(logrus.Fields{
+		"method":     q.Method(),
+		"transforms": applied,
+		"request_id": hctx.RequestID,
+	}).Debug("request transformed")
+	return nil, nil
+}
+
+// MoveParams moves params between dot-separated paths, from old to new ones, e.g. "fee_amount" to "fee.amount".
+// Maps along the new path are created as needed and those left empty along the old path are removed.
+// Params which are present at the new path already are kept and the old ones are dropped.
+func MoveParams(moves map[string]string) TransformFunc {
+	froms := make([]string, 0, len(moves))
+	for from := range moves {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	return func(params map[string]interface{}) (map[string]interface{}, bool, error) {
+		var moved bool
+		for _, from := range froms {
+			v, ok := takeParam(params, strings.Split(from, "."))
+			if !ok {
+				continue
+			}
+			if err := putParam(params, strings.Split(moves[from], "."), v); err != nil {
+				return nil, false, err
+			}
+			moved = true
+		}
+		return params, moved, nil
+	}
+}
+
+// takeParam removes the value at path from m and returns it, pruning maps it leaves empty.
+func takeParam(m map[string]interface{}, path []string) (interface{}, bool) {
+	if len(path) == 1 {
+		v, ok := m[path[0]]
+		delete(m, path[0])
+		return v, ok
+	}
+	sub, ok := m[path[0]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	v, ok := takeParam(sub, path[1:])
+	if ok && len(sub) == 0 {
+		delete(m, path[0])
+	}
+	return v, ok
+}
+
+// putParam sets the value at path in m unless there's one already.
+func putParam(m map[string]interface{}, path []string, v interface{}) error {
+	for i, k := range path[:len(path)-1] {
+		next, ok := m[k]
+		if !ok {
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		sub, ok := next.(map[string]interface{})
+		if !ok {
+			return errors.Err("%v is not an object", strings.Join(path[:i+1], "."))
+		}
+		m = sub
+	}
+	last := path[len(path)-1]
+	if _, ok := m[last]; !ok {
+		m[last] = v
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestMoveParams(t *testing.T) {
+	move := MoveParams(map[string]string{
+		"channel.ids":  "channel_ids",
+		"channel.name": "channel",
+		"fee_amount":   "fee.amount",
+		"fee_currency": "fee.currency",
+	})
+
+	// Old schema
+	p, changed, err := move(map[string]interface{}{
+		"channel":      map[string]interface{}{"ids": []interface{}{"abc"}, "name": "@chan"},
+		"fee_amount":   "1.0",
+		"fee_currency": "LBC",
+		"page":         1,
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]interface{}{
+		"channel_ids": []interface{}{"abc"},
+		"channel":     "@chan",
+		"fee":         map[string]interface{}{"amount": "1.0", "currency": "LBC"},
+		"page":        1,
+	}, p)
+
+	// Current schema is left alone
+	current := map[string]interface{}{"channel": "@chan", "fee": map[string]interface{}{"amount": "1.0"}}
+	p, changed, err = move(current)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, map[string]interface{}{"channel": "@chan", "fee": map[string]interface{}{"amount": "1.0"}}, p)
+
+	// Values at the new path win
+	p, _, err = move(map[string]interface{}{"fee_amount": "2.0", "fee": map[string]interface{}{"amount": "1.0"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"fee": map[string]interface{}{"amount": "1.0"}}, p)
+
+	_, _, err = move(map[string]interface{}{"fee_amount": "2.0", "fee": "1.0"})
+	assert.EqualError(t, err, "fee is not an object")
+}
+
+func TestCaller_RequestTransforms(t *testing.T) {
+	config.Override("SDKRequestTransforms", []interface{}{
+		map[string]interface{}{"Name": "channel-object", "Method": MethodClaimSearch, "Moves": map[string]interface{}{
+			"channel.ids": "channel_ids", "channel.name": "channel",
+		}},
+	})
+	config.Override("ParamDefaults", map[string]interface{}{
+		"claim_search": map[string]interface{}{"channel_ids": []string{"default"}},
+	})
+	defer config.RestoreOverridden()
+
+	transformsMu.Lock()
+	registered := transforms
+	transformsMu.Unlock()
+	defer func() {
+		transformsMu.Lock()
+		transforms = registered
+		transformsMu.Unlock()
+	}()
+	RegisterRequestTransform(RequestTransform{Name: "order", Method: MethodClaimSearch,
+		Transform: func(params map[string]interface{}) (map[string]interface{}, bool, error) {
+			raw, ok := params["order"]
+			if !ok {
+				return params, false, nil
+			}
+			order, ok := raw.(string)
+			if !ok {
+				return nil, false, errors.Err("order must be a string")
+			}
+			params["order_by"] = []interface{}{order}
+			delete(params, "order")
+			return params, true, nil
+		},
+	})
+
+	received := make(chan interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received <- req.Params
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+	c := NewCaller(srv.URL, 0)
+
+	before := metrics.GetCounterValue(metrics.ProxyRequestsTransformed.WithLabelValues(MethodClaimSearch, "channel-object"))
+	_, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{
+		"channel": map[string]interface{}{"ids": []string{"abc"}, "name": "@chan"},
+		"order":   "release_time",
+	}))
+	require.NoError(t, err)
+	// Defaults apply to the converted params
+	assert.Equal(t, map[string]interface{}{
+		"channel_ids": []interface{}{"abc"}, "channel": "@chan", "order_by": []interface{}{"release_time"},
+	}, <-received)
+	assert.Equal(t, before+1, metrics.GetCounterValue(metrics.ProxyRequestsTransformed.WithLabelValues(MethodClaimSearch, "channel-object")))
+
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"channel": "@chan"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"channel": "@chan", "channel_ids": []interface{}{"default"}}, <-received)
+	assert.Equal(t, before+1, metrics.GetCounterValue(metrics.ProxyRequestsTransformed.WithLabelValues(MethodClaimSearch, "channel-object")))
+
+	res, err := c.Call(jsonrpc.NewRequest(MethodClaimSearch, map[string]interface{}{"order": 5}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "cannot convert params to the current schema: order must be a string", res.Error.Message)
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/internal/monitor"
+)
+
+// upstreamSnippetSize is how many bytes from the beginning of an SDK response which couldn't be decoded
+// are included in the error.
+const upstreamSnippetSize = 200
+
+// responseSniffer keeps the status, content type and the beginning of the body of an SDK response,
+// so it can be described when it turns out not to be a JSON-RPC response.
+type responseSniffer struct {
+	next http.RoundTripper
+
+	received    bool
+	status      int
+	contentType string
+	head        []byte
+	size        int
+	// readErr is set when reading the body has failed, which is a transport failure rather than an invalid response.
+	readErr error
+}
+
+func (s *responseSniffer) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := s.next.RoundTrip(r)
+	if err != nil {
+		return res, err
+	}
+	s.received = true
+	s.status = res.StatusCode
+	s.contentType = res.Header.Get("Content-Type")
+	res.Body = &sniffedBody{ReadCloser: res.Body, s: s}
+	return res, nil
+}
+
+type sniffedBody struct {
+	io.ReadCloser
+	s *responseSniffer
+}
+
+func (b *sniffedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := upstreamSnippetSize - len(b.s.head); room > 0 {
+		if room > n {
+			room = n
+		}
+		b.s.head = append(b.s.head, p[:room]...)
+	}
+	b.s.size += n
+	if err != nil && err != io.EOF {
+		b.s.readErr = err
+	}
+	return n, err
+}
+
+// invalidResponseError returns an error describing the SDK response if err is the JSON-RPC client failing
+// to decode it, or nil if the call has failed for another reason.
+func (s *responseSniffer) invalidResponseError(err error) error {
+	if err == nil || !s.received || s.readErr != nil {
+		return nil
+	}
+	return rpcerrors.NewUpstreamInvalidError(s.status, s.contentType, s.snippet())
+}
+
+// snippet returns the beginning of the response body on a single line, with sensitive values masked.
+func (s *responseSniffer) snippet() string {
+	text := strings.Join(strings.Fields(strings.ToValidUTF8(string(s.head), "")), " ")
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, text)
+	if s.size > len(s.head) {
+		text += "..."
+	}
+	return monitor.RedactText(text)
+}
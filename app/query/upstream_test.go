@@ -0,0 +1,81 @@
+package query
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_InvalidUpstreamResponse(t *testing.T) {
+	cases := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "html",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html>\n  <body>Bad Gateway token=abc123</body>\n</html>",
+			expected: `upstream returned invalid response (status 502, content type "text/html"), could not decode body to rpc response: ` +
+				`<html> <body>Bad Gateway token=****</body> </html>`,
+		},
+		{
+			name:     "empty",
+			status:   http.StatusOK,
+			expected: `upstream returned invalid response (status 200, content type ""), could not decode body to rpc response: (empty)`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c.contentType != "" {
+					w.Header().Set("Content-Type", c.contentType)
+				}
+				w.WriteHeader(c.status)
+				w.Write([]byte(c.body))
+			}))
+			defer srv.Close()
+
+			q, err := NewQuery(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}), "")
+			require.NoError(t, err)
+			_, err = NewCaller(srv.URL, 0).SendQuery(q)
+			require.Error(t, err)
+			assert.True(t, rpcerrors.IsUpstreamInvalid(err))
+			assert.Contains(t, err.Error(), c.expected)
+		})
+	}
+}
+
+func TestCaller_UpstreamDownIsNotInvalid(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	q, err := NewQuery(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}), "")
+	require.NoError(t, err)
+	_, err = NewCaller(url, 0).SendQuery(q)
+	require.Error(t, err)
+	assert.False(t, rpcerrors.IsUpstreamInvalid(err))
+}
+
+func TestResponseSnippet(t *testing.T) {
+	s := &responseSniffer{}
+	body := strings.Repeat("a", upstreamSnippetSize) + "tail"
+	s.head = []byte(body[:upstreamSnippetSize])
+	s.size = len(body)
+	assert.Equal(t, strings.Repeat("a", upstreamSnippetSize)+"...", s.snippet())
+
+	head := []byte("line\x00one\n\tpassword: \"hunter2\"\xff")
+	s = &responseSniffer{head: head, size: len(head)}
+	assert.Equal(t, `lineone password: "****"`, s.snippet())
+}
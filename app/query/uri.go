@@ -0,0 +1,257 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+const uriScheme = "lbry://"
+
+// ErrInvalidURI is returned for LBRY URIs which the SDK would not be able to resolve.
+var ErrInvalidURI = errors.Base("invalid lbry uri")
+
+var (
+	// Characters not allowed in claim names, see https://spec.lbry.com/#urls
+	reInvalidNameChars = regexp.MustCompile("[=&#:*$@%?/;\"<>{}|^~\\[\\]`\\s\\\\]")
+	reClaimID          = regexp.MustCompile("^[0-9a-fA-F]{1,40}$")
+	reNumber           = regexp.MustCompile("^[1-9][0-9]*$")
+)
+
+// URIPart is a channel or stream segment of a LBRY URI: a claim name with at most one modifier.
+type URIPart struct {
+	Name string
+	// ClaimID is a full or partial (prefix) claim ID.
+	ClaimID string
+	// Sequence picks the n-th claim for the name in the order they were made.
+	Sequence int
+	// AmountOrder picks the claim with the n-th largest bid for the name.
+	AmountOrder int
+}
+
+// URI is a parsed LBRY URI. Either of its parts can be missing but not both.
+type URI struct {
+	Channel *URIPart
+	Stream  *URIPart
+}
+
+// ParseURI parses LBRY URI, with or without the lbry:// scheme.
+// Both "#" and legacy ":" are accepted as claim ID separators.
+func ParseURI(raw string) (*URI, error) {
+	s := strings.TrimSpace(raw)
+	if strings.HasPrefix(strings.ToLower(s), uriScheme) {
+		s = s[len(uriScheme):]
+	}
+	s = strings.TrimSuffix(s, "/")
+	if s == "" {
+		return nil, invalidURI(raw, "empty uri")
+	}
+
+	segments := strings.Split(s, "/")
+	if len(segments) > 2 {
+		return nil, invalidURI(raw, "too many path segments")
+	}
+
+	u := &URI{}
+	for i, seg := range segments {
+		isChannel := strings.HasPrefix(seg, "@")
+		if i == 1 && isChannel {
+			return nil, invalidURI(raw, "stream name cannot start with @")
+		}
+		if len(segments) == 2 && i == 0 && !isChannel {
+			return nil, invalidURI(raw, "channel name must start with @")
+		}
+		part, err := parseURIPart(strings.TrimPrefix(seg, "@"))
+		if err != nil {
+			return nil, invalidURI(raw, err.Error())
+		}
+		if isChannel {
+			u.Channel = part
+		} else {
+			u.Stream = part
+		}
+	}
+	return u, nil
+}
+
+func parseURIPart(seg string) (*URIPart, error) {
+	i := strings.IndexAny(seg, "#:*$")
+	name, modifier := seg, ""
+	if i >= 0 {
+		name, modifier = seg[:i], seg[i:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("empty claim name")
+	}
+	if reInvalidNameChars.MatchString(name) {
+		return nil, fmt.Errorf("claim name %q contains invalid characters", name)
+	}
+
+	p := &URIPart{Name: name}
+	if modifier == "" {
+		return p, nil
+	}
+	value := modifier[1:]
+	switch modifier[0] {
+	case '#', ':':
+		if !reClaimID.MatchString(value) {
+			return nil, fmt.Errorf("invalid claim id %q", value)
+		}
+		p.ClaimID = strings.ToLower(value)
+	case '*', '$':
+		if !reNumber.MatchString(value) {
+			return nil, fmt.Errorf("invalid modifier %q", modifier)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modifier %q", modifier)
+		}
+		if modifier[0] == '*' {
+			p.Sequence = n
+		} else {
+			p.AmountOrder = n
+		}
+	}
+	return p, nil
+}
+
+// String returns the canonical form of the URI, which has the scheme and uses "#" for claim IDs.
+func (u URI) String() string {
+	segments := []string{}
+	if u.Channel != nil {
+		segments = append(segments, "@"+u.Channel.String())
+	}
+	if u.Stream != nil {
+		segments = append(segments, u.Stream.String())
+	}
+	return uriScheme + strings.Join(segments, "/")
+}
+
+func (p URIPart) String() string {
+	switch {
+	case p.ClaimID != "":
+		return p.Name + "#" + p.ClaimID
+	case p.Sequence > 0:
+		return p.Name + "*" + strconv.Itoa(p.Sequence)
+	case p.AmountOrder > 0:
+		return p.Name + "$" + strconv.Itoa(p.AmountOrder)
+	}
+	return p.Name
+}
+
+// NormalizeURI returns the canonical form of LBRY URI or an error if it's not valid.
+// Claim names are kept as is since their normalization is up to the SDK.
+func NormalizeURI(raw string) (string, error) {
+	u, err := ParseURI(raw)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func invalidURI(raw, reason string) error {
+	return errors.Err("%w %q: %s", ErrInvalidURI, raw, reason)
+}
+
+// preflightHookNormalizeURIs replaces URIs in resolve params with their canonical forms so equivalent
+// queries share cache entries, and rejects invalid resolve and get URIs without calling the SDK.
+// Resolve results are keyed by URI so the URIs supplied by the client are put back by the caller.
+// get responses aren't cached so its URI is only validated.
+func preflightHookNormalizeURIs(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	q := hctx.Query
+	params := q.ParamsAsMap()
+	if params == nil {
+		return nil, nil
+	}
+
+	var err error
+	switch q.Method() {
+	case MethodGet:
+		if uri, ok := params["uri"].(string); ok {
+			_, err = ParseURI(uri)
+		}
+	case MethodResolve:
+		err = normalizeResolveURLs(q, params)
+	}
+	if err != nil {
+		res := q.newResponse()
+		res.Error = &jsonrpc.RPCError{Code: rpcerrors.NewInvalidParamsError(err).Code(), Message: err.Error()}
+		return res, nil
+	}
+	return nil, nil
+}
+
+func normalizeResolveURLs(q *Query, params map[string]interface{}) error {
+	var urls []string
+	switch v := params["urls"].(type) {
+	case string:
+		n, err := NormalizeURI(v)
+		if err != nil {
+			return err
+		}
+		q.originalURLs = map[string][]string{n: {v}}
+		params["urls"] = n
+		return nil
+	case []interface{}:
+		for _, u := range v {
+			s, ok := u.(string)
+			if !ok {
+				return errors.Err("%w: urls must be strings", ErrInvalidURI)
+			}
+			urls = append(urls, s)
+		}
+	case []string:
+		urls = v
+	default:
+		return nil
+	}
+
+	normalized := []interface{}{}
+	for _, u := range urls {
+		n, err := NormalizeURI(u)
+		if err != nil {
+			return err
+		}
+		if _, ok := q.originalURLs[n]; !ok {
+			normalized = append(normalized, n)
+		}
+		if q.originalURLs == nil {
+			q.originalURLs = map[string][]string{}
+		}
+		q.originalURLs[n] = append(q.originalURLs[n], u)
+	}
+	params["urls"] = normalized
+	return nil
+}
+
+// restoreURLs returns r with resolve result keyed by URLs as they were supplied by the client.
+// r is not modified as it might be shared through the cache.
+func (q *Query) restoreURLs(r *jsonrpc.RPCResponse) *jsonrpc.RPCResponse {
+	if len(q.originalURLs) == 0 || r == nil || r.Error != nil {
+		return r
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return r
+	}
+	restored := make(map[string]interface{}, len(result))
+	for k, v := range result {
+		originals, ok := q.originalURLs[k]
+		if !ok {
+			restored[k] = v
+			continue
+		}
+		for _, o := range originals {
+			restored[o] = v
+		}
+	}
+	res := *r
+	res.Result = restored
+	return &res
+}
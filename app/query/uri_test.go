@@ -0,0 +1,187 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestParseURI(t *testing.T) {
+	cases := []struct {
+		uri      string
+		expected URI
+	}{
+		{"what", URI{Stream: &URIPart{Name: "what"}}},
+		{"lbry://what", URI{Stream: &URIPart{Name: "what"}}},
+		{"LBRY://what/", URI{Stream: &URIPart{Name: "what"}}},
+		{" lbry://what ", URI{Stream: &URIPart{Name: "what"}}},
+		{"what#19b9c243", URI{Stream: &URIPart{Name: "what", ClaimID: "19b9c243"}}},
+		{"what:19B9C243", URI{Stream: &URIPart{Name: "what", ClaimID: "19b9c243"}}},
+		{"what*3", URI{Stream: &URIPart{Name: "what", Sequence: 3}}},
+		{"what$2", URI{Stream: &URIPart{Name: "what", AmountOrder: 2}}},
+		{"@chan", URI{Channel: &URIPart{Name: "chan"}}},
+		{"lbry://@chan#a", URI{Channel: &URIPart{Name: "chan", ClaimID: "a"}}},
+		{"@chan:a/what:b", URI{Channel: &URIPart{Name: "chan", ClaimID: "a"}, Stream: &URIPart{Name: "what", ClaimID: "b"}}},
+		{"@chan*1/what$1", URI{Channel: &URIPart{Name: "chan", Sequence: 1}, Stream: &URIPart{Name: "what", AmountOrder: 1}}},
+		{"@Каналы/видео-1", URI{Channel: &URIPart{Name: "Каналы"}, Stream: &URIPart{Name: "видео-1"}}},
+		{"@chan/what#19b9c243bea0c45175e6a6027911abbad53e983e", URI{
+			Channel: &URIPart{Name: "chan"},
+			Stream:  &URIPart{Name: "what", ClaimID: "19b9c243bea0c45175e6a6027911abbad53e983e"},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.uri, func(t *testing.T) {
+			u, err := ParseURI(c.uri)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, *u)
+		})
+	}
+}
+
+func TestParseURI_Invalid(t *testing.T) {
+	cases := []string{
+		"",
+		"lbry://",
+		"lbry:///",
+		"#abc",
+		"@",
+		"@#abc",
+		"what#",
+		"what#xyz",
+		"what#19b9c243bea0c45175e6a6027911abbad53e983e0",
+		"what*0",
+		"what*-1",
+		"what$",
+		"what$x",
+		"what#ab:1",
+		"what*1#ab",
+		"what#@1||||",
+		"wh at",
+		"what?x=1",
+		"wh%20at",
+		"chan/what",
+		"@chan/@what",
+		"@chan/what/more",
+		"@chan//what",
+		"https://odysee.com/what",
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			_, err := ParseURI(c)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidURI))
+		})
+	}
+}
+
+func TestNormalizeURI(t *testing.T) {
+	cases := map[string]string{
+		"what":                   "lbry://what",
+		"lbry://what/":           "lbry://what",
+		"what:AB":                "lbry://what#ab",
+		"@Chan:1/What:2":         "lbry://@Chan#1/What#2",
+		"lbry://@chan*2/what$3":  "lbry://@chan*2/what$3",
+		"LBRY://@chan#1/what#2 ": "lbry://@chan#1/what#2",
+	}
+	for uri, expected := range cases {
+		n, err := NormalizeURI(uri)
+		require.NoError(t, err, uri)
+		assert.Equal(t, expected, n, uri)
+
+		renormalized, err := NormalizeURI(n)
+		require.NoError(t, err)
+		assert.Equal(t, n, renormalized)
+	}
+}
+
+func TestCaller_NormalizeResolveURIs(t *testing.T) {
+	received := make(chan interface{}, 1)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req jsonrpc.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		urls := req.Params.(map[string]interface{})["urls"]
+		received <- urls
+		result := map[string]interface{}{}
+		switch v := urls.(type) {
+		case string:
+			result[v] = map[string]interface{}{"name": v}
+		case []interface{}:
+			for _, u := range v {
+				result[u.(string)] = map[string]interface{}{"name": u}
+			}
+		}
+		json.NewEncoder(w).Encode(jsonrpc.RPCResponse{JSONRPC: "2.0", Result: result})
+	}))
+	defer srv.Close()
+
+	var err error
+	c := NewCaller(srv.URL, 0)
+	c.Cache, err = cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+
+	res, err := c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{
+		"urls": []interface{}{"what:AB", "lbry://what#ab", "@chan/one"},
+	}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, []interface{}{"lbry://what#ab", "lbry://@chan/one"}, <-received)
+	assert.Equal(t, map[string]interface{}{
+		"what:AB":        map[string]interface{}{"name": "lbry://what#ab"},
+		"lbry://what#ab": map[string]interface{}{"name": "lbry://what#ab"},
+		"@chan/one":      map[string]interface{}{"name": "lbry://@chan/one"},
+	}, res.Result)
+	c.Cache.Wait()
+
+	// An equivalent query is answered from the cache, keyed by its own URLs
+	res, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{
+		"urls": []interface{}{"lbry://what:ab", "lbry://@chan/one/"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, map[string]interface{}{
+		"lbry://what:ab":    map[string]interface{}{"name": "lbry://what#ab"},
+		"lbry://@chan/one/": map[string]interface{}{"name": "lbry://@chan/one"},
+	}, res.Result)
+
+	res, err = c.Call(jsonrpc.NewRequest(MethodResolve, map[string]interface{}{"urls": "what"}))
+	require.NoError(t, err)
+	assert.Equal(t, "lbry://what", <-received)
+	assert.Equal(t, map[string]interface{}{"what": map[string]interface{}{"name": "lbry://what"}}, res.Result)
+}
+
+func TestCaller_NormalizeURIsInvalid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("sdk should not be called")
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	c := NewCaller(srv.URL, 0)
+	for _, params := range []map[string]interface{}{
+		{"urls": []interface{}{"what", "@chan/what/more"}},
+		{"urls": "what#xyz"},
+		{"urls": []interface{}{1}},
+	} {
+		res, err := c.Call(jsonrpc.NewRequest(MethodResolve, params))
+		require.NoError(t, err)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, rpcerrors.NewInvalidParamsError(nil).Code(), res.Error.Code)
+	}
+
+	res, err := c.Call(jsonrpc.NewRequest(MethodGet, map[string]interface{}{"uri": "what*0"}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, rpcerrors.NewInvalidParamsError(nil).Code(), res.Error.Code)
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"sync"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// Response validation modes, see ResponseValidation config option.
+const (
+	ValidationOff    = "off"
+	ValidationLog    = "log"
+	ValidationReject = "reject"
+)
+
+// ErrMalformedResponse is returned when a response from the SDK fails validation in reject mode.
+var ErrMalformedResponse = errors.Base("malformed sdk response")
+
+// Validator checks the result of a successful SDK response, returning an error if it's not shaped as expected.
+// Validators are run for every response of their method so they should only do cheap checks.
+type Validator func(result interface{}) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string][]Validator{
+		MethodResolve:       {validateResolve},
+		MethodClaimSearch:   {validateClaimSearch},
+		MethodWalletBalance: {validateWalletBalance},
+	}
+)
+
+// RegisterValidator adds a validator for method responses. It's meant to be called at startup.
+func RegisterValidator(method string, v Validator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[method] = append(validators[method], v)
+}
+
+// validateResponse runs validators registered for the query method against r.
+// Depending on ResponseValidation config, a failure is either logged or returned as an error.
+func validateResponse(q *Query, r *jsonrpc.RPCResponse) error {
+	mode := config.GetResponseValidation()
+	if mode == ValidationOff || r == nil || r.Error != nil {
+		return nil
+	}
+
+	validatorsMu.RLock()
+	vs := validators[q.Method()]
+	validatorsMu.RUnlock()
+
+	for _, v := range vs {
+		err := v(r.Result)
+		if err == nil {
+			continue
+		}
+		metrics.ProxyResponseValidationFailures.WithLabelValues(q.Method()).Inc()
+		logger.WithFields(logrus.Fields{"method": q.Method(), "mode": mode}).Errorf("sdk response failed validation: %v", err)
+		if mode == ValidationReject {
+			return errors.Err(ErrMalformedResponse)
+		}
+		return nil
+	}
+	return nil
+}
+
+func validateResolve(result interface{}) error {
+	claims, ok := result.(map[string]interface{})
+	if !ok {
+		return errors.Err("result is not an object")
+	}
+	for url, c := range claims {
+		if _, ok := c.(map[string]interface{}); !ok {
+			return errors.Err("result for %v is not an object", url)
+		}
+	}
+	return nil
+}
+
+func validateClaimSearch(result interface{}) error {
+	page, ok := result.(map[string]interface{})
+	if !ok {
+		return errors.Err("result is not an object")
+	}
+	if _, ok := page["items"].([]interface{}); !ok {
+		return errors.Err("items list is missing")
+	}
+	return nil
+}
+
+func validateWalletBalance(result interface{}) error {
+	balance, ok := result.(map[string]interface{})
+	if !ok {
+		return errors.Err("result is not an object")
+	}
+	if _, ok := balance["available"]; !ok {
+		return errors.Err("available balance is missing")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestBuiltinValidators(t *testing.T) {
+	cases := []struct {
+		validator Validator
+		result    interface{}
+		valid     bool
+	}{
+		{validateResolve, map[string]interface{}{"lbry://one": map[string]interface{}{"claim_id": "abc"}}, true},
+		{validateResolve, map[string]interface{}{"lbry://one": map[string]interface{}{"error": map[string]interface{}{}}}, true},
+		{validateResolve, map[string]interface{}{"lbry://one": "abc"}, false},
+		{validateResolve, []interface{}{}, false},
+		{validateClaimSearch, map[string]interface{}{"items": []interface{}{}, "page": 1.0}, true},
+		{validateClaimSearch, map[string]interface{}{"page": 1.0}, false},
+		{validateClaimSearch, nil, false},
+		{validateWalletBalance, map[string]interface{}{"available": "1.0", "total": "2.0"}, true},
+		{validateWalletBalance, map[string]interface{}{"total": "2.0"}, false},
+	}
+	for i, c := range cases {
+		err := c.validator(c.result)
+		if c.valid {
+			assert.NoError(t, err, "case %v", i)
+		} else {
+			assert.Error(t, err, "case %v", i)
+		}
+	}
+}
+
+func TestCaller_ValidatesResponses(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	malformed := `{"jsonrpc": "2.0", "result": {"page": 1}, "id": 0}`
+	failures := metrics.ProxyResponseValidationFailures.WithLabelValues(MethodClaimSearch)
+
+	before := metrics.GetCounterValue(failures)
+	srv.NextResponse <- malformed
+	res, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, before+1, metrics.GetCounterValue(failures))
+
+	config.Override("ResponseValidation", ValidationReject)
+	defer config.RestoreOverridden()
+	srv.NextResponse <- malformed
+	_, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMalformedResponse))
+	assert.Equal(t, before+2, metrics.GetCounterValue(failures))
+
+	// Valid responses and SDK errors are passed through as usual
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": {"items": [], "page": 1}, "id": 0}`
+	res, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	srv.NextResponse <- `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "bad request"}, "id": 0}`
+	res, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.NotNil(t, res.Error)
+
+	config.Override("ResponseValidation", ValidationOff)
+	srv.NextResponse <- malformed
+	_, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest(MethodClaimSearch))
+	require.NoError(t, err)
+	assert.Equal(t, before+2, metrics.GetCounterValue(failures))
+}
+
+func TestRegisterValidator(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+	config.Override("ResponseValidation", ValidationReject)
+	defer config.RestoreOverridden()
+
+	RegisterValidator("version", func(result interface{}) error {
+		if _, ok := result.(map[string]interface{}); !ok {
+			return errors.Err("result is not an object")
+		}
+		return nil
+	})
+	defer func() {
+		validatorsMu.Lock()
+		delete(validators, "version")
+		validatorsMu.Unlock()
+	}()
+
+	srv.NextResponse <- `{"jsonrpc": "2.0", "result": [], "id": 0}`
+	_, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("version"))
+	assert.True(t, errors.Is(err, ErrMalformedResponse))
+}
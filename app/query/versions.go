@@ -0,0 +1,88 @@
+package query
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// ErrUnsupportedResponseVersion is returned by NegotiateResponseVersion when none of the requested versions is available.
+var ErrUnsupportedResponseVersion = errors.Base("unsupported response version")
+
+var (
+	responseVersionsMu sync.RWMutex
+	responseVersions   = map[string]map[string]AdaptFunc{}
+)
+
+// RegisterResponseVersion makes version of method's response available to clients asking for it with Accept-Version.
+// adapt converts the result returned by the SDK to the shape of that version, nil keeps it as it is,
+// which is how the current shape is given a version name.
+func RegisterResponseVersion(method, version string, adapt AdaptFunc) {
+	responseVersionsMu.Lock()
+	defer responseVersionsMu.Unlock()
+	if responseVersions[method] == nil {
+		responseVersions[method] = map[string]AdaptFunc{}
+	}
+	responseVersions[method][version] = adapt
+}
+
+func responseVersion(method, version string) (AdaptFunc, bool) {
+	responseVersionsMu.RLock()
+	defer responseVersionsMu.RUnlock()
+	adapt, ok := responseVersions[method][version]
+	return adapt, ok
+}
+
+// HasResponseVersions tells if method has any response versions registered.
+func HasResponseVersions(method string) bool {
+	responseVersionsMu.RLock()
+	defer responseVersionsMu.RUnlock()
+	return len(responseVersions[method]) > 0
+}
+
+// NegotiateResponseVersion picks the version of method's response to serve. requested is the value of Accept-Version:
+// a comma-separated list of versions in order of preference, the first available one is picked.
+// Without requested versions, the default from ResponseVersionDefaults config is served.
+// Empty version means the result is served as the SDK returns it, which is always the case for methods without versions.
+func NegotiateResponseVersion(method, requested string) (string, error) {
+	if !HasResponseVersions(method) {
+		return "", nil
+	}
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		v := config.GetResponseVersionDefaults()[strings.ToLower(method)]
+		if _, ok := responseVersion(method, v); v != "" && !ok {
+			logger.Log().Warnf("default response version %q of %v is not registered", v, method)
+			return "", nil
+		}
+		return v, nil
+	}
+	for _, v := range strings.Split(requested, ",") {
+		v = strings.TrimSpace(v)
+		if _, ok := responseVersion(method, v); ok {
+			return v, nil
+		}
+	}
+	return "", errors.Err("%w %q for %v", ErrUnsupportedResponseVersion, requested, method)
+}
+
+// VersionResponse returns a copy of a successful response with the result converted to version of method's response.
+// r itself is returned when there's nothing to convert, it's never modified as responses are shared through the cache.
+func VersionResponse(r *jsonrpc.RPCResponse, method, version string) *jsonrpc.RPCResponse {
+	if r == nil || r.Error != nil || version == "" {
+		return r
+	}
+	adapt, ok := responseVersion(method, version)
+	if !ok || adapt == nil {
+		return r
+	}
+	metrics.ProxyResponseVersions.WithLabelValues(method, version).Inc()
+	res := *r
+	res.Result = adapt(copyValue(r.Result))
+	return &res
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestResponseVersions(t *testing.T) {
+	const method = "versions_test_method"
+	RegisterResponseVersion(method, "1", nil)
+	RegisterResponseVersion(method, "2", Chain(RenameFields(map[string]string{"name": "title"}), FillDefaults(map[string]interface{}{"tags": []interface{}{}})))
+	defer func() {
+		responseVersionsMu.Lock()
+		delete(responseVersions, method)
+		responseVersionsMu.Unlock()
+	}()
+
+	assert.True(t, HasResponseVersions(method))
+	assert.False(t, HasResponseVersions(MethodResolve))
+
+	sdkRes := &jsonrpc.RPCResponse{Result: map[string]interface{}{"name": "abc", "nested": map[string]interface{}{"a": 1}}}
+
+	v, err := NegotiateResponseVersion(method, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+	res := VersionResponse(sdkRes, method, v)
+	assert.Equal(t, map[string]interface{}{"title": "abc", "tags": []interface{}{}, "nested": map[string]interface{}{"a": 1}}, res.Result)
+	// Cached responses are shared so the original stays intact
+	assert.Equal(t, map[string]interface{}{"name": "abc", "nested": map[string]interface{}{"a": 1}}, sdkRes.Result)
+
+	v, err = NegotiateResponseVersion(method, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+	assert.Same(t, sdkRes, VersionResponse(sdkRes, method, v))
+
+	// First available version in order of preference
+	v, err = NegotiateResponseVersion(method, "3, 2 ,1")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+
+	_, err = NegotiateResponseVersion(method, "3")
+	assert.True(t, errors.Is(err, ErrUnsupportedResponseVersion))
+
+	// Methods without versions ignore the header
+	v, err = NegotiateResponseVersion(MethodResolve, "3")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	// Errors are never converted
+	errRes := &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "x"}}
+	assert.Same(t, errRes, VersionResponse(errRes, method, "2"))
+}
+
+func TestNegotiateResponseVersionDefault(t *testing.T) {
+	const method = "versions_test_default"
+	RegisterResponseVersion(method, "1", nil)
+	RegisterResponseVersion(method, "2", FillDefaults(map[string]interface{}{"v": 2}))
+	defer func() {
+		responseVersionsMu.Lock()
+		delete(responseVersions, method)
+		responseVersionsMu.Unlock()
+	}()
+	defer config.RestoreOverridden()
+
+	// No default configured means the SDK shape
+	v, err := NegotiateResponseVersion(method, "")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	config.Override("ResponseVersionDefaults", map[string]string{method: "2"})
+	v, err = NegotiateResponseVersion(method, " ")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+	res := VersionResponse(&jsonrpc.RPCResponse{Result: map[string]interface{}{}}, method, v)
+	assert.Equal(t, map[string]interface{}{"v": 2}, res.Result)
+
+	// Requested version wins over the default
+	v, err = NegotiateResponseVersion(method, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	// Misconfigured default falls back to the SDK shape
+	config.Override("ResponseVersionDefaults", map[string]string{method: "9"})
+	v, err = NegotiateResponseVersion(method, "")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+}
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+// Fields marking results served from the last known ones during a wallet server outage.
+const (
+	StaleField     = "stale"
+	StaleAsOfField = "stale_as_of"
+)
+
+// maxLastKnownResults bounds the number of results kept for serving during wallet server outages.
+const maxLastKnownResults = 10000
+
+// lastKnown holds the latest successful results of user queries to methods set to be served from them
+// during wallet server outages (see WalletOutageResponses config).
+var lastKnown = newResultStore(maxLastKnownResults)
+
+type storedResult struct {
+	key    string
+	result []byte
+	at     time.Time
+}
+
+// resultStore keeps serialized results, dropping the ones stored earliest once it's full.
+// Results are serialized so every response served from the store gets its own copy.
+type resultStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newResultStore(maxEntries int) *resultStore {
+	return &resultStore{maxEntries: maxEntries, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+func (s *resultStore) put(key string, result []byte, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.order.Remove(el)
+	}
+	s.entries[key] = s.order.PushBack(&storedResult{key: key, result: result, at: at})
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*storedResult).key)
+	}
+}
+
+func (s *resultStore) get(key string) (*storedResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return el.Value.(*storedResult), true
+}
+
+// walletOutageMethod returns outage handling configured for the query method. Only reads of users' own wallets
+// are eligible, wallet mutations always fail with the actual error.
+func walletOutageMethod(q *Query) (config.WalletOutageResponse, bool) {
+	if !q.IsAuthenticated() || IsWalletMutation(q.Method()) {
+		return config.WalletOutageResponse{}, false
+	}
+	r, ok := config.GetWalletOutageResponses()[q.Method()]
+	return r, ok
+}
+
+func lastKnownKey(q *Query) (string, error) {
+	params, err := json.Marshal(q.Params())
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(append([]byte(q.WalletID+"|"+q.Method()+"|"), params...))
+	return hex.EncodeToString(h[:]), nil
+}
+
+// rememberResult stores a successful result of the query for serving during wallet server outages
+// if the method is set to be served from last known results.
+func rememberResult(q *Query, res *jsonrpc.RPCResponse) {
+	if res == nil || res.Error != nil {
+		return
+	}
+	if r, ok := walletOutageMethod(q); !ok || r.MaxStaleness <= 0 {
+		return
+	}
+	key, err := lastKnownKey(q)
+	if err != nil {
+		return
+	}
+	result, err := json.Marshal(res.Result)
+	if err != nil {
+		return
+	}
+	lastKnown.put(key, result, time.Now())
+}
+
+// walletOutage returns the response configured in WalletOutageResponses for the query when its wallet server
+// couldn't be reached, cause is the reason the call failed. It's the last known result of the same query marked
+// as stale if there's one within MaxStaleness, or a wallet unavailable error otherwise. ok is false if the method
+// is not set to be handled this way.
+func (c *Caller) walletOutage(q *Query, cause error) (res *jsonrpc.RPCResponse, ok bool) {
+	r, ok := walletOutageMethod(q)
+	if !ok {
+		return nil, false
+	}
+	log := logger.WithFields(logrus.Fields{"method": q.Method(), "endpoint": c.endpoint, "user_id": c.userID, "cause": cause})
+
+	if r.MaxStaleness > 0 {
+		if result, at, ok := lastKnownResult(q, r.MaxStaleness); ok {
+			result[StaleField] = true
+			result[StaleAsOfField] = at.UTC().Format(time.RFC3339)
+			res = q.newResponse()
+			res.Result = result
+			metrics.ProxyWalletOutageResponses.WithLabelValues(q.Method(), "last_known").Inc()
+			log.Warn("wallet server is unavailable, responding with last known result")
+			return res, true
+		}
+	}
+
+	rerr := rpcerrors.NewWalletUnavailableError()
+	res = q.newResponse()
+	res.Error = &jsonrpc.RPCError{Code: rerr.Code(), Message: rerr.Error()}
+	metrics.ProxyWalletOutageResponses.WithLabelValues(q.Method(), "unavailable").Inc()
+	log.Warn("wallet server is unavailable")
+	return res, true
+}
+
+// lastKnownResult returns a copy of the last known result of the query if it's at most maxStaleness old.
+// Only map results can be marked as stale, others are never returned.
+func lastKnownResult(q *Query, maxStaleness time.Duration) (map[string]interface{}, time.Time, bool) {
+	key, err := lastKnownKey(q)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	stored, ok := lastKnown.get(key)
+	if !ok || time.Since(stored.at) > maxStaleness {
+		return nil, time.Time{}, false
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(stored.result, &result); err != nil || result == nil {
+		return nil, time.Time{}, false
+	}
+	return result, stored.at, true
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestCaller_WalletOutage(t *testing.T) {
+	config.Override("WalletOutageResponses", map[string]interface{}{
+		MethodWalletBalance: map[string]interface{}{"MaxStaleness": "1m"},
+		"txo_list":          map[string]interface{}{},
+		MethodWalletSend:    map[string]interface{}{"MaxStaleness": "1m"},
+	})
+	defer config.RestoreOverridden()
+	lastKnown = newResultStore(maxLastKnownResults)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {"available": "1.5", "total": "2.0"}, "id": 0}`)
+	}))
+	c := NewCaller(srv.URL, 551)
+
+	res, err := c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	srv.Close()
+
+	lastKnownServed := metrics.GetCounterValue(metrics.ProxyWalletOutageResponses.WithLabelValues(MethodWalletBalance, "last_known"))
+	res, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	result := res.Result.(map[string]interface{})
+	assert.Equal(t, "1.5", result["available"])
+	assert.Equal(t, true, result[StaleField])
+	asOf, err := time.Parse(time.RFC3339, result[StaleAsOfField].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), asOf, time.Minute)
+	assert.Equal(t, lastKnownServed+1, metrics.GetCounterValue(metrics.ProxyWalletOutageResponses.WithLabelValues(MethodWalletBalance, "last_known")))
+
+	// Served results are copies
+	res, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", res.Result.(map[string]interface{})["available"])
+
+	// Nothing known for different params
+	res, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance, map[string]interface{}{"confirmations": 6}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32099, res.Error.Code)
+
+	// Too old
+	lastKnown = newResultStore(maxLastKnownResults)
+	q, err := NewQuery(jsonrpc.NewRequest(MethodWalletBalance), "lbrytv-id.551.wallet")
+	require.NoError(t, err)
+	key, err := lastKnownKey(q)
+	require.NoError(t, err)
+	lastKnown.put(key, []byte(`{"available": "1.0"}`), time.Now().Add(-2*time.Minute))
+	res, err = c.Call(jsonrpc.NewRequest(MethodWalletBalance))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32099, res.Error.Code)
+
+	unavailable := metrics.GetCounterValue(metrics.ProxyWalletOutageResponses.WithLabelValues("txo_list", "unavailable"))
+	res, err = c.Call(jsonrpc.NewRequest("txo_list"))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32099, res.Error.Code)
+	assert.Equal(t, "wallet is temporarily unavailable, please try again later", res.Error.Message)
+	assert.Equal(t, unavailable+1, metrics.GetCounterValue(metrics.ProxyWalletOutageResponses.WithLabelValues("txo_list", "unavailable")))
+
+	// Mutations and methods which aren't listed fail as usual
+	_, err = c.Call(jsonrpc.NewRequest(MethodWalletSend, map[string]interface{}{"addresses": []string{"x"}, "amount": "1"}))
+	assert.Error(t, err)
+	_, err = c.Call(jsonrpc.NewRequest(MethodClaimList))
+	assert.Error(t, err)
+
+	// Anonymous queries are not handled
+	_, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("txo_list"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// WarningsField is the result field where SDK puts non-fatal warnings accompanying a successful result.
+const WarningsField = "warnings"
+
+// WarningTypeGeneric is assigned to warnings which come without a type.
+const WarningTypeGeneric = "generic"
+
+// Warning is a non-fatal notice returned by the SDK along with a result.
+type Warning struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// postflightHookWarnings brings warnings found in SDK result to the uniform []Warning shape
+// so clients can show them without knowing each method's format, and counts them.
+func postflightHookWarnings(_ *Caller, hctx *HookContext) (*jsonrpc.RPCResponse, error) {
+	r := hctx.Response
+	if r == nil || r.Error != nil {
+		return nil, nil
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := result[WarningsField]
+	if !ok {
+		return nil, nil
+	}
+	warnings := parseWarnings(raw)
+	if len(warnings) == 0 {
+		delete(result, WarningsField)
+		return nil, nil
+	}
+	result[WarningsField] = warnings
+	for _, w := range warnings {
+		metrics.ProxySDKWarnings.WithLabelValues(hctx.Query.Method(), w.Type).Inc()
+	}
+	hctx.AddLogField("warnings", warnings)
+	return nil, nil
+}
+
+// ResponseWarnings returns SDK warnings contained in a response processed by the caller.
+func ResponseWarnings(r *jsonrpc.RPCResponse) []Warning {
+	if r == nil || r.Error != nil {
+		return nil
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch w := result[WarningsField].(type) {
+	case []Warning:
+		return w
+	case nil:
+		return nil
+	default:
+		return parseWarnings(w)
+	}
+}
+
+// WithWarnings returns a copy of a successful response with warnings appended to the ones in its result.
+// The response itself may be cached, so it's never modified. Results which are not objects are returned as is.
+func WithWarnings(r *jsonrpc.RPCResponse, warnings ...Warning) *jsonrpc.RPCResponse {
+	if r == nil || r.Error != nil || len(warnings) == 0 {
+		return r
+	}
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return r
+	}
+	newResult := make(map[string]interface{}, len(result)+1)
+	for k, v := range result {
+		newResult[k] = v
+	}
+	existing := ResponseWarnings(r)
+	all := make([]Warning, 0, len(existing)+len(warnings))
+	all = append(all, existing...)
+	newResult[WarningsField] = append(all, warnings...)
+
+	res := *r
+	res.Result = newResult
+	return &res
+}
+
+// parseWarnings accepts a single warning or a list of them, each being either a plain message
+// or an object with message and type (or code) fields.
+func parseWarnings(raw interface{}) []Warning {
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case nil:
+		return nil
+	default:
+		items = []interface{}{v}
+	}
+
+	warnings := []Warning{}
+	for _, i := range items {
+		w := Warning{Type: WarningTypeGeneric}
+		switch v := i.(type) {
+		case string:
+			w.Message = v
+		case map[string]interface{}:
+			if m, ok := v["message"]; ok {
+				w.Message = fmt.Sprint(m)
+			}
+			if t, ok := v["type"].(string); ok && t != "" {
+				w.Type = t
+			} else if c, ok := v["code"]; ok {
+				w.Type = fmt.Sprint(c)
+			}
+		default:
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestParseWarnings(t *testing.T) {
+	assert.Nil(t, parseWarnings(nil))
+	assert.Equal(t, []Warning{}, parseWarnings([]interface{}{}))
+	assert.Equal(t, []Warning{{Type: WarningTypeGeneric, Message: "slow down"}}, parseWarnings("slow down"))
+	assert.Equal(t,
+		[]Warning{
+			{Type: "deprecated", Message: "use claim_search"},
+			{Type: "404", Message: "stream not found"},
+			{Type: WarningTypeGeneric, Message: "plain"},
+		},
+		parseWarnings([]interface{}{
+			map[string]interface{}{"type": "deprecated", "message": "use claim_search"},
+			map[string]interface{}{"code": 404.0, "message": "stream not found"},
+			"plain",
+			42.0,
+		}),
+	)
+}
+
+func TestCaller_Warnings(t *testing.T) {
+	var response string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, response)
+	}))
+	defer srv.Close()
+
+	counter := metrics.ProxySDKWarnings.WithLabelValues("version", "deprecated")
+	before := metrics.GetCounterValue(counter)
+
+	response = `{"jsonrpc": "2.0", "result": {"build": "release", "warnings": [{"type": "deprecated", "message": "going away"}]}, "id": 0}`
+	res, err := NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("version"))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, []Warning{{Type: "deprecated", Message: "going away"}}, ResponseWarnings(res))
+	assert.Equal(t, "release", res.Result.(map[string]interface{})["build"])
+	assert.Equal(t, before+1, metrics.GetCounterValue(counter))
+
+	response = `{"jsonrpc": "2.0", "result": {"build": "release"}, "id": 0}`
+	res, err = NewCaller(srv.URL, 0).Call(jsonrpc.NewRequest("version"))
+	require.NoError(t, err)
+	assert.Nil(t, ResponseWarnings(res))
+	assert.NotContains(t, res.Result.(map[string]interface{}), WarningsField)
+
+	// Builtin postflight hooks are not duplicated in cloned callers
+	c := NewCaller(srv.URL, 0).CloneWithoutHook(srv.URL, "", "")
+	n := 0
+	for _, h := range c.postflightHooks {
+		if h.name == builtinHookName {
+			n++
+		}
+	}
+	assert.Equal(t, len(NewCaller(srv.URL, 0).postflightHooks), n)
+}
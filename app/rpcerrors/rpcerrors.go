@@ -2,6 +2,7 @@ package rpcerrors
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/lbryio/lbrytv/internal/errors"
 	"github.com/lbryio/lbrytv/internal/monitor"
@@ -12,13 +13,26 @@ import (
 var logger = monitor.NewModuleLogger("rpc_errors")
 
 const (
-	rpcErrorCodeInternal         int = -32080 // general errors that originate inside the proxy module
-	rpcErrorCodeSDK              int = -32603 // otherwise-unspecified errors from the SDK
-	rpcErrorCodeAuthRequired     int = -32084 // auth info is required but is not provided
-	rpcErrorCodeForbidden        int = -32085 // auth info is provided but is not found in the database
-	rpcErrorCodeJSONParse        int = -32700 // invalid JSON was received by the server
-	rpcErrorCodeInvalidParams    int = -32602 // error in params that the client provided
-	rpcErrorCodeMethodNotAllowed int = -32601 // the requested method is not allowed to be called
+	rpcErrorCodeInternal          int = -32080 // general errors that originate inside the proxy module
+	rpcErrorCodeSDK               int = -32603 // otherwise-unspecified errors from the SDK
+	rpcErrorCodeAuthRequired      int = -32084 // auth info is required but is not provided
+	rpcErrorCodeForbidden         int = -32085 // auth info is provided but is not found in the database
+	rpcErrorCodeJSONParse         int = -32700 // invalid JSON was received by the server
+	rpcErrorCodeInvalidRequest    int = -32600 // the JSON sent is not a valid request object
+	rpcErrorCodeInvalidParams     int = -32602 // error in params that the client provided
+	rpcErrorCodeMethodNotAllowed  int = -32601 // the requested method is not allowed to be called
+	rpcErrorCodeMaintenance       int = -32090 // the service is under maintenance and is not accepting requests
+	rpcErrorCodeMethodDisabled    int = -32091 // the requested method is temporarily disabled by operators
+	rpcErrorCodeQueued            int = -32092 // the request failed but has been queued for retrying
+	rpcErrorCodeWalletBusy        int = -32093 // another wallet-mutating request of the same user is in progress
+	rpcErrorCodeRateLimited       int = -32094 // the client has made too many requests and should retry later
+	rpcErrorCodeGeoRestricted     int = -32095 // the requested method is not available in the client's region
+	rpcErrorCodeOverloaded        int = -32096 // the service is too busy to take the request and it should be retried later
+	rpcErrorCodeSessionExpired    int = -32097 // auth info is provided and used to be valid but has expired or been revoked
+	rpcErrorCodeDuplicate         int = -32098 // the request is a duplicate of one the client has just made
+	rpcErrorCodeWalletUnavailable int = -32099 // the wallet server of the user is temporarily unreachable
+	rpcErrorCodeUserCircuitOpen   int = -32100 // calls of the user are refused for a while after too many of them failed
+	rpcErrorCodeUpstreamInvalid   int = -32101 // the SDK has responded with something other than a JSON-RPC response
 )
 
 type RPCError struct {
@@ -49,7 +63,16 @@ func (e RPCError) JSON() []byte {
 	return b
 }
 
-var ErrAuthRequired = errors.Base(responses.AuthRequiredErrorMessage)
+var (
+	ErrAuthRequired      = errors.Base(responses.AuthRequiredErrorMessage)
+	ErrMaintenance       = errors.Base("service under maintenance, please try again later")
+	ErrGeoRestricted     = errors.Base("this method is not available in your region")
+	ErrOverloaded        = errors.Base("service is overloaded, please try again later")
+	ErrDuplicateRequest  = errors.Base("identical request has just been made")
+	ErrWalletUnavailable = errors.Base("wallet is temporarily unavailable, please try again later")
+	ErrUserCircuitOpen   = errors.Base("too many of your requests have failed recently, please try again later")
+	ErrUpstreamInvalid   = errors.Base("upstream returned invalid response")
+)
 
 func newRPCErr(e error, code int) RPCError { return RPCError{errors.Err(e), code} }
 
@@ -60,12 +83,67 @@ func NewInvalidParamsError(e error) RPCError    { return newRPCErr(e, rpcErrorCo
 func NewSDKError(e error) RPCError              { return newRPCErr(e, rpcErrorCodeSDK) }
 func NewForbiddenError(e error) RPCError        { return newRPCErr(e, rpcErrorCodeForbidden) }
 func NewAuthRequiredError() RPCError            { return newRPCErr(ErrAuthRequired, rpcErrorCodeAuthRequired) }
+func NewMaintenanceError() RPCError             { return newRPCErr(ErrMaintenance, rpcErrorCodeMaintenance) }
+func NewMethodDisabledError(e error) RPCError   { return newRPCErr(e, rpcErrorCodeMethodDisabled) }
+func NewQueuedError(e error) RPCError           { return newRPCErr(e, rpcErrorCodeQueued) }
+func NewWalletBusyError(e error) RPCError       { return newRPCErr(e, rpcErrorCodeWalletBusy) }
+func NewRateLimitedError(e error) RPCError      { return newRPCErr(e, rpcErrorCodeRateLimited) }
+func NewGeoRestrictedError() RPCError           { return newRPCErr(ErrGeoRestricted, rpcErrorCodeGeoRestricted) }
+func NewOverloadedError() RPCError              { return newRPCErr(ErrOverloaded, rpcErrorCodeOverloaded) }
+func NewSessionExpiredError(e error) RPCError   { return newRPCErr(e, rpcErrorCodeSessionExpired) }
+func NewDuplicateRequestError() RPCError {
+	return newRPCErr(ErrDuplicateRequest, rpcErrorCodeDuplicate)
+}
+func NewWalletUnavailableError() RPCError {
+	return newRPCErr(ErrWalletUnavailable, rpcErrorCodeWalletUnavailable)
+}
+func NewUserCircuitOpenError() RPCError {
+	return newRPCErr(ErrUserCircuitOpen, rpcErrorCodeUserCircuitOpen)
+}
+
+// NewUpstreamInvalidError describes an SDK response which couldn't be decoded, snippet is the beginning of its body.
+func NewUpstreamInvalidError(status int, contentType, snippet string) RPCError {
+	if snippet == "" {
+		snippet = "(empty)"
+	}
+	return newRPCErr(
+		fmt.Errorf("%w (status %v, content type %q), could not decode body to rpc response: %v", ErrUpstreamInvalid, status, contentType, snippet),
+		rpcErrorCodeUpstreamInvalid,
+	)
+}
+
+// IsUpstreamInvalid returns true if err is about an SDK response which couldn't be decoded.
+func IsUpstreamInvalid(err error) bool {
+	return errors.Is(err, ErrUpstreamInvalid)
+}
 
 func isJSONParseError(err error) bool {
 	var e RPCError
 	return err != nil && errors.As(err, &e) && e.code == rpcErrorCodeJSONParse
 }
 
+// IsClientError returns true if err is caused by what the client has sent, like invalid params or a method
+// which can't be called.
+func IsClientError(err error) bool {
+	var e RPCError
+	if err == nil || !errors.As(err, &e) {
+		return false
+	}
+	return IsClientErrorCode(e.code)
+}
+
+// IsClientErrorCode returns true if a JSON-RPC error with the code is caused by what the client has sent,
+// like invalid params, or is a refusal of a call the client isn't allowed to make.
+// SDK application errors (-32500) are not, the SDK uses them for its own and wallet server failures too.
+func IsClientErrorCode(code int) bool {
+	switch code {
+	case rpcErrorCodeJSONParse, rpcErrorCodeInvalidRequest, rpcErrorCodeInvalidParams, rpcErrorCodeMethodNotAllowed,
+		rpcErrorCodeAuthRequired, rpcErrorCodeForbidden, rpcErrorCodeGeoRestricted:
+		return true
+	}
+	return false
+}
+
 func ErrorToJSON(err error) []byte {
 	var rpcErr RPCError
 	if errors.As(err, &rpcErr) {
@@ -0,0 +1,89 @@
+package sdkrouter
+
+import (
+	"hash/fnv"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+)
+
+// Routing strategies which can be set for a method.
+const (
+	// StrategyRandom sends queries to a random server. It's the default.
+	StrategyRandom = "random"
+	// StrategyHash sends queries for the same resource (claim, wallet) to the same server
+	// to make the most of SDK-side caches. Adding or removing a server only moves
+	// the resources of that server.
+	StrategyHash = "hash"
+)
+
+// SetMethodStrategies validates routing strategies by method and makes the router use them.
+// Methods not listed are routed randomly.
+func (r *Router) SetMethodStrategies(strategies map[string]string) error {
+	s := make(map[string]string, len(strategies))
+	for m, st := range strategies {
+		if st != StrategyRandom && st != StrategyHash {
+			return errors.Err("unknown routing strategy %q for method %v", st, m)
+		}
+		s[m] = st
+	}
+
+	r.strategiesMu.Lock()
+	defer r.strategiesMu.Unlock()
+	r.strategies = s
+	return nil
+}
+
+// MethodStrategy returns the routing strategy set for method.
+func (r *Router) MethodStrategy(method string) string {
+	r.strategiesMu.RLock()
+	defer r.strategiesMu.RUnlock()
+	if s, ok := r.strategies[method]; ok {
+		return s
+	}
+	return StrategyRandom
+}
+
+// ServerFor returns a server for method, picked by key if the method is routed with StrategyHash.
+// Quarantined and draining servers are skipped unless all of them are such.
+func (r *Router) ServerFor(method, key string) *models.LbrynetServer {
+	if r.MethodStrategy(method) == StrategyHash {
+		return r.HashServer(key)
+	}
+	return r.RandomServer()
+}
+
+// HashServer returns the server key maps to, skipping quarantined and draining ones unless all of them are such.
+func (r *Router) HashServer(key string) *models.LbrynetServer {
+	return hashServer(key, r.inRotation(r.GetAll()))
+}
+
+// hashServer picks a server with rendezvous hashing: every server gets a score for the key
+// and the highest one wins, so taking a server away only affects keys it has been winning.
+func hashServer(key string, servers []*models.LbrynetServer) *models.LbrynetServer {
+	var (
+		best      *models.LbrynetServer
+		bestScore uint64
+	)
+	for _, s := range servers {
+		h := fnv.New64a()
+		h.Write([]byte(s.Address))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		score := mix(h.Sum64())
+		if best == nil || score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best
+}
+
+// mix spreads FNV output, which is poorly distributed for inputs sharing a long prefix.
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
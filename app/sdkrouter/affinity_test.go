@@ -0,0 +1,87 @@
+package sdkrouter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServers(n int) []*models.LbrynetServer {
+	servers := make([]*models.LbrynetServer, n)
+	for i := range servers {
+		servers[i] = &models.LbrynetServer{Name: fmt.Sprintf("srv%d", i), Address: fmt.Sprintf("http://srv%d:5279/", i)}
+	}
+	return servers
+}
+
+func TestSetMethodStrategies(t *testing.T) {
+	r := NewWithServers(testServers(2)...)
+	assert.Error(t, r.SetMethodStrategies(map[string]string{"resolve": "sticky"}))
+	assert.Equal(t, StrategyRandom, r.MethodStrategy("resolve"))
+
+	require.NoError(t, r.SetMethodStrategies(map[string]string{"resolve": StrategyHash}))
+	assert.Equal(t, StrategyHash, r.MethodStrategy("resolve"))
+	assert.Equal(t, StrategyRandom, r.MethodStrategy("claim_search"))
+}
+
+func TestServerFor(t *testing.T) {
+	r := NewWithServers(testServers(5)...)
+	require.NoError(t, r.SetMethodStrategies(map[string]string{"resolve": StrategyHash}))
+
+	s := r.ServerFor("resolve", "lbry://one")
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, s.Address, r.ServerFor("resolve", "lbry://one").Address)
+	}
+
+	r.Quarantine(s.Address)
+	other := r.ServerFor("resolve", "lbry://one")
+	assert.NotEqual(t, s.Address, other.Address)
+	r.release(s.Address)
+	assert.Equal(t, s.Address, r.ServerFor("resolve", "lbry://one").Address)
+}
+
+func TestHashServer_Remapping(t *testing.T) {
+	servers := testServers(10)
+	keys := make([]string, 10000)
+	before := map[string]string{}
+	counts := map[string]int{}
+	for i := range keys {
+		keys[i] = fmt.Sprintf("lbry://claim-%d", i)
+		s := hashServer(keys[i], servers)
+		before[keys[i]] = s.Address
+		counts[s.Address]++
+	}
+	// Keys should be spread across all servers somewhat evenly.
+	for _, s := range servers {
+		assert.InDelta(t, 1000, counts[s.Address], 200, s.Address)
+	}
+
+	// Removing a server only moves keys which were on it.
+	removed := servers[3]
+	remaining := append(append([]*models.LbrynetServer{}, servers[:3]...), servers[4:]...)
+	for _, k := range keys {
+		a := hashServer(k, remaining).Address
+		if before[k] == removed.Address {
+			assert.NotEqual(t, removed.Address, a)
+		} else {
+			assert.Equal(t, before[k], a, k)
+		}
+	}
+
+	// Adding a server only moves keys to the new one, about 1/11th of them.
+	added := &models.LbrynetServer{Name: "srv10", Address: "http://srv10:5279/"}
+	grown := append(append([]*models.LbrynetServer{}, servers...), added)
+	var moved int
+	for _, k := range keys {
+		a := hashServer(k, grown).Address
+		if a != before[k] {
+			assert.Equal(t, added.Address, a)
+			moved++
+		}
+	}
+	assert.InDelta(t, len(keys)/11, moved, 200)
+}
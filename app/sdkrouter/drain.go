@@ -0,0 +1,146 @@
+package sdkrouter
+
+import (
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// ServerStatus describes the routing state of an SDK server.
+type ServerStatus struct {
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	Quarantined bool   `json:"quarantined"`
+	Draining    bool   `json:"draining"`
+}
+
+// Drain takes the server at address out of rotation for maintenance: no new users are assigned to it
+// and it's not picked for unassigned requests, while requests already sent to it complete as usual.
+// Users assigned to a draining server are reassigned on their next authentication.
+// When servers are loaded from the database, drain state is saved there so all API instances pick it up.
+func (r *Router) Drain(address string) error {
+	if s := r.FindServer(address); s == nil || s.Address != address {
+		return errors.Err("unknown sdk server %v", address)
+	}
+	if r.useDB {
+		d := &models.LbrynetServerDrain{Address: address}
+		err := d.Upsert(boil.GetDB(), false, []string{models.LbrynetServerDrainColumns.Address}, boil.Whitelist(), boil.Infer())
+		if err != nil {
+			return errors.Err(err)
+		}
+	}
+	r.setDraining(address, true)
+	return nil
+}
+
+// Undrain puts the server at address back into rotation.
+func (r *Router) Undrain(address string) error {
+	if r.useDB {
+		_, err := models.LbrynetServerDrains(models.LbrynetServerDrainWhere.Address.EQ(address)).DeleteAll(boil.GetDB())
+		if err != nil {
+			return errors.Err(err)
+		}
+	}
+	r.setDraining(address, false)
+	return nil
+}
+
+// IsDraining returns true if the server at address is being drained.
+func (r *Router) IsDraining(address string) bool {
+	r.drainMu.RLock()
+	defer r.drainMu.RUnlock()
+	_, ok := r.draining[address]
+	return ok
+}
+
+// Status returns routing state of all servers.
+func (r *Router) Status() []ServerStatus {
+	servers := r.GetAll()
+	st := make([]ServerStatus, len(servers))
+	for i, s := range servers {
+		st[i] = ServerStatus{
+			Name:        s.Name,
+			Address:     s.Address,
+			Quarantined: r.IsQuarantined(s.Address),
+			Draining:    r.IsDraining(s.Address),
+		}
+	}
+	return st
+}
+
+// FindServer returns the server with the given name or address, or nil if there's no such server.
+func (r *Router) FindServer(nameOrAddress string) *models.LbrynetServer {
+	for _, s := range r.GetAll() {
+		if s.Name == nameOrAddress || s.Address == nameOrAddress {
+			return s
+		}
+	}
+	return nil
+}
+
+func (r *Router) setDraining(address string, on bool) {
+	r.drainMu.Lock()
+	defer r.drainMu.Unlock()
+	if r.draining == nil {
+		r.draining = map[string]time.Time{}
+	}
+	_, was := r.draining[address]
+	if on && !was {
+		r.draining[address] = time.Now()
+		metrics.LbrynetServerDraining.WithLabelValues(address).Set(1)
+		logger.Log().Warnf("lbrynet instance %s is draining", address)
+	} else if !on && was {
+		delete(r.draining, address)
+		metrics.LbrynetServerDraining.WithLabelValues(address).Set(0)
+		logger.Log().Infof("lbrynet instance %s is back in rotation", address)
+	}
+}
+
+// reloadDrainsFromDB replaces drain state with the one saved in the database by any API instance.
+func (r *Router) reloadDrainsFromDB() {
+	drains, err := models.LbrynetServerDrains().All(boil.GetDB())
+	if err != nil {
+		logger.Log().Error("Error retrieving lbrynet server drains: ", err)
+		return
+	}
+
+	saved := map[string]bool{}
+	for _, d := range drains {
+		saved[d.Address] = true
+	}
+
+	r.drainMu.RLock()
+	current := make([]string, 0, len(r.draining))
+	for a := range r.draining {
+		current = append(current, a)
+	}
+	r.drainMu.RUnlock()
+
+	for _, a := range current {
+		if !saved[a] {
+			r.setDraining(a, false)
+		}
+	}
+	for a := range saved {
+		r.setDraining(a, true)
+	}
+}
+
+// inRotation returns servers which are neither draining nor quarantined,
+// or all of them if there are none such.
+func (r *Router) inRotation(servers []*models.LbrynetServer) []*models.LbrynetServer {
+	available := make([]*models.LbrynetServer, 0, len(servers))
+	for _, s := range servers {
+		if !r.IsQuarantined(s.Address) && !r.IsDraining(s.Address) {
+			available = append(available, s)
+		}
+	}
+	if len(available) == 0 {
+		return servers
+	}
+	return available
+}
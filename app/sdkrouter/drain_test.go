@@ -0,0 +1,54 @@
+package sdkrouter
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	r := NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: "http://srv1"},
+		&models.LbrynetServer{Name: "srv2", Address: "http://srv2"},
+	)
+	r.leastLoaded = r.servers[1]
+
+	require.NoError(t, r.Drain("http://srv2"))
+	assert.True(t, r.IsDraining("http://srv2"))
+	assert.False(t, r.IsDraining("http://srv1"))
+	assert.Error(t, r.Drain("http://srv3"))
+	assert.Error(t, r.Drain("srv1"), "servers are drained by address")
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "srv1", r.RandomServer().Name)
+		assert.Equal(t, "srv1", r.LeastLoaded().Name)
+	}
+	assert.Nil(t, r.HealthyServer("http://srv1"))
+
+	assert.Equal(t, []ServerStatus{
+		{Name: "srv1", Address: "http://srv1"},
+		{Name: "srv2", Address: "http://srv2", Draining: true},
+	}, r.Status())
+
+	// Falls back to any server when everything is out of rotation
+	r.Quarantine("http://srv1")
+	assert.NotNil(t, r.RandomServer())
+
+	require.NoError(t, r.Undrain("http://srv2"))
+	assert.False(t, r.IsDraining("http://srv2"))
+	assert.Equal(t, "srv2", r.LeastLoaded().Name)
+	assert.Equal(t, "srv2", r.HealthyServer("http://srv1").Name)
+}
+
+func TestFindServer(t *testing.T) {
+	r := NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: "http://srv1"},
+		&models.LbrynetServer{Name: "srv2", Address: "http://srv2"},
+	)
+	assert.Equal(t, "http://srv2", r.FindServer("srv2").Address)
+	assert.Equal(t, "srv1", r.FindServer("http://srv1").Name)
+	assert.Nil(t, r.FindServer("srv3"))
+}
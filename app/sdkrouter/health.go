@@ -0,0 +1,134 @@
+package sdkrouter
+
+import (
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+
+	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+// restartErrorMarkers are substrings of transport errors which SDK nodes produce while restarting.
+// They are matched on error text because the JSON-RPC client doesn't wrap underlying errors.
+var restartErrorMarkers = []string{
+	"connection refused",
+	"connection reset by peer",
+}
+
+// IsRestartError returns true if err means that an SDK node is not accepting connections,
+// which is what happens while it's restarting. Timeouts are not considered restart errors
+// as the node might just be busy.
+func IsRestartError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	msg := err.Error()
+	for _, m := range restartErrorMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Quarantine takes the SDK node at address out of rotation until a health check passes.
+func (r *Router) Quarantine(address string) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	if r.quarantined == nil {
+		r.quarantined = map[string]time.Time{}
+	}
+	if _, ok := r.quarantined[address]; ok {
+		return
+	}
+	r.quarantined[address] = time.Now()
+	metrics.LbrynetServerQuarantined.WithLabelValues(address).Set(1)
+	logger.Log().Warnf("lbrynet instance %s quarantined", address)
+}
+
+// IsQuarantined returns true if the SDK node at address is waiting for a health check to pass.
+func (r *Router) IsQuarantined(address string) bool {
+	r.healthMu.RLock()
+	defer r.healthMu.RUnlock()
+	_, ok := r.quarantined[address]
+	return ok
+}
+
+func (r *Router) release(address string) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	since, ok := r.quarantined[address]
+	if !ok {
+		return
+	}
+	delete(r.quarantined, address)
+	r.startRamp(address, time.Now())
+	metrics.LbrynetServerQuarantined.WithLabelValues(address).Set(0)
+	logger.Log().Infof("lbrynet instance %s released from quarantine after %s", address, time.Since(since))
+}
+
+// HealthyServer returns a random non-quarantined, non-draining public server other than the one at exclude address,
+// servers on the slow-start ramp being picked less often. It returns nil if there are no such servers.
+func (r *Router) HealthyServer(exclude string) *models.LbrynetServer {
+	var candidates []*models.LbrynetServer
+	for _, s := range r.GetAll() {
+		if s.Address == exclude || s.Private || r.IsQuarantined(s.Address) || r.IsDraining(s.Address) {
+			continue
+		}
+		candidates = append(candidates, s)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return r.pickWeighted(candidates)
+}
+
+// WatchHealth keeps checking quarantined SDK nodes and puts them back into rotation once they respond,
+// slowly ramping up their traffic if SetSlowStart has been called.
+func (r *Router) WatchHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		<-ticker.C
+		r.checkQuarantined()
+		r.updateRamps(time.Now())
+	}
+}
+
+func (r *Router) checkQuarantined() {
+	r.healthMu.RLock()
+	addresses := make([]string, 0, len(r.quarantined))
+	for a := range r.quarantined {
+		addresses = append(addresses, a)
+	}
+	r.healthMu.RUnlock()
+
+	for _, a := range addresses {
+		if err := checkHealth(a); err != nil {
+			logger.Log().Debugf("lbrynet instance %s is still unhealthy: %v", a, err)
+			continue
+		}
+		r.release(a)
+	}
+}
+
+func checkHealth(address string) error {
+	c := ljsonrpc.NewClient(address)
+	c.SetRPCTimeout(healthCheckTimeout)
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+	if !status.IsRunning {
+		return errors.Err("sdk is not running yet")
+	}
+	return nil
+}
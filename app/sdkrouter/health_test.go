@@ -0,0 +1,117 @@
+package sdkrouter
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRestartError(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	addr := srv.URL
+	srv.Close()
+
+	_, err := ljsonrpc.NewClient(addr).Status()
+	require.Error(t, err)
+	assert.True(t, IsRestartError(err))
+
+	assert.False(t, IsRestartError(nil))
+	assert.False(t, IsRestartError(errors.Err("net/http: timeout awaiting response headers")))
+	assert.True(t, IsRestartError(errors.Err("read tcp 10.0.0.1:5279: connection reset by peer")))
+}
+
+func TestQuarantine(t *testing.T) {
+	r := NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: "http://srv1"},
+		&models.LbrynetServer{Name: "srv2", Address: "http://srv2"},
+		&models.LbrynetServer{Name: "srv-pvt", Address: "http://srv-pvt", Private: true},
+	)
+
+	assert.Equal(t, "srv2", r.HealthyServer("http://srv1").Name)
+
+	r.Quarantine("http://srv2")
+	assert.True(t, r.IsQuarantined("http://srv2"))
+	assert.Nil(t, r.HealthyServer("http://srv1"))
+	for i := 0; i < 20; i++ {
+		assert.NotEqual(t, "srv2", r.RandomServer().Name)
+	}
+
+	r.Quarantine("http://srv1")
+	r.Quarantine("http://srv-pvt")
+	// Falls back to any server when everything is quarantined
+	assert.NotNil(t, r.RandomServer())
+}
+
+func TestCheckQuarantined(t *testing.T) {
+	rpcServer := test.MockHTTPServer(nil)
+	defer rpcServer.Close()
+
+	r := NewWithServers(&models.LbrynetServer{Name: "srv", Address: rpcServer.URL})
+	r.Quarantine(rpcServer.URL)
+
+	rpcServer.NextResponse <- `{"result": {"is_running": false}}`
+	r.checkQuarantined()
+	assert.True(t, r.IsQuarantined(rpcServer.URL))
+
+	rpcServer.NextResponse <- `{"result": {"is_running": true}}`
+	r.checkQuarantined()
+	assert.False(t, r.IsQuarantined(rpcServer.URL))
+}
+
+func TestRampWeight(t *testing.T) {
+	period := 100 * time.Second
+	assert.Equal(t, minRampWeight, rampWeight(0, period))
+	assert.Equal(t, minRampWeight, rampWeight(5*time.Second, period))
+	assert.Equal(t, 0.25, rampWeight(25*time.Second, period))
+	assert.Equal(t, 0.5, rampWeight(50*time.Second, period))
+	assert.Equal(t, 1.0, rampWeight(period, period))
+	assert.Equal(t, 1.0, rampWeight(2*period, period))
+	assert.Equal(t, 1.0, rampWeight(0, 0))
+}
+
+func TestSlowStart(t *testing.T) {
+	r := NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: "http://srv1"},
+		&models.LbrynetServer{Name: "srv2", Address: "http://srv2"},
+	)
+	r.SetSlowStart(time.Minute)
+	r.Quarantine("http://srv2")
+	r.release("http://srv2")
+	assert.False(t, r.IsQuarantined("http://srv2"))
+
+	now := time.Now()
+	assert.Equal(t, minRampWeight, r.RampWeight("http://srv2", now))
+	assert.InDelta(t, 0.5, r.RampWeight("http://srv2", now.Add(30*time.Second)), 0.01)
+	assert.Equal(t, 1.0, r.RampWeight("http://srv1", now))
+	assert.Equal(t, minRampWeight, metrics.GetGaugeValue(metrics.LbrynetServerRampWeight.WithLabelValues("http://srv2")))
+
+	picks := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		picks[r.RandomServer().Name]++
+	}
+	// srv2 should get about a tenth of srv1's traffic
+	assert.InDelta(t, 2000/11, picks["srv2"], 80)
+
+	r.updateRamps(now.Add(30 * time.Second))
+	assert.InDelta(t, 0.5, metrics.GetGaugeValue(metrics.LbrynetServerRampWeight.WithLabelValues("http://srv2")), 0.01)
+	r.updateRamps(now.Add(time.Minute))
+	assert.Equal(t, 1.0, metrics.GetGaugeValue(metrics.LbrynetServerRampWeight.WithLabelValues("http://srv2")))
+	assert.Equal(t, 1.0, r.RampWeight("http://srv2", now))
+	assert.Empty(t, r.ramping)
+}
+
+func TestSlowStart_Disabled(t *testing.T) {
+	r := NewWithServers(&models.LbrynetServer{Name: "srv", Address: "http://srv"})
+	r.Quarantine("http://srv")
+	r.release("http://srv")
+	assert.Equal(t, 1.0, r.RampWeight("http://srv", time.Now()))
+}
@@ -19,6 +19,11 @@ func FromRequest(r *http.Request) *Router {
 	return v.(*Router)
 }
 
+// IsOnRequest checks if router is present on the request.
+func IsOnRequest(r *http.Request) bool {
+	return r.Context().Value(contextKey) != nil
+}
+
 func AddToRequest(rt *Router, fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fn(w, r.Clone(context.WithValue(r.Context(), contextKey, rt)))
@@ -0,0 +1,83 @@
+package sdkrouter
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+)
+
+// MethodPool is a set of SDK servers dedicated to serving specific methods,
+// isolating expensive workloads from the default pool.
+type MethodPool struct {
+	Name    string
+	Methods []string
+	Servers []string
+}
+
+// SetMethodPools validates pools and makes the router send their methods to pool servers.
+// A method can only belong to one pool.
+func (r *Router) SetMethodPools(pools []MethodPool) error {
+	byMethod := map[string][]*models.LbrynetServer{}
+	for _, p := range pools {
+		if p.Name == "" {
+			return errors.Err("sdk method pool name is required")
+		}
+		if len(p.Methods) == 0 {
+			return errors.Err("sdk method pool %v has no methods", p.Name)
+		}
+		if len(p.Servers) == 0 {
+			return errors.Err("sdk method pool %v has no servers", p.Name)
+		}
+
+		servers := make([]*models.LbrynetServer, len(p.Servers))
+		for i, a := range p.Servers {
+			u, err := url.Parse(a)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.Err("sdk method pool %v has invalid server address %q", p.Name, a)
+			}
+			servers[i] = &models.LbrynetServer{Name: fmt.Sprintf("%v-%v", p.Name, i), Address: a}
+		}
+		for _, m := range p.Methods {
+			if _, ok := byMethod[m]; ok {
+				return errors.Err("method %v is assigned to more than one sdk pool", m)
+			}
+			byMethod[m] = servers
+		}
+		logger.Log().Infof("sdk method pool %v serves %v with %d servers", p.Name, p.Methods, len(servers))
+	}
+
+	r.poolsMu.Lock()
+	defer r.poolsMu.Unlock()
+	r.pools = byMethod
+	return nil
+}
+
+// IsPooled returns true if method is served by a dedicated pool.
+func (r *Router) IsPooled(method string) bool {
+	r.poolsMu.RLock()
+	defer r.poolsMu.RUnlock()
+	_, ok := r.pools[method]
+	return ok
+}
+
+// PoolServer returns a random non-quarantined, non-draining server from the pool dedicated to method, weighted by slow start,
+// other than the one at exclude address. It returns nil if the method has no pool or the pool has no such servers.
+func (r *Router) PoolServer(method, exclude string) *models.LbrynetServer {
+	r.poolsMu.RLock()
+	servers := r.pools[method]
+	r.poolsMu.RUnlock()
+
+	var candidates []*models.LbrynetServer
+	for _, s := range servers {
+		if s.Address == exclude || r.IsQuarantined(s.Address) || r.IsDraining(s.Address) {
+			continue
+		}
+		candidates = append(candidates, s)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return r.pickWeighted(candidates)
+}
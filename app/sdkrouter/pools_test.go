@@ -0,0 +1,54 @@
+package sdkrouter
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMethodPools_Validation(t *testing.T) {
+	r := NewWithServers(&models.LbrynetServer{Name: "srv", Address: "http://srv"})
+
+	cases := map[string][]MethodPool{
+		"no name":        {{Methods: []string{"claim_search"}, Servers: []string{"http://search1"}}},
+		"no methods":     {{Name: "search", Servers: []string{"http://search1"}}},
+		"no servers":     {{Name: "search", Methods: []string{"claim_search"}}},
+		"invalid server": {{Name: "search", Methods: []string{"claim_search"}, Servers: []string{"search1:5279"}}},
+		"duplicate method": {
+			{Name: "search", Methods: []string{"claim_search"}, Servers: []string{"http://search1"}},
+			{Name: "heavy", Methods: []string{"claim_search"}, Servers: []string{"http://heavy1"}},
+		},
+	}
+	for name, pools := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, r.SetMethodPools(pools))
+		})
+	}
+	assert.False(t, r.IsPooled("claim_search"))
+}
+
+func TestPoolServer(t *testing.T) {
+	r := NewWithServers(&models.LbrynetServer{Name: "srv", Address: "http://srv"})
+	require.NoError(t, r.SetMethodPools([]MethodPool{
+		{Name: "search", Methods: []string{"claim_search"}, Servers: []string{"http://search1", "http://search2"}},
+	}))
+
+	assert.True(t, r.IsPooled("claim_search"))
+	assert.False(t, r.IsPooled("resolve"))
+	assert.Nil(t, r.PoolServer("resolve", ""))
+
+	s := r.PoolServer("claim_search", "http://search1")
+	require.NotNil(t, s)
+	assert.Equal(t, "http://search2", s.Address)
+	assert.Equal(t, "search-1", s.Name)
+
+	r.Quarantine("http://search2")
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "http://search1", r.PoolServer("claim_search", "").Address)
+	}
+	r.Quarantine("http://search1")
+	assert.Nil(t, r.PoolServer("claim_search", ""))
+}
@@ -25,9 +25,29 @@ type Router struct {
 
 	loadMu      sync.RWMutex
 	leastLoaded *models.LbrynetServer
+	loads       map[string]uint64
+	// metered are addresses wallet load gauges have been set for, so gauges of removed servers can be dropped.
+	metered map[string]bool
 
 	useDB      bool
 	lastLoaded time.Time
+
+	healthMu    sync.RWMutex
+	quarantined map[string]time.Time
+	slowStart   time.Duration
+	ramping     map[string]time.Time
+
+	poolsMu sync.RWMutex
+	pools   map[string][]*models.LbrynetServer
+
+	drainMu  sync.RWMutex
+	draining map[string]time.Time
+
+	strategiesMu sync.RWMutex
+	strategies   map[string]string
+
+	versionsMu sync.RWMutex
+	versions   map[string]string
 }
 
 func New(servers map[string]string) *Router {
@@ -59,11 +79,10 @@ func (r *Router) GetAll() []*models.LbrynetServer {
 	return r.servers
 }
 
+// RandomServer returns a random server, skipping quarantined and draining ones unless all of them are such.
+// Servers on the slow-start ramp are picked less often.
 func (r *Router) RandomServer() *models.LbrynetServer {
-	r.reloadServersFromDB()
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return r.servers[rand.Intn(len(r.servers))]
+	return r.pickWeighted(r.inRotation(r.GetAll()))
 }
 
 func (r *Router) reloadServersFromDB() {
@@ -82,6 +101,7 @@ func (r *Router) reloadServersFromDB() {
 	}
 
 	r.setServers(servers)
+	r.reloadDrainsFromDB()
 }
 
 func (r *Router) setServers(servers []*models.LbrynetServer) {
@@ -116,37 +136,67 @@ func (r *Router) WatchLoad() {
 func (r *Router) updateLoadAndMetrics() {
 	var best *models.LbrynetServer
 	var min uint64
+	loads := map[string]uint64{}
 
 	servers := r.GetAll()
+	metered := map[string]bool{}
 	logger.Log().Infof("updating load for %d servers", len(servers))
 	for _, server := range servers {
+		metered[server.Address] = true
 		metric := metrics.LbrynetWalletsLoaded.WithLabelValues(server.Address)
-		walletList, err := ljsonrpc.NewClient(server.Address).WalletList("", 1, 1)
+		client := ljsonrpc.NewClient(server.Address)
+		walletList, err := client.WalletList("", 1, 1)
 		if err != nil {
 			logger.Log().Errorf("lbrynet instance %s is not responding: %v", server.Address, err)
 			metric.Set(-1.0)
 			// TODO: maybe mark this instance as unresponsive so new users are assigned to other instances
 			continue
 		}
-
+		if v, err := client.Version(); err != nil {
+			logger.Log().Warnf("cannot get lbrynet version of %s: %v", server.Address, err)
+		} else {
+			r.SetSDKVersion(server.Address, v.LbrynetVersion)
+		}
 		numWallets := walletList.TotalPages
+		loads[server.Address] = numWallets
+		// Servers out of rotation still report their load so the gauge doesn't go stale
+		metric.Set(float64(numWallets))
+		if r.IsQuarantined(server.Address) || r.IsDraining(server.Address) || r.isRamping(server.Address) {
+			continue
+		}
+
 		logger.Log().Debugf("load update: considering %s with load %d", server.Address, numWallets)
 		if (best == nil || numWallets < min) && !server.Private {
 			logger.Log().Debugf("load update: %s has least with %d", server.Address, numWallets)
 			best = server
 			min = numWallets
 		}
-		metric.Set(float64(walletList.TotalPages))
 	}
 
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	for address := range r.metered {
+		if !metered[address] {
+			metrics.LbrynetWalletsLoaded.DeleteLabelValues(address)
+		}
+	}
+	r.metered = metered
+	r.loads = loads
 	if best != nil {
-		r.loadMu.Lock()
-		defer r.loadMu.Unlock()
 		r.leastLoaded = best
 		logger.Log().Infof("After updating load, least loaded server is %s", best.Address)
 	}
 }
 
+// LoadedWallets returns the number of wallets loaded on the server at address as of the last load update.
+// It returns false if the server hasn't responded to the last update or load hasn't been updated yet.
+func (r *Router) LoadedWallets(address string) (uint64, bool) {
+	r.loadMu.RLock()
+	defer r.loadMu.RUnlock()
+	n, ok := r.loads[address]
+	return n, ok
+}
+
 // LeastLoaded returns the least-loaded wallet
 func (r *Router) LeastLoaded() *models.LbrynetServer {
 	r.loadMu.RLock()
@@ -156,6 +206,9 @@ func (r *Router) LeastLoaded() *models.LbrynetServer {
 		logger.Log().Warnf("LeastLoaded() called before load metrics were updated. Returning random server.")
 		return r.RandomServer()
 	}
+	if r.IsDraining(r.leastLoaded.Address) {
+		return r.RandomServer()
+	}
 
 	return r.leastLoaded
 }
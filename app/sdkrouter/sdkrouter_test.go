@@ -1,15 +1,21 @@
 package sdkrouter
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
 	"github.com/lbryio/lbrytv/internal/storage"
 	"github.com/lbryio/lbrytv/internal/test"
 	"github.com/lbryio/lbrytv/models"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -72,20 +78,63 @@ func TestLeastLoaded(t *testing.T) {
 	}
 	r := NewWithServers(servers...)
 
+	// Servers are asked for their wallet list and then their version
+	respond := func(next chan<- string, pages int) {
+		go func() {
+			next <- fmt.Sprintf(`{"result":{"total_pages":%d}}`, pages)
+			next <- `{"result":{"lbrynet_version":"0.99.0"}}`
+		}()
+	}
+
 	// try doing the load in increasing order
-	rpcServerPvt.NextResponse <- `{"result":{"total_pages":5}}`
-	rpcServer1.NextResponse <- `{"result":{"total_pages":10}}`
-	rpcServer2.NextResponse <- `{"result":{"total_pages":20}}`
-	rpcServer3.NextResponse <- `{"result":{"total_pages":30}}`
+	respond(rpcServerPvt.NextResponse, 5)
+	respond(rpcServer1.NextResponse, 10)
+	respond(rpcServer2.NextResponse, 20)
+	respond(rpcServer3.NextResponse, 30)
 	r.updateLoadAndMetrics()
 	assert.Equal(t, "srv1", r.LeastLoaded().Name)
 
 	// now do the load in decreasing order
-	rpcServer1.NextResponse <- `{"result":{"total_pages":30}}`
-	rpcServer2.NextResponse <- `{"result":{"total_pages":20}}`
-	rpcServer3.NextResponse <- `{"result":{"total_pages":10}}`
-	rpcServerPvt.NextResponse <- `{"result":{"total_pages":5}}`
+	respond(rpcServer1.NextResponse, 30)
+	respond(rpcServer2.NextResponse, 20)
+	respond(rpcServer3.NextResponse, 10)
+	respond(rpcServerPvt.NextResponse, 5)
 	r.updateLoadAndMetrics()
 	assert.Equal(t, "srv3", r.LeastLoaded().Name)
 
 }
+
+func TestLoadMetricsOutOfRotation(t *testing.T) {
+	sdk := func(wallets int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":{"total_pages":%d,"lbrynet_version":"0.99.0"}}`, wallets)
+		}))
+	}
+	srv1, srv2, srv3 := sdk(10), sdk(20), sdk(30)
+	defer srv1.Close()
+	defer srv2.Close()
+	defer srv3.Close()
+	loaded := func(address string) float64 {
+		return testutil.ToFloat64(metrics.LbrynetWalletsLoaded.WithLabelValues(address))
+	}
+
+	r := NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: srv1.URL},
+		&models.LbrynetServer{Name: "srv2", Address: srv2.URL},
+		&models.LbrynetServer{Name: "srv3", Address: srv3.URL},
+	)
+	r.Quarantine(srv1.URL)
+	require.NoError(t, r.Drain(srv2.URL))
+	r.updateLoadAndMetrics()
+	assert.Equal(t, 10.0, loaded(srv1.URL))
+	assert.Equal(t, 20.0, loaded(srv2.URL))
+	assert.Equal(t, 30.0, loaded(srv3.URL))
+	assert.Equal(t, "srv3", r.LeastLoaded().Name)
+
+	// Gauges of servers which are gone are dropped
+	r.setServers([]*models.LbrynetServer{{Name: "srv3", Address: srv3.URL}})
+	r.updateLoadAndMetrics()
+	assert.False(t, metrics.LbrynetWalletsLoaded.DeleteLabelValues(srv1.URL))
+	assert.False(t, metrics.LbrynetWalletsLoaded.DeleteLabelValues(srv2.URL))
+	assert.Equal(t, 30.0, loaded(srv3.URL))
+}
@@ -0,0 +1,94 @@
+package sdkrouter
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+)
+
+// minRampWeight is the share of traffic a node gets right after it's released from quarantine,
+// relative to fully available nodes.
+const minRampWeight = 0.1
+
+// SetSlowStart makes nodes released from quarantine receive a share of traffic growing linearly
+// from minRampWeight to full over period. Zero period puts them back to full traffic immediately.
+func (r *Router) SetSlowStart(period time.Duration) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	r.slowStart = period
+}
+
+// startRamp puts the node at address on the slow-start ramp, healthMu must be held.
+func (r *Router) startRamp(address string, now time.Time) {
+	if r.slowStart <= 0 {
+		return
+	}
+	if r.ramping == nil {
+		r.ramping = map[string]time.Time{}
+	}
+	r.ramping[address] = now
+	metrics.LbrynetServerRampWeight.WithLabelValues(address).Set(minRampWeight)
+}
+
+// RampWeight returns the share of traffic the node at address should get at now, relative to fully available nodes.
+// It's 1 for nodes which are not on the slow-start ramp.
+func (r *Router) RampWeight(address string, now time.Time) float64 {
+	r.healthMu.RLock()
+	defer r.healthMu.RUnlock()
+	since, ok := r.ramping[address]
+	if !ok {
+		return 1
+	}
+	return rampWeight(now.Sub(since), r.slowStart)
+}
+
+func rampWeight(elapsed, period time.Duration) float64 {
+	if period <= 0 || elapsed >= period {
+		return 1
+	}
+	w := float64(elapsed) / float64(period)
+	if w < minRampWeight {
+		return minRampWeight
+	}
+	return w
+}
+
+// updateRamps refreshes ramp weight metrics and takes nodes which have finished the ramp off it.
+func (r *Router) updateRamps(now time.Time) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	for a, since := range r.ramping {
+		w := rampWeight(now.Sub(since), r.slowStart)
+		metrics.LbrynetServerRampWeight.WithLabelValues(a).Set(w)
+		if w >= 1 {
+			delete(r.ramping, a)
+			logger.Log().Infof("lbrynet instance %s is fully available after slow start", a)
+		}
+	}
+}
+
+// isRamping returns true if the node at address is on the slow-start ramp.
+func (r *Router) isRamping(address string) bool {
+	return r.RampWeight(address, time.Now()) < 1
+}
+
+// pickWeighted returns a random server out of servers, nodes on the slow-start ramp being picked proportionally less often.
+func (r *Router) pickWeighted(servers []*models.LbrynetServer) *models.LbrynetServer {
+	now := time.Now()
+	weights := make([]float64, len(servers))
+	var total float64
+	for i, s := range servers {
+		weights[i] = r.RampWeight(s.Address, now)
+		total += weights[i]
+	}
+	x := rand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return servers[i]
+		}
+		x -= w
+	}
+	return servers[len(servers)-1]
+}
@@ -0,0 +1,19 @@
+package sdkrouter
+
+// SDKVersion returns the lbrynet version reported by the server at address during the last load update,
+// empty if it's not known yet.
+func (r *Router) SDKVersion(address string) string {
+	r.versionsMu.RLock()
+	defer r.versionsMu.RUnlock()
+	return r.versions[address]
+}
+
+// SetSDKVersion records the lbrynet version of the server at address.
+func (r *Router) SetSDKVersion(address, version string) {
+	r.versionsMu.Lock()
+	defer r.versionsMu.Unlock()
+	if r.versions == nil {
+		r.versions = map[string]string{}
+	}
+	r.versions[address] = version
+}
@@ -0,0 +1,286 @@
+// Package deadletter keeps wallet operations which have failed for transient reasons
+// and retries them in the background.
+package deadletter
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	// StatusPending letters are waiting for the next retry.
+	StatusPending = "pending"
+	// StatusProcessing letters are being retried right now.
+	StatusProcessing = "processing"
+	// StatusDone letters have been successfully retried.
+	StatusDone = "done"
+	// StatusFailed letters have failed permanently and need manual review.
+	StatusFailed = "failed"
+
+	tableName = "dead_letters"
+
+	pgUniqueConstraintViolation = "23505"
+	// maxClaimRetries is how many times claimNext tries again after losing a letter to a concurrent worker.
+	maxClaimRetries = 10
+)
+
+var logger = monitor.NewModuleLogger("deadletter")
+
+// Letter is a failed wallet operation.
+type Letter struct {
+	ID            int                 `json:"id"`
+	UserID        int                 `json:"user_id"`
+	Method        string              `json:"method"`
+	Request       *jsonrpc.RPCRequest `json:"request"`
+	Status        string              `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	NextAttemptAt time.Time           `json:"next_attempt_at"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+func toLetter(m *models.DeadLetter) (*Letter, error) {
+	l := &Letter{
+		ID:            m.ID,
+		UserID:        m.UserID,
+		Method:        m.Method,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		LastError:     m.LastError.String,
+		NextAttemptAt: m.NextAttemptAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+	if err := m.Request.Unmarshal(&l.Request); err != nil {
+		return nil, errors.Err(err)
+	}
+	return l, nil
+}
+
+func now() time.Time {
+	return time.Now().In(boil.GetLocation())
+}
+
+// idempotencyKey identifies the operation so the same wallet operation is never queued twice
+// while it's still waiting to go through. The JSON-RPC request ID is left out as clients
+// send a new one when they repeat a request.
+func idempotencyKey(userID int, req *jsonrpc.RPCRequest) (string, error) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|", userID, req.Method)
+	h.Write(params)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// findActive returns the letter with the given key which hasn't been retried successfully yet, or nil.
+func findActive(exec boil.Executor, key string) (*models.DeadLetter, error) {
+	m, err := models.DeadLetters(
+		models.DeadLetterWhere.IdempotencyKey.EQ(key),
+		models.DeadLetterWhere.Status.NEQ(StatusDone),
+	).One(exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+// Enqueue stores a failed operation to be retried after retryIn. If the same operation
+// is already waiting to be retried, the existing letter is returned.
+func Enqueue(exec boil.Executor, userID int, req *jsonrpc.RPCRequest, cause error, retryIn time.Duration) (*Letter, error) {
+	key, err := idempotencyKey(userID, req)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	request, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+
+	m, err := findActive(exec, key)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	if m != nil {
+		return toLetter(m)
+	}
+
+	m = &models.DeadLetter{
+		UserID:         userID,
+		Method:         req.Method,
+		Request:        request,
+		IdempotencyKey: key,
+		Status:         StatusPending,
+		NextAttemptAt:  now().Add(retryIn),
+	}
+	if cause != nil {
+		m.LastError = null.StringFrom(cause.Error())
+	}
+	err = m.Insert(exec, boil.Infer())
+	if err != nil {
+		// Another request has queued the same operation in the meantime.
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueConstraintViolation {
+			m, err = findActive(exec, key)
+			if err == nil && m == nil {
+				err = sql.ErrNoRows
+			}
+			if err != nil {
+				return nil, errors.Err(err)
+			}
+			return toLetter(m)
+		}
+		return nil, errors.Err(err)
+	}
+	metrics.ProxyDeadLetterCount.WithLabelValues(req.Method).Inc()
+	logger.Log().Infof("queued %v of user %v for retrying: %v", req.Method, userID, cause)
+	return toLetter(m)
+}
+
+// Get returns a letter by ID or nil if it doesn't exist.
+func Get(exec boil.Executor, id int) (*Letter, error) {
+	m, err := models.FindDeadLetter(exec, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	return toLetter(m)
+}
+
+// List returns letters with the given status (or all of them if status is empty), oldest first.
+func List(exec boil.Executor, status string, limit, offset int) ([]*Letter, error) {
+	mods := []qm.QueryMod{qm.OrderBy(models.DeadLetterColumns.ID), qm.Limit(limit), qm.Offset(offset)}
+	if status != "" {
+		mods = append(mods, models.DeadLetterWhere.Status.EQ(status))
+	}
+	ms, err := models.DeadLetters(mods...).All(exec)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+
+	letters := []*Letter{}
+	for _, m := range ms {
+		l, err := toLetter(m)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, l)
+	}
+	return letters, nil
+}
+
+// Purge deletes a letter by ID, returning the number of deleted letters.
+// Letters which are being retried at the moment are not deleted.
+func Purge(exec boil.Executor, id int) (int64, error) {
+	n, err := models.DeadLetters(
+		models.DeadLetterWhere.ID.EQ(id),
+		models.DeadLetterWhere.Status.NEQ(StatusProcessing),
+	).DeleteAll(exec)
+	return n, errors.Err(err)
+}
+
+// PurgeStatus deletes all letters with the given status, returning the number of deleted letters.
+func PurgeStatus(exec boil.Executor, status string) (int64, error) {
+	if status == StatusProcessing {
+		return 0, errors.Err("letters being processed cannot be purged")
+	}
+	n, err := models.DeadLetters(models.DeadLetterWhere.Status.EQ(status)).DeleteAll(exec)
+	return n, errors.Err(err)
+}
+
+// claimNext marks the next letter due for retrying as being processed and returns it.
+// It returns nil if no letters are due. Concurrent workers never get the same letter:
+// the status only flips from pending once, so a worker which loses the race moves on to the next one.
+func claimNext(exec boil.Executor) (*Letter, error) {
+	for i := 0; i < maxClaimRetries; i++ {
+		m, err := models.DeadLetters(
+			models.DeadLetterWhere.Status.EQ(StatusPending),
+			qm.Where(models.DeadLetterColumns.NextAttemptAt+" <= ?", now()),
+			qm.OrderBy(models.DeadLetterColumns.NextAttemptAt),
+		).One(exec)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.Err(err)
+		}
+
+		m.Status = StatusProcessing
+		m.Attempts++
+		m.UpdatedAt = now()
+		n, err := models.DeadLetters(
+			models.DeadLetterWhere.ID.EQ(m.ID),
+			models.DeadLetterWhere.Status.EQ(StatusPending),
+		).UpdateAll(exec, models.M{
+			models.DeadLetterColumns.Status:    m.Status,
+			models.DeadLetterColumns.Attempts:  m.Attempts,
+			models.DeadLetterColumns.UpdatedAt: m.UpdatedAt,
+		})
+		if err != nil {
+			return nil, errors.Err(err)
+		}
+		if n == 1 {
+			return toLetter(m)
+		}
+	}
+	return nil, nil
+}
+
+func setStatus(exec boil.Executor, id int, status string, cause error, retryIn time.Duration) error {
+	cols := models.M{
+		models.DeadLetterColumns.Status:        status,
+		models.DeadLetterColumns.NextAttemptAt: now().Add(retryIn),
+		models.DeadLetterColumns.UpdatedAt:     now(),
+	}
+	if cause != nil {
+		cols[models.DeadLetterColumns.LastError] = null.StringFrom(cause.Error())
+	}
+	_, err := models.DeadLetters(models.DeadLetterWhere.ID.EQ(id)).UpdateAll(exec, cols)
+	return errors.Err(err)
+}
+
+// postpone puts a claimed letter back in the queue without counting the attempt,
+// for when it couldn't be sent at all.
+func postpone(exec boil.Executor, l *Letter, retryIn time.Duration) error {
+	_, err := models.DeadLetters(models.DeadLetterWhere.ID.EQ(l.ID)).UpdateAll(exec, models.M{
+		models.DeadLetterColumns.Status:        StatusPending,
+		models.DeadLetterColumns.Attempts:      l.Attempts - 1,
+		models.DeadLetterColumns.NextAttemptAt: now().Add(retryIn),
+		models.DeadLetterColumns.UpdatedAt:     now(),
+	})
+	return errors.Err(err)
+}
+
+// failStuck flags letters which have been processing for too long for manual review,
+// as the operation might have gone through before the worker was interrupted.
+func failStuck(exec boil.Executor, olderThan time.Duration) (int64, error) {
+	n, err := models.DeadLetters(
+		models.DeadLetterWhere.Status.EQ(StatusProcessing),
+		models.DeadLetterWhere.UpdatedAt.LT(now().Add(-olderThan)),
+	).UpdateAll(exec, models.M{
+		models.DeadLetterColumns.Status:    StatusFailed,
+		models.DeadLetterColumns.LastError: "retry was interrupted",
+		models.DeadLetterColumns.UpdatedAt: now(),
+	})
+	return n, errors.Err(err)
+}
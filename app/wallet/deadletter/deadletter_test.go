@@ -0,0 +1,216 @@
+package deadletter
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestMain(m *testing.M) {
+	dbConfig := config.GetDatabase()
+	params := storage.ConnParams{
+		Connection: dbConfig.Connection,
+		DBName:     dbConfig.DBName,
+		Options:    dbConfig.Options + "&TimeZone=UTC",
+	}
+	dbConn, connCleanup := storage.CreateTestConn(params)
+	dbConn.SetDefaultConnection()
+
+	code := m.Run()
+
+	connCleanup()
+	os.Exit(code)
+}
+
+func TestEnqueue_Idempotent(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	req := jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0", "addresses": []string{"bXYZ"}})
+	cause := errors.Err(syscall.ECONNREFUSED)
+
+	l1, err := Enqueue(boil.GetDB(), 123, req, cause, 0)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, l1.Status)
+	assert.Equal(t, "wallet_send", l1.Request.Method)
+	assert.Equal(t, cause.Error(), l1.LastError)
+
+	l2, err := Enqueue(boil.GetDB(), 123, req, cause, 0)
+	require.NoError(t, err)
+	assert.Equal(t, l1.ID, l2.ID)
+
+	l3, err := Enqueue(boil.GetDB(), 124, req, cause, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, l1.ID, l3.ID)
+}
+
+func TestWorker_ProcessDue(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	refused := errors.Err(syscall.ECONNREFUSED)
+
+	okLetter, err := Enqueue(boil.GetDB(), 1, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0"}), refused, 0)
+	require.NoError(t, err)
+	downLetter, err := Enqueue(boil.GetDB(), 2, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "2.0"}), refused, 0)
+	require.NoError(t, err)
+	badLetter, err := Enqueue(boil.GetDB(), 3, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "3.0"}), refused, 0)
+	require.NoError(t, err)
+	laterLetter, err := Enqueue(boil.GetDB(), 4, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "4.0"}), refused, time.Hour)
+	require.NoError(t, err)
+
+	sent := map[int]int{}
+	w := NewWorker(boil.GetDB(), 2, time.Minute)
+	w.send = func(l *Letter) (*jsonrpc.RPCResponse, error) {
+		sent[l.UserID]++
+		switch l.ID {
+		case downLetter.ID:
+			return nil, refused
+		case badLetter.ID:
+			return &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Message: "insufficient funds"}}, nil
+		}
+		return &jsonrpc.RPCResponse{Result: "ok"}, nil
+	}
+
+	n, err := w.ProcessDue()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, map[int]int{1: 1, 2: 1, 3: 1}, sent)
+
+	l, err := Get(boil.GetDB(), okLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDone, l.Status)
+
+	l, err = Get(boil.GetDB(), badLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, l.Status)
+	assert.Equal(t, "insufficient funds", l.LastError)
+
+	l, err = Get(boil.GetDB(), downLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, l.Status)
+	assert.Equal(t, 1, l.Attempts)
+	assert.True(t, l.NextAttemptAt.After(time.Now().UTC().Add(time.Minute)))
+
+	l, err = Get(boil.GetDB(), laterLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, l.Status)
+	assert.Equal(t, 0, l.Attempts)
+
+	// Make the failing letter due again, it should be flagged after reaching max attempts.
+	_, err = boil.GetDB().Exec(`UPDATE "dead_letters" SET "next_attempt_at" = now() WHERE "id" = $1`, downLetter.ID)
+	require.NoError(t, err)
+	n, err = w.ProcessDue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	l, err = Get(boil.GetDB(), downLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, l.Status)
+	assert.Equal(t, 2, l.Attempts)
+}
+
+func TestFailStuck(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	l, err := Enqueue(boil.GetDB(), 1, jsonrpc.NewRequest("wallet_send"), nil, 0)
+	require.NoError(t, err)
+	claimed, err := claimNext(boil.GetDB())
+	require.NoError(t, err)
+	require.Equal(t, l.ID, claimed.ID)
+
+	n, err := failStuck(boil.GetDB(), time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, n)
+
+	_, err = boil.GetDB().Exec(`UPDATE "dead_letters" SET "updated_at" = now() - interval '2 hours' WHERE "id" = $1`, l.ID)
+	require.NoError(t, err)
+	n, err = failStuck(boil.GetDB(), time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	l, err = Get(boil.GetDB(), l.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, l.Status)
+}
+
+func TestListAndPurge(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	var ids []int
+	for i := 1; i <= 3; i++ {
+		l, err := Enqueue(boil.GetDB(), i, jsonrpc.NewRequest("wallet_send"), nil, 0)
+		require.NoError(t, err)
+		ids = append(ids, l.ID)
+	}
+	require.NoError(t, setStatus(boil.GetDB(), ids[0], StatusFailed, nil, 0))
+
+	letters, err := List(boil.GetDB(), "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, letters, 3)
+
+	letters, err = List(boil.GetDB(), StatusPending, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, ids[2], letters[0].ID)
+
+	n, err := PurgeStatus(boil.GetDB(), StatusFailed)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	n, err = Purge(boil.GetDB(), ids[1])
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	letters, err = List(boil.GetDB(), "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, ids[2], letters[0].ID)
+
+	_, err = PurgeStatus(boil.GetDB(), StatusProcessing)
+	assert.Error(t, err)
+}
+
+func TestEnqueue_AfterDone(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	req := jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0"})
+
+	l1, err := Enqueue(boil.GetDB(), 1, req, nil, 0)
+	require.NoError(t, err)
+	l2, err := Enqueue(boil.GetDB(), 1, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0"}), nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, l1.ID, l2.ID)
+
+	// Once the operation has gone through, the user is free to do the same thing again.
+	require.NoError(t, setStatus(boil.GetDB(), l1.ID, StatusDone, nil, 0))
+	l3, err := Enqueue(boil.GetDB(), 1, req, nil, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, l1.ID, l3.ID)
+}
+
+func TestWorker_WalletBusy(t *testing.T) {
+	storage.Conn.Truncate([]string{tableName})
+	l, err := Enqueue(boil.GetDB(), 1, jsonrpc.NewRequest("wallet_send"), nil, 0)
+	require.NoError(t, err)
+
+	w := NewWorker(boil.GetDB(), 2, time.Minute)
+	w.lock = func(int) (func(), error) { return nil, errors.Err("wallet is busy") }
+	w.send = func(l *Letter) (*jsonrpc.RPCResponse, error) {
+		t.Fatal("letter should not be sent while the wallet is locked")
+		return nil, nil
+	}
+
+	n, err := w.ProcessDue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	l, err = Get(boil.GetDB(), l.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, l.Status)
+	assert.Equal(t, 0, l.Attempts)
+	assert.True(t, l.NextAttemptAt.After(time.Now().UTC()))
+}
@@ -0,0 +1,127 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+var errForbidden = errors.Base("admin token required")
+
+func errInvalidParam(name string) error {
+	return errors.Base("invalid %v parameter", name)
+}
+
+// HandleList returns a page of queued operations for admins (see auth.IsAdmin).
+// Supported query parameters: status, page, page_size.
+func HandleList(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	if !auth.IsAdmin(r) {
+		writeError(w, http.StatusForbidden, errForbidden.Error())
+		return
+	}
+
+	status := r.FormValue("status")
+	if status != "" && !isValidStatus(status) {
+		writeError(w, http.StatusBadRequest, errInvalidParam("status").Error())
+		return
+	}
+	page, err := parseIntParam(r, "page", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := parseIntParam(r, "page_size", defaultPageSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	letters, err := List(boil.GetDB(), status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Log().Errorf("cannot list dead letters: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot list dead letters")
+		return
+	}
+	respByte, _ := json.Marshal(letters)
+	w.Write(respByte)
+}
+
+// HandlePurge deletes queued operations for admins, either a single one by {id} from the path
+// or all with the status supplied in the query.
+func HandlePurge(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	if !auth.IsAdmin(r) {
+		writeError(w, http.StatusForbidden, errForbidden.Error())
+		return
+	}
+
+	var (
+		n   int64
+		err error
+	)
+	if v, ok := mux.Vars(r)["id"]; ok {
+		id, perr := strconv.Atoi(v)
+		if perr != nil {
+			writeError(w, http.StatusBadRequest, errInvalidParam("id").Error())
+			return
+		}
+		n, err = Purge(boil.GetDB(), id)
+	} else {
+		status := r.FormValue("status")
+		if !isValidStatus(status) || status == StatusProcessing {
+			writeError(w, http.StatusBadRequest, errInvalidParam("status").Error())
+			return
+		}
+		n, err = PurgeStatus(boil.GetDB(), status)
+	}
+	if err != nil {
+		logger.Log().Errorf("cannot purge dead letters: %v", err)
+		writeError(w, http.StatusInternalServerError, "cannot purge dead letters")
+		return
+	}
+	respByte, _ := json.Marshal(map[string]int64{"deleted": n})
+	w.Write(respByte)
+}
+
+func isValidStatus(s string) bool {
+	switch s {
+	case StatusPending, StatusProcessing, StatusDone, StatusFailed:
+		return true
+	}
+	return false
+}
+
+func parseIntParam(r *http.Request, name string, def int) (int, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i <= 0 {
+		return 0, errInvalidParam(name)
+	}
+	return i, nil
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	respByte, _ := json.Marshal(map[string]string{"error": msg})
+	w.Write(respByte)
+}
@@ -0,0 +1,143 @@
+package deadletter
+
+import (
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	maxBackoff = time.Hour
+	// stuckAfter is how long a letter can stay in processing before the retry is considered interrupted.
+	stuckAfter = 30 * time.Minute
+)
+
+// IsTransient returns true if err means the SDK could not be reached at all, so the operation
+// was never received by it and can be safely sent again. Timeouts and dropped connections
+// don't qualify since the operation might have gone through.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "connection refused")
+}
+
+// backoff returns the delay before the next retry of an operation which has been attempted the given number of times.
+func backoff(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 0; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Sender sends a queued operation to the SDK.
+type Sender func(l *Letter) (*jsonrpc.RPCResponse, error)
+
+// Worker retries queued operations which are due.
+type Worker struct {
+	db          boil.Executor
+	send        Sender
+	lock        func(userID int) (func(), error)
+	maxAttempts int
+	interval    time.Duration
+}
+
+// NewWorker creates a worker retrying operations up to maxAttempts times, with interval between the first attempts.
+func NewWorker(db boil.Executor, maxAttempts int, interval time.Duration) *Worker {
+	return &Worker{db: db, send: sendToSDK, lock: wallet.Lock, maxAttempts: maxAttempts, interval: interval}
+}
+
+// Run keeps retrying due operations every tick.
+func (w *Worker) Run(tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	for {
+		<-ticker.C
+		if n, err := failStuck(w.db, stuckAfter); err != nil {
+			logger.Log().Errorf("cannot check for interrupted retries: %v", err)
+		} else if n > 0 {
+			logger.Log().Warnf("%v interrupted retries flagged for manual review", n)
+		}
+		if _, err := w.ProcessDue(); err != nil {
+			logger.Log().Errorf("cannot process dead letters: %v", err)
+		}
+	}
+}
+
+// ProcessDue retries all operations which are due and returns the number of processed operations.
+func (w *Worker) ProcessDue() (int, error) {
+	var n int
+	for {
+		l, err := claimNext(w.db)
+		if err != nil {
+			return n, err
+		}
+		if l == nil {
+			return n, nil
+		}
+		if err := w.process(l); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+func (w *Worker) process(l *Letter) error {
+	log := logger.WithFields(logrus.Fields{"id": l.ID, "user_id": l.UserID, "method": l.Method, "attempt": l.Attempts})
+
+	// The retry mutates the wallet just like the original request did, so it must not run
+	// alongside the user's own operations.
+	release, err := w.lock(l.UserID)
+	if err != nil {
+		log.Infof("wallet is busy, postponing queued operation: %v", err)
+		return postpone(w.db, l, w.interval)
+	}
+	defer release()
+
+	res, err := w.send(l)
+	if err == nil && res.Error != nil {
+		err = errors.Err(res.Error.Message)
+	}
+
+	var status string
+	var retryIn time.Duration
+	switch {
+	case err == nil:
+		status = StatusDone
+		log.Info("queued operation retried successfully")
+	case IsTransient(err) && l.Attempts < w.maxAttempts:
+		status = StatusPending
+		retryIn = backoff(w.interval, l.Attempts)
+		log.Infof("queued operation failed again, retrying in %s: %v", retryIn, err)
+	default:
+		status = StatusFailed
+		log.Errorf("queued operation failed, flagged for manual review: %v", err)
+	}
+	metrics.ProxyDeadLetterRetryCount.WithLabelValues(l.Method, status).Inc()
+	return setStatus(w.db, l.ID, status, err, retryIn)
+}
+
+func sendToSDK(l *Letter) (*jsonrpc.RPCResponse, error) {
+	user, err := wallet.GetDBUserG(l.UserID)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	addr := sdkrouter.GetSDKAddress(user)
+	if addr == "" {
+		return nil, errors.Err("user %v has no sdk assigned", l.UserID)
+	}
+	return query.NewCaller(addr, l.UserID).Call(l.Request)
+}
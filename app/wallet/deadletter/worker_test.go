@@ -0,0 +1,66 @@
+package deadletter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, IsTransient(nil))
+	assert.True(t, IsTransient(errors.Err(syscall.ECONNREFUSED)))
+	assert.True(t, IsTransient(errors.Err(`Post "http://lbrynet:5279/": dial tcp 10.0.0.1:5279: connect: connection refused`)))
+	assert.False(t, IsTransient(errors.Err(syscall.ECONNRESET)))
+	assert.False(t, IsTransient(errors.Err("context deadline exceeded (Client.Timeout exceeded while awaiting headers)")))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(30*time.Second, 0))
+	assert.Equal(t, 60*time.Second, backoff(30*time.Second, 1))
+	assert.Equal(t, 4*time.Minute, backoff(30*time.Second, 3))
+	assert.Equal(t, maxBackoff, backoff(30*time.Second, 20))
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	req := jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0"})
+	k1, err := idempotencyKey(1, req)
+	require.NoError(t, err)
+	k2, err := idempotencyKey(1, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "1.0"}))
+	require.NoError(t, err)
+	assert.Equal(t, k1, k2)
+
+	for _, other := range []struct {
+		userID int
+		req    *jsonrpc.RPCRequest
+	}{
+		{2, req},
+		{1, jsonrpc.NewRequest("wallet_send", map[string]interface{}{"amount": "2.0"})},
+		{1, jsonrpc.NewRequest("support_create", map[string]interface{}{"amount": "1.0"})},
+	} {
+		k, err := idempotencyKey(other.userID, other.req)
+		require.NoError(t, err)
+		assert.NotEqual(t, k1, k)
+	}
+
+	// A repeated request gets a new JSON-RPC ID but is the same operation.
+	k, err := idempotencyKey(1, &jsonrpc.RPCRequest{Method: "wallet_send", Params: map[string]interface{}{"amount": "1.0"}, ID: 5})
+	require.NoError(t, err)
+	assert.Equal(t, k1, k)
+}
+
+func TestHandlers_AdminOnly(t *testing.T) {
+	for _, h := range []http.HandlerFunc{HandleList, HandlePurge} {
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letters", nil))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.JSONEq(t, `{"error": "admin token required"}`, rr.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// DeviceTokenPrefix marks tokens issued by IssueDeviceToken. They're checked against the database
+// instead of internal-apis.
+const DeviceTokenPrefix = "device-"
+
+// maxDeviceNameLength is how long a device name can be, longer ones are cut.
+const maxDeviceNameLength = 100
+
+// IsDeviceToken tells whether token has been issued by IssueDeviceToken.
+func IsDeviceToken(token string) bool {
+	return strings.HasPrefix(token, DeviceTokenPrefix)
+}
+
+// IssueDeviceToken creates a new token for a device of the user. The token authenticates as the same user
+// as any other token of theirs, shows up among their sessions under deviceName and can be revoked on its own.
+// The token is only returned here, the database keeps its hash.
+func IssueDeviceToken(exec boil.Executor, userID int, deviceName, ip string) (string, Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", Session{}, errors.Err(err)
+	}
+	token := DeviceTokenPrefix + hex.EncodeToString(b)
+	if r := []rune(deviceName); len(r) > maxDeviceNameLength {
+		deviceName = string(r[:maxDeviceNameLength])
+	}
+
+	now := time.Now().UTC()
+	m := &models.UserSession{
+		UserID:     userID,
+		TokenHash:  HashToken(token),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		LastIP:     ip,
+		DeviceName: deviceName,
+	}
+	if err := m.Insert(exec, boil.Infer()); err != nil {
+		return "", Session{}, errors.Err(err)
+	}
+	return token, toSession(m), nil
+}
+
+// getDeviceTokenUserID returns ID of the user a device token has been issued to.
+// It returns zero if there's no such token and ErrSessionRevoked if it has been revoked.
+func getDeviceTokenUserID(exec boil.Executor, tokenHash string) (int, error) {
+	m, err := models.UserSessions(models.UserSessionWhere.TokenHash.EQ(tokenHash)).One(exec)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Err(err)
+	}
+	if m.RevokedAt.Valid {
+		sessions.markRevoked(tokenHash, time.Now())
+		return 0, errors.Err(ErrSessionRevoked)
+	}
+	return m.UserID, nil
+}
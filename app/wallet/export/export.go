@@ -0,0 +1,196 @@
+// Package export serves encrypted backups of user wallets.
+//
+// The wallet is encrypted with the user-supplied password by the SDK node the user is assigned to,
+// and its response is relayed to the client as it arrives, so unencrypted wallet data never reaches this service.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/proxy"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/lbrynet"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	// AuditMethod is the method name wallet exports are recorded under in the query log.
+	AuditMethod = "wallet_export"
+
+	sdkMethod     = "sync_apply"
+	sdkTimeout    = 2 * time.Minute
+	maxRequestLen = 1 << 16
+	// peekLen is how much of the SDK response is inspected for errors before it's relayed to the client.
+	// SDK errors are always shorter than that.
+	peekLen = 1 << 16
+)
+
+var logger = monitor.NewModuleLogger("wallet_export")
+
+// logExport records an export attempt, replaced in tests.
+var logExport = audit.LogQuery
+
+// lockWallet and loadWallet prepare the wallet the same way proxied queries do, replaced in tests.
+var (
+	lockWallet = wallet.Lock
+	loadWallet = wallet.LoadWallet
+)
+
+type exportRequest struct {
+	Password string `json:"password"`
+	WalletID string `json:"wallet_id"`
+}
+
+// Exporter handles wallet export requests, limiting how often each user can make them.
+type Exporter struct {
+	client  *http.Client
+	limiter *limiter
+}
+
+// NewExporter returns an Exporter allowing each user limit exports per period.
+func NewExporter(limit int, period time.Duration) *Exporter {
+	return &Exporter{
+		client:  &http.Client{Timeout: sdkTimeout},
+		limiter: newLimiter(limit, period),
+	}
+}
+
+// Handle exports the wallet of the authenticated user encrypted with the password from the request body.
+// An optional `wallet_id` can be supplied but it must be the one belonging to the user.
+func (e *Exporter) Handle(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+
+	user, err := auth.FromRequest(r)
+	if authErr := proxy.GetAuthError(user, err); authErr != nil {
+		w.Write(rpcerrors.ErrorToJSON(authErr))
+		return
+	}
+	sdkAddress := sdkrouter.GetSDKAddress(user)
+	if sdkAddress == "" {
+		w.Write(rpcerrors.NewInternalError(errors.Err("user does not have sdk address assigned")).JSON())
+		return
+	}
+
+	var req exportRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestLen)).Decode(&req); err != nil || req.Password == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(rpcerrors.NewInvalidParamsError(errors.Err("password is required")).JSON())
+		return
+	}
+
+	// Trusted backend services are not subject to user-level limits.
+	if !auth.IsService(r) {
+		if ok, retryIn := e.limiter.allow(user.ID, time.Now()); !ok {
+			w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(retryIn.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write(rpcerrors.NewRateLimitedError(errors.Err("too many wallet export attempts")).JSON())
+			return
+		}
+	}
+
+	walletID := sdkrouter.WalletID(user.ID)
+	auditBody, _ := json.Marshal(map[string]string{"wallet_id": walletID})
+	remoteIP := ip.FromRequest(r)
+
+	if req.WalletID != "" && req.WalletID != walletID {
+		logger.Log().Warnf("user %v attempted to export wallet %v", user.ID, req.WalletID)
+		logExport(user.ID, remoteIP, AuditMethod, auditBody, audit.OutcomeError)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write(rpcerrors.NewForbiddenError(errors.Err("wallet does not belong to the user")).JSON())
+		return
+	}
+
+	release, err := lockWallet(user.ID)
+	if err != nil {
+		logExport(user.ID, remoteIP, AuditMethod, auditBody, audit.OutcomeError)
+		w.WriteHeader(http.StatusConflict)
+		w.Write(rpcerrors.NewWalletBusyError(err).JSON())
+		return
+	}
+	defer release()
+	if err := loadWallet(sdkAddress, user.ID); err != nil && !errors.Is(err, lbrynet.ErrWalletAlreadyLoaded) {
+		logger.WithFields(logrus.Fields{"wallet_id": walletID}).Errorf("cannot load wallet for export: %v", err)
+		logExport(user.ID, remoteIP, AuditMethod, auditBody, audit.OutcomeError)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(rpcerrors.NewSDKError(errors.Err("cannot load wallet")).JSON())
+		return
+	}
+
+	outcome := e.relay(w, sdkAddress, walletID, req.Password)
+	logExport(user.ID, remoteIP, AuditMethod, auditBody, outcome)
+}
+
+// relay requests the encrypted wallet from the SDK and copies the response to w without buffering it whole.
+// It returns the outcome of the export for the audit log.
+func (e *Exporter) relay(w http.ResponseWriter, sdkAddress, walletID, password string) string {
+	payload, err := json.Marshal(jsonrpc.NewRequest(sdkMethod, map[string]interface{}{
+		"wallet_id": walletID,
+		"password":  password,
+	}))
+	if err != nil {
+		w.Write(rpcerrors.NewInternalError(err).JSON())
+		return audit.OutcomeError
+	}
+
+	res, err := e.client.Post(sdkAddress, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		logger.WithFields(logrus.Fields{"wallet_id": walletID}).Errorf("wallet export request failed: %v", err)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(rpcerrors.NewSDKError(errors.Err("wallet server is unavailable")).JSON())
+		return audit.OutcomeError
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		logger.WithFields(logrus.Fields{"wallet_id": walletID}).Errorf("wallet export failed with sdk status %v", res.StatusCode)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(rpcerrors.NewSDKError(errors.Err("wallet server responded with status %v", res.StatusCode)).JSON())
+		return audit.OutcomeError
+	}
+
+	body := bufio.NewReaderSize(res.Body, peekLen)
+	head, err := body.Peek(peekLen)
+	if err != nil && err != io.EOF {
+		logger.WithFields(logrus.Fields{"wallet_id": walletID}).Errorf("cannot read wallet export response: %v", err)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write(rpcerrors.NewSDKError(errors.Err("cannot read wallet server response")).JSON())
+		return audit.OutcomeError
+	}
+	if len(head) < peekLen {
+		var rpcRes jsonrpc.RPCResponse
+		if err := json.Unmarshal(head, &rpcRes); err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write(rpcerrors.NewSDKError(errors.Err("malformed wallet server response")).JSON())
+			return audit.OutcomeError
+		}
+		if rpcRes.Error != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write(rpcerrors.NewSDKError(errors.Err(rpcRes.Error.Message)).JSON())
+			return audit.OutcomeError
+		}
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="wallet.json"`)
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := io.Copy(w, body); err != nil {
+		logger.WithFields(logrus.Fields{"wallet_id": walletID}).Errorf("wallet export interrupted: %v", err)
+		return audit.OutcomeError
+	}
+	return audit.OutcomeSuccess
+}
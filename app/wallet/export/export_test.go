@@ -0,0 +1,265 @@
+package export
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditRecord struct {
+	userID  int
+	body    string
+	outcome string
+}
+
+func captureAudit(t *testing.T) *[]auditRecord {
+	t.Helper()
+	var records []auditRecord
+	orig := logExport
+	logExport = func(userID int, remoteIP string, method string, body []byte, outcome string) *models.QueryLog {
+		assert.Equal(t, AuditMethod, method)
+		records = append(records, auditRecord{userID, string(body), outcome})
+		return nil
+	}
+	t.Cleanup(func() { logExport = orig })
+	return &records
+}
+
+// stubWallet replaces wallet locking and loading, returning the list of users whose wallets were loaded.
+func stubWallet(t *testing.T, lockErr error) *[]int {
+	t.Helper()
+	var loaded []int
+	origLock, origLoad := lockWallet, loadWallet
+	lockWallet = func(userID int) (func(), error) {
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		return func() {}, nil
+	}
+	loadWallet = func(addr string, userID int) error {
+		loaded = append(loaded, userID)
+		return nil
+	}
+	t.Cleanup(func() { lockWallet, loadWallet = origLock, origLoad })
+	return &loaded
+}
+
+func serveExport(t *testing.T, e *Exporter, sdkAddress, body string, authenticated bool) *httptest.ResponseRecorder {
+	t.Helper()
+	r, err := http.NewRequest(http.MethodPost, "/api/v1/wallet/export", strings.NewReader(body))
+	require.NoError(t, err)
+	if authenticated {
+		r.Header.Set(wallet.TokenHeader, "export-token")
+	}
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 42}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Address: sdkAddress}
+		return u, nil
+	}
+	rr := httptest.NewRecorder()
+	middleware.Apply(auth.Middleware(provider), e.Handle).ServeHTTP(rr, r)
+	return rr
+}
+
+func sdkServer(t *testing.T, response string, received *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, sdkMethod, req.Method)
+		if received != nil {
+			*received = req.Params
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestHandle(t *testing.T) {
+	records := captureAudit(t)
+	loaded := stubWallet(t, nil)
+	var params map[string]interface{}
+	ts := sdkServer(t, `{"jsonrpc": "2.0", "result": {"hash": "abc", "data": "encrypted"}, "id": 0}`, &params)
+
+	rr := serveExport(t, NewExporter(3, time.Hour), ts.URL, `{"password": "secret"}`, true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"hash": "abc", "data": "encrypted"}, "id": 0}`, rr.Body.String())
+
+	assert.Equal(t, map[string]interface{}{"password": "secret", "wallet_id": sdkrouter.WalletID(42)}, params)
+	require.Len(t, *records, 1)
+	assert.Equal(t, 42, (*records)[0].userID)
+	assert.Equal(t, audit.OutcomeSuccess, (*records)[0].outcome)
+	assert.NotContains(t, (*records)[0].body, "secret")
+	assert.Equal(t, []int{42}, *loaded)
+}
+
+func TestHandle_WalletBusy(t *testing.T) {
+	records := captureAudit(t)
+	stubWallet(t, errors.Err("wallet is busy"))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("sdk should not be called")
+	}))
+	defer ts.Close()
+
+	rr := serveExport(t, NewExporter(3, time.Hour), ts.URL, `{"password": "secret"}`, true)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "wallet is busy")
+	require.Len(t, *records, 1)
+	assert.Equal(t, audit.OutcomeError, (*records)[0].outcome)
+}
+
+func TestHandle_StreamsLargeExports(t *testing.T) {
+	captureAudit(t)
+	stubWallet(t, nil)
+	data := strings.Repeat("x", 5*peekLen)
+	resp := `{"jsonrpc": "2.0", "result": {"hash": "abc", "data": "` + data + `"}, "id": 0}`
+	ts := sdkServer(t, resp, nil)
+
+	rr := serveExport(t, NewExporter(3, time.Hour), ts.URL, `{"password": "secret"}`, true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, resp, rr.Body.String())
+}
+
+func TestHandle_SDKError(t *testing.T) {
+	records := captureAudit(t)
+	stubWallet(t, nil)
+	ts := sdkServer(t, `{"jsonrpc": "2.0", "error": {"code": -32500, "message": "wallet is locked"}, "id": 0}`, nil)
+
+	rr := serveExport(t, NewExporter(3, time.Hour), ts.URL, `{"password": "secret"}`, true)
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "wallet is locked")
+	assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	require.Len(t, *records, 1)
+	assert.Equal(t, audit.OutcomeError, (*records)[0].outcome)
+}
+
+func TestHandle_SDKUnavailable(t *testing.T) {
+	records := captureAudit(t)
+	stubWallet(t, nil)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	rr := serveExport(t, NewExporter(3, time.Hour), ts.URL, `{"password": "secret"}`, true)
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	require.Len(t, *records, 1)
+	assert.Equal(t, audit.OutcomeError, (*records)[0].outcome)
+}
+
+func TestHandle_Rejected(t *testing.T) {
+	records := captureAudit(t)
+	stubWallet(t, nil)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("sdk should not be called")
+	}))
+	defer ts.Close()
+	e := NewExporter(3, time.Hour)
+
+	rr := serveExport(t, e, ts.URL, `{"password": "secret"}`, false)
+	assert.Contains(t, rr.Body.String(), `"code": -32084`)
+
+	rr = serveExport(t, e, ts.URL, `{}`, true)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "password is required")
+
+	rr = serveExport(t, e, ts.URL, `{"password": "secret", "wallet_id": "`+sdkrouter.WalletID(43)+`"}`, true)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	require.Len(t, *records, 1)
+	assert.Equal(t, audit.OutcomeError, (*records)[0].outcome)
+}
+
+func TestHandle_RateLimited(t *testing.T) {
+	captureAudit(t)
+	stubWallet(t, nil)
+	ts := sdkServer(t, `{"jsonrpc": "2.0", "result": {}, "id": 0}`, nil)
+	e := NewExporter(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		rr := serveExport(t, e, ts.URL, `{"password": "secret"}`, true)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	rr := serveExport(t, e, ts.URL, `{"password": "secret"}`, true)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "3600", rr.Header().Get("Retry-After"))
+	body, _ := ioutil.ReadAll(rr.Body)
+	assert.Contains(t, string(body), `"code": -32094`)
+}
+
+func TestHandle_ServiceNotRateLimited(t *testing.T) {
+	config.Override("ServiceSecrets", map[string]string{"comments": "comment-secret"})
+	defer config.RestoreOverridden()
+	captureAudit(t)
+	stubWallet(t, nil)
+	ts := sdkServer(t, `{"jsonrpc": "2.0", "result": {}, "id": 0}`, nil)
+	e := NewExporter(1, time.Hour)
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 42}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Address: ts.URL}
+		return u, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		body := `{"password": "secret"}`
+		now := time.Now().Unix()
+		r, err := http.NewRequest(http.MethodPost, "/api/v1/wallet/export", strings.NewReader(body))
+		require.NoError(t, err)
+		r.Header.Set(wallet.TokenHeader, "export-token")
+		r.Header.Set(auth.ServiceNameHeader, "comments")
+		r.Header.Set(auth.ServiceTimestampHeader, strconv.FormatInt(now, 10))
+		nonce := strconv.FormatInt(time.Now().UnixNano(), 10)
+		r.Header.Set(auth.ServiceNonceHeader, nonce)
+		r.Header.Set(auth.ServiceSignatureHeader, auth.SignServiceRequest("comment-secret", http.MethodPost, "/api/v1/wallet/export", "", now, nonce, []byte(body)))
+		rr := httptest.NewRecorder()
+		middleware.Apply(middleware.Chain(auth.ServiceMiddleware, auth.Middleware(provider)), e.Handle).ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(2, time.Minute)
+	now := time.Now()
+
+	ok, _ := l.allow(1, now)
+	assert.True(t, ok)
+	ok, _ = l.allow(1, now.Add(10*time.Second))
+	assert.True(t, ok)
+	ok, retryIn := l.allow(1, now.Add(20*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryIn)
+
+	ok, _ = l.allow(2, now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	ok, _ = l.allow(1, now.Add(time.Minute))
+	assert.True(t, ok)
+
+	l.allow(3, now.Add(3*time.Minute))
+	assert.NotContains(t, l.attempts, 1)
+	assert.NotContains(t, l.attempts, 2)
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter allows a fixed number of attempts per user within a sliding time window.
+type limiter struct {
+	limit  int
+	period time.Duration
+
+	mu        sync.Mutex
+	attempts  map[int][]time.Time
+	lastSweep time.Time
+}
+
+func newLimiter(limit int, period time.Duration) *limiter {
+	return &limiter{limit: limit, period: period, attempts: map[int][]time.Time{}}
+}
+
+// allow registers an attempt of the user and returns true if it fits into the limit.
+// Otherwise it returns false along with the time left until the next attempt is allowed.
+func (l *limiter) allow(userID int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > l.period {
+		for id := range l.attempts {
+			l.prune(id, now)
+		}
+		l.lastSweep = now
+	}
+
+	recent := l.prune(userID, now)
+	if len(recent) >= l.limit {
+		return false, recent[0].Add(l.period).Sub(now)
+	}
+	l.attempts[userID] = append(recent, now)
+	return true, 0
+}
+
+// prune drops attempts of the user which are out of the window and returns the rest.
+func (l *limiter) prune(userID int, now time.Time) []time.Time {
+	ts := l.attempts[userID]
+	i := 0
+	for i < len(ts) && now.Sub(ts[i]) >= l.period {
+		i++
+	}
+	ts = ts[i:]
+	if len(ts) == 0 {
+		delete(l.attempts, userID)
+		return nil
+	}
+	l.attempts[userID] = ts
+	return ts
+}
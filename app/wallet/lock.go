@@ -0,0 +1,35 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/userlock"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// locks keeps users from running wallet-mutating operations concurrently, e.g. double-clicking send.
+var locks = userlock.New()
+
+// Lock locks userID for a wallet-mutating operation on this instance and,
+// when WalletLockShared is on, on all API instances. Every path that mutates a wallet
+// (proxied queries, background retries, exports) should hold it.
+func Lock(userID int) (func(), error) {
+	start := time.Now()
+	wait, maxHold := config.GetWalletLockWait(), config.GetWalletLockMaxHold()
+	release, err := locks.Acquire(userID, wait, maxHold)
+	if err != nil || !config.IsWalletLockShared() {
+		return release, err
+	}
+	sharedRelease, err := userlock.NewDBLocker(boil.GetDB(), config.IsWalletLockFailOpen()).
+		Acquire(userID, wait-time.Since(start), maxHold)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return func() {
+		sharedRelease()
+		release()
+	}, nil
+}
@@ -0,0 +1,263 @@
+// Package rebalance evens out the number of wallets assigned to SDK servers.
+package rebalance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+var logger = monitor.NewModuleLogger("rebalance")
+
+// AuditMethodMigrate is the method name wallet migrations are recorded under in the query log.
+const AuditMethodMigrate = "wallet_migrate"
+
+// migration moves a wallet between servers identified by their IDs.
+type migration struct {
+	from, to int
+}
+
+// planMigrations returns migrations which even out the numbers of wallets assigned to servers, as long as
+// the most loaded server has more than threshold above the average and at most max of them.
+func planMigrations(counts map[int]int, threshold float64, max int) []migration {
+	if len(counts) < 2 {
+		return nil
+	}
+	ids := make([]int, 0, len(counts))
+	left := make(map[int]int, len(counts))
+	total := 0
+	for id, n := range counts {
+		ids = append(ids, id)
+		left[id] = n
+		total += n
+	}
+	sort.Ints(ids)
+	avg := float64(total) / float64(len(ids))
+
+	var plan []migration
+	for len(plan) < max {
+		most, least := ids[0], ids[0]
+		for _, id := range ids {
+			if left[id] > left[most] {
+				most = id
+			}
+			if left[id] < left[least] {
+				least = id
+			}
+		}
+		if float64(left[most]) <= avg*(1+threshold) || left[most]-left[least] <= 1 {
+			break
+		}
+		plan = append(plan, migration{from: most, to: least})
+		left[most]--
+		left[least]++
+	}
+	return plan
+}
+
+// imbalance returns the ratio of the highest number of wallets per server to the average.
+func imbalance(counts map[int]int) float64 {
+	var total, most int
+	for _, n := range counts {
+		total += n
+		if n > most {
+			most = n
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(most) / (float64(total) / float64(len(counts)))
+}
+
+// Rebalancer moves wallets from SDK servers which have many more users assigned than the others.
+//
+// Only wallets of idle users, which have been unloaded by the wallet tracker, are moved, and the user's wallet lock
+// is held during the move so no operations are in flight. The user is reassigned the same way as when their
+// server is drained: the wallet is created on the new server which takes over from then on.
+type Rebalancer struct {
+	db     boil.Executor
+	rt     *sdkrouter.Router
+	lock   func(userID int) (func(), error)
+	create func(serverAddress string, userID int) error
+}
+
+// New creates a rebalancer for servers of rt.
+func New(db boil.Executor, rt *sdkrouter.Router) *Rebalancer {
+	return &Rebalancer{db: db, rt: rt, lock: wallet.Lock, create: wallet.Create}
+}
+
+// Run rebalances wallets every WalletRebalancing.Interval while it's enabled.
+func (r *Rebalancer) Run() {
+	for {
+		cfg := config.GetWalletRebalancing()
+		interval := cfg.Interval
+		if interval <= 0 {
+			interval = 10 * time.Minute
+		}
+		time.Sleep(interval)
+		if !cfg.Enabled {
+			continue
+		}
+		if n, err := r.Rebalance(cfg.Threshold, cfg.MaxMigrations); err != nil {
+			logger.Log().Errorf("cannot rebalance wallets: %v", err)
+		} else if n > 0 {
+			logger.Log().Infof("rebalancing moved %v wallets", n)
+		}
+	}
+}
+
+// Rebalance moves up to max wallets between servers in rotation and returns the number of moved wallets.
+func (r *Rebalancer) Rebalance(threshold float64, max int) (int, error) {
+	servers := map[int]*models.LbrynetServer{}
+	for _, s := range r.rt.GetAll() {
+		if s.ID == 0 || s.Private || r.rt.IsDraining(s.Address) || r.rt.IsQuarantined(s.Address) {
+			continue
+		}
+		servers[s.ID] = s
+	}
+	counts, err := r.countAssigned(servers)
+	if err != nil {
+		return 0, err
+	}
+	metrics.LbrynetWalletImbalance.Set(imbalance(counts))
+
+	plan := planMigrations(counts, threshold, max)
+	metrics.LbrynetWalletMigrationsPending.Set(float64(len(plan)))
+	defer metrics.LbrynetWalletMigrationsPending.Set(0)
+
+	candidates := map[int][]*models.User{}
+	moved := 0
+	for _, m := range plan {
+		if _, ok := candidates[m.from]; !ok {
+			candidates[m.from], err = r.idleUsers(m.from, 2*len(plan))
+			if err != nil {
+				return moved, err
+			}
+		}
+		for len(candidates[m.from]) > 0 {
+			user := candidates[m.from][0]
+			candidates[m.from] = candidates[m.from][1:]
+			ok, err := r.migrate(user.ID, servers[m.from], servers[m.to])
+			if err != nil {
+				logger.WithFields(logrus.Fields{"user_id": user.ID}).Errorf("cannot migrate wallet: %v", err)
+				metrics.LbrynetWalletMigrations.WithLabelValues("failed").Inc()
+				continue
+			}
+			if ok {
+				moved++
+				metrics.LbrynetWalletMigrations.WithLabelValues("moved").Inc()
+				break
+			}
+			metrics.LbrynetWalletMigrations.WithLabelValues("skipped").Inc()
+		}
+		metrics.LbrynetWalletMigrationsPending.Dec()
+	}
+	return moved, nil
+}
+
+// countAssigned returns the number of users assigned to each of servers.
+func (r *Rebalancer) countAssigned(servers map[int]*models.LbrynetServer) (map[int]int, error) {
+	q := fmt.Sprintf(`SELECT "%s", COUNT(*) FROM "%s" WHERE "%s" IS NOT NULL GROUP BY 1`,
+		models.UserColumns.LbrynetServerID,
+		models.TableNames.Users,
+		models.UserColumns.LbrynetServerID,
+	)
+	rows, err := r.db.Query(q)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int, len(servers))
+	for id := range servers {
+		counts[id] = 0
+	}
+	for rows.Next() {
+		var id, n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, errors.Err(err)
+		}
+		if s, ok := servers[id]; ok {
+			counts[id] = n
+			metrics.LbrynetWalletsAssigned.WithLabelValues(s.Address).Set(float64(n))
+		}
+	}
+	return counts, errors.Err(rows.Err())
+}
+
+// idleUsers returns up to limit users assigned to the server whose wallets are not loaded.
+func (r *Rebalancer) idleUsers(serverID, limit int) ([]*models.User, error) {
+	users, err := models.Users(
+		models.UserWhere.LbrynetServerID.EQ(null.IntFrom(serverID)),
+		models.UserWhere.LastSeenAt.IsNull(),
+		qm.OrderBy(models.UserColumns.ID),
+		qm.Limit(limit),
+	).All(r.db)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	return users, nil
+}
+
+// migrate moves the wallet of an idle user to another server. It returns false if the user has become active
+// or has been reassigned in the meantime, which leaves the wallet where it is.
+func (r *Rebalancer) migrate(userID int, from, to *models.LbrynetServer) (bool, error) {
+	release, err := r.lock(userID)
+	if err != nil {
+		return false, nil
+	}
+	defer release()
+
+	// atomic update. it checks that the user is still idle and assigned to the same server
+	q := fmt.Sprintf(`UPDATE "%s" SET "%s" = $1 WHERE "%s" = $2 AND "%s" = $3 AND "%s" IS NULL`,
+		models.TableNames.Users,
+		models.UserColumns.LbrynetServerID,
+		models.UserColumns.ID,
+		models.UserColumns.LbrynetServerID,
+		models.UserColumns.LastSeenAt,
+	)
+	result, err := r.db.Exec(q, to.ID, userID, from.ID)
+	if err != nil {
+		return false, errors.Err(err)
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return false, errors.Err(err)
+	} else if count == 0 {
+		return false, nil
+	}
+
+	log := logger.WithFields(logrus.Fields{"user_id": userID})
+	body, _ := json.Marshal(map[string]string{"from": from.Name, "to": to.Name})
+	if err := r.create(to.Address, userID); err != nil {
+		q := fmt.Sprintf(`UPDATE "%s" SET "%s" = $1 WHERE "%s" = $2 AND "%s" = $3`,
+			models.TableNames.Users,
+			models.UserColumns.LbrynetServerID,
+			models.UserColumns.ID,
+			models.UserColumns.LbrynetServerID,
+		)
+		if _, rerr := r.db.Exec(q, from.ID, userID, to.ID); rerr != nil {
+			log.Errorf("cannot revert assignment to sdk %s: %v", from.Name, rerr)
+		}
+		audit.LogQuery(userID, "", AuditMethodMigrate, body, audit.OutcomeError)
+		return false, err
+	}
+	audit.LogQuery(userID, "", AuditMethodMigrate, body, audit.OutcomeSuccess)
+	log.Infof("user %d: wallet migrated from sdk %s to %s (%s)", userID, from.Name, to.Name, to.Address)
+	return true, nil
+}
@@ -0,0 +1,34 @@
+package rebalance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanMigrations(t *testing.T) {
+	counts := map[int]int{1: 100, 2: 40, 3: 70}
+	plan := planMigrations(counts, 0.2, 10)
+	assert.Len(t, plan, 10)
+	for _, m := range plan {
+		assert.Equal(t, 1, m.from)
+		assert.Equal(t, 2, m.to)
+	}
+	assert.Equal(t, map[int]int{1: 100, 2: 40, 3: 70}, counts, "counts are not modified")
+
+	// Moves stop once the most loaded server is within the threshold
+	plan = planMigrations(map[int]int{1: 100, 2: 40, 3: 70}, 0.2, 100)
+	assert.Len(t, plan, 16)
+	assert.Equal(t, migration{1, 2}, plan[0])
+
+	assert.Empty(t, planMigrations(map[int]int{1: 11, 2: 10}, 0, 10))
+	assert.Empty(t, planMigrations(map[int]int{1: 100, 2: 90}, 0.2, 10))
+	assert.Empty(t, planMigrations(map[int]int{1: 100}, 0.2, 10))
+	assert.Empty(t, planMigrations(map[int]int{1: 100, 2: 0}, 0.2, 0))
+}
+
+func TestImbalance(t *testing.T) {
+	assert.Equal(t, 1.0, imbalance(map[int]int{1: 0, 2: 0}))
+	assert.Equal(t, 1.0, imbalance(map[int]int{1: 10, 2: 10}))
+	assert.InDelta(t, 1.43, imbalance(map[int]int{1: 100, 2: 40, 3: 70}), 0.01)
+}
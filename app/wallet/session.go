@@ -0,0 +1,288 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+var (
+	// ErrSessionRevoked is returned when authenticating with a token the user has revoked.
+	ErrSessionRevoked = errors.Base("session has been revoked")
+	// ErrSessionExpired is returned when authenticating with a token which has worked before but is now rejected by internal-apis.
+	ErrSessionExpired = errors.Base("session has expired")
+	// ErrSessionNotFound is returned when revoking a session which doesn't exist, belongs to another user or is already revoked.
+	ErrSessionNotFound = errors.Base("session not found")
+)
+
+// Session is a token a user has authenticated with. Tokens themselves are never stored, only their hashes.
+type Session struct {
+	ID         int       `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	LastIP     string    `json:"last_ip"`
+	// DeviceName is set for sessions of device tokens, see IssueDeviceToken.
+	DeviceName string `json:"device_name,omitempty"`
+	// Current is true for the session the listing has been requested with.
+	Current bool `json:"current"`
+	// TokenHash identifies the session internally and is never sent to clients.
+	TokenHash string `json:"-"`
+}
+
+// HashToken returns the hash sessions are identified by.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+type sessionUse struct {
+	userID int
+	ip     string
+	at     time.Time
+}
+
+// sessionTracker keeps recent session uses in memory until they're flushed to the database,
+// so authentication doesn't wait for a write. It also knows which tokens have been revoked recently
+// for as long as they could still be in the token cache.
+type sessionTracker struct {
+	mu       sync.Mutex
+	pending  map[string]sessionUse
+	revoked  map[string]time.Time
+	lastSync time.Time
+}
+
+var sessions = newSessionTracker()
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{
+		pending: map[string]sessionUse{},
+		revoked: map[string]time.Time{},
+	}
+}
+
+func (t *sessionTracker) touch(tokenHash string, userID int, ip string, now time.Time) {
+	t.mu.Lock()
+	t.pending[tokenHash] = sessionUse{userID: userID, ip: ip, at: now}
+	t.mu.Unlock()
+}
+
+func (t *sessionTracker) markRevoked(tokenHash string, now time.Time) {
+	t.mu.Lock()
+	t.revoked[tokenHash] = now.Add(ttlConfirmed)
+	delete(t.pending, tokenHash)
+	t.mu.Unlock()
+}
+
+func (t *sessionTracker) isRevoked(tokenHash string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	until, ok := t.revoked[tokenHash]
+	return ok && now.Before(until)
+}
+
+// takePending returns session uses recorded since the last call and forgets revocations which no longer matter.
+func (t *sessionTracker) takePending(now time.Time) map[string]sessionUse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending := t.pending
+	t.pending = map[string]sessionUse{}
+	for h, until := range t.revoked {
+		if !now.Before(until) {
+			delete(t.revoked, h)
+		}
+	}
+	return pending
+}
+
+func (t *sessionTracker) pendingUse(tokenHash string) (sessionUse, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.pending[tokenHash]
+	return u, ok
+}
+
+// restore puts back session uses which couldn't be saved, unless the session has been used again
+// or revoked since they were taken.
+func (t *sessionTracker) restore(uses map[string]sessionUse, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for h, u := range uses {
+		if _, ok := t.pending[h]; ok {
+			continue
+		}
+		if until, ok := t.revoked[h]; ok && now.Before(until) {
+			continue
+		}
+		t.pending[h] = u
+	}
+}
+
+// FlushSessions saves session uses recorded in memory and learns about sessions revoked
+// through other instances since the previous flush. Uses which fail to be saved are kept for the next flush.
+func FlushSessions(exec boil.Executor) error {
+	now := time.Now()
+	pending := sessions.takePending(now)
+	failed := map[string]sessionUse{}
+	var saveErr error
+	for h, u := range pending {
+		// A model upsert can't skip revoked sessions, so this one is written by hand
+		_, err := exec.Exec(`
+			INSERT INTO "user_sessions" ("user_id", "token_hash", "created_at", "last_used_at", "last_ip")
+			VALUES ($1, $2, $3, $3, $4)
+			ON CONFLICT ("token_hash") DO UPDATE SET "last_used_at" = EXCLUDED."last_used_at", "last_ip" = EXCLUDED."last_ip"
+			WHERE "user_sessions"."revoked_at" IS NULL`,
+			u.userID, h, u.at.UTC(), u.ip,
+		)
+		if err != nil {
+			failed[h] = u
+			saveErr = err
+		}
+	}
+	if len(failed) > 0 {
+		sessions.restore(failed, now)
+		saveErr = errors.Err("cannot save %v of %v session uses: %v", len(failed), len(pending), saveErr)
+	}
+
+	sessions.mu.Lock()
+	since := sessions.lastSync
+	sessions.mu.Unlock()
+	if since.IsZero() {
+		// Tokens revoked earlier than that can't be in the token cache anymore
+		since = now.Add(-ttlConfirmed)
+	}
+	revoked, err := models.UserSessions(
+		qm.Select(models.UserSessionColumns.TokenHash),
+		models.UserSessionWhere.RevokedAt.GT(null.TimeFrom(since.UTC())),
+	).All(exec)
+	if err != nil {
+		return errors.Err(err)
+	}
+	for _, s := range revoked {
+		sessions.markRevoked(s.TokenHash, now)
+	}
+	sessions.mu.Lock()
+	// Revocations made while the query ran are picked up next time
+	sessions.lastSync = now.Add(-time.Second)
+	sessions.mu.Unlock()
+	return saveErr
+}
+
+// RunSessionFlusher calls FlushSessions every interval, it should be run in a goroutine.
+func RunSessionFlusher(exec boil.Executor, interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := FlushSessions(exec); err != nil {
+			logger.Log().Errorf("cannot flush sessions: %v", err)
+		}
+	}
+}
+
+// ListSessions returns active sessions of the user, most recently used first.
+// Uses which haven't been flushed yet are taken into account.
+func ListSessions(exec boil.Executor, userID int) ([]Session, error) {
+	ms, err := models.UserSessions(
+		models.UserSessionWhere.UserID.EQ(userID),
+		models.UserSessionWhere.RevokedAt.IsNull(),
+	).All(exec)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+
+	list := make([]Session, 0, len(ms))
+	for _, m := range ms {
+		s := toSession(m)
+		if u, ok := sessions.pendingUse(s.TokenHash); ok && u.at.After(s.LastUsedAt) {
+			s.LastUsedAt, s.LastIP = u.at.UTC(), u.ip
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastUsedAt.After(list[j].LastUsedAt) })
+	return list, nil
+}
+
+func toSession(m *models.UserSession) Session {
+	return Session{
+		ID:         m.ID,
+		CreatedAt:  m.CreatedAt,
+		LastUsedAt: m.LastUsedAt,
+		LastIP:     m.LastIP,
+		DeviceName: m.DeviceName,
+		TokenHash:  m.TokenHash,
+	}
+}
+
+// MarkCurrentSession sets Current for the session of token.
+func MarkCurrentSession(list []Session, token string) {
+	h := HashToken(token)
+	for i := range list {
+		list[i].Current = list[i].TokenHash == h
+	}
+}
+
+// RevokeSession revokes a session of the user. The token stops working on this instance immediately
+// and on other ones after their next FlushSessions.
+func RevokeSession(exec boil.Executor, userID, sessionID int) error {
+	mods := []qm.QueryMod{
+		models.UserSessionWhere.ID.EQ(sessionID),
+		models.UserSessionWhere.UserID.EQ(userID),
+		models.UserSessionWhere.RevokedAt.IsNull(),
+	}
+	m, err := models.UserSessions(mods...).One(exec)
+	if err == sql.ErrNoRows {
+		return errors.Err(ErrSessionNotFound)
+	} else if err != nil {
+		return errors.Err(err)
+	}
+	// The session could have been revoked concurrently since it's been found
+	n, err := models.UserSessions(mods...).UpdateAll(exec, models.M{
+		models.UserSessionColumns.RevokedAt: null.TimeFrom(time.Now().UTC()),
+	})
+	if err != nil {
+		return errors.Err(err)
+	} else if n == 0 {
+		return errors.Err(ErrSessionNotFound)
+	}
+	sessions.markRevoked(m.TokenHash, time.Now())
+	return nil
+}
+
+// isKnownSession tells whether the token has been used successfully before and hasn't been revoked.
+func isKnownSession(exec boil.Executor, tokenHash string) (bool, error) {
+	if _, ok := sessions.pendingUse(tokenHash); ok {
+		return true, nil
+	}
+	known, err := models.UserSessions(
+		models.UserSessionWhere.TokenHash.EQ(tokenHash),
+		models.UserSessionWhere.RevokedAt.IsNull(),
+	).Exists(exec)
+	if err != nil {
+		return false, errors.Err(err)
+	}
+	return known, nil
+}
+
+// checkSessionRevoked returns ErrSessionRevoked if the token has been revoked according to the database.
+// It's only consulted when the token isn't in the token cache, cached tokens are checked with the tracker.
+func checkSessionRevoked(exec boil.Executor, tokenHash string) error {
+	revoked, err := models.UserSessions(
+		models.UserSessionWhere.TokenHash.EQ(tokenHash),
+		models.UserSessionWhere.RevokedAt.IsNotNull(),
+	).Exists(exec)
+	if err != nil {
+		return errors.Err(err)
+	}
+	if revoked {
+		sessions.markRevoked(tokenHash, time.Now())
+		return errors.Err(ErrSessionRevoked)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTracker(t *testing.T) {
+	now := time.Now()
+	tr := newSessionTracker()
+	tr.touch("a", 1, "8.8.8.8", now.Add(-time.Second))
+	tr.touch("a", 1, "1.1.1.1", now)
+	tr.touch("b", 2, "8.8.8.8", now)
+
+	u, ok := tr.pendingUse("a")
+	assert.True(t, ok)
+	assert.Equal(t, sessionUse{1, "1.1.1.1", now}, u)
+
+	tr.markRevoked("b", now)
+	assert.True(t, tr.isRevoked("b", now))
+	assert.True(t, tr.isRevoked("b", now.Add(ttlConfirmed-time.Second)))
+	assert.False(t, tr.isRevoked("a", now))
+
+	pending := tr.takePending(now)
+	assert.Equal(t, map[string]sessionUse{"a": {1, "1.1.1.1", now}}, pending, "revoked sessions are not saved")
+	assert.Empty(t, tr.takePending(now))
+
+	// Revocations are forgotten once the token can't be in the token cache anymore
+	tr.takePending(now.Add(ttlConfirmed))
+	assert.False(t, tr.isRevoked("b", now.Add(ttlConfirmed)))
+	assert.Empty(t, tr.revoked)
+}
+
+func TestMarkCurrentSession(t *testing.T) {
+	list := []Session{{ID: 1, TokenHash: HashToken("one")}, {ID: 2, TokenHash: HashToken("two")}}
+	MarkCurrentSession(list, "two")
+	assert.False(t, list[0].Current)
+	assert.True(t, list[1].Current)
+	assert.NotEqual(t, "two", HashToken("two"))
+	assert.Len(t, HashToken("two"), 64)
+}
+
+// failingSessionDB fails to save uses of sessions in failing and has no revocations to report.
+type failingSessionDB struct {
+	failing map[string]bool
+	saved   []string
+}
+
+func (db *failingSessionDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	h := args[1].(string)
+	if db.failing[h] {
+		return nil, errors.Err("insert failed")
+	}
+	db.saved = append(db.saved, h)
+	return nil, nil
+}
+
+func (db *failingSessionDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.Err("no revocations")
+}
+
+func (db *failingSessionDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestFlushSessionsKeepsFailedUses(t *testing.T) {
+	orig := sessions
+	defer func() { sessions = orig }()
+	sessions = newSessionTracker()
+
+	now := time.Now()
+	for i, h := range []string{"a", "b", "c"} {
+		sessions.touch(h, i+1, "8.8.8.8", now)
+	}
+	db := &failingSessionDB{failing: map[string]bool{"b": true}}
+	err := FlushSessions(db)
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{"a", "c"}, db.saved, "other uses are saved despite the failure")
+
+	u, ok := sessions.pendingUse("b")
+	assert.True(t, ok, "failed use is kept for the next flush")
+	assert.Equal(t, sessionUse{2, "8.8.8.8", now}, u)
+	_, ok = sessions.pendingUse("a")
+	assert.False(t, ok)
+
+	// Newer uses and revocations are not overwritten by failed ones
+	sessions.touch("a", 1, "1.1.1.1", now.Add(time.Second))
+	sessions.markRevoked("c", now)
+	sessions.restore(map[string]sessionUse{"a": {1, "8.8.8.8", now}, "c": {3, "8.8.8.8", now}}, now)
+	u, _ = sessions.pendingUse("a")
+	assert.Equal(t, "1.1.1.1", u.ip)
+	_, ok = sessions.pendingUse("c")
+	assert.False(t, ok)
+}
@@ -0,0 +1,134 @@
+// Package sessions lets users see the tokens they're signed in with, issue tokens for their devices and revoke them.
+package sessions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	"github.com/gorilla/mux"
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// AuditMethod is the method name session revocations are recorded under in the query log.
+const AuditMethod = "session_revoke"
+
+// AuditMethodIssue is the method name device token issuance is recorded under in the query log.
+const AuditMethodIssue = "session_issue"
+
+var logger = monitor.NewModuleLogger("sessions")
+
+// Database operations, replaced in tests.
+var (
+	listSessions = func(userID int) ([]wallet.Session, error) {
+		return wallet.ListSessions(boil.GetDB(), userID)
+	}
+	revokeSession = func(userID, sessionID int) error {
+		return wallet.RevokeSession(boil.GetDB(), userID, sessionID)
+	}
+	issueDeviceToken = func(userID int, deviceName, ip string) (string, wallet.Session, error) {
+		return wallet.IssueDeviceToken(boil.GetDB(), userID, deviceName, ip)
+	}
+	logAction = audit.LogQuery
+)
+
+// HandleList responds with active sessions of the authenticated user.
+func HandleList(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	user, err := auth.FromRequest(r)
+	if err != nil || user == nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	list, err := listSessions(user.ID)
+	if err != nil {
+		logger.Log().Errorf("cannot list sessions of user %v: %v", user.ID, err)
+		writeError(w, http.StatusInternalServerError, "cannot list sessions")
+		return
+	}
+	wallet.MarkCurrentSession(list, r.Header.Get(wallet.TokenHeader))
+	b, _ := json.Marshal(map[string]interface{}{"sessions": list})
+	w.Write(b)
+}
+
+// HandleRevoke revokes a session of the authenticated user, the session ID comes from the URL.
+// Users can revoke the session they're making the request with, too.
+func HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	user, err := auth.FromRequest(r)
+	if err != nil || user == nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || sessionID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	body, _ := json.Marshal(map[string]int{"session_id": sessionID})
+	err = revokeSession(user.ID, sessionID)
+	if errors.Is(err, wallet.ErrSessionNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		logger.Log().Errorf("cannot revoke session %v of user %v: %v", sessionID, user.ID, err)
+		logAction(user.ID, ip.FromRequest(r), AuditMethod, body, audit.OutcomeError)
+		writeError(w, http.StatusInternalServerError, "cannot revoke session")
+		return
+	}
+	logAction(user.ID, ip.FromRequest(r), AuditMethod, body, audit.OutcomeSuccess)
+	logger.Log().Infof("user %v revoked session %v", user.ID, sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleIssue issues a new token for a device of the authenticated user, the device name comes from the JSON body.
+// The token is only ever sent in this response.
+func HandleIssue(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	user, err := auth.FromRequest(r)
+	if err != nil || user == nil {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	var req struct {
+		DeviceName string `json:"device_name"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	deviceName := strings.TrimSpace(req.DeviceName)
+	if err != nil || deviceName == "" {
+		writeError(w, http.StatusBadRequest, "device_name is required")
+		return
+	}
+
+	remoteIP := ip.FromRequest(r)
+	body, _ := json.Marshal(map[string]string{"device_name": deviceName})
+	token, session, err := issueDeviceToken(user.ID, deviceName, remoteIP)
+	if err != nil {
+		logger.Log().Errorf("cannot issue device token for user %v: %v", user.ID, err)
+		logAction(user.ID, remoteIP, AuditMethodIssue, body, audit.OutcomeError)
+		writeError(w, http.StatusInternalServerError, "cannot issue token")
+		return
+	}
+	logAction(user.ID, remoteIP, AuditMethodIssue, body, audit.OutcomeSuccess)
+	logger.Log().Infof("user %v issued token for device session %v", user.ID, session.ID)
+	b, _ := json.Marshal(map[string]interface{}{"token": token, "session": session})
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	b, _ := json.Marshal(map[string]string{"error": msg})
+	w.Write(b)
+}
@@ -0,0 +1,168 @@
+package sessions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type revocation struct {
+	userID, sessionID int
+}
+
+type auditRecord struct {
+	userID  int
+	body    string
+	outcome string
+}
+
+// stubStorage replaces database operations with ones working on sessions of user 42.
+// Both revocation and token issuance fail with revokeErr if it's set.
+func stubStorage(t *testing.T, list []wallet.Session, revokeErr error) (*[]revocation, *[]auditRecord) {
+	t.Helper()
+	var revoked []revocation
+	var records []auditRecord
+	origList, origRevoke, origIssue, origLog := listSessions, revokeSession, issueDeviceToken, logAction
+	listSessions = func(userID int) ([]wallet.Session, error) {
+		assert.Equal(t, 42, userID)
+		return list, nil
+	}
+	revokeSession = func(userID, sessionID int) error {
+		if revokeErr != nil {
+			return revokeErr
+		}
+		revoked = append(revoked, revocation{userID, sessionID})
+		return nil
+	}
+	issueDeviceToken = func(userID int, deviceName, ip string) (string, wallet.Session, error) {
+		if revokeErr != nil {
+			return "", wallet.Session{}, revokeErr
+		}
+		return wallet.DeviceTokenPrefix + "new", wallet.Session{ID: 8, DeviceName: deviceName, TokenHash: "secret"}, nil
+	}
+	logAction = func(userID int, remoteIP string, method string, body []byte, outcome string) *models.QueryLog {
+		assert.Contains(t, []string{AuditMethod, AuditMethodIssue}, method)
+		records = append(records, auditRecord{userID, string(body), outcome})
+		return nil
+	}
+	t.Cleanup(func() {
+		listSessions, revokeSession, issueDeviceToken, logAction = origList, origRevoke, origIssue, origLog
+	})
+	return &revoked, &records
+}
+
+func serve(t *testing.T, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveBody(t, method, path, token, "")
+}
+
+func serveBody(t *testing.T, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	provider := func(token, ip string) (*models.User, error) {
+		return &models.User{ID: 42}, nil
+	}
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(provider))
+	router.HandleFunc("/sessions", HandleList).Methods(http.MethodGet)
+	router.HandleFunc("/sessions", HandleIssue).Methods(http.MethodPost)
+	router.HandleFunc("/sessions/{id:[0-9]+}", HandleRevoke).Methods(http.MethodDelete)
+
+	r, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		r.Header.Set(wallet.TokenHeader, token)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, r)
+	return rr
+}
+
+func TestHandleList(t *testing.T) {
+	used := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	stubStorage(t, []wallet.Session{
+		{ID: 2, CreatedAt: used, LastUsedAt: used, LastIP: "8.8.8.8", TokenHash: wallet.HashToken("current")},
+		{ID: 1, CreatedAt: used, LastUsedAt: used.Add(-time.Hour), LastIP: "1.1.1.1", TokenHash: wallet.HashToken("other")},
+	}, nil)
+
+	rr := serve(t, http.MethodGet, "/sessions", "current")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), wallet.HashToken("current"))
+
+	var res struct {
+		Sessions []map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res.Sessions, 2)
+	assert.Equal(t, map[string]interface{}{
+		"id": 2.0, "created_at": "2021-03-01T12:00:00Z", "last_used_at": "2021-03-01T12:00:00Z", "last_ip": "8.8.8.8", "current": true,
+	}, res.Sessions[0])
+	assert.Equal(t, false, res.Sessions[1]["current"])
+}
+
+func TestHandleRevoke(t *testing.T) {
+	revoked, records := stubStorage(t, nil, nil)
+
+	rr := serve(t, http.MethodDelete, "/sessions/7", "current")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, []revocation{{42, 7}}, *revoked)
+	assert.Equal(t, []auditRecord{{42, `{"session_id":7}`, audit.OutcomeSuccess}}, *records)
+}
+
+func TestHandleRevoke_Errors(t *testing.T) {
+	_, records := stubStorage(t, nil, errors.Err(wallet.ErrSessionNotFound))
+	rr := serve(t, http.MethodDelete, "/sessions/7", "current")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Empty(t, *records)
+
+	_, records = stubStorage(t, nil, errors.Err("db is down"))
+	rr = serve(t, http.MethodDelete, "/sessions/7", "current")
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, []auditRecord{{42, `{"session_id":7}`, audit.OutcomeError}}, *records)
+
+	rr = serve(t, http.MethodDelete, "/sessions/7", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr = serve(t, http.MethodGet, "/sessions", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestHandleIssue(t *testing.T) {
+	_, records := stubStorage(t, nil, nil)
+
+	rr := serveBody(t, http.MethodPost, "/sessions", "current", `{"device_name": " Living room TV "}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+	var res struct {
+		Token   string
+		Session map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, wallet.DeviceTokenPrefix+"new", res.Token)
+	assert.Equal(t, 8.0, res.Session["id"])
+	assert.Equal(t, "Living room TV", res.Session["device_name"])
+	assert.Equal(t, []auditRecord{{42, `{"device_name":"Living room TV"}`, audit.OutcomeSuccess}}, *records)
+
+	for _, body := range []string{"", `{"device_name": " "}`, `{"device_name": 1}`} {
+		rr = serveBody(t, http.MethodPost, "/sessions", "current", body)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+	rr = serveBody(t, http.MethodPost, "/sessions", "", `{"device_name": "phone"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	_, records = stubStorage(t, nil, errors.Err("db is down"))
+	rr = serveBody(t, http.MethodPost, "/sessions", "current", `{"device_name": "phone"}`)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, []auditRecord{{42, `{"device_name":"phone"}`, audit.OutcomeError}}, *records)
+}
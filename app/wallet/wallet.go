@@ -15,6 +15,7 @@ import (
 	"github.com/lbryio/lbrytv/models"
 
 	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+	"github.com/lbryio/lbry.go/v2/extras/lbryinc"
 
 	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
@@ -37,31 +38,58 @@ const (
 	txMaxRetries                  = 2
 )
 
-// GetUserWithSDKServer gets user by internal-apis auth token. If the user does not have a
-// wallet yet, they are assigned an SDK and a wallet is created for them on that SDK.
+// GetUserWithSDKServer gets user by internal-apis auth token or a device token (see IssueDeviceToken).
+// If the user does not have a wallet yet, they are assigned an SDK and a wallet is created for them on that SDK.
 func GetUserWithSDKServer(rt *sdkrouter.Router, internalAPIHost, token, metaRemoteIP string) (*models.User, error) {
 	var localUser *models.User
 	log := logger.WithFields(logrus.Fields{monitor.TokenF: token, "ip": metaRemoteIP})
 
+	tokenHash := HashToken(token)
+	if sessions.isRevoked(tokenHash, time.Now()) {
+		return nil, errors.Err(ErrSessionRevoked)
+	}
+
 	user, err := currentCache.get(token, func() (interface{}, error) {
-		remoteUser, err := getRemoteUser(internalAPIHost, token, metaRemoteIP)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
-		if !remoteUser.HasVerifiedEmail {
-			return nil, nil
+		var (
+			remoteUserID int
+			err          error
+		)
+		if IsDeviceToken(token) {
+			userID, err := getDeviceTokenUserID(storage.Conn.DB.DB, tokenHash)
+			if err != nil || userID == 0 {
+				return nil, err
+			}
+			remoteUserID = userID
+			log.Data["device_token"] = true
+		} else {
+			if err := checkSessionRevoked(storage.Conn.DB.DB, tokenHash); err != nil {
+				return nil, err
+			}
+			remoteUser, err := getRemoteUser(internalAPIHost, token, metaRemoteIP)
+			if err != nil {
+				log.Error(err)
+				if errors.As(err, &lbryinc.APIError{}) {
+					if known, kerr := isKnownSession(storage.Conn.DB.DB, tokenHash); kerr == nil && known {
+						return nil, errors.Err(ErrSessionExpired)
+					}
+				}
+				return nil, err
+			}
+			if !remoteUser.HasVerifiedEmail {
+				return nil, nil
+			}
+			remoteUserID = remoteUser.ID
+			log.Data["has_email"] = remoteUser.HasVerifiedEmail
 		}
 
-		log.Data["remote_user_id"] = remoteUser.ID
-		log.Data["has_email"] = remoteUser.HasVerifiedEmail
+		log.Data["remote_user_id"] = remoteUserID
 		log.Debugf("user authenticated")
 
 		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancelFn()
 
 		err = inTx(ctx, storage.Conn.DB.DB, func(tx *sql.Tx) error {
-			localUser, err = getOrCreateLocalUser(tx, remoteUser.ID, log)
+			localUser, err = getOrCreateLocalUser(tx, remoteUserID, log)
 			if err != nil {
 				return err
 			}
@@ -71,6 +99,11 @@ func GetUserWithSDKServer(rt *sdkrouter.Router, internalAPIHost, token, metaRemo
 				if err != nil {
 					return err
 				}
+			} else if current := sdkrouter.GetLbrynetServer(localUser); current != nil && rt.IsDraining(current.Address) {
+				err := reassignSDKServerToUser(tx, localUser, current, rt.LeastLoaded(), log)
+				if err != nil {
+					return err
+				}
 			}
 			return nil
 		})
@@ -85,6 +118,9 @@ func GetUserWithSDKServer(rt *sdkrouter.Router, internalAPIHost, token, metaRemo
 	// 	currentCache.set(token, localUser)
 	// }
 
+	if err == nil && user != nil {
+		sessions.touch(tokenHash, user.ID, metaRemoteIP, time.Now())
+	}
 	return user, err
 }
 
@@ -257,6 +293,53 @@ func assignSDKServerToUser(exec boil.Executor, user *models.User, server *models
 	return nil
 }
 
+// reassignSDKServerToUser moves user off the current sdk, which is being drained, to server.
+// The wallet is created on the new sdk the same way as on the first assignment.
+func reassignSDKServerToUser(exec boil.Executor, user *models.User, current, server *models.LbrynetServer, log *logrus.Entry) error {
+	op := metrics.StartOperation("db", "update_user")
+	defer op.End()
+
+	if server == nil || server.ID == 0 || server.ID == current.ID {
+		// nowhere to move to, the user keeps using the draining sdk
+		return nil
+	}
+
+	// atomic update. it checks that the user is still assigned to the draining server
+	q := fmt.Sprintf(`UPDATE "%s" SET "%s" = $1 WHERE "%s" = $2 and "%s" = $3`,
+		models.TableNames.Users,
+		models.UserColumns.LbrynetServerID,
+		models.UserColumns.ID,
+		models.UserColumns.LbrynetServerID,
+	)
+	result, err := exec.Exec(q, server.ID, user.ID, current.ID)
+	if err != nil {
+		return errors.Err(err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return errors.Err(err)
+	}
+	if count == 0 {
+		// reassigned by another request already
+		if err := user.Reload(exec); err != nil {
+			return errors.Err(err)
+		}
+	} else {
+		user.LbrynetServerID.SetValid(server.ID)
+	}
+
+	srv, err := user.LbrynetServer().One(exec)
+	if err != nil {
+		return errors.Err(err)
+	}
+	user.R.LbrynetServer = srv
+	if count == 0 {
+		return nil
+	}
+	log.Infof("user %d: reassigned from draining sdk %s to %s (%s)", user.ID, current.Name, server.Name, server.Address)
+	return Create(server.Address, user.ID)
+}
+
 // Create creates a wallet on an sdk that can be immediately used in subsequent commands.
 // It can recover from errors like existing wallets, but if a wallet is known to exist
 // (eg. a wallet ID stored in the database already), loadWallet() should be called instead.
@@ -154,6 +154,32 @@ func TestGetUserWithWallet_NonexistentUser(t *testing.T) {
 	assert.EqualError(t, err, "api error: could not authenticate user")
 }
 
+func TestGetUserWithWallet_ExpiredSession(t *testing.T) {
+	setupTest()
+	storage.Conn.Truncate([]string{"user_sessions"})
+	srv := test.RandServerAddress(t)
+	rt := sdkrouter.New(map[string]string{"a": srv})
+	url, cleanup := dummyAPI(srv)
+	defer cleanup()
+
+	_, err := GetUserWithSDKServer(rt, url, "abc", "")
+	require.NoError(t, err)
+	require.NoError(t, FlushSessions(boil.GetDB()))
+	currentCache.flush()
+
+	ts := test.MockHTTPServer(nil)
+	defer ts.Close()
+	rejected := `{"success": false, "error": "could not authenticate user", "data": null}`
+
+	// Token which has been used before is expired, an unknown one is invalid
+	ts.NextResponse <- rejected
+	_, err = GetUserWithSDKServer(rt, ts.URL, "abc", "")
+	assert.ErrorIs(t, err, ErrSessionExpired)
+	ts.NextResponse <- rejected
+	_, err = GetUserWithSDKServer(rt, ts.URL, "xyz", "")
+	assert.EqualError(t, err, "api error: could not authenticate user")
+}
+
 func TestGetUserWithWallet_ExistingUser(t *testing.T) {
 	setupTest()
 	srv := test.RandServerAddress(t)
@@ -196,6 +222,62 @@ func TestGetUserWithWallet_CachedUser(t *testing.T) {
 	assert.Equal(t, metricValue+1, metrics.GetCounterValue(metrics.AuthTokenCacheHits))
 }
 
+func TestGetUserWithWallet_DeviceTokens(t *testing.T) {
+	setupTest()
+	storage.Conn.Truncate([]string{"user_sessions"})
+	srv := test.RandServerAddress(t)
+	rt := sdkrouter.New(map[string]string{"a": srv})
+	url, cleanup := dummyAPI(srv)
+	defer cleanup()
+
+	u, err := GetUserWithSDKServer(rt, url, "abc", "")
+	require.NoError(t, err)
+	require.NotNil(t, u)
+
+	phone, phoneSession, err := IssueDeviceToken(boil.GetDB(), u.ID, "phone", "8.8.8.8")
+	require.NoError(t, err)
+	laptop, _, err := IssueDeviceToken(boil.GetDB(), u.ID, "laptop", "8.8.8.8")
+	require.NoError(t, err)
+	assert.NotEqual(t, phone, laptop)
+	assert.True(t, IsDeviceToken(phone))
+
+	// Device tokens are not checked with internal-apis
+	for _, token := range []string{phone, laptop} {
+		du, err := GetUserWithSDKServer(rt, "http://localhost:1", token, "")
+		require.NoError(t, err)
+		require.NotNil(t, du)
+		assert.Equal(t, u.ID, du.ID)
+	}
+	du, err := GetUserWithSDKServer(rt, "http://localhost:1", DeviceTokenPrefix+"unknown", "")
+	require.NoError(t, err)
+	assert.Nil(t, du)
+
+	list, err := ListSessions(boil.GetDB(), u.ID)
+	require.NoError(t, err)
+	names := []string{}
+	for _, s := range list {
+		names = append(names, s.DeviceName)
+	}
+	assert.ElementsMatch(t, []string{"phone", "laptop"}, names)
+
+	require.NoError(t, RevokeSession(boil.GetDB(), u.ID, phoneSession.ID))
+	for _, flush := range []bool{false, true} {
+		if flush {
+			// Revocation is still known once the token has left the cache
+			currentCache.flush()
+			sessions = newSessionTracker()
+		}
+		_, err = GetUserWithSDKServer(rt, "http://localhost:1", phone, "")
+		assert.ErrorIs(t, err, ErrSessionRevoked)
+		du, err = GetUserWithSDKServer(rt, "http://localhost:1", laptop, "")
+		require.NoError(t, err)
+		assert.Equal(t, u.ID, du.ID)
+		du, err = GetUserWithSDKServer(rt, url, "abc", "")
+		require.NoError(t, err)
+		assert.Equal(t, u.ID, du.ID)
+	}
+}
+
 func TestGetUserWithWallet_ExistingUserWithoutSDKGetsAssignedOneOnRetrieve(t *testing.T) {
 	setupTest()
 	userID := rand.Intn(999999)
@@ -0,0 +1,77 @@
+package walletevents
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/proxy"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/responses"
+)
+
+const defaultWait = 30 * time.Second
+
+type pollResponse struct {
+	Events  []Event `json:"events"`
+	Timeout bool    `json:"timeout"`
+}
+
+// Handle waits for the next wallet events of the authenticated user and returns them.
+// If nothing happens within the time specified by `timeout` query parameter (in seconds),
+// an empty list of events is returned with `timeout` set to true.
+func (h *Hub) Handle(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+
+	user, err := auth.FromRequest(r)
+	if authErr := proxy.GetAuthError(user, err); authErr != nil {
+		w.Write(rpcerrors.ErrorToJSON(authErr))
+		return
+	}
+	sdkAddress := sdkrouter.GetSDKAddress(user)
+	if sdkAddress == "" {
+		w.Write(rpcerrors.NewInternalError(errors.Err("user does not have sdk address assigned")).JSON())
+		return
+	}
+
+	wait := defaultWait
+	if v := r.FormValue("timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(rpcerrors.NewInvalidParamsError(errors.Err("invalid timeout value")).JSON())
+			return
+		}
+		wait = time.Duration(secs) * time.Second
+	}
+	if max := config.GetWalletEventsMaxWait(); wait > max {
+		wait = max
+	}
+
+	events, cancel := h.Subscribe(user.ID, sdkAddress)
+	defer cancel()
+
+	metrics.LbrytvWalletEventsWaiting.Inc()
+	defer metrics.LbrytvWalletEventsWaiting.Dec()
+
+	resp := pollResponse{Events: []Event{}}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case evs := <-events:
+		resp.Events = evs
+	case <-timer.C:
+		resp.Timeout = true
+	case <-r.Context().Done():
+		return
+	}
+
+	b, _ := json.Marshal(resp)
+	w.Write(b)
+}
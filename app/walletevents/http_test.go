@@ -0,0 +1,84 @@
+package walletevents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveEvents(t *testing.T, h *Hub, target string, authenticated bool) *httptest.ResponseRecorder {
+	t.Helper()
+	r, err := http.NewRequest(http.MethodGet, target, nil)
+	require.NoError(t, err)
+	if authenticated {
+		r.Header.Set(wallet.TokenHeader, "events-token")
+	}
+	provider := func(token, ip string) (*models.User, error) {
+		u := &models.User{ID: 42}
+		u.R = u.R.NewStruct()
+		u.R.LbrynetServer = &models.LbrynetServer{Address: "http://sdk"}
+		return u, nil
+	}
+	rr := httptest.NewRecorder()
+	middleware.Apply(auth.Middleware(provider), h.Handle).ServeHTTP(rr, r)
+	return rr
+}
+
+func TestHandleAuthRequired(t *testing.T) {
+	h := NewHub((&fakeWallet{}).fetch, time.Millisecond)
+	rr := serveEvents(t, h, "/api/v1/wallet/events", false)
+	assert.Contains(t, rr.Body.String(), `"code": -32084`)
+	assert.Equal(t, 0, h.Watching())
+}
+
+func TestHandleTimeout(t *testing.T) {
+	config.Override("WalletEventsMaxWait", "50ms")
+	defer config.RestoreOverridden()
+
+	h := NewHub((&fakeWallet{}).fetch, 5*time.Millisecond)
+	start := time.Now()
+	rr := serveEvents(t, h, "/api/v1/wallet/events?timeout=30", true)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp pollResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.True(t, resp.Timeout)
+	assert.Empty(t, resp.Events)
+}
+
+func TestHandleEvent(t *testing.T) {
+	fw := &fakeWallet{}
+	fw.set("1.0", "abc")
+	h := NewHub(fw.fetch, 5*time.Millisecond)
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		fw.set("1.0", "def")
+	}()
+	rr := serveEvents(t, h, "/api/v1/wallet/events?timeout=5", true)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp pollResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.False(t, resp.Timeout)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, EventTransaction, resp.Events[0].Type)
+}
+
+func TestHandleInvalidTimeout(t *testing.T) {
+	h := NewHub((&fakeWallet{}).fetch, time.Millisecond)
+	rr := serveEvents(t, h, "/api/v1/wallet/events?timeout=soon", true)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
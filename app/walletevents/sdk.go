@@ -0,0 +1,47 @@
+package walletevents
+
+import (
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+const methodTransactionList = "transaction_list"
+
+// SDKFetcher retrieves wallet balance and the latest transaction of the user via query.Caller.
+func SDKFetcher(userID int, sdkAddress string) (*Snapshot, error) {
+	c := query.NewCaller(sdkAddress, userID)
+	snap := &Snapshot{}
+
+	res, err := c.Call(jsonrpc.NewRequest(query.MethodWalletBalance))
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.Err(res.Error.Message)
+	}
+	if err := res.GetObject(&snap.Balance); err != nil {
+		return nil, errors.Err(err)
+	}
+
+	res, err = c.Call(jsonrpc.NewRequest(methodTransactionList, map[string]interface{}{"page": 1, "page_size": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.Err(res.Error.Message)
+	}
+	var txList struct {
+		Items []struct {
+			TxID string `json:"txid"`
+		} `json:"items"`
+	}
+	if err := res.GetObject(&txList); err != nil {
+		return nil, errors.Err(err)
+	}
+	if len(txList.Items) > 0 {
+		snap.LastTxID = txList.Items[0].TxID
+	}
+	return snap, nil
+}
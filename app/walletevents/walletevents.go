@@ -0,0 +1,180 @@
+// Package walletevents lets clients wait for changes in their wallets (incoming transactions,
+// balance changes) instead of repeatedly polling the SDK themselves.
+// Wallets are polled internally only while there are clients waiting for their events.
+package walletevents
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+)
+
+const (
+	EventBalanceChanged = "balance_changed"
+	EventTransaction    = "transaction"
+)
+
+var logger = monitor.NewModuleLogger("walletevents")
+
+// defaultLinger is how long a wallet keeps being polled after its last waiting client is gone,
+// so events occurring between consecutive long-poll requests are not missed.
+const defaultLinger = time.Minute
+
+// maxPendingEvents is how many events are kept for a wallet while no client is waiting for them,
+// older ones are dropped first.
+const maxPendingEvents = 100
+
+// Event is a single change in the user's wallet.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Snapshot is the wallet state compared between polls to detect events.
+type Snapshot struct {
+	Balance  map[string]interface{}
+	LastTxID string
+}
+
+// Fetcher retrieves the current wallet state of the user from the SDK.
+type Fetcher func(userID int, sdkAddress string) (*Snapshot, error)
+
+// Hub polls wallets and fans out their events to waiting clients.
+type Hub struct {
+	fetch    Fetcher
+	interval time.Duration
+	linger   time.Duration
+
+	mu       sync.Mutex
+	watchers map[int]*watcher
+}
+
+type watcher struct {
+	userID      int
+	sdkAddress  string
+	subscribers map[chan []Event]struct{}
+	idleSince   time.Time
+	last        *Snapshot
+	// pending are events which have occurred while no client was waiting, they go to the next subscriber.
+	pending []Event
+}
+
+// NewHub creates a Hub which polls wallets of waiting users every interval.
+func NewHub(fetch Fetcher, interval time.Duration) *Hub {
+	return &Hub{
+		fetch:    fetch,
+		interval: interval,
+		linger:   defaultLinger,
+		watchers: map[int]*watcher{},
+	}
+}
+
+// Subscribe registers the caller for the next batch of wallet events of the user.
+// The returned channel receives a single batch of events, right away if some have occurred
+// since the previous client stopped waiting.
+// cancel must be called when the caller is no longer waiting.
+func (h *Hub) Subscribe(userID int, sdkAddress string) (<-chan []Event, func()) {
+	ch := make(chan []Event, 1)
+
+	h.mu.Lock()
+	w, ok := h.watchers[userID]
+	if !ok {
+		w = &watcher{userID: userID, sdkAddress: sdkAddress, subscribers: map[chan []Event]struct{}{}}
+		h.watchers[userID] = w
+		metrics.LbrytvWalletEventsWatchers.Inc()
+		go h.watch(w)
+	}
+	if len(w.pending) > 0 {
+		ch <- w.pending
+		w.pending = nil
+		w.idleSince = time.Now()
+	} else {
+		w.subscribers[ch] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := w.subscribers[ch]; ok {
+			delete(w.subscribers, ch)
+			if len(w.subscribers) == 0 {
+				w.idleSince = time.Now()
+			}
+		}
+	}
+	return ch, cancel
+}
+
+// Watching returns the number of wallets currently being polled.
+func (h *Hub) Watching() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.watchers)
+}
+
+func (h *Hub) watch(w *watcher) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.poll(w)
+		<-ticker.C
+
+		h.mu.Lock()
+		if len(w.subscribers) == 0 && time.Since(w.idleSince) > h.linger {
+			delete(h.watchers, w.userID)
+			h.mu.Unlock()
+			metrics.LbrytvWalletEventsWatchers.Dec()
+			return
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *Hub) poll(w *watcher) {
+	snap, err := h.fetch(w.userID, w.sdkAddress)
+	if err != nil {
+		logger.Log().Warnf("error polling wallet events for user %v: %v", w.userID, err)
+		return
+	}
+	events := diff(w.last, snap)
+	w.last = snap
+	if len(events) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(w.subscribers) == 0 {
+		w.pending = append(w.pending, events...)
+		if len(w.pending) > maxPendingEvents {
+			w.pending = w.pending[len(w.pending)-maxPendingEvents:]
+		}
+		return
+	}
+	for ch := range w.subscribers {
+		ch <- events
+		delete(w.subscribers, ch)
+	}
+	w.idleSince = time.Now()
+}
+
+// diff returns events which have occurred between two snapshots.
+// No events are returned for the first snapshot as there's nothing to compare it to.
+func diff(prev, cur *Snapshot) []Event {
+	var events []Event
+	if prev == nil || cur == nil {
+		return events
+	}
+	if cur.LastTxID != "" && cur.LastTxID != prev.LastTxID {
+		events = append(events, Event{Type: EventTransaction, Data: map[string]string{"txid": cur.LastTxID}})
+	}
+	if !reflect.DeepEqual(prev.Balance, cur.Balance) {
+		events = append(events, Event{Type: EventBalanceChanged, Data: cur.Balance})
+	}
+	return events
+}
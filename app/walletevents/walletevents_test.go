@@ -0,0 +1,159 @@
+package walletevents
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWallet struct {
+	mu    sync.Mutex
+	snap  Snapshot
+	calls int
+}
+
+func (f *fakeWallet) set(balance, txid string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snap = Snapshot{Balance: map[string]interface{}{"available": balance}, LastTxID: txid}
+}
+
+func (f *fakeWallet) fetch(userID int, sdkAddress string) (*Snapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	s := f.snap
+	return &s, nil
+}
+
+func (f *fakeWallet) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestHubDeliversEvents(t *testing.T) {
+	fw := &fakeWallet{}
+	fw.set("1.0", "abc")
+	h := NewHub(fw.fetch, 10*time.Millisecond)
+
+	events, cancel := h.Subscribe(1, "")
+	defer cancel()
+	otherEvents, otherCancel := h.Subscribe(1, "")
+	defer otherCancel()
+	assert.Equal(t, 1, h.Watching())
+
+	// Let the baseline snapshot be taken
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case <-events:
+		t.Fatal("no events expected before wallet changes")
+	default:
+	}
+
+	fw.set("2.0", "def")
+	for _, ch := range []<-chan []Event{events, otherEvents} {
+		select {
+		case evs := <-ch:
+			require.Len(t, evs, 2)
+			assert.Equal(t, EventTransaction, evs[0].Type)
+			assert.Equal(t, map[string]string{"txid": "def"}, evs[0].Data)
+			assert.Equal(t, EventBalanceChanged, evs[1].Type)
+			assert.Equal(t, map[string]interface{}{"available": "2.0"}, evs[1].Data)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
+
+func TestHubKeepsEventsBetweenSubscriptions(t *testing.T) {
+	fw := &fakeWallet{}
+	fw.set("1.0", "abc")
+	h := NewHub(fw.fetch, 5*time.Millisecond)
+
+	_, cancel := h.Subscribe(1, "")
+	// Let the baseline snapshot be taken
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	fw.set("1.0", "def")
+	time.Sleep(20 * time.Millisecond)
+	fw.set("2.0", "def")
+	time.Sleep(20 * time.Millisecond)
+
+	events, cancel := h.Subscribe(1, "")
+	defer cancel()
+	select {
+	case evs := <-events:
+		require.Len(t, evs, 2)
+		assert.Equal(t, EventTransaction, evs[0].Type)
+		assert.Equal(t, EventBalanceChanged, evs[1].Type)
+	default:
+		t.Fatal("events occurred between subscriptions have been lost")
+	}
+
+	// Events are only delivered once
+	again, cancelAgain := h.Subscribe(1, "")
+	defer cancelAgain()
+	select {
+	case <-again:
+		t.Fatal("no events expected")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestHubStopsPollingIdleWallets(t *testing.T) {
+	fw := &fakeWallet{}
+	h := NewHub(fw.fetch, 5*time.Millisecond)
+	h.linger = 20 * time.Millisecond
+	_, cancel := h.Subscribe(1, "")
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	assert.Eventually(t, func() bool { return h.Watching() == 0 }, time.Second, 5*time.Millisecond)
+	calls := fw.callCount()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls, fw.callCount())
+}
+
+func TestDiff(t *testing.T) {
+	s1 := &Snapshot{Balance: map[string]interface{}{"total": "1.0"}, LastTxID: "a"}
+	s2 := &Snapshot{Balance: map[string]interface{}{"total": "1.0"}, LastTxID: "a"}
+	s3 := &Snapshot{Balance: map[string]interface{}{"total": "0.5"}, LastTxID: "a"}
+
+	assert.Empty(t, diff(nil, s1))
+	assert.Empty(t, diff(s1, s2))
+	evs := diff(s2, s3)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventBalanceChanged, evs[0].Type)
+}
+
+func TestSDKFetcher(t *testing.T) {
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+
+	srv.QueueResponses(
+		`{"jsonrpc": "2.0", "result": {"available": "1.5", "total": "2.0"}, "id": 0}`,
+		`{"jsonrpc": "2.0", "result": {"items": [{"txid": "f00d"}], "page": 1, "page_size": 1}, "id": 0}`,
+	)
+
+	snap, err := SDKFetcher(123, srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "f00d", snap.LastTxID)
+	assert.EqualValues(t, "1.5", snap.Balance["available"])
+
+	req := <-reqChan
+	assert.Contains(t, req.Body, query.MethodWalletBalance)
+	assert.Contains(t, req.Body, fmt.Sprintf(`"wallet_id":"%v"`, sdkrouter.WalletID(123)))
+	req = <-reqChan
+	assert.Contains(t, req.Body, methodTransactionList)
+}
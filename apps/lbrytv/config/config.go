@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -28,23 +30,139 @@ var (
 )
 
 func init() {
-	Config = cfg.ReadConfig(configName)
-	c := Config
-	c.Viper.SetConfigName(configName)
+	Config = cfg.ReadConfig(configName, setDefaults)
+}
+
+// setDefaults is applied to settings every time they're (re)loaded.
+func setDefaults(v *viper.Viper) {
+	v.SetEnvPrefix("LW")
+	v.SetDefault("Debug", false)
 
-	c.Viper.SetEnvPrefix("LW")
-	c.Viper.SetDefault("Debug", false)
+	v.BindEnv("Debug")
+	v.BindEnv("Lbrynet")
+	v.BindEnv("SentryDSN")
+	v.BindEnv("DatabaseDSN")
+	v.BindEnv("AdminToken")
 
-	c.Viper.BindEnv("Debug")
-	c.Viper.BindEnv("Lbrynet")
-	c.Viper.BindEnv("SentryDSN")
-	c.Viper.BindEnv("DatabaseDSN")
+	v.SetDefault("Address", ":8080")
+	v.SetDefault("Host", "http://localhost:8080")
+	v.SetDefault("FreeContentURL", "http://localhost:8080/content/")
+	v.SetDefault("ReflectorTimeout", int64(10))
+	v.SetDefault("RefractorTimeout", int64(10))
+	v.SetDefault("MaintenanceRetryAfter", "5m")
+	v.SetDefault("PreserveJSONNumbers", true)
+	v.SetDefault("StrictRequestJSON", false)
+	v.SetDefault("ClientIdentityHeader", "User-Agent")
+	v.SetDefault("WalletEventsPollInterval", "5s")
+	v.SetDefault("WalletEventsMaxWait", "60s")
+	v.SetDefault("SDKHealthCheckInterval", "5s")
+	v.SetDefault("SDKRetryBackoff", "100ms")
+	v.SetDefault("SDKRetryMaxBackoff", "2s")
+	v.SetDefault("SDKSlowStart", 0)
+	v.SetDefault("CachePinsRefreshInterval", "1m")
+	v.SetDefault("ServiceSignatureMaxAge", "5m")
+	v.SetDefault("ServiceNonceRequired", true)
+	v.SetDefault("ServiceNonceShared", true)
+	v.SetDefault("CacheableMethods", map[string]string{"resolve": "3m", "claim_search": "3m"})
+	v.SetDefault("AdaptiveCacheTTLMin", "1m")
+	v.SetDefault("AdaptiveCacheTTLMax", "30m")
+	v.SetDefault("ResolveNotFoundTTL", "30s")
+	v.SetDefault("CacheMethodPriorities", map[string]float64{})
+	v.SetDefault("CacheSnapshotPath", "")
+	v.SetDefault("CacheSnapshotInterval", "5m")
+	v.SetDefault("ClaimSearchEnrichment", map[string]interface{}{"Enabled": true, "MaxItems": 50, "MaxQueued": 0})
+	v.SetDefault("CacheShadow", map[string]interface{}{"Primary": "", "Shadow": "", "QueueSize": 10000, "Workers": 4})
+	v.SetDefault("DeadLetterMethods", []string{"wallet_send", "support_create"})
+	v.SetDefault("DeadLetterMaxAttempts", 5)
+	v.SetDefault("DeadLetterRetryInterval", "30s")
+	v.SetDefault("SessionFlushInterval", "30s")
+	v.SetDefault("WalletLockWait", "0s")
+	v.SetDefault("WalletLockMaxHold", "5m")
+	v.SetDefault("WalletLockShared", false)
+	v.SetDefault("WalletLockFailOpen", true)
+	v.SetDefault("WalletRebalancing", map[string]interface{}{
+		"Enabled": false, "Interval": "10m", "Threshold": 0.2, "MaxMigrations": 10,
+	})
+	v.SetDefault("WalletExportLimit", 3)
+	v.SetDefault("WalletExportLimitPeriod", "1h")
+	v.SetDefault("SchedulerConcurrency", 0)
+	v.SetDefault("SchedulerAging", "1s")
+	v.SetDefault("SchedulerQueueTimeout", 0)
+	v.SetDefault("Canary", map[string]interface{}{"Enabled": false, "Build": "", "Percentage": 0})
+	v.SetDefault("Backpressure", map[string]interface{}{"Enabled": false, "Threshold": 0.8, "RetryAfter": "1s"})
+	v.SetDefault("ResponseValidation", "log")
+	v.SetDefault("ErrorRateWindow", "5m")
+	v.SetDefault("ExposeCacheInfo", false)
+	v.SetDefault("ExposeSDKNode", false)
+	v.SetDefault("SDKStatusTimeout", "5s")
+	v.SetDefault("SizedRPCTimeouts", map[string]interface{}{})
+	v.SetDefault("ClaimIDsBatchSize", 50)
+	v.SetDefault("GeoBlockUnknown", "allow")
+	v.SetDefault("ParamDefaults", map[string]interface{}{})
+	v.SetDefault("ForcedParams", map[string]interface{}{})
+	v.SetDefault("MaxPageSizes", map[string]int{})
+	v.SetDefault("OutageFallbacks", map[string]interface{}{})
+	v.SetDefault("MethodAliases", map[string]string{})
+	v.SetDefault("RequestRulesFile", "")
+	v.SetDefault("ReadModel", map[string]interface{}{
+		"Enabled": false, "Interval": "30s", "MaxLag": "2m", "Backfill": 1000,
+		"PageSize": 50, "MaxPages": 20, "MaxEntries": 100000,
+	})
+	v.SetDefault("HTTPServer", map[string]interface{}{
+		"ReadTimeout": "0s", "ReadHeaderTimeout": "10s", "WriteTimeout": "0s", "IdleTimeout": "2m",
+	})
+	v.SetDefault("MetricsExemplars", map[string]interface{}{"Enabled": false, "Threshold": "1s"})
+	v.SetDefault("AuditLog", map[string]interface{}{
+		"Path": "", "MaxFileSize": 100 << 20, "RotateInterval": "24h", "ArchiveInterval": "1m",
+		"ArchiveURL": "", "ArchiveHeaders": map[string]string{}, "Retention": "2160h",
+	})
+	v.SetDefault("ResponseSampling", map[string]interface{}{
+		"Enabled": false, "Rate": 0.001, "Methods": []string{}, "Dir": "samples",
+		"MaxFileSize": 100 << 20, "MaxFiles": 10, "BufferSize": 1000,
+	})
+	v.SetDefault("Entitlements", map[string]interface{}{
+		"Source": "db", "URL": "", "Timeout": "2s", "CacheTTL": "30s", "FailOpen": false,
+	})
+	v.SetDefault("MethodEntitlements", map[string]string{})
+	v.SetDefault("MaxPageSizeWarning", true)
+	v.SetDefault("BodyLoggedMethods", []string{})
+	v.SetDefault("SDKRoutingStrategies", map[string]string{})
+	v.SetDefault("RequestCosts", map[string]interface{}{"Read": 0, "Write": 0, "Methods": map[string]int64{}})
+	v.SetDefault("CDNRewrite", map[string]interface{}{
+		"Fields": []string{"streaming_url", "thumbnail_url"}, "Regions": map[string]string{}, "Hosts": map[string]interface{}{},
+	})
+	v.SetDefault("ForwardedHeaders", []string{})
+	v.SetDefault("ForwardedSensitiveHeaders", []string{})
+	v.SetDefault("ResponseStreamingThreshold", 10000)
+	v.SetDefault("BatchMaxSize", 50)
+	v.SetDefault("RateLimits", map[string]interface{}{
+		"Enabled": false, "Default": map[string]interface{}{"Rate": 5, "Burst": 20}, "Methods": map[string]interface{}{},
+	})
+	v.SetDefault("DeprecatedMethods", map[string]interface{}{})
+	v.SetDefault("HiddenMethods", []string{})
+	v.SetDefault("DuplicateRequests", map[string]interface{}{})
+	v.SetDefault("PublishUploadAbandonedAfter", "24h")
+	v.SetDefault("PublishUploadSweepInterval", "1h")
+	v.SetDefault("SDKRequestTransforms", []interface{}{})
+	v.SetDefault("ResponseVersionDefaults", map[string]string{})
+	v.SetDefault("ParamSanitization", map[string]interface{}{})
+	v.SetDefault("WalletOutageResponses", map[string]interface{}{})
+	v.SetDefault("ResponseCompression", map[string]interface{}{"Enabled": false, "Threshold": 1024, "Methods": map[string]string{}})
+	v.SetDefault("CircuitBreakers", map[string]interface{}{
+		"Endpoint": map[string]interface{}{"Threshold": 0, "Window": "30s", "Cooldown": "30s"},
+		"Methods":  map[string]interface{}{},
+		"User":     map[string]interface{}{"Threshold": 0, "Window": "1m", "Cooldown": "1m"},
+	})
+	v.SetDefault("PaginationLimits", map[string]interface{}{"PageSize": 50, "MaxPages": 100, "MaxItems": 5000, "Concurrency": 4})
+	v.SetDefault("ClaimSearchDegradedMode", map[string]interface{}{"Enabled": false, "Timeout": "5s", "PageSize": 10})
+	v.SetDefault("KnownClientApps", []string{"odysee", "odysee-web", "odysee-android", "odysee-ios", "lbry-desktop", "lbry-android", "okhttp"})
+	v.SetDefault("KnownClientVersions", []string{})
+}
 
-	c.Viper.SetDefault("Address", ":8080")
-	c.Viper.SetDefault("Host", "http://localhost:8080")
-	c.Viper.SetDefault("FreeContentURL", "http://localhost:8080/content/")
-	c.Viper.SetDefault("ReflectorTimeout", int64(10))
-	c.Viper.SetDefault("RefractorTimeout", int64(10))
+// Watch makes config file changes take effect without a restart.
+// Only settings which are read on every use (like MaintenanceMode) will pick up the new values.
+func Watch() {
+	Config.Watch()
 }
 
 func ProjectRoot() string {
@@ -62,7 +180,7 @@ func IsProduction() bool {
 
 // GetInternalAPIHost returns the address of internal-api server
 func GetInternalAPIHost() string {
-	return Config.Viper.GetString("InternalAPIHost")
+	return Config.Viper().GetString("InternalAPIHost")
 }
 
 // GetDatabase returns postgresql database server connection config
@@ -72,51 +190,66 @@ func GetDatabase() cfg.DBConfig {
 
 // GetSentryDSN returns sentry.io service DSN
 func GetSentryDSN() string {
-	return Config.Viper.GetString("SentryDSN")
+	return Config.Viper().GetString("SentryDSN")
+}
+
+// GetPublishMaxSize returns the maximum size of publish request body in bytes, zero means no limit.
+func GetPublishMaxSize() int64 {
+	return Config.Viper().GetInt64("PublishMaxSize")
 }
 
 // GetPublishSourceDir returns directory for storing published files before they're uploaded to lbrynet.
 // The directory needs to be accessed by the running SDK instance.
 func GetPublishSourceDir() string {
-	return Config.Viper.GetString("PublishSourceDir")
+	return Config.Viper().GetString("PublishSourceDir")
+}
+
+// GetPublishUploadAbandonedAfter returns how long resumable uploads can go untouched before they're removed.
+func GetPublishUploadAbandonedAfter() time.Duration {
+	return Config.Viper().GetDuration("PublishUploadAbandonedAfter")
+}
+
+// GetPublishUploadSweepInterval returns how often abandoned resumable uploads are looked for.
+func GetPublishUploadSweepInterval() time.Duration {
+	return Config.Viper().GetDuration("PublishUploadSweepInterval")
 }
 
 // GetBlobFilesDir returns directory where SDK instance stores blob files.
 func GetBlobFilesDir() string {
-	return Config.Viper.GetString("BlobFilesDir")
+	return Config.Viper().GetString("BlobFilesDir")
 }
 
 // GetReflectorAddress returns reflector address in the format of host:port.
 func GetReflectorAddress() string {
-	return Config.Viper.GetString("ReflectorAddress")
+	return Config.Viper().GetString("ReflectorAddress")
 }
 
 // ShouldLogResponses enables or disables full SDK responses logging
 func ShouldLogResponses() bool {
-	return Config.Viper.GetBool("ShouldLogResponses")
+	return Config.Viper().GetBool("ShouldLogResponses")
 }
 
 // GetPaidTokenPrivKey returns absolute path to the private RSA key for generating paid tokens
 func GetPaidTokenPrivKey() string {
-	return Config.Viper.GetString("PaidTokenPrivKey")
+	return Config.Viper().GetString("PaidTokenPrivKey")
 }
 
 // GetAddress determines address to bind http API server to
 func GetAddress() string {
-	return Config.Viper.GetString("Address")
+	return Config.Viper().GetString("Address")
 }
 
 //GetLbrynetServers returns the names/addresses of every SDK server
 func GetLbrynetServers() map[string]string {
-	if Config.Viper.GetString(deprecatedLbrynet) != "" &&
-		len(Config.Viper.GetStringMapString(lbrynetServers)) > 0 {
+	if Config.Viper().GetString(deprecatedLbrynet) != "" &&
+		len(Config.Viper().GetStringMapString(lbrynetServers)) > 0 {
 		logrus.Panicf("Both %s and %s are set. This is a highlander situation...there can be only 1.", deprecatedLbrynet, lbrynetServers)
 	}
 
-	if len(Config.Viper.GetStringMapString(lbrynetServers)) > 0 {
-		return Config.Viper.GetStringMapString(lbrynetServers)
-	} else if Config.Viper.GetString(deprecatedLbrynet) != "" {
-		return map[string]string{"sdk": Config.Viper.GetString(deprecatedLbrynet)}
+	if len(Config.Viper().GetStringMapString(lbrynetServers)) > 0 {
+		return Config.Viper().GetStringMapString(lbrynetServers)
+	} else if Config.Viper().GetString(deprecatedLbrynet) != "" {
+		return map[string]string{"sdk": Config.Viper().GetString(deprecatedLbrynet)}
 	} else {
 		servers, err := models.LbrynetServers().AllG()
 		if err != nil {
@@ -138,23 +271,23 @@ func RestoreOverridden() {
 }
 
 func GetLbrynetXServer() string {
-	return Config.Viper.GetString("LbrynetXServer")
+	return Config.Viper().GetString("LbrynetXServer")
 }
 
 func GetLbrynetXPercentage() int {
-	return Config.Viper.GetInt("LbrynetXPercentage")
+	return Config.Viper().GetInt("LbrynetXPercentage")
 }
 
 func GetTokenCacheTimeout() time.Duration {
-	return Config.Viper.GetDuration("TokenCacheTimeout") * time.Second
+	return Config.Viper().GetDuration("TokenCacheTimeout") * time.Second
 }
 
 func GetCORSDomains() []string {
-	return Config.Viper.GetStringSlice("CORSDomains")
+	return Config.Viper().GetStringSlice("CORSDomains")
 }
 
 func GetRPCTimeout(method string) *time.Duration {
-	ts := Config.Viper.GetStringMapString("RPCTimeouts")
+	ts := Config.Viper().GetStringMapString("RPCTimeouts")
 	if ts != nil {
 		if t, ok := ts[method]; ok {
 			d := cast.ToDuration(t)
@@ -164,3 +297,1071 @@ func GetRPCTimeout(method string) *time.Duration {
 	}
 	return nil
 }
+
+// SizedRPCTimeout is an SDK call timeout which grows with the size of data the call carries: Base plus PerMB
+// for every megabyte (MiB), capped at Max if it's set.
+type SizedRPCTimeout struct {
+	Base  time.Duration
+	PerMB time.Duration
+	Max   time.Duration
+}
+
+// GetSizedRPCTimeouts returns size-dependent SDK call timeouts by method. They take precedence over RPCTimeouts.
+func GetSizedRPCTimeouts() map[string]SizedRPCTimeout {
+	ts := map[string]SizedRPCTimeout{}
+	if err := Config.Viper().UnmarshalKey("SizedRPCTimeouts", &ts); err != nil {
+		logrus.Errorf("invalid SizedRPCTimeouts config: %v", err)
+		return map[string]SizedRPCTimeout{}
+	}
+	return ts
+}
+
+// IsMaintenanceMode is true when the API should reject client requests with a maintenance error.
+func IsMaintenanceMode() bool {
+	return Config.Viper().GetBool("MaintenanceMode")
+}
+
+// GetMaintenanceRetryAfter returns the duration clients are advised to wait before retrying during maintenance.
+func GetMaintenanceRetryAfter() time.Duration {
+	return Config.Viper().GetDuration("MaintenanceRetryAfter")
+}
+
+// GetAdminToken returns the token which identifies administrative requests.
+// Admin access is disabled when it's empty.
+func GetAdminToken() string {
+	return Config.Viper().GetString("AdminToken")
+}
+
+// ShouldPreserveJSONNumbers is true when numbers in client requests should be kept
+// in their original text form instead of being decoded into float64.
+func ShouldPreserveJSONNumbers() bool {
+	return Config.Viper().GetBool("PreserveJSONNumbers")
+}
+
+// IsStrictRequestJSON is true when JSON-RPC requests with unknown or repeated envelope fields should be rejected.
+func IsStrictRequestJSON() bool {
+	return Config.Viper().GetBool("StrictRequestJSON")
+}
+
+// GetClientIdentityHeader returns the name of HTTP header which clients use to report their app name and version.
+func GetClientIdentityHeader() string {
+	return Config.Viper().GetString("ClientIdentityHeader")
+}
+
+// GetKnownClientApps returns client app names which are tracked separately in metrics.
+// Other apps are reported as unknown to keep metrics cardinality low.
+func GetKnownClientApps() []string {
+	return Config.Viper().GetStringSlice("KnownClientApps")
+}
+
+// GetKnownClientVersions returns client versions (major.minor) which are tracked separately in metrics.
+// Other versions are reported as "other" so clients cannot inflate metrics cardinality.
+func GetKnownClientVersions() []string {
+	return Config.Viper().GetStringSlice("KnownClientVersions")
+}
+
+// GetWalletEventsPollInterval returns how often wallets of users waiting for wallet events are polled.
+func GetWalletEventsPollInterval() time.Duration {
+	return Config.Viper().GetDuration("WalletEventsPollInterval")
+}
+
+// GetWalletEventsMaxWait returns the maximum time a long-poll request for wallet events can be held open.
+func GetWalletEventsMaxWait() time.Duration {
+	return Config.Viper().GetDuration("WalletEventsMaxWait")
+}
+
+// GetCacheBypassAllowlist returns IP addresses which are allowed to bypass query cache without admin token.
+func GetCacheBypassAllowlist() []string {
+	return Config.Viper().GetStringSlice("CacheBypassAllowlist")
+}
+
+// GetSDKRetryBackoff returns the upper bound of the random wait before the first retry of an SDK query, doubled with every retry.
+func GetSDKRetryBackoff() time.Duration {
+	return Config.Viper().GetDuration("SDKRetryBackoff")
+}
+
+// GetSDKRetryMaxBackoff returns the longest wait before a retry of an SDK query, zero means no limit.
+func GetSDKRetryMaxBackoff() time.Duration {
+	return Config.Viper().GetDuration("SDKRetryMaxBackoff")
+}
+
+// GetSDKHealthCheckInterval returns how often quarantined SDK servers are checked for coming back online.
+func GetSDKHealthCheckInterval() time.Duration {
+	return Config.Viper().GetDuration("SDKHealthCheckInterval")
+}
+
+// GetSDKSlowStart returns how long it takes for SDK servers released from quarantine to get their full share of traffic.
+func GetSDKSlowStart() time.Duration {
+	return Config.Viper().GetDuration("SDKSlowStart")
+}
+
+// GetServiceSecrets returns secrets shared with trusted backend services, keyed by lowercase service name.
+func GetServiceSecrets() map[string]string {
+	return Config.Viper().GetStringMapString("ServiceSecrets")
+}
+
+// GetServiceSignatureMaxAge returns how far off a service request signature timestamp can be from the current time.
+func GetServiceSignatureMaxAge() time.Duration {
+	return Config.Viper().GetDuration("ServiceSignatureMaxAge")
+}
+
+// IsServiceNonceRequired returns true if signed service requests must carry a nonce, making each of them single-use.
+func IsServiceNonceRequired() bool {
+	return Config.Viper().GetBool("ServiceNonceRequired")
+}
+
+// IsServiceNonceShared returns true if service request nonces should be kept in the database,
+// which keeps signed requests from being replayed against other API instances.
+func IsServiceNonceShared() bool {
+	return Config.Viper().GetBool("ServiceNonceShared")
+}
+
+// GetCacheableMethods returns SDK methods allowed to be cached, along with their cache TTL.
+// Entries with invalid TTL are skipped so the method is not cached.
+func GetCacheableMethods() map[string]time.Duration {
+	ttls := map[string]time.Duration{}
+	for m, v := range Config.Viper().GetStringMapString("CacheableMethods") {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			logrus.Errorf("invalid cache TTL for method %v: %v", m, err)
+			continue
+		}
+		ttls[m] = ttl
+	}
+	return ttls
+}
+
+// GetCacheSnapshotPath returns the file the query cache is saved to and warmed from on startup. Empty disables snapshots.
+func GetCacheSnapshotPath() string {
+	return Config.Viper().GetString("CacheSnapshotPath")
+}
+
+// GetCacheSnapshotInterval returns how often the query cache is saved to disk.
+func GetCacheSnapshotInterval() time.Duration {
+	return Config.Viper().GetDuration("CacheSnapshotInterval")
+}
+
+// IsAdaptiveCacheTTLEnabled is true when resolve and claim_search responses should be cached longer for claims which don't change often.
+func IsAdaptiveCacheTTLEnabled() bool {
+	return Config.Viper().GetBool("AdaptiveCacheTTL")
+}
+
+// GetAdaptiveCacheTTLMin returns cache TTL for the most recently updated claims.
+func GetAdaptiveCacheTTLMin() time.Duration {
+	return Config.Viper().GetDuration("AdaptiveCacheTTLMin")
+}
+
+// GetAdaptiveCacheTTLMax returns cache TTL for claims which haven't been updated for a long time.
+func GetAdaptiveCacheTTLMax() time.Duration {
+	return Config.Viper().GetDuration("AdaptiveCacheTTLMax")
+}
+
+// GetResolveNotFoundTTL returns how long resolve responses with only nonexistent claims are cached for.
+// Zero makes them cached like any other resolve response.
+func GetResolveNotFoundTTL() time.Duration {
+	return Config.Viper().GetDuration("ResolveNotFoundTTL")
+}
+
+// GetCacheMethodPriorities returns how expensive responses of SDK methods are to get, for the cache to keep
+// the expensive ones longer. Empty map leaves eviction to the default policy.
+func GetCacheMethodPriorities() map[string]float64 {
+	priorities := map[string]float64{}
+	for m, v := range Config.Viper().GetStringMap("CacheMethodPriorities") {
+		p, err := cast.ToFloat64E(v)
+		if err != nil || p <= 0 {
+			logrus.Errorf("invalid cache priority for %v: %v", m, v)
+			continue
+		}
+		priorities[m] = p
+	}
+	return priorities
+}
+
+// CacheShadow configures mirroring of query cache operations to a shadow backend, which is validated
+// against the primary one without serving anything from it.
+type CacheShadow struct {
+	// Primary is the backend responses are served from, the default one if empty.
+	Primary string
+	// Shadow is the backend operations are mirrored to, shadowing is off if empty.
+	Shadow string
+	// QueueSize is how many operations can wait for workers before new ones are dropped.
+	QueueSize int
+	Workers   int
+}
+
+// GetCacheShadow returns query cache shadowing settings.
+func GetCacheShadow() CacheShadow {
+	s := CacheShadow{}
+	if err := Config.Viper().UnmarshalKey("CacheShadow", &s); err != nil {
+		logrus.Errorf("invalid CacheShadow config: %v", err)
+		return CacheShadow{}
+	}
+	return s
+}
+
+// KillSwitchRule matches queries which should be rejected (or let through) during incidents.
+// Empty fields match anything.
+type KillSwitchRule struct {
+	Name   string
+	Method string
+	// Auth is either "anonymous" or "authenticated".
+	Auth    string
+	Origins []string
+	// Params match when param value (or length for lists, maps and strings) is above the threshold.
+	Params map[string]float64
+	// Allow makes matching queries skip the rules that follow.
+	Allow   bool
+	Message string
+}
+
+// GetKillSwitchRules returns kill switch rules in the order they should be evaluated.
+// Rules are read on every call so they can be changed without a restart.
+func GetKillSwitchRules() []KillSwitchRule {
+	var rules []KillSwitchRule
+	if err := Config.Viper().UnmarshalKey("KillSwitchRules", &rules); err != nil {
+		logrus.Errorf("cannot parse kill switch rules: %v", err)
+		return nil
+	}
+	return rules
+}
+
+// CachePin is a query whose response is kept in the query cache and refreshed proactively.
+// URI is a shorthand for resolve of that single URI.
+type CachePin struct {
+	Method string
+	Params map[string]interface{}
+	URI    string
+}
+
+// GetCachePins returns queries whose responses should never be evicted from the query cache.
+// Pins are read on every call so they can be changed without a restart.
+func GetCachePins() []CachePin {
+	var pins []CachePin
+	if err := Config.Viper().UnmarshalKey("CachePins", &pins); err != nil {
+		logrus.Errorf("cannot parse cache pins: %v", err)
+		return nil
+	}
+	return pins
+}
+
+// GetCachePinsRefreshInterval returns how often responses to pinned queries are refreshed.
+func GetCachePinsRefreshInterval() time.Duration {
+	return Config.Viper().GetDuration("CachePinsRefreshInterval")
+}
+
+// SDKResponseAdapter normalizes results of a method returned by SDK servers of some versions
+// by filling in missing fields and renaming changed ones.
+type SDKResponseAdapter struct {
+	Name   string
+	Method string
+	// MinVersion (inclusive) and MaxVersion (exclusive) bound lbrynet versions, empty means unbounded.
+	MinVersion string
+	MaxVersion string
+	// Items makes the adapter apply to each item of a paginated result instead of the result itself.
+	Items    bool
+	Defaults map[string]interface{}
+	// Renames maps old field names to new ones.
+	Renames map[string]string
+}
+
+// GetSDKResponseAdapters returns SDK response adapters. They are read on every call so they can be changed without a restart.
+func GetSDKResponseAdapters() []SDKResponseAdapter {
+	var adapters []SDKResponseAdapter
+	if err := Config.Viper().UnmarshalKey("SDKResponseAdapters", &adapters); err != nil {
+		logrus.Errorf("cannot parse sdk response adapters: %v", err)
+		return nil
+	}
+	return adapters
+}
+
+// GetResponseVersionDefaults returns versions of responses served to clients not sending Accept-Version, by method.
+// Method keys are lowercase.
+func GetResponseVersionDefaults() map[string]string {
+	return Config.Viper().GetStringMapString("ResponseVersionDefaults")
+}
+
+// SDKRequestTransform converts requests to a method made in its old schema into the one SDK servers expect now.
+type SDKRequestTransform struct {
+	Name   string
+	Method string
+	// Moves maps dot-separated paths of params in the old schema to their paths in the new one.
+	Moves map[string]string
+}
+
+// GetSDKRequestTransforms returns SDK request transforms. They are read on every call so they can be changed without a restart.
+func GetSDKRequestTransforms() []SDKRequestTransform {
+	var transforms []SDKRequestTransform
+	if err := Config.Viper().UnmarshalKey("SDKRequestTransforms", &transforms); err != nil {
+		logrus.Errorf("cannot parse sdk request transforms: %v", err)
+		return nil
+	}
+	return transforms
+}
+
+// GeoBlockRule restricts a method to clients from certain countries, identified by ISO codes.
+type GeoBlockRule struct {
+	// Allow makes the method available only in listed countries when not empty.
+	Allow []string
+	// Deny makes the method unavailable in listed countries.
+	Deny []string
+}
+
+// GetGeoBlockRules returns geoblocking rules by method. Rules are read on every call so they can be changed without a restart.
+func GetGeoBlockRules() map[string]GeoBlockRule {
+	rules := map[string]GeoBlockRule{}
+	if err := Config.Viper().UnmarshalKey("GeoBlockRules", &rules); err != nil {
+		logrus.Errorf("cannot parse geoblock rules: %v", err)
+	}
+	return rules
+}
+
+// ShouldGeoBlockUnknown returns true if methods with geoblock rules should be denied to clients
+// whose country cannot be determined, including those with private addresses.
+func ShouldGeoBlockUnknown() bool {
+	return Config.Viper().GetString("GeoBlockUnknown") == "deny"
+}
+
+// GetGeoIPDB returns path to the GeoIP database file used for determining client country.
+func GetGeoIPDB() string {
+	return Config.Viper().GetString("GeoIPDB")
+}
+
+// GetTranslationsFile returns path to the JSON file with translations of messages sent to clients.
+func GetTranslationsFile() string {
+	return Config.Viper().GetString("TranslationsFile")
+}
+
+// GetDeadLetterMethods returns wallet methods which are queued for retrying when the SDK is unreachable.
+func GetDeadLetterMethods() []string {
+	return Config.Viper().GetStringSlice("DeadLetterMethods")
+}
+
+// GetDeadLetterMaxAttempts returns how many times a queued operation is retried before it's flagged for manual review.
+func GetDeadLetterMaxAttempts() int {
+	return Config.Viper().GetInt("DeadLetterMaxAttempts")
+}
+
+// GetSessionFlushInterval returns how often session uses are saved and revocations made on other instances are picked up.
+func GetSessionFlushInterval() time.Duration {
+	return Config.Viper().GetDuration("SessionFlushInterval")
+}
+
+// GetDeadLetterRetryInterval returns the delay before the first retry of a queued operation, doubled with every attempt.
+func GetDeadLetterRetryInterval() time.Duration {
+	return Config.Viper().GetDuration("DeadLetterRetryInterval")
+}
+
+// SDKMethodPool is a set of SDK servers dedicated to serving specific methods.
+type SDKMethodPool struct {
+	Name    string
+	Methods []string
+	Servers []string
+}
+
+// GetSDKMethodPools returns SDK pools for methods which shouldn't be served by the default SDK servers.
+func GetSDKMethodPools() ([]SDKMethodPool, error) {
+	var pools []SDKMethodPool
+	err := Config.Viper().UnmarshalKey("SDKMethodPools", &pools)
+	return pools, err
+}
+
+// GetSDKRoutingStrategies returns how SDK servers are picked for queries, by method.
+func GetSDKRoutingStrategies() map[string]string {
+	return Config.Viper().GetStringMapString("SDKRoutingStrategies")
+}
+
+// GetWalletLockWait returns how long a wallet-mutating request waits for another one of the same user to complete before it's rejected.
+func GetWalletLockWait() time.Duration {
+	return Config.Viper().GetDuration("WalletLockWait")
+}
+
+// GetWalletLockMaxHold returns how long a wallet-mutating request can keep other ones of the same user waiting.
+func GetWalletLockMaxHold() time.Duration {
+	return Config.Viper().GetDuration("WalletLockMaxHold")
+}
+
+// IsWalletLockShared returns true if wallet locks should be held in the database, which makes them apply across API instances.
+func IsWalletLockShared() bool {
+	return Config.Viper().GetBool("WalletLockShared")
+}
+
+// IsWalletLockFailOpen returns true if wallet-mutating requests should proceed when the shared wallet lock cannot be acquired
+// because of a database failure.
+func IsWalletLockFailOpen() bool {
+	return Config.Viper().GetBool("WalletLockFailOpen")
+}
+
+// GetWalletExportLimit returns how many wallet exports a user can request within WalletExportLimitPeriod.
+func GetWalletExportLimit() int {
+	return Config.Viper().GetInt("WalletExportLimit")
+}
+
+// GetWalletExportLimitPeriod returns the time window wallet export attempts are counted in.
+func GetWalletExportLimitPeriod() time.Duration {
+	return Config.Viper().GetDuration("WalletExportLimitPeriod")
+}
+
+// GetSchedulerConcurrency returns how many queries can be sent to the SDK at once, zero means no limit.
+func GetSchedulerConcurrency() int {
+	return Config.Viper().GetInt("SchedulerConcurrency")
+}
+
+// GetSchedulerAging returns how long a query waits for an SDK call slot before it's promoted to the next priority class.
+func GetSchedulerAging() time.Duration {
+	return Config.Viper().GetDuration("SchedulerAging")
+}
+
+// Canary makes an instance serve the canary variant of API code paths to a share of clients, see package canary.
+type Canary struct {
+	Enabled bool
+	// Build identifies the build in metrics and headers, empty means the app version.
+	Build string
+	// Percentage of clients getting the canary variant, 0 to 100.
+	Percentage int
+	// SDKServers are addresses of SDK servers running a canary SDK build, which serve queries of canary clients
+	// not bound to the wallet server of the user. Empty means canary clients use the same servers as everyone else.
+	SDKServers []string
+}
+
+// GetCanary returns canary settings of this instance.
+func GetCanary() Canary {
+	c := Canary{}
+	if err := Config.Viper().UnmarshalKey("Canary", &c); err != nil {
+		logrus.Errorf("invalid Canary config: %v", err)
+		return Canary{}
+	}
+	return c
+}
+
+// Backpressure tells clients to slow down when the scheduler is close to running out of SDK call slots.
+type Backpressure struct {
+	Enabled bool
+	// Threshold is the scheduler utilization (running and queued queries relative to SchedulerConcurrency)
+	// at which responses start carrying the signal.
+	Threshold float64
+	// RetryAfter is sent as Retry-After with signaled responses, zero omits the header.
+	RetryAfter time.Duration
+}
+
+// GetBackpressure returns backpressure signaling settings.
+func GetBackpressure() Backpressure {
+	b := Backpressure{}
+	if err := Config.Viper().UnmarshalKey("Backpressure", &b); err != nil {
+		logrus.Errorf("invalid Backpressure config: %v", err)
+		return Backpressure{}
+	}
+	return b
+}
+
+// GetSchedulerQueueTimeout returns how long a query can wait for an SDK call slot before it's rejected, zero means no limit.
+func GetSchedulerQueueTimeout() time.Duration {
+	return Config.Viper().GetDuration("SchedulerQueueTimeout")
+}
+
+// GetResponseStreamingThreshold returns the number of values in a response result above which
+// the response is streamed to the client instead of being serialized in memory first.
+func GetResponseStreamingThreshold() int {
+	return Config.Viper().GetInt("ResponseStreamingThreshold")
+}
+
+// GetBatchMaxSize returns the maximum number of requests in a single batch call.
+func GetBatchMaxSize() int {
+	return Config.Viper().GetInt("BatchMaxSize")
+}
+
+// GetForwardedHeaders returns names of client request headers which are passed on to the SDK.
+func GetForwardedHeaders() []string {
+	return Config.Viper().GetStringSlice("ForwardedHeaders")
+}
+
+// GetForwardedSensitiveHeaders returns names of headers carrying credentials which are allowed
+// to be passed on to the SDK when listed in ForwardedHeaders.
+func GetForwardedSensitiveHeaders() []string {
+	return Config.Viper().GetStringSlice("ForwardedSensitiveHeaders")
+}
+
+// GetSDKUserAgent returns User-Agent header value sent with SDK calls, empty means Go default.
+func GetSDKUserAgent() string {
+	return Config.Viper().GetString("SDKUserAgent")
+}
+
+// ShouldExposeCacheInfo returns true if all clients should get query cache status headers, not just admins.
+func ShouldExposeCacheInfo() bool {
+	return Config.Viper().GetBool("ExposeCacheInfo")
+}
+
+// ShouldExposeSDKNode returns true if all clients should be told which SDK server has served their request, not just admins.
+func ShouldExposeSDKNode() bool {
+	return Config.Viper().GetBool("ExposeSDKNode")
+}
+
+// GetRequestRulesFile returns the path to the file with request transformation rules, empty if there are none.
+func GetRequestRulesFile() string {
+	return Config.Viper().GetString("RequestRulesFile")
+}
+
+// GetSDKStatusTimeout returns how long each SDK server is given to respond when collecting statuses of all of them.
+func GetSDKStatusTimeout() time.Duration {
+	return Config.Viper().GetDuration("SDKStatusTimeout")
+}
+
+// GetErrorRateWindow returns the period over which the rolling per-method error rate is calculated.
+func GetErrorRateWindow() time.Duration {
+	return Config.Viper().GetDuration("ErrorRateWindow")
+}
+
+// GetParamDefaults returns params added to queries of a method when clients don't supply them, by method.
+func GetParamDefaults() map[string]map[string]interface{} {
+	defaults := map[string]map[string]interface{}{}
+	for m, v := range Config.Viper().GetStringMap("ParamDefaults") {
+		params, err := cast.ToStringMapE(v)
+		if err != nil {
+			logrus.Errorf("invalid ParamDefaults config for %v: %v", m, err)
+			continue
+		}
+		defaults[m] = params
+	}
+	return defaults
+}
+
+// GetForcedParams returns params set on all queries of a method regardless of client values, by method.
+func GetForcedParams() map[string]map[string]interface{} {
+	forced := map[string]map[string]interface{}{}
+	for m, v := range Config.Viper().GetStringMap("ForcedParams") {
+		params, err := cast.ToStringMapE(v)
+		if err != nil {
+			logrus.Errorf("invalid ForcedParams config for %v: %v", m, err)
+			continue
+		}
+		forced[m] = params
+	}
+	return forced
+}
+
+// ParamRule restricts values clients can send in a param.
+type ParamRule struct {
+	// Allow is a regular expression string values must match in full.
+	Allow string
+	// Strip is a regular expression of substrings removed from string values before they're checked against Allow.
+	Strip string
+	// MaxItems limits the number of values of list params, zero means no limit.
+	MaxItems int
+	// Action is "reject" (the default) to fail queries with values which are not allowed or "drop" to remove them.
+	Action string
+}
+
+// GetParamSanitization returns param rules by method and param name. Rules are read on every call
+// so they can be changed without a restart.
+func GetParamSanitization() map[string]map[string]ParamRule {
+	rules := map[string]map[string]ParamRule{}
+	if err := Config.Viper().UnmarshalKey("ParamSanitization", &rules); err != nil {
+		logrus.Errorf("invalid ParamSanitization config: %v", err)
+		return map[string]map[string]ParamRule{}
+	}
+	return rules
+}
+
+// GetMaxPageSizes returns the largest page_size allowed for list methods, by method.
+func GetMaxPageSizes() map[string]int {
+	sizes := map[string]int{}
+	for m, v := range Config.Viper().GetStringMap("MaxPageSizes") {
+		size, err := cast.ToIntE(v)
+		if err != nil {
+			logrus.Errorf("invalid MaxPageSizes config for %v: %v", m, err)
+			continue
+		}
+		sizes[m] = size
+	}
+	return sizes
+}
+
+// GetMethodAliases returns current method names by their old names which clients may still call.
+func GetMethodAliases() map[string]string {
+	return Config.Viper().GetStringMapString("MethodAliases")
+}
+
+// WalletOutageResponse sets how reads of a user's wallet are answered when their wallet server can't be reached.
+type WalletOutageResponse struct {
+	// MaxStaleness is how old the last known result of the same query can be to be served, marked as stale.
+	// Without one, or when it's zero, a wallet unavailable error is returned.
+	MaxStaleness time.Duration
+}
+
+// GetWalletOutageResponses returns wallet outage handling by method, methods not listed fail with the actual error.
+// It's read on every call so it can be changed without a restart.
+func GetWalletOutageResponses() map[string]WalletOutageResponse {
+	r := map[string]WalletOutageResponse{}
+	if err := Config.Viper().UnmarshalKey("WalletOutageResponses", &r); err != nil {
+		logrus.Errorf("invalid WalletOutageResponses config: %v", err)
+		return map[string]WalletOutageResponse{}
+	}
+	return r
+}
+
+// GetOutageFallbacks returns static results given for queries of safe read methods when all SDK servers are down, by method.
+func GetOutageFallbacks() map[string]map[string]interface{} {
+	fallbacks := map[string]map[string]interface{}{}
+	for m, v := range Config.Viper().GetStringMap("OutageFallbacks") {
+		result, err := cast.ToStringMapE(v)
+		if err != nil {
+			logrus.Errorf("invalid OutageFallbacks config for %v: %v", m, err)
+			continue
+		}
+		fallbacks[m] = result
+	}
+	return fallbacks
+}
+
+// ShouldWarnOnPageSizeLimit is true when clients should be warned that their page_size has been reduced.
+func ShouldWarnOnPageSizeLimit() bool {
+	return Config.Viper().GetBool("MaxPageSizeWarning")
+}
+
+// GetBodyLoggedMethods returns methods which have their full request and response bodies logged, redacted.
+func GetBodyLoggedMethods() []string {
+	return Config.Viper().GetStringSlice("BodyLoggedMethods")
+}
+
+// GetClaimIDsBatchSize returns the maximum number of claims requested from the SDK in one claim_search
+// when resolving claims by their IDs.
+func GetClaimIDsBatchSize() int {
+	return Config.Viper().GetInt("ClaimIDsBatchSize")
+}
+
+// ClaimSearchEnrichment configures merging of cached claims into claim_search results.
+type ClaimSearchEnrichment struct {
+	Enabled bool
+	// MaxItems is how many result items at most are looked up in the cache, zero means all of them.
+	MaxItems int
+	// MaxQueued is how many SDK calls can be waiting for the scheduler before enrichment is skipped.
+	MaxQueued int
+}
+
+// GetClaimSearchEnrichment returns claim_search enrichment settings.
+func GetClaimSearchEnrichment() ClaimSearchEnrichment {
+	e := ClaimSearchEnrichment{}
+	if err := Config.Viper().UnmarshalKey("ClaimSearchEnrichment", &e); err != nil {
+		logrus.Errorf("invalid ClaimSearchEnrichment config: %v", err)
+		return ClaimSearchEnrichment{}
+	}
+	return e
+}
+
+// GetMethodPriorities returns methods by the name of priority class they belong to.
+func GetMethodPriorities() map[string][]string {
+	return Config.Viper().GetStringMapStringSlice("MethodPriorities")
+}
+
+// GetResponseValidation returns what happens to SDK responses failing validation: "off", "log" or "reject".
+func GetResponseValidation() string {
+	return Config.Viper().GetString("ResponseValidation")
+}
+
+// CachePolicy contains values of caching headers sent with responses of a method.
+type CachePolicy struct {
+	CacheControl     string
+	SurrogateControl string
+}
+
+// ReadModel configures the local store of claims resolve queries can be answered from.
+type ReadModel struct {
+	Enabled bool
+	// Interval is how often claims from new blocks are fetched, up to MaxPages pages of PageSize claims at a time.
+	Interval time.Duration
+	PageSize int
+	MaxPages int
+	// MaxLag is how long the store may go without catching up with the blockchain before it stops being used.
+	MaxLag time.Duration
+	// Backfill is the number of recent blocks claims are fetched from on startup.
+	Backfill int
+	// MaxEntries is the number of claims kept, the ones stored earliest are dropped first.
+	MaxEntries int
+}
+
+// GetReadModel returns local read model settings.
+func GetReadModel() ReadModel {
+	m := ReadModel{}
+	if err := Config.Viper().UnmarshalKey("ReadModel", &m); err != nil {
+		logrus.Errorf("invalid ReadModel config: %v", err)
+		return ReadModel{}
+	}
+	return m
+}
+
+// HTTPServer configures timeouts of the API HTTP server, zero means no timeout. See http.Server for their meaning.
+type HTTPServer struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// GetHTTPServer returns API HTTP server settings.
+func GetHTTPServer() HTTPServer {
+	s := HTTPServer{}
+	if err := Config.Viper().UnmarshalKey("HTTPServer", &s); err != nil {
+		logrus.Errorf("invalid HTTPServer config: %v", err)
+		return HTTPServer{ReadHeaderTimeout: 10 * time.Second, IdleTimeout: 2 * time.Minute}
+	}
+	return s
+}
+
+// MetricsExemplars configures exemplars linking slow calls in latency metrics to their traces.
+type MetricsExemplars struct {
+	Enabled bool
+	// Threshold is the call duration from which observations get an exemplar.
+	Threshold time.Duration
+}
+
+// GetMetricsExemplars returns latency metrics exemplar settings.
+func GetMetricsExemplars() MetricsExemplars {
+	e := MetricsExemplars{}
+	if err := Config.Viper().UnmarshalKey("MetricsExemplars", &e); err != nil {
+		logrus.Errorf("invalid MetricsExemplars config: %v", err)
+		return MetricsExemplars{}
+	}
+	return e
+}
+
+// AuditLog configures writing audited queries to files in addition to the database.
+type AuditLog struct {
+	// Path is the current audit log file. Empty turns file logging off.
+	Path string
+	// The current file is rotated once it reaches MaxFileSize bytes or is RotateInterval old, zero disables either.
+	MaxFileSize    int64
+	RotateInterval time.Duration
+	// ArchiveInterval is how often rotated files are compressed, uploaded and expired.
+	ArchiveInterval time.Duration
+	// ArchiveURL is where compressed files are uploaded to with HTTP PUT as ArchiveURL/<file name>,
+	// with ArchiveHeaders added. Empty keeps archives on disk only.
+	ArchiveURL     string
+	ArchiveHeaders map[string]string
+	// Retention is how long archives are kept on disk, zero keeps them forever.
+	Retention time.Duration
+}
+
+// GetAuditLog returns audit log file settings.
+func GetAuditLog() AuditLog {
+	l := AuditLog{}
+	if err := Config.Viper().UnmarshalKey("AuditLog", &l); err != nil {
+		logrus.Errorf("invalid AuditLog config: %v", err)
+		return AuditLog{}
+	}
+	return l
+}
+
+// ResponseSampling configures capturing of a fraction of queries and their responses for offline analysis.
+type ResponseSampling struct {
+	Enabled bool
+	// Rate is the fraction of queries to capture, from 0 to 1.
+	Rate float64
+	// Methods limits capturing to the listed methods, all methods are captured if it's empty.
+	Methods []string
+	// Dir is where sample files are written, new files are started after MaxFileSize bytes
+	// and only MaxFiles most recent ones are kept.
+	Dir         string
+	MaxFileSize int64
+	MaxFiles    int
+	// BufferSize is the number of samples waiting to be written, beyond which new samples are dropped.
+	BufferSize int
+}
+
+// GetResponseSampling returns query sampling settings.
+func GetResponseSampling() ResponseSampling {
+	s := ResponseSampling{}
+	if err := Config.Viper().UnmarshalKey("ResponseSampling", &s); err != nil {
+		logrus.Errorf("invalid ResponseSampling config: %v", err)
+		return ResponseSampling{}
+	}
+	return s
+}
+
+// Entitlements configures where user entitlements, which gate methods listed in MethodEntitlements, come from.
+type Entitlements struct {
+	// Source is "db" or "http".
+	Source string
+	// URL is the address of the entitlements service, Timeout and CacheTTL apply to fetching from it.
+	URL      string
+	Timeout  time.Duration
+	CacheTTL time.Duration
+	// FailOpen lets users call gated methods when their entitlements cannot be fetched.
+	FailOpen bool
+}
+
+// GetEntitlements returns entitlement source settings.
+func GetEntitlements() Entitlements {
+	e := Entitlements{}
+	if err := Config.Viper().UnmarshalKey("Entitlements", &e); err != nil {
+		logrus.Errorf("invalid Entitlements config: %v", err)
+		return Entitlements{}
+	}
+	return e
+}
+
+// GetMethodEntitlements returns entitlements users need to call methods, by method.
+func GetMethodEntitlements() map[string]string {
+	return Config.Viper().GetStringMapString("MethodEntitlements")
+}
+
+// WalletRebalancing configures moving wallets off SDK servers which have many more users assigned than the others.
+type WalletRebalancing struct {
+	Enabled  bool
+	Interval time.Duration
+	// Threshold is how much above the average number of wallets per server (0.2 is 20%) a server has to be
+	// for wallets to be moved off it.
+	Threshold float64
+	// MaxMigrations is the maximum number of wallets moved every Interval.
+	MaxMigrations int
+}
+
+// GetWalletRebalancing returns wallet rebalancing settings.
+func GetWalletRebalancing() WalletRebalancing {
+	r := WalletRebalancing{}
+	if err := Config.Viper().UnmarshalKey("WalletRebalancing", &r); err != nil {
+		logrus.Errorf("invalid WalletRebalancing config: %v", err)
+		return WalletRebalancing{}
+	}
+	return r
+}
+
+// DegradedMode configures best-effort responses given when the SDK is too slow or unavailable to answer.
+type DegradedMode struct {
+	Enabled bool
+	// Timeout is how long to wait for the SDK before falling back to a degraded response.
+	Timeout time.Duration
+	// PageSize is the maximum number of results in a degraded response.
+	PageSize int
+}
+
+// CircuitBreaker stops calls after too many failures and lets a single call through after a cooldown
+// to check if they can be resumed.
+type CircuitBreaker struct {
+	// Threshold is the number of failures within Window which opens the breaker. Zero disables it.
+	Threshold int
+	Window    time.Duration
+	// Cooldown is how long the breaker stays open before letting a trial call through.
+	Cooldown time.Duration
+}
+
+// CircuitBreakers configures breakers guarding SDK calls. Every SDK server gets its own Endpoint breaker,
+// methods listed in Methods get one more. A call is only made if all of its breakers allow it.
+// User breakers are kept for each signed in user separately and count JSON-RPC errors their calls get,
+// refusing all calls of a user whose breaker is open.
+type CircuitBreakers struct {
+	Endpoint CircuitBreaker
+	Methods  map[string]CircuitBreaker
+	User     CircuitBreaker
+}
+
+// GetCircuitBreakers returns circuit breaker settings. Changes are picked up without a restart.
+func GetCircuitBreakers() CircuitBreakers {
+	b := CircuitBreakers{}
+	if err := Config.Viper().UnmarshalKey("CircuitBreakers", &b); err != nil {
+		logrus.Errorf("invalid CircuitBreakers config: %v", err)
+		return CircuitBreakers{}
+	}
+	return b
+}
+
+// PaginationLimits bound internal queries which follow pagination to collect all results of a list method.
+type PaginationLimits struct {
+	// PageSize is the number of items requested per page.
+	PageSize int
+	// MaxPages is the maximum number of pages fetched.
+	MaxPages int
+	// MaxItems is the maximum number of items collected, the rest are dropped.
+	MaxItems int
+	// Concurrency is how many pages are fetched at once.
+	Concurrency int
+}
+
+// GetPaginationLimits returns limits for internal queries collecting all pages of results.
+func GetPaginationLimits() PaginationLimits {
+	l := PaginationLimits{}
+	if err := Config.Viper().UnmarshalKey("PaginationLimits", &l); err != nil {
+		logrus.Errorf("invalid PaginationLimits config: %v", err)
+	}
+	return l
+}
+
+// GetClaimSearchDegradedMode returns degraded mode settings for claim_search.
+func GetClaimSearchDegradedMode() DegradedMode {
+	m := DegradedMode{}
+	if err := Config.Viper().UnmarshalKey("ClaimSearchDegradedMode", &m); err != nil {
+		logrus.Errorf("invalid ClaimSearchDegradedMode config: %v", err)
+		return DegradedMode{}
+	}
+	return m
+}
+
+// RequestCosts sets how much successful requests of authenticated users cost for metered billing.
+type RequestCosts struct {
+	// Read and Write are the costs of methods not listed in Methods, by whether they mutate the wallet.
+	Read  int64
+	Write int64
+	// Methods are costs of specific methods.
+	Methods map[string]int64
+}
+
+// GetRequestCosts returns costs charged for requests of authenticated users.
+func GetRequestCosts() RequestCosts {
+	c := RequestCosts{}
+	if err := Config.Viper().UnmarshalKey("RequestCosts", &c); err != nil {
+		logrus.Errorf("invalid RequestCosts config: %v", err)
+		return RequestCosts{}
+	}
+	return c
+}
+
+// CDNRewrite sets how storage URLs in responses are pointed at CDN edges nearest to the client.
+type CDNRewrite struct {
+	// Fields are names of response fields holding storage URLs, at any depth.
+	Fields []string
+	// Regions maps client countries (ISO codes) to CDN regions.
+	Regions map[string]string
+	// Hosts maps storage hosts to CDN hosts by region. Clients outside listed regions get the "default" one.
+	Hosts map[string]map[string]string
+}
+
+// GetCDNRewrite returns CDN rewrite settings. Country, region and host keys are lowercase.
+func GetCDNRewrite() CDNRewrite {
+	c := CDNRewrite{}
+	if err := Config.Viper().UnmarshalKey("CDNRewrite", &c); err != nil {
+		logrus.Errorf("invalid CDNRewrite config: %v", err)
+		return CDNRewrite{}
+	}
+	regions := map[string]string{}
+	for country, region := range c.Regions {
+		regions[strings.ToLower(country)] = strings.ToLower(region)
+	}
+	hosts := map[string]map[string]string{}
+	for storage, edges := range c.Hosts {
+		h := map[string]string{}
+		for region, edge := range edges {
+			h[strings.ToLower(region)] = edge
+		}
+		hosts[strings.ToLower(storage)] = h
+	}
+	c.Regions, c.Hosts = regions, hosts
+	return c
+}
+
+// RateLimit is a token bucket holding up to Burst calls and refilling at Rate calls per second.
+// Zero Burst or Rate means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits throttle proxied calls per user, or per IP for anonymous clients.
+type RateLimits struct {
+	Enabled bool
+	// Default applies to all methods not listed in Methods together, they share a bucket.
+	Default RateLimit
+	// Methods have buckets of their own.
+	Methods map[string]RateLimit
+}
+
+// GetRateLimits returns proxy rate limits. Method keys are lowercase.
+func GetRateLimits() RateLimits {
+	l := RateLimits{}
+	if err := Config.Viper().UnmarshalKey("RateLimits", &l); err != nil {
+		logrus.Errorf("invalid RateLimits config: %v", err)
+		return RateLimits{}
+	}
+	l.Default = validRateLimit("Default", l.Default)
+	methods := map[string]RateLimit{}
+	for m, ml := range l.Methods {
+		methods[strings.ToLower(m)] = validRateLimit(m, ml)
+	}
+	l.Methods = methods
+	return l
+}
+
+// validRateLimit turns limits which would never refill into no limit.
+func validRateLimit(name string, l RateLimit) RateLimit {
+	if l.Burst > 0 && l.Rate <= 0 {
+		logrus.Errorf("invalid RateLimits config for %v: Rate must be positive, not limiting", name)
+		return RateLimit{}
+	}
+	return l
+}
+
+// GetHiddenMethods returns methods left out of the method discovery document. They can still be called.
+func GetHiddenMethods() []string {
+	return Config.Viper().GetStringSlice("HiddenMethods")
+}
+
+// DeprecatedMethod describes a method scheduled for removal.
+type DeprecatedMethod struct {
+	// Sunset is the date (YYYY-MM-DD) after which the method can be removed.
+	Sunset string
+	// Replacement is the method clients should use instead, optional.
+	Replacement string
+}
+
+// GetDeprecatedMethods returns methods scheduled for removal. Method keys are lowercase.
+func GetDeprecatedMethods() map[string]DeprecatedMethod {
+	methods := map[string]DeprecatedMethod{}
+	if err := Config.Viper().UnmarshalKey("DeprecatedMethods", &methods); err != nil {
+		logrus.Errorf("invalid DeprecatedMethods config: %v", err)
+		return map[string]DeprecatedMethod{}
+	}
+	lower := map[string]DeprecatedMethod{}
+	for m, d := range methods {
+		lower[strings.ToLower(m)] = d
+	}
+	return lower
+}
+
+// DuplicateRequest sets how identical requests of a user to a method following each other closely are handled.
+type DuplicateRequest struct {
+	// Window is how long after the first request an identical one is treated as a duplicate.
+	Window time.Duration
+	// Action is "coalesce" to give duplicates the response to the first request or "reject" to fail them.
+	Action string
+}
+
+// GetDuplicateRequests returns duplicate handling by method, methods not listed aren't checked for duplicates.
+// It's read on every call so it can be changed without a restart.
+func GetDuplicateRequests() map[string]DuplicateRequest {
+	dups := map[string]DuplicateRequest{}
+	if err := Config.Viper().UnmarshalKey("DuplicateRequests", &dups); err != nil {
+		logrus.Errorf("invalid DuplicateRequests config: %v", err)
+		return map[string]DuplicateRequest{}
+	}
+	return dups
+}
+
+// ResponseCompression sets which proxy responses are gzipped for clients accepting it.
+type ResponseCompression struct {
+	Enabled bool
+	// Threshold is the response size in bytes from which responses of methods not listed in Methods are compressed.
+	Threshold int
+	// Methods override Threshold per method with a size threshold of their own, "always" or "never".
+	Methods map[string]string
+}
+
+// GetResponseCompression returns response compression settings. Method keys are lowercase.
+func GetResponseCompression() ResponseCompression {
+	c := ResponseCompression{}
+	if err := Config.Viper().UnmarshalKey("ResponseCompression", &c); err != nil {
+		logrus.Errorf("invalid ResponseCompression config: %v", err)
+		return ResponseCompression{}
+	}
+	methods := map[string]string{}
+	for m, v := range c.Methods {
+		methods[strings.ToLower(m)] = strings.ToLower(v)
+	}
+	c.Methods = methods
+	return c
+}
+
+// GetCachePolicies returns caching headers by method for responses which can be cached downstream.
+func GetCachePolicies() map[string]CachePolicy {
+	policies := map[string]CachePolicy{}
+	if err := Config.Viper().UnmarshalKey("CachePolicies", &policies); err != nil {
+		logrus.Errorf("invalid CachePolicies config: %v", err)
+	}
+	return policies
+}
@@ -22,8 +22,8 @@ func TestGetLbrynetServers(t *testing.T) {
 }
 
 func TestGetLbrynetServersNoDB(t *testing.T) {
-	if Config.Viper.GetString(deprecatedLbrynet) != "" &&
-		len(Config.Viper.GetStringMapString(lbrynetServers)) > 0 {
+	if Config.Viper().GetString(deprecatedLbrynet) != "" &&
+		len(Config.Viper().GetStringMapString(lbrynetServers)) > 0 {
 		t.Fatalf("Both %s and %s are set. This is a highlander situation...there can be only one.", deprecatedLbrynet, lbrynetServers)
 	}
 }
@@ -45,3 +45,45 @@ func TestGetRPCTimeout(t *testing.T) {
 	assert.Equal(t, 200*time.Millisecond, *GetRPCTimeout("resolve"))
 	assert.Nil(t, GetRPCTimeout("random_method"))
 }
+
+func TestGetSizedRPCTimeouts(t *testing.T) {
+	Config.Override("SizedRPCTimeouts", map[string]interface{}{
+		"Publish": map[string]interface{}{"Base": "1m", "PerMB": "500ms", "Max": "20m"},
+	})
+	defer Config.RestoreOverridden()
+
+	assert.Equal(t, map[string]SizedRPCTimeout{
+		"publish": {Base: time.Minute, PerMB: 500 * time.Millisecond, Max: 20 * time.Minute},
+	}, GetSizedRPCTimeouts())
+}
+
+func TestGetHTTPServer(t *testing.T) {
+	s := GetHTTPServer()
+	assert.Equal(t, 10*time.Second, s.ReadHeaderTimeout)
+	assert.Equal(t, 2*time.Minute, s.IdleTimeout)
+	assert.Zero(t, s.ReadTimeout)
+	assert.Zero(t, s.WriteTimeout)
+
+	Config.Override("HTTPServer", map[string]string{"ReadHeaderTimeout": "5s", "WriteTimeout": "10m"})
+	defer Config.RestoreOverridden()
+	s = GetHTTPServer()
+	assert.Equal(t, 5*time.Second, s.ReadHeaderTimeout)
+	assert.Equal(t, 10*time.Minute, s.WriteTimeout)
+}
+
+func TestGetRateLimits(t *testing.T) {
+	Config.Override("RateLimits", map[string]interface{}{
+		"Enabled": true,
+		"Default": map[string]interface{}{"Rate": 0, "Burst": 20},
+		"Methods": map[string]interface{}{
+			"Claim_Search": map[string]interface{}{"Rate": 2, "Burst": 10},
+			"resolve":      map[string]interface{}{"Burst": 5},
+		},
+	})
+	defer Config.RestoreOverridden()
+
+	assert.Equal(t, RateLimits{
+		Enabled: true,
+		Methods: map[string]RateLimit{"claim_search": {Rate: 2, Burst: 10}, "resolve": {}},
+	}, GetRateLimits())
+}
@@ -12,14 +12,22 @@ import (
 	reportersvr "github.com/lbryio/lbrytv/apps/watchman/gen/http/reporter/server"
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	goahttp "goa.design/goa/v3/http"
 	httpmdlwr "goa.design/goa/v3/http/middleware"
 	"goa.design/goa/v3/middleware"
 )
 
+// serverTimeouts are applied to the HTTP server, see http.Server for their meaning.
+type serverTimeouts struct {
+	Read, ReadHeader, Write, Idle time.Duration
+}
+
 // handleHTTPServer starts configures and starts a HTTP server on the given
 // URL. It shuts down the server if any error is received in the error channel.
-func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *reporter.Endpoints, wg *sync.WaitGroup, errc chan error, logger *log.Logger, debug bool) {
+// Request body size and processing time are bounded by maxBodySize and timeout,
+// connections of slow and idle clients by timeouts.
+func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *reporter.Endpoints, wg *sync.WaitGroup, errc chan error, logger *log.Logger, debug bool, maxBodySize int64, timeout time.Duration, timeouts serverTimeouts) {
 
 	// Setup goa log adapter.
 	var (
@@ -56,6 +64,7 @@ func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *repor
 		eh := errorHandler(logger)
 		reporterServer = reportersvr.New(reporterEndpoints, mux, dec, enc, eh, nil)
 		reporterServer.Use(watchman.RemoteAddressMiddleware())
+		reporterServer.Use(watchman.GuardMiddleware(maxBodySize, timeout))
 
 		if debug {
 			servers := goahttp.Servers{
@@ -66,6 +75,7 @@ func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *repor
 	}
 	// Configure the mux.
 	reportersvr.Mount(mux, reporterServer)
+	mux.Handle(http.MethodGet, "/metrics", promhttp.Handler().ServeHTTP)
 
 	// Wrap the multiplexer with additional middlewares. Middlewares mounted
 	// here apply to all the service endpoints.
@@ -75,9 +85,14 @@ func handleHTTPServer(ctx context.Context, addr string, reporterEndpoints *repor
 		handler = httpmdlwr.RequestID()(handler)
 	}
 
-	// Start HTTP server using default configuration, change the code to
-	// configure the server as required by your service.
-	srv := &http.Server{Addr: addr, Handler: handler}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       timeouts.Read,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
 	for _, m := range reporterServer.Mounts {
 		logger.Printf("HTTP %q mounted on %s %s", m.Method, m.Verb, m.Pattern)
 	}
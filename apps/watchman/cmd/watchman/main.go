@@ -15,6 +15,9 @@ import (
 	"github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
 	"github.com/lbryio/lbrytv/apps/watchman/log"
 	"github.com/lbryio/lbrytv/apps/watchman/olapdb"
+	"github.com/lbryio/lbrytv/internal/maintenance"
+
+	lbrytvconfig "github.com/lbryio/lbrytv/config"
 
 	"github.com/alecthomas/kong"
 )
@@ -35,7 +38,8 @@ func main() {
 	if err != nil {
 		log.Log.Fatal(err)
 	}
-	logCfg := cfg.GetStringMapString("log")
+	v := cfg.Viper()
+	logCfg := v.GetStringMapString("log")
 	if logCfg["encoding"] == "" {
 		logCfg["encoding"] = log.EncodingConsole
 	}
@@ -44,12 +48,12 @@ func main() {
 	}
 	log.Configure(logCfg["level"], logCfg["encoding"])
 
-	dbCfg := cfg.GetStringMapString("clickhouse")
+	dbCfg := v.GetStringMapString("clickhouse")
 	err = olapdb.Connect(dbCfg["url"], "watchman")
 	if err != nil {
 		log.Log.Fatal(err)
 	}
-	err = olapdb.OpenGeoDB(cfg.GetString("geoipdb"))
+	err = olapdb.OpenGeoDB(v.GetString("geoipdb"))
 	if err != nil {
 		log.Log.Fatal(err)
 	}
@@ -57,7 +61,9 @@ func main() {
 	ctx := kong.Parse(&CLI)
 	switch ctx.Command() {
 	case "serve":
-		serve(CLI.Serve.Bind, CLI.Serve.Debug)
+		// Config is watched so maintenance mode, stats keys and retry periods can be changed without a restart
+		cfg.Watch()
+		serve(CLI.Serve.Bind, CLI.Serve.Debug, cfg)
 	case "generate":
 		generate(CLI.Generate.Number, CLI.Generate.Days)
 	default:
@@ -65,14 +71,46 @@ func main() {
 	}
 }
 
-func serve(bindF string, dbgF bool) {
+// serve starts the service. Settings which can change at runtime are read from the current config snapshot
+// on every use, the rest only once here.
+func serve(bindF string, dbgF bool, cfg *lbrytvconfig.ConfigWrapper) {
+	v := cfg.Viper()
 	// Initialize the services.
 	var (
 		reporterSvc reporter.Service
+		queue       *watchman.ReportQueue
 	)
 	{
+		mnt := maintenance.NewSwitch(
+			func() bool { return cfg.Viper().GetBool("maintenance") },
+			func(on bool) { log.Log.Warnw("maintenance mode switched", "on", on) },
+		)
+		retry := watchman.RetryPolicy{
+			MaxAttempts: v.GetInt("QueueRetryMaxAttempts"),
+			Backoff:     v.GetDuration("QueueRetryBackoff"),
+			MaxBackoff:  v.GetDuration("QueueRetryMaxBackoff"),
+			Rate:        v.GetFloat64("QueueRetryRate"),
+			Size:        v.GetInt("QueueRetrySize"),
+		}
+		queue = watchman.NewReportQueue(v.GetInt("QueueSize"), v.GetInt("QueueWorkers"), retry, func(r *reporter.PlaybackReport, addr string) error {
+			return olapdb.BatchWrite(r, addr, "")
+		})
+		if ttl := v.GetDuration("StatsCacheTTL"); ttl > 0 {
+			olapdb.EnableStatsCache(olapdb.NewStatsCache(ttl, v.GetInt("StatsCacheSize")))
+		}
+		limits := watchman.FieldLimits{Truncate: v.GetBool("ReportFieldTruncate")}
+		if err := v.UnmarshalKey("ReportFieldMaxLengths", &limits.MaxLengths); err != nil {
+			log.Log.Fatalw("invalid ReportFieldMaxLengths config", "err", err)
+		}
 		// TODO: provide DB connection as the first argument
-		reporterSvc = watchman.NewReporter(nil, log.Log)
+		retryAfter := func() watchman.RetryAfter {
+			var r watchman.RetryAfter
+			if err := cfg.Viper().UnmarshalKey("RetryAfter", &r); err != nil {
+				log.Log.Errorw("invalid RetryAfter config", "err", err)
+			}
+			return r
+		}
+		reporterSvc = watchman.NewReporter(nil, log.Log, mnt, func() []string { return cfg.Viper().GetStringSlice("statskeys") }, queue, limits, retryAfter)
 	}
 
 	// Wrap the services in endpoints that can be invoked from other services
@@ -100,7 +138,13 @@ func serve(bindF string, dbgF bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Start the servers and send errors (if any) to the error channel.
-	handleHTTPServer(ctx, bindF, reporterEndpoints, &wg, errc, stdlog.New(io.Discard, "[watchman] ", stdlog.Ltime), dbgF)
+	handleHTTPServer(ctx, bindF, reporterEndpoints, &wg, errc, stdlog.New(io.Discard, "[watchman] ", stdlog.Ltime), dbgF,
+		v.GetInt64("RequestMaxSize"), v.GetDuration("RequestTimeout"), serverTimeouts{
+			Read:       v.GetDuration("HTTPServer.ReadTimeout"),
+			ReadHeader: v.GetDuration("HTTPServer.ReadHeaderTimeout"),
+			Write:      v.GetDuration("HTTPServer.WriteTimeout"),
+			Idle:       v.GetDuration("HTTPServer.IdleTimeout"),
+		})
 
 	// Wait for signal.
 	log.Log.Infof("exiting (%v)", <-errc)
@@ -109,6 +153,14 @@ func serve(bindF string, dbgF bool) {
 	cancel()
 
 	wg.Wait()
+
+	// No more reports are coming in, write out everything accepted so far.
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), v.GetDuration("QueueFlushTimeout"))
+	defer flushCancel()
+	if err := queue.Close(flushCtx); err != nil {
+		log.Log.Errorw("report queue was not flushed", "pending", queue.Len(), "err", err)
+	}
+	olapdb.Flush()
 	log.Log.Info("exited")
 }
 
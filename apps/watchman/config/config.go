@@ -4,20 +4,44 @@ import (
 	"os"
 	"path/filepath"
 
+	cfg "github.com/lbryio/lbrytv/config"
+
 	"github.com/spf13/viper"
 )
 
 const configName = "watchman"
 
-func Read() (*viper.Viper, error) {
-	cfg := viper.New()
-	cfg.SetConfigName(configName)
-	cfg.AddConfigPath(ProjectRoot())
-	cfg.AddConfigPath(".")
-	cfg.AddConfigPath("../")
-	cfg.AddConfigPath("./config")
+// Read loads watchman settings. Once watched, they're reloaded into a fresh snapshot on every change,
+// so settings which can change at runtime have to be read from Viper() on every use rather than kept.
+func Read() (*cfg.ConfigWrapper, error) {
+	return cfg.LoadConfig(configName, setDefaults)
+}
+
+// setDefaults is applied to settings every time they're (re)loaded.
+func setDefaults(v *viper.Viper) {
+	v.AddConfigPath(ProjectRoot())
 
-	return cfg, cfg.ReadInConfig()
+	v.SetDefault("RequestMaxSize", 256<<10)
+	v.SetDefault("RequestTimeout", "30s")
+	v.SetDefault("ReportFieldMaxLengths", map[string]int{"url": 512, "player": 64, "user_id": 45})
+	v.SetDefault("ReportFieldTruncate", false)
+	v.SetDefault("QueueSize", 10000)
+	v.SetDefault("QueueWorkers", 4)
+	v.SetDefault("QueueFlushTimeout", "30s")
+	v.SetDefault("QueueRetryMaxAttempts", 5)
+	v.SetDefault("QueueRetryBackoff", "1s")
+	v.SetDefault("QueueRetryMaxBackoff", "1m")
+	v.SetDefault("QueueRetryRate", 50)
+	v.SetDefault("QueueRetrySize", 10000)
+	v.SetDefault("StatsCacheTTL", "1m")
+	v.SetDefault("StatsCacheSize", 10000)
+	v.SetDefault("HTTPServer.ReadTimeout", "30s")
+	v.SetDefault("HTTPServer.ReadHeaderTimeout", "5s")
+	v.SetDefault("HTTPServer.WriteTimeout", "60s")
+	v.SetDefault("HTTPServer.IdleTimeout", "2m")
+	v.SetDefault("RetryAfter.Maintenance", "5m")
+	v.SetDefault("RetryAfter.QueueFull", "10s")
+	v.SetDefault("RetryAfter.Unavailable", "30s")
 }
 
 func ProjectRoot() string {
@@ -17,7 +17,7 @@ var _ = API("watchman", func() {
 
 	cors.Origin(`/(http:\/\/localhost:\d+)|(https:\/\/odysee.com)|(https:\/\/.+\.odysee.com)|(https:\/\/.+\.lbry.tv)/`, func() {
 		cors.Methods(http.MethodGet, http.MethodPost)
-		cors.Headers("content-type")
+		cors.Headers("content-type", "x-watchman-key")
 		cors.MaxAge(600)
 	})
 
@@ -41,6 +41,7 @@ var _ = Service("reporter", func() {
 	Description("Media playback reports")
 
 	Error("multi_field_error", MultiFieldError) // Use custom error type
+	Error("maintenance", MaintenanceError)
 
 	Method("add", func() {
 		Payload(PlaybackReport)
@@ -48,9 +49,58 @@ var _ = Service("reporter", func() {
 		HTTP(func() {
 			POST("/reports/playback")
 			Response("multi_field_error", StatusBadRequest)
+			Response("maintenance", StatusServiceUnavailable, func() {
+				Header("retry_after:Retry-After")
+			})
 			Response(StatusCreated)
 		})
 	})
+	Method("stats", func() {
+		Description("Aggregate playback stats of a claim, bucketed by time")
+		Security(StatsKeyAuth)
+		Payload(func() {
+			APIKey("stats_key", "key", String, "Stats API key")
+			Attribute("claim_id", String, "Claim ID", func() {
+				Example("e7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67")
+				Pattern("^[a-f0-9]{40}$")
+			})
+			Attribute("from", String, "Start of the time range", func() {
+				Format(FormatDateTime)
+			})
+			Attribute("to", String, "End of the time range (exclusive)", func() {
+				Format(FormatDateTime)
+			})
+			Attribute("bucket", String, "Time bucket size", func() {
+				Enum("hour", "day")
+				Default("day")
+			})
+			Attribute("page", Int, "Page number", func() {
+				Minimum(1)
+				Default(1)
+			})
+			Attribute("page_size", Int, "Number of buckets per page", func() {
+				Minimum(1)
+				Maximum(500)
+				Default(100)
+			})
+			Required("key", "claim_id", "from", "to")
+		})
+		Result(ClaimStats)
+		Error("unauthorized", String, "Invalid or missing stats key")
+		Error("multi_field_error", MultiFieldError)
+		HTTP(func() {
+			GET("/stats/claims/{claim_id}")
+			Header("key:X-Watchman-Key")
+			Param("from")
+			Param("to")
+			Param("bucket")
+			Param("page")
+			Param("page_size")
+			Response(StatusOK)
+			Response("unauthorized", StatusUnauthorized)
+			Response("multi_field_error", StatusBadRequest)
+		})
+	})
 	Method("healthz", func() {
 		Result(String, func() {
 			Example("OK")
@@ -62,6 +112,33 @@ var _ = Service("reporter", func() {
 	})
 })
 
+var StatsKeyAuth = APIKeySecurity("stats_key", func() {
+	Description("Secures access to playback stats")
+})
+
+var ClaimStats = ResultType("application/vnd.watchman.claim-stats", func() {
+	Description("Playback stats of a claim")
+	Attributes(func() {
+		Attribute("claim_id", String, "Claim ID")
+		Attribute("bucket", String, "Time bucket size")
+		Attribute("page", Int, "Page number")
+		Attribute("page_size", Int, "Number of buckets per page")
+		Attribute("has_more", Boolean, "Whether more buckets are available on the next page")
+		Attribute("buckets", ArrayOf(StatsBucket), "Stats buckets, oldest first")
+		Required("claim_id", "bucket", "page", "page_size", "has_more", "buckets")
+	})
+})
+
+var StatsBucket = Type("StatsBucket", func() {
+	Attribute("start", String, "Bucket start time", func() {
+		Format(FormatDateTime)
+	})
+	Attribute("views", Int64, "Number of distinct viewers")
+	Attribute("avg_bitrate", Float64, "Average media bitrate, bit/s")
+	Attribute("rebuf_rate", Float64, "Share of playback time spent rebuffering, 0—1")
+	Required("start", "views", "avg_bitrate", "rebuf_rate")
+})
+
 var MultiFieldError = Type("MultiFieldError", func() {
 	Description("MultiFieldError is the error returned when several fields failed a validation rule.")
 	Field(1, "message", String, func() {
@@ -70,6 +147,17 @@ var MultiFieldError = Type("MultiFieldError", func() {
 	Required("message")
 })
 
+var MaintenanceError = Type("MaintenanceError", func() {
+	Description("MaintenanceError is returned when the service is under maintenance or overloaded and doesn't accept reports.")
+	Field(1, "message", String, func() {
+		Example("service under maintenance, please try again later")
+	})
+	Field(2, "retry_after", Int, "Number of seconds after which the client should retry", func() {
+		Example(300)
+	})
+	Required("message", "retry_after")
+})
+
 var PlaybackReport = Type("PlaybackReport", func() {
 	Attribute("url", String, "LBRY URL (lbry://... without the protocol part)", func() {
 		Example("@veritasium#f/driverless-cars-are-already-here#1")
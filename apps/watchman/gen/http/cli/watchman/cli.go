@@ -23,24 +23,24 @@ import (
 //    command (subcommand1|subcommand2|...)
 //
 func UsageCommands() string {
-	return `reporter (add|healthz)
+	return `reporter (add|stats|healthz)
 `
 }
 
 // UsageExamples produces an example of a valid invocation of the CLI tool.
 func UsageExamples() string {
 	return os.Args[0] + ` reporter add --body '{
-      "bandwidth": 64944106,
-      "bitrate": 13952061,
-      "cache": "miss",
-      "device": "ios",
+      "bandwidth": 866167004,
+      "bitrate": 1009407834,
+      "cache": "local",
+      "device": "adr",
       "duration": 30000,
       "player": "sg-p2",
-      "position": 1045058586,
-      "protocol": "hls",
-      "rebuf_count": 2095695930,
-      "rebuf_duration": 38439,
-      "rel_position": 13,
+      "position": 1610131105,
+      "protocol": "stb",
+      "rebuf_count": 603924334,
+      "rebuf_duration": 11336,
+      "rel_position": 57,
       "url": "@veritasium#f/driverless-cars-are-already-here#1",
       "user_id": "432521"
    }'` + "\n" +
@@ -62,10 +62,20 @@ func ParseEndpoint(
 		reporterAddFlags    = flag.NewFlagSet("add", flag.ExitOnError)
 		reporterAddBodyFlag = reporterAddFlags.String("body", "REQUIRED", "")
 
+		reporterStatsFlags        = flag.NewFlagSet("stats", flag.ExitOnError)
+		reporterStatsClaimIDFlag  = reporterStatsFlags.String("claim-id", "REQUIRED", "Claim ID")
+		reporterStatsFromFlag     = reporterStatsFlags.String("from", "REQUIRED", "")
+		reporterStatsToFlag       = reporterStatsFlags.String("to", "REQUIRED", "")
+		reporterStatsBucketFlag   = reporterStatsFlags.String("bucket", "day", "")
+		reporterStatsPageFlag     = reporterStatsFlags.String("page", "1", "")
+		reporterStatsPageSizeFlag = reporterStatsFlags.String("page-size", "100", "")
+		reporterStatsKeyFlag      = reporterStatsFlags.String("key", "REQUIRED", "")
+
 		reporterHealthzFlags = flag.NewFlagSet("healthz", flag.ExitOnError)
 	)
 	reporterFlags.Usage = reporterUsage
 	reporterAddFlags.Usage = reporterAddUsage
+	reporterStatsFlags.Usage = reporterStatsUsage
 	reporterHealthzFlags.Usage = reporterHealthzUsage
 
 	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
@@ -105,6 +115,9 @@ func ParseEndpoint(
 			case "add":
 				epf = reporterAddFlags
 
+			case "stats":
+				epf = reporterStatsFlags
+
 			case "healthz":
 				epf = reporterHealthzFlags
 
@@ -136,6 +149,9 @@ func ParseEndpoint(
 			case "add":
 				endpoint = c.Add()
 				data, err = reporterc.BuildAddPayload(*reporterAddBodyFlag)
+			case "stats":
+				endpoint = c.Stats()
+				data, err = reporterc.BuildStatsPayload(*reporterStatsClaimIDFlag, *reporterStatsFromFlag, *reporterStatsToFlag, *reporterStatsBucketFlag, *reporterStatsPageFlag, *reporterStatsPageSizeFlag, *reporterStatsKeyFlag)
 			case "healthz":
 				endpoint = c.Healthz()
 				data = nil
@@ -157,6 +173,7 @@ Usage:
 
 COMMAND:
     add: Add implements add.
+    stats: Aggregate playback stats of a claim, bucketed by time
     healthz: Healthz implements healthz.
 
 Additional help:
@@ -171,23 +188,40 @@ Add implements add.
 
 Example:
     %[1]s reporter add --body '{
-      "bandwidth": 64944106,
-      "bitrate": 13952061,
-      "cache": "miss",
-      "device": "ios",
+      "bandwidth": 866167004,
+      "bitrate": 1009407834,
+      "cache": "local",
+      "device": "adr",
       "duration": 30000,
       "player": "sg-p2",
-      "position": 1045058586,
-      "protocol": "hls",
-      "rebuf_count": 2095695930,
-      "rebuf_duration": 38439,
-      "rel_position": 13,
+      "position": 1610131105,
+      "protocol": "stb",
+      "rebuf_count": 603924334,
+      "rebuf_duration": 11336,
+      "rel_position": 57,
       "url": "@veritasium#f/driverless-cars-are-already-here#1",
       "user_id": "432521"
    }'
 `, os.Args[0])
 }
 
+func reporterStatsUsage() {
+	fmt.Fprintf(os.Stderr, `%[1]s [flags] reporter stats -claim-id STRING -from STRING -to STRING -bucket STRING -page INT -page-size INT -key STRING
+
+Aggregate playback stats of a claim, bucketed by time
+    -claim-id STRING: Claim ID
+    -from STRING: 
+    -to STRING: 
+    -bucket STRING: 
+    -page INT: 
+    -page-size INT: 
+    -key STRING: 
+
+Example:
+    %[1]s reporter stats --claim-id "e7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67" --from "2003-12-01T03:14:16Z" --to "2009-04-23T09:54:34Z" --bucket "hour" --page 4499269243983337863 --page-size 493 --key "Rerum voluptas quod a."
+`, os.Args[0])
+}
+
 func reporterHealthzUsage() {
 	fmt.Fprintf(os.Stderr, `%[1]s [flags] reporter healthz
 
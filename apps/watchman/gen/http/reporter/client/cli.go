@@ -10,6 +10,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"unicode/utf8"
 
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
@@ -24,7 +25,7 @@ func BuildAddPayload(reporterAddBody string) (*reporter.PlaybackReport, error) {
 	{
 		err = json.Unmarshal([]byte(reporterAddBody), &body)
 		if err != nil {
-			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"bandwidth\": 64944106,\n      \"bitrate\": 13952061,\n      \"cache\": \"miss\",\n      \"device\": \"ios\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 1045058586,\n      \"protocol\": \"hls\",\n      \"rebuf_count\": 2095695930,\n      \"rebuf_duration\": 38439,\n      \"rel_position\": 13,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"bandwidth\": 866167004,\n      \"bitrate\": 1009407834,\n      \"cache\": \"local\",\n      \"device\": \"adr\",\n      \"duration\": 30000,\n      \"player\": \"sg-p2\",\n      \"position\": 1610131105,\n      \"protocol\": \"stb\",\n      \"rebuf_count\": 603924334,\n      \"rebuf_duration\": 11336,\n      \"rel_position\": 57,\n      \"url\": \"@veritasium#f/driverless-cars-are-already-here#1\",\n      \"user_id\": \"432521\"\n   }'")
 		}
 		if utf8.RuneCountInString(body.URL) > 512 {
 			err = goa.MergeErrors(err, goa.InvalidLengthError("body.url", body.URL, utf8.RuneCountInString(body.URL), 512, false))
@@ -95,3 +96,98 @@ func BuildAddPayload(reporterAddBody string) (*reporter.PlaybackReport, error) {
 
 	return v, nil
 }
+
+// BuildStatsPayload builds the payload for the reporter stats endpoint from
+// CLI flags.
+func BuildStatsPayload(reporterStatsClaimID string, reporterStatsFrom string, reporterStatsTo string, reporterStatsBucket string, reporterStatsPage string, reporterStatsPageSize string, reporterStatsKey string) (*reporter.StatsPayload, error) {
+	var err error
+	var claimID string
+	{
+		claimID = reporterStatsClaimID
+		err = goa.MergeErrors(err, goa.ValidatePattern("claimID", claimID, "^[a-f0-9]{40}$"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var from string
+	{
+		from = reporterStatsFrom
+		err = goa.MergeErrors(err, goa.ValidateFormat("from", from, goa.FormatDateTime))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	var to string
+	{
+		to = reporterStatsTo
+		err = goa.MergeErrors(err, goa.ValidateFormat("to", to, goa.FormatDateTime))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	var bucket string
+	{
+		if reporterStatsBucket != "" {
+			bucket = reporterStatsBucket
+			if !(bucket == "hour" || bucket == "day") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []interface{}{"hour", "day"}))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var page int
+	{
+		if reporterStatsPage != "" {
+			var v int64
+			v, err = strconv.ParseInt(reporterStatsPage, 10, 64)
+			page = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for page, must be INT")
+			}
+			if page < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var pageSize int
+	{
+		if reporterStatsPageSize != "" {
+			var v int64
+			v, err = strconv.ParseInt(reporterStatsPageSize, 10, 64)
+			pageSize = int(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for pageSize, must be INT")
+			}
+			if pageSize < 1 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("pageSize", pageSize, 1, true))
+			}
+			if pageSize > 500 {
+				err = goa.MergeErrors(err, goa.InvalidRangeError("pageSize", pageSize, 500, false))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	var key string
+	{
+		key = reporterStatsKey
+	}
+	v := &reporter.StatsPayload{}
+	v.ClaimID = claimID
+	v.From = from
+	v.To = to
+	v.Bucket = bucket
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v, nil
+}
@@ -20,6 +20,9 @@ type Client struct {
 	// Add Doer is the HTTP client used to make requests to the add endpoint.
 	AddDoer goahttp.Doer
 
+	// Stats Doer is the HTTP client used to make requests to the stats endpoint.
+	StatsDoer goahttp.Doer
+
 	// Healthz Doer is the HTTP client used to make requests to the healthz
 	// endpoint.
 	HealthzDoer goahttp.Doer
@@ -48,6 +51,7 @@ func NewClient(
 ) *Client {
 	return &Client{
 		AddDoer:             doer,
+		StatsDoer:           doer,
 		HealthzDoer:         doer,
 		CORSDoer:            doer,
 		RestoreResponseBody: restoreBody,
@@ -82,6 +86,30 @@ func (c *Client) Add() goa.Endpoint {
 	}
 }
 
+// Stats returns an endpoint that makes HTTP requests to the reporter service
+// stats server.
+func (c *Client) Stats() goa.Endpoint {
+	var (
+		encodeRequest  = EncodeStatsRequest(c.encoder)
+		decodeResponse = DecodeStatsResponse(c.decoder, c.RestoreResponseBody)
+	)
+	return func(ctx context.Context, v interface{}) (interface{}, error) {
+		req, err := c.BuildStatsRequest(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		err = encodeRequest(req, v)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.StatsDoer.Do(req)
+		if err != nil {
+			return nil, goahttp.ErrRequestError("reporter", "stats", err)
+		}
+		return decodeResponse(resp)
+	}
+}
+
 // Healthz returns an endpoint that makes HTTP requests to the reporter service
 // healthz server.
 func (c *Client) Healthz() goa.Endpoint {
@@ -10,12 +10,16 @@ package client
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	reporterviews "github.com/lbryio/lbrytv/apps/watchman/gen/reporter/views"
 	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
 )
 
 // BuildAddRequest instantiates a HTTP request object with method and path set
@@ -53,6 +57,7 @@ func EncodeAddRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Re
 // add endpoint. restoreBody controls whether the response body should be
 // restored after having been read.
 // DecodeAddResponse may return the following errors:
+//	- "maintenance" (type *reporter.MaintenanceError): http.StatusServiceUnavailable
 //	- "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
 //	- error: internal error
 func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (interface{}, error) {
@@ -72,6 +77,37 @@ func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody
 		switch resp.StatusCode {
 		case http.StatusCreated:
 			return nil, nil
+		case http.StatusServiceUnavailable:
+			var (
+				body AddMaintenanceResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "add", err)
+			}
+			err = ValidateAddMaintenanceResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "add", err)
+			}
+			var (
+				retryAfter int
+			)
+			{
+				retryAfterRaw := resp.Header.Get("Retry-After")
+				if retryAfterRaw == "" {
+					return nil, goahttp.ErrValidationError("reporter", "add", goa.MissingFieldError("Retry-After", "header"))
+				}
+				v, err2 := strconv.ParseInt(retryAfterRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("retryAfter", retryAfterRaw, "integer"))
+				}
+				retryAfter = int(v)
+			}
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "add", err)
+			}
+			return nil, NewAddMaintenance(&body, retryAfter)
 		case http.StatusBadRequest:
 			var (
 				body AddMultiFieldErrorResponseBody
@@ -93,6 +129,124 @@ func DecodeAddResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody
 	}
 }
 
+// BuildStatsRequest instantiates a HTTP request object with method and path
+// set to call the "reporter" service "stats" endpoint
+func (c *Client) BuildStatsRequest(ctx context.Context, v interface{}) (*http.Request, error) {
+	var (
+		claimID string
+	)
+	{
+		p, ok := v.(*reporter.StatsPayload)
+		if !ok {
+			return nil, goahttp.ErrInvalidType("reporter", "stats", "*reporter.StatsPayload", v)
+		}
+		claimID = p.ClaimID
+	}
+	u := &url.URL{Scheme: c.scheme, Host: c.host, Path: StatsReporterPath(claimID)}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, goahttp.ErrInvalidURL("reporter", "stats", u.String(), err)
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return req, nil
+}
+
+// EncodeStatsRequest returns an encoder for requests sent to the reporter
+// stats server.
+func EncodeStatsRequest(encoder func(*http.Request) goahttp.Encoder) func(*http.Request, interface{}) error {
+	return func(req *http.Request, v interface{}) error {
+		p, ok := v.(*reporter.StatsPayload)
+		if !ok {
+			return goahttp.ErrInvalidType("reporter", "stats", "*reporter.StatsPayload", v)
+		}
+		{
+			head := p.Key
+			req.Header.Set("X-Watchman-Key", head)
+		}
+		values := req.URL.Query()
+		values.Add("from", p.From)
+		values.Add("to", p.To)
+		values.Add("bucket", p.Bucket)
+		values.Add("page", fmt.Sprintf("%v", p.Page))
+		values.Add("page_size", fmt.Sprintf("%v", p.PageSize))
+		req.URL.RawQuery = values.Encode()
+		return nil
+	}
+}
+
+// DecodeStatsResponse returns a decoder for responses returned by the reporter
+// stats endpoint. restoreBody controls whether the response body should be
+// restored after having been read.
+// DecodeStatsResponse may return the following errors:
+//	- "multi_field_error" (type *reporter.MultiFieldError): http.StatusBadRequest
+//	- "unauthorized" (type reporter.Unauthorized): http.StatusUnauthorized
+//	- error: internal error
+func DecodeStatsResponse(decoder func(*http.Response) goahttp.Decoder, restoreBody bool) func(*http.Response) (interface{}, error) {
+	return func(resp *http.Response) (interface{}, error) {
+		if restoreBody {
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewBuffer(b))
+			defer func() {
+				resp.Body = ioutil.NopCloser(bytes.NewBuffer(b))
+			}()
+		} else {
+			defer resp.Body.Close()
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var (
+				body StatsResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "stats", err)
+			}
+			p := NewStatsWatchmanClaimStatsOK(&body)
+			view := "default"
+			vres := &reporterviews.WatchmanClaimStats{Projected: p, View: view}
+			if err = reporterviews.ValidateWatchmanClaimStats(vres); err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "stats", err)
+			}
+			res := reporter.NewWatchmanClaimStats(vres)
+			return res, nil
+		case http.StatusBadRequest:
+			var (
+				body StatsMultiFieldErrorResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "stats", err)
+			}
+			err = ValidateStatsMultiFieldErrorResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("reporter", "stats", err)
+			}
+			return nil, NewStatsMultiFieldError(&body)
+		case http.StatusUnauthorized:
+			var (
+				body StatsUnauthorizedResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("reporter", "stats", err)
+			}
+			return nil, NewStatsUnauthorized(body)
+		default:
+			body, _ := ioutil.ReadAll(resp.Body)
+			return nil, goahttp.ErrInvalidResponse("reporter", "stats", resp.StatusCode, string(body))
+		}
+	}
+}
+
 // BuildHealthzRequest instantiates a HTTP request object with method and path
 // set to call the "reporter" service "healthz" endpoint
 func (c *Client) BuildHealthzRequest(ctx context.Context, v interface{}) (*http.Request, error) {
@@ -142,3 +296,17 @@ func DecodeHealthzResponse(decoder func(*http.Response) goahttp.Decoder, restore
 		}
 	}
 }
+
+// unmarshalStatsBucketResponseBodyToReporterviewsStatsBucketView builds a
+// value of type *reporterviews.StatsBucketView from a value of type
+// *StatsBucketResponseBody.
+func unmarshalStatsBucketResponseBodyToReporterviewsStatsBucketView(v *StatsBucketResponseBody) *reporterviews.StatsBucketView {
+	res := &reporterviews.StatsBucketView{
+		Start:      v.Start,
+		Views:      v.Views,
+		AvgBitrate: v.AvgBitrate,
+		RebufRate:  v.RebufRate,
+	}
+
+	return res
+}
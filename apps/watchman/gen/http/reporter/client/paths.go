@@ -7,11 +7,20 @@
 
 package client
 
+import (
+	"fmt"
+)
+
 // AddReporterPath returns the URL path to the reporter service add HTTP endpoint.
 func AddReporterPath() string {
 	return "/reports/playback"
 }
 
+// StatsReporterPath returns the URL path to the reporter service stats HTTP endpoint.
+func StatsReporterPath(claimID string) string {
+	return fmt.Sprintf("/stats/claims/%v", claimID)
+}
+
 // HealthzReporterPath returns the URL path to the reporter service healthz HTTP endpoint.
 func HealthzReporterPath() string {
 	return "/healthz"
@@ -9,6 +9,7 @@ package client
 
 import (
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	reporterviews "github.com/lbryio/lbrytv/apps/watchman/gen/reporter/views"
 	goa "goa.design/goa/v3/pkg"
 )
 
@@ -44,12 +45,57 @@ type AddRequestBody struct {
 	Device string `form:"device" json:"device" xml:"device"`
 }
 
+// StatsResponseBody is the type of the "reporter" service "stats" endpoint
+// HTTP response body.
+type StatsResponseBody struct {
+	// Claim ID
+	ClaimID *string `form:"claim_id,omitempty" json:"claim_id,omitempty" xml:"claim_id,omitempty"`
+	// Time bucket size
+	Bucket *string `form:"bucket,omitempty" json:"bucket,omitempty" xml:"bucket,omitempty"`
+	// Page number
+	Page *int `form:"page,omitempty" json:"page,omitempty" xml:"page,omitempty"`
+	// Number of buckets per page
+	PageSize *int `form:"page_size,omitempty" json:"page_size,omitempty" xml:"page_size,omitempty"`
+	// Whether more buckets are available on the next page
+	HasMore *bool `form:"has_more,omitempty" json:"has_more,omitempty" xml:"has_more,omitempty"`
+	// Stats buckets, oldest first
+	Buckets []*StatsBucketResponseBody `form:"buckets,omitempty" json:"buckets,omitempty" xml:"buckets,omitempty"`
+}
+
+// AddMaintenanceResponseBody is the type of the "reporter" service "add"
+// endpoint HTTP response body for the "maintenance" error.
+type AddMaintenanceResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
 // AddMultiFieldErrorResponseBody is the type of the "reporter" service "add"
 // endpoint HTTP response body for the "multi_field_error" error.
 type AddMultiFieldErrorResponseBody struct {
 	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
 }
 
+// StatsMultiFieldErrorResponseBody is the type of the "reporter" service
+// "stats" endpoint HTTP response body for the "multi_field_error" error.
+type StatsMultiFieldErrorResponseBody struct {
+	Message *string `form:"message,omitempty" json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// StatsUnauthorizedResponseBody is the type of the "reporter" service "stats"
+// endpoint HTTP response body for the "unauthorized" error.
+type StatsUnauthorizedResponseBody string
+
+// StatsBucketResponseBody is used to define fields on response body types.
+type StatsBucketResponseBody struct {
+	// Bucket start time
+	Start *string `form:"start,omitempty" json:"start,omitempty" xml:"start,omitempty"`
+	// Number of distinct viewers
+	Views *int64 `form:"views,omitempty" json:"views,omitempty" xml:"views,omitempty"`
+	// Average media bitrate, bit/s
+	AvgBitrate *float64 `form:"avg_bitrate,omitempty" json:"avg_bitrate,omitempty" xml:"avg_bitrate,omitempty"`
+	// Share of playback time spent rebuffering, 0—1
+	RebufRate *float64 `form:"rebuf_rate,omitempty" json:"rebuf_rate,omitempty" xml:"rebuf_rate,omitempty"`
+}
+
 // NewAddRequestBody builds the HTTP request body from the payload of the "add"
 // endpoint of the "reporter" service.
 func NewAddRequestBody(p *reporter.PlaybackReport) *AddRequestBody {
@@ -71,6 +117,16 @@ func NewAddRequestBody(p *reporter.PlaybackReport) *AddRequestBody {
 	return body
 }
 
+// NewAddMaintenance builds a reporter service add endpoint maintenance error.
+func NewAddMaintenance(body *AddMaintenanceResponseBody, retryAfter int) *reporter.MaintenanceError {
+	v := &reporter.MaintenanceError{
+		Message: *body.Message,
+	}
+	v.RetryAfter = retryAfter
+
+	return v
+}
+
 // NewAddMultiFieldError builds a reporter service add endpoint
 // multi_field_error error.
 func NewAddMultiFieldError(body *AddMultiFieldErrorResponseBody) *reporter.MultiFieldError {
@@ -81,6 +137,51 @@ func NewAddMultiFieldError(body *AddMultiFieldErrorResponseBody) *reporter.Multi
 	return v
 }
 
+// NewStatsWatchmanClaimStatsOK builds a "reporter" service "stats" endpoint
+// result from a HTTP "OK" response.
+func NewStatsWatchmanClaimStatsOK(body *StatsResponseBody) *reporterviews.WatchmanClaimStatsView {
+	v := &reporterviews.WatchmanClaimStatsView{
+		ClaimID:  body.ClaimID,
+		Bucket:   body.Bucket,
+		Page:     body.Page,
+		PageSize: body.PageSize,
+		HasMore:  body.HasMore,
+	}
+	v.Buckets = make([]*reporterviews.StatsBucketView, len(body.Buckets))
+	for i, val := range body.Buckets {
+		v.Buckets[i] = unmarshalStatsBucketResponseBodyToReporterviewsStatsBucketView(val)
+	}
+
+	return v
+}
+
+// NewStatsMultiFieldError builds a reporter service stats endpoint
+// multi_field_error error.
+func NewStatsMultiFieldError(body *StatsMultiFieldErrorResponseBody) *reporter.MultiFieldError {
+	v := &reporter.MultiFieldError{
+		Message: *body.Message,
+	}
+
+	return v
+}
+
+// NewStatsUnauthorized builds a reporter service stats endpoint unauthorized
+// error.
+func NewStatsUnauthorized(body StatsUnauthorizedResponseBody) reporter.Unauthorized {
+	v := reporter.Unauthorized(body)
+
+	return v
+}
+
+// ValidateAddMaintenanceResponseBody runs the validations defined on
+// add_maintenance_response_body
+func ValidateAddMaintenanceResponseBody(body *AddMaintenanceResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
 // ValidateAddMultiFieldErrorResponseBody runs the validations defined on
 // add_multi_field_error_response_body
 func ValidateAddMultiFieldErrorResponseBody(body *AddMultiFieldErrorResponseBody) (err error) {
@@ -89,3 +190,33 @@ func ValidateAddMultiFieldErrorResponseBody(body *AddMultiFieldErrorResponseBody
 	}
 	return
 }
+
+// ValidateStatsMultiFieldErrorResponseBody runs the validations defined on
+// stats_multi_field_error_response_body
+func ValidateStatsMultiFieldErrorResponseBody(body *StatsMultiFieldErrorResponseBody) (err error) {
+	if body.Message == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("message", "body"))
+	}
+	return
+}
+
+// ValidateStatsBucketResponseBody runs the validations defined on
+// StatsBucketResponseBody
+func ValidateStatsBucketResponseBody(body *StatsBucketResponseBody) (err error) {
+	if body.Start == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("start", "body"))
+	}
+	if body.Views == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("views", "body"))
+	}
+	if body.AvgBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("avg_bitrate", "body"))
+	}
+	if body.RebufRate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuf_rate", "body"))
+	}
+	if body.Start != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.start", *body.Start, goa.FormatDateTime))
+	}
+	return
+}
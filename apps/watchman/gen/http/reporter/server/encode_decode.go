@@ -11,8 +11,11 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	reporterviews "github.com/lbryio/lbrytv/apps/watchman/gen/reporter/views"
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
 )
@@ -61,6 +64,23 @@ func EncodeAddError(encoder func(context.Context, http.ResponseWriter) goahttp.E
 			return encodeError(ctx, w, v)
 		}
 		switch en.ErrorName() {
+		case "maintenance":
+			res := v.(*reporter.MaintenanceError)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(res)
+			} else {
+				body = NewAddMaintenanceResponseBody(res)
+			}
+			{
+				val := res.RetryAfter
+				retryAfters := strconv.Itoa(val)
+				w.Header().Set("Retry-After", retryAfters)
+			}
+			w.Header().Set("goa-error", res.ErrorName())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return enc.Encode(body)
 		case "multi_field_error":
 			res := v.(*reporter.MultiFieldError)
 			enc := encoder(ctx, w)
@@ -79,6 +99,148 @@ func EncodeAddError(encoder func(context.Context, http.ResponseWriter) goahttp.E
 	}
 }
 
+// EncodeStatsResponse returns an encoder for responses returned by the
+// reporter stats endpoint.
+func EncodeStatsResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*reporterviews.WatchmanClaimStats)
+		enc := encoder(ctx, w)
+		body := NewStatsResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeStatsRequest returns a decoder for requests sent to the reporter stats
+// endpoint.
+func DecodeStatsRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			claimID  string
+			from     string
+			to       string
+			bucket   string
+			page     int
+			pageSize int
+			key      string
+			err      error
+
+			params = mux.Vars(r)
+		)
+		claimID = params["claim_id"]
+		err = goa.MergeErrors(err, goa.ValidatePattern("claimID", claimID, "^[a-f0-9]{40}$"))
+		from = r.URL.Query().Get("from")
+		if from == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("from", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("from", from, goa.FormatDateTime))
+
+		to = r.URL.Query().Get("to")
+		if to == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("to", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("to", to, goa.FormatDateTime))
+
+		bucketRaw := r.URL.Query().Get("bucket")
+		if bucketRaw != "" {
+			bucket = bucketRaw
+		} else {
+			bucket = "day"
+		}
+		if !(bucket == "hour" || bucket == "day") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("bucket", bucket, []interface{}{"hour", "day"}))
+		}
+		{
+			pageRaw := r.URL.Query().Get("page")
+			if pageRaw == "" {
+				page = 1
+			} else {
+				v, err2 := strconv.ParseInt(pageRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("page", pageRaw, "integer"))
+				}
+				page = int(v)
+			}
+		}
+		if page < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("page", page, 1, true))
+		}
+		{
+			pageSizeRaw := r.URL.Query().Get("page_size")
+			if pageSizeRaw == "" {
+				pageSize = 100
+			} else {
+				v, err2 := strconv.ParseInt(pageSizeRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("pageSize", pageSizeRaw, "integer"))
+				}
+				pageSize = int(v)
+			}
+		}
+		if pageSize < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("pageSize", pageSize, 1, true))
+		}
+		if pageSize > 500 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("pageSize", pageSize, 500, false))
+		}
+		key = r.Header.Get("X-Watchman-Key")
+		if key == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("X-Watchman-Key", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewStatsPayload(claimID, from, to, bucket, page, pageSize, key)
+		if strings.Contains(payload.Key, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.Key, " ", 2)[1]
+			payload.Key = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeStatsError returns an encoder for errors returned by the stats
+// reporter endpoint.
+func EncodeStatsError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		en, ok := v.(ErrorNamer)
+		if !ok {
+			return encodeError(ctx, w, v)
+		}
+		switch en.ErrorName() {
+		case "multi_field_error":
+			res := v.(*reporter.MultiFieldError)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(res)
+			} else {
+				body = NewStatsMultiFieldErrorResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.ErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "unauthorized":
+			res := v.(reporter.Unauthorized)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(res)
+			} else {
+				body = NewStatsUnauthorizedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.ErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return enc.Encode(body)
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
 // EncodeHealthzResponse returns an encoder for responses returned by the
 // reporter healthz endpoint.
 func EncodeHealthzResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
@@ -90,3 +252,17 @@ func EncodeHealthzResponse(encoder func(context.Context, http.ResponseWriter) go
 		return enc.Encode(body)
 	}
 }
+
+// marshalReporterviewsStatsBucketViewToStatsBucketResponseBody builds a value
+// of type *StatsBucketResponseBody from a value of type
+// *reporterviews.StatsBucketView.
+func marshalReporterviewsStatsBucketViewToStatsBucketResponseBody(v *reporterviews.StatsBucketView) *StatsBucketResponseBody {
+	res := &StatsBucketResponseBody{
+		Start:      *v.Start,
+		Views:      *v.Views,
+		AvgBitrate: *v.AvgBitrate,
+		RebufRate:  *v.RebufRate,
+	}
+
+	return res
+}
@@ -22,6 +22,7 @@ import (
 type Server struct {
 	Mounts  []*MountPoint
 	Add     http.Handler
+	Stats   http.Handler
 	Healthz http.Handler
 	CORS    http.Handler
 }
@@ -60,11 +61,14 @@ func New(
 	return &Server{
 		Mounts: []*MountPoint{
 			{"Add", "POST", "/reports/playback"},
+			{"Stats", "GET", "/stats/claims/{claim_id}"},
 			{"Healthz", "GET", "/healthz"},
 			{"CORS", "OPTIONS", "/reports/playback"},
+			{"CORS", "OPTIONS", "/stats/claims/{claim_id}"},
 			{"CORS", "OPTIONS", "/healthz"},
 		},
 		Add:     NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
+		Stats:   NewStatsHandler(e.Stats, mux, decoder, encoder, errhandler, formatter),
 		Healthz: NewHealthzHandler(e.Healthz, mux, decoder, encoder, errhandler, formatter),
 		CORS:    NewCORSHandler(),
 	}
@@ -76,6 +80,7 @@ func (s *Server) Service() string { return "reporter" }
 // Use wraps the server handlers with the given middleware.
 func (s *Server) Use(m func(http.Handler) http.Handler) {
 	s.Add = m(s.Add)
+	s.Stats = m(s.Stats)
 	s.Healthz = m(s.Healthz)
 	s.CORS = m(s.CORS)
 }
@@ -83,6 +88,7 @@ func (s *Server) Use(m func(http.Handler) http.Handler) {
 // Mount configures the mux to serve the reporter endpoints.
 func Mount(mux goahttp.Muxer, h *Server) {
 	MountAddHandler(mux, h.Add)
+	MountStatsHandler(mux, h.Stats)
 	MountHealthzHandler(mux, h.Healthz)
 	MountCORSHandler(mux, h.CORS)
 }
@@ -138,6 +144,57 @@ func NewAddHandler(
 	})
 }
 
+// MountStatsHandler configures the mux to serve the "reporter" service "stats"
+// endpoint.
+func MountStatsHandler(mux goahttp.Muxer, h http.Handler) {
+	f, ok := HandleReporterOrigin(h).(http.HandlerFunc)
+	if !ok {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+		}
+	}
+	mux.Handle("GET", "/stats/claims/{claim_id}", f)
+}
+
+// NewStatsHandler creates a HTTP handler which loads the HTTP request and
+// calls the "reporter" service "stats" endpoint.
+func NewStatsHandler(
+	endpoint goa.Endpoint,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(err error) goahttp.Statuser,
+) http.Handler {
+	var (
+		decodeRequest  = DecodeStatsRequest(mux, decoder)
+		encodeResponse = EncodeStatsResponse(encoder)
+		encodeError    = EncodeStatsError(encoder, formatter)
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), goahttp.AcceptTypeKey, r.Header.Get("Accept"))
+		ctx = context.WithValue(ctx, goa.MethodKey, "stats")
+		ctx = context.WithValue(ctx, goa.ServiceKey, "reporter")
+		payload, err := decodeRequest(r)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		res, err := endpoint(ctx, payload)
+		if err != nil {
+			if err := encodeError(ctx, w, err); err != nil {
+				errhandler(ctx, w, err)
+			}
+			return
+		}
+		if err := encodeResponse(ctx, w, res); err != nil {
+			errhandler(ctx, w, err)
+		}
+	})
+}
+
 // MountHealthzHandler configures the mux to serve the "reporter" service
 // "healthz" endpoint.
 func MountHealthzHandler(mux goahttp.Muxer, h http.Handler) {
@@ -193,6 +250,7 @@ func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
 		}
 	}
 	mux.Handle("OPTIONS", "/reports/playback", f)
+	mux.Handle("OPTIONS", "/stats/claims/{claim_id}", f)
 	mux.Handle("OPTIONS", "/healthz", f)
 }
 
@@ -222,7 +280,7 @@ func HandleReporterOrigin(h http.Handler) http.Handler {
 			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
 				// We are handling a preflight request
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-				w.Header().Set("Access-Control-Allow-Headers", "content-type")
+				w.Header().Set("Access-Control-Allow-Headers", "content-type, x-watchman-key")
 			}
 			origHndlr(w, r)
 			return
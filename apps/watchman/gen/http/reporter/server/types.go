@@ -11,6 +11,7 @@ import (
 	"unicode/utf8"
 
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	reporterviews "github.com/lbryio/lbrytv/apps/watchman/gen/reporter/views"
 	goa "goa.design/goa/v3/pkg"
 )
 
@@ -46,12 +47,85 @@ type AddRequestBody struct {
 	Device *string `form:"device,omitempty" json:"device,omitempty" xml:"device,omitempty"`
 }
 
+// StatsResponseBody is the type of the "reporter" service "stats" endpoint
+// HTTP response body.
+type StatsResponseBody struct {
+	// Claim ID
+	ClaimID string `form:"claim_id" json:"claim_id" xml:"claim_id"`
+	// Time bucket size
+	Bucket string `form:"bucket" json:"bucket" xml:"bucket"`
+	// Page number
+	Page int `form:"page" json:"page" xml:"page"`
+	// Number of buckets per page
+	PageSize int `form:"page_size" json:"page_size" xml:"page_size"`
+	// Whether more buckets are available on the next page
+	HasMore bool `form:"has_more" json:"has_more" xml:"has_more"`
+	// Stats buckets, oldest first
+	Buckets []*StatsBucketResponseBody `form:"buckets" json:"buckets" xml:"buckets"`
+}
+
+// AddMaintenanceResponseBody is the type of the "reporter" service "add"
+// endpoint HTTP response body for the "maintenance" error.
+type AddMaintenanceResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
 // AddMultiFieldErrorResponseBody is the type of the "reporter" service "add"
 // endpoint HTTP response body for the "multi_field_error" error.
 type AddMultiFieldErrorResponseBody struct {
 	Message string `form:"message" json:"message" xml:"message"`
 }
 
+// StatsMultiFieldErrorResponseBody is the type of the "reporter" service
+// "stats" endpoint HTTP response body for the "multi_field_error" error.
+type StatsMultiFieldErrorResponseBody struct {
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// StatsUnauthorizedResponseBody is the type of the "reporter" service "stats"
+// endpoint HTTP response body for the "unauthorized" error.
+type StatsUnauthorizedResponseBody string
+
+// StatsBucketResponseBody is used to define fields on response body types.
+type StatsBucketResponseBody struct {
+	// Bucket start time
+	Start string `form:"start" json:"start" xml:"start"`
+	// Number of distinct viewers
+	Views int64 `form:"views" json:"views" xml:"views"`
+	// Average media bitrate, bit/s
+	AvgBitrate float64 `form:"avg_bitrate" json:"avg_bitrate" xml:"avg_bitrate"`
+	// Share of playback time spent rebuffering, 0—1
+	RebufRate float64 `form:"rebuf_rate" json:"rebuf_rate" xml:"rebuf_rate"`
+}
+
+// NewStatsResponseBody builds the HTTP response body from the result of the
+// "stats" endpoint of the "reporter" service.
+func NewStatsResponseBody(res *reporterviews.WatchmanClaimStatsView) *StatsResponseBody {
+	body := &StatsResponseBody{
+		ClaimID:  *res.ClaimID,
+		Bucket:   *res.Bucket,
+		Page:     *res.Page,
+		PageSize: *res.PageSize,
+		HasMore:  *res.HasMore,
+	}
+	if res.Buckets != nil {
+		body.Buckets = make([]*StatsBucketResponseBody, len(res.Buckets))
+		for i, val := range res.Buckets {
+			body.Buckets[i] = marshalReporterviewsStatsBucketViewToStatsBucketResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewAddMaintenanceResponseBody builds the HTTP response body from the result
+// of the "add" endpoint of the "reporter" service.
+func NewAddMaintenanceResponseBody(res *reporter.MaintenanceError) *AddMaintenanceResponseBody {
+	body := &AddMaintenanceResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
 // NewAddMultiFieldErrorResponseBody builds the HTTP response body from the
 // result of the "add" endpoint of the "reporter" service.
 func NewAddMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *AddMultiFieldErrorResponseBody {
@@ -61,6 +135,22 @@ func NewAddMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *AddMultiF
 	return body
 }
 
+// NewStatsMultiFieldErrorResponseBody builds the HTTP response body from the
+// result of the "stats" endpoint of the "reporter" service.
+func NewStatsMultiFieldErrorResponseBody(res *reporter.MultiFieldError) *StatsMultiFieldErrorResponseBody {
+	body := &StatsMultiFieldErrorResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewStatsUnauthorizedResponseBody builds the HTTP response body from the
+// result of the "stats" endpoint of the "reporter" service.
+func NewStatsUnauthorizedResponseBody(res reporter.Unauthorized) StatsUnauthorizedResponseBody {
+	body := StatsUnauthorizedResponseBody(res)
+	return body
+}
+
 // NewAddPlaybackReport builds a reporter service add endpoint payload.
 func NewAddPlaybackReport(body *AddRequestBody) *reporter.PlaybackReport {
 	v := &reporter.PlaybackReport{
@@ -82,6 +172,20 @@ func NewAddPlaybackReport(body *AddRequestBody) *reporter.PlaybackReport {
 	return v
 }
 
+// NewStatsPayload builds a reporter service stats endpoint payload.
+func NewStatsPayload(claimID string, from string, to string, bucket string, page int, pageSize int, key string) *reporter.StatsPayload {
+	v := &reporter.StatsPayload{}
+	v.ClaimID = claimID
+	v.From = from
+	v.To = to
+	v.Bucket = bucket
+	v.Page = page
+	v.PageSize = pageSize
+	v.Key = key
+
+	return v
+}
+
 // ValidateAddRequestBody runs the validations defined on AddRequestBody
 func ValidateAddRequestBody(body *AddRequestBody) (err error) {
 	if body.URL == nil {
@@ -16,13 +16,15 @@ import (
 // Client is the "reporter" service client.
 type Client struct {
 	AddEndpoint     goa.Endpoint
+	StatsEndpoint   goa.Endpoint
 	HealthzEndpoint goa.Endpoint
 }
 
 // NewClient initializes a "reporter" service client given the endpoints.
-func NewClient(add, healthz goa.Endpoint) *Client {
+func NewClient(add, stats, healthz goa.Endpoint) *Client {
 	return &Client{
 		AddEndpoint:     add,
+		StatsEndpoint:   stats,
 		HealthzEndpoint: healthz,
 	}
 }
@@ -33,6 +35,20 @@ func (c *Client) Add(ctx context.Context, p *PlaybackReport) (err error) {
 	return
 }
 
+// Stats calls the "stats" endpoint of the "reporter" service.
+// Stats may return the following errors:
+//	- "unauthorized" (type Unauthorized)
+//	- "multi_field_error" (type *MultiFieldError)
+//	- error: internal error
+func (c *Client) Stats(ctx context.Context, p *StatsPayload) (res *WatchmanClaimStats, err error) {
+	var ires interface{}
+	ires, err = c.StatsEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*WatchmanClaimStats), nil
+}
+
 // Healthz calls the "healthz" endpoint of the "reporter" service.
 func (c *Client) Healthz(ctx context.Context) (res string, err error) {
 	var ires interface{}
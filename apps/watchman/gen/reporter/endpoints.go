@@ -11,18 +11,23 @@ import (
 	"context"
 
 	goa "goa.design/goa/v3/pkg"
+	"goa.design/goa/v3/security"
 )
 
 // Endpoints wraps the "reporter" service endpoints.
 type Endpoints struct {
 	Add     goa.Endpoint
+	Stats   goa.Endpoint
 	Healthz goa.Endpoint
 }
 
 // NewEndpoints wraps the methods of the "reporter" service with endpoints.
 func NewEndpoints(s Service) *Endpoints {
+	// Casting service to Auther interface
+	a := s.(Auther)
 	return &Endpoints{
 		Add:     NewAddEndpoint(s),
+		Stats:   NewStatsEndpoint(s, a.APIKeyAuth),
 		Healthz: NewHealthzEndpoint(s),
 	}
 }
@@ -30,6 +35,7 @@ func NewEndpoints(s Service) *Endpoints {
 // Use applies the given middleware to all the "reporter" service endpoints.
 func (e *Endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
 	e.Add = m(e.Add)
+	e.Stats = m(e.Stats)
 	e.Healthz = m(e.Healthz)
 }
 
@@ -42,6 +48,30 @@ func NewAddEndpoint(s Service) goa.Endpoint {
 	}
 }
 
+// NewStatsEndpoint returns an endpoint function that calls the method "stats"
+// of service "reporter".
+func NewStatsEndpoint(s Service, authAPIKeyFn security.AuthAPIKeyFunc) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		p := req.(*StatsPayload)
+		var err error
+		sc := security.APIKeyScheme{
+			Name:           "stats_key",
+			Scopes:         []string{},
+			RequiredScopes: []string{},
+		}
+		ctx, err = authAPIKeyFn(ctx, p.Key, &sc)
+		if err != nil {
+			return nil, err
+		}
+		res, err := s.Stats(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		vres := NewViewedWatchmanClaimStats(res, "default")
+		return vres, nil
+	}
+}
+
 // NewHealthzEndpoint returns an endpoint function that calls the method
 // "healthz" of service "reporter".
 func NewHealthzEndpoint(s Service) goa.Endpoint {
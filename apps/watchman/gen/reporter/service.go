@@ -9,16 +9,27 @@ package reporter
 
 import (
 	"context"
+
+	reporterviews "github.com/lbryio/lbrytv/apps/watchman/gen/reporter/views"
+	"goa.design/goa/v3/security"
 )
 
 // Media playback reports
 type Service interface {
 	// Add implements add.
 	Add(context.Context, *PlaybackReport) (err error)
+	// Aggregate playback stats of a claim, bucketed by time
+	Stats(context.Context, *StatsPayload) (res *WatchmanClaimStats, err error)
 	// Healthz implements healthz.
 	Healthz(context.Context) (res string, err error)
 }
 
+// Auther defines the authorization functions to be implemented by the service.
+type Auther interface {
+	// APIKeyAuth implements the authorization logic for the APIKey security scheme.
+	APIKeyAuth(ctx context.Context, key string, schema *security.APIKeyScheme) (context.Context, error)
+}
+
 // ServiceName is the name of the service as defined in the design. This is the
 // same value that is set in the endpoint request contexts under the ServiceKey
 // key.
@@ -27,7 +38,7 @@ const ServiceName = "reporter"
 // MethodNames lists the service method names as defined in the design. These
 // are the same values that are set in the endpoint request contexts under the
 // MethodKey key.
-var MethodNames = [2]string{"add", "healthz"}
+var MethodNames = [3]string{"add", "stats", "healthz"}
 
 // PlaybackReport is the payload type of the reporter service add method.
 type PlaybackReport struct {
@@ -60,12 +71,68 @@ type PlaybackReport struct {
 	Device string
 }
 
+// StatsPayload is the payload type of the reporter service stats method.
+type StatsPayload struct {
+	// Stats API key
+	Key string
+	// Claim ID
+	ClaimID string
+	// Start of the time range
+	From string
+	// End of the time range (exclusive)
+	To string
+	// Time bucket size
+	Bucket string
+	// Page number
+	Page int
+	// Number of buckets per page
+	PageSize int
+}
+
+// WatchmanClaimStats is the result type of the reporter service stats method.
+type WatchmanClaimStats struct {
+	// Claim ID
+	ClaimID string
+	// Time bucket size
+	Bucket string
+	// Page number
+	Page int
+	// Number of buckets per page
+	PageSize int
+	// Whether more buckets are available on the next page
+	HasMore bool
+	// Stats buckets, oldest first
+	Buckets []*StatsBucket
+}
+
+type StatsBucket struct {
+	// Bucket start time
+	Start string
+	// Number of distinct viewers
+	Views int64
+	// Average media bitrate, bit/s
+	AvgBitrate float64
+	// Share of playback time spent rebuffering, 0—1
+	RebufRate float64
+}
+
 // MultiFieldError is the error returned when several fields failed a
 // validation rule.
 type MultiFieldError struct {
 	Message string
 }
 
+// MaintenanceError is returned when the service is under maintenance or
+// overloaded and doesn't accept reports.
+type MaintenanceError struct {
+	Message string
+	// Number of seconds after which the client should retry
+	RetryAfter int
+}
+
+// Invalid or missing stats key
+type Unauthorized string
+
 // Error returns an error description.
 func (e *MultiFieldError) Error() string {
 	return "MultiFieldError is the error returned when several fields failed a validation rule."
@@ -75,3 +142,112 @@ func (e *MultiFieldError) Error() string {
 func (e *MultiFieldError) ErrorName() string {
 	return "multi_field_error"
 }
+
+// Error returns an error description.
+func (e *MaintenanceError) Error() string {
+	return "MaintenanceError is returned when the service is under maintenance or overloaded and doesn't accept reports."
+}
+
+// ErrorName returns "MaintenanceError".
+func (e *MaintenanceError) ErrorName() string {
+	return "maintenance"
+}
+
+// Error returns an error description.
+func (e Unauthorized) Error() string {
+	return "Invalid or missing stats key"
+}
+
+// ErrorName returns "unauthorized".
+func (e Unauthorized) ErrorName() string {
+	return "unauthorized"
+}
+
+// NewWatchmanClaimStats initializes result type WatchmanClaimStats from viewed
+// result type WatchmanClaimStats.
+func NewWatchmanClaimStats(vres *reporterviews.WatchmanClaimStats) *WatchmanClaimStats {
+	return newWatchmanClaimStats(vres.Projected)
+}
+
+// NewViewedWatchmanClaimStats initializes viewed result type
+// WatchmanClaimStats from result type WatchmanClaimStats using the given view.
+func NewViewedWatchmanClaimStats(res *WatchmanClaimStats, view string) *reporterviews.WatchmanClaimStats {
+	p := newWatchmanClaimStatsView(res)
+	return &reporterviews.WatchmanClaimStats{Projected: p, View: "default"}
+}
+
+// newWatchmanClaimStats converts projected type WatchmanClaimStats to service
+// type WatchmanClaimStats.
+func newWatchmanClaimStats(vres *reporterviews.WatchmanClaimStatsView) *WatchmanClaimStats {
+	res := &WatchmanClaimStats{}
+	if vres.ClaimID != nil {
+		res.ClaimID = *vres.ClaimID
+	}
+	if vres.Bucket != nil {
+		res.Bucket = *vres.Bucket
+	}
+	if vres.Page != nil {
+		res.Page = *vres.Page
+	}
+	if vres.PageSize != nil {
+		res.PageSize = *vres.PageSize
+	}
+	if vres.HasMore != nil {
+		res.HasMore = *vres.HasMore
+	}
+	if vres.Buckets != nil {
+		res.Buckets = make([]*StatsBucket, len(vres.Buckets))
+		for i, val := range vres.Buckets {
+			res.Buckets[i] = transformReporterviewsStatsBucketViewToStatsBucket(val)
+		}
+	}
+	return res
+}
+
+// newWatchmanClaimStatsView projects result type WatchmanClaimStats to
+// projected type WatchmanClaimStatsView using the "default" view.
+func newWatchmanClaimStatsView(res *WatchmanClaimStats) *reporterviews.WatchmanClaimStatsView {
+	vres := &reporterviews.WatchmanClaimStatsView{
+		ClaimID:  &res.ClaimID,
+		Bucket:   &res.Bucket,
+		Page:     &res.Page,
+		PageSize: &res.PageSize,
+		HasMore:  &res.HasMore,
+	}
+	if res.Buckets != nil {
+		vres.Buckets = make([]*reporterviews.StatsBucketView, len(res.Buckets))
+		for i, val := range res.Buckets {
+			vres.Buckets[i] = transformStatsBucketToReporterviewsStatsBucketView(val)
+		}
+	}
+	return vres
+}
+
+// transformReporterviewsStatsBucketViewToStatsBucket builds a value of type
+// *StatsBucket from a value of type *reporterviews.StatsBucketView.
+func transformReporterviewsStatsBucketViewToStatsBucket(v *reporterviews.StatsBucketView) *StatsBucket {
+	if v == nil {
+		return nil
+	}
+	res := &StatsBucket{
+		Start:      *v.Start,
+		Views:      *v.Views,
+		AvgBitrate: *v.AvgBitrate,
+		RebufRate:  *v.RebufRate,
+	}
+
+	return res
+}
+
+// transformStatsBucketToReporterviewsStatsBucketView builds a value of type
+// *reporterviews.StatsBucketView from a value of type *StatsBucket.
+func transformStatsBucketToReporterviewsStatsBucketView(v *StatsBucket) *reporterviews.StatsBucketView {
+	res := &reporterviews.StatsBucketView{
+		Start:      &v.Start,
+		Views:      &v.Views,
+		AvgBitrate: &v.AvgBitrate,
+		RebufRate:  &v.RebufRate,
+	}
+
+	return res
+}
@@ -0,0 +1,127 @@
+// Code generated by goa v3.5.2, DO NOT EDIT.
+//
+// reporter views
+//
+// Command:
+// $ goa gen github.com/lbryio/lbrytv/apps/watchman/design -o apps/watchman
+
+package views
+
+import (
+	goa "goa.design/goa/v3/pkg"
+)
+
+// WatchmanClaimStats is the viewed result type that is projected based on a
+// view.
+type WatchmanClaimStats struct {
+	// Type to project
+	Projected *WatchmanClaimStatsView
+	// View to render
+	View string
+}
+
+// WatchmanClaimStatsView is a type that runs validations on a projected type.
+type WatchmanClaimStatsView struct {
+	// Claim ID
+	ClaimID *string
+	// Time bucket size
+	Bucket *string
+	// Page number
+	Page *int
+	// Number of buckets per page
+	PageSize *int
+	// Whether more buckets are available on the next page
+	HasMore *bool
+	// Stats buckets, oldest first
+	Buckets []*StatsBucketView
+}
+
+// StatsBucketView is a type that runs validations on a projected type.
+type StatsBucketView struct {
+	// Bucket start time
+	Start *string
+	// Number of distinct viewers
+	Views *int64
+	// Average media bitrate, bit/s
+	AvgBitrate *float64
+	// Share of playback time spent rebuffering, 0—1
+	RebufRate *float64
+}
+
+var (
+	// WatchmanClaimStatsMap is a map indexing the attribute names of
+	// WatchmanClaimStats by view name.
+	WatchmanClaimStatsMap = map[string][]string{
+		"default": {
+			"claim_id",
+			"bucket",
+			"page",
+			"page_size",
+			"has_more",
+			"buckets",
+		},
+	}
+)
+
+// ValidateWatchmanClaimStats runs the validations defined on the viewed result
+// type WatchmanClaimStats.
+func ValidateWatchmanClaimStats(result *WatchmanClaimStats) (err error) {
+	switch result.View {
+	case "default", "":
+		err = ValidateWatchmanClaimStatsView(result.Projected)
+	default:
+		err = goa.InvalidEnumValueError("view", result.View, []interface{}{"default"})
+	}
+	return
+}
+
+// ValidateWatchmanClaimStatsView runs the validations defined on
+// WatchmanClaimStatsView using the "default" view.
+func ValidateWatchmanClaimStatsView(result *WatchmanClaimStatsView) (err error) {
+	if result.ClaimID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("claim_id", "result"))
+	}
+	if result.Bucket == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("bucket", "result"))
+	}
+	if result.Page == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page", "result"))
+	}
+	if result.PageSize == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("page_size", "result"))
+	}
+	if result.HasMore == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("has_more", "result"))
+	}
+	if result.Buckets == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("buckets", "result"))
+	}
+	for _, e := range result.Buckets {
+		if e != nil {
+			if err2 := ValidateStatsBucketView(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// ValidateStatsBucketView runs the validations defined on StatsBucketView.
+func ValidateStatsBucketView(result *StatsBucketView) (err error) {
+	if result.Start == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("start", "result"))
+	}
+	if result.Views == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("views", "result"))
+	}
+	if result.AvgBitrate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("avg_bitrate", "result"))
+	}
+	if result.RebufRate == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("rebuf_rate", "result"))
+	}
+	if result.Start != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("result.start", *result.Start, goa.FormatDateTime))
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package watchman
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var OversizedFields = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "watchman",
+	Subsystem: "reports",
+	Name:      "oversized_fields_total",
+	Help:      "Number of playback report fields over their length limit, by field and whether the report was rejected or the field truncated",
+}, []string{"field", "action"})
+
+// FieldLimits bounds the length of free-form string fields of playback reports below the limits in the API design,
+// which reject grossly oversized values before they reach the service.
+type FieldLimits struct {
+	// MaxLengths are limits in characters by field name (url, player, user_id). Fields not listed are only bound by the design.
+	MaxLengths map[string]int
+	// Truncate cuts oversized fields down to their limit instead of rejecting the report.
+	Truncate bool
+}
+
+// limitedFields returns free-form string fields of p by their names in the API.
+func limitedFields(p *reporter.PlaybackReport) map[string]*string {
+	return map[string]*string{"url": &p.URL, "player": &p.Player, "user_id": &p.UserID}
+}
+
+// apply checks fields of p against the limits, truncating oversized ones in place if configured to.
+// Otherwise an error listing every oversized field is returned.
+func (l FieldLimits) apply(p *reporter.PlaybackReport) error {
+	fields := limitedFields(p)
+	names := make([]string, 0, len(l.MaxLengths))
+	for name := range l.MaxLengths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		max := l.MaxLengths[name]
+		v, ok := fields[name]
+		if !ok || max <= 0 || utf8.RuneCountInString(*v) <= max {
+			continue
+		}
+		if l.Truncate {
+			*v = string([]rune(*v)[:max])
+			OversizedFields.WithLabelValues(name, "truncated").Inc()
+			continue
+		}
+		OversizedFields.WithLabelValues(name, "rejected").Inc()
+		problems = append(problems, fmt.Sprintf("%v cannot be longer than %v characters", name, max))
+	}
+	if len(problems) > 0 {
+		return &reporter.MultiFieldError{Message: strings.Join(problems, "; ")}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package watchman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	reporterclt "github.com/lbryio/lbrytv/apps/watchman/gen/http/reporter/client"
+	reportersvr "github.com/lbryio/lbrytv/apps/watchman/gen/http/reporter/server"
+	"github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	"github.com/lbryio/lbrytv/apps/watchman/log"
+	"github.com/lbryio/lbrytv/apps/watchman/olapdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goahttp "goa.design/goa/v3/http"
+)
+
+func TestAddOversizedFields(t *testing.T) {
+	limits := FieldLimits{MaxLengths: map[string]int{"url": 10, "player": 5, "user_id": 0}}
+	written := make(chan *reporter.PlaybackReport, 1)
+	q := NewReportQueue(10, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error {
+		written <- r
+		return nil
+	})
+	defer q.Close(context.Background())
+	ctx := context.WithValue(context.Background(), RemoteAddressKey, "1.1.1.1")
+
+	svc := NewReporter(nil, log.Log, nil, nil, q, limits, nil)
+	err := svc.Add(ctx, &reporter.PlaybackReport{URL: strings.Repeat("a", 11), Player: "sg-p22", UserID: strings.Repeat("1", 45), Duration: 30000})
+	var fErr *reporter.MultiFieldError
+	require.True(t, errors.As(err, &fErr))
+	assert.Equal(t, "player cannot be longer than 5 characters; url cannot be longer than 10 characters", fErr.Message)
+	assert.Empty(t, written)
+
+	require.NoError(t, svc.Add(ctx, &reporter.PlaybackReport{URL: strings.Repeat("a", 10), Player: "sg-p2", Duration: 30000}))
+	assert.Equal(t, strings.Repeat("a", 10), (<-written).URL)
+
+	limits.Truncate = true
+	svc = NewReporter(nil, log.Log, nil, nil, q, limits, nil)
+	require.NoError(t, svc.Add(ctx, &reporter.PlaybackReport{URL: "@каналы#1/видео#2", Player: "sg-p22", Duration: 30000}))
+	r := <-written
+	assert.Equal(t, "@каналы#1/", r.URL)
+	assert.Equal(t, "sg-p2", r.Player)
+}
+
+func TestAddOversizedFieldsHTTP(t *testing.T) {
+	q := NewReportQueue(10, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error { return nil })
+	defer q.Close(context.Background())
+	svc := NewReporter(nil, log.Log, nil, nil, q, FieldLimits{MaxLengths: map[string]int{"url": 100}}, nil)
+
+	mux := goahttp.NewMuxer()
+	server := reportersvr.New(reporter.NewEndpoints(svc), mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil)
+	server.Use(RemoteAddressMiddleware())
+	reportersvr.Mount(mux, server)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(url string) (int, string) {
+		rep := olapdb.PlaybackReportAddRequestFactory.MustCreate().(*reporterclt.AddRequestBody)
+		rep.URL = url
+		body, err := json.Marshal(rep)
+		require.NoError(t, err)
+		resp, err := http.Post(ts.URL+reporterclt.AddReporterPath(), "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	code, _ := post(strings.Repeat("a", 100))
+	assert.Equal(t, http.StatusCreated, code)
+
+	// Configured limit
+	code, body := post(strings.Repeat("a", 101))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "url cannot be longer than 100 characters")
+
+	// Design limit
+	code, body = post(strings.Repeat("a", 513))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "body.url")
+}
@@ -2,8 +2,13 @@ package watchman
 
 import (
 	"context"
+	"encoding/json"
 	"net"
 	"net/http"
+	"time"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
 )
 
 type ctxKey int
@@ -19,6 +24,35 @@ func RemoteAddressMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
+// GuardMiddleware limits request body size and the time a request can take, so a slow or oversized
+// report cannot tie up the server. Requests exceeding the limits are rejected with goa-formatted errors.
+// Bodies not declaring their size are cut off at maxBodySize and fail to decode.
+func GuardMiddleware(maxBodySize int64, timeout time.Duration) func(http.Handler) http.Handler {
+	timeoutBody, _ := json.Marshal(&goahttp.ErrorResponse{
+		Name:      "timeout",
+		Message:   "request took too long to process",
+		Temporary: true,
+		Timeout:   true,
+	})
+	return func(h http.Handler) http.Handler {
+		th := http.TimeoutHandler(h, timeout, string(timeoutBody))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBodySize {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(goahttp.NewErrorResponse(
+					goa.PermanentError("request_too_large", "request body cannot be larger than %v bytes", maxBodySize),
+				))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+			// Set for the timeout response, handler's own headers replace it otherwise.
+			w.Header().Set("Content-Type", "application/json")
+			th.ServeHTTP(w, r)
+		})
+	}
+}
+
 // from makes a best effort to compute the request client IP.
 func from(req *http.Request) string {
 	if f := req.Header.Get("X-Forwarded-For"); f != "" {
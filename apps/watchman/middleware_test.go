@@ -0,0 +1,60 @@
+package watchman
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goahttp "goa.design/goa/v3/http"
+)
+
+func TestGuardMiddleware(t *testing.T) {
+	h := GuardMiddleware(16, 50*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if string(body) == "slow" {
+			time.Sleep(time.Second)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	decodeError := func(rr *httptest.ResponseRecorder) goahttp.ErrorResponse {
+		var e goahttp.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &e))
+		return e
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports/playback", strings.NewReader("{}")))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports/playback", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "request_too_large", decodeError(rr).Name)
+
+	// Body size is not known upfront
+	r := httptest.NewRequest(http.MethodPost, "/reports/playback", strings.NewReader(strings.Repeat("x", 17)))
+	r.ContentLength = -1
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports/playback", strings.NewReader("slow")))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	e := decodeError(rr)
+	assert.Equal(t, "timeout", e.Name)
+	assert.True(t, e.Timeout)
+}
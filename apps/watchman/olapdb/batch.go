@@ -40,7 +40,11 @@ func (b *BatchWriter) Start() {
 
 	for !stop {
 		select {
-		case el := <-b.rcvChan:
+		case el, ok := <-b.rcvChan:
+			if !ok {
+				stop = true
+				continue
+			}
 			if el == nil {
 				continue
 			}
@@ -62,6 +66,7 @@ func (b *BatchWriter) Start() {
 			counter = 0
 		}
 	}
+	ticker.Stop()
 
 	// Write out the remaining records after Stop() has been called.
 	if counter > 0 {
@@ -73,8 +78,10 @@ func (b *BatchWriter) Start() {
 	b.stopChan <- true
 }
 
+// Stop makes the writer flush the remaining records and waits for it to finish.
 func (b *BatchWriter) Stop() {
 	close(b.rcvChan)
+	<-b.stopChan
 }
 
 func (b *BatchWriter) Write(r *reporter.PlaybackReport, addr string, ts string) error {
@@ -115,6 +122,12 @@ func (b *BatchWriter) writeBatch() error {
 	if err := tx.Commit(); err != nil {
 		return errors.Wrap(err, "cannot commit")
 	}
+	for _, row := range b.batch {
+		// URL and Timestamp, see prepareArgs
+		url, _ := row[0].(string)
+		t, _ := row[2].(time.Time)
+		invalidateStats(url, t)
+	}
 
 	b.batch = [][]interface{}{}
 	return nil
@@ -126,6 +126,11 @@ func WriteOne(r *reporter.PlaybackReport, addr string, ts string) error {
 	if err := tx.Commit(); err != nil {
 		return errors.Wrap(err, "cannot commit")
 	}
+	t, err := time.Parse(time.RFC1123Z, ts)
+	if err != nil {
+		t = time.Now()
+	}
+	invalidateStats(r.URL, t)
 	return nil
 }
 
@@ -133,6 +138,11 @@ func BatchWrite(r *reporter.PlaybackReport, addr string, ts string) error {
 	return batchWriter.Write(r, addr, ts)
 }
 
+// Flush writes out records pending in the batch writer, no writes are accepted after it's called.
+func Flush() {
+	batchWriter.Stop()
+}
+
 func prepareWrite(tx *sql.Tx) (*sql.Stmt, error) {
 	return tx.Prepare(prepareInsertQuery("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"))
 }
@@ -1,8 +1,10 @@
 package olapdb
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,7 +31,7 @@ func (s *BaseOlapdbSuite) SetupSuite() {
 	cfg, err := config.Read()
 	s.Require().NoError(err)
 
-	dbCfg := cfg.GetStringMapString("clickhouse")
+	dbCfg := cfg.Viper().GetStringMapString("clickhouse")
 	dbName := randomdata.Alphanumeric(32)
 	err = Connect(dbCfg["url"], dbName)
 	s.cleanup = func() {
@@ -61,3 +63,22 @@ func (s *olapdbSuite) TestWriteOne() {
 	s.Require().NoError(err)
 	s.Equal(r.Duration, duration)
 }
+
+func (s *olapdbSuite) TestClaimStatsSharedPrefix() {
+	claimID := "abcdef" + strings.Repeat("1", 34)
+	otherClaimID := "abcdef" + strings.Repeat("2", 34)
+	ts := time.Now().Format(time.RFC1123Z)
+	for _, url := range []string{"what#" + claimID, "other#" + otherClaimID, "short#abcdef"} {
+		r := PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+		r.URL = url
+		s.Require().NoError(WriteOne(r, randomdata.IpV4Address(), ts))
+	}
+
+	buckets, err := ClaimStats(context.Background(), claimID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "hour", 10, 0)
+	s.Require().NoError(err)
+	s.Require().Len(buckets, 1)
+	s.EqualValues(1, buckets[0].Views)
+
+	_, err = ClaimStats(context.Background(), "abcdef", time.Now().Add(-time.Hour), time.Now(), "hour", 10, 0)
+	s.Error(err)
+}
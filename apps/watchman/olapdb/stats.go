@@ -0,0 +1,67 @@
+package olapdb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatsBucket contains aggregate playback stats for a period of time.
+type StatsBucket struct {
+	Start      time.Time
+	Views      uint64
+	AvgBitrate float64
+	RebufRate  float64
+}
+
+var bucketFuncs = map[string]string{
+	"hour": "toStartOfHour",
+	"day":  "toStartOfDay",
+}
+
+var claimIDPattern = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// ClaimStats aggregates playback reports for URLs pointing to the claim into time buckets.
+// Reports are matched by the full claim ID in their URL. URLs with short (prefix) claim IDs are left out
+// as a prefix can be shared by several claims.
+func ClaimStats(ctx context.Context, claimID string, from, to time.Time, bucket string, limit, offset int) ([]StatsBucket, error) {
+	bucketFunc, ok := bucketFuncs[bucket]
+	if !ok {
+		return nil, fmt.Errorf("unknown bucket size: %v", bucket)
+	}
+	if !claimIDPattern.MatchString(claimID) {
+		return nil, fmt.Errorf("invalid claim id: %v", claimID)
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			%v(Timestamp) AS Bucket,
+			uniqExact(UserID) AS Views,
+			ifNotFinite(avgIf(Bitrate, Bitrate > 0), 0) AS AvgBitrate,
+			if(sum(Duration) > 0, sum(RebufDuration) / sum(Duration), 0) AS RebufRate
+		FROM %v.playback
+		WHERE Timestamp >= ? AND Timestamp < ?
+			AND extract(URL, '#([0-9a-f]{40})$') = ?
+		GROUP BY Bucket
+		ORDER BY Bucket
+		LIMIT %d OFFSET %d`, bucketFunc, database, limit, offset),
+		from, to, claimID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot query claim stats")
+	}
+	defer rows.Close()
+
+	buckets := []StatsBucket{}
+	for rows.Next() {
+		b := StatsBucket{}
+		if err := rows.Scan(&b.Start, &b.Views, &b.AvgBitrate, &b.RebufRate); err != nil {
+			return nil, errors.Wrap(err, "cannot scan claim stats")
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
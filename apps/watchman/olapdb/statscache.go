@@ -0,0 +1,156 @@
+package olapdb
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	StatsCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "stats_cache",
+		Name:      "requests_total",
+		Help:      "Number of claim stats requests by whether they were served from cache (hit) or aggregated (miss)",
+	}, []string{"result"})
+	StatsCacheInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "stats_cache",
+		Name:      "invalidations_total",
+		Help:      "Number of cached claim stats dropped because reports have been written into their time range",
+	})
+)
+
+// urlClaimIDPattern extracts the full claim ID from report URLs, the same way ClaimStats matches them.
+var urlClaimIDPattern = regexp.MustCompile("#([0-9a-f]{40})$")
+
+type statsQuery func(ctx context.Context, claimID string, from, to time.Time, bucket string, limit, offset int) ([]StatsBucket, error)
+
+type statsKey struct {
+	from, to      time.Time
+	bucket        string
+	limit, offset int
+}
+
+type statsEntry struct {
+	buckets []StatsBucket
+	expires time.Time
+}
+
+// StatsCache keeps results of ClaimStats for a while so repeated dashboard loads don't aggregate reports again.
+// Cached results of a claim are dropped as soon as reports for it are written into the time range they cover.
+type StatsCache struct {
+	ttl   time.Duration
+	size  int
+	query statsQuery
+	now   func() time.Time
+
+	mu      sync.Mutex
+	count   int
+	entries map[string]map[statsKey]statsEntry
+}
+
+var statsCache *StatsCache
+
+// NewStatsCache creates a cache keeping up to size results for ttl.
+func NewStatsCache(ttl time.Duration, size int) *StatsCache {
+	return &StatsCache{ttl: ttl, size: size, query: ClaimStats, now: time.Now, entries: map[string]map[statsKey]statsEntry{}}
+}
+
+// EnableStatsCache makes CachedClaimStats serve results from c and writes invalidate it. Nil disables caching.
+func EnableStatsCache(c *StatsCache) {
+	statsCache = c
+}
+
+// CachedClaimStats is ClaimStats served from the cache set by EnableStatsCache, if any.
+func CachedClaimStats(ctx context.Context, claimID string, from, to time.Time, bucket string, limit, offset int) ([]StatsBucket, error) {
+	if statsCache == nil {
+		return ClaimStats(ctx, claimID, from, to, bucket, limit, offset)
+	}
+	return statsCache.ClaimStats(ctx, claimID, from, to, bucket, limit, offset)
+}
+
+// ClaimStats returns cached results of ClaimStats for the same params or aggregates and caches them.
+func (c *StatsCache) ClaimStats(ctx context.Context, claimID string, from, to time.Time, bucket string, limit, offset int) ([]StatsBucket, error) {
+	key := statsKey{from: from.UTC(), to: to.UTC(), bucket: bucket, limit: limit, offset: offset}
+	c.mu.Lock()
+	e, ok := c.entries[claimID][key]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		StatsCacheRequests.WithLabelValues("hit").Inc()
+		return e.buckets, nil
+	}
+	StatsCacheRequests.WithLabelValues("miss").Inc()
+
+	buckets, err := c.query(ctx, claimID, from, to, bucket, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	c.set(claimID, key, buckets)
+	return buckets, nil
+}
+
+func (c *StatsCache) set(claimID string, key statsKey, buckets []StatsBucket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[claimID][key]; !ok {
+		if c.count >= c.size {
+			c.prune()
+		}
+		if c.count >= c.size {
+			return
+		}
+		c.count++
+	}
+	if c.entries[claimID] == nil {
+		c.entries[claimID] = map[statsKey]statsEntry{}
+	}
+	c.entries[claimID][key] = statsEntry{buckets: buckets, expires: c.now().Add(c.ttl)}
+}
+
+// prune drops expired results.
+func (c *StatsCache) prune() {
+	now := c.now()
+	for claimID, entries := range c.entries {
+		for key, e := range entries {
+			if !now.Before(e.expires) {
+				delete(entries, key)
+				c.count--
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.entries, claimID)
+		}
+	}
+}
+
+// Invalidate drops cached results of the claim whose time range includes t.
+func (c *StatsCache) Invalidate(claimID string, t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.entries[claimID]
+	for key := range entries {
+		if !t.Before(key.from) && t.Before(key.to) {
+			delete(entries, key)
+			c.count--
+			StatsCacheInvalidations.Inc()
+		}
+	}
+	if len(entries) == 0 {
+		delete(c.entries, claimID)
+	}
+}
+
+// invalidateStats drops cached stats affected by a report for url written with timestamp t.
+func invalidateStats(url string, t time.Time) {
+	if statsCache == nil {
+		return
+	}
+	if m := urlClaimIDPattern.FindStringSubmatch(url); m != nil {
+		statsCache.Invalidate(m[1], t)
+	}
+}
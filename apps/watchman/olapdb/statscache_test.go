@@ -0,0 +1,113 @@
+package olapdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClaimID      = "e7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67"
+	testOtherClaimID = "a7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67"
+)
+
+func newTestStatsCache(ttl time.Duration, size int) (*StatsCache, *int, *time.Time) {
+	c := NewStatsCache(ttl, size)
+	queries := 0
+	now := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.query = func(ctx context.Context, claimID string, from, to time.Time, bucket string, limit, offset int) ([]StatsBucket, error) {
+		queries++
+		return []StatsBucket{{Start: from, Views: uint64(queries)}}, nil
+	}
+	return c, &queries, &now
+}
+
+func TestStatsCache(t *testing.T) {
+	c, queries, now := newTestStatsCache(time.Minute, 100)
+	from, to := now.Add(-24*time.Hour), now.Add(time.Hour)
+	hits := testutil.ToFloat64(StatsCacheRequests.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(StatsCacheRequests.WithLabelValues("miss"))
+
+	b, err := c.ClaimStats(context.Background(), testClaimID, from, to, "day", 101, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, b[0].Views)
+	b, err = c.ClaimStats(context.Background(), testClaimID, from, to, "day", 101, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, b[0].Views)
+	assert.Equal(t, 1, *queries)
+
+	// Different params are cached separately
+	_, err = c.ClaimStats(context.Background(), testClaimID, from, to, "hour", 101, 0)
+	require.NoError(t, err)
+	_, err = c.ClaimStats(context.Background(), testClaimID, from, to, "day", 101, 100)
+	require.NoError(t, err)
+	_, err = c.ClaimStats(context.Background(), testOtherClaimID, from, to, "day", 101, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, *queries)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(StatsCacheRequests.WithLabelValues("hit")))
+	assert.Equal(t, misses+4, testutil.ToFloat64(StatsCacheRequests.WithLabelValues("miss")))
+
+	*now = now.Add(time.Minute)
+	b, err = c.ClaimStats(context.Background(), testClaimID, from, to, "day", 101, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, b[0].Views)
+}
+
+func TestStatsCacheInvalidatedByNewReports(t *testing.T) {
+	c, queries, now := newTestStatsCache(time.Hour, 100)
+	EnableStatsCache(c)
+	defer EnableStatsCache(nil)
+	from, to := now.Add(-24*time.Hour), now.Add(time.Hour)
+	get := func(claimID string) uint64 {
+		b, err := CachedClaimStats(context.Background(), claimID, from, to, "day", 101, 0)
+		require.NoError(t, err)
+		return b[0].Views
+	}
+
+	assert.EqualValues(t, 1, get(testClaimID))
+	assert.EqualValues(t, 2, get(testOtherClaimID))
+
+	// Reports outside of the cached time range, of other claims or with short claim IDs don't affect it
+	invalidateStats("@chan#a/video#"+testClaimID, to)
+	invalidateStats("@chan#a/video#"+testClaimID, from.Add(-time.Second))
+	invalidateStats("video#e7b", *now)
+	invalidateStats("video#"+testOtherClaimID, *now)
+	assert.EqualValues(t, 1, get(testClaimID))
+	assert.Equal(t, 2, *queries)
+
+	invalidateStats("@chan#a/video#"+testClaimID, from)
+	assert.EqualValues(t, 3, get(testClaimID))
+	invalidateStats("video#"+testClaimID, *now)
+	assert.EqualValues(t, 4, get(testClaimID))
+	assert.Equal(t, 4, *queries)
+}
+
+func TestStatsCacheSize(t *testing.T) {
+	c, queries, now := newTestStatsCache(time.Minute, 2)
+	from := now.Add(-time.Hour)
+	get := func(to time.Time) {
+		_, err := c.ClaimStats(context.Background(), testClaimID, from, to, "hour", 10, 0)
+		require.NoError(t, err)
+	}
+
+	get(*now)
+	get(now.Add(time.Hour))
+	// Cache is full, the result is not kept
+	get(now.Add(2 * time.Hour))
+	get(now.Add(2 * time.Hour))
+	assert.Equal(t, 4, *queries)
+	get(*now)
+	assert.Equal(t, 4, *queries)
+
+	// Expired results make room for new ones
+	*now = now.Add(time.Minute)
+	get(now.Add(2 * time.Hour))
+	get(now.Add(2 * time.Hour))
+	assert.Equal(t, 5, *queries)
+}
@@ -0,0 +1,297 @@
+package watchman
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	"github.com/lbryio/lbrytv/apps/watchman/log"
+	_ "github.com/lbryio/lbrytv/internal/metrics/constlabels"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ErrQueueFull   = errors.New("report queue is full")
+	ErrQueueClosed = errors.New("report queue is closed")
+)
+
+var (
+	QueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "depth",
+		Help:      "Number of playback reports waiting to be written to storage",
+	})
+	QueueEnqueued = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "enqueued_total",
+		Help:      "Number of playback reports accepted into the queue",
+	})
+	QueueDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "dropped_total",
+		Help:      "Number of playback reports rejected because the queue was full",
+	})
+	QueueWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "write_failures_total",
+		Help:      "Number of queued playback reports storage refused to write",
+	})
+	QueueRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "retries_total",
+		Help:      "Number of attempts to write playback reports storage has refused before",
+	})
+	QueueRetryDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "retry_dropped_total",
+		Help:      "Number of playback reports given up on after failed writes",
+	})
+	QueueRetryDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "watchman",
+		Subsystem: "queue",
+		Name:      "retry_depth",
+		Help:      "Number of playback reports waiting to be written again",
+	})
+)
+
+// RetryPolicy configures how reports storage has failed to write are retried. Zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a report is written before it's dropped, including the first attempt.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, it's doubled after each failed retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Rate is the maximum number of retries per second, so storage coming back up isn't flooded.
+	Rate float64
+	// Size is the number of reports which can be waiting for a retry, failed reports beyond it are dropped.
+	Size int
+}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1 && p.Rate > 0 && p.Size > 0
+}
+
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	b := p.Backoff
+	for i := 1; i < attempts && (p.MaxBackoff <= 0 || b < p.MaxBackoff); i++ {
+		b *= 2
+	}
+	if p.MaxBackoff > 0 && b > p.MaxBackoff {
+		b = p.MaxBackoff
+	}
+	return b
+}
+
+// WriteFunc persists a playback report received from addr.
+type WriteFunc func(r *reporter.PlaybackReport, addr string) error
+
+type queuedReport struct {
+	report *reporter.PlaybackReport
+	addr   string
+	// attempts is the number of failed writes, due is when the report is written again
+	attempts int
+	due      time.Time
+}
+
+// retryHeap orders reports waiting for a retry by the time they are due.
+type retryHeap []*queuedReport
+
+func (h retryHeap) Len() int            { return len(h) }
+func (h retryHeap) Less(i, j int) bool  { return h[i].due.Before(h[j].due) }
+func (h retryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *retryHeap) Push(x interface{}) { *h = append(*h, x.(*queuedReport)) }
+func (h *retryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	i := old[n-1]
+	*h = old[:n-1]
+	return i
+}
+
+// ReportQueue is a bounded in-memory queue between the reporter endpoint and storage,
+// reports put into it are written out by a pool of workers.
+// Reports storage fails to write are retried with backoff according to RetryPolicy.
+type ReportQueue struct {
+	write  WriteFunc
+	items  chan queuedReport
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+
+	retry       RetryPolicy
+	retryMu     sync.Mutex
+	retries     retryHeap
+	stopRetries chan struct{}
+	stopOnce    sync.Once
+	retriesDone chan struct{}
+}
+
+// NewReportQueue starts workers writing reports with write, at most size reports can be waiting.
+// Failed writes are retried according to retry.
+func NewReportQueue(size, workers int, retry RetryPolicy, write WriteFunc) *ReportQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &ReportQueue{
+		write:       write,
+		items:       make(chan queuedReport, size),
+		retry:       retry,
+		stopRetries: make(chan struct{}),
+		retriesDone: make(chan struct{}),
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	if retry.enabled() {
+		go q.retryFailed()
+	} else {
+		close(q.retriesDone)
+	}
+	return q
+}
+
+// Enqueue puts the report into the queue without blocking,
+// ErrQueueFull is returned if there's no room for it.
+func (q *ReportQueue) Enqueue(r *reporter.PlaybackReport, addr string) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.items <- queuedReport{report: r, addr: addr}:
+		QueueEnqueued.Inc()
+		QueueDepth.Set(float64(len(q.items)))
+		return nil
+	default:
+		QueueDropped.Inc()
+		return ErrQueueFull
+	}
+}
+
+// Len returns the number of reports waiting to be written.
+func (q *ReportQueue) Len() int {
+	return len(q.items)
+}
+
+// RetryLen returns the number of reports waiting to be written again.
+func (q *ReportQueue) RetryLen() int {
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+	return q.retries.Len()
+}
+
+// Close stops accepting reports and waits until the ones already queued are written
+// or ctx is done. Reports waiting for a retry are dropped.
+func (q *ReportQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		q.stopOnce.Do(func() { close(q.stopRetries) })
+		<-q.retriesDone
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *ReportQueue) work() {
+	defer q.wg.Done()
+	for i := range q.items {
+		QueueDepth.Set(float64(len(q.items)))
+		if err := q.write(i.report, i.addr); err != nil {
+			QueueWriteFailures.Inc()
+			log.Log.Errorw("cannot write queued report", "url", i.report.URL, "err", err)
+			failed := i
+			failed.attempts++
+			q.scheduleRetry(&failed)
+		}
+	}
+}
+
+// scheduleRetry puts a report which has failed to be written aside for a retry,
+// unless it has run out of attempts or there's no room for it.
+func (q *ReportQueue) scheduleRetry(i *queuedReport) {
+	if !q.retry.enabled() || i.attempts >= q.retry.MaxAttempts {
+		QueueRetryDropped.Inc()
+		log.Log.Errorw("dropping report after failed writes", "url", i.report.URL, "attempts", i.attempts)
+		return
+	}
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+	if q.retries.Len() >= q.retry.Size {
+		QueueRetryDropped.Inc()
+		log.Log.Errorw("retry queue is full, dropping report", "url", i.report.URL)
+		return
+	}
+	i.due = time.Now().Add(q.retry.backoff(i.attempts))
+	heap.Push(&q.retries, i)
+	QueueRetryDepth.Set(float64(q.retries.Len()))
+}
+
+// nextRetry takes the report which is due for a retry, nil if there's none yet.
+func (q *ReportQueue) nextRetry() *queuedReport {
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+	if q.retries.Len() == 0 || q.retries[0].due.After(time.Now()) {
+		return nil
+	}
+	i := heap.Pop(&q.retries).(*queuedReport)
+	QueueRetryDepth.Set(float64(q.retries.Len()))
+	return i
+}
+
+// retryFailed writes reports due for a retry, at most RetryPolicy.Rate of them per second, until Close.
+func (q *ReportQueue) retryFailed() {
+	defer close(q.retriesDone)
+	t := time.NewTicker(time.Duration(float64(time.Second) / q.retry.Rate))
+	defer t.Stop()
+	for {
+		select {
+		case <-q.stopRetries:
+			q.retryMu.Lock()
+			if n := q.retries.Len(); n > 0 {
+				QueueRetryDropped.Add(float64(n))
+				log.Log.Errorw("dropping reports waiting for retry on shutdown", "count", n)
+			}
+			q.retries = nil
+			QueueRetryDepth.Set(0)
+			q.retryMu.Unlock()
+			return
+		case <-t.C:
+		}
+		i := q.nextRetry()
+		if i == nil {
+			continue
+		}
+		QueueRetries.Inc()
+		if err := q.write(i.report, i.addr); err != nil {
+			log.Log.Warnw("cannot write report on retry", "url", i.report.URL, "attempt", i.attempts+1, "err", err)
+			i.attempts++
+			q.scheduleRetry(i)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package watchman
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
+	"github.com/lbryio/lbrytv/apps/watchman/log"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportQueue(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		written []string
+	)
+	unblock := make(chan struct{})
+	q := NewReportQueue(2, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error {
+		<-unblock
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, r.URL)
+		return nil
+	})
+
+	// The worker picks up the first report and blocks, two more fit into the queue.
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "one"}, "1.1.1.1"))
+	require.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "two"}, "1.1.1.1"))
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "three"}, "1.1.1.1"))
+	assert.True(t, errors.Is(q.Enqueue(&reporter.PlaybackReport{URL: "four"}, "1.1.1.1"), ErrQueueFull))
+
+	close(unblock)
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, []string{"one", "two", "three"}, written)
+	assert.True(t, errors.Is(q.Enqueue(&reporter.PlaybackReport{URL: "five"}, "1.1.1.1"), ErrQueueClosed))
+}
+
+func TestReportQueueCloseTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	q := NewReportQueue(1, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error {
+		<-unblock
+		return nil
+	})
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "one"}, "1.1.1.1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Close(ctx))
+}
+
+func TestAddQueueFull(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	q := NewReportQueue(1, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error {
+		<-unblock
+		return nil
+	})
+	svc := NewReporter(nil, log.Log, nil, nil, q, FieldLimits{}, nil)
+	ctx := context.WithValue(context.Background(), RemoteAddressKey, "1.1.1.1")
+	rep := &reporter.PlaybackReport{URL: "what", Duration: 30000}
+
+	require.NoError(t, svc.Add(ctx, rep))
+	require.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, svc.Add(ctx, rep))
+
+	err := svc.Add(ctx, rep)
+	var mErr *reporter.MaintenanceError
+	require.True(t, errors.As(err, &mErr))
+	assert.Equal(t, 10, mErr.RetryAfter)
+}
+
+func TestReportQueueRetries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+		written  []string
+	)
+	q := NewReportQueue(10, 1, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Rate: 1000, Size: 10}, func(r *reporter.PlaybackReport, addr string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[r.URL]++
+		// "flaky" is written on the second attempt, "broken" never is
+		if r.URL == "broken" || attempts[r.URL] < 2 {
+			return errors.New("storage is down")
+		}
+		written = append(written, r.URL)
+		return nil
+	})
+	retries := testutil.ToFloat64(QueueRetries)
+	dropped := testutil.ToFloat64(QueueRetryDropped)
+
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "flaky"}, "1.1.1.1"))
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "broken"}, "1.1.1.1"))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(QueueRetryDropped) == dropped+1 && q.RetryLen() == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, q.Close(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"flaky"}, written)
+	assert.Equal(t, 3, attempts["broken"])
+	assert.Equal(t, retries+3, testutil.ToFloat64(QueueRetries))
+}
+
+func TestReportQueueRetryLimits(t *testing.T) {
+	q := NewReportQueue(10, 1, RetryPolicy{MaxAttempts: 5, Backoff: time.Hour, Rate: 1000, Size: 1}, func(r *reporter.PlaybackReport, addr string) error {
+		return errors.New("storage is down")
+	})
+	dropped := testutil.ToFloat64(QueueRetryDropped)
+
+	// Only one report fits into the retry queue, it's dropped on shutdown along with the rest
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "one"}, "1.1.1.1"))
+	require.NoError(t, q.Enqueue(&reporter.PlaybackReport{URL: "two"}, "1.1.1.1"))
+	require.Eventually(t, func() bool { return testutil.ToFloat64(QueueRetryDropped) == dropped+1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, q.RetryLen())
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, dropped+2, testutil.ToFloat64(QueueRetryDropped))
+	assert.Equal(t, 0, q.RetryLen())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4))
+	assert.Equal(t, 5*time.Second, p.backoff(50))
+}
+
+func TestAddQueueClosed(t *testing.T) {
+	q := NewReportQueue(1, 1, RetryPolicy{}, func(r *reporter.PlaybackReport, addr string) error { return nil })
+	require.NoError(t, q.Close(context.Background()))
+	svc := NewReporter(nil, log.Log, nil, nil, q, FieldLimits{}, nil)
+	ctx := context.WithValue(context.Background(), RemoteAddressKey, "1.1.1.1")
+
+	err := svc.Add(ctx, &reporter.PlaybackReport{URL: "what", Duration: 30000})
+	var mErr *reporter.MaintenanceError
+	require.True(t, errors.As(err, &mErr))
+	assert.Equal(t, 30, mErr.RetryAfter)
+}
@@ -2,26 +2,70 @@ package watchman
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
+	"errors"
+	"time"
 
 	reporter "github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
 	"github.com/lbryio/lbrytv/apps/watchman/olapdb"
+	"github.com/lbryio/lbrytv/internal/maintenance"
 
 	"go.uber.org/zap"
+	"goa.design/goa/v3/security"
 )
 
 // reporter service example implementation.
 // The example methods log the requests and return zero values.
 type reportersrvc struct {
-	db     *sql.DB
-	logger *zap.SugaredLogger
+	db          *sql.DB
+	logger      *zap.SugaredLogger
+	maintenance *maintenance.Switch
+	statsKeys   func() []string
+	queue       *ReportQueue
+	limits      FieldLimits
+	retryAfter  func() RetryAfter
+}
+
+// RetryAfter holds periods clients are advised to wait before retrying reports rejected during maintenance,
+// while the report queue is full, and while storage is failing or the service is shutting down.
+type RetryAfter struct {
+	Maintenance time.Duration
+	QueueFull   time.Duration
+	Unavailable time.Duration
+}
+
+// DefaultRetryAfter is used by reporters without configured periods and for periods which are not set.
+var DefaultRetryAfter = RetryAfter{Maintenance: 5 * time.Minute, QueueFull: 10 * time.Second, Unavailable: 30 * time.Second}
+
+func (r RetryAfter) withDefaults() RetryAfter {
+	if r.Maintenance <= 0 {
+		r.Maintenance = DefaultRetryAfter.Maintenance
+	}
+	if r.QueueFull <= 0 {
+		r.QueueFull = DefaultRetryAfter.QueueFull
+	}
+	if r.Unavailable <= 0 {
+		r.Unavailable = DefaultRetryAfter.Unavailable
+	}
+	return r
 }
 
 // NewReporter returns the reporter service implementation.
-func NewReporter(db *sql.DB, logger *zap.SugaredLogger) reporter.Service {
+// Reports are rejected while maintenance switch is on, nil switch disables maintenance mode.
+// statsKeys should return API keys allowed to query stats, stats are not accessible if it's nil.
+// Reports are put into queue for writing, nil queue makes them go to storage directly.
+// String fields of reports are checked against limits, zero value leaves them to the API design limits.
+// retryAfter is called for periods to advise clients of when reports are rejected, nil means DefaultRetryAfter.
+func NewReporter(db *sql.DB, logger *zap.SugaredLogger, mnt *maintenance.Switch, statsKeys func() []string, queue *ReportQueue, limits FieldLimits, retryAfter func() RetryAfter) reporter.Service {
 	svc := &reportersrvc{
-		db:     db,
-		logger: logger,
+		db:          db,
+		logger:      logger,
+		maintenance: mnt,
+		statsKeys:   statsKeys,
+		queue:       queue,
+		limits:      limits,
+		retryAfter:  retryAfter,
 	}
 	return svc
 }
@@ -29,16 +73,112 @@ func NewReporter(db *sql.DB, logger *zap.SugaredLogger) reporter.Service {
 // Add implements add.
 func (s *reportersrvc) Add(ctx context.Context, p *reporter.PlaybackReport) error {
 	s.logger.Debug("reporter.add")
+	retryAfter := s.getRetryAfter()
+
+	if s.maintenance.IsOn() {
+		return &reporter.MaintenanceError{
+			Message:    "service under maintenance, please try again later",
+			RetryAfter: int(retryAfter.Maintenance.Seconds()),
+		}
+	}
 
 	if p.RebufDuration > p.Duration {
 		return &reporter.MultiFieldError{Message: "rebufferung duration cannot be larger than duration"}
 	}
+	if err := s.limits.apply(p); err != nil {
+		return err
+	}
 	addr := ctx.Value(RemoteAddressKey).(string)
-	err := olapdb.BatchWrite(p, addr, "")
+	if s.queue == nil {
+		if err := olapdb.BatchWrite(p, addr, ""); err != nil {
+			s.logger.Errorw("cannot write report", "url", p.URL, "err", err)
+			return &reporter.MaintenanceError{
+				Message:    "report could not be stored, please try again later",
+				RetryAfter: int(retryAfter.Unavailable.Seconds()),
+			}
+		}
+		return nil
+	}
+	err := s.queue.Enqueue(p, addr)
+	if errors.Is(err, ErrQueueFull) {
+		s.logger.Warn("report queue is full, rejecting report")
+		return &reporter.MaintenanceError{
+			Message:    "service is overloaded, please try again later",
+			RetryAfter: int(retryAfter.QueueFull.Seconds()),
+		}
+	}
+	if errors.Is(err, ErrQueueClosed) {
+		return &reporter.MaintenanceError{
+			Message:    "service is shutting down, please try again later",
+			RetryAfter: int(retryAfter.Unavailable.Seconds()),
+		}
+	}
+	return err
+}
+
+func (s *reportersrvc) getRetryAfter() RetryAfter {
+	if s.retryAfter == nil {
+		return DefaultRetryAfter
+	}
+	return s.retryAfter().withDefaults()
+}
+
+// APIKeyAuth implements the authorization logic for stats_key security scheme.
+func (s *reportersrvc) APIKeyAuth(ctx context.Context, key string, schema *security.APIKeyScheme) (context.Context, error) {
+	if s.statsKeys == nil || key == "" {
+		return ctx, reporter.Unauthorized("invalid stats key")
+	}
+	for _, k := range s.statsKeys() {
+		if k != "" && subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return ctx, nil
+		}
+	}
+	return ctx, reporter.Unauthorized("invalid stats key")
+}
+
+// Stats implements stats.
+func (s *reportersrvc) Stats(ctx context.Context, p *reporter.StatsPayload) (*reporter.WatchmanClaimStats, error) {
+	s.logger.Debug("reporter.stats")
+
+	from, err := time.Parse(time.RFC3339, p.From)
 	if err != nil {
-		return err
+		return nil, &reporter.MultiFieldError{Message: "invalid from value"}
+	}
+	to, err := time.Parse(time.RFC3339, p.To)
+	if err != nil {
+		return nil, &reporter.MultiFieldError{Message: "invalid to value"}
+	}
+	if !to.After(from) {
+		return nil, &reporter.MultiFieldError{Message: "to must be later than from"}
+	}
+
+	// Fetching one extra bucket to find out if there is a next page
+	buckets, err := olapdb.CachedClaimStats(ctx, p.ClaimID, from, to, p.Bucket, p.PageSize+1, (p.Page-1)*p.PageSize)
+	if err != nil {
+		s.logger.Errorw("cannot retrieve claim stats", "claim_id", p.ClaimID, "err", err)
+		return nil, err
+	}
+
+	res := &reporter.WatchmanClaimStats{
+		ClaimID:  p.ClaimID,
+		Bucket:   p.Bucket,
+		Page:     p.Page,
+		PageSize: p.PageSize,
+		Buckets:  []*reporter.StatsBucket{},
+	}
+	if len(buckets) > p.PageSize {
+		res.HasMore = true
+		buckets = buckets[:p.PageSize]
+	}
+	for _, b := range buckets {
+		res.Buckets = append(res.Buckets, &reporter.StatsBucket{
+			Start:      b.Start.UTC().Format(time.RFC3339),
+			Views:      int64(b.Views),
+			AvgBitrate: b.AvgBitrate,
+			RebufRate:  b.RebufRate,
+		})
 	}
-	return nil
+	return res, nil
 }
 
 func (s *reportersrvc) Healthz(ctx context.Context) (string, error) {
@@ -2,13 +2,18 @@ package watchman
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/lbryio/lbrytv/apps/watchman/config"
 	"github.com/lbryio/lbrytv/apps/watchman/gen/http/reporter/client"
@@ -17,12 +22,17 @@ import (
 	"github.com/lbryio/lbrytv/apps/watchman/gen/reporter"
 	"github.com/lbryio/lbrytv/apps/watchman/log"
 	"github.com/lbryio/lbrytv/apps/watchman/olapdb"
+	"github.com/lbryio/lbrytv/internal/maintenance"
 
 	"github.com/Pallinder/go-randomdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	goahttp "goa.design/goa/v3/http"
 )
 
+const testStatsKey = "test-stats-key"
+
 type reporterSuite struct {
 	suite.Suite
 	ts      *httptest.Server
@@ -39,7 +49,7 @@ func (s *reporterSuite) SetupSuite() {
 
 	log.Configure(log.LevelDebug, log.EncodingConsole)
 
-	dbCfg := cfg.GetStringMapString("clickhouse")
+	dbCfg := cfg.Viper().GetStringMapString("clickhouse")
 	dbName := randomdata.Alphanumeric(32)
 	err = olapdb.Connect(dbCfg["url"], dbName)
 	s.cleanup = func() {
@@ -51,7 +61,7 @@ func (s *reporterSuite) SetupSuite() {
 	err = olapdb.OpenGeoDB(p)
 	s.Require().NoError(err)
 
-	reporterSvc := NewReporter(nil, log.Log)
+	reporterSvc := NewReporter(nil, log.Log, nil, func() []string { return []string{testStatsKey} }, nil, FieldLimits{}, nil)
 	reporterEndpoints := reporter.NewEndpoints(reporterSvc)
 
 	var (
@@ -104,12 +114,112 @@ func (s *reporterSuite) TestAdd() {
 			s.Regexp(regexp.MustCompile(c.respBodyRegex), string(b))
 			s.Equal(c.origin, resp.Header.Get("access-control-allow-origin"))
 			s.Equal("GET, POST", resp.Header.Get("access-control-allow-methods"))
-			s.Equal("content-type", resp.Header.Get("access-control-allow-headers"))
+			s.Equal("content-type, x-watchman-key", resp.Header.Get("access-control-allow-headers"))
 		})
 	}
 
 }
 
+func (s *reporterSuite) TestStats() {
+	claimID := "e7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67"
+	now := time.Now().UTC()
+	for i, u := range []string{"@chan#a/video#e7b", "video#e7bd1cd3b18f3cf0fbc1eab9b79c8ad7e6fe7c67", "other#e7c", "video#e7b"} {
+		r := olapdb.PlaybackReportFactory.MustCreate().(*reporter.PlaybackReport)
+		r.URL = u
+		r.UserID = fmt.Sprintf("%v", i)
+		r.Duration = 10000
+		r.RebufDuration = 1000
+		bitrate := int32(1000000)
+		r.Bitrate = &bitrate
+		err := olapdb.WriteOne(r, "8.8.8.8", now.Add(-time.Duration(i)*time.Hour).Format(time.RFC1123Z))
+		s.Require().NoError(err)
+	}
+
+	query := url.Values{
+		"from":   {now.Add(-24 * time.Hour).Format(time.RFC3339)},
+		"to":     {now.Add(time.Minute).Format(time.RFC3339)},
+		"bucket": {"day"},
+	}
+	statsURL := s.ts.URL + reporterclt.StatsReporterPath(claimID) + "?" + query.Encode()
+
+	r, err := http.NewRequest(http.MethodGet, statsURL, nil)
+	s.Require().NoError(err)
+	resp, err := http.DefaultClient.Do(r)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	r, err = http.NewRequest(http.MethodGet, statsURL, nil)
+	s.Require().NoError(err)
+	r.Header.Add("X-Watchman-Key", testStatsKey)
+	r.Header.Add("origin", "https://odysee.com")
+	resp, err = http.DefaultClient.Do(r)
+	s.Require().NoError(err)
+	b, err := ioutil.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode, string(b))
+	s.Equal("https://odysee.com", resp.Header.Get("access-control-allow-origin"))
+
+	var stats reportersvr.StatsResponseBody
+	s.Require().NoError(json.Unmarshal(b, &stats))
+	s.Equal(claimID, stats.ClaimID)
+	s.False(stats.HasMore)
+	var views int64
+	for _, b := range stats.Buckets {
+		views += b.Views
+		s.InDelta(0.1, b.RebufRate, 0.001)
+		s.InDelta(1000000, b.AvgBitrate, 1)
+	}
+	s.EqualValues(3, views)
+}
+
 func (s *reporterSuite) TearDownSuite() {
 	s.cleanup()
 }
+
+func TestAddMaintenance(t *testing.T) {
+	on := true
+	svc := NewReporter(nil, log.Log, maintenance.NewSwitch(func() bool { return on }, nil), nil, nil, FieldLimits{}, nil)
+	rep := &reporter.PlaybackReport{URL: "what", Duration: 30000}
+
+	err := svc.Add(context.Background(), rep)
+	require.Error(t, err)
+	var mErr *reporter.MaintenanceError
+	require.True(t, errors.As(err, &mErr))
+	assert.Equal(t, 300, mErr.RetryAfter)
+}
+
+func TestAddMaintenanceConfiguredRetryAfter(t *testing.T) {
+	retryAfter := RetryAfter{Maintenance: time.Minute}
+	svc := NewReporter(nil, log.Log, maintenance.NewSwitch(func() bool { return true }, nil), nil, nil, FieldLimits{},
+		func() RetryAfter { return retryAfter })
+	rep := &reporter.PlaybackReport{URL: "what", Duration: 30000}
+
+	var mErr *reporter.MaintenanceError
+	require.True(t, errors.As(svc.Add(context.Background(), rep), &mErr))
+	assert.Equal(t, 60, mErr.RetryAfter)
+
+	// Periods are read on every report
+	retryAfter.Maintenance = 2 * time.Minute
+	require.True(t, errors.As(svc.Add(context.Background(), rep), &mErr))
+	assert.Equal(t, 120, mErr.RetryAfter)
+
+	assert.Equal(t, RetryAfter{Maintenance: time.Minute, QueueFull: 10 * time.Second, Unavailable: 30 * time.Second},
+		RetryAfter{Maintenance: time.Minute}.withDefaults())
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	svc := NewReporter(nil, log.Log, nil, func() []string { return []string{"", "key1", "key2"} }, nil, FieldLimits{}, nil).(*reportersrvc)
+
+	_, err := svc.APIKeyAuth(context.Background(), "key2", nil)
+	assert.NoError(t, err)
+
+	for _, k := range []string{"", "key3", "key"} {
+		_, err = svc.APIKeyAuth(context.Background(), k, nil)
+		var uErr reporter.Unauthorized
+		assert.True(t, errors.As(err, &uErr), k)
+	}
+
+	svc = NewReporter(nil, log.Log, nil, nil, nil, FieldLimits{}, nil).(*reportersrvc)
+	_, err = svc.APIKeyAuth(context.Background(), "key1", nil)
+	assert.Error(t, err)
+}
@@ -11,10 +11,21 @@ import (
 	"github.com/lbryio/lbrytv-player/pkg/paid"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
 	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/app/wallet/deadletter"
+	"github.com/lbryio/lbrytv/app/wallet/rebalance"
 	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/entitlements"
+	"github.com/lbryio/lbrytv/internal/geoip"
+	"github.com/lbryio/lbrytv/internal/locale"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/readmodel"
+	"github.com/lbryio/lbrytv/internal/rules"
+	"github.com/lbryio/lbrytv/internal/sampling"
 	"github.com/lbryio/lbrytv/server"
 
 	"github.com/spf13/cobra"
+	"github.com/volatiletech/sqlboiler/boil"
 )
 
 var rootCmd = &cobra.Command{
@@ -23,10 +34,92 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		rand.Seed(time.Now().UnixNano()) // always seed random!
 		sdkRouter := sdkrouter.New(config.GetLbrynetServers())
+		pools, err := config.GetSDKMethodPools()
+		if err != nil {
+			log.Fatalf("cannot parse sdk method pools: %v", err)
+		}
+		methodPools := make([]sdkrouter.MethodPool, len(pools))
+		for i, p := range pools {
+			methodPools[i] = sdkrouter.MethodPool(p)
+		}
+		if err := sdkRouter.SetMethodPools(methodPools); err != nil {
+			log.Fatal(err)
+		}
+		if err := sdkRouter.SetMethodStrategies(config.GetSDKRoutingStrategies()); err != nil {
+			log.Fatal(err)
+		}
 		go sdkRouter.WatchLoad()
+		sdkRouter.SetSlowStart(config.GetSDKSlowStart())
+		go sdkRouter.WatchHealth(config.GetSDKHealthCheckInterval())
+		config.Watch()
+
+		if e := config.GetMetricsExemplars(); e.Enabled {
+			metrics.SetExemplarThreshold(e.Threshold)
+		}
+
+		if db := config.GetGeoIPDB(); db != "" {
+			if err := geoip.Open(db); err != nil {
+				log.Fatalf("cannot open geoip database: %v", err)
+			}
+		}
+
+		if f := config.GetTranslationsFile(); f != "" {
+			if err := locale.Load(f); err != nil {
+				log.Fatalf("cannot load translations: %v", err)
+			}
+		}
+
+		if f := config.GetRequestRulesFile(); f != "" {
+			if err := rules.Load(f); err != nil {
+				log.Fatalf("cannot load request rules: %v", err)
+			}
+			rules.Watch(f)
+		}
+
+		ep, err := entitlements.NewProvider(config.GetEntitlements(), boil.GetDB())
+		if err != nil {
+			log.Fatalf("cannot set up entitlements: %v", err)
+		}
+		entitlements.SetProvider(ep)
+
+		if rc := config.GetReadModel(); rc.Enabled {
+			store := readmodel.NewStore(rc.MaxEntries)
+			go readmodel.NewSyncer(store, sdkRouter, rc).Run()
+			readmodel.SetStore(store)
+		}
+
+		var sampler *sampling.Sampler
+		if sc := config.GetResponseSampling(); sc.Enabled {
+			sink, err := sampling.NewFileSink(sc.Dir, sc.MaxFileSize, sc.MaxFiles)
+			if err != nil {
+				log.Fatalf("cannot set up response sampling: %v", err)
+			}
+			sampler = sampling.New(sc, sink)
+			go sampler.Run()
+			sampling.SetSampler(sampler)
+		}
+
+		var auditLog *audit.FileLog
+		if ac := config.GetAuditLog(); ac.Path != "" {
+			var uploader audit.Uploader
+			if ac.ArchiveURL != "" {
+				uploader = &audit.HTTPUploader{URL: ac.ArchiveURL, Headers: ac.ArchiveHeaders}
+			}
+			auditLog, err = audit.NewFileLog(ac, uploader)
+			if err != nil {
+				log.Fatalf("cannot open audit log: %v", err)
+			}
+			go auditLog.Run(ac.ArchiveInterval)
+			audit.SetFileLog(auditLog)
+		}
+
+		retryInterval := config.GetDeadLetterRetryInterval()
+		go deadletter.NewWorker(boil.GetDB(), config.GetDeadLetterMaxAttempts(), retryInterval).Run(retryInterval)
+		go wallet.RunSessionFlusher(boil.GetDB(), config.GetSessionFlushInterval())
+		go rebalance.New(boil.GetDB(), sdkRouter).Run()
 
 		s := server.NewServer(config.GetAddress(), sdkRouter)
-		err := s.Start()
+		err = s.Start()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -44,6 +137,19 @@ var rootCmd = &cobra.Command{
 
 		// ServeUntilShutdown is blocking, should be last
 		s.ServeUntilShutdown()
+
+		if auditLog != nil {
+			audit.SetFileLog(nil)
+			if err := auditLog.Stop(); err != nil {
+				log.Printf("cannot close audit log: %v", err)
+			}
+		}
+		if sampler != nil {
+			sampling.SetSampler(nil)
+			if err := sampler.Stop(); err != nil {
+				log.Printf("cannot write out samples: %v", err)
+			}
+		}
 	},
 }
 
@@ -1,12 +1,20 @@
 package config
 
 import (
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
+// ConfigWrapper holds the current settings. A viper instance isn't safe to read while it's being reloaded
+// so reloading builds a fresh one and swaps it in, instances returned by Viper are never modified after that
+// except by Override.
 type ConfigWrapper struct {
-	Viper      *viper.Viper
+	current    atomic.Value
 	configName string
+	setup      func(v *viper.Viper)
 	overridden map[string]interface{}
 }
 
@@ -17,63 +25,111 @@ type DBConfig struct {
 }
 
 func NewConfig() *ConfigWrapper {
-	return &ConfigWrapper{
+	c := &ConfigWrapper{
 		overridden: map[string]interface{}{},
-		Viper:      viper.New(),
 	}
+	c.current.Store(viper.New())
+	return c
+}
+
+// ReadConfig initializes a ConfigWrapper and reads `configName`, panicking if it cannot be read.
+// setup, if not nil, is applied to every viper instance created for the config (for defaults, env bindings,
+// extra config paths etc).
+func ReadConfig(configName string, setup func(v *viper.Viper)) *ConfigWrapper {
+	c, err := LoadConfig(configName, setup)
+	if err != nil {
+		panic(err)
+	}
+	return c
 }
 
-// ReadConfig initializes a ConfigWrapper and reads `configName`
-func ReadConfig(configName string) *ConfigWrapper {
+// LoadConfig is like ReadConfig but returns an error if the config cannot be read.
+func LoadConfig(configName string, setup func(v *viper.Viper)) (*ConfigWrapper, error) {
 	c := NewConfig()
 	c.configName = configName
-	c.initPaths()
-	c.read()
-	return c
+	c.setup = setup
+	v, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.current.Store(v)
+	return c, nil
 }
 
-func (c *ConfigWrapper) initPaths() {
-	c.Viper.SetConfigName(c.configName)
-	c.Viper.AddConfigPath("./config/")
-	c.Viper.AddConfigPath(".")
-	c.Viper.AddConfigPath("..")
-	c.Viper.AddConfigPath("../../")
-	c.Viper.AddConfigPath("../../../")
+// Viper returns the current settings.
+func (c *ConfigWrapper) Viper() *viper.Viper {
+	return c.current.Load().(*viper.Viper)
 }
 
-func (c *ConfigWrapper) read() {
-	err := c.Viper.ReadInConfig()
-	if err != nil {
-		panic(err)
+func (c *ConfigWrapper) load() (*viper.Viper, error) {
+	v := viper.New()
+	c.initPaths(v)
+	if c.setup != nil {
+		c.setup(v)
+	}
+	return v, v.ReadInConfig()
+}
+
+func (c *ConfigWrapper) initPaths(v *viper.Viper) {
+	v.SetConfigName(c.configName)
+	v.AddConfigPath("./config/")
+	v.AddConfigPath(".")
+	v.AddConfigPath("..")
+	v.AddConfigPath("../../")
+	v.AddConfigPath("../../../")
+}
+
+// Watch reloads settings when the config file changes. A file which fails to load is ignored
+// and the settings loaded previously stay in effect.
+func (c *ConfigWrapper) Watch() {
+	// The watcher instance is only used for detecting changes, it's never read by anyone else.
+	w := viper.New()
+	c.initPaths(w)
+	if c.setup != nil {
+		c.setup(w)
+	}
+	if err := w.ReadInConfig(); err != nil {
+		logrus.Errorf("cannot watch config: %v", err)
+		return
 	}
+	w.OnConfigChange(func(fsnotify.Event) {
+		v, err := c.load()
+		if err != nil {
+			logrus.Errorf("cannot reload config, keeping the previous one: %v", err)
+			return
+		}
+		c.current.Store(v)
+		logrus.Info("config reloaded")
+	})
+	w.WatchConfig()
 }
 
 // IsProduction is true if we are running in a production environment
 func (c *ConfigWrapper) IsProduction() bool {
-	return !c.Viper.GetBool("Debug")
+	return !c.Viper().GetBool("Debug")
 }
 
 // GetDatabase returns postgresql database server connection config
 func (c *ConfigWrapper) GetDatabase() DBConfig {
 	var dbc DBConfig
-	c.Viper.UnmarshalKey("Database", &dbc)
-	dbc.Connection = c.Viper.GetString("DatabaseDSN")
+	c.Viper().UnmarshalKey("Database", &dbc)
+	dbc.Connection = c.Viper().GetString("DatabaseDSN")
 	return dbc
 }
 
 // Override sets a setting key value to whatever you supply.
-// Useful in tests:
+// It modifies the current settings in place so it's only meant for tests:
 //	config.Override("Lbrynet", "http://www.google.com:8080/api/proxy")
 //	defer config.RestoreOverridden()
 //	...
 func (c *ConfigWrapper) Override(key string, value interface{}) {
-	c.overridden[key] = c.Viper.Get(key)
-	c.Viper.Set(key, value)
+	c.overridden[key] = c.Viper().Get(key)
+	c.Viper().Set(key, value)
 }
 
 // RestoreOverridden restores original v values overridden by Override
 func (c *ConfigWrapper) RestoreOverridden() {
-	v := c.Viper
+	v := c.Viper()
 	if len(c.overridden) == 0 {
 		return
 	}
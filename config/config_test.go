@@ -1,22 +1,27 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestOverride(t *testing.T) {
 	c := NewConfig()
-	err := c.Viper.ReadConfig(strings.NewReader("Lbrynet: http://localhost:5279"))
+	err := c.Viper().ReadConfig(strings.NewReader("Lbrynet: http://localhost:5279"))
 	require.Nil(t, err)
-	originalSetting := c.Viper.Get("Lbrynet")
+	originalSetting := c.Viper().Get("Lbrynet")
 	c.Override("Lbrynet", "http://www.google.com:8080/api/proxy")
-	assert.Equal(t, "http://www.google.com:8080/api/proxy", c.Viper.Get("Lbrynet"))
+	assert.Equal(t, "http://www.google.com:8080/api/proxy", c.Viper().Get("Lbrynet"))
 	c.RestoreOverridden()
-	assert.Equal(t, originalSetting, c.Viper.Get("Lbrynet"))
+	assert.Equal(t, originalSetting, c.Viper().Get("Lbrynet"))
 	assert.Empty(t, c.overridden)
 }
 
@@ -27,3 +32,55 @@ func TestIsProduction(t *testing.T) {
 	c.Override("Debug", true)
 	assert.False(t, c.IsProduction())
 }
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	file := filepath.Join(dir, "test.yml")
+	writeConfig(t, file, []byte("Value: 1\n"))
+	c := ReadConfig("test", func(v *viper.Viper) { v.SetDefault("Default", "yes") })
+	c.Watch()
+
+	before := c.Viper()
+	assert.Equal(t, 1, before.GetInt("Value"))
+	writeConfig(t, file, []byte("Value: 2\n"))
+	assert.Eventually(t, func() bool { return c.Viper().GetInt("Value") == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "yes", c.Viper().GetString("Default"))
+	assert.Equal(t, 1, before.GetInt("Value"))
+
+	writeConfig(t, file, []byte("Value: [\n"))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 2, c.Viper().GetInt("Value"))
+}
+
+func TestLoadConfigSetupPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = LoadConfig("setup-paths", nil)
+	assert.Error(t, err)
+
+	file := filepath.Join(dir, "setup-paths.yml")
+	writeConfig(t, file, []byte("Value: 1\n"))
+	c, err := LoadConfig("setup-paths", func(v *viper.Viper) { v.AddConfigPath(dir) })
+	require.NoError(t, err)
+	c.Watch()
+	assert.Equal(t, 1, c.Viper().GetInt("Value"))
+
+	writeConfig(t, file, []byte("Value: 2\n"))
+	assert.Eventually(t, func() bool { return c.Viper().GetInt("Value") == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// writeConfig replaces the config file at once like editors do, so it's never seen empty by the watcher.
+func writeConfig(t *testing.T, file string, content []byte) {
+	tmp := file + ".tmp"
+	require.NoError(t, ioutil.WriteFile(tmp, content, 0644))
+	require.NoError(t, os.Rename(tmp, file))
+}
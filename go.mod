@@ -8,6 +8,8 @@ require (
 	github.com/alecthomas/kong v0.2.16
 	github.com/bluele/factory-go v0.0.1
 	github.com/dgraph-io/ristretto v0.1.0
+	github.com/ericlagergren/decimal v0.0.0-20190204014639-71cf34b7c2b5 // indirect
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/getsentry/sentry-go v0.6.1
 	github.com/gobuffalo/logger v1.0.3 // indirect
 	github.com/gobuffalo/packd v1.0.0 // indirect
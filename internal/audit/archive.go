@@ -0,0 +1,233 @@
+package audit
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+const archiveSuffix = ".gz"
+
+// Uploader ships audit log archives to object storage.
+type Uploader interface {
+	Upload(name string, r io.Reader) error
+}
+
+// HTTPUploader uploads archives with HTTP PUT to URL/<archive name>, which works with object storage
+// accepting plain uploads, like a bucket behind a presigned or authenticating proxy URL.
+type HTTPUploader struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func (u *HTTPUploader) Upload(name string, r io.Reader) error {
+	req, err := http.NewRequest(http.MethodPut, strings.TrimSuffix(u.URL, "/")+"/"+name, r)
+	if err != nil {
+		return errors.Err(err)
+	}
+	for k, v := range u.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.Err(err)
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.Err("archive upload responded with status %v", res.StatusCode)
+	}
+	return nil
+}
+
+// Run rotates the current file once it's older than the rotation interval and archives rotated files
+// every interval until Stop is called.
+func (l *FileLog) Run(interval time.Duration) {
+	defer close(l.done)
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+			if err := l.Maintain(); err != nil {
+				logger.Log().Errorf("cannot archive audit logs: %v", err)
+			}
+		}
+	}
+}
+
+// Stop stops Run and closes the current file.
+func (l *FileLog) Stop() error {
+	close(l.stop)
+	<-l.done
+	return l.Close()
+}
+
+// Maintain rotates the current file if it's due, compresses rotated files, uploads archives which
+// haven't been uploaded yet and deletes archives older than the retention period.
+func (l *FileLog) Maintain() error {
+	l.mu.Lock()
+	if l.file != nil && l.size > 0 && l.expired() {
+		if err := l.rotate(); err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	l.mu.Unlock()
+
+	l.archiveMu.Lock()
+	defer l.archiveMu.Unlock()
+	rotated, err := l.list("")
+	if err != nil {
+		return err
+	}
+	for _, name := range rotated {
+		archive, err := compress(name)
+		if err != nil {
+			return err
+		}
+		if l.uploader != nil {
+			l.pending[archive] = true
+		}
+	}
+	l.upload()
+	_, err = l.expire()
+	return err
+}
+
+// list returns rotated files, or archives of them when suffix is archiveSuffix, from the oldest.
+func (l *FileLog) list(suffix string) ([]string, error) {
+	dir := filepath.Dir(l.cfg.Path)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := l.rotatedAt(e.Name(), suffix); ok {
+			names = append(names, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// rotatedAt parses the rotation time from a rotated file name with suffix.
+func (l *FileLog) rotatedAt(name, suffix string) (time.Time, bool) {
+	base := filepath.Base(l.cfg.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext+suffix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(rotatedTimeLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext+suffix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// upload ships pending archives, those which fail are tried again next time.
+func (l *FileLog) upload() {
+	for archive := range l.pending {
+		f, err := os.Open(archive)
+		if os.IsNotExist(err) {
+			delete(l.pending, archive)
+			continue
+		} else if err != nil {
+			logger.Log().Errorf("cannot open audit log archive %v: %v", archive, err)
+			continue
+		}
+		err = l.uploader.Upload(filepath.Base(archive), f)
+		f.Close()
+		if err != nil {
+			metrics.AuditLogArchiveUploads.WithLabelValues("failed").Inc()
+			logger.Log().Errorf("cannot upload audit log archive %v: %v", archive, err)
+			continue
+		}
+		metrics.AuditLogArchiveUploads.WithLabelValues("uploaded").Inc()
+		delete(l.pending, archive)
+	}
+}
+
+// expire deletes archives rotated longer than the retention period ago, returning how many were deleted.
+func (l *FileLog) expire() (int, error) {
+	if l.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	archives, err := l.list(archiveSuffix)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, archive := range archives {
+		t, _ := l.rotatedAt(filepath.Base(archive), archiveSuffix)
+		if l.now().Sub(t) < l.cfg.Retention {
+			continue
+		}
+		if err := os.Remove(archive); err != nil {
+			return n, errors.Err(err)
+		}
+		delete(l.pending, archive)
+		metrics.AuditLogArchivesExpired.Inc()
+		n++
+	}
+	return n, nil
+}
+
+// compress gzips a rotated file next to it and removes the original, returning the archive name.
+// The archive only appears under its name once it's complete.
+func compress(name string) (string, error) {
+	archive := name + archiveSuffix
+	src, err := os.Open(name)
+	if err != nil {
+		return "", errors.Err(err)
+	}
+	defer src.Close()
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".audit-archive-")
+	if err != nil {
+		return "", errors.Err(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	zw.Name = filepath.Base(name)
+	if _, err := io.Copy(zw, src); err != nil {
+		tmp.Close()
+		return "", errors.Err(err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return "", errors.Err(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", errors.Err(err)
+	}
+	if err := os.Rename(tmp.Name(), archive); err != nil {
+		return "", errors.Err(err)
+	}
+	return archive, errors.Err(os.Remove(name))
+}
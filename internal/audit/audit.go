@@ -1,19 +1,53 @@
 package audit
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/lbryio/lbrytv/internal/monitor"
 	"github.com/lbryio/lbrytv/models"
 	"github.com/volatiletech/null"
 	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	// OutcomeBlocked is for queries rejected by the proxy before reaching the SDK.
+	OutcomeBlocked = "blocked"
 )
 
 var logger = monitor.NewModuleLogger("audit")
 
-func LogQuery(userID int, remoteIP string, method string, body []byte) *models.QueryLog {
-	qLog := models.QueryLog{Method: method, UserID: null.IntFrom(userID), RemoteIP: remoteIP, Body: null.JSONFrom(body)}
+func LogQuery(userID int, remoteIP string, method string, body []byte, outcome string) *models.QueryLog {
+	qLog := models.QueryLog{
+		Method:   method,
+		UserID:   null.IntFrom(userID),
+		RemoteIP: remoteIP,
+		Body:     null.JSONFrom(body),
+		Outcome:  null.NewString(outcome, outcome != ""),
+	}
 	err := qLog.InsertG(boil.Infer())
 	if err != nil {
 		logger.Log().Error("cannot insert query log:", err)
 	}
+	if l := getFileLog(); l != nil {
+		e := Entry{Time: time.Now().UTC(), UserID: userID, RemoteIP: remoteIP, Method: method, Outcome: outcome}
+		if json.Valid(body) {
+			e.Body = body
+		}
+		if err := l.Write(e); err != nil {
+			logger.Log().Error("cannot write query log to file:", err)
+		}
+	}
 	return &qLog
 }
+
+// OutcomeFromResponse returns an outcome value suitable for LogQuery.
+func OutcomeFromResponse(res *jsonrpc.RPCResponse) string {
+	if res == nil || res.Error != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
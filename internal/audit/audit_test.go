@@ -3,7 +3,9 @@ package audit
 import (
 	"encoding/json"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/lbryio/lbry.go/v2/extras/null"
 	"github.com/lbryio/lbrytv/app/query"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/sqlboiler/boil"
 	"github.com/ybbus/jsonrpc"
 )
 
@@ -39,11 +42,12 @@ func TestLogQuery(t *testing.T) {
 		query.MethodWalletSend,
 		map[string]interface{}{"addresses": []string{"dgjkldfjgldkfjgkldfjg"}, "amount": "6.49999000"})
 	q := test.ReqToStr(t, jReq)
-	ql := LogQuery(dummyUserID, "8.8.8.8", query.MethodWalletSend, []byte(q))
+	ql := LogQuery(dummyUserID, "8.8.8.8", query.MethodWalletSend, []byte(q), OutcomeSuccess)
 	ql, err := models.QueryLogs(models.QueryLogWhere.ID.EQ(ql.ID)).OneG()
 	require.NoError(t, err)
 	assert.Equal(t, "8.8.8.8", ql.RemoteIP)
 	assert.EqualValues(t, null.IntFrom(dummyUserID), ql.UserID)
+	assert.Equal(t, OutcomeSuccess, ql.Outcome.String)
 
 	loggedReq := &jsonrpc.RPCRequest{}
 	expReq := &jsonrpc.RPCRequest{}
@@ -62,7 +66,7 @@ func TestLogQueryNoUserNoRemoteIP(t *testing.T) {
 		query.MethodWalletSend,
 		map[string]interface{}{"addresses": []string{"dgjkldfjgldkfjgkldfjg"}, "amount": "6.49999000"})
 	q := test.ReqToStr(t, jReq)
-	ql := LogQuery(dummyUserID, "", query.MethodWalletSend, []byte(q))
+	ql := LogQuery(dummyUserID, "", query.MethodWalletSend, []byte(q), "")
 	ql, err := models.QueryLogs(models.QueryLogWhere.ID.EQ(ql.ID)).OneG()
 	require.NoError(t, err)
 	assert.Equal(t, "", ql.RemoteIP)
@@ -78,3 +82,25 @@ func TestLogQueryNoUserNoRemoteIP(t *testing.T) {
 
 	assert.Equal(t, expReq, loggedReq)
 }
+
+func TestAddCost(t *testing.T) {
+	userID := int(time.Now().UnixNano() % 1000000)
+	total, err := TotalCost(boil.GetDB(), userID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := AddCost(boil.GetDB(), userID, 3)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	total, err = TotalCost(boil.GetDB(), userID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 30, total)
+}
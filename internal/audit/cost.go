@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"database/sql"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// AddCost adds cost to the total accumulated by the user, returning the new total.
+// It's a single upsert so concurrent requests of the same user never lose an increment,
+// which is why it's a raw query: model upserts can only overwrite the total.
+func AddCost(exec boil.Executor, userID int, cost int64) (int64, error) {
+	var total int64
+	err := exec.QueryRow(`
+		INSERT INTO "user_costs" ("user_id", "total") VALUES ($1, $2)
+		ON CONFLICT ("user_id") DO UPDATE SET "total" = "user_costs"."total" + EXCLUDED."total", "updated_at" = now()
+		RETURNING "total"`,
+		userID, cost,
+	).Scan(&total)
+	return total, errors.Err(err)
+}
+
+// TotalCost returns the cost accumulated by the user, zero if nothing has been charged yet.
+func TotalCost(exec boil.Executor, userID int) (int64, error) {
+	c, err := models.FindUserCost(exec, userID, models.UserCostColumns.Total)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Err(err)
+	}
+	return c.Total, nil
+}
@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+// rotatedTimeLayout is the timestamp in names of rotated files, they sort in the order they were rotated.
+const rotatedTimeLayout = "20060102T150405.000000000"
+
+// Entry is a query log line in audit log files.
+type Entry struct {
+	Time     time.Time       `json:"time"`
+	UserID   int             `json:"user_id,omitempty"`
+	RemoteIP string          `json:"remote_ip,omitempty"`
+	Method   string          `json:"method"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Outcome  string          `json:"outcome,omitempty"`
+}
+
+// FileLog appends audit entries as JSON lines to the current file, which is rotated once it reaches
+// its maximum size or age. Rotated files are compressed, shipped and expired by Run.
+type FileLog struct {
+	cfg      config.AuditLog
+	uploader Uploader
+	now      func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	archiveMu sync.Mutex
+	// pending are archives which couldn't be uploaded yet.
+	pending map[string]bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+var (
+	fileLogMu sync.RWMutex
+	fileLog   *FileLog
+)
+
+// SetFileLog sets the file log LogQuery writes to in addition to the database, nil turns it off.
+func SetFileLog(l *FileLog) {
+	fileLogMu.Lock()
+	defer fileLogMu.Unlock()
+	fileLog = l
+}
+
+func getFileLog() *FileLog {
+	fileLogMu.RLock()
+	defer fileLogMu.RUnlock()
+	return fileLog
+}
+
+// NewFileLog opens the current audit log file at cfg.Path, creating its directory if missing.
+// Archives are uploaded with uploader if it's not nil.
+func NewFileLog(cfg config.AuditLog, uploader Uploader) (*FileLog, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, errors.Err(err)
+	}
+	l := &FileLog{
+		cfg:      cfg,
+		uploader: uploader,
+		now:      time.Now,
+		pending:  map[string]bool{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends an entry to the current file, rotating it first if the entry would take it over
+// the maximum size or the file is older than the rotation interval.
+func (l *FileLog) Write(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return errors.Err(err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return errors.Err("audit log is closed")
+	}
+	if l.size > 0 && (l.cfg.MaxFileSize > 0 && l.size+int64(len(line)) > l.cfg.MaxFileSize || l.expired()) {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return errors.Err(err)
+}
+
+// Rotate starts a new current file if the current one isn't empty.
+func (l *FileLog) Rotate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil || l.size == 0 {
+		return nil
+	}
+	return l.rotate()
+}
+
+// Close closes the current file. Entries can't be written after it.
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return errors.Err(err)
+}
+
+func (l *FileLog) open() error {
+	f, err := os.OpenFile(l.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Err(err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Err(err)
+	}
+	l.file = f
+	l.size = st.Size()
+	l.openedAt = l.now()
+	return nil
+}
+
+// rotate renames the current file for archiving and opens a new one. Writes are held off by l.mu meanwhile,
+// so every entry ends up in either of them.
+func (l *FileLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return errors.Err(err)
+	}
+	l.file = nil
+	if err := os.Rename(l.cfg.Path, l.freeRotatedName()); err != nil {
+		// Keep writing to the current file rather than losing entries
+		if oerr := l.open(); oerr != nil {
+			return oerr
+		}
+		return errors.Err(err)
+	}
+	metrics.AuditLogRotations.Inc()
+	return l.open()
+}
+
+func (l *FileLog) expired() bool {
+	return l.cfg.RotateInterval > 0 && l.now().Sub(l.openedAt) >= l.cfg.RotateInterval
+}
+
+// freeRotatedName returns a name for the file being rotated which isn't taken by another rotated file or archive.
+func (l *FileLog) freeRotatedName() string {
+	t := l.now()
+	for {
+		name := l.rotatedName(t)
+		if !exists(name) && !exists(name+archiveSuffix) {
+			return name
+		}
+		t = t.Add(time.Nanosecond)
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return !os.IsNotExist(err)
+}
+
+// rotatedName returns the name the current file gets when rotated at t, e.g. audit-20210901T120000.000000000.jsonl
+// for audit.jsonl.
+func (l *FileLog) rotatedName(t time.Time) string {
+	ext := filepath.Ext(l.cfg.Path)
+	return strings.TrimSuffix(l.cfg.Path, ext) + "-" + t.UTC().Format(rotatedTimeLayout) + ext
+}
@@ -0,0 +1,232 @@
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEntries returns entries from all audit log files in dir, both plain and compressed, along with file names.
+func readEntries(t *testing.T, dir string) ([]Entry, []string) {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var entries []Entry
+	var names []string
+	for _, fi := range files {
+		names = append(names, fi.Name())
+		f, err := os.Open(filepath.Join(dir, fi.Name()))
+		require.NoError(t, err)
+		var r io.Reader = f
+		if filepath.Ext(fi.Name()) == archiveSuffix {
+			r, err = gzip.NewReader(f)
+			require.NoError(t, err)
+		}
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			var e Entry
+			require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+			entries = append(entries, e)
+		}
+		require.NoError(t, s.Err())
+		f.Close()
+	}
+	sort.Strings(names)
+	return entries, names
+}
+
+func testEntry(n int) Entry {
+	return Entry{
+		Time: time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC), UserID: n, Method: "wallet_send",
+		Body: json.RawMessage(`{"amount":"1.0"}`), Outcome: OutcomeSuccess,
+	}
+}
+
+func TestFileLogRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	line, err := json.Marshal(testEntry(1))
+	require.NoError(t, err)
+	l, err := NewFileLog(config.AuditLog{Path: filepath.Join(dir, "audit.jsonl"), MaxFileSize: int64(len(line)+1) * 2}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, l.Write(testEntry(i)))
+	}
+	entries, names := readEntries(t, dir)
+	assert.Len(t, entries, 5)
+	require.Len(t, names, 3)
+	assert.Equal(t, "audit.jsonl", names[2])
+
+	require.NoError(t, l.Maintain())
+	entries, names = readEntries(t, dir)
+	assert.Len(t, entries, 5)
+	assert.Regexp(t, `^audit-\d{8}T\d{6}\.\d{9}\.jsonl\.gz$`, names[0])
+	assert.Regexp(t, `^audit-\d{8}T\d{6}\.\d{9}\.jsonl\.gz$`, names[1])
+	assert.Equal(t, "audit.jsonl", names[2])
+}
+
+func TestFileLogRotatesByInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewFileLog(config.AuditLog{Path: filepath.Join(dir, "audit.jsonl"), RotateInterval: time.Hour}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+	l.now = func() time.Time { return now }
+	l.openedAt = now
+
+	require.NoError(t, l.Write(testEntry(1)))
+	require.NoError(t, l.Maintain())
+	_, names := readEntries(t, dir)
+	assert.Equal(t, []string{"audit.jsonl"}, names)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, l.Maintain())
+	entries, names := readEntries(t, dir)
+	assert.Equal(t, []string{"audit-20210901T130000.000000000.jsonl.gz", "audit.jsonl"}, names)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].UserID)
+
+	// Empty files are not rotated
+	now = now.Add(time.Hour)
+	require.NoError(t, l.Maintain())
+	_, names = readEntries(t, dir)
+	assert.Len(t, names, 2)
+}
+
+func TestFileLogConcurrentRotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewFileLog(config.AuditLog{Path: filepath.Join(dir, "audit.jsonl"), MaxFileSize: 1000}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, l.Write(testEntry(w*100+i+1)))
+			}
+		}(w)
+	}
+	stop := make(chan struct{})
+	maintained := make(chan struct{})
+	go func() {
+		defer close(maintained)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				assert.NoError(t, l.Rotate())
+				assert.NoError(t, l.Maintain())
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-maintained
+	require.NoError(t, l.Maintain())
+
+	entries, _ := readEntries(t, dir)
+	seen := map[int]bool{}
+	for _, e := range entries {
+		seen[e.UserID] = true
+	}
+	assert.Len(t, entries, 1000)
+	assert.Len(t, seen, 1000)
+}
+
+func TestFileLogRetention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewFileLog(config.AuditLog{Path: filepath.Join(dir, "audit.jsonl"), Retention: 24 * time.Hour}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+	l.now = func() time.Time { return now }
+
+	for _, name := range []string{
+		"audit-20210830T120000.000000000.jsonl.gz",
+		"audit-20210831T115959.000000000.jsonl.gz",
+		"audit-20210831T120001.000000000.jsonl.gz",
+		"other-20210801T120000.000000000.jsonl.gz",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	require.NoError(t, l.Write(testEntry(1)))
+	require.NoError(t, l.Rotate())
+	require.NoError(t, l.Maintain())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{
+		"audit-20210831T120001.000000000.jsonl.gz",
+		"audit-20210901T120000.000000000.jsonl.gz",
+		"audit.jsonl",
+		"other-20210801T120000.000000000.jsonl.gz",
+	}, names)
+}
+
+func TestFileLogUpload(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := map[string][]byte{}
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		uploaded[r.URL.Path] = body
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	uploader := &HTTPUploader{URL: srv.URL + "/audit/", Headers: map[string]string{"Authorization": "secret"}}
+	l, err := NewFileLog(config.AuditLog{Path: filepath.Join(dir, "audit.jsonl")}, uploader)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.Write(testEntry(1)))
+	require.NoError(t, l.Rotate())
+	require.NoError(t, l.Maintain())
+	assert.Empty(t, uploaded)
+	assert.Len(t, l.pending, 1)
+
+	// Failed uploads are retried
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	require.NoError(t, l.Maintain())
+	_, names := readEntries(t, dir)
+	require.Len(t, uploaded, 1)
+	content, err := ioutil.ReadFile(filepath.Join(dir, names[0]))
+	require.NoError(t, err)
+	assert.Equal(t, content, uploaded[fmt.Sprintf("/audit/%v", names[0])])
+	assert.Empty(t, l.pending)
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+// HistoryFilter defines which audited operations should be retrieved by History.
+// Zero values of Method, After and Before mean no filtering on the respective field.
+type HistoryFilter struct {
+	UserID   int
+	Method   string
+	After    time.Time
+	Before   time.Time
+	Page     int
+	PageSize int
+}
+
+// HistoryItem is a single audited operation, safe to be exposed to the user.
+type HistoryItem struct {
+	ID        int                    `json:"id"`
+	Method    string                 `json:"method"`
+	Timestamp time.Time              `json:"timestamp"`
+	Outcome   string                 `json:"outcome,omitempty"`
+	Summary   map[string]interface{} `json:"summary,omitempty"`
+}
+
+// HistoryPage is a page of audited operations returned by History.
+type HistoryPage struct {
+	Items    []HistoryItem `json:"items"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	HasMore  bool          `json:"has_more"`
+	// TotalCost is the cost of requests accumulated by the user (see config.GetRequestCosts).
+	TotalCost int64 `json:"total_cost"`
+}
+
+// History retrieves audited operations of a single user, most recent first.
+func History(f HistoryFilter) (*HistoryPage, error) {
+	if f.UserID == 0 {
+		return nil, errors.Err("user id is required")
+	}
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultHistoryPageSize
+	} else if f.PageSize > MaxHistoryPageSize {
+		f.PageSize = MaxHistoryPageSize
+	}
+
+	mods := []qm.QueryMod{
+		models.QueryLogWhere.UserID.EQ(null.IntFrom(f.UserID)),
+	}
+	if f.Method != "" {
+		mods = append(mods, models.QueryLogWhere.Method.EQ(f.Method))
+	}
+	if !f.After.IsZero() {
+		mods = append(mods, models.QueryLogWhere.Timestamp.GTE(f.After))
+	}
+	if !f.Before.IsZero() {
+		mods = append(mods, models.QueryLogWhere.Timestamp.LT(f.Before))
+	}
+	// Fetching one extra record to find out if there is a next page
+	mods = append(mods,
+		qm.OrderBy(models.QueryLogColumns.Timestamp+" DESC, "+models.QueryLogColumns.ID+" DESC"),
+		qm.Limit(f.PageSize+1),
+		qm.Offset((f.Page-1)*f.PageSize),
+	)
+
+	logs, err := models.QueryLogs(mods...).AllG()
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+
+	total, err := TotalCost(boil.GetDB(), f.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HistoryPage{Items: []HistoryItem{}, Page: f.Page, PageSize: f.PageSize, TotalCost: total}
+	if len(logs) > f.PageSize {
+		page.HasMore = true
+		logs = logs[:f.PageSize]
+	}
+	for _, l := range logs {
+		page.Items = append(page.Items, toHistoryItem(l))
+	}
+	return page, nil
+}
+
+func toHistoryItem(l *models.QueryLog) HistoryItem {
+	return HistoryItem{
+		ID:        l.ID,
+		Method:    l.Method,
+		Timestamp: l.Timestamp,
+		Outcome:   l.Outcome.String,
+		Summary:   summarizeBody(l.Body.JSON),
+	}
+}
+
+// summarizeBody extracts JSON-RPC request params from the logged query body
+// and masks sensitive values in them.
+func summarizeBody(body []byte) map[string]interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var req struct {
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	return monitor.RedactParams(req.Params)
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/responses"
+)
+
+var (
+	errAuthRequired = errors.Base("authentication required")
+	errForbidden    = errors.Base("not allowed to access history of another user")
+)
+
+func errInvalidParam(name string) error {
+	return errors.Base("invalid %v parameter", name)
+}
+
+func errMissingParam(name string) error {
+	return errors.Base("%v parameter is required", name)
+}
+
+// HandleHistory returns a page of audited operations for the requesting user.
+// Admins (see auth.IsAdmin) can retrieve history of any user by supplying user_id.
+// Supported query parameters: method, after, before (RFC3339), page, page_size.
+func HandleHistory(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+
+	userID, code, err := historyUserID(r)
+	if err != nil {
+		writeHistoryError(w, code, err.Error())
+		return
+	}
+
+	f := HistoryFilter{UserID: userID, Method: r.FormValue("method")}
+	if f.After, err = parseTimeParam(r, "after"); err != nil {
+		writeHistoryError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Before, err = parseTimeParam(r, "before"); err != nil {
+		writeHistoryError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.Page, err = parseIntParam(r, "page"); err != nil {
+		writeHistoryError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f.PageSize, err = parseIntParam(r, "page_size"); err != nil {
+		writeHistoryError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := History(f)
+	if err != nil {
+		logger.Log().Errorf("cannot retrieve history for user %v: %v", userID, err)
+		writeHistoryError(w, http.StatusInternalServerError, "cannot retrieve history")
+		return
+	}
+	respByte, _ := json.Marshal(page)
+	w.Write(respByte)
+}
+
+// historyUserID determines whose history is being requested and whether the requester is allowed to see it.
+func historyUserID(r *http.Request) (int, int, error) {
+	var requestedID int
+	if v := r.FormValue("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return 0, http.StatusBadRequest, errInvalidParam("user_id")
+		}
+		requestedID = id
+	}
+
+	if auth.IsAdmin(r) {
+		if requestedID == 0 {
+			return 0, http.StatusBadRequest, errMissingParam("user_id")
+		}
+		return requestedID, 0, nil
+	}
+
+	user, err := auth.FromRequest(r)
+	if err != nil || user == nil {
+		return 0, http.StatusUnauthorized, errAuthRequired
+	}
+	if requestedID != 0 && requestedID != user.ID {
+		return 0, http.StatusForbidden, errForbidden
+	}
+	return user.ID, 0, nil
+}
+
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errInvalidParam(name)
+	}
+	return t, nil
+}
+
+func parseIntParam(r *http.Request, name string) (int, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, errInvalidParam(name)
+	}
+	return i, nil
+}
+
+func writeHistoryError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	respByte, _ := json.Marshal(map[string]string{"error": msg})
+	w.Write(respByte)
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/ybbus/jsonrpc"
+)
+
+func historyRequest(t *testing.T, target string, userID int, admin bool) *httptest.ResponseRecorder {
+	t.Helper()
+	r, err := http.NewRequest(http.MethodGet, target, nil)
+	require.NoError(t, err)
+	if userID != 0 {
+		r.Header.Set(wallet.TokenHeader, "history-token")
+	}
+	if admin {
+		r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+	}
+	provider := func(token, ip string) (*models.User, error) {
+		return &models.User{ID: userID}, nil
+	}
+	rr := httptest.NewRecorder()
+	middleware.Apply(auth.Middleware(provider), HandleHistory).ServeHTTP(rr, r)
+	return rr
+}
+
+func TestHandleHistoryAuthorization(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	rr := historyRequest(t, "/api/v1/history", 0, false)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = historyRequest(t, "/api/v1/history?user_id=2", 1, false)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = historyRequest(t, "/api/v1/history?user_id=abc", 1, false)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = historyRequest(t, "/api/v1/history", 0, true)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "user_id parameter is required")
+
+	rr = historyRequest(t, "/api/v1/history?after=yesterday", 1, false)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid after parameter")
+}
+
+func TestHandleHistory(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	userID := int(time.Now().UnixNano() % 1000000)
+	for i := 0; i < 3; i++ {
+		q := test.ReqToStr(t, jsonrpc.NewRequest(
+			query.MethodWalletSend,
+			map[string]interface{}{"addresses": []string{"bPxDgjkldfjgl"}, "amount": fmt.Sprintf("%v.0", i), "password": "hunter2"}))
+		LogQuery(userID, "8.8.8.8", query.MethodWalletSend, []byte(q), OutcomeSuccess)
+	}
+	LogQuery(userID, "8.8.8.8", "wallet_balance", []byte(`{"method": "wallet_balance"}`), OutcomeError)
+	_, err := AddCost(boil.GetDB(), userID, 15)
+	require.NoError(t, err)
+
+	rr := historyRequest(t, fmt.Sprintf("/api/v1/history?method=%v&page_size=2", query.MethodWalletSend), userID, false)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "hunter2")
+	assert.NotContains(t, rr.Body.String(), "8.8.8.8")
+
+	page := HistoryPage{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	require.Len(t, page.Items, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, query.MethodWalletSend, page.Items[0].Method)
+	assert.Equal(t, OutcomeSuccess, page.Items[0].Outcome)
+	assert.Equal(t, "2.0", page.Items[0].Summary["amount"])
+	assert.Equal(t, "****", page.Items[0].Summary["password"])
+	assert.EqualValues(t, 15, page.TotalCost)
+
+	rr = historyRequest(t, fmt.Sprintf("/api/v1/history?user_id=%v&page=2&page_size=2", userID), 0, true)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	page = HistoryPage{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	require.Len(t, page.Items, 2)
+	assert.False(t, page.HasMore)
+
+	rr = historyRequest(t, fmt.Sprintf("/api/v1/history?after=%v", time.Now().Add(time.Hour).Format(time.RFC3339)), userID, false)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	page = HistoryPage{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 0)
+}
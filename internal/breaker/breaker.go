@@ -0,0 +1,300 @@
+// Package breaker implements circuit breakers which stop calls to failing dependencies for a while.
+package breaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+)
+
+// Kinds of breakers.
+const (
+	KindEndpoint = "endpoint"
+	KindMethod   = "method"
+	// KindUser breakers guard calls of a single user. There can be a lot of them,
+	// so their states are not exported to metrics.
+	KindUser = "user"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed breakers let calls through.
+	Closed State = iota
+	// HalfOpen breakers let a single trial call through, which decides if they close or open again.
+	HalfOpen
+	// Open breakers refuse calls until their cooldown passes.
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "closed"
+}
+
+var logger = monitor.NewModuleLogger("breaker")
+
+// Breaker opens after Threshold failures within Window and after Cooldown lets a trial call through.
+type Breaker struct {
+	Kind string
+	Name string
+
+	mu       sync.Mutex
+	settings config.CircuitBreaker
+	state    State
+	failures []time.Time
+	openedAt time.Time
+	probing  bool
+}
+
+// Status describes the breaker state at a point in time.
+type Status struct {
+	Kind     string     `json:"kind"`
+	Name     string     `json:"name"`
+	State    string     `json:"state"`
+	Failures int        `json:"recent_failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// New creates a closed breaker.
+func New(kind, name string, settings config.CircuitBreaker) *Breaker {
+	b := &Breaker{Kind: kind, Name: name, settings: settings}
+	b.reportState()
+	return b
+}
+
+// Allow returns true if a call can be made. An open breaker past its cooldown becomes half-open and
+// allows a single trial call, the outcome of which must be reported with Success, Failure or Release.
+func (b *Breaker) Allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if now.Sub(b.openedAt) < b.settings.Cooldown {
+			return false
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Release gives back a trial call allowed by a half-open breaker which hasn't been made.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// Success records a successful call. It closes a half-open breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.failures = nil
+		b.probing = false
+		b.setState(Closed)
+		logger.Log().Infof("%v circuit breaker for %v closed", b.Kind, b.Name)
+	}
+}
+
+// Failure records a failed call. It opens a half-open breaker or a closed one which has reached its threshold
+// and returns true if it has.
+func (b *Breaker) Failure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case HalfOpen:
+		b.open(now)
+		return true
+	case Closed:
+		b.failures = append(b.recentFailures(now), now)
+		if b.settings.Threshold > 0 && len(b.failures) >= b.settings.Threshold {
+			b.open(now)
+			return true
+		}
+	}
+	return false
+}
+
+// RetryIn returns how long until an open breaker lets a trial call through, zero if it's not open.
+func (b *Breaker) RetryIn(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != Open {
+		return 0
+	}
+	if left := b.settings.Cooldown - now.Sub(b.openedAt); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Status returns the current breaker state. Open breakers past their cooldown are reported as half-open.
+func (b *Breaker) Status(now time.Time) Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := Status{Kind: b.Kind, Name: b.Name, State: b.state.String(), Failures: len(b.recentFailures(now))}
+	if b.state != Closed {
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+		if b.state == Open && now.Sub(b.openedAt) >= b.settings.Cooldown {
+			s.State = HalfOpen.String()
+		}
+	}
+	return s
+}
+
+func (b *Breaker) setSettings(settings config.CircuitBreaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.settings = settings
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.openedAt = now
+	b.probing = false
+	b.failures = nil
+	b.setState(Open)
+	logger.Log().Warnf("%v circuit breaker for %v opened", b.Kind, b.Name)
+}
+
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.reportState()
+}
+
+func (b *Breaker) reportState() {
+	if b.Kind == KindUser {
+		return
+	}
+	metrics.ProxyCircuitBreakerState.WithLabelValues(b.Kind, b.Name).Set(float64(b.state))
+}
+
+// idle returns true if the breaker holds nothing worth keeping: it's closed without recent failures
+// or it has been open for longer than its cooldown without a trial call being made.
+func (b *Breaker) idle(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Closed:
+		return len(b.recentFailures(now)) == 0
+	case Open:
+		return now.Sub(b.openedAt) >= b.settings.Cooldown
+	}
+	return false
+}
+
+// recentFailures returns failures within the window, dropping the older ones.
+func (b *Breaker) recentFailures(now time.Time) []time.Time {
+	i := 0
+	for i < len(b.failures) && now.Sub(b.failures[i]) > b.settings.Window {
+		i++
+	}
+	b.failures = b.failures[i:]
+	return b.failures
+}
+
+// Registry keeps breakers by kind and name.
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{breakers: map[string]*Breaker{}}
+}
+
+// Get returns the breaker of kind for name, creating it if needed. Settings of existing breakers are updated.
+func (r *Registry) Get(kind, name string, settings config.CircuitBreaker) *Breaker {
+	key := kind + "\n" + name
+	r.mu.Lock()
+	b, ok := r.breakers[key]
+	if !ok {
+		b = New(kind, name, settings)
+		r.breakers[key] = b
+	}
+	r.mu.Unlock()
+	if ok {
+		b.setSettings(settings)
+	}
+	return b
+}
+
+// Lookup returns the breaker of kind for name or nil if there's none.
+func (r *Registry) Lookup(kind, name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.breakers[kind+"\n"+name]
+}
+
+// Prune removes idle breakers, so registries of breakers created on demand don't grow without bounds.
+// It returns the number of breakers removed.
+func (r *Registry) Prune(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for k, b := range r.breakers {
+		if b.idle(now) {
+			delete(r.breakers, k)
+			n++
+		}
+	}
+	return n
+}
+
+// Status returns states of all breakers, ordered by kind and name.
+func (r *Registry) Status(now time.Time) []Status {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status(now))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Kind != statuses[j].Kind {
+			return statuses[i].Kind < statuses[j].Kind
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// AllowAll returns true if all breakers allow a call. When one of them doesn't,
+// trial calls allowed by the others are released so they're not held up.
+func AllowAll(breakers []*Breaker, now time.Time) bool {
+	for i, b := range breakers {
+		if !b.Allow(now) {
+			for _, allowed := range breakers[:i] {
+				allowed.Release()
+			}
+			return false
+		}
+	}
+	return true
+}
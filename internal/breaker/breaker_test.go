@@ -0,0 +1,130 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var settings = config.CircuitBreaker{Threshold: 3, Window: time.Minute, Cooldown: 30 * time.Second}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := New(KindMethod, "test_method", settings)
+	state := func() float64 {
+		m := metrics.GetMetric(metrics.ProxyCircuitBreakerState.WithLabelValues(KindMethod, "test_method"))
+		return m.GetGauge().GetValue()
+	}
+
+	// Failures outside the window don't count
+	b.Failure(now.Add(-2 * time.Minute))
+	b.Failure(now)
+	b.Failure(now)
+	assert.True(t, b.Allow(now))
+	assert.Equal(t, "closed", b.Status(now).State)
+	assert.Equal(t, 2, b.Status(now).Failures)
+
+	b.Failure(now)
+	assert.False(t, b.Allow(now))
+	assert.Equal(t, "open", b.Status(now).State)
+	assert.EqualValues(t, Open, state())
+
+	// After the cooldown a single trial call is let through
+	later := now.Add(31 * time.Second)
+	assert.Equal(t, "half-open", b.Status(later).State)
+	assert.True(t, b.Allow(later))
+	assert.EqualValues(t, HalfOpen, state())
+	assert.False(t, b.Allow(later))
+
+	// Failed trial opens the breaker again
+	b.Failure(later)
+	assert.False(t, b.Allow(later.Add(time.Second)))
+	assert.Equal(t, "open", b.Status(later).State)
+
+	// Successful trial closes it
+	later = later.Add(31 * time.Second)
+	assert.True(t, b.Allow(later))
+	b.Success()
+	assert.Equal(t, Status{Kind: KindMethod, Name: "test_method", State: "closed"}, b.Status(later))
+	assert.EqualValues(t, Closed, state())
+	assert.True(t, b.Allow(later))
+	assert.True(t, b.Allow(later))
+}
+
+func TestBreaker_Release(t *testing.T) {
+	now := time.Now()
+	b := New(KindEndpoint, "http://sdk", config.CircuitBreaker{Threshold: 1, Window: time.Minute, Cooldown: time.Second})
+	b.Failure(now)
+	later := now.Add(2 * time.Second)
+	assert.True(t, b.Allow(later))
+	assert.False(t, b.Allow(later))
+	b.Release()
+	assert.True(t, b.Allow(later))
+}
+
+func TestAllowAll(t *testing.T) {
+	now := time.Now()
+	method := New(KindMethod, "m", config.CircuitBreaker{Threshold: 1, Window: time.Minute, Cooldown: time.Second})
+	endpoint := New(KindEndpoint, "e", config.CircuitBreaker{Threshold: 1, Window: time.Minute, Cooldown: time.Hour})
+
+	assert.True(t, AllowAll([]*Breaker{method, endpoint}, now))
+
+	method.Failure(now)
+	endpoint.Failure(now)
+	later := now.Add(2 * time.Second)
+	// The method breaker is half-open but the endpoint one is still open, the trial call is not used up
+	assert.False(t, AllowAll([]*Breaker{method, endpoint}, later))
+	assert.True(t, method.Allow(later))
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	b := r.Get(KindMethod, "resolve", settings)
+	assert.Same(t, b, r.Get(KindMethod, "resolve", config.CircuitBreaker{Threshold: 1, Window: time.Minute, Cooldown: time.Minute}))
+	r.Get(KindEndpoint, "http://sdk2", settings)
+	r.Get(KindEndpoint, "http://sdk1", settings)
+
+	// Updated settings are applied
+	now := time.Now()
+	b.Failure(now)
+	assert.False(t, b.Allow(now))
+
+	st := r.Status(now)
+	require.Len(t, st, 3)
+	assert.Equal(t, "http://sdk1", st[0].Name)
+	assert.Equal(t, "http://sdk2", st[1].Name)
+	assert.Equal(t, "resolve", st[2].Name)
+	assert.Equal(t, "open", st[2].State)
+	require.NotNil(t, st[2].OpenedAt)
+	assert.Equal(t, now, *st[2].OpenedAt)
+}
+
+func TestRegistry_Prune(t *testing.T) {
+	r := NewRegistry()
+	now := time.Now()
+	assert.Nil(t, r.Lookup(KindUser, "1"))
+
+	failing := r.Get(KindUser, "1", settings)
+	failing.Failure(now)
+	open := r.Get(KindUser, "2", settings)
+	for i := 0; i < settings.Threshold; i++ {
+		open.Failure(now)
+	}
+	assert.Equal(t, settings.Cooldown, open.RetryIn(now))
+	assert.Equal(t, time.Duration(0), failing.RetryIn(now))
+	r.Get(KindUser, "3", settings)
+
+	assert.Equal(t, 1, r.Prune(now))
+	assert.Same(t, failing, r.Lookup(KindUser, "1"))
+	assert.Same(t, open, r.Lookup(KindUser, "2"))
+	assert.Nil(t, r.Lookup(KindUser, "3"))
+
+	// Failures have left the window and the cooldown has passed without the user coming back
+	assert.Equal(t, 2, r.Prune(now.Add(2*time.Minute)))
+	assert.Empty(t, r.Status(now))
+}
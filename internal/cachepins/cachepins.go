@@ -0,0 +1,98 @@
+// Package cachepins keeps responses to queries listed in CachePins in the query cache,
+// refreshing them proactively so they're always served from the cache.
+package cachepins
+
+import (
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+var logger = monitor.NewModuleLogger("cachepins")
+
+// Refresher pins queries in the cache and refreshes their responses.
+type Refresher struct {
+	cache *cache.Cache
+	rt    *sdkrouter.Router
+	// pins returns the current pin list, it's config.GetCachePins outside of tests.
+	pins func() []config.CachePin
+}
+
+// New creates a refresher for pins from the config, sending queries to SDK servers of rt.
+func New(c *cache.Cache, rt *sdkrouter.Router) *Refresher {
+	return &Refresher{cache: c, rt: rt, pins: config.GetCachePins}
+}
+
+// Run refreshes pinned queries every interval, picking up changes to the pin list.
+func (r *Refresher) Run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		r.Refresh()
+		<-t.C
+	}
+}
+
+// Refresh pins queries currently listed and sends them to the SDK through the cache, replacing stored responses.
+// It returns the number of queries which failed to refresh, their earlier responses stay in the cache.
+func (r *Refresher) Refresh() int {
+	pins := r.pins()
+	cachePins := make([]cache.Pin, len(pins))
+	for i, p := range pins {
+		method, params := expand(p)
+		cachePins[i] = cache.Pin{Method: method, Params: params}
+	}
+	if err := r.cache.SetPins(cachePins); err != nil {
+		logger.Log().Errorf("cannot pin cache entries: %v", err)
+		return len(pins)
+	}
+
+	failed := 0
+	for _, p := range cachePins {
+		if err := r.refresh(p); err != nil {
+			failed++
+			metrics.ProxyQueryCachePinRefreshFailures.WithLabelValues(p.Method).Inc()
+			logger.Log().Warnf("cannot refresh pinned %v query: %v", p.Method, err)
+		}
+	}
+	return failed
+}
+
+func (r *Refresher) refresh(p cache.Pin) error {
+	if _, ok := config.GetCacheableMethods()[p.Method]; !ok {
+		return errors.Err("method %v is not cacheable", p.Method)
+	}
+	c := query.NewCaller(r.rt.RandomServer().Address, 0)
+	c.Cache = r.cache
+	c.BypassCache = true
+	req := jsonrpc.NewRequest(p.Method)
+	if p.Params != nil {
+		req = jsonrpc.NewRequest(p.Method, p.Params)
+	}
+	res, err := c.Call(req)
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return errors.Err(res.Error.Message)
+	}
+	return nil
+}
+
+func expand(p config.CachePin) (string, interface{}) {
+	if p.URI != "" {
+		return query.MethodResolve, map[string]interface{}{"urls": []interface{}{p.URI}}
+	}
+	if p.Params == nil {
+		return p.Method, nil
+	}
+	return p.Method, p.Params
+}
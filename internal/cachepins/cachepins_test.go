@@ -0,0 +1,56 @@
+package cachepins
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query/cache"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestRefresh(t *testing.T) {
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+	respond := func(res string) {
+		go func() {
+			<-reqChan
+			srv.NextResponse <- res
+		}()
+	}
+
+	c, err := cache.New(cache.DefaultConfig())
+	require.NoError(t, err)
+	r := New(c, sdkrouter.NewWithServers(&models.LbrynetServer{Address: srv.URL}))
+	r.pins = func() []config.CachePin {
+		return []config.CachePin{{URI: "lbry://featured"}, {Method: "wallet_balance"}}
+	}
+	params := map[string]interface{}{"urls": []interface{}{"lbry://featured"}}
+	failures := metrics.GetCounterValue(metrics.ProxyQueryCachePinRefreshFailures.WithLabelValues("resolve"))
+
+	respond(`{"jsonrpc": "2.0", "id": 0, "result": {"lbry://featured": {"name": "featured"}}}`)
+	// wallet_balance is not cacheable so it's never sent
+	assert.Equal(t, 1, r.Refresh())
+	assert.Equal(t, 1, c.Pinned())
+	v, ok := c.Get("resolve", params)
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"name": "featured"}, v.(*jsonrpc.RPCResponse).Result.(map[string]interface{})["lbry://featured"])
+
+	// Failed refresh keeps the previous response
+	respond(`{"jsonrpc": "2.0", "id": 0, "error": {"code": -32500, "message": "sdk is busy"}}`)
+	assert.Equal(t, 2, r.Refresh())
+	assert.Equal(t, failures+1, metrics.GetCounterValue(metrics.ProxyQueryCachePinRefreshFailures.WithLabelValues("resolve")))
+	_, ok = c.Get("resolve", params)
+	assert.True(t, ok)
+
+	r.pins = func() []config.CachePin { return nil }
+	assert.Equal(t, 0, r.Refresh())
+	assert.Equal(t, 0, c.Pinned())
+}
@@ -0,0 +1,121 @@
+// Package canary splits clients between the stable and canary variants of API code paths served by instances
+// running a canary build, so new code can be tried on a fraction of traffic and compared against the stable one.
+// Clients are bucketed by user ID, or IP address for anonymous ones, so a client stays on the same variant.
+package canary
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/version"
+)
+
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+// Headers telling admins (see auth.IsAdmin) which build has served the request and which variant the client is on.
+const (
+	BuildHeader   = "X-API-Build"
+	VariantHeader = "X-API-Variant"
+)
+
+type ctxKey int
+
+const contextKey ctxKey = iota
+
+// Bucket consistently maps key to a number from 0 to 99.
+func Bucket(key string) int {
+	sum := sha256.Sum256([]byte(key))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+// Variant returns the variant of a client identified by key. Clients get the canary variant only
+// on instances with canary enabled, in the proportion set by Percentage.
+func Variant(c config.Canary, key string) string {
+	if !c.Enabled || Bucket(key) >= c.Percentage {
+		return VariantStable
+	}
+	return VariantCanary
+}
+
+// Build returns the identifier of the build this instance is running, as configured or the app version.
+func Build(c config.Canary) string {
+	if c.Build != "" {
+		return c.Build
+	}
+	return version.GetDevVersion()
+}
+
+// clientKey identifies the client making the request for bucketing.
+func clientKey(r *http.Request) string {
+	if user, err := auth.FromRequest(r); err == nil && user != nil {
+		return "user:" + strconv.Itoa(user.ID)
+	}
+	return "ip:" + ip.FromRequest(r)
+}
+
+// FromRequest returns the variant attached to the request by Middleware, stable if there's none.
+func FromRequest(r *http.Request) string {
+	if v, ok := r.Context().Value(contextKey).(string); ok {
+		return v
+	}
+	return VariantStable
+}
+
+// IsCanary tells if the request should take canary code paths.
+func IsCanary(r *http.Request) bool {
+	return FromRequest(r) == VariantCanary
+}
+
+// SDKServer returns the address of a random canary SDK server (see config.Canary) for a request of a canary client,
+// skipping those rt has quarantined or is draining. It's empty for stable clients or if there's no such server.
+func SDKServer(r *http.Request, c config.Canary, rt *sdkrouter.Router) string {
+	if !IsCanary(r) {
+		return ""
+	}
+	var candidates []string
+	for _, a := range c.SDKServers {
+		if rt != nil && (rt.IsQuarantined(a) || rt.IsDraining(a)) {
+			continue
+		}
+		candidates = append(candidates, a)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// AddToRequest returns a copy of the request with variant attached.
+func AddToRequest(r *http.Request, variant string) *http.Request {
+	return r.Clone(context.WithValue(r.Context(), contextKey, variant))
+}
+
+// Middleware assigns every request a variant, records its duration by build and variant and tells admins about both.
+// It has to come after auth.Middleware so authenticated users are bucketed by their ID.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := config.GetCanary()
+		build := Build(c)
+		variant := Variant(c, clientKey(r))
+		if c.Enabled {
+			metrics.AddObserver(r, metrics.LbrytvCanaryCallDurations.WithLabelValues(build, variant))
+		}
+		if auth.IsAdmin(r) {
+			w.Header().Set(BuildHeader, build)
+			w.Header().Set(VariantHeader, variant)
+		}
+		next.ServeHTTP(w, AddToRequest(r, variant))
+	})
+}
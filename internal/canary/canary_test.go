@@ -0,0 +1,132 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/app/wallet"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/ip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketIsConsistent(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("user:%v", i)
+		b := Bucket(key)
+		require.GreaterOrEqual(t, b, 0)
+		require.Less(t, b, 100)
+		require.Equal(t, b, Bucket(key), key)
+	}
+}
+
+func TestVariant(t *testing.T) {
+	c := config.Canary{Enabled: true, Percentage: 20}
+	canaries := map[string]bool{}
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("user:%v", i)
+		v := Variant(c, key)
+		assert.Equal(t, v, Variant(c, key))
+		if v == VariantCanary {
+			canaries[key] = true
+		}
+	}
+	assert.InDelta(t, 2000, len(canaries), 300)
+
+	// Raising the percentage only moves stable clients to canary
+	c.Percentage = 50
+	for key := range canaries {
+		assert.Equal(t, VariantCanary, Variant(c, key), key)
+	}
+
+	c.Percentage = 0
+	assert.Equal(t, VariantStable, Variant(c, "user:1"))
+	c.Percentage = 100
+	assert.Equal(t, VariantCanary, Variant(c, "user:1"))
+	c.Enabled = false
+	assert.Equal(t, VariantStable, Variant(c, "user:1"))
+}
+
+func TestMiddleware(t *testing.T) {
+	config.Override("Canary", map[string]interface{}{"Enabled": true, "Build": "v1.2-rc1", "Percentage": 50})
+	config.Override("AdminToken", "admintoken")
+	defer config.RestoreOverridden()
+
+	provider := func(token, ip string) (*models.User, error) {
+		if token == "" {
+			return nil, nil
+		}
+		var id int
+		fmt.Sscanf(token, "%d", &id)
+		return &models.User{ID: id}, nil
+	}
+	var variant string
+	handler := middleware.Apply(
+		middleware.Chain(metrics.MeasureMiddleware(), ip.Middleware, auth.Middleware(provider), Middleware),
+		func(w http.ResponseWriter, r *http.Request) { variant = FromRequest(r) },
+	)
+	call := func(userID int, admin bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if userID != 0 {
+			r.Header.Set(wallet.TokenHeader, fmt.Sprint(userID))
+		}
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admintoken")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	for id := 1; id < 50; id++ {
+		expected := Variant(config.GetCanary(), fmt.Sprintf("user:%v", id))
+		rr := call(id, false)
+		assert.Equal(t, expected, variant)
+		assert.Empty(t, rr.Header().Get(BuildHeader))
+		assert.Empty(t, rr.Header().Get(VariantHeader))
+
+		rr = call(id, true)
+		assert.Equal(t, expected, variant)
+		assert.Equal(t, "v1.2-rc1", rr.Header().Get(BuildHeader))
+		assert.Equal(t, expected, rr.Header().Get(VariantHeader))
+	}
+	// Durations of both variants are recorded
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.LbrytvCanaryCallDurations))
+
+	config.Override("Canary", map[string]interface{}{"Enabled": false})
+	for id := 1; id < 50; id++ {
+		call(id, false)
+		assert.Equal(t, VariantStable, variant)
+	}
+}
+
+func TestSDKServer(t *testing.T) {
+	c := config.Canary{Enabled: true, Percentage: 100, SDKServers: []string{"http://canary1:5279/", "http://canary2:5279/"}}
+	rt := sdkrouter.New(map[string]string{"a": "http://stable:5279/"})
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	assert.Equal(t, "", SDKServer(r, c, rt))
+
+	r = AddToRequest(r, VariantCanary)
+	picked := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		picked[SDKServer(r, c, rt)] = true
+	}
+	assert.Equal(t, map[string]bool{"http://canary1:5279/": true, "http://canary2:5279/": true}, picked)
+
+	rt.Quarantine("http://canary1:5279/")
+	assert.Equal(t, "http://canary2:5279/", SDKServer(r, c, rt))
+	rt.Quarantine("http://canary2:5279/")
+	assert.Equal(t, "", SDKServer(r, c, rt))
+	assert.Equal(t, "", SDKServer(r, config.Canary{Enabled: true, Percentage: 100}, rt))
+}
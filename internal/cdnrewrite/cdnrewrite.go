@@ -0,0 +1,145 @@
+// Package cdnrewrite points storage URLs in responses at CDN edges nearest to the client.
+//
+// Rewriting is done on the final response rather than in a postflight hook because responses
+// are shared between clients through the cache and some (like get) never reach postflight hooks.
+// Responses are never modified in place: only the parts leading to rewritten URLs are copied.
+package cdnrewrite
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/geoip"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// DefaultRegion is the region of clients from countries not listed in config.
+const DefaultRegion = "default"
+
+var logger = monitor.NewModuleLogger("cdnrewrite")
+
+var countryForIP = geoip.Country
+
+// Response returns res with storage URLs rewritten for the region of remoteIP according to CDNRewrite config.
+// res itself is returned when there's nothing to rewrite.
+func Response(res *jsonrpc.RPCResponse, remoteIP string) *jsonrpc.RPCResponse {
+	if res == nil || res.Error != nil || res.Result == nil {
+		return res
+	}
+	cfg := config.GetCDNRewrite()
+	if len(cfg.Hosts) == 0 || len(cfg.Fields) == 0 {
+		return res
+	}
+	hosts := HostsFor(cfg, Region(cfg, remoteIP))
+	if len(hosts) == 0 {
+		return res
+	}
+	fields := map[string]bool{}
+	for _, f := range cfg.Fields {
+		fields[f] = true
+	}
+	result, changed := Rewrite(res.Result, fields, hosts)
+	if !changed {
+		return res
+	}
+	rewritten := *res
+	rewritten.Result = result
+	return &rewritten
+}
+
+// Region returns the CDN region of the client, DefaultRegion if its country is unknown or not listed.
+func Region(cfg config.CDNRewrite, remoteIP string) string {
+	if len(cfg.Regions) == 0 {
+		return DefaultRegion
+	}
+	country, err := countryForIP(remoteIP)
+	if err != nil {
+		logger.Log().Debugf("cannot determine country of %v: %v", remoteIP, err)
+		return DefaultRegion
+	}
+	if r, ok := cfg.Regions[strings.ToLower(country)]; ok {
+		return r
+	}
+	return DefaultRegion
+}
+
+// HostsFor returns storage host to CDN host mapping for region, falling back to the default region.
+func HostsFor(cfg config.CDNRewrite, region string) map[string]string {
+	hosts := map[string]string{}
+	for storage, edges := range cfg.Hosts {
+		if h, ok := edges[region]; ok {
+			hosts[storage] = h
+		} else if h, ok := edges[DefaultRegion]; ok {
+			hosts[storage] = h
+		}
+	}
+	return hosts
+}
+
+// Rewrite replaces hosts of URLs found in fields anywhere in v according to hosts mapping.
+// v is not modified, the second value is true if anything was rewritten.
+func Rewrite(v interface{}, fields map[string]bool, hosts map[string]string) (interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		var out map[string]interface{}
+		for k, val := range t {
+			var nv interface{}
+			var changed bool
+			if s, ok := val.(string); ok {
+				if fields[k] {
+					nv, changed = rewriteURL(s, hosts)
+				}
+			} else {
+				nv, changed = Rewrite(val, fields, hosts)
+			}
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(t))
+				for k2, v2 := range t {
+					out[k2] = v2
+				}
+			}
+			out[k] = nv
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case []interface{}:
+		var out []interface{}
+		for i, val := range t {
+			nv, changed := Rewrite(val, fields, hosts)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make([]interface{}, len(t))
+				copy(out, t)
+			}
+			out[i] = nv
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	}
+	return v, false
+}
+
+func rewriteURL(s string, hosts map[string]string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s, false
+	}
+	h, ok := hosts[strings.ToLower(u.Host)]
+	if !ok {
+		return s, false
+	}
+	u.Host = h
+	return u.String(), true
+}
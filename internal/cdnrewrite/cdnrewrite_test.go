@@ -0,0 +1,97 @@
+package cdnrewrite
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/geoip"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybbus/jsonrpc"
+)
+
+func setupConfig(t *testing.T) {
+	countryForIP = func(ip string) (string, error) {
+		switch ip {
+		case "1.1.1.1":
+			return "DE", nil
+		case "2.2.2.2":
+			return "JP", nil
+		}
+		return "", geoip.ErrUnresolved
+	}
+	config.Override("CDNRewrite", map[string]interface{}{
+		"Fields":  []string{"streaming_url", "thumbnail_url"},
+		"Regions": map[string]string{"DE": "eu"},
+		"Hosts": map[string]interface{}{
+			"player.storage.com": map[string]string{"eu": "eu.cdn.com", "default": "us.cdn.com"},
+			"thumbs.storage.com": map[string]string{"eu": "eu.thumbs.cdn.com"},
+		},
+	})
+	t.Cleanup(func() {
+		countryForIP = geoip.Country
+		config.RestoreOverridden()
+	})
+}
+
+func TestResponse(t *testing.T) {
+	setupConfig(t)
+
+	result := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"name":      "one",
+				"permanent": "https://player.storage.com/keep",
+				"value": map[string]interface{}{
+					"thumbnail_url": "https://thumbs.storage.com/1.jpg",
+				},
+			},
+			map[string]interface{}{
+				"name":          "two",
+				"streaming_url": "https://player.storage.com/v6/streams/abc?x=1",
+			},
+			map[string]interface{}{
+				"name":          "three",
+				"streaming_url": "https://elsewhere.com/abc",
+			},
+			map[string]interface{}{"name": "four"},
+		},
+	}
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: result}
+
+	eu := Response(res, "1.1.1.1")
+	items := eu.Result.(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, "https://eu.thumbs.cdn.com/1.jpg", items[0].(map[string]interface{})["value"].(map[string]interface{})["thumbnail_url"])
+	assert.Equal(t, "https://player.storage.com/keep", items[0].(map[string]interface{})["permanent"])
+	assert.Equal(t, "https://eu.cdn.com/v6/streams/abc?x=1", items[1].(map[string]interface{})["streaming_url"])
+	assert.Equal(t, "https://elsewhere.com/abc", items[2].(map[string]interface{})["streaming_url"])
+	assert.Equal(t, map[string]interface{}{"name": "four"}, items[3])
+
+	// Other regions get default hosts, storage hosts without a default are left as is
+	other := Response(res, "2.2.2.2")
+	items = other.Result.(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, "https://thumbs.storage.com/1.jpg", items[0].(map[string]interface{})["value"].(map[string]interface{})["thumbnail_url"])
+	assert.Equal(t, "https://us.cdn.com/v6/streams/abc?x=1", items[1].(map[string]interface{})["streaming_url"])
+	assert.Equal(t, "https://us.cdn.com/v6/streams/abc?x=1",
+		Response(res, "3.3.3.3").Result.(map[string]interface{})["items"].([]interface{})[1].(map[string]interface{})["streaming_url"])
+
+	// The original response is shared through the cache and must stay intact
+	items = res.Result.(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, "https://thumbs.storage.com/1.jpg", items[0].(map[string]interface{})["value"].(map[string]interface{})["thumbnail_url"])
+	assert.Equal(t, "https://player.storage.com/v6/streams/abc?x=1", items[1].(map[string]interface{})["streaming_url"])
+}
+
+func TestResponse_Unchanged(t *testing.T) {
+	setupConfig(t)
+
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"streaming_url": "https://elsewhere.com/abc"}}
+	assert.Same(t, res, Response(res, "1.1.1.1"))
+
+	errRes := &jsonrpc.RPCResponse{JSONRPC: "2.0", Error: &jsonrpc.RPCError{Code: -32000}}
+	assert.Same(t, errRes, Response(errRes, "1.1.1.1"))
+	assert.Nil(t, Response(nil, "1.1.1.1"))
+
+	config.Override("CDNRewrite", map[string]interface{}{"Fields": []string{"streaming_url"}})
+	res = &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"streaming_url": "https://player.storage.com/abc"}}
+	assert.Same(t, res, Response(res, "1.1.1.1"))
+}
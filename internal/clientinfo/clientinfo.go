@@ -0,0 +1,147 @@
+// Package clientinfo identifies client applications making API requests
+// by their self-reported name and version, e.g. "odysee-web/1.4.2".
+package clientinfo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// Unknown is used as both app name and version for clients which cannot be identified.
+const Unknown = "unknown"
+
+// OtherVersion is the version bucket of clients with versions not listed in KnownClientVersions config setting.
+const OtherVersion = "other"
+
+type ctxKey int
+
+const contextKey ctxKey = iota
+
+// Info contains client application name and version.
+type Info struct {
+	App     string
+	Version string
+}
+
+// UnknownClient is returned for requests without a recognized client identity.
+var UnknownClient = Info{App: Unknown, Version: Unknown}
+
+// Parse extracts client app and version from a header value in the "app/version" format,
+// optionally followed by other space-separated tokens, as in User-Agent.
+// Apps not listed in KnownClientApps config setting are reported as unknown.
+func Parse(value string) Info {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return UnknownClient
+	}
+	parts := strings.SplitN(fields[0], "/", 2)
+	app := strings.ToLower(parts[0])
+	if !isKnownApp(app) {
+		return UnknownClient
+	}
+	info := Info{App: app, Version: Unknown}
+	if len(parts) == 2 {
+		if v := strings.TrimPrefix(strings.ToLower(parts[1]), "v"); v != "" {
+			info.Version = v
+		}
+	}
+	return info
+}
+
+func isKnownApp(app string) bool {
+	for _, a := range config.GetKnownClientApps() {
+		if strings.ToLower(a) == app {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionBucket returns major.minor part of the client version,
+// which is suitable for using as a metrics label.
+// Versions not listed in KnownClientVersions config setting are reported as OtherVersion.
+func (i Info) VersionBucket() string {
+	v := versionParts(i.Version)
+	if v == nil {
+		return Unknown
+	}
+	if len(v) == 1 {
+		v = append(v, 0)
+	}
+	bucket := strconv.Itoa(v[0]) + "." + strconv.Itoa(v[1])
+	for _, known := range config.GetKnownClientVersions() {
+		if known == bucket {
+			return bucket
+		}
+	}
+	return OtherVersion
+}
+
+// AtLeast checks if client version is greater than or equal to the supplied one.
+// Unknown versions are always considered older.
+func (i Info) AtLeast(version string) bool {
+	cv := versionParts(i.Version)
+	rv := versionParts(version)
+	if cv == nil || rv == nil {
+		return false
+	}
+	for n := 0; n < len(rv); n++ {
+		var c int
+		if n < len(cv) {
+			c = cv[n]
+		}
+		if c != rv[n] {
+			return c > rv[n]
+		}
+	}
+	return true
+}
+
+// versionParts parses numeric dot-separated version components,
+// stopping at the first non-numeric one (like "-beta" suffix).
+func versionParts(version string) []int {
+	var parts []int
+	for _, p := range strings.Split(version, ".") {
+		end := 0
+		for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, err := strconv.Atoi(p[:end])
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+		if end < len(p) {
+			break
+		}
+	}
+	return parts
+}
+
+// FromRequest returns client info attached to the request by Middleware or AddToRequest.
+// If it's not there, client info is parsed from the request headers.
+func FromRequest(r *http.Request) Info {
+	if v, ok := r.Context().Value(contextKey).(Info); ok {
+		return v
+	}
+	return Parse(r.Header.Get(config.GetClientIdentityHeader()))
+}
+
+// AddToRequest returns a copy of the request with client info attached.
+func AddToRequest(r *http.Request, info Info) *http.Request {
+	return r.Clone(context.WithValue(r.Context(), contextKey, info))
+}
+
+// Middleware attaches client info parsed from request headers to every request.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, AddToRequest(r, FromRequest(r)))
+	})
+}
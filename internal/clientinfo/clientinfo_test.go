@@ -0,0 +1,80 @@
+package clientinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	config.Override("KnownClientVersions", []string{"1.4", "0.9", "4.0"})
+	defer config.RestoreOverridden()
+
+	cases := []struct {
+		value, app, version, bucket string
+	}{
+		{"odysee-web/1.4.2", "odysee-web", "1.4.2", "1.4"},
+		{"Odysee-Android/v0.9.12-beta (Linux; Android 11)", "odysee-android", "0.9.12-beta", "0.9"},
+		{"okhttp/4", "okhttp", "4", "4.0"},
+		{"odysee-web/1.5.0", "odysee-web", "1.5.0", OtherVersion},
+		{"odysee-web/1844674407.99999", "odysee-web", "1844674407.99999", OtherVersion},
+		{"lbry-desktop", "lbry-desktop", Unknown, Unknown},
+		{"lbry-desktop/nightly", "lbry-desktop", "nightly", Unknown},
+		{"Mozilla/5.0 (X11; Linux x86_64)", Unknown, Unknown, Unknown},
+		{"", Unknown, Unknown, Unknown},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			info := Parse(c.value)
+			assert.Equal(t, c.app, info.App)
+			assert.Equal(t, c.version, info.Version)
+			assert.Equal(t, c.bucket, info.VersionBucket())
+		})
+	}
+}
+
+func TestParseKnownAppsConfig(t *testing.T) {
+	config.Override("KnownClientApps", []string{"my-app"})
+	defer config.RestoreOverridden()
+
+	assert.Equal(t, Info{App: "my-app", Version: "2.0"}, Parse("my-app/2.0"))
+	assert.Equal(t, UnknownClient, Parse("odysee-web/1.4.2"))
+}
+
+func TestAtLeast(t *testing.T) {
+	info := Info{App: "odysee-web", Version: "1.4.2"}
+	assert.True(t, info.AtLeast("1.4.2"))
+	assert.True(t, info.AtLeast("1.4"))
+	assert.True(t, info.AtLeast("1.3.9"))
+	assert.True(t, info.AtLeast("0.99"))
+	assert.False(t, info.AtLeast("1.4.3"))
+	assert.False(t, info.AtLeast("1.10"))
+	assert.False(t, info.AtLeast("2"))
+	assert.False(t, info.AtLeast("garbage"))
+
+	assert.True(t, Info{Version: "2"}.AtLeast("1.9.9"))
+	assert.False(t, UnknownClient.AtLeast("0.0.1"))
+}
+
+func TestMiddleware(t *testing.T) {
+	config.Override("ClientIdentityHeader", "X-Client")
+	defer config.RestoreOverridden()
+
+	var info Info
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info = FromRequest(r)
+	}))
+
+	r, err := http.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Client", "odysee-ios/3.1.0")
+	r.Header.Set("User-Agent", "lbry-desktop/1.0.0")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, Info{App: "odysee-ios", Version: "3.1.0"}, info)
+}
@@ -0,0 +1,237 @@
+// Package entitlements tells which paid features or tiers users are entitled to, so methods can be gated by them.
+// Entitlements come from a Provider: the database by default, or an external entitlements service.
+package entitlements
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/ybbus/jsonrpc"
+)
+
+const hookName = "entitlements"
+
+// Entitlement sources, see Entitlements config.
+const (
+	SourceDB   = "db"
+	SourceHTTP = "http"
+)
+
+var logger = monitor.NewModuleLogger("entitlements")
+
+// Provider returns entitlements of a user.
+type Provider interface {
+	Entitlements(userID int) ([]string, error)
+}
+
+var (
+	providerMu sync.RWMutex
+	provider   Provider
+)
+
+// SetProvider makes p the source of entitlements for gated methods.
+func SetProvider(p Provider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	provider = p
+}
+
+func getProvider() Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return provider
+}
+
+// NewProvider creates a provider according to Entitlements config. Entitlements fetched over HTTP are cached.
+func NewProvider(cfg config.Entitlements, db boil.Executor) (Provider, error) {
+	switch cfg.Source {
+	case SourceDB, "":
+		return NewDBProvider(db), nil
+	case SourceHTTP:
+		if cfg.URL == "" {
+			return nil, errors.Err("entitlements service url is not set")
+		}
+		return NewCachedProvider(NewHTTPProvider(cfg.URL, cfg.Timeout), cfg.CacheTTL), nil
+	}
+	return nil, errors.Err("unknown entitlements source %v", cfg.Source)
+}
+
+// DBProvider reads entitlements from the user_entitlements table.
+type DBProvider struct {
+	db boil.Executor
+}
+
+// NewDBProvider creates a provider reading entitlements from the database.
+func NewDBProvider(db boil.Executor) *DBProvider {
+	return &DBProvider{db: db}
+}
+
+func (p *DBProvider) Entitlements(userID int) ([]string, error) {
+	ents, err := models.UserEntitlements(models.UserEntitlementWhere.UserID.EQ(userID)).All(p.db)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	var list []string
+	for _, e := range ents {
+		list = append(list, e.Entitlement)
+	}
+	return list, nil
+}
+
+// HTTPProvider fetches entitlements from an external service, which is expected to respond to
+// GET <url>?user_id=<id> with {"entitlements": ["premium", ...]}, or 404 for users it doesn't know.
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPProvider creates a provider fetching entitlements from the service at serviceURL.
+func NewHTTPProvider(serviceURL string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{url: serviceURL, client: &http.Client{Timeout: timeout}}
+}
+
+func (p *HTTPProvider) Entitlements(userID int) ([]string, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	q := u.Query()
+	q.Set("user_id", strconv.Itoa(userID))
+	u.RawQuery = q.Encode()
+
+	op := metrics.StartOperation("entitlements", "fetch")
+	defer op.End()
+	r, err := p.client.Get(u.String())
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	defer r.Body.Close()
+	if r.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, errors.Err("entitlements service responded with %v", r.Status)
+	}
+	var body struct {
+		Entitlements []string `json:"entitlements"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, errors.Err(err)
+	}
+	return body.Entitlements, nil
+}
+
+type cachedEntry struct {
+	list    []string
+	expires time.Time
+}
+
+// CachedProvider keeps entitlements returned by another provider for a while. Failures are not cached.
+type CachedProvider struct {
+	p   Provider
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[int]cachedEntry
+}
+
+// NewCachedProvider caches entitlements returned by p for ttl.
+func NewCachedProvider(p Provider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{p: p, ttl: ttl, now: time.Now, entries: map[int]cachedEntry{}}
+}
+
+func (c *CachedProvider) Entitlements(userID int) ([]string, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		metrics.EntitlementsCacheHits.Inc()
+		return e.list, nil
+	}
+
+	metrics.EntitlementsCacheMisses.Inc()
+	list, err := c.p.Entitlements(userID)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[userID] = cachedEntry{list: list, expires: now.Add(c.ttl)}
+	for id, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, id)
+		}
+	}
+	return list, nil
+}
+
+// IsEntitled returns true if the user has the entitlement. When entitlements cannot be fetched,
+// Entitlements.FailOpen config decides. Anonymous users are never entitled.
+func IsEntitled(userID int, entitlement string) bool {
+	if userID == 0 {
+		return false
+	}
+	p := getProvider()
+	if p == nil {
+		return config.GetEntitlements().FailOpen
+	}
+	list, err := p.Entitlements(userID)
+	if err != nil {
+		failOpen := config.GetEntitlements().FailOpen
+		metrics.EntitlementsFetchFailures.Inc()
+		logger.WithFields(logrus.Fields{"user_id": userID, "fail_open": failOpen}).Errorf("cannot fetch entitlements: %v", err)
+		return failOpen
+	}
+	for _, e := range list {
+		if e == entitlement {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallHook makes the caller reject queries for methods which require an entitlement (see MethodEntitlements config)
+// the user doesn't have. The hook runs before builtin ones so their early responses are gated too.
+func InstallHook(c *query.Caller, userID int) {
+	c.PrependPreflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		method := hctx.Query.Method()
+		entitlement, ok := config.GetMethodEntitlements()[method]
+		if !ok || entitlement == "" {
+			return nil, nil
+		}
+		if userID == 0 {
+			return errorResponse(hctx.Query, rpcerrors.NewAuthRequiredError()), nil
+		}
+		if IsEntitled(userID, entitlement) {
+			return nil, nil
+		}
+		metrics.ProxyEntitlementDenied.WithLabelValues(method).Inc()
+		logger.WithFields(logrus.Fields{"method": method, "user_id": userID}).Info("query rejected for lack of entitlement")
+		return errorResponse(hctx.Query, rpcerrors.NewForbiddenError(fmt.Errorf("%v requires %v entitlement", method, entitlement))), nil
+	}, hookName)
+}
+
+func errorResponse(q *query.Query, err rpcerrors.RPCError) *jsonrpc.RPCResponse {
+	return &jsonrpc.RPCResponse{
+		JSONRPC: q.Request.JSONRPC,
+		ID:      q.Request.ID,
+		Error:   &jsonrpc.RPCError{Code: err.Code(), Message: err.Error()},
+	}
+}
@@ -0,0 +1,152 @@
+package entitlements
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+type fakeProvider struct {
+	calls int
+	list  map[int][]string
+	err   error
+}
+
+func (p *fakeProvider) Entitlements(userID int) ([]string, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.list[userID], nil
+}
+
+func TestCachedProvider(t *testing.T) {
+	fp := &fakeProvider{list: map[int][]string{1: {"premium"}}}
+	now := time.Now()
+	cp := NewCachedProvider(fp, 30*time.Second)
+	cp.now = func() time.Time { return now }
+
+	hits := metrics.GetCounterValue(metrics.EntitlementsCacheHits)
+	for i := 0; i < 3; i++ {
+		list, err := cp.Entitlements(1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"premium"}, list)
+	}
+	assert.Equal(t, 1, fp.calls)
+	assert.Equal(t, hits+2, metrics.GetCounterValue(metrics.EntitlementsCacheHits))
+
+	fp.list[1] = []string{"premium", "creator"}
+	now = now.Add(31 * time.Second)
+	list, err := cp.Entitlements(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"premium", "creator"}, list)
+	assert.Equal(t, 2, fp.calls)
+
+	// Failures are not cached
+	fp.err = errors.New("service down")
+	_, err = cp.Entitlements(2)
+	require.Error(t, err)
+	_, err = cp.Entitlements(2)
+	require.Error(t, err)
+	assert.Equal(t, 4, fp.calls)
+
+	fp.err = nil
+	list, err = cp.Entitlements(2)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	assert.Equal(t, 5, fp.calls)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("user_id") {
+		case "1":
+			fmt.Fprint(w, `{"entitlements": ["premium"]}`)
+		case "2":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	p := NewHTTPProvider(ts.URL+"/entitlements?key=abc", time.Second)
+	list, err := p.Entitlements(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"premium"}, list)
+
+	list, err = p.Entitlements(2)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = p.Entitlements(3)
+	assert.EqualError(t, err, "entitlements service responded with 500 Internal Server Error")
+}
+
+func TestIsEntitledFetchFailure(t *testing.T) {
+	fp := &fakeProvider{err: errors.New("service down")}
+	SetProvider(fp)
+	defer SetProvider(nil)
+	defer config.RestoreOverridden()
+
+	failures := metrics.GetCounterValue(metrics.EntitlementsFetchFailures)
+	config.Override("Entitlements", map[string]interface{}{"FailOpen": false})
+	assert.False(t, IsEntitled(1, "premium"))
+	config.Override("Entitlements", map[string]interface{}{"FailOpen": true})
+	assert.True(t, IsEntitled(1, "premium"))
+	assert.Equal(t, failures+2, metrics.GetCounterValue(metrics.EntitlementsFetchFailures))
+
+	// Anonymous users are never let through
+	assert.False(t, IsEntitled(0, "premium"))
+	assert.Equal(t, 2, fp.calls)
+}
+
+func TestInstallHook(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	SetProvider(&fakeProvider{list: map[int][]string{1: {"premium"}}})
+	defer SetProvider(nil)
+	config.Override("MethodEntitlements", map[string]string{"status": "premium"})
+	defer config.RestoreOverridden()
+
+	denied := metrics.GetCounterValue(metrics.ProxyEntitlementDenied.WithLabelValues("status"))
+
+	c := query.NewCaller(srv.URL, 1)
+	InstallHook(c, 1)
+	res, err := c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+
+	c = query.NewCaller(srv.URL, 2)
+	InstallHook(c, 2)
+	res, err = c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "status requires premium entitlement", res.Error.Message)
+	assert.Equal(t, denied+1, metrics.GetCounterValue(metrics.ProxyEntitlementDenied.WithLabelValues("status")))
+
+	c = query.NewCaller(srv.URL, 0)
+	InstallHook(c, 0)
+	res, err = c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, denied+1, metrics.GetCounterValue(metrics.ProxyEntitlementDenied.WithLabelValues("status")))
+
+	config.Override("MethodEntitlements", map[string]string{})
+	res, err = c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+}
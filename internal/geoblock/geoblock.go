@@ -0,0 +1,102 @@
+package geoblock
+
+import (
+	"strings"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/geoip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	hookName = "geoblock"
+
+	// Country labels for clients whose country cannot be determined.
+	countryPrivate = "private"
+	countryUnknown = "unknown"
+)
+
+var logger = monitor.NewModuleLogger("geoblock")
+
+var (
+	countryForIP = geoip.Country
+	logQuery     = audit.LogQuery
+)
+
+// InstallHook makes the caller reject queries for methods which are not available in the client's country
+// according to GeoBlockRules config. Rejected queries are recorded in the query log.
+func InstallHook(c *query.Caller, remoteIP string, userID int, body []byte) {
+	c.PrependPreflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		method := hctx.Query.Method()
+		rule, ok := config.GetGeoBlockRules()[method]
+		if !ok {
+			return nil, nil
+		}
+		country, known := resolveCountry(remoteIP)
+		if IsAllowed(rule, country, known, config.ShouldGeoBlockUnknown()) {
+			return nil, nil
+		}
+
+		metrics.ProxyGeoBlocked.WithLabelValues(method, country).Inc()
+		logger.WithFields(logrus.Fields{
+			"method":    method,
+			"country":   country,
+			"remote_ip": remoteIP,
+			"user_id":   userID,
+		}).Info("query rejected by geoblocking")
+		logQuery(userID, remoteIP, method, body, audit.OutcomeBlocked)
+
+		return &jsonrpc.RPCResponse{
+			JSONRPC: hctx.Query.Request.JSONRPC,
+			ID:      hctx.Query.Request.ID,
+			Error: &jsonrpc.RPCError{
+				Code:    rpcerrors.NewGeoRestrictedError().Code(),
+				Message: rpcerrors.ErrGeoRestricted.Error(),
+			},
+		}, nil
+	}, hookName)
+}
+
+// IsAllowed returns true if the rule lets through clients from country.
+// When the country is not known, denyUnknown decides.
+func IsAllowed(rule config.GeoBlockRule, country string, known, denyUnknown bool) bool {
+	if !known {
+		return !denyUnknown
+	}
+	if inList(country, rule.Deny) {
+		return false
+	}
+	return len(rule.Allow) == 0 || inList(country, rule.Allow)
+}
+
+// resolveCountry returns the client country code, or a label explaining why it's not known.
+func resolveCountry(remoteIP string) (string, bool) {
+	country, err := countryForIP(remoteIP)
+	if err == nil {
+		return country, true
+	}
+	if errors.Is(err, geoip.ErrPrivate) {
+		return countryPrivate, false
+	}
+	if !errors.Is(err, geoip.ErrUnresolved) {
+		logger.Log().Warnf("cannot determine country of %v: %v", remoteIP, err)
+	}
+	return countryUnknown, false
+}
+
+func inList(country string, list []string) bool {
+	for _, c := range list {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package geoblock
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/audit"
+	"github.com/lbryio/lbrytv/internal/geoip"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestIsAllowed(t *testing.T) {
+	deny := config.GeoBlockRule{Deny: []string{"XX", "yy"}}
+	allow := config.GeoBlockRule{Allow: []string{"US"}}
+
+	assert.False(t, IsAllowed(deny, "XX", true, false))
+	assert.False(t, IsAllowed(deny, "YY", true, false))
+	assert.True(t, IsAllowed(deny, "US", true, false))
+	assert.True(t, IsAllowed(allow, "US", true, false))
+	assert.False(t, IsAllowed(allow, "XX", true, false))
+
+	assert.True(t, IsAllowed(deny, countryUnknown, false, false))
+	assert.True(t, IsAllowed(allow, countryPrivate, false, false))
+	assert.False(t, IsAllowed(deny, countryUnknown, false, true))
+	assert.False(t, IsAllowed(allow, countryPrivate, false, true))
+}
+
+func TestInstallHook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {}, "id": 0}`)
+	}))
+	defer srv.Close()
+
+	countries := map[string]string{"1.1.1.1": "XX", "2.2.2.2": "US"}
+	countryForIP = func(ip string) (string, error) {
+		if c, ok := countries[ip]; ok {
+			return c, nil
+		}
+		if ip == "10.0.0.1" {
+			return "", geoip.ErrPrivate
+		}
+		return "", geoip.ErrUnresolved
+	}
+	var logged []string
+	logQuery = func(userID int, remoteIP string, method string, body []byte, outcome string) *models.QueryLog {
+		logged = append(logged, remoteIP+" "+method+" "+outcome)
+		return nil
+	}
+	defer func() {
+		countryForIP = geoip.Country
+		logQuery = audit.LogQuery
+	}()
+
+	config.Override("GeoBlockRules", map[string]interface{}{
+		query.MethodClaimSearch: map[string]interface{}{"deny": []string{"XX"}},
+	})
+	defer config.RestoreOverridden()
+
+	call := func(ip, method string) *jsonrpc.RPCResponse {
+		c := query.NewCaller(srv.URL, 0)
+		InstallHook(c, ip, 1, []byte(`{}`))
+		res, err := c.Call(jsonrpc.NewRequest(method))
+		require.NoError(t, err)
+		return res
+	}
+
+	blocked := metrics.GetCounterValue(metrics.ProxyGeoBlocked.WithLabelValues(query.MethodClaimSearch, "XX"))
+	res := call("1.1.1.1", query.MethodClaimSearch)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32095, res.Error.Code)
+	assert.Equal(t, blocked+1, metrics.GetCounterValue(metrics.ProxyGeoBlocked.WithLabelValues(query.MethodClaimSearch, "XX")))
+	assert.Equal(t, []string{"1.1.1.1 claim_search blocked"}, logged)
+
+	assert.Nil(t, call("1.1.1.1", query.MethodStatus).Error)
+	assert.Nil(t, call("2.2.2.2", query.MethodClaimSearch).Error)
+	assert.Nil(t, call("10.0.0.1", query.MethodClaimSearch).Error)
+
+	privateBlocked := metrics.GetCounterValue(metrics.ProxyGeoBlocked.WithLabelValues(query.MethodClaimSearch, countryPrivate))
+	config.Override("GeoBlockUnknown", "deny")
+	res = call("10.0.0.1", query.MethodClaimSearch)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32095, res.Error.Code)
+	assert.Equal(t, privateBlocked+1, metrics.GetCounterValue(metrics.ProxyGeoBlocked.WithLabelValues(query.MethodClaimSearch, countryPrivate)))
+	assert.Len(t, logged, 2)
+}
@@ -0,0 +1,66 @@
+package geoip
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/ip"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+var (
+	// ErrNotOpen is returned by lookups made before a GeoIP database has been opened.
+	ErrNotOpen = errors.Base("geoip database is not open")
+	// ErrPrivate is returned for addresses which belong to private or loopback networks.
+	ErrPrivate = errors.Base("address is private")
+	// ErrUnresolved is returned for addresses which cannot be parsed or have no country in the database.
+	ErrUnresolved = errors.Base("address country is unknown")
+)
+
+var (
+	mu    sync.RWMutex
+	geodb *geoip2.Reader
+)
+
+// Open loads the GeoIP (MaxMind country or city) database which lookups are made against.
+func Open(file string) error {
+	db, err := geoip2.Open(file)
+	if err != nil {
+		return errors.Err(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if geodb != nil {
+		geodb.Close()
+	}
+	geodb = db
+	return nil
+}
+
+// Country returns the upper-case ISO code of the country the address is located in.
+func Country(address string) (string, error) {
+	parsed := net.ParseIP(address)
+	if parsed == nil {
+		return "", ErrUnresolved
+	}
+	if parsed.IsLoopback() || ip.IsPrivateSubnet(parsed) {
+		return "", ErrPrivate
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if geodb == nil {
+		return "", ErrNotOpen
+	}
+	record, err := geodb.Country(parsed)
+	if err != nil {
+		return "", errors.Err(err)
+	}
+	if record.Country.IsoCode == "" {
+		return "", ErrUnresolved
+	}
+	return strings.ToUpper(record.Country.IsoCode), nil
+}
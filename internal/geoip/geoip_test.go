@@ -0,0 +1,24 @@
+package geoip
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountryWithoutDatabase(t *testing.T) {
+	for ip, expected := range map[string]error{
+		"":            ErrUnresolved,
+		"not-an-ip":   ErrUnresolved,
+		"127.0.0.1":   ErrPrivate,
+		"10.1.1.1":    ErrPrivate,
+		"192.168.0.1": ErrPrivate,
+		"8.8.8.8":     ErrNotOpen,
+	} {
+		c, err := Country(ip)
+		assert.Empty(t, c)
+		assert.True(t, errors.Is(err, expected), "%v: %v", ip, err)
+	}
+}
@@ -0,0 +1,135 @@
+package killswitch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ybbus/jsonrpc"
+)
+
+const (
+	hookName = "killswitch"
+
+	authAnonymous     = "anonymous"
+	authAuthenticated = "authenticated"
+
+	defaultMessage = "method is temporarily disabled, please try again later"
+)
+
+var logger = monitor.NewModuleLogger("killswitch")
+
+// InstallHook makes the caller reject queries matching deny rules from KillSwitchRules config.
+// The hook runs before all others so builtin early responses can be switched off too.
+// origin is the client origin as determined by the proxy, used for matching rules by origin.
+func InstallHook(c *query.Caller, origin string) {
+	c.PrependPreflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		rule := Evaluate(config.GetKillSwitchRules(), hctx.Query, origin)
+		if rule == nil || rule.Allow {
+			return nil, nil
+		}
+
+		metrics.ProxyKillSwitchHits.WithLabelValues(rule.Name).Inc()
+		logger.WithFields(logrus.Fields{
+			"rule":   rule.Name,
+			"method": hctx.Query.Method(),
+			"origin": origin,
+		}).Info("query rejected by kill switch")
+
+		msg := rule.Message
+		if msg == "" {
+			msg = defaultMessage
+		}
+		return &jsonrpc.RPCResponse{
+			JSONRPC: hctx.Query.Request.JSONRPC,
+			ID:      hctx.Query.Request.ID,
+			Error: &jsonrpc.RPCError{
+				Code:    rpcerrors.NewMethodDisabledError(nil).Code(),
+				Message: msg,
+			},
+		}, nil
+	}, hookName)
+}
+
+// Evaluate returns the first rule matching the query or nil if none do.
+func Evaluate(rules []config.KillSwitchRule, q *query.Query, origin string) *config.KillSwitchRule {
+	for i := range rules {
+		if matches(rules[i], q, origin) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func matches(rule config.KillSwitchRule, q *query.Query, origin string) bool {
+	if rule.Method != "" && rule.Method != q.Method() {
+		return false
+	}
+	switch rule.Auth {
+	case authAnonymous:
+		if q.IsAuthenticated() {
+			return false
+		}
+	case authAuthenticated:
+		if !q.IsAuthenticated() {
+			return false
+		}
+	}
+	if len(rule.Origins) > 0 && !inList(origin, rule.Origins) {
+		return false
+	}
+	if len(rule.Params) > 0 {
+		params := q.ParamsAsMap()
+		for name, threshold := range rule.Params {
+			v, ok := paramSize(params[name])
+			if !ok || v <= threshold {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// paramSize returns param numeric value, or its length for strings and collections.
+func paramSize(v interface{}) (float64, bool) {
+	switch p := v.(type) {
+	case nil:
+		return 0, false
+	case json.Number:
+		f, err := p.Float64()
+		return f, err == nil
+	case float64:
+		return p, true
+	case int:
+		return float64(p), true
+	case int64:
+		return float64(p), true
+	case string:
+		if f, err := strconv.ParseFloat(p, 64); err == nil {
+			return f, true
+		}
+		return float64(len(p)), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), true
+	}
+	return 0, false
+}
+
+func inList(v string, list []string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
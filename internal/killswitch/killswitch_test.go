@@ -0,0 +1,91 @@
+package killswitch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func newQuery(t *testing.T, method string, params interface{}, walletID string) *query.Query {
+	q, err := query.NewQuery(jsonrpc.NewRequest(method, params), walletID)
+	require.NoError(t, err)
+	return q
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := []config.KillSwitchRule{
+		{Name: "allow-ios", Method: query.MethodClaimSearch, Origins: []string{"ios"}, Allow: true},
+		{Name: "big-anon-search", Method: query.MethodClaimSearch, Auth: "anonymous", Params: map[string]float64{"page_size": 50}},
+		{Name: "many-urls", Method: query.MethodResolve, Params: map[string]float64{"urls": 2}},
+		{Name: "authed-balance", Method: query.MethodWalletBalance, Auth: "authenticated"},
+	}
+
+	cases := []struct {
+		name     string
+		query    *query.Query
+		origin   string
+		expected string
+	}{
+		{"big anonymous search", newQuery(t, query.MethodClaimSearch, map[string]interface{}{"page_size": json.Number("100")}, ""), "odysee", "big-anon-search"},
+		{"big anonymous search from ios", newQuery(t, query.MethodClaimSearch, map[string]interface{}{"page_size": 100}, ""), "ios", "allow-ios"},
+		{"small anonymous search", newQuery(t, query.MethodClaimSearch, map[string]interface{}{"page_size": 20}, ""), "odysee", ""},
+		{"search without page size", newQuery(t, query.MethodClaimSearch, map[string]interface{}{}, ""), "odysee", ""},
+		{"big authenticated search", newQuery(t, query.MethodClaimSearch, map[string]interface{}{"page_size": 100.0}, "lbrytv-id.1.wallet"), "odysee", ""},
+		{"resolve with many urls", newQuery(t, query.MethodResolve, map[string]interface{}{"urls": []interface{}{"a", "b", "c"}}, ""), "", "many-urls"},
+		{"resolve with few urls", newQuery(t, query.MethodResolve, map[string]interface{}{"urls": []interface{}{"a", "b"}}, ""), "", ""},
+		{"authenticated balance", newQuery(t, query.MethodWalletBalance, nil, "lbrytv-id.1.wallet"), "", "authed-balance"},
+		{"other method", newQuery(t, query.MethodStatus, nil, ""), "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := Evaluate(rules, c.query, c.origin)
+			if c.expected == "" {
+				assert.Nil(t, r)
+			} else {
+				require.NotNil(t, r)
+				assert.Equal(t, c.expected, r.Name)
+			}
+		})
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	srv := test.MockHTTPServer(nil)
+	defer srv.Close()
+
+	config.Override("KillSwitchRules", []map[string]interface{}{
+		{"name": "no-get", "method": "get", "message": "downloads are disabled"},
+		{"name": "no-status-for-android", "method": "status", "origins": []string{"android"}},
+	})
+	defer config.RestoreOverridden()
+
+	hits := metrics.GetCounterValue(metrics.ProxyKillSwitchHits.WithLabelValues("no-get"))
+
+	c := query.NewCaller(srv.URL, 0)
+	InstallHook(c, "odysee")
+	res, err := c.Call(jsonrpc.NewRequest("get", map[string]interface{}{"uri": "what"}))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "downloads are disabled", res.Error.Message)
+	assert.Equal(t, -32091, res.Error.Code)
+	assert.Equal(t, hits+1, metrics.GetCounterValue(metrics.ProxyKillSwitchHits.WithLabelValues("no-get")))
+
+	res, err = c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+
+	c = query.NewCaller(srv.URL, 0)
+	InstallHook(c, "android")
+	res, err = c.Call(jsonrpc.NewRequest("status"))
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, defaultMessage, res.Error.Message)
+}
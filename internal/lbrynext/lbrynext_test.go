@@ -69,7 +69,7 @@ func Test_experimentNewSdkParam_ResponseMatch(t *testing.T) {
 
 	c.AddPostflightHook(query.MethodResolve, experimentNewSdkParam, resolveHookName)
 
-	request := jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"})
+	request := jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "lbry://what"})
 	expectedRequest := test.ReqToStr(t, request)
 	resp, err := c.Call(request)
 	require.NoError(t, err)
@@ -83,7 +83,7 @@ func Test_experimentNewSdkParam_ResponseMatch(t *testing.T) {
 	assert.EqualValues(t, expectedRequest, receivedRequest.Body)
 
 	receivedRequestX := <-reqChan
-	expectedRequestX := test.ReqToStr(t, jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what", "new_sdk_server": "http://localhost"}))
+	expectedRequestX := test.ReqToStr(t, jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "lbry://what", "new_sdk_server": "http://localhost"}))
 	assert.EqualValues(t, expectedRequestX, receivedRequestX.Body)
 
 	entry = hook.LastEntry()
@@ -113,7 +113,7 @@ func Test_experimentParallel_ResponseMatch(t *testing.T) {
 
 	c.AddPostflightHook(query.MethodResolve, experimentParallel, resolveHookName)
 
-	request := jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "what"})
+	request := jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "lbry://what"})
 	expectedRequest := test.ReqToStr(t, request)
 	c.Call(request)
 
@@ -0,0 +1,161 @@
+// Package locale picks the language of messages sent to clients from their Accept-Language header
+// and translates messages using tables loaded from a file.
+package locale
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+)
+
+// Default is the locale messages are written in, it's used when none of the client's locales is available.
+const Default = "en"
+
+type ctxKey int
+
+const contextKey ctxKey = iota
+
+var (
+	mu     sync.RWMutex
+	tables = map[string]map[string]string{}
+)
+
+// Load reads translation tables from a JSON file which maps locales to tables of messages
+// and their translations, e.g. {"es": {"method not found": "método no encontrado"}}.
+// Loaded tables replace the ones loaded earlier.
+func Load(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return errors.Err(err)
+	}
+	var t map[string]map[string]string
+	if err := json.Unmarshal(b, &t); err != nil {
+		return errors.Err(err)
+	}
+	SetTables(t)
+	return nil
+}
+
+// SetTables replaces translation tables, locales are matched case-insensitively.
+func SetTables(t map[string]map[string]string) {
+	normalized := make(map[string]map[string]string, len(t))
+	for l, table := range t {
+		normalized[normalize(l)] = table
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	tables = normalized
+}
+
+// Available is true when any translation tables are loaded, so responses may depend on the client's locale.
+func Available() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(tables) > 0
+}
+
+// Parse returns locales listed in Accept-Language header in the order of preference.
+// Locales with zero or invalid quality and the wildcard are skipped.
+func Parse(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		l := normalize(fields[0])
+		if l == "" || l == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if !strings.HasPrefix(f, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(f[2:], 64)
+			if err != nil {
+				v = 0
+			}
+			q = v
+		}
+		if q <= 0 {
+			continue
+		}
+		items = append(items, weighted{l, q})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].q > items[j].q })
+
+	locales := make([]string, len(items))
+	for i, w := range items {
+		locales[i] = w.locale
+	}
+	return locales
+}
+
+// Match returns the first of preferred locales which has a translation table, trying the base language
+// when there's no table for the regional variant (pt-br falls back to pt). Preferring the default locale
+// over the others or not matching any of them gives Default.
+func Match(preferred []string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, l := range preferred {
+		base := strings.SplitN(l, "-", 2)[0]
+		if l == Default || base == Default {
+			return Default
+		}
+		if _, ok := tables[l]; ok {
+			return l
+		}
+		if _, ok := tables[base]; ok {
+			return base
+		}
+	}
+	return Default
+}
+
+// Translate returns msg translated to locale, or msg itself if there's no translation for it.
+func Translate(locale, msg string) string {
+	if locale == Default {
+		return msg
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if t, ok := tables[locale][msg]; ok && t != "" {
+		return t
+	}
+	return msg
+}
+
+// FromHeader matches locale for the Accept-Language header value.
+func FromHeader(header string) string {
+	if header == "" {
+		return Default
+	}
+	return Match(Parse(header))
+}
+
+// AddToRequest stores locale in the request context.
+func AddToRequest(r *http.Request, locale string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), contextKey, locale))
+}
+
+// FromRequest returns locale stored in the request context, or Default.
+func FromRequest(r *http.Request) string {
+	if l, ok := r.Context().Value(contextKey).(string); ok {
+		return l
+	}
+	return Default
+}
+
+func normalize(l string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(l), "_", "-"))
+}
@@ -0,0 +1,64 @@
+package locale
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []string{"fr-ch", "fr", "en", "de"}, Parse("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"de", "pt-br"}, Parse("pt_BR;q=0.5, de, es;q=0, it;q=abc"))
+	assert.Empty(t, Parse(""))
+	assert.Empty(t, Parse("*"))
+}
+
+func TestMatch(t *testing.T) {
+	assert.False(t, Available())
+	SetTables(map[string]map[string]string{"es": {}, "PT-BR": {}, "fr": {}})
+	defer SetTables(nil)
+	assert.True(t, Available())
+
+	cases := []struct {
+		header, expected string
+	}{
+		{"es", "es"},
+		{"es-MX, en;q=0.5", "es"},
+		{"pt-BR", "pt-br"},
+		{"pt-PT, fr;q=0.2", "fr"},
+		{"en-US, es;q=0.9", Default},
+		{"de, it", Default},
+		{"", Default},
+		{"de, fr;q=0.9, es;q=0.95", "es"},
+	}
+	for _, c := range cases {
+		t.Run(c.header, func(t *testing.T) {
+			assert.Equal(t, c.expected, FromHeader(c.header))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "translations.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"ES": {"wallet not found": "billetera no encontrada"}}`), 0644))
+	require.NoError(t, Load(file))
+	defer SetTables(nil)
+
+	assert.Equal(t, "billetera no encontrada", Translate("es", "wallet not found"))
+	assert.Equal(t, "something else", Translate("es", "something else"))
+	assert.Equal(t, "wallet not found", Translate("fr", "wallet not found"))
+	assert.Equal(t, "wallet not found", Translate(Default, "wallet not found"))
+
+	assert.Error(t, Load(filepath.Join(t.TempDir(), "missing.json")))
+	assert.Equal(t, "billetera no encontrada", Translate("es", "wallet not found"))
+}
+
+func TestFromRequest(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPost, "/", nil)
+	assert.Equal(t, Default, FromRequest(r))
+	assert.Equal(t, "es", FromRequest(AddToRequest(r, "es")))
+}
@@ -0,0 +1,37 @@
+// Package maintenance implements a runtime toggle for putting a service into maintenance mode.
+package maintenance
+
+import "sync"
+
+// Switch reports whether maintenance mode is currently on.
+// The state is read from source on every check so it can be flipped at runtime
+// (for example by a hot-reloaded config value), and onChange is called on every transition.
+type Switch struct {
+	mu       sync.Mutex
+	on       bool
+	source   func() bool
+	onChange func(on bool)
+}
+
+// NewSwitch creates a maintenance Switch. onChange can be nil.
+func NewSwitch(source func() bool, onChange func(on bool)) *Switch {
+	return &Switch{source: source, onChange: onChange}
+}
+
+// IsOn returns true if the service is in maintenance mode.
+func (s *Switch) IsOn() bool {
+	if s == nil || s.source == nil {
+		return false
+	}
+	on := s.source()
+
+	s.mu.Lock()
+	changed := on != s.on
+	s.on = on
+	s.mu.Unlock()
+
+	if changed && s.onChange != nil {
+		s.onChange(on)
+	}
+	return on
+}
@@ -0,0 +1,29 @@
+package maintenance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitch(t *testing.T) {
+	var (
+		flag        bool
+		transitions []bool
+	)
+	s := NewSwitch(func() bool { return flag }, func(on bool) { transitions = append(transitions, on) })
+
+	assert.False(t, s.IsOn())
+	flag = true
+	assert.True(t, s.IsOn())
+	assert.True(t, s.IsOn())
+	flag = false
+	assert.False(t, s.IsOn())
+
+	assert.Equal(t, []bool{true, false}, transitions)
+}
+
+func TestSwitchNil(t *testing.T) {
+	var s *Switch
+	assert.False(t, s.IsOn())
+}
@@ -0,0 +1,105 @@
+// Package constlabels attaches deployment-wide labels, like the region the API runs in, to all metrics
+// registered with the default Prometheus registerer. Labels are read from the environment when the package
+// is initialized, which happens before any metric of packages importing it is registered:
+//
+//	LW_METRICS_REGION=eu-central
+//	LW_METRICS_LABELS=cluster=blue,tier=web
+//
+// so a central Prometheus can tell apart metrics of different deployments. Keep the values few and static,
+// each one multiplies the number of series in the central Prometheus. The labels must not collide
+// with labels of any metric, registering such a metric panics.
+package constlabels
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Environment variables holding the labels.
+const (
+	RegionEnv = "LW_METRICS_REGION"
+	LabelsEnv = "LW_METRICS_LABELS"
+)
+
+// RegionLabel is the name of the label set from RegionEnv.
+const RegionLabel = "region"
+
+var (
+	labels    prometheus.Labels
+	labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+func init() {
+	var err error
+	labels, err = Parse(os.Getenv(RegionEnv), os.Getenv(LabelsEnv))
+	if err != nil {
+		// Loggers are not available yet and running without labels would mix up metrics of different deployments.
+		panic(fmt.Sprintf("invalid metric labels: %v", err))
+	}
+	if len(labels) == 0 {
+		return
+	}
+	replaceDefaultRegistry(labels)
+}
+
+// Labels returns labels attached to all metrics.
+func Labels() prometheus.Labels {
+	l := prometheus.Labels{}
+	for k, v := range labels {
+		l[k] = v
+	}
+	return l
+}
+
+// Parse builds a label set from region and a comma-separated list of name=value pairs.
+func Parse(region, list string) (prometheus.Labels, error) {
+	l := prometheus.Labels{}
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Err("%q is not a name=value pair", pair)
+		}
+		l[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if region = strings.TrimSpace(region); region != "" {
+		l[RegionLabel] = region
+	}
+	for k, v := range l {
+		if !labelName.MatchString(k) || strings.HasPrefix(k, "__") {
+			return nil, errors.Err("%q is not a valid label name", k)
+		}
+		if v == "" {
+			return nil, errors.Err("label %v has no value", k)
+		}
+	}
+	return l, nil
+}
+
+// Wrap returns a registerer attaching labels to all metrics registered with it in reg.
+func Wrap(reg prometheus.Registerer, labels prometheus.Labels) prometheus.Registerer {
+	return prometheus.WrapRegistererWith(labels, reg)
+}
+
+// replaceDefaultRegistry makes a new registry the default one, with all metrics registered in it getting labels.
+// Go runtime and process collectors, which prometheus registers in its own default registry, are registered again with them.
+// The old registry can't be reused as it keeps label names of metrics registered once, even after they're unregistered.
+func replaceDefaultRegistry(labels prometheus.Labels) {
+	reg := prometheus.NewRegistry()
+	wrapped := Wrap(reg, labels)
+	wrapped.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+	prometheus.DefaultRegisterer = wrapped
+	prometheus.DefaultGatherer = reg
+}
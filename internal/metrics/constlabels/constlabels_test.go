@@ -0,0 +1,51 @@
+package constlabels
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	l, err := Parse("eu-central", " cluster=blue, tier = web ,")
+	require.NoError(t, err)
+	assert.Equal(t, prometheus.Labels{"region": "eu-central", "cluster": "blue", "tier": "web"}, l)
+
+	l, err = Parse("", "")
+	require.NoError(t, err)
+	assert.Empty(t, l)
+
+	l, err = Parse("us-east", "region=eu-central")
+	require.NoError(t, err)
+	assert.Equal(t, prometheus.Labels{"region": "us-east"}, l)
+
+	for _, list := range []string{"cluster", "cluster=", "1cluster=blue", "__name__=x", "clu-ster=blue"} {
+		_, err := Parse("", list)
+		assert.Error(t, err, list)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := promauto.With(Wrap(reg, prometheus.Labels{"region": "eu-central"})).NewCounterVec(
+		prometheus.CounterOpts{Name: "calls_total", Help: "Calls"}, []string{"method"},
+	)
+	c.WithLabelValues("resolve").Inc()
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	require.Len(t, mfs[0].Metric, 1)
+	got := map[string]string{}
+	for _, lp := range mfs[0].Metric[0].Label {
+		got[lp.GetName()] = lp.GetValue()
+	}
+	assert.Equal(t, map[string]string{"region": "eu-central", "method": "resolve"}, got)
+
+	assert.Error(t, Wrap(reg, prometheus.Labels{"method": "x"}).Register(
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "other_total", Help: "Other"}, []string{"method"}),
+	))
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbrytv/internal/metrics/constlabels"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run with LW_METRICS_REGION / LW_METRICS_LABELS set to check labels make it to exported metrics.
+func TestConstLabelsExported(t *testing.T) {
+	ProxyCallCounter.WithLabelValues("resolve", "http://lbrynet:5279/", "").Inc()
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, mfs)
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			got := map[string]string{}
+			for _, lp := range m.Label {
+				got[lp.GetName()] = lp.GetValue()
+			}
+			for k, v := range constlabels.Labels() {
+				assert.Equal(t, v, got[k], "%v is missing %v label", mf.GetName(), k)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errorRateSlots is the number of slots the rolling window is split into.
+const errorRateSlots = 60
+
+// RollingErrorRate is a gauge of the share of failed calls over a sliding time window, by label value.
+// It's derived from the same observations as call counters, which makes it usable for alerting
+// without rate calculations on the metrics backend side.
+type RollingErrorRate struct {
+	desc   *prometheus.Desc
+	mu     sync.Mutex
+	window time.Duration
+	values map[string]*[errorRateSlots]errorRateSlot
+	now    func() time.Time
+}
+
+type errorRateSlot struct {
+	n               int64
+	total, failures int
+}
+
+// NewRollingErrorRate creates an error rate gauge with a single label, it needs to be registered before use.
+func NewRollingErrorRate(opts prometheus.GaugeOpts, label string, window time.Duration) *RollingErrorRate {
+	return &RollingErrorRate{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+			opts.Help, []string{label}, opts.ConstLabels,
+		),
+		window: window,
+		values: map[string]*[errorRateSlots]errorRateSlot{},
+		now:    time.Now,
+	}
+}
+
+// Observe records the outcome of a call. Changing window discards calls observed so far.
+func (e *RollingErrorRate) Observe(value string, failed bool, window time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if window > 0 && window != e.window {
+		e.window = window
+		e.values = map[string]*[errorRateSlots]errorRateSlot{}
+	}
+	slots, ok := e.values[value]
+	if !ok {
+		slots = &[errorRateSlots]errorRateSlot{}
+		e.values[value] = slots
+	}
+	n := e.slot()
+	s := &slots[n%errorRateSlots]
+	if s.n != n {
+		*s = errorRateSlot{n: n}
+	}
+	s.total++
+	if failed {
+		s.failures++
+	}
+}
+
+// Rate returns the share of failed calls within the window, ok is false if there were no calls.
+func (e *RollingErrorRate) Rate(value string) (rate float64, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	slots, ok := e.values[value]
+	if !ok {
+		return 0, false
+	}
+	return e.rate(slots, e.slot())
+}
+
+// Describe implements prometheus.Collector.
+func (e *RollingErrorRate) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.desc
+}
+
+// Collect implements prometheus.Collector. Values without calls within the window are not reported.
+func (e *RollingErrorRate) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := e.slot()
+	for v, slots := range e.values {
+		rate, ok := e.rate(slots, n)
+		if !ok {
+			delete(e.values, v)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(e.desc, prometheus.GaugeValue, rate, v)
+	}
+}
+
+func (e *RollingErrorRate) slot() int64 {
+	width := int64(e.window / errorRateSlots)
+	if width <= 0 {
+		width = 1
+	}
+	return e.now().UnixNano() / width
+}
+
+func (e *RollingErrorRate) rate(slots *[errorRateSlots]errorRateSlot, n int64) (float64, bool) {
+	var total, failures int
+	for _, s := range slots {
+		if s.n > n-errorRateSlots {
+			total += s.total
+			failures += s.failures
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(failures) / float64(total), true
+}
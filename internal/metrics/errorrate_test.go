@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingErrorRate(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	e := NewRollingErrorRate(prometheus.GaugeOpts{Name: "test_error_rate"}, "method", time.Minute)
+	e.now = func() time.Time { return now }
+
+	_, ok := e.Rate("resolve")
+	assert.False(t, ok)
+
+	e.Observe("resolve", false, time.Minute)
+	e.Observe("resolve", true, time.Minute)
+	now = now.Add(30 * time.Second)
+	e.Observe("resolve", false, time.Minute)
+	e.Observe("resolve", false, time.Minute)
+	e.Observe("claim_search", true, time.Minute)
+
+	rate, ok := e.Rate("resolve")
+	require.True(t, ok)
+	assert.Equal(t, 0.25, rate)
+	rate, _ = e.Rate("claim_search")
+	assert.Equal(t, 1.0, rate)
+
+	// Calls from the first half of the window fall out of it
+	now = now.Add(40 * time.Second)
+	rate, _ = e.Rate("resolve")
+	assert.Equal(t, 0.0, rate)
+
+	now = now.Add(time.Minute)
+	_, ok = e.Rate("resolve")
+	assert.False(t, ok)
+
+	e.Observe("resolve", true, time.Minute)
+	m := GetMetric(e)
+	assert.Equal(t, 1.0, m.GetGauge().GetValue())
+	assert.Equal(t, "resolve", m.GetLabel()[0].GetValue())
+
+	// Methods without calls within the window are not reported
+	c := make(chan prometheus.Metric, 10)
+	e.Collect(c)
+	assert.Len(t, c, 1)
+
+	e.Observe("resolve", false, 2*time.Minute)
+	rate, _ = e.Rate("resolve")
+	assert.Equal(t, 0.0, rate, "changing window should reset observations")
+}
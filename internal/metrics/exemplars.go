@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceIDLabel is the exemplar label holding the ID of the traced request.
+const TraceIDLabel = "trace_id"
+
+// maxTraceIDLength keeps exemplars within the 128 characters OpenMetrics allows for their labels.
+const maxTraceIDLength = 64
+
+// exemplarThreshold holds bits of the duration in seconds from which observations get an exemplar,
+// zero means exemplars are disabled.
+var exemplarThreshold uint64
+
+// SetExemplarThreshold makes ObserveWithTrace attach exemplars to observations of threshold or longer.
+// Zero threshold disables exemplars.
+func SetExemplarThreshold(threshold time.Duration) {
+	atomic.StoreUint64(&exemplarThreshold, math.Float64bits(threshold.Seconds()))
+}
+
+// ObserveWithTrace records duration d in seconds, attaching an exemplar linking it to traceID
+// if d is above the exemplar threshold. Faster calls are observed as usual, so exemplars only point at slow ones.
+func ObserveWithTrace(o prometheus.Observer, d float64, traceID string) {
+	threshold := math.Float64frombits(atomic.LoadUint64(&exemplarThreshold))
+	eo, ok := o.(prometheus.ExemplarObserver)
+	if threshold <= 0 || d < threshold || traceID == "" || !ok {
+		o.Observe(d)
+		return
+	}
+	if len(traceID) > maxTraceIDLength {
+		traceID = traceID[:maxTraceIDLength]
+	}
+	if !utf8.ValidString(traceID) {
+		o.Observe(d)
+		return
+	}
+	eo.ObserveWithExemplar(d, prometheus.Labels{TraceIDLabel: traceID})
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveWithTrace(t *testing.T) {
+	defer SetExemplarThreshold(0)
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Buckets: []float64{0.5, 5}})
+	exemplars := func() map[float64]string {
+		m := GetMetric(h)
+		found := map[float64]string{}
+		for _, b := range m.Histogram.Bucket {
+			if e := b.GetExemplar(); e != nil {
+				require.Len(t, e.Label, 1)
+				assert.Equal(t, TraceIDLabel, e.Label[0].GetName())
+				found[b.GetUpperBound()] = e.Label[0].GetValue()
+			}
+		}
+		return found
+	}
+
+	ObserveWithTrace(h, 2, "disabled")
+	assert.Empty(t, exemplars())
+
+	SetExemplarThreshold(time.Second)
+	ObserveWithTrace(h, 0.1, "fast")
+	ObserveWithTrace(h, 2, "")
+	assert.Empty(t, exemplars())
+
+	ObserveWithTrace(h, 2, "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, map[float64]string{5: "4bf92f3577b34da6a3ce929d0e0e4736"}, exemplars())
+	assert.EqualValues(t, 4, GetMetric(h).Histogram.GetSampleCount())
+}
@@ -1,6 +1,11 @@
 package metrics
 
 import (
+	"time"
+
+	// Attaches deployment labels (region etc.) to metrics below, it has to be initialized before they're registered.
+	_ "github.com/lbryio/lbrytv/internal/metrics/constlabels"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	dto "github.com/prometheus/client_model/go"
@@ -34,6 +39,21 @@ const (
 	FailureKindAuth             = "auth"
 	FailureKindInternal         = "internal"
 	FailureKindLbrynetXMismatch = "xmismatch"
+	FailureKindMaintenance      = "maintenance"
+	FailureKindWalletBusy       = "wallet_busy"
+	FailureKindRateLimited      = "rate_limited"
+	FailureKindDuplicate        = "duplicate"
+	FailureKindUserCircuitOpen  = "user_circuit_open"
+	// FailureKindUpstreamInvalid is for SDK responses which aren't JSON-RPC, like HTML error pages of a proxy in front of it.
+	FailureKindUpstreamInvalid = "upstream_invalid"
+	// FailureKindDropped is recorded for calls which have finished without their outcome being observed.
+	FailureKindDropped = "dropped"
+
+	// Stages of resumable publish uploads (see PublishUploads).
+	UploadStarted   = "started"
+	UploadCompleted = "completed"
+	UploadPublished = "published"
+	UploadAbandoned = "abandoned"
 
 	GroupControl      = "control"
 	GroupExperimental = "experimental"
@@ -41,6 +61,8 @@ const (
 
 var (
 	callsSecondsBuckets = []float64{0.005, 0.025, 0.05, 0.1, 0.25, 0.4, 1, 2, 5, 10, 20, 60, 120, 300}
+	// bodyBytesBuckets go from 128B to 8MB
+	bodyBytesBuckets = prometheus.ExponentialBuckets(128, 4, 9)
 
 	IAPIAuthSuccessDurations = promauto.NewHistogram(prometheus.HistogramOpts{
 		Namespace: nsIAPI,
@@ -82,6 +104,26 @@ var (
 		},
 		[]string{"method"},
 	)
+	ProxyE2ERequestSizes = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: nsProxy,
+			Subsystem: "e2e_calls",
+			Name:      "request_bytes",
+			Help:      "Request body size distributions",
+			Buckets:   bodyBytesBuckets,
+		},
+		[]string{"method"},
+	)
+	ProxyE2EResponseSizes = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: nsProxy,
+			Subsystem: "e2e_calls",
+			Name:      "response_bytes",
+			Help:      "Response body size distributions, before compression",
+			Buckets:   bodyBytesBuckets,
+		},
+		[]string{"method"},
+	)
 	ProxyE2ECallFailedDurations = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: nsProxy,
@@ -110,6 +152,16 @@ var (
 		},
 		[]string{"method", "kind"},
 	)
+	// ProxyE2ECallErrorRate is updated from the same observation points as ProxyE2ECallCounter.
+	ProxyE2ECallErrorRate = NewRollingErrorRate(
+		prometheus.GaugeOpts{
+			Namespace: nsProxy,
+			Subsystem: "e2e_calls",
+			Name:      "error_rate",
+			Help:      "Share of failed end-to-end method calls over a rolling window",
+		},
+		"method", 5*time.Minute,
+	)
 
 	ProxyCallDurations = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -140,6 +192,15 @@ var (
 		},
 		[]string{"method", "endpoint", "origin"},
 	)
+	ProxyClientCallCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: nsProxy,
+			Subsystem: "calls",
+			Name:      "client_count",
+			Help:      "Call count by client app and version (major.minor, limited to KnownClientVersions)",
+		},
+		[]string{"app", "version"},
+	)
 	ProxyCallFailedCounter = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: nsProxy,
@@ -155,19 +216,365 @@ var (
 		Subsystem: "cache",
 		Name:      "hit_count",
 		Help:      "Total number of queries found in the local cache",
+	}, []string{"method", "backend"})
+	ProxyPageSizeLimitedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "query",
+		Name:      "page_size_limited_count",
+		Help:      "Total number of queries which had their page_size reduced to the maximum",
 	}, []string{"method"})
+	ProxyQueryCacheNotFoundHitCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "not_found_hit_count",
+		Help:      "Total number of queries answered with a cached not found response",
+	}, []string{"method"})
+	ProxyClaimIDsCacheHitRatio = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "claim_ids_hit_ratio",
+		Help:      "Share of claims found in the local cache per resolve_claim_ids query",
+		Buckets:   prometheus.LinearBuckets(0, 0.1, 11),
+	})
+	ProxyClaimSearchEnrichmentHitRatio = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "claim_search_enrichment_hit_ratio",
+		Help:      "Share of claim_search result items found among cached claims per query",
+		Buckets:   prometheus.LinearBuckets(0, 0.1, 11),
+	})
+	ProxyClaimSearchEnrichedItems = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "claim_search_enriched_items",
+		Help:      "Total number of claim_search result items updated with cached claim data",
+	})
+	ProxyClaimSearchEnrichmentSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "claim_search_enrichment_skipped",
+		Help:      "Total number of claim_search results left without enrichment by reason",
+	}, []string{"reason"})
 	ProxyQueryCacheMissCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: nsProxy,
 		Subsystem: "cache",
 		Name:      "miss_count",
 		Help:      "Total number of queries that were not in the local cache",
+	}, []string{"method", "backend"})
+	ProxyQueryCachePinned = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "pinned",
+		Help:      "Number of pinned cache entries which are never evicted",
+	})
+	ProxyQueryCachePinRefreshFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "pin_refresh_failures",
+		Help:      "Total number of failed refreshes of pinned cache entries",
+	}, []string{"method"})
+	ProxyQueryCacheBypassCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "bypass_count",
+		Help:      "Total number of queries sent to the SDK bypassing the local cache",
+	}, []string{"method"})
+	ProxyKillSwitchHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "killswitch",
+		Name:      "hit_count",
+		Help:      "Total number of queries rejected by kill switch rules",
+	}, []string{"rule"})
+	ProxyRequestRuleHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "rules",
+		Name:      "hit_count",
+		Help:      "Total number of queries changed or rejected by request rules",
+	}, []string{"rule", "action"})
+	ReadModelLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "readmodel",
+		Name:      "lookups",
+		Help:      "Total number of resolve queries looked up in the local read model by outcome (hit, miss, stale)",
+	}, []string{"outcome"})
+	ReadModelLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsProxy,
+		Subsystem: "readmodel",
+		Name:      "lag_seconds",
+		Help:      "Time since the local read model has last caught up with the blockchain",
+	})
+	ReadModelClaims = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsProxy,
+		Subsystem: "readmodel",
+		Name:      "claims",
+		Help:      "Number of claims in the local read model",
+	})
+	SamplingSamples = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sampling",
+		Name:      "samples",
+		Help:      "Total number of captured query samples by outcome (written, dropped when the buffer is full, failed to write)",
+	}, []string{"outcome"})
+	AuditLogRotations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "audit_log",
+		Name:      "rotations",
+		Help:      "Total number of audit log file rotations",
+	})
+	AuditLogArchiveUploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "audit_log",
+		Name:      "archive_uploads",
+		Help:      "Total number of audit log archive uploads by result (uploaded, failed)",
+	}, []string{"result"})
+	AuditLogArchivesExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "audit_log",
+		Name:      "archives_expired",
+		Help:      "Total number of audit log archives deleted after the retention period",
+	})
+	ProxyEntitlementDenied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "entitlements",
+		Name:      "denied_count",
+		Help:      "Total number of queries rejected because the user lacks the entitlement required for the method",
+	}, []string{"method"})
+	EntitlementsFetchFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "entitlements",
+		Name:      "fetch_failures",
+		Help:      "Total number of times user entitlements could not be fetched",
+	})
+	EntitlementsCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "entitlements",
+		Name:      "cache_hits",
+	})
+	EntitlementsCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "entitlements",
+		Name:      "cache_misses",
+	})
+	ProxyGeoBlocked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "geoblock",
+		Name:      "blocked_count",
+		Help:      "Total number of queries rejected because of client country",
+	}, []string{"method", "country"})
+	ProxySDKRerouteCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "reroute_count",
+		Help:      "Total number of queries rerouted from restarting SDK servers to healthy ones",
+	}, []string{"method"})
+	ProxySDKRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "retries",
+		Help:      "Total number of SDK query retries by reason and attempt number",
+	}, []string{"method", "reason", "attempt"})
+	ProxyQueryCacheTTL = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "ttl_seconds",
+		Help:      "TTL of responses stored in the local cache",
+		Buckets:   []float64{30, 60, 180, 300, 600, 900, 1800, 3600},
 	}, []string{"method"})
 	ProxyQueryCacheErrorCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: nsProxy,
 		Subsystem: "cache",
 		Name:      "error_count",
 		Help:      "Total number of errors retrieving queries from the local cache",
+	}, []string{"method", "backend"})
+	ProxyQueryCacheOperationDurations = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "operation_seconds",
+		Help:      "Latency of cache backend operations",
+		Buckets:   []float64{0.00001, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5},
+	}, []string{"backend", "operation"})
+	ProxyQueryCacheDirectives = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "directives",
+		Help:      "Total number of cache directives of trusted callers applied, or rejected, by kind",
+	}, []string{"directive"})
+	ProxyQueryCacheShadowResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "cache",
+		Name:      "shadow_results",
+		Help:      "Total number of operations mirrored to the shadow cache backend by their result",
+	}, []string{"backend", "operation", "result"})
+	ProxyDeadLetterCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "deadletter",
+		Name:      "queued_count",
+		Help:      "Total number of failed wallet operations queued for retrying",
+	}, []string{"method"})
+	ProxyDeadLetterRetryCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "deadletter",
+		Name:      "retry_count",
+		Help:      "Total number of queued wallet operation retries by their result",
+	}, []string{"method", "status"})
+	ProxySchedulerWaitSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "scheduler",
+		Name:      "wait_seconds",
+		Help:      "Time queries spend waiting for an SDK call slot by priority class",
+		Buckets:   callsSecondsBuckets,
+	}, []string{"priority"})
+	ProxySchedulerQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsProxy,
+		Subsystem: "scheduler",
+		Name:      "queued",
+		Help:      "Number of queries waiting for an SDK call slot by priority class",
+	}, []string{"priority"})
+	ProxySchedulerQueueTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "scheduler",
+		Name:      "queue_timeouts",
+		Help:      "Number of queries rejected after waiting too long for an SDK call slot by priority class",
+	}, []string{"priority"})
+	ProxyBackpressureSignals = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "scheduler",
+		Name:      "backpressure_signals",
+		Help:      "Number of responses telling clients to slow down because SDK call slots are nearly exhausted",
+	})
+	ProxyResponseValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "validation_failures",
+		Help:      "Total number of SDK responses which failed validation",
+	}, []string{"method"})
+	ProxySDKWarnings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "warnings",
+		Help:      "Total number of warnings returned by the SDK along with successful results",
+	}, []string{"method", "type"})
+	ProxyProjectionBytesSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "projection_bytes_saved",
+		Help:      "Total size of list item fields removed from responses as clients haven't asked for them",
+	}, []string{"method"})
+	ProxySDKResponsesAdapted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "responses_adapted",
+		Help:      "Total number of SDK responses normalized by version-specific adapters",
+	}, []string{"method", "adapter"})
+	ProxyResponseVersions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "response_versions",
+		Help:      "Total number of responses converted to a version negotiated with Accept-Version",
+	}, []string{"method", "version"})
+	ProxyParamsSanitized = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "params_sanitized",
+		Help:      "Total number of params not allowed by sanitization rules, by action taken",
+	}, []string{"method", "param", "action"})
+	ProxyRequestsTransformed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "sdk",
+		Name:      "requests_transformed",
+		Help:      "Total number of requests converted from an old schema of the method by request transforms",
+	}, []string{"method", "transform"})
+	ProxyDegradedResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "degraded",
+		Help:      "Total number of best-effort responses given instead of SDK ones",
+	}, []string{"method"})
+	PublishUploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "publish",
+		Name:      "uploads",
+		Help:      "Total number of resumable publish uploads reaching each stage, from started to published or abandoned",
+	}, []string{"stage"})
+	ProxyDuplicateRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "duplicate",
+		Help:      "Total number of duplicate requests, by how they were handled",
+	}, []string{"method", "action"})
+	ProxyAliasedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "aliased",
+		Help:      "Total number of calls made using old method names",
+	}, []string{"alias"})
+	ProxyWalletOutageResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "wallet_outage_responses",
+		Help:      "Total number of user wallet reads answered without their wallet server, by kind of response",
+	}, []string{"method", "kind"})
+	ProxyFallbackResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "calls",
+		Name:      "fallback",
+		Help:      "Total number of static fallback responses given while all SDK servers were down",
+	}, []string{"method"})
+	ProxyCircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsProxy,
+		Subsystem: "circuit_breaker",
+		Name:      "state",
+		Help:      "Circuit breaker state: 0 closed, 1 half-open, 2 open",
+	}, []string{"kind", "name"})
+	ProxyCircuitBreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "circuit_breaker",
+		Name:      "rejected",
+		Help:      "Total number of SDK calls not made because a circuit breaker was open",
+	}, []string{"method"})
+	ProxyUserCircuitBreakerTrips = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "circuit_breaker",
+		Name:      "user_trips",
+		Help:      "Total number of times circuit breakers of users opened, by user ID bucket",
+	}, []string{"bucket"})
+	ProxyUserCircuitBreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "circuit_breaker",
+		Name:      "user_rejected",
+		Help:      "Total number of calls refused because the circuit breaker of the calling user was open",
+	}, []string{"method"})
+	ProxyRateLimitedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "rate_limit",
+		Name:      "throttled",
+		Help:      "Total number of calls rejected by rate limits",
+	}, []string{"method"})
+	ProxyDeprecatedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "deprecation",
+		Name:      "calls",
+		Help:      "Total number of calls to deprecated methods",
 	}, []string{"method"})
+	ProxyResponseCompressionRatio = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: nsProxy,
+		Subsystem: "compression",
+		Name:      "ratio",
+		Help:      "Size of compressed responses relative to their original size",
+		Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+	}, []string{"method"})
+	ProxyBatchCoalescedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "batch",
+		Name:      "coalesced",
+		Help:      "Total number of batch sub-requests answered with the response to an identical one",
+	}, []string{"method"})
+	UserLockBackendFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: nsProxy,
+		Subsystem: "wallet_lock",
+		Name:      "backend_failures",
+		Help:      "Total number of times the shared wallet lock could not be acquired because of a database failure",
+	})
 
 	LbrynetWalletsLoaded = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: nsLbrynet,
@@ -175,6 +582,48 @@ var (
 		Name:      "count",
 		Help:      "Number of wallets currently loaded",
 	}, []string{LabelSource})
+	LbrynetServerQuarantined = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "server",
+		Name:      "quarantined",
+		Help:      "Whether SDK server is out of rotation until it passes a health check",
+	}, []string{LabelSource})
+	LbrynetServerRampWeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "server",
+		Name:      "ramp_weight",
+		Help:      "Share of traffic SDK server gets while slowly starting after quarantine, 1 when fully available",
+	}, []string{LabelSource})
+	LbrynetServerDraining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "server",
+		Name:      "draining",
+		Help:      "Whether SDK server is taken out of rotation for maintenance",
+	}, []string{LabelSource})
+	LbrynetWalletsAssigned = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "wallets",
+		Name:      "assigned",
+		Help:      "Number of users assigned to SDK server as of the last rebalancing check",
+	}, []string{LabelSource})
+	LbrynetWalletImbalance = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "wallets",
+		Name:      "imbalance",
+		Help:      "Ratio of wallets assigned to the most loaded SDK server to the average across servers in rotation",
+	})
+	LbrynetWalletMigrationsPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "wallets",
+		Name:      "migrations_pending",
+		Help:      "Number of wallet migrations left in the current rebalancing run",
+	})
+	LbrynetWalletMigrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: nsLbrynet,
+		Subsystem: "wallets",
+		Name:      "migrations",
+		Help:      "Total number of wallet migrations between SDK servers by outcome",
+	}, []string{"outcome"})
 
 	UIBufferCount = promauto.NewCounter(prometheus.CounterOpts{
 		Namespace: nsUI,
@@ -200,6 +649,16 @@ var (
 		},
 		[]string{"path"},
 	)
+	LbrytvCanaryCallDurations = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: nsLbrytv,
+			Subsystem: "calls",
+			Name:      "variant_seconds",
+			Help:      "How long do calls to lbrytv take (end-to-end) by build and canary variant",
+			Buckets:   callsSecondsBuckets,
+		},
+		[]string{"build", "variant"},
+	)
 
 	LbrytvNewUsers = promauto.NewCounter(prometheus.CounterOpts{
 		Namespace: nsLbrytv,
@@ -246,6 +705,19 @@ var (
 		Help:      "Number of idle db connections in the Go connection pool",
 	})
 
+	LbrytvWalletEventsWaiting = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "wallet_events",
+		Name:      "waiting",
+		Help:      "Number of long-poll connections waiting for wallet events",
+	})
+	LbrytvWalletEventsWatchers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: nsLbrytv,
+		Subsystem: "wallet_events",
+		Name:      "watchers",
+		Help:      "Number of wallets being polled for events",
+	})
+
 	LbrynetXCallDurations = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: nsLbrynext,
@@ -296,6 +768,10 @@ var (
 	)
 )
 
+func init() {
+	prometheus.MustRegister(ProxyE2ECallErrorRate)
+}
+
 func GetMetric(col prometheus.Collector) dto.Metric {
 	c := make(chan prometheus.Metric, 1) // 1 for metric with no vector
 	col.Collect(c)                       // collect current metric value into the channel
@@ -308,3 +784,8 @@ func GetCounterValue(col prometheus.Collector) float64 {
 	m := GetMetric(col)
 	return *m.Counter.Value
 }
+
+func GetGaugeValue(col prometheus.Collector) float64 {
+	m := GetMetric(col)
+	return *m.Gauge.Value
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SensitiveParams contains names of request parameters which values should never be exposed
+// outside of the service (in logs, error reports or API responses).
+var SensitiveParams = []string{
+	"password",
+	"new_password",
+	"private_key",
+	"seed",
+	"data",
+	"token",
+	"auth_token",
+	"api_key",
+	"wallet_id",
+}
+
+// IsSensitiveParam checks if parameter name belongs to SensitiveParams, case-insensitive.
+func IsSensitiveParam(name string) bool {
+	for _, p := range SensitiveParams {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+var sensitiveTextValue = regexp.MustCompile(
+	`(?i)((?:` + strings.Join(SensitiveParams, "|") + `)["']?\s*[:=]\s*["']?)[^"'&\s,;}<]+`,
+)
+
+// RedactText masks values of sensitive parameters appearing in free-form text, like `"wallet_id": "..."`
+// or `token=...`, for text of unknown format which is about to be exposed.
+func RedactText(s string) string {
+	return sensitiveTextValue.ReplaceAllString(s, "${1}"+valueMask)
+}
+
+// RedactParams returns a copy of params with values of sensitive parameters masked.
+// Nested maps and lists are processed recursively, the original map is not modified.
+func RedactParams(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if IsSensitiveParam(k) {
+			redacted[k] = valueMask
+			continue
+		}
+		redacted[k] = Redact(v)
+	}
+	return redacted
+}
+
+// Redact returns a copy of v with values of sensitive parameters masked in all maps it contains.
+// It's used for anything leaving the service: logs, Sentry reports and API responses.
+func Redact(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return RedactParams(vv)
+	case []interface{}:
+		l := make([]interface{}, len(vv))
+		for i, e := range vv {
+			l[i] = Redact(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
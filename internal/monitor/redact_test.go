@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactParams(t *testing.T) {
+	params := map[string]interface{}{
+		"password": "secret",
+		"amount":   "1.0",
+		"nested": map[string]interface{}{
+			"Private_Key": "abc",
+			"name":        "wallet",
+		},
+		"list": []interface{}{map[string]interface{}{"seed": "words"}, "plain"},
+	}
+	redacted := RedactParams(params)
+
+	assert.Equal(t, valueMask, redacted["password"])
+	assert.Equal(t, "1.0", redacted["amount"])
+	assert.Equal(t, valueMask, redacted["nested"].(map[string]interface{})["Private_Key"])
+	assert.Equal(t, "wallet", redacted["nested"].(map[string]interface{})["name"])
+	assert.Equal(t, valueMask, redacted["list"].([]interface{})[0].(map[string]interface{})["seed"])
+	assert.Equal(t, "plain", redacted["list"].([]interface{})[1])
+
+	// Original should stay intact
+	assert.Equal(t, "secret", params["password"])
+	assert.Equal(t, "abc", params["nested"].(map[string]interface{})["Private_Key"])
+
+	assert.Nil(t, RedactParams(nil))
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "plain", Redact("plain"))
+	assert.Equal(t,
+		[]interface{}{map[string]interface{}{"token": valueMask, "name": "one"}},
+		Redact([]interface{}{map[string]interface{}{"token": "abc", "name": "one"}}))
+}
+
+func TestRedactText(t *testing.T) {
+	assert.Equal(t, "plain text", RedactText("plain text"))
+	assert.Equal(t, "token=****&name=one", RedactText("token=abc&name=one"))
+	assert.Equal(t, `{"wallet_id": "****", "Password":"****"}`, RedactText(`{"wallet_id": "w1", "Password":"pw"}`))
+}
@@ -0,0 +1,129 @@
+// Package ratelimit throttles clients with token buckets, allowing short bursts while capping the sustained rate.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+)
+
+// sweepInterval is how often buckets which have refilled completely are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   config.RateLimit
+}
+
+// refill adds tokens accumulated since the last update, up to the burst size.
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	}
+	b.updated = now
+}
+
+// Result describes the outcome of Allow.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryIn is the time until the next call is allowed, zero for allowed calls.
+	RetryIn time.Duration
+}
+
+// Limiter keeps a token bucket per key. Each bucket holds up to Burst tokens and refills at Rate tokens
+// per second, every call takes a token.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New creates a limiter with no buckets.
+func New() *Limiter {
+	return &Limiter{buckets: map[string]*bucket{}}
+}
+
+// Allow takes a token from the bucket of key, creating a full one if it doesn't exist.
+// Limits are taken on every call so they can change at runtime. Zero Burst or Rate doesn't limit calls.
+func (l *Limiter) Allow(key string, limit config.RateLimit, now time.Time) Result {
+	if !isLimited(limit) {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return Result{Remaining: 0, RetryIn: refillTime(1-b.tokens, limit)}
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}
+}
+
+// Status describes a bucket without taking a token from it.
+type Status struct {
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// ResetIn is the time until the bucket is full again, zero if it's full.
+	ResetIn time.Duration
+}
+
+// Status returns the state of the bucket of key as Allow would see it at now, a bucket which doesn't exist is full.
+func (l *Limiter) Status(key string, limit config.RateLimit, now time.Time) Status {
+	if !isLimited(limit) {
+		return Status{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		return Status{Remaining: limit.Burst}
+	}
+	bc := *b
+	bc.limit = limit
+	bc.refill(now)
+	st := Status{Remaining: int(bc.tokens)}
+	if missing := float64(limit.Burst) - bc.tokens; missing > 0 {
+		st.ResetIn = refillTime(missing, limit)
+	}
+	return st
+}
+
+// isLimited is false for limits which don't limit calls. Buckets which never refill would block their key forever
+// (and never get swept), so zero Rate is not a limit either.
+func isLimited(limit config.RateLimit) bool {
+	return limit.Burst > 0 && limit.Rate > 0
+}
+
+// refillTime returns how long it takes for the bucket to get the number of tokens back.
+func refillTime(tokens float64, limit config.RateLimit) time.Duration {
+	return time.Duration(math.Ceil(tokens / limit.Rate * float64(time.Second)))
+}
+
+// sweep drops buckets which would be full by now, they're the same as new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
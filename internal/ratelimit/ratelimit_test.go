@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_BurstThenSustained(t *testing.T) {
+	l := New()
+	limit := config.RateLimit{Rate: 2, Burst: 5}
+	now := time.Now()
+
+	// A page load firing a burst of calls goes through
+	for i := 4; i >= 0; i-- {
+		res := l.Allow("user:1", limit, now)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+	res := l.Allow("user:1", limit, now)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryIn)
+
+	// Sustained flooding only gets the refill rate through
+	allowed := 0
+	for i := 1; i <= 100; i++ {
+		if l.Allow("user:1", limit, now.Add(time.Duration(i)*100*time.Millisecond)).Allowed {
+			allowed++
+		}
+	}
+	assert.Equal(t, 20, allowed)
+
+	// After a pause the burst is available again, but no more than that
+	now = now.Add(time.Minute)
+	allowed = 0
+	for i := 0; i < 10; i++ {
+		if l.Allow("user:1", limit, now).Allowed {
+			allowed++
+		}
+	}
+	assert.Equal(t, 5, allowed)
+}
+
+func TestLimiter_Keys(t *testing.T) {
+	l := New()
+	limit := config.RateLimit{Rate: 1, Burst: 1}
+	now := time.Now()
+	assert.True(t, l.Allow("a", limit, now).Allowed)
+	assert.False(t, l.Allow("a", limit, now).Allowed)
+	assert.True(t, l.Allow("b", limit, now).Allowed)
+
+	// Limits can change between calls
+	res := l.Allow("a", config.RateLimit{Rate: 1, Burst: 3}, now.Add(3*time.Second))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+
+	assert.True(t, l.Allow("a", config.RateLimit{}, now).Allowed)
+	// Buckets which never refill would block forever, they don't limit at all
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("c", config.RateLimit{Burst: 1}, now).Allowed)
+	}
+	assert.NotContains(t, l.buckets, "c")
+	assert.Equal(t, Status{}, l.Status("c", config.RateLimit{Burst: 1}, now))
+}
+
+func TestLimiter_RetryIn(t *testing.T) {
+	l := New()
+	limit := config.RateLimit{Rate: 0.01, Burst: 1}
+	now := time.Now()
+
+	assert.True(t, l.Allow("a", limit, now).Allowed)
+	res := l.Allow("a", limit, now)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 100*time.Second, res.RetryIn)
+	res = l.Allow("a", limit, now.Add(40*time.Second))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 60*time.Second, res.RetryIn)
+	assert.True(t, l.Allow("a", limit, now.Add(100*time.Second)).Allowed)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l := New()
+	now := time.Now()
+	l.Allow("a", config.RateLimit{Rate: 1, Burst: 10}, now)
+	for i := 0; i < 10; i++ {
+		l.Allow("b", config.RateLimit{Rate: 0.1, Burst: 10}, now)
+	}
+	assert.Len(t, l.buckets, 2)
+
+	// Bucket a has refilled by now and is dropped, b is still short of tokens
+	l.Allow("c", config.RateLimit{Rate: 1, Burst: 10}, now.Add(sweepInterval))
+	assert.Len(t, l.buckets, 2)
+	assert.NotContains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "b")
+}
+
+func TestLimiter_Status(t *testing.T) {
+	l := New()
+	limit := config.RateLimit{Rate: 2, Burst: 5}
+	now := time.Now()
+
+	assert.Equal(t, Status{Remaining: 5}, l.Status("user:1", limit, now))
+	for i := 0; i < 3; i++ {
+		l.Allow("user:1", limit, now)
+	}
+	st := l.Status("user:1", limit, now)
+	assert.Equal(t, Status{Remaining: 2, ResetIn: 1500 * time.Millisecond}, st)
+	// Checking doesn't take tokens
+	assert.Equal(t, st, l.Status("user:1", limit, now))
+	assert.Equal(t, 1, l.Allow("user:1", limit, now).Remaining)
+
+	assert.Equal(t, Status{Remaining: 2, ResetIn: 1500 * time.Millisecond}, l.Status("user:1", limit, now.Add(500*time.Millisecond)))
+	assert.Equal(t, Status{Remaining: 5}, l.Status("user:1", limit, now.Add(time.Minute)))
+	assert.Equal(t, Status{}, l.Status("user:1", config.RateLimit{}, now))
+}
@@ -0,0 +1,112 @@
+// Package readmodel serves resolve queries from a local store of claims, kept in sync with the blockchain
+// by fetching claims from recent blocks from the SDK. Queries the store can't fully answer, or any queries
+// while the store is lagging behind more than allowed, go to the SDK as usual.
+package readmodel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+const hookName = "readmodel"
+
+// Lookup outcomes for metrics.
+const (
+	outcomeHit   = "hit"
+	outcomeMiss  = "miss"
+	outcomeStale = "stale"
+)
+
+var logger = monitor.NewModuleLogger("readmodel")
+
+var (
+	storeMu sync.RWMutex
+	store   *Store
+)
+
+// SetStore makes callers set up by InstallHook serve resolve queries from s. Nil disables the read model.
+func SetStore(s *Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() *Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// InstallHook makes the caller answer resolve queries from the store set by SetStore when it has all the claims
+// requested and has caught up with the blockchain within ReadModel.MaxLag.
+func InstallHook(c *query.Caller) {
+	s := getStore()
+	if s == nil {
+		return
+	}
+	maxLag := config.GetReadModel().MaxLag
+	c.AddPreflightHook(query.MethodResolve, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		q := hctx.Query
+		urls, ok := resolvedURLs(q)
+		if !ok {
+			return nil, nil
+		}
+		if lag, ok := s.Lag(time.Now()); !ok || lag > maxLag {
+			metrics.ReadModelLookups.WithLabelValues(outcomeStale).Inc()
+			return nil, nil
+		}
+		result := make(map[string]interface{}, len(urls))
+		for _, u := range urls {
+			claim, ok := s.Get(u)
+			if !ok {
+				metrics.ReadModelLookups.WithLabelValues(outcomeMiss).Inc()
+				return nil, nil
+			}
+			result[u] = claim
+		}
+		metrics.ReadModelLookups.WithLabelValues(outcomeHit).Inc()
+		hctx.AddLogField("read_model", true)
+		return &jsonrpc.RPCResponse{JSONRPC: q.Request.JSONRPC, ID: q.Request.ID, Result: result}, nil
+	}, hookName)
+}
+
+// resolvedURLs returns URLs of an anonymous resolve query which the store can answer. Any params other than urls
+// might change the result, so queries enabling them are left to the SDK.
+func resolvedURLs(q *query.Query) ([]string, bool) {
+	if q.IsAuthenticated() {
+		return nil, false
+	}
+	params := q.ParamsAsMap()
+	if params == nil {
+		return nil, false
+	}
+	var urls []string
+	for k, v := range params {
+		if k == "urls" {
+			continue
+		}
+		if b, ok := v.(bool); !ok || b {
+			return nil, false
+		}
+	}
+	switch v := params["urls"].(type) {
+	case string:
+		urls = []string{v}
+	case []interface{}:
+		for _, u := range v {
+			s, ok := u.(string)
+			if !ok {
+				return nil, false
+			}
+			urls = append(urls, s)
+		}
+	}
+	return urls, len(urls) > 0
+}
@@ -0,0 +1,188 @@
+package readmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/test"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func claim(name, id string, height int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":          name,
+		"claim_id":      id,
+		"permanent_url": "lbry://" + name + "#" + id,
+		"height":        float64(height),
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(2)
+	assert.Equal(t, 2, s.Put([]map[string]interface{}{claim("one", "aa", 1), claim("two", "bb", 2), {"name": "broken"}}))
+	c, ok := s.Get("lbry://one#aa")
+	require.True(t, ok)
+	assert.Equal(t, "one", c["name"])
+
+	// Updated claim replaces the earlier version, renamed ones are not found by their old URLs
+	s.Put([]map[string]interface{}{claim("uno", "aa", 3)})
+	_, ok = s.Get("lbry://one#aa")
+	assert.False(t, ok)
+	_, ok = s.Get("lbry://uno#aa")
+	assert.True(t, ok)
+	assert.Equal(t, 2, s.Len())
+
+	// Claims stored earliest are dropped
+	s.Put([]map[string]interface{}{claim("three", "cc", 4)})
+	assert.Equal(t, 2, s.Len())
+	_, ok = s.Get("lbry://two#bb")
+	assert.False(t, ok)
+
+	now := time.Now()
+	_, ok = s.Lag(now)
+	assert.False(t, ok)
+	s.MarkSynced(4, now.Add(-time.Minute))
+	lag, ok := s.Lag(now)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, lag)
+	assert.Equal(t, 4, s.Height())
+}
+
+type sdkMock struct {
+	claims   []map[string]interface{}
+	requests []map[string]interface{}
+}
+
+func (m *sdkMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Params map[string]interface{} `json:"params"`
+	}
+	json.Unmarshal(body, &req)
+	m.requests = append(m.requests, req.Params)
+
+	var items []map[string]interface{}
+	page, _ := req.Params["page"].(float64)
+	pageSize := int(req.Params["page_size"].(float64))
+	if h, ok := req.Params["height"].(string); ok {
+		var min float64
+		json.Unmarshal([]byte(strings.TrimPrefix(h, ">=")), &min)
+		for _, c := range m.claims {
+			if c["height"].(float64) >= min {
+				items = append(items, c)
+			}
+		}
+		start := (int(page) - 1) * pageSize
+		if start > len(items) {
+			start = len(items)
+		}
+		end := start + pageSize
+		if end > len(items) {
+			end = len(items)
+		}
+		items = items[start:end]
+	} else {
+		items = m.claims[len(m.claims)-1:]
+	}
+	res, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 0, "result": map[string]interface{}{"items": items}})
+	w.Write(res)
+}
+
+func TestSync(t *testing.T) {
+	mock := &sdkMock{}
+	for i := 1; i <= 10; i++ {
+		mock.claims = append(mock.claims, claim("c", fmt.Sprintf("%02x", i), 100+i))
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	store := NewStore(100)
+	rt := sdkrouter.NewWithServers(&models.LbrynetServer{Address: srv.URL})
+	s := NewSyncer(store, rt, config.ReadModel{Backfill: 5, PageSize: 2, MaxPages: 2})
+
+	// Backlog doesn't fit into one round
+	require.NoError(t, s.Sync())
+	assert.Equal(t, ">=105", mock.requests[1]["height"])
+	assert.Equal(t, 4, store.Len())
+	_, ok := store.Lag(time.Now())
+	assert.False(t, ok)
+
+	require.NoError(t, s.Sync())
+	assert.Equal(t, 6, store.Len())
+	_, ok = store.Lag(time.Now())
+	assert.True(t, ok)
+	assert.Equal(t, 110, store.Height())
+
+	mock.claims = append(mock.claims, claim("new", "abcd", 111))
+	require.NoError(t, s.Sync())
+	assert.Equal(t, ">=110", mock.requests[len(mock.requests)-1]["height"])
+	_, ok = store.Get("lbry://new#abcd")
+	assert.True(t, ok)
+}
+
+func TestInstallHook(t *testing.T) {
+	reqChan := test.ReqChan()
+	srv := test.MockHTTPServer(reqChan)
+	defer srv.Close()
+
+	s := NewStore(10)
+	s.Put([]map[string]interface{}{claim("one", "aa", 1), claim("two", "bb", 2)})
+	SetStore(s)
+	defer SetStore(nil)
+	config.Override("ReadModel", map[string]interface{}{"MaxLag": "1m"})
+	defer config.RestoreOverridden()
+
+	c := query.NewCaller(srv.URL, 0)
+	InstallHook(c)
+
+	// Store hasn't caught up yet
+	hits := metrics.GetCounterValue(metrics.ReadModelLookups.WithLabelValues(outcomeHit))
+	stale := metrics.GetCounterValue(metrics.ReadModelLookups.WithLabelValues(outcomeStale))
+	go func() {
+		req := <-reqChan
+		srv.NextResponse <- `{"jsonrpc": "2.0", "id": 0, "result": {}}`
+		assert.Contains(t, req.Body, "resolve")
+	}()
+	_, err := c.Call(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{"urls": "lbry://one#aa"}))
+	require.NoError(t, err)
+	assert.Equal(t, stale+1, metrics.GetCounterValue(metrics.ReadModelLookups.WithLabelValues(outcomeStale)))
+
+	s.MarkSynced(2, time.Now())
+	res, err := c.Call(jsonrpc.NewRequest(query.MethodResolve, map[string]interface{}{
+		"urls": []interface{}{"one#aa", "lbry://two:bb"}, "include_purchase_receipt": false,
+	}))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	result := res.Result.(map[string]interface{})
+	assert.Equal(t, "one", result["one#aa"].(map[string]interface{})["name"])
+	assert.Equal(t, "two", result["lbry://two:bb"].(map[string]interface{})["name"])
+	assert.Equal(t, hits+1, metrics.GetCounterValue(metrics.ReadModelLookups.WithLabelValues(outcomeHit)))
+
+	// Claims missing from the store and extra params are left to the SDK
+	for _, params := range []map[string]interface{}{
+		{"urls": []interface{}{"lbry://one#aa", "lbry://three#cc"}},
+		{"urls": "lbry://one#aa", "include_is_my_output": true},
+	} {
+		go func() {
+			<-reqChan
+			srv.NextResponse <- `{"jsonrpc": "2.0", "id": 0, "result": {}}`
+		}()
+		_, err = c.Call(jsonrpc.NewRequest(query.MethodResolve, params))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, hits+1, metrics.GetCounterValue(metrics.ReadModelLookups.WithLabelValues(outcomeHit)))
+}
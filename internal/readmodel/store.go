@@ -0,0 +1,122 @@
+package readmodel
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+)
+
+type entry struct {
+	claimID string
+	url     string
+	claim   map[string]interface{}
+}
+
+// Store keeps claims by their permanent URLs, dropping the ones stored earliest once it's full.
+// Claims are shared by all responses served from the store and must not be modified.
+type Store struct {
+	maxEntries int
+
+	mu       sync.RWMutex
+	byURL    map[string]*list.Element
+	byID     map[string]*list.Element
+	order    *list.List
+	syncedAt time.Time
+	height   int
+}
+
+// NewStore creates a store holding up to maxEntries claims.
+func NewStore(maxEntries int) *Store {
+	return &Store{
+		maxEntries: maxEntries,
+		byURL:      map[string]*list.Element{},
+		byID:       map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// Get returns the claim with permanent URL url, which must be in canonical form (see query.NormalizeURI).
+func (s *Store) Get(url string) (map[string]interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	el, ok := s.byURL[url]
+	if !ok {
+		return nil, false
+	}
+	return el.Value.(*entry).claim, true
+}
+
+// Put stores claims as returned by claim_search, replacing earlier versions of the same claims.
+// Claims without a claim ID or a valid permanent URL are skipped. It returns the number of stored claims.
+func (s *Store) Put(claims []map[string]interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, c := range claims {
+		id, _ := c["claim_id"].(string)
+		raw, _ := c["permanent_url"].(string)
+		if id == "" || raw == "" {
+			continue
+		}
+		url, err := query.NormalizeURI(raw)
+		if err != nil {
+			continue
+		}
+		if el, ok := s.byID[id]; ok {
+			s.remove(el)
+		}
+		if el, ok := s.byURL[url]; ok {
+			s.remove(el)
+		}
+		el := s.order.PushBack(&entry{claimID: id, url: url, claim: c})
+		s.byID[id] = el
+		s.byURL[url] = el
+		n++
+	}
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		s.remove(s.order.Front())
+	}
+	return n
+}
+
+func (s *Store) remove(el *list.Element) {
+	e := el.Value.(*entry)
+	delete(s.byID, e.claimID)
+	delete(s.byURL, e.url)
+	s.order.Remove(el)
+}
+
+// Len returns the number of claims in the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.order.Len()
+}
+
+// MarkSynced records that the store has caught up with the blockchain up to height at t.
+func (s *Store) MarkSynced(height int, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.height = height
+	s.syncedAt = t
+}
+
+// Height returns the block height the store has last caught up with, zero if it hasn't yet.
+func (s *Store) Height() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.height
+}
+
+// Lag returns how long ago the store has last caught up with the blockchain.
+// It returns false if the store hasn't caught up yet.
+func (s *Store) Lag(now time.Time) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.syncedAt.IsZero() {
+		return 0, false
+	}
+	return now.Sub(s.syncedAt), true
+}
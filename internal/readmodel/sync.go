@@ -0,0 +1,121 @@
+package readmodel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+// Syncer keeps the store up to date by fetching claims made or updated in recent blocks from the SDK.
+type Syncer struct {
+	store *Store
+	rt    *sdkrouter.Router
+	cfg   config.ReadModel
+	// height is the block claims are fetched from in the next round
+	height int
+}
+
+// NewSyncer creates a syncer filling store with claims from SDK servers of rt.
+func NewSyncer(store *Store, rt *sdkrouter.Router, cfg config.ReadModel) *Syncer {
+	return &Syncer{store: store, rt: rt, cfg: cfg}
+}
+
+// Run keeps syncing the store every cfg.Interval.
+func (s *Syncer) Run() {
+	t := time.NewTicker(s.cfg.Interval)
+	defer t.Stop()
+	for {
+		if err := s.Sync(); err != nil {
+			logger.Log().Errorf("read model sync failed: %v", err)
+		}
+		if lag, ok := s.store.Lag(time.Now()); ok {
+			metrics.ReadModelLag.Set(lag.Seconds())
+		}
+		metrics.ReadModelClaims.Set(float64(s.store.Len()))
+		<-t.C
+	}
+}
+
+// Sync fetches claims from blocks since the last sync, up to cfg.MaxPages pages of them.
+// The first sync starts cfg.Backfill blocks behind the current height. The store is marked as caught up
+// once there are no more claims to fetch, otherwise the next round continues where this one has stopped.
+func (s *Syncer) Sync() error {
+	op := metrics.StartOperation("readmodel", "sync")
+	defer op.End()
+
+	c := query.NewCaller(s.rt.RandomServer().Address, 0)
+	if s.height == 0 {
+		items, err := claimSearch(c, map[string]interface{}{"order_by": []string{"height"}, "page_size": 1, "no_totals": true})
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return errors.Err("cannot determine current height")
+		}
+		s.height = claimHeight(items[0]) - s.cfg.Backfill
+		if s.height < 1 {
+			s.height = 1
+		}
+	}
+
+	maxHeight := s.height
+	for page := 1; page <= s.cfg.MaxPages; page++ {
+		items, err := claimSearch(c, map[string]interface{}{
+			"height":    fmt.Sprintf(">=%d", s.height),
+			"order_by":  []string{"^height"},
+			"page":      page,
+			"page_size": s.cfg.PageSize,
+			"no_totals": true,
+		})
+		if err != nil {
+			return err
+		}
+		s.store.Put(items)
+		for _, i := range items {
+			if h := claimHeight(i); h > maxHeight {
+				maxHeight = h
+			}
+		}
+		if len(items) < s.cfg.PageSize {
+			s.height = maxHeight
+			s.store.MarkSynced(maxHeight, time.Now())
+			return nil
+		}
+	}
+	// Claims of the last block seen might have not all been fetched, so it's fetched again next time
+	s.height = maxHeight
+	return nil
+}
+
+func claimSearch(c *query.Caller, params map[string]interface{}) ([]map[string]interface{}, error) {
+	q, err := query.NewQuery(jsonrpc.NewRequest(query.MethodClaimSearch, params), "")
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.SendQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.Err(res.Error.Message)
+	}
+	var page struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := res.GetObject(&page); err != nil {
+		return nil, errors.Err(err)
+	}
+	return page.Items, nil
+}
+
+func claimHeight(claim map[string]interface{}) int {
+	h, _ := claim["height"].(float64)
+	return int(h)
+}
@@ -0,0 +1,75 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/ybbus/jsonrpc"
+)
+
+// Envelope is an alternative response format for clients which don't want to deal with JSON-RPC.
+// JSON-RPC result goes into Data, JSON-RPC error into Error.
+type Envelope struct {
+	Success bool           `json:"success"`
+	Data    interface{}    `json:"data"`
+	Error   *EnvelopeError `json:"error,omitempty"`
+}
+
+// EnvelopeError is a JSON-RPC error translated into the envelope.
+type EnvelopeError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// NewEnvelope translates JSON-RPC response into an envelope.
+func NewEnvelope(r *jsonrpc.RPCResponse) *Envelope {
+	if r.Error != nil {
+		return &Envelope{Error: &EnvelopeError{Code: r.Error.Code, Message: r.Error.Message, Data: r.Error.Data}}
+	}
+	return &Envelope{Success: true, Data: r.Result}
+}
+
+// EnvelopeSerialize marshals JSON-RPC response wrapped in an envelope for sending it to the client.
+// Numbers are preserved the same way as in JSONRPCSerialize.
+func EnvelopeSerialize(r *jsonrpc.RPCResponse) ([]byte, error) {
+	var e error
+	defer errors.Recover(&e)
+	b, err := json.MarshalIndent(NewEnvelope(r), "", "  ")
+	if e != nil {
+		return b, e
+	}
+	return b, err
+}
+
+// EnvelopeWriter translates serialized JSON-RPC responses written to it into envelopes.
+// Every Write call is expected to carry a complete response, anything which is not a JSON-RPC response
+// is written as is.
+type EnvelopeWriter struct {
+	http.ResponseWriter
+}
+
+// NewEnvelopeWriter wraps w so JSON-RPC responses get translated into envelopes.
+func NewEnvelopeWriter(w http.ResponseWriter) http.ResponseWriter {
+	if _, ok := w.(*EnvelopeWriter); ok {
+		return w
+	}
+	return &EnvelopeWriter{w}
+}
+
+func (w *EnvelopeWriter) Write(b []byte) (int, error) {
+	var res *jsonrpc.RPCResponse
+	if err := DecodeJSON(bytes.NewReader(b), &res); err != nil || res == nil || (res.Result == nil && res.Error == nil && res.JSONRPC == "") {
+		return w.ResponseWriter.Write(b)
+	}
+	eb, err := EnvelopeSerialize(res)
+	if err != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	if _, err := w.ResponseWriter.Write(eb); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
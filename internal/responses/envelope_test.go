@@ -0,0 +1,37 @@
+package responses
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestEnvelopeSerialize(t *testing.T) {
+	b, err := EnvelopeSerialize(&jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"available": "1.0"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success": true, "data": {"available": "1.0"}}`, string(b))
+
+	b, err = EnvelopeSerialize(&jsonrpc.RPCResponse{JSONRPC: "2.0", Error: &jsonrpc.RPCError{Code: -32085, Message: "forbidden", Data: map[string]interface{}{"a": 1}}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success": false, "data": null, "error": {"code": -32085, "message": "forbidden", "data": {"a": 1}}}`, string(b))
+}
+
+func TestEnvelopeWriter(t *testing.T) {
+	rr := httptest.NewRecorder()
+	w := NewEnvelopeWriter(rr)
+	assert.Same(t, w, NewEnvelopeWriter(w))
+
+	raw := []byte(`{"jsonrpc": "2.0", "result": {"dewies": 9007199254740993}, "id": 0}`)
+	n, err := w.Write(raw)
+	require.NoError(t, err)
+	assert.Equal(t, len(raw), n)
+	assert.JSONEq(t, `{"success": true, "data": {"dewies": 9007199254740993}}`, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "9007199254740993")
+
+	rr = httptest.NewRecorder()
+	NewEnvelopeWriter(rr).Write([]byte("not json"))
+	assert.Equal(t, "not json", rr.Body.String())
+}
@@ -1,9 +1,13 @@
 package responses
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
 	"github.com/lbryio/lbrytv/internal/errors"
 	"github.com/ybbus/jsonrpc"
 )
@@ -17,6 +21,74 @@ func AddJSONContentType(w http.ResponseWriter) {
 	w.Header().Add("content-type", "application/json; charset=utf-8")
 }
 
+// DecodeJSON decodes a single JSON value from the reader into v.
+// Unless disabled by config, numbers are decoded as json.Number so that large integers
+// (like amounts in dewies) and high-precision decimals are passed through unchanged.
+func DecodeJSON(r io.Reader, v interface{}) error {
+	return decodeJSON(json.NewDecoder(r), v)
+}
+
+func decodeJSON(dec *json.Decoder, v interface{}) error {
+	if config.ShouldPreserveJSONNumbers() {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.Err("invalid character after top-level value")
+	}
+	return nil
+}
+
+// UnmarshalJSON is a drop-in replacement for json.Unmarshal which decodes numbers the same way as DecodeJSON.
+func UnmarshalJSON(data []byte, v interface{}) error {
+	return DecodeJSON(bytes.NewReader(data), v)
+}
+
+// UnmarshalJSONStrict is like UnmarshalJSON but rejects fields of a top-level object which v has no place for
+// and keys repeated in it, which json.Unmarshal would silently resolve to the last value.
+// Keys differing only in case count as repeated, as they land in the same struct field. Nested values are not checked.
+func UnmarshalJSONStrict(data []byte, v interface{}) error {
+	if err := checkDuplicateKeys(data); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return decodeJSON(dec, v)
+}
+
+// checkDuplicateKeys returns an error if data is an object with a key repeated. Anything else is left to the decoder.
+func checkDuplicateKeys(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+	seen := map[string]bool{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil
+		}
+		if seen[strings.ToLower(key)] {
+			return errors.Err("duplicate key %q", key)
+		}
+		seen[strings.ToLower(key)] = true
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// JSONRPCSerialize marshals JSON-RPC response for sending it to the client.
+// Numbers in the result should be json.Number (as decoded by the SDK client or DecodeJSON)
+// for their precision to be preserved.
 func JSONRPCSerialize(r *jsonrpc.RPCResponse) ([]byte, error) {
 	var (
 		b []byte
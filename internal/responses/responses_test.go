@@ -0,0 +1,96 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestUnmarshalJSONPreservesNumbers(t *testing.T) {
+	raw := `{"jsonrpc": "2.0", "method": "wallet_send", "params": {"amount": 123456789012345678901234567890, "fee": 0.000000000000000001}, "id": 1}`
+
+	var req *jsonrpc.RPCRequest
+	require.NoError(t, UnmarshalJSON([]byte(raw), &req))
+	params := req.Params.(map[string]interface{})
+	assert.Equal(t, json.Number("123456789012345678901234567890"), params["amount"])
+	assert.Equal(t, json.Number("0.000000000000000001"), params["fee"])
+	assert.Equal(t, 1, req.ID)
+
+	reencoded, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.Contains(t, string(reencoded), `"amount":123456789012345678901234567890`)
+	assert.Contains(t, string(reencoded), `"fee":0.000000000000000001`)
+}
+
+func TestUnmarshalJSONNumbersDisabled(t *testing.T) {
+	config.Override("PreserveJSONNumbers", false)
+	defer config.RestoreOverridden()
+
+	var v map[string]interface{}
+	require.NoError(t, UnmarshalJSON([]byte(`{"amount": 9007199254740993}`), &v))
+	assert.Equal(t, float64(9007199254740992), v["amount"])
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	var v map[string]interface{}
+	err := UnmarshalJSON([]byte("yo"), &v)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid character 'y' looking for beginning of value")
+
+	err = UnmarshalJSON([]byte(`{"a": 1} {"b": 2}`), &v)
+	assert.Error(t, err)
+
+	assert.NoError(t, UnmarshalJSON([]byte("{\"a\": 1}\n"), &v))
+}
+
+func TestUnmarshalJSONStrict(t *testing.T) {
+	cases := []struct {
+		name, raw, err string
+	}{
+		{"Valid", `{"jsonrpc": "2.0", "method": "resolve", "params": {"urls": "x", "extra": {"a": 1, "a": 2}}, "id": 1}`, ""},
+		{"DuplicateKey", `{"jsonrpc": "2.0", "method": "resolve", "method": "wallet_send", "id": 1}`, `duplicate key "method"`},
+		{"DuplicateKeyCase", `{"jsonrpc": "2.0", "method": "resolve", "Method": "wallet_send", "id": 1}`, `duplicate key "Method"`},
+		{"UnknownField", `{"jsonrpc": "2.0", "method": "resolve", "wallet": "x", "id": 1}`, `unknown field "wallet"`},
+		{"Malformed", `{"jsonrpc": "2.0", "method": `, "unexpected EOF"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var req *jsonrpc.RPCRequest
+			err := UnmarshalJSONStrict([]byte(c.raw), &req)
+			if c.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "resolve", req.Method)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.err)
+			}
+		})
+	}
+
+	// Lenient decoding takes the last value and ignores unknown fields
+	var req *jsonrpc.RPCRequest
+	require.NoError(t, UnmarshalJSON([]byte(`{"method": "resolve", "method": "wallet_send", "wallet": "x"}`), &req))
+	assert.Equal(t, "wallet_send", req.Method)
+
+	// Non-objects are left to the decoder
+	var list []interface{}
+	require.NoError(t, UnmarshalJSONStrict([]byte(`[1, 1]`), &list))
+}
+
+func TestJSONRPCSerializePreservesNumbers(t *testing.T) {
+	raw := `{"jsonrpc": "2.0", "result": {"total": 98765432109876543210, "available": "1.000000000000000001", "dewies": 9007199254740993}, "id": 0}`
+	var res *jsonrpc.RPCResponse
+	require.NoError(t, DecodeJSON(bytes.NewBufferString(raw), &res))
+
+	b, err := JSONRPCSerialize(res)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"total": 98765432109876543210`)
+	assert.Contains(t, string(b), `"available": "1.000000000000000001"`)
+	assert.Contains(t, string(b), `"dewies": 9007199254740993`)
+}
@@ -0,0 +1,178 @@
+package responses
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/ybbus/jsonrpc"
+)
+
+// streamBufferSize is how much of a streamed response is accumulated before it's sent to the client.
+const streamBufferSize = 32 << 10
+
+type streamField struct {
+	name  string
+	value interface{}
+}
+
+// countingWriter keeps track of how many bytes have reached the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// IsLarge returns true if the response result contains more than threshold values (counting nested ones),
+// which makes it worth streaming. Threshold of zero or less means no response is large.
+func IsLarge(r *jsonrpc.RPCResponse, threshold int) bool {
+	if threshold <= 0 || r == nil {
+		return false
+	}
+	n := 0
+	return countValues(r.Result, &n, threshold)
+}
+
+func countValues(v interface{}, n *int, threshold int) bool {
+	*n++
+	if *n > threshold {
+		return true
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, i := range v {
+			if countValues(i, n, threshold) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, i := range v {
+			if countValues(i, n, threshold) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Unwrapper is implemented by writers wrapping another one, so StreamJSONRPC can find an EnvelopeWriter under them.
+type Unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// findEnvelopeWriter returns the EnvelopeWriter w is or wraps, or nil if there's none.
+func findEnvelopeWriter(w http.ResponseWriter) *EnvelopeWriter {
+	for {
+		switch v := w.(type) {
+		case *EnvelopeWriter:
+			return v
+		case Unwrapper:
+			w = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// StreamJSONRPC encodes JSON-RPC response directly into w instead of building it in memory first,
+// writers created by NewEnvelopeWriter get it in envelope format, even when wrapped by an Unwrapper.
+// The envelope is then written past the writers wrapping it, as they expect JSON-RPC.
+// Output is compact, unlike JSONRPCSerialize.
+// If encoding fails, flushed tells if any part of the response has already been sent to the client,
+// in which case it's too late to respond with an error.
+func StreamJSONRPC(w http.ResponseWriter, r *jsonrpc.RPCResponse) (flushed bool, err error) {
+	if w.Header().Get("content-type") == "" {
+		AddJSONContentType(w)
+	}
+
+	var fields []streamField
+	if ew := findEnvelopeWriter(w); ew != nil {
+		w = ew.ResponseWriter
+		env := NewEnvelope(r)
+		fields = []streamField{{"success", env.Success}, {"data", env.Data}}
+		if env.Error != nil {
+			fields = append(fields, streamField{"error", env.Error})
+		}
+	} else {
+		fields = []streamField{{"jsonrpc", r.JSONRPC}}
+		if r.Result != nil {
+			fields = append(fields, streamField{"result", r.Result})
+		}
+		if r.Error != nil {
+			fields = append(fields, streamField{"error", r.Error})
+		}
+		fields = append(fields, streamField{"id", r.ID})
+	}
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriterSize(cw, streamBufferSize)
+	err = func() (e error) {
+		defer errors.Recover(&e)
+		if err := writeObject(bw, fields); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}()
+	return cw.n > 0, err
+}
+
+func writeObject(w *bufio.Writer, fields []streamField) error {
+	w.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := writeValue(w, f.name); err != nil {
+			return err
+		}
+		w.WriteByte(':')
+		if err := writeValue(w, f.value); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// writeValue descends into maps and slices so only their leaves are ever marshaled as a whole.
+// Map keys are sorted the same way json.Marshal does it.
+func writeValue(w *bufio.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]streamField, len(keys))
+		for i, k := range keys {
+			fields[i] = streamField{k, v[k]}
+		}
+		return writeObject(w, fields)
+	case []interface{}:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeValue(w, item); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte(']')
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+}
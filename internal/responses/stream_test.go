@@ -0,0 +1,99 @@
+package responses
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+func TestStreamJSONRPC(t *testing.T) {
+	res := &jsonrpc.RPCResponse{
+		JSONRPC: "2.0",
+		ID:      1,
+		Result: map[string]interface{}{
+			"items":  []interface{}{map[string]interface{}{"name": "<what>", "amount": json.Number("123456789012345678901234567890")}, "b", nil},
+			"total":  json.Number("3"),
+			"nested": map[string]interface{}{"z": true, "a": []interface{}{}},
+		},
+	}
+	expected, err := json.Marshal(res)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	flushed, err := StreamJSONRPC(rr, res)
+	require.NoError(t, err)
+	assert.True(t, flushed)
+	assert.Equal(t, string(expected), rr.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("content-type"))
+
+	rr = httptest.NewRecorder()
+	errRes := &jsonrpc.RPCResponse{JSONRPC: "2.0", Error: &jsonrpc.RPCError{Code: -32000, Message: "oops"}}
+	_, err = StreamJSONRPC(rr, errRes)
+	require.NoError(t, err)
+	expected, _ = json.Marshal(errRes)
+	assert.Equal(t, string(expected), rr.Body.String())
+}
+
+func TestStreamJSONRPCEnvelope(t *testing.T) {
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"items": []interface{}{"a"}}}
+	expected, err := json.Marshal(NewEnvelope(res))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	_, err = StreamJSONRPC(NewEnvelopeWriter(rr), res)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), rr.Body.String())
+}
+
+type wrappingWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func TestStreamJSONRPCWrappedEnvelope(t *testing.T) {
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"items": []interface{}{"a"}}}
+	expected, err := json.Marshal(NewEnvelope(res))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	_, err = StreamJSONRPC(wrappingWriter{wrappingWriter{NewEnvelopeWriter(rr)}}, res)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	_, err = StreamJSONRPC(wrappingWriter{rr}, res)
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","result":{"items":["a"]},"id":0}`, rr.Body.String())
+}
+
+func TestStreamJSONRPCErrors(t *testing.T) {
+	rr := httptest.NewRecorder()
+	flushed, err := StreamJSONRPC(rr, &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: map[string]interface{}{"bad": math.Inf(1)}})
+	assert.Error(t, err)
+	assert.False(t, flushed)
+	assert.Empty(t, rr.Body.String())
+
+	// Fails after the buffer has been sent
+	rr = httptest.NewRecorder()
+	big := strings.Repeat("x", streamBufferSize*2)
+	flushed, err = StreamJSONRPC(rr, &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: []interface{}{big, math.Inf(1)}})
+	assert.Error(t, err)
+	assert.True(t, flushed)
+	assert.NotEmpty(t, rr.Body.String())
+}
+
+func TestIsLarge(t *testing.T) {
+	res := &jsonrpc.RPCResponse{Result: map[string]interface{}{"items": []interface{}{1, 2, map[string]interface{}{"a": 1}}}}
+	assert.False(t, IsLarge(res, 0))
+	assert.False(t, IsLarge(res, 6))
+	assert.True(t, IsLarge(res, 5))
+	assert.False(t, IsLarge(nil, 5))
+}
@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/internal/errors"
+)
+
+// SetDefault sets param to value if the query doesn't have it and returns true if it did.
+// Positional params are left alone as there's no telling which of them are omitted.
+func SetDefault(q *query.Query, param string, value interface{}) bool {
+	if q.Params() == nil {
+		q.Request.Params = map[string]interface{}{}
+	}
+	params := q.ParamsAsMap()
+	if params == nil {
+		return false
+	}
+	if _, ok := params[param]; ok {
+		return false
+	}
+	params[param] = value
+	return true
+}
+
+// Clamp keeps numeric param between min and max, either of which may be nil. It returns true if the param was changed.
+// Missing and non-numeric params are left alone.
+func Clamp(q *query.Query, param string, min, max *float64) bool {
+	params := q.ParamsAsMap()
+	v, ok := toFloat(params[param])
+	if !ok {
+		return false
+	}
+	clamped := v
+	if min != nil {
+		clamped = math.Max(clamped, *min)
+	}
+	if max != nil {
+		clamped = math.Min(clamped, *max)
+	}
+	if clamped == v {
+		return false
+	}
+	if clamped == math.Trunc(clamped) {
+		params[param] = int64(clamped)
+	} else {
+		params[param] = clamped
+	}
+	return true
+}
+
+// RewriteMethod sends the query to another method. Only methods with the same wallet requirements
+// are interchangeable, otherwise the query would skip authentication checks made for the target method.
+func RewriteMethod(q *query.Query, method string) error {
+	from := q.Method()
+	if query.MethodRequiresWallet(from, nil) != query.MethodRequiresWallet(method, nil) ||
+		query.MethodAcceptsWallet(from) != query.MethodAcceptsWallet(method) {
+		return errors.Err("cannot rewrite %v to %v, they differ in wallet requirements", from, method)
+	}
+	q.Request.Method = method
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
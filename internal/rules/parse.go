@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+
+	"github.com/spf13/cast"
+)
+
+// ruleKeys are the keys a rule may have, along with actions they are required for.
+var ruleKeys = map[string][]string{
+	"name":    nil,
+	"method":  nil,
+	"params":  nil,
+	"present": nil,
+	"absent":  nil,
+	"action":  nil,
+	"param":   {ActionDefault, ActionClamp},
+	"value":   {ActionDefault},
+	"min":     nil,
+	"max":     nil,
+	"message": nil,
+	"to":      {ActionRewrite},
+}
+
+// Parse validates a list of rules decoded from a YAML or JSON file and converts them to Rule values.
+// Rules must have unique names, a known action and the fields that action needs, unknown fields are not allowed.
+func Parse(raw interface{}) ([]Rule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.Err("rules must be a list")
+	}
+	rules := make([]Rule, 0, len(list))
+	names := map[string]bool{}
+	for i, item := range list {
+		r, err := parseRule(item)
+		if err != nil {
+			return nil, errors.Err("rule #%v: %v", i+1, err)
+		}
+		if names[r.Name] {
+			return nil, errors.Err("rule #%v: duplicate name %v", i+1, r.Name)
+		}
+		names[r.Name] = true
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseRule(item interface{}) (Rule, error) {
+	var r Rule
+	fields, err := cast.ToStringMapE(normalize(item))
+	if err != nil {
+		return r, fmt.Errorf("rule must be a map")
+	}
+	for k := range fields {
+		if _, ok := ruleKeys[k]; !ok {
+			return r, fmt.Errorf("unknown field %v", k)
+		}
+	}
+
+	if r.Name, err = stringField(fields, "name"); err != nil {
+		return r, err
+	}
+	if r.Name == "" {
+		return r, fmt.Errorf("name is required")
+	}
+	if r.Action, err = stringField(fields, "action"); err != nil {
+		return r, err
+	}
+	switch r.Action {
+	case ActionDefault, ActionClamp, ActionReject, ActionRewrite:
+	default:
+		return r, fmt.Errorf("unknown action %q", r.Action)
+	}
+	for k, actions := range ruleKeys {
+		if _, ok := fields[k]; !ok && inList(r.Action, actions) {
+			return r, fmt.Errorf("%v is required for %v action", k, r.Action)
+		}
+	}
+
+	for k, dst := range map[string]*string{"method": &r.Method, "param": &r.Param, "message": &r.Message, "to": &r.To} {
+		if *dst, err = stringField(fields, k); err != nil {
+			return r, err
+		}
+	}
+	for k, dst := range map[string]*[]string{"present": &r.Present, "absent": &r.Absent} {
+		if v, ok := fields[k]; ok {
+			if *dst, err = cast.ToStringSliceE(v); err != nil {
+				return r, fmt.Errorf("%v must be a list of param names", k)
+			}
+		}
+	}
+	if v, ok := fields["params"]; ok {
+		if r.Params, err = cast.ToStringMapE(v); err != nil {
+			return r, fmt.Errorf("params must be a map")
+		}
+	}
+	r.Value = fields["value"]
+	for k, dst := range map[string]**float64{"min": &r.Min, "max": &r.Max} {
+		if v, ok := fields[k]; ok {
+			f, err := cast.ToFloat64E(v)
+			if err != nil {
+				return r, fmt.Errorf("%v must be a number", k)
+			}
+			*dst = &f
+		}
+	}
+
+	switch r.Action {
+	case ActionClamp:
+		if r.Min == nil && r.Max == nil {
+			return r, fmt.Errorf("min or max is required for clamp action")
+		}
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return r, fmt.Errorf("min is greater than max")
+		}
+	case ActionRewrite:
+		if r.To == "" {
+			return r, fmt.Errorf("to is required for rewrite action")
+		}
+	}
+	return r, nil
+}
+
+func stringField(fields map[string]interface{}, key string) (string, error) {
+	v, ok := fields[key]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%v must be a string", key)
+	}
+	return s, nil
+}
+
+// normalize converts maps decoded from YAML to maps with lowercase string keys at the top level
+// and string keys below it, so values can be sent to the SDK as JSON.
+func normalize(v interface{}) interface{} {
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return v
+	}
+	n := make(map[string]interface{}, len(m))
+	for k, i := range m {
+		n[strings.ToLower(k)] = normalizeValue(i)
+	}
+	return n
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch i := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(i))
+		for k, e := range i {
+			m[fmt.Sprint(k)] = normalizeValue(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(i))
+		for k, e := range i {
+			m[k] = normalizeValue(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(i))
+		for j, e := range i {
+			l[j] = normalizeValue(e)
+		}
+		return l
+	}
+	return v
+}
+
+func inList(v string, list []string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Package rules applies an ordered pipeline of request transformations and validations loaded from a rules file,
+// so ops can adjust how queries are handled without code changes.
+//
+// Each rule matches queries by method and params and performs one action:
+//
+//	default: sets param to value unless it's supplied
+//	clamp:   keeps numeric param between min and max
+//	reject:  responds with an invalid params error
+//	rewrite: sends the query to another method
+//
+// Actions are also available as functions (SetDefault, Clamp, RewriteMethod) for use in code.
+package rules
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/ybbus/jsonrpc"
+)
+
+const hookName = "rules"
+
+// Rule actions.
+const (
+	ActionDefault = "default"
+	ActionClamp   = "clamp"
+	ActionReject  = "reject"
+	ActionRewrite = "rewrite"
+)
+
+const defaultRejectMessage = "request rejected"
+
+var logger = monitor.NewModuleLogger("rules")
+
+// Rule matches queries and transforms or rejects them.
+type Rule struct {
+	Name string
+	// Method matches queries of the method, empty matches all methods.
+	Method string
+	// Params match when query params are equal to the values.
+	Params map[string]interface{}
+	// Present and Absent list params which must be supplied or omitted.
+	Present []string
+	Absent  []string
+
+	Action string
+	// Param and Value are the param changed by default and clamp actions and the default value.
+	Param string
+	Value interface{}
+	// Min and Max are the bounds of clamp action, either can be omitted.
+	Min, Max *float64
+	// Message is the error message of reject action.
+	Message string
+	// To is the method the query is sent to by rewrite action.
+	To string
+}
+
+var current atomic.Value
+
+// Load reads rules from a YAML or JSON file and makes them effective if they are all valid.
+func Load(file string) error {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return errors.Err(err)
+	}
+	return load(v)
+}
+
+func load(v *viper.Viper) error {
+	rules, err := Parse(v.Get("rules"))
+	if err != nil {
+		return err
+	}
+	Set(rules)
+	logger.Log().Infof("loaded %v request rules", len(rules))
+	return nil
+}
+
+// Watch reloads rules when the file changes. A file which fails validation is ignored
+// and the rules loaded previously stay in effect.
+func Watch(file string) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		logger.Log().Errorf("cannot watch request rules: %v", err)
+		return
+	}
+	v.OnConfigChange(func(fsnotify.Event) {
+		if err := v.ReadInConfig(); err != nil {
+			logger.Log().Errorf("cannot reload request rules, keeping the previous ones: %v", err)
+			return
+		}
+		if err := load(v); err != nil {
+			logger.Log().Errorf("cannot reload request rules, keeping the previous ones: %v", err)
+		}
+	})
+	v.WatchConfig()
+}
+
+// Set replaces effective rules.
+func Set(rules []Rule) {
+	current.Store(rules)
+}
+
+// Get returns effective rules in the order they are applied.
+func Get() []Rule {
+	rules, _ := current.Load().([]Rule)
+	return rules
+}
+
+// InstallHook makes the caller apply effective rules to queries before builtin hooks,
+// so rewritten queries get the same treatment as the ones sent to the target method directly.
+func InstallHook(c *query.Caller) {
+	c.PrependPreflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		return Apply(Get(), hctx.Query), nil
+	}, hookName)
+}
+
+// Apply runs rules matching the query in order. It returns an error response if the query is rejected.
+func Apply(rules []Rule, q *query.Query) *jsonrpc.RPCResponse {
+	for _, r := range rules {
+		if !matches(r, q) {
+			continue
+		}
+		log := logger.WithFields(logrus.Fields{"rule": r.Name, "method": q.Method()})
+		applied := false
+		switch r.Action {
+		case ActionDefault:
+			applied = SetDefault(q, r.Param, r.Value)
+		case ActionClamp:
+			applied = Clamp(q, r.Param, r.Min, r.Max)
+		case ActionRewrite:
+			if err := RewriteMethod(q, r.To); err != nil {
+				log.Warnf("cannot rewrite query: %v", err)
+			} else {
+				applied = true
+			}
+		case ActionReject:
+			metrics.ProxyRequestRuleHits.WithLabelValues(r.Name, r.Action).Inc()
+			log.Info("query rejected by request rule")
+			msg := r.Message
+			if msg == "" {
+				msg = defaultRejectMessage
+			}
+			return &jsonrpc.RPCResponse{
+				JSONRPC: q.Request.JSONRPC,
+				ID:      q.Request.ID,
+				Error: &jsonrpc.RPCError{
+					Code:    rpcerrors.NewInvalidParamsError(nil).Code(),
+					Message: msg,
+				},
+			}
+		}
+		if applied {
+			metrics.ProxyRequestRuleHits.WithLabelValues(r.Name, r.Action).Inc()
+			log.Debug("request rule applied")
+		}
+	}
+	return nil
+}
+
+func matches(r Rule, q *query.Query) bool {
+	if r.Method != "" && r.Method != q.Method() {
+		return false
+	}
+	params := q.ParamsAsMap()
+	for name, expected := range r.Params {
+		v, ok := params[name]
+		if !ok || !equal(v, expected) {
+			return false
+		}
+	}
+	for _, name := range r.Present {
+		if _, ok := params[name]; !ok {
+			return false
+		}
+	}
+	for _, name := range r.Absent {
+		if _, ok := params[name]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares a query param with a value from the rules file, which may have decoded numbers differently.
+func equal(param, value interface{}) bool {
+	if p, ok := toFloat(param); ok {
+		v, ok := toFloat(value)
+		return ok && p == v
+	}
+	return reflect.DeepEqual(param, value)
+}
@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/rpcerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+const rulesYAML = `
+rules:
+  - name: search-default-order
+    method: claim_search
+    absent: [order_by]
+    action: default
+    param: order_by
+    value: [release_time]
+  - name: search-page-size
+    method: claim_search
+    action: clamp
+    param: page_size
+    min: 1
+    max: 50
+  - name: no-spam
+    method: claim_search
+    params: {channel: "@spam", any_tags: [a, b]}
+    action: reject
+    message: nope
+  - name: stream-claims
+    method: claim_list
+    params: {claim_type: stream}
+    action: rewrite
+    to: stream_list
+  - name: bad-rewrite
+    method: resolve
+    action: rewrite
+    to: wallet_send
+`
+
+func newQuery(t *testing.T, method string, params map[string]interface{}) *query.Query {
+	q, err := query.NewQuery(jsonrpc.NewRequest(method, params), "")
+	require.NoError(t, err)
+	return q
+}
+
+func TestLoadAndApply(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(rulesYAML), 0644))
+	require.NoError(t, Load(file))
+	defer Set(nil)
+	require.Len(t, Get(), 5)
+
+	q := newQuery(t, "claim_search", map[string]interface{}{"page_size": 500.0})
+	assert.Nil(t, Apply(Get(), q))
+	assert.Equal(t, map[string]interface{}{"page_size": int64(50), "order_by": []interface{}{"release_time"}}, q.ParamsAsMap())
+
+	q = newQuery(t, "claim_search", map[string]interface{}{"page_size": 0.5, "order_by": "name"})
+	assert.Nil(t, Apply(Get(), q))
+	assert.Equal(t, map[string]interface{}{"page_size": int64(1), "order_by": "name"}, q.ParamsAsMap())
+
+	q = newQuery(t, "claim_search", map[string]interface{}{"channel": "@spam", "any_tags": []interface{}{"a", "b"}})
+	res := Apply(Get(), q)
+	require.NotNil(t, res)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, "nope", res.Error.Message)
+	assert.Equal(t, rpcerrors.NewInvalidParamsError(nil).Code(), res.Error.Code)
+
+	q = newQuery(t, "claim_search", map[string]interface{}{"channel": "@spam", "any_tags": []interface{}{"a"}})
+	assert.Nil(t, Apply(Get(), q))
+
+	q, err := query.NewQuery(jsonrpc.NewRequest("claim_list", map[string]interface{}{"claim_type": "stream"}), "wallet")
+	require.NoError(t, err)
+	assert.Nil(t, Apply(Get(), q))
+	assert.Equal(t, "stream_list", q.Method())
+
+	// Rewrites changing wallet requirements are skipped
+	q = newQuery(t, "resolve", map[string]interface{}{"urls": "what"})
+	assert.Nil(t, Apply(Get(), q))
+	assert.Equal(t, "resolve", q.Method())
+}
+
+func TestLoadJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"rules": [
+		{"name": "limit", "method": "txo_list", "action": "clamp", "param": "page_size", "max": 20}
+	]}`), 0644))
+	require.NoError(t, Load(file))
+	defer Set(nil)
+	require.Len(t, Get(), 1)
+	assert.Equal(t, 20.0, *Get()[0].Max)
+	assert.Nil(t, Get()[0].Min)
+}
+
+func TestParseValidation(t *testing.T) {
+	cases := []struct {
+		name, rule, err string
+	}{
+		{"unknown field", `{"name": "a", "action": "reject", "color": "red"}`, "unknown field color"},
+		{"no name", `{"action": "reject"}`, "name is required"},
+		{"unknown action", `{"name": "a", "action": "explode"}`, `unknown action "explode"`},
+		{"default without value", `{"name": "a", "action": "default", "param": "x"}`, "value is required for default action"},
+		{"clamp without bounds", `{"name": "a", "action": "clamp", "param": "x"}`, "min or max is required"},
+		{"clamp bounds", `{"name": "a", "action": "clamp", "param": "x", "min": 5, "max": 1}`, "min is greater than max"},
+		{"clamp bound type", `{"name": "a", "action": "clamp", "param": "x", "min": "low"}`, "min must be a number"},
+		{"rewrite target", `{"name": "a", "action": "rewrite", "to": ""}`, "to is required"},
+		{"method type", `{"name": "a", "action": "reject", "method": 5}`, "method must be a string"},
+		{"absent type", `{"name": "a", "action": "reject", "absent": {"x": 1}}`, "absent must be a list"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.json")
+			require.NoError(t, ioutil.WriteFile(file, []byte(`{"rules": [`+c.rule+`]}`), 0644))
+			err := Load(file)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+
+	_, err := Parse([]interface{}{
+		map[string]interface{}{"name": "a", "action": "reject"},
+		map[string]interface{}{"name": "a", "action": "reject"},
+	})
+	assert.EqualError(t, err, "rule #2: duplicate name a")
+	_, err = Parse("rules")
+	assert.EqualError(t, err, "rules must be a list")
+
+	// Invalid file keeps the rules loaded before
+	Set([]Rule{{Name: "kept", Action: ActionReject}})
+	defer Set(nil)
+	file := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"rules": [{"name": "a"}]}`), 0644))
+	require.Error(t, Load(file))
+	assert.Equal(t, "kept", Get()[0].Name)
+}
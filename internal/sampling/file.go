@@ -0,0 +1,102 @@
+package sampling
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+)
+
+const (
+	filePrefix = "samples-"
+	fileSuffix = ".jsonl"
+)
+
+// FileSink writes samples to JSON lines files in a directory, starting a new file once the current one
+// reaches maxSize bytes and removing the oldest files so that no more than maxFiles are kept.
+type FileSink struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	now      func() time.Time
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink creates a sink writing samples to dir, which is created if missing.
+func NewFileSink(dir string, maxSize int64, maxFiles int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Err(err)
+	}
+	return &FileSink{dir: dir, maxSize: maxSize, maxFiles: maxFiles, now: time.Now}, nil
+}
+
+func (s *FileSink) Write(smp *Sample) error {
+	line, err := json.Marshal(smp)
+	if err != nil {
+		return errors.Err(err)
+	}
+	line = append(line, '\n')
+	if s.file == nil || (s.size > 0 && s.size+int64(len(line)) > s.maxSize) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return errors.Err(err)
+}
+
+func (s *FileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return errors.Err(err)
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.Close(); err != nil {
+		return err
+	}
+	name := filepath.Join(s.dir, filePrefix+s.now().UTC().Format("20060102T150405.000000000")+fileSuffix)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Err(err)
+	}
+	s.file = f
+	s.size = 0
+	return s.prune()
+}
+
+// prune removes the oldest sample files over maxFiles. File names sort in the order they were created.
+func (s *FileSink) prune() error {
+	if s.maxFiles < 1 {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return errors.Err(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), filePrefix) && strings.HasSuffix(e.Name(), fileSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > s.maxFiles {
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			return errors.Err(err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package sampling captures a fraction of proxied queries along with their responses for offline analysis,
+// e.g. building test fixtures from production traffic. Sensitive params and result fields are redacted.
+// Capturing never blocks requests: samples are queued in a bounded buffer and written out in the background,
+// those which don't fit into the buffer are dropped.
+package sampling
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/ybbus/jsonrpc"
+)
+
+const hookName = "sampling"
+
+// Sample outcomes for metrics.
+const (
+	outcomeWritten = "written"
+	outcomeDropped = "dropped"
+	outcomeFailed  = "failed"
+)
+
+var logger = monitor.NewModuleLogger("sampling")
+
+// Sample is a single captured query.
+type Sample struct {
+	Time     time.Time            `json:"time"`
+	Method   string               `json:"method"`
+	Request  jsonrpc.RPCRequest   `json:"request"`
+	Response *jsonrpc.RPCResponse `json:"response"`
+}
+
+// Sink stores samples somewhere they can be picked up for analysis.
+type Sink interface {
+	Write(s *Sample) error
+	Close() error
+}
+
+// Sampler decides which queries to capture and passes them on to a sink.
+type Sampler struct {
+	rate    float64
+	methods map[string]bool
+	sink    Sink
+	queue   chan *Sample
+	done    chan struct{}
+	random  func() float64
+}
+
+var (
+	samplerMu sync.RWMutex
+	sampler   *Sampler
+)
+
+// SetSampler makes s capture queries of callers set up by InstallHook. Nil disables capturing.
+func SetSampler(s *Sampler) {
+	samplerMu.Lock()
+	defer samplerMu.Unlock()
+	sampler = s
+}
+
+func getSampler() *Sampler {
+	samplerMu.RLock()
+	defer samplerMu.RUnlock()
+	return sampler
+}
+
+// New creates a sampler capturing cfg.Rate of queries for cfg.Methods (all methods if empty) into sink.
+// Run needs to be called for samples to be written.
+func New(cfg config.ResponseSampling, sink Sink) *Sampler {
+	methods := map[string]bool{}
+	for _, m := range cfg.Methods {
+		methods[m] = true
+	}
+	size := cfg.BufferSize
+	if size < 1 {
+		size = 1
+	}
+	return &Sampler{
+		rate:    cfg.Rate,
+		methods: methods,
+		sink:    sink,
+		queue:   make(chan *Sample, size),
+		done:    make(chan struct{}),
+		random:  rand.Float64,
+	}
+}
+
+// Offer captures the query and its response if they are picked for sampling. It never blocks.
+func (s *Sampler) Offer(q *query.Query, r *jsonrpc.RPCResponse) {
+	if len(s.methods) > 0 && !s.methods[q.Method()] {
+		return
+	}
+	if s.random() >= s.rate {
+		return
+	}
+	// Redacting makes copies, so the sample is not affected by anything done to the query or response later
+	req := *q.Request
+	req.Params = monitor.Redact(req.Params)
+	var res *jsonrpc.RPCResponse
+	if r != nil {
+		rc := *r
+		rc.Result = monitor.Redact(r.Result)
+		res = &rc
+	}
+	select {
+	case s.queue <- &Sample{Time: time.Now(), Method: q.Method(), Request: req, Response: res}:
+	default:
+		metrics.SamplingSamples.WithLabelValues(outcomeDropped).Inc()
+	}
+}
+
+// Run writes queued samples to the sink until Stop is called.
+func (s *Sampler) Run() {
+	defer close(s.done)
+	for smp := range s.queue {
+		if err := s.sink.Write(smp); err != nil {
+			metrics.SamplingSamples.WithLabelValues(outcomeFailed).Inc()
+			logger.Log().Errorf("cannot write sample: %v", err)
+			continue
+		}
+		metrics.SamplingSamples.WithLabelValues(outcomeWritten).Inc()
+	}
+}
+
+// Stop writes out samples still in the queue and closes the sink. Offer must not be called after Stop.
+func (s *Sampler) Stop() error {
+	close(s.queue)
+	<-s.done
+	return s.sink.Close()
+}
+
+// InstallHook makes the caller offer processed queries to the sampler set by SetSampler.
+func InstallHook(c *query.Caller) {
+	s := getSampler()
+	if s == nil {
+		return
+	}
+	c.AddPostflightHook(query.AllMethodsHook, func(_ *query.Caller, hctx *query.HookContext) (*jsonrpc.RPCResponse, error) {
+		s.Offer(hctx.Query, hctx.Response)
+		return nil, nil
+	}, hookName)
+}
@@ -0,0 +1,142 @@
+package sampling
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybbus/jsonrpc"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	samples []*Sample
+	closed  bool
+}
+
+func (s *memorySink) Write(smp *Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples = append(s.samples, smp)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func newQuery(t *testing.T, method string, params interface{}) *query.Query {
+	q, err := query.NewQuery(jsonrpc.NewRequest(method, params), "")
+	require.NoError(t, err)
+	return q
+}
+
+func TestSamplerOffer(t *testing.T) {
+	sink := &memorySink{}
+	s := New(config.ResponseSampling{Rate: 0.5, Methods: []string{"resolve", "account_balance"}, BufferSize: 10}, sink)
+	rolls := []float64{0.1, 0.7}
+	s.random = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+	go s.Run()
+
+	res := &jsonrpc.RPCResponse{Result: map[string]interface{}{"lbry://one": map[string]interface{}{"token": "secret", "name": "one"}}}
+	q := newQuery(t, "resolve", map[string]interface{}{"urls": "lbry://one", "auth_token": "secret"})
+	s.Offer(q, res)
+	// Not picked
+	s.Offer(newQuery(t, "resolve", map[string]interface{}{"urls": "lbry://two"}), res)
+	// Not listed
+	s.Offer(newQuery(t, "claim_search", nil), res)
+
+	// Changes made after capturing don't affect the sample
+	q.ParamsAsMap()["urls"] = "lbry://changed"
+	require.NoError(t, s.Stop())
+
+	require.Len(t, sink.samples, 1)
+	smp := sink.samples[0]
+	assert.Equal(t, "resolve", smp.Method)
+	assert.Equal(t, map[string]interface{}{"urls": "lbry://one", "auth_token": "****"}, smp.Request.Params)
+	assert.Equal(t, map[string]interface{}{"lbry://one": map[string]interface{}{"token": "****", "name": "one"}}, smp.Response.Result)
+	assert.Equal(t, "secret", res.Result.(map[string]interface{})["lbry://one"].(map[string]interface{})["token"])
+	assert.True(t, sink.closed)
+}
+
+func TestSamplerDropsWhenFull(t *testing.T) {
+	sink := &memorySink{}
+	s := New(config.ResponseSampling{Rate: 1, BufferSize: 2}, sink)
+	dropped := metrics.GetCounterValue(metrics.SamplingSamples.WithLabelValues(outcomeDropped))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			s.Offer(newQuery(t, "version", nil), &jsonrpc.RPCResponse{})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("offering samples blocked")
+	}
+	assert.Equal(t, dropped+3, metrics.GetCounterValue(metrics.SamplingSamples.WithLabelValues(outcomeDropped)))
+
+	go s.Run()
+	require.NoError(t, s.Stop())
+	assert.Len(t, sink.samples, 2)
+}
+
+func TestFileSink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "samples")
+	sink, err := NewFileSink(dir, 300, 2)
+	require.NoError(t, err)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, sink.Write(&Sample{
+			Time:     now,
+			Method:   "version",
+			Request:  *jsonrpc.NewRequest("version"),
+			Response: &jsonrpc.RPCResponse{Result: map[string]interface{}{"n": i}},
+		}))
+	}
+	require.NoError(t, sink.Close())
+
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var last []float64
+	for _, e := range entries {
+		assert.LessOrEqual(t, e.Size(), int64(300))
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var smp Sample
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &smp))
+			last = append(last, smp.Response.Result.(map[string]interface{})["n"].(float64))
+		}
+		f.Close()
+	}
+	// Oldest files are removed
+	assert.Equal(t, 6.0, last[len(last)-1])
+	assert.NotContains(t, last, 0.0)
+}
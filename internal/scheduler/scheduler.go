@@ -0,0 +1,183 @@
+// Package scheduler limits the number of concurrent operations, letting higher priority ones go first
+// when the limit is reached.
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+)
+
+// Priority is a class of operations. Lower values are served first.
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+)
+
+var priorityNames = map[Priority]string{
+	PriorityHigh:   "high",
+	PriorityNormal: "normal",
+	PriorityLow:    "low",
+}
+
+func (p Priority) String() string {
+	return priorityNames[p]
+}
+
+// ParsePriority returns the priority named s.
+func ParsePriority(s string) (Priority, error) {
+	for p, n := range priorityNames {
+		if n == s {
+			return p, nil
+		}
+	}
+	return PriorityNormal, errors.Err("unknown priority %q", s)
+}
+
+type waiter struct {
+	priority Priority
+	since    time.Time
+	ready    chan struct{}
+}
+
+// Scheduler lets a limited number of operations run at once and queues the rest.
+// When a slot frees up, it goes to the queued operation of the highest priority.
+// Queued operations are promoted by one priority class for every aging interval they have waited,
+// so low priority ones are eventually served even under a constant stream of high priority ones.
+type Scheduler struct {
+	capacity int
+	aging    time.Duration
+
+	mu      sync.Mutex
+	running int
+	queue   []*waiter
+}
+
+// New creates a Scheduler running up to capacity operations at once.
+// Capacity of zero or less disables the limit.
+func New(capacity int, aging time.Duration) *Scheduler {
+	return &Scheduler{capacity: capacity, aging: aging}
+}
+
+// ErrQueueTimeout is returned by AcquireTimeout when an operation hasn't been let to run in time.
+var ErrQueueTimeout = errors.Base("timed out waiting in queue")
+
+// Acquire blocks until an operation of priority p is allowed to run.
+// The returned function must be called when the operation is done, calling it more than once is safe.
+func (s *Scheduler) Acquire(p Priority) func() {
+	release, _ := s.AcquireTimeout(p, 0)
+	return release
+}
+
+// AcquireTimeout is like Acquire but gives up waiting in queue after timeout, returning ErrQueueTimeout.
+// Timeout of zero or less waits indefinitely.
+func (s *Scheduler) AcquireTimeout(p Priority, timeout time.Duration) (func(), error) {
+	if s.capacity <= 0 {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	s.mu.Lock()
+	if s.running < s.capacity && len(s.queue) == 0 {
+		s.running++
+		s.mu.Unlock()
+	} else {
+		w := &waiter{priority: p, since: start, ready: make(chan struct{})}
+		s.queue = append(s.queue, w)
+		s.mu.Unlock()
+		metrics.ProxySchedulerQueued.WithLabelValues(p.String()).Inc()
+		err := s.wait(w, timeout)
+		metrics.ProxySchedulerQueued.WithLabelValues(p.String()).Dec()
+		if err != nil {
+			metrics.ProxySchedulerQueueTimeouts.WithLabelValues(p.String()).Inc()
+			return func() {}, err
+		}
+	}
+	metrics.ProxySchedulerWaitSeconds.WithLabelValues(p.String()).Observe(time.Since(start).Seconds())
+
+	var once sync.Once
+	return func() { once.Do(s.release) }, nil
+}
+
+// wait blocks until w is let to run or timeout passes, in which case w is taken off the queue.
+func (s *Scheduler) wait(w *waiter, timeout time.Duration) error {
+	if timeout <= 0 {
+		<-w.ready
+		return nil
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-w.ready:
+		return nil
+	case <-t.C:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, qw := range s.queue {
+		if qw == w {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return errors.Err(ErrQueueTimeout)
+		}
+	}
+	// The slot has been handed over just as the timeout has passed
+	return nil
+}
+
+// Queued returns the number of operations waiting to run.
+func (s *Scheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Utilization returns how loaded the scheduler is: running and queued operations relative to capacity.
+// Values above 1 mean operations are waiting in queue. It's always zero when the limit is disabled.
+func (s *Scheduler) Utilization() float64 {
+	if s.capacity <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return float64(s.running+len(s.queue)) / float64(s.capacity)
+}
+
+// release hands the slot over to the next queued operation or frees it if there are none.
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		s.running--
+		return
+	}
+	i := s.next(time.Now())
+	w := s.queue[i]
+	s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	close(w.ready)
+}
+
+// next returns the index of the queued operation to run next: the one with the best effective priority,
+// or the longest waiting one among equals.
+func (s *Scheduler) next(now time.Time) int {
+	best, bestPriority := 0, s.effective(s.queue[0], now)
+	for i, w := range s.queue[1:] {
+		if p := s.effective(w, now); p < bestPriority {
+			best, bestPriority = i+1, p
+		}
+	}
+	return best
+}
+
+func (s *Scheduler) effective(w *waiter, now time.Time) int {
+	p := int(w.priority)
+	if s.aging > 0 {
+		p -= int(now.Sub(w.since) / s.aging)
+	}
+	return p
+}
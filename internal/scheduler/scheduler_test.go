@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enqueue starts an operation of priority p in the background, recording its name to order once it's admitted,
+// and waits until it's queued.
+func enqueue(t *testing.T, s *Scheduler, p Priority, name string, order chan<- string, wg *sync.WaitGroup) {
+	t.Helper()
+	queued := s.Queued()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		release := s.Acquire(p)
+		order <- name
+		release()
+	}()
+	require.Eventually(t, func() bool { return s.Queued() == queued+1 }, time.Second, time.Millisecond)
+}
+
+func TestScheduler_HigherPriorityFirst(t *testing.T) {
+	s := New(1, time.Hour)
+	release := s.Acquire(PriorityNormal)
+
+	order := make(chan string, 4)
+	wg := &sync.WaitGroup{}
+	enqueue(t, s, PriorityLow, "low", order, wg)
+	enqueue(t, s, PriorityNormal, "normal1", order, wg)
+	enqueue(t, s, PriorityHigh, "high", order, wg)
+	enqueue(t, s, PriorityNormal, "normal2", order, wg)
+
+	release()
+	wg.Wait()
+	close(order)
+	var got []string
+	for n := range order {
+		got = append(got, n)
+	}
+	assert.Equal(t, []string{"high", "normal1", "normal2", "low"}, got)
+	assert.Equal(t, 0, s.running)
+}
+
+func TestScheduler_NoStarvation(t *testing.T) {
+	s := New(1, 20*time.Millisecond)
+	release := s.Acquire(PriorityHigh)
+
+	order := make(chan string, 2)
+	wg := &sync.WaitGroup{}
+	enqueue(t, s, PriorityLow, "low", order, wg)
+	time.Sleep(50 * time.Millisecond)
+	enqueue(t, s, PriorityHigh, "high", order, wg)
+
+	release()
+	wg.Wait()
+	assert.Equal(t, "low", <-order)
+	assert.Equal(t, "high", <-order)
+}
+
+func TestScheduler_Capacity(t *testing.T) {
+	s := New(2, time.Hour)
+	r1 := s.Acquire(PriorityLow)
+	r2 := s.Acquire(PriorityLow)
+
+	admitted := make(chan struct{})
+	go func() {
+		s.Acquire(PriorityHigh)()
+		close(admitted)
+	}()
+	require.Eventually(t, func() bool { return s.Queued() == 1 }, time.Second, time.Millisecond)
+
+	r1()
+	r1()
+	<-admitted
+	r2()
+	assert.Equal(t, 0, s.running)
+}
+
+func TestScheduler_QueueTimeout(t *testing.T) {
+	s := New(1, time.Hour)
+	release := s.Acquire(PriorityNormal)
+	timeouts := metrics.GetCounterValue(metrics.ProxySchedulerQueueTimeouts.WithLabelValues(PriorityLow.String()))
+
+	start := time.Now()
+	r, err := s.AcquireTimeout(PriorityLow, 50*time.Millisecond)
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	r()
+	assert.Equal(t, 0, s.Queued())
+	assert.Equal(t, timeouts+1, metrics.GetCounterValue(metrics.ProxySchedulerQueueTimeouts.WithLabelValues(PriorityLow.String())))
+
+	// Operations still waiting get the slot once it's released
+	admitted := make(chan error)
+	go func() {
+		r, err := s.AcquireTimeout(PriorityLow, time.Second)
+		r()
+		admitted <- err
+	}()
+	require.Eventually(t, func() bool { return s.Queued() == 1 }, time.Second, time.Millisecond)
+	release()
+	assert.NoError(t, <-admitted)
+	assert.Equal(t, 0, s.running)
+}
+
+func TestScheduler_Unlimited(t *testing.T) {
+	s := New(0, time.Second)
+	for i := 0; i < 100; i++ {
+		s.Acquire(PriorityLow)
+	}
+	assert.Equal(t, 0, s.Queued())
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		parsed, err := ParsePriority(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	_, err := ParsePriority("urgent")
+	assert.Error(t, err)
+}
+
+func TestScheduler_Utilization(t *testing.T) {
+	assert.Equal(t, 0.0, New(0, time.Hour).Utilization())
+
+	s := New(4, time.Hour)
+	assert.Equal(t, 0.0, s.Utilization())
+	releases := []func(){s.Acquire(PriorityNormal), s.Acquire(PriorityNormal), s.Acquire(PriorityNormal)}
+	assert.Equal(t, 0.75, s.Utilization())
+	releases = append(releases, s.Acquire(PriorityNormal))
+
+	done := make(chan struct{})
+	go func() {
+		s.Acquire(PriorityNormal)()
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return s.Queued() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1.25, s.Utilization())
+
+	for _, r := range releases {
+		r()
+	}
+	<-done
+	assert.Equal(t, 0.0, s.Utilization())
+}
@@ -0,0 +1,23 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/internal/responses"
+)
+
+// HandleCircuitBreakers returns states of SDK server and method circuit breakers to admins (see auth.IsAdmin).
+// Breakers appear once they've guarded a call.
+func HandleCircuitBreakers(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	if !auth.IsAdmin(r) {
+		writeError(w, http.StatusForbidden, "admin token required")
+		return
+	}
+	respByte, _ := json.Marshal(query.Breakers.Status(time.Now()))
+	w.Write(respByte)
+}
@@ -0,0 +1,46 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/breaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCircuitBreakers(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+	query.Breakers = breaker.NewRegistry()
+	defer func() { query.Breakers = breaker.NewRegistry() }()
+
+	settings := config.CircuitBreaker{Threshold: 1, Window: time.Minute, Cooldown: time.Minute}
+	query.Breakers.Get(breaker.KindEndpoint, "http://srv1", settings)
+	query.Breakers.Get(breaker.KindMethod, query.MethodClaimSearch, settings).Failure(time.Now())
+
+	rr := httptest.NewRecorder()
+	HandleCircuitBreakers(rr, httptest.NewRequest(http.MethodGet, "/api/v1/admin/breakers", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/breakers", nil)
+	r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+	rr = httptest.NewRecorder()
+	HandleCircuitBreakers(rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var st []breaker.Status
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &st))
+	require.Len(t, st, 2)
+	assert.Equal(t, "closed", st[0].State)
+	assert.Equal(t, "http://srv1", st[0].Name)
+	assert.Equal(t, "open", st[1].State)
+	assert.NotNil(t, st[1].OpenedAt)
+}
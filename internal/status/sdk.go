@@ -0,0 +1,50 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/internal/responses"
+)
+
+// HandleSDKServers returns routing state of SDK servers to admins (see auth.IsAdmin).
+// POST drains the server supplied by name or address in `server` query parameter
+// and DELETE puts it back into rotation, both respond with the updated state.
+func HandleSDKServers(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	if !auth.IsAdmin(r) {
+		writeError(w, http.StatusForbidden, "admin token required")
+		return
+	}
+	rt := sdkrouter.FromRequest(r)
+
+	if r.Method == http.MethodPost || r.Method == http.MethodDelete {
+		s := rt.FindServer(r.FormValue("server"))
+		if s == nil {
+			writeError(w, http.StatusNotFound, "sdk server not found")
+			return
+		}
+		var err error
+		if r.Method == http.MethodPost {
+			err = rt.Drain(s.Address)
+		} else {
+			err = rt.Undrain(s.Address)
+		}
+		if err != nil {
+			logger.Log().Errorf("cannot change drain state of %v: %v", s.Address, err)
+			writeError(w, http.StatusInternalServerError, "cannot change drain state")
+			return
+		}
+	}
+
+	respByte, _ := json.Marshal(rt.Status())
+	w.Write(respByte)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	respByte, _ := json.Marshal(map[string]string{"error": msg})
+	w.Write(respByte)
+}
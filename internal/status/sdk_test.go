@@ -0,0 +1,56 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSDKServers(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	defer config.RestoreOverridden()
+
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "srv1", Address: "http://srv1"},
+		&models.LbrynetServer{Name: "srv2", Address: "http://srv2"},
+	)
+	call := func(method, target string, admin bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+		}
+		rr := httptest.NewRecorder()
+		middleware.Apply(sdkrouter.Middleware(rt), HandleSDKServers).ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := call(http.MethodPost, "/api/v1/admin/sdk?server=srv2", false)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.False(t, rt.IsDraining("http://srv2"))
+
+	rr = call(http.MethodPost, "/api/v1/admin/sdk?server=srv3", true)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = call(http.MethodPost, "/api/v1/admin/sdk?server=srv2", true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var st []sdkrouter.ServerStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &st))
+	assert.Equal(t, []sdkrouter.ServerStatus{
+		{Name: "srv1", Address: "http://srv1"},
+		{Name: "srv2", Address: "http://srv2", Draining: true},
+	}, st)
+
+	rr = call(http.MethodDelete, "/api/v1/admin/sdk?server=http://srv2", true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, rt.IsDraining("http://srv2"))
+}
@@ -0,0 +1,88 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/query"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/responses"
+
+	ljsonrpc "github.com/lbryio/lbry.go/v2/extras/jsonrpc"
+	"github.com/ybbus/jsonrpc"
+)
+
+// sdkNodeStatus is the live status of an SDK server along with the state the router keeps for it.
+type sdkNodeStatus struct {
+	sdkrouter.ServerStatus
+	Reachable    bool    `json:"reachable"`
+	Error        string  `json:"error,omitempty"`
+	Running      bool    `json:"running"`
+	Height       int     `json:"height"`
+	BlocksBehind int     `json:"blocks_behind"`
+	Peers        uint64  `json:"peers"`
+	Wallets      *uint64 `json:"wallets,omitempty"`
+}
+
+type sdkStatusReport struct {
+	Timestamp   string           `json:"timestamp"`
+	Reachable   int              `json:"reachable"`
+	Unreachable int              `json:"unreachable"`
+	Nodes       []*sdkNodeStatus `json:"nodes"`
+}
+
+// HandleSDKStatus calls status on all SDK servers concurrently and reports their state.
+// Servers which fail to respond in time are included in the report as unreachable.
+func HandleSDKStatus(w http.ResponseWriter, r *http.Request) {
+	responses.AddJSONContentType(w)
+	if !auth.IsAdmin(r) {
+		writeError(w, http.StatusForbidden, "admin token required")
+		return
+	}
+	respByte, _ := json.Marshal(collectSDKStatus(sdkrouter.FromRequest(r), config.GetSDKStatusTimeout()))
+	w.Write(respByte)
+}
+
+func collectSDKStatus(rt *sdkrouter.Router, timeout time.Duration) *sdkStatusReport {
+	servers := rt.Status()
+	endpoints := make([]string, len(servers))
+	for i, s := range servers {
+		endpoints[i] = s.Address
+	}
+	res := query.FanOutDirect(endpoints, jsonrpc.NewRequest(query.MethodStatus), timeout)
+
+	report := &sdkStatusReport{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Nodes:     make([]*sdkNodeStatus, len(servers)),
+	}
+	for i, s := range servers {
+		node := &sdkNodeStatus{ServerStatus: s}
+		if n, ok := rt.LoadedWallets(s.Address); ok {
+			node.Wallets = &n
+		}
+		report.Nodes[i] = node
+
+		if err, ok := res.Failed[s.Address]; ok {
+			node.Error = err.Error()
+			report.Unreachable++
+			continue
+		}
+		node.Reachable = true
+		report.Reachable++
+
+		var st ljsonrpc.StatusResponse
+		if err := ljsonrpc.Decode(res.Responses[s.Address].Result, &st); err != nil {
+			logger.Log().Warnf("cannot decode status of %v: %v", s.Address, err)
+			node.Error = "unexpected status response"
+			continue
+		}
+		node.Running = st.IsRunning
+		node.Height = st.Wallet.Blocks
+		node.BlocksBehind = st.Wallet.BlocksBehind
+		node.Peers = st.Dht.PeersInRoutingTable
+	}
+	return report
+}
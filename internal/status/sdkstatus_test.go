@@ -0,0 +1,72 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lbryio/lbrytv/app/auth"
+	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/middleware"
+	"github.com/lbryio/lbrytv/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSDKStatus(t *testing.T) {
+	config.Override("AdminToken", "admin-secret")
+	config.Override("SDKStatusTimeout", "2s")
+	defer config.RestoreOverridden()
+
+	sdk := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": 0, "result": {
+			"is_running": true, "dht": {"peers_in_routing_table": 42}, "wallet": {"blocks": 1000, "blocks_behind": 2}
+		}}`))
+	}))
+	defer sdk.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	rt := sdkrouter.NewWithServers(
+		&models.LbrynetServer{Name: "up", Address: sdk.URL},
+		&models.LbrynetServer{Name: "down", Address: down.URL},
+	)
+	require.NoError(t, rt.Drain(sdk.URL))
+	call := func(admin bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/sdk/status", nil)
+		if admin {
+			r.Header.Set(auth.AdminTokenHeader, "admin-secret")
+		}
+		rr := httptest.NewRecorder()
+		middleware.Apply(sdkrouter.Middleware(rt), HandleSDKStatus).ServeHTTP(rr, r)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusForbidden, call(false).Code)
+
+	rr := call(true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var report sdkStatusReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Reachable)
+	assert.Equal(t, 1, report.Unreachable)
+	require.Len(t, report.Nodes, 2)
+
+	up := report.Nodes[0]
+	assert.Equal(t, "up", up.Name)
+	assert.True(t, up.Draining)
+	assert.True(t, up.Reachable)
+	assert.True(t, up.Running)
+	assert.Equal(t, 1000, up.Height)
+	assert.Equal(t, 2, up.BlocksBehind)
+	assert.EqualValues(t, 42, up.Peers)
+	assert.Empty(t, up.Error)
+
+	unreachable := report.Nodes[1]
+	assert.Equal(t, "down", unreachable.Name)
+	assert.False(t, unreachable.Reachable)
+	assert.NotEmpty(t, unreachable.Error)
+}
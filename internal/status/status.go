@@ -40,6 +40,7 @@ const (
 	statusNotReady      = "not_ready"
 	statusOffline       = "offline"
 	statusFailing       = "failing"
+	statusDraining      = "draining"
 	statusCacheValidity = 120 * time.Second
 )
 
@@ -79,9 +80,12 @@ func GetStatus(w http.ResponseWriter, req *http.Request) {
 		}
 		failureDetected := false
 
-		sdks := sdkrouter.FromRequest(req).GetAll()
-		for _, s := range sdks {
-			services["lbrynet"] = append(services["lbrynet"], &serverItem{Name: s.Name, Status: statusOK})
+		for _, s := range sdkrouter.FromRequest(req).Status() {
+			st := statusOK
+			if s.Draining {
+				st = statusDraining
+			}
+			services["lbrynet"] = append(services["lbrynet"], &serverItem{Name: s.Name, Status: st})
 		}
 
 		for _, ps := range PlayerServers {
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+ALTER TABLE query_log
+    ADD COLUMN "outcome" VARCHAR;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+CREATE INDEX queries_user_id_timestamp_idx ON query_log(user_id, "timestamp");
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP INDEX IF EXISTS queries_user_id_timestamp_idx;
+-- +migrate StatementEnd
+
+-- +migrate StatementBegin
+ALTER TABLE query_log
+    DROP COLUMN "outcome";
+-- +migrate StatementEnd
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "dead_letters" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" uinteger NOT NULL,
+    "method" varchar NOT NULL,
+    "request" jsonb NOT NULL,
+    "idempotency_key" varchar NOT NULL,
+
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" varchar,
+    "next_attempt_at" timestamp NOT NULL DEFAULT now(),
+
+    "created_at" timestamp NOT NULL DEFAULT now(),
+    "updated_at" timestamp NOT NULL DEFAULT now(),
+
+    UNIQUE ("idempotency_key")
+);
+CREATE INDEX dead_letters_status_next_attempt_at_idx ON dead_letters(status, next_attempt_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "dead_letters";
+-- +migrate StatementEnd
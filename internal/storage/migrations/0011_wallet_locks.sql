@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "wallet_locks" (
+    "user_id" integer PRIMARY KEY,
+    "token" varchar NOT NULL,
+    "expires_at" timestamp NOT NULL
+);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "wallet_locks";
+-- +migrate StatementEnd
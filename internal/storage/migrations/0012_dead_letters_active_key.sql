@@ -0,0 +1,13 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+ALTER TABLE "dead_letters" DROP CONSTRAINT "dead_letters_idempotency_key_key";
+CREATE UNIQUE INDEX dead_letters_active_idempotency_key_idx ON dead_letters(idempotency_key) WHERE status <> 'done';
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP INDEX dead_letters_active_idempotency_key_idx;
+ALTER TABLE "dead_letters" ADD CONSTRAINT "dead_letters_idempotency_key_key" UNIQUE ("idempotency_key");
+-- +migrate StatementEnd
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "user_costs" (
+    "user_id" integer PRIMARY KEY,
+    "total" bigint NOT NULL DEFAULT 0,
+    "updated_at" timestamp NOT NULL DEFAULT now()
+);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "user_costs";
+-- +migrate StatementEnd
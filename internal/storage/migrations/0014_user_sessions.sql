@@ -0,0 +1,23 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "user_sessions" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "token_hash" varchar NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT now(),
+    "last_used_at" timestamp NOT NULL DEFAULT now(),
+    "last_ip" varchar NOT NULL DEFAULT '',
+    "revoked_at" timestamp,
+
+    UNIQUE ("token_hash")
+);
+CREATE INDEX user_sessions_user_id_idx ON user_sessions(user_id);
+CREATE INDEX user_sessions_revoked_at_idx ON user_sessions(revoked_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "user_sessions";
+-- +migrate StatementEnd
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "user_entitlements" (
+    "user_id" integer NOT NULL,
+    "entitlement" varchar NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT now(),
+    PRIMARY KEY ("user_id", "entitlement")
+);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "user_entitlements";
+-- +migrate StatementEnd
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+ALTER TABLE "user_sessions" ADD COLUMN "device_name" varchar NOT NULL DEFAULT '';
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+ALTER TABLE "user_sessions" DROP COLUMN "device_name";
+-- +migrate StatementEnd
@@ -0,0 +1,14 @@
+-- +migrate Up
+
+-- +migrate StatementBegin
+CREATE TABLE "service_nonces" (
+    "nonce" varchar PRIMARY KEY,
+    "expires_at" timestamp NOT NULL
+);
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+-- +migrate StatementBegin
+DROP TABLE "service_nonces";
+-- +migrate StatementEnd
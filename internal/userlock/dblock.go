@@ -0,0 +1,115 @@
+package userlock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+	"github.com/lbryio/lbrytv/internal/metrics"
+	"github.com/lbryio/lbrytv/internal/monitor"
+
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+// ErrUnavailable is returned by DBLocker when the database cannot be reached and it's not allowed to fail open.
+var ErrUnavailable = errors.Base("user lock backend is unavailable")
+
+// DefaultPollInterval is how often DBLocker checks if a lock held by another instance has been released.
+const DefaultPollInterval = 100 * time.Millisecond
+
+var logger = monitor.NewModuleLogger("userlock")
+
+// DBLocker holds per-user locks in the database so they're shared by all API instances using it.
+type DBLocker struct {
+	db       boil.Executor
+	failOpen bool
+	// PollInterval is how often a held lock is checked while waiting for it.
+	PollInterval time.Duration
+}
+
+// NewDBLocker creates a DBLocker. When the database fails, locks are granted if failOpen is true
+// and ErrUnavailable is returned otherwise.
+func NewDBLocker(db boil.Executor, failOpen bool) *DBLocker {
+	return &DBLocker{db: db, failOpen: failOpen, PollInterval: DefaultPollInterval}
+}
+
+// Acquire works like Locker.Acquire. A lock which hasn't been released within maxHold, e.g. because
+// the instance holding it has died, is taken over by the next one trying to acquire it.
+func (l *DBLocker) Acquire(userID int, wait, maxHold time.Duration) (func(), error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, errors.Err(err)
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		ok, err := l.tryAcquire(userID, token, maxHold)
+		if err != nil {
+			return l.backendFailed(userID, err)
+		}
+		if ok {
+			return l.releaser(userID, token), nil
+		}
+
+		left := time.Until(deadline)
+		if left <= 0 {
+			return nil, ErrLocked
+		}
+		if left > l.PollInterval {
+			left = l.PollInterval
+		}
+		time.Sleep(left)
+	}
+}
+
+func (l *DBLocker) tryAcquire(userID int, token string, maxHold time.Duration) (bool, error) {
+	res, err := l.db.Exec(`
+		INSERT INTO "wallet_locks" ("user_id", "token", "expires_at")
+		VALUES ($1, $2, now() + $3 * interval '1 millisecond')
+		ON CONFLICT ("user_id") DO UPDATE SET "token" = EXCLUDED."token", "expires_at" = EXCLUDED."expires_at"
+		WHERE "wallet_locks"."expires_at" < now()`,
+		userID, token, maxHold.Milliseconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (l *DBLocker) releaser(userID int, token string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(userID, token) })
+	}
+}
+
+func (l *DBLocker) release(userID int, token string) {
+	_, err := l.db.Exec(`DELETE FROM "wallet_locks" WHERE "user_id" = $1 AND "token" = $2`, userID, token)
+	if err != nil {
+		// The lock will be taken over after it expires
+		logger.Log().Errorf("cannot release lock of user %v: %v", userID, err)
+	}
+}
+
+func (l *DBLocker) backendFailed(userID int, err error) (func(), error) {
+	metrics.UserLockBackendFailures.Inc()
+	if l.failOpen {
+		logger.Log().Warnf("lock backend failed, proceeding without locking user %v: %v", userID, err)
+		return func() {}, nil
+	}
+	logger.Log().Errorf("lock backend failed, cannot lock user %v: %v", userID, err)
+	return nil, ErrUnavailable
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,105 @@
+package userlock
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/storage"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+func TestDBLocker_AcrossInstances(t *testing.T) {
+	storage.Conn.Truncate([]string{"wallet_locks"})
+	// Each API instance has its own locker, they only share the database
+	node1 := NewDBLocker(boil.GetDB(), false)
+	node2 := NewDBLocker(boil.GetDB(), false)
+
+	release, err := node1.Acquire(1, 0, time.Minute)
+	require.NoError(t, err)
+
+	_, err = node2.Acquire(1, 0, time.Minute)
+	assert.Equal(t, ErrLocked, err)
+
+	release2, err := node2.Acquire(2, 0, time.Minute)
+	require.NoError(t, err)
+	release2()
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		release()
+	}()
+	release, err = node2.Acquire(1, 2*time.Second, time.Minute)
+	require.NoError(t, err)
+	release()
+	release()
+}
+
+func TestDBLocker_Expires(t *testing.T) {
+	storage.Conn.Truncate([]string{"wallet_locks"})
+	dead := NewDBLocker(boil.GetDB(), false)
+	alive := NewDBLocker(boil.GetDB(), false)
+
+	// Node holding the lock dies without releasing it
+	_, err := dead.Acquire(1, 0, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	release, err := alive.Acquire(1, time.Second, time.Minute)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = dead.Acquire(1, 0, time.Minute)
+	assert.Equal(t, ErrLocked, err)
+}
+
+func TestDBLocker_ConcurrentSends(t *testing.T) {
+	storage.Conn.Truncate([]string{"wallet_locks"})
+	var (
+		wg       sync.WaitGroup
+		inFlight int32
+		overlaps int32
+		done     int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			node := NewDBLocker(boil.GetDB(), false)
+			node.PollInterval = 10 * time.Millisecond
+			release, err := node.Acquire(1, 5*time.Second, time.Minute)
+			if err != nil {
+				return
+			}
+			if atomic.AddInt32(&inFlight, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			// wallet_send being processed
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			atomic.AddInt32(&done, 1)
+			release()
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 0, overlaps)
+	assert.EqualValues(t, 10, done)
+}
+
+func TestDBLocker_BackendUnavailable(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://lbrytv@127.0.0.1:1/lbrytv?sslmode=disable&connect_timeout=1")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewDBLocker(db, false).Acquire(1, time.Second, time.Minute)
+	assert.Equal(t, ErrUnavailable, err)
+
+	release, err := NewDBLocker(db, true).Acquire(1, time.Second, time.Minute)
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,80 @@
+// Package userlock serializes operations performed on behalf of the same user.
+package userlock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lbryio/lbrytv/internal/errors"
+)
+
+// ErrLocked is returned when the user's lock couldn't be acquired in time.
+var ErrLocked = errors.Base("another operation is in progress for this user")
+
+type hold struct {
+	token    uint64
+	expires  time.Time
+	released chan struct{}
+}
+
+// Locker holds per-user locks.
+type Locker struct {
+	mu    sync.Mutex
+	held  map[int]*hold
+	token uint64
+}
+
+// New creates a Locker.
+func New() *Locker {
+	return &Locker{held: map[int]*hold{}}
+}
+
+// Acquire locks userID, waiting up to wait for an operation already holding the lock to complete.
+// The lock is held until the returned function is called or for maxHold at most,
+// so a stuck operation cannot lock the user out forever. Calling the release function more than once is safe.
+func (l *Locker) Acquire(userID int, wait, maxHold time.Duration) (func(), error) {
+	deadline := time.Now().Add(wait)
+	for {
+		l.mu.Lock()
+		h, ok := l.held[userID]
+		if !ok || time.Now().After(h.expires) {
+			l.token++
+			h = &hold{token: l.token, expires: time.Now().Add(maxHold), released: make(chan struct{})}
+			l.held[userID] = h
+			l.mu.Unlock()
+			return func() { l.release(userID, h) }, nil
+		}
+		l.mu.Unlock()
+
+		left := time.Until(deadline)
+		if left <= 0 {
+			return nil, ErrLocked
+		}
+		if untilExpiry := time.Until(h.expires); untilExpiry < left {
+			left = untilExpiry
+		}
+		t := time.NewTimer(left)
+		select {
+		case <-h.released:
+		case <-t.C:
+		}
+		t.Stop()
+	}
+}
+
+// IsLocked returns true if userID is currently locked.
+func (l *Locker) IsLocked(userID int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.held[userID]
+	return ok && time.Now().Before(h.expires)
+}
+
+func (l *Locker) release(userID int, h *hold) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cur, ok := l.held[userID]; ok && cur.token == h.token {
+		delete(l.held, userID)
+		close(h.released)
+	}
+}
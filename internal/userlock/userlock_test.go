@@ -0,0 +1,83 @@
+package userlock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire_Rejects(t *testing.T) {
+	l := New()
+	release, err := l.Acquire(1, 0, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, l.IsLocked(1))
+
+	_, err = l.Acquire(1, 0, time.Minute)
+	assert.Equal(t, ErrLocked, err)
+
+	// Other users are not affected
+	release2, err := l.Acquire(2, 0, time.Minute)
+	require.NoError(t, err)
+	release2()
+
+	release()
+	release()
+	assert.False(t, l.IsLocked(1))
+	release, err = l.Acquire(1, 0, time.Minute)
+	require.NoError(t, err)
+	release()
+}
+
+func TestAcquire_Waits(t *testing.T) {
+	l := New()
+	release, err := l.Acquire(1, 0, time.Minute)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		release()
+	}()
+	start := time.Now()
+	release, err = l.Acquire(1, time.Second, time.Minute)
+	require.NoError(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	release()
+}
+
+func TestAcquire_Expires(t *testing.T) {
+	l := New()
+	stale, err := l.Acquire(1, 0, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	release, err := l.Acquire(1, time.Second, time.Minute)
+	require.NoError(t, err)
+	// Releasing the expired lock must not release the new one
+	stale()
+	assert.True(t, l.IsLocked(1))
+	release()
+}
+
+func TestAcquire_Concurrent(t *testing.T) {
+	l := New()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		acquired int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Acquire(1, 0, time.Minute); err == nil {
+				mu.Lock()
+				acquired++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, acquired)
+}
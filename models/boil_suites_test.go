@@ -12,89 +12,154 @@ import "testing"
 // It does NOT run each operation group in parallel.
 // Separating the tests thusly grants avoidance of Postgres deadlocks.
 func TestParent(t *testing.T) {
+	t.Run("DeadLetters", testDeadLetters)
 	t.Run("GorpMigrations", testGorpMigrations)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrains)
 	t.Run("LbrynetServers", testLbrynetServers)
 	t.Run("QueryLogs", testQueryLogs)
+	t.Run("UserCosts", testUserCosts)
+	t.Run("UserEntitlements", testUserEntitlements)
+	t.Run("UserSessions", testUserSessions)
 	t.Run("Users", testUsers)
 }
 
 func TestDelete(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersDelete)
 	t.Run("GorpMigrations", testGorpMigrationsDelete)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsDelete)
 	t.Run("LbrynetServers", testLbrynetServersDelete)
 	t.Run("QueryLogs", testQueryLogsDelete)
+	t.Run("UserCosts", testUserCostsDelete)
+	t.Run("UserEntitlements", testUserEntitlementsDelete)
+	t.Run("UserSessions", testUserSessionsDelete)
 	t.Run("Users", testUsersDelete)
 }
 
 func TestQueryDeleteAll(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersQueryDeleteAll)
 	t.Run("GorpMigrations", testGorpMigrationsQueryDeleteAll)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsQueryDeleteAll)
 	t.Run("LbrynetServers", testLbrynetServersQueryDeleteAll)
 	t.Run("QueryLogs", testQueryLogsQueryDeleteAll)
+	t.Run("UserCosts", testUserCostsQueryDeleteAll)
+	t.Run("UserEntitlements", testUserEntitlementsQueryDeleteAll)
+	t.Run("UserSessions", testUserSessionsQueryDeleteAll)
 	t.Run("Users", testUsersQueryDeleteAll)
 }
 
 func TestSliceDeleteAll(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersSliceDeleteAll)
 	t.Run("GorpMigrations", testGorpMigrationsSliceDeleteAll)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsSliceDeleteAll)
 	t.Run("LbrynetServers", testLbrynetServersSliceDeleteAll)
 	t.Run("QueryLogs", testQueryLogsSliceDeleteAll)
+	t.Run("UserCosts", testUserCostsSliceDeleteAll)
+	t.Run("UserEntitlements", testUserEntitlementsSliceDeleteAll)
+	t.Run("UserSessions", testUserSessionsSliceDeleteAll)
 	t.Run("Users", testUsersSliceDeleteAll)
 }
 
 func TestExists(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersExists)
 	t.Run("GorpMigrations", testGorpMigrationsExists)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsExists)
 	t.Run("LbrynetServers", testLbrynetServersExists)
 	t.Run("QueryLogs", testQueryLogsExists)
+	t.Run("UserCosts", testUserCostsExists)
+	t.Run("UserEntitlements", testUserEntitlementsExists)
+	t.Run("UserSessions", testUserSessionsExists)
 	t.Run("Users", testUsersExists)
 }
 
 func TestFind(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersFind)
 	t.Run("GorpMigrations", testGorpMigrationsFind)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsFind)
 	t.Run("LbrynetServers", testLbrynetServersFind)
 	t.Run("QueryLogs", testQueryLogsFind)
+	t.Run("UserCosts", testUserCostsFind)
+	t.Run("UserEntitlements", testUserEntitlementsFind)
+	t.Run("UserSessions", testUserSessionsFind)
 	t.Run("Users", testUsersFind)
 }
 
 func TestBind(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersBind)
 	t.Run("GorpMigrations", testGorpMigrationsBind)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsBind)
 	t.Run("LbrynetServers", testLbrynetServersBind)
 	t.Run("QueryLogs", testQueryLogsBind)
+	t.Run("UserCosts", testUserCostsBind)
+	t.Run("UserEntitlements", testUserEntitlementsBind)
+	t.Run("UserSessions", testUserSessionsBind)
 	t.Run("Users", testUsersBind)
 }
 
 func TestOne(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersOne)
 	t.Run("GorpMigrations", testGorpMigrationsOne)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsOne)
 	t.Run("LbrynetServers", testLbrynetServersOne)
 	t.Run("QueryLogs", testQueryLogsOne)
+	t.Run("UserCosts", testUserCostsOne)
+	t.Run("UserEntitlements", testUserEntitlementsOne)
+	t.Run("UserSessions", testUserSessionsOne)
 	t.Run("Users", testUsersOne)
 }
 
 func TestAll(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersAll)
 	t.Run("GorpMigrations", testGorpMigrationsAll)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsAll)
 	t.Run("LbrynetServers", testLbrynetServersAll)
 	t.Run("QueryLogs", testQueryLogsAll)
+	t.Run("UserCosts", testUserCostsAll)
+	t.Run("UserEntitlements", testUserEntitlementsAll)
+	t.Run("UserSessions", testUserSessionsAll)
 	t.Run("Users", testUsersAll)
 }
 
 func TestCount(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersCount)
 	t.Run("GorpMigrations", testGorpMigrationsCount)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsCount)
 	t.Run("LbrynetServers", testLbrynetServersCount)
 	t.Run("QueryLogs", testQueryLogsCount)
+	t.Run("UserCosts", testUserCostsCount)
+	t.Run("UserEntitlements", testUserEntitlementsCount)
+	t.Run("UserSessions", testUserSessionsCount)
 	t.Run("Users", testUsersCount)
 }
 
 func TestHooks(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersHooks)
 	t.Run("GorpMigrations", testGorpMigrationsHooks)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsHooks)
 	t.Run("LbrynetServers", testLbrynetServersHooks)
 	t.Run("QueryLogs", testQueryLogsHooks)
+	t.Run("UserCosts", testUserCostsHooks)
+	t.Run("UserEntitlements", testUserEntitlementsHooks)
+	t.Run("UserSessions", testUserSessionsHooks)
 	t.Run("Users", testUsersHooks)
 }
 
 func TestInsert(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersInsert)
+	t.Run("DeadLetters", testDeadLettersInsertWhitelist)
 	t.Run("GorpMigrations", testGorpMigrationsInsert)
 	t.Run("GorpMigrations", testGorpMigrationsInsertWhitelist)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsInsert)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsInsertWhitelist)
 	t.Run("LbrynetServers", testLbrynetServersInsert)
 	t.Run("LbrynetServers", testLbrynetServersInsertWhitelist)
 	t.Run("QueryLogs", testQueryLogsInsert)
 	t.Run("QueryLogs", testQueryLogsInsertWhitelist)
+	t.Run("UserCosts", testUserCostsInsert)
+	t.Run("UserCosts", testUserCostsInsertWhitelist)
+	t.Run("UserEntitlements", testUserEntitlementsInsert)
+	t.Run("UserEntitlements", testUserEntitlementsInsertWhitelist)
+	t.Run("UserSessions", testUserSessionsInsert)
+	t.Run("UserSessions", testUserSessionsInsertWhitelist)
 	t.Run("Users", testUsersInsert)
 	t.Run("Users", testUsersInsertWhitelist)
 }
@@ -154,36 +219,61 @@ func TestToManyRemove(t *testing.T) {
 }
 
 func TestReload(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersReload)
 	t.Run("GorpMigrations", testGorpMigrationsReload)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsReload)
 	t.Run("LbrynetServers", testLbrynetServersReload)
 	t.Run("QueryLogs", testQueryLogsReload)
+	t.Run("UserCosts", testUserCostsReload)
+	t.Run("UserEntitlements", testUserEntitlementsReload)
+	t.Run("UserSessions", testUserSessionsReload)
 	t.Run("Users", testUsersReload)
 }
 
 func TestReloadAll(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersReloadAll)
 	t.Run("GorpMigrations", testGorpMigrationsReloadAll)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsReloadAll)
 	t.Run("LbrynetServers", testLbrynetServersReloadAll)
 	t.Run("QueryLogs", testQueryLogsReloadAll)
+	t.Run("UserCosts", testUserCostsReloadAll)
+	t.Run("UserEntitlements", testUserEntitlementsReloadAll)
+	t.Run("UserSessions", testUserSessionsReloadAll)
 	t.Run("Users", testUsersReloadAll)
 }
 
 func TestSelect(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersSelect)
 	t.Run("GorpMigrations", testGorpMigrationsSelect)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsSelect)
 	t.Run("LbrynetServers", testLbrynetServersSelect)
 	t.Run("QueryLogs", testQueryLogsSelect)
+	t.Run("UserCosts", testUserCostsSelect)
+	t.Run("UserEntitlements", testUserEntitlementsSelect)
+	t.Run("UserSessions", testUserSessionsSelect)
 	t.Run("Users", testUsersSelect)
 }
 
 func TestUpdate(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersUpdate)
 	t.Run("GorpMigrations", testGorpMigrationsUpdate)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsUpdate)
 	t.Run("LbrynetServers", testLbrynetServersUpdate)
 	t.Run("QueryLogs", testQueryLogsUpdate)
+	t.Run("UserCosts", testUserCostsUpdate)
+	t.Run("UserEntitlements", testUserEntitlementsUpdate)
+	t.Run("UserSessions", testUserSessionsUpdate)
 	t.Run("Users", testUsersUpdate)
 }
 
 func TestSliceUpdateAll(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersSliceUpdateAll)
 	t.Run("GorpMigrations", testGorpMigrationsSliceUpdateAll)
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsSliceUpdateAll)
 	t.Run("LbrynetServers", testLbrynetServersSliceUpdateAll)
 	t.Run("QueryLogs", testQueryLogsSliceUpdateAll)
+	t.Run("UserCosts", testUserCostsSliceUpdateAll)
+	t.Run("UserEntitlements", testUserEntitlementsSliceUpdateAll)
+	t.Run("UserSessions", testUserSessionsSliceUpdateAll)
 	t.Run("Users", testUsersSliceUpdateAll)
 }
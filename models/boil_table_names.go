@@ -4,13 +4,23 @@
 package models
 
 var TableNames = struct {
-	GorpMigrations string
-	LbrynetServers string
-	QueryLog       string
-	Users          string
+	DeadLetters         string
+	GorpMigrations      string
+	LbrynetServerDrains string
+	LbrynetServers      string
+	QueryLog            string
+	UserCosts           string
+	UserEntitlements    string
+	UserSessions        string
+	Users               string
 }{
-	GorpMigrations: "gorp_migrations",
-	LbrynetServers: "lbrynet_servers",
-	QueryLog:       "query_log",
-	Users:          "users",
+	DeadLetters:         "dead_letters",
+	GorpMigrations:      "gorp_migrations",
+	LbrynetServerDrains: "lbrynet_server_drains",
+	LbrynetServers:      "lbrynet_servers",
+	QueryLog:            "query_log",
+	UserCosts:           "user_costs",
+	UserEntitlements:    "user_entitlements",
+	UserSessions:        "user_sessions",
+	Users:               "users",
 }
@@ -0,0 +1,926 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/queries/qmhelper"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+// LbrynetServerDrain is an object representing the database table.
+type LbrynetServerDrain struct {
+	Address   string    `boil:"address" json:"address" toml:"address" yaml:"address"`
+	CreatedAt time.Time `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+
+	R *lbrynetServerDrainR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L lbrynetServerDrainL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var LbrynetServerDrainColumns = struct {
+	Address   string
+	CreatedAt string
+}{
+	Address:   "address",
+	CreatedAt: "created_at",
+}
+
+// Generated where
+
+var LbrynetServerDrainWhere = struct {
+	Address   whereHelperstring
+	CreatedAt whereHelpertime_Time
+}{
+	Address:   whereHelperstring{field: "\"lbrynet_server_drains\".\"address\""},
+	CreatedAt: whereHelpertime_Time{field: "\"lbrynet_server_drains\".\"created_at\""},
+}
+
+// LbrynetServerDrainRels is where relationship names are stored.
+var LbrynetServerDrainRels = struct {
+}{}
+
+// lbrynetServerDrainR is where relationships are stored.
+type lbrynetServerDrainR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*lbrynetServerDrainR) NewStruct() *lbrynetServerDrainR {
+	return &lbrynetServerDrainR{}
+}
+
+// lbrynetServerDrainL is where Load methods for each relationship are stored.
+type lbrynetServerDrainL struct{}
+
+var (
+	lbrynetServerDrainAllColumns            = []string{"address", "created_at"}
+	lbrynetServerDrainColumnsWithoutDefault = []string{"address"}
+	lbrynetServerDrainColumnsWithDefault    = []string{"created_at"}
+	lbrynetServerDrainPrimaryKeyColumns     = []string{"address"}
+)
+
+type (
+	// LbrynetServerDrainSlice is an alias for a slice of pointers to LbrynetServerDrain.
+	// This should generally be used opposed to []LbrynetServerDrain.
+	LbrynetServerDrainSlice []*LbrynetServerDrain
+	// LbrynetServerDrainHook is the signature for custom LbrynetServerDrain hook methods
+	LbrynetServerDrainHook func(boil.Executor, *LbrynetServerDrain) error
+
+	lbrynetServerDrainQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	lbrynetServerDrainType                 = reflect.TypeOf(&LbrynetServerDrain{})
+	lbrynetServerDrainMapping              = queries.MakeStructMapping(lbrynetServerDrainType)
+	lbrynetServerDrainPrimaryKeyMapping, _ = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, lbrynetServerDrainPrimaryKeyColumns)
+	lbrynetServerDrainInsertCacheMut       sync.RWMutex
+	lbrynetServerDrainInsertCache          = make(map[string]insertCache)
+	lbrynetServerDrainUpdateCacheMut       sync.RWMutex
+	lbrynetServerDrainUpdateCache          = make(map[string]updateCache)
+	lbrynetServerDrainUpsertCacheMut       sync.RWMutex
+	lbrynetServerDrainUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var lbrynetServerDrainBeforeInsertHooks []LbrynetServerDrainHook
+var lbrynetServerDrainBeforeUpdateHooks []LbrynetServerDrainHook
+var lbrynetServerDrainBeforeDeleteHooks []LbrynetServerDrainHook
+var lbrynetServerDrainBeforeUpsertHooks []LbrynetServerDrainHook
+
+var lbrynetServerDrainAfterInsertHooks []LbrynetServerDrainHook
+var lbrynetServerDrainAfterSelectHooks []LbrynetServerDrainHook
+var lbrynetServerDrainAfterUpdateHooks []LbrynetServerDrainHook
+var lbrynetServerDrainAfterDeleteHooks []LbrynetServerDrainHook
+var lbrynetServerDrainAfterUpsertHooks []LbrynetServerDrainHook
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *LbrynetServerDrain) doBeforeInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainBeforeInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *LbrynetServerDrain) doBeforeUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainBeforeUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *LbrynetServerDrain) doBeforeDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainBeforeDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *LbrynetServerDrain) doBeforeUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainBeforeUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *LbrynetServerDrain) doAfterInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainAfterInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *LbrynetServerDrain) doAfterSelectHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainAfterSelectHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *LbrynetServerDrain) doAfterUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainAfterUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *LbrynetServerDrain) doAfterDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainAfterDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *LbrynetServerDrain) doAfterUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range lbrynetServerDrainAfterUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddLbrynetServerDrainHook registers your hook function for all future operations.
+func AddLbrynetServerDrainHook(hookPoint boil.HookPoint, lbrynetServerDrainHook LbrynetServerDrainHook) {
+	switch hookPoint {
+	case boil.BeforeInsertHook:
+		lbrynetServerDrainBeforeInsertHooks = append(lbrynetServerDrainBeforeInsertHooks, lbrynetServerDrainHook)
+	case boil.BeforeUpdateHook:
+		lbrynetServerDrainBeforeUpdateHooks = append(lbrynetServerDrainBeforeUpdateHooks, lbrynetServerDrainHook)
+	case boil.BeforeDeleteHook:
+		lbrynetServerDrainBeforeDeleteHooks = append(lbrynetServerDrainBeforeDeleteHooks, lbrynetServerDrainHook)
+	case boil.BeforeUpsertHook:
+		lbrynetServerDrainBeforeUpsertHooks = append(lbrynetServerDrainBeforeUpsertHooks, lbrynetServerDrainHook)
+	case boil.AfterInsertHook:
+		lbrynetServerDrainAfterInsertHooks = append(lbrynetServerDrainAfterInsertHooks, lbrynetServerDrainHook)
+	case boil.AfterSelectHook:
+		lbrynetServerDrainAfterSelectHooks = append(lbrynetServerDrainAfterSelectHooks, lbrynetServerDrainHook)
+	case boil.AfterUpdateHook:
+		lbrynetServerDrainAfterUpdateHooks = append(lbrynetServerDrainAfterUpdateHooks, lbrynetServerDrainHook)
+	case boil.AfterDeleteHook:
+		lbrynetServerDrainAfterDeleteHooks = append(lbrynetServerDrainAfterDeleteHooks, lbrynetServerDrainHook)
+	case boil.AfterUpsertHook:
+		lbrynetServerDrainAfterUpsertHooks = append(lbrynetServerDrainAfterUpsertHooks, lbrynetServerDrainHook)
+	}
+}
+
+// OneG returns a single lbrynetServerDrain record from the query using the global executor.
+func (q lbrynetServerDrainQuery) OneG() (*LbrynetServerDrain, error) {
+	return q.One(boil.GetDB())
+}
+
+// One returns a single lbrynetServerDrain record from the query.
+func (q lbrynetServerDrainQuery) One(exec boil.Executor) (*LbrynetServerDrain, error) {
+	o := &LbrynetServerDrain{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(nil, exec, o)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for lbrynet_server_drains")
+	}
+
+	if err := o.doAfterSelectHooks(exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// AllG returns all LbrynetServerDrain records from the query using the global executor.
+func (q lbrynetServerDrainQuery) AllG() (LbrynetServerDrainSlice, error) {
+	return q.All(boil.GetDB())
+}
+
+// All returns all LbrynetServerDrain records from the query.
+func (q lbrynetServerDrainQuery) All(exec boil.Executor) (LbrynetServerDrainSlice, error) {
+	var o []*LbrynetServerDrain
+
+	err := q.Bind(nil, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to LbrynetServerDrain slice")
+	}
+
+	if len(lbrynetServerDrainAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// CountG returns the count of all LbrynetServerDrain records in the query, and panics on error.
+func (q lbrynetServerDrainQuery) CountG() (int64, error) {
+	return q.Count(boil.GetDB())
+}
+
+// Count returns the count of all LbrynetServerDrain records in the query.
+func (q lbrynetServerDrainQuery) Count(exec boil.Executor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count lbrynet_server_drains rows")
+	}
+
+	return count, nil
+}
+
+// ExistsG checks if the row exists in the table, and panics on error.
+func (q lbrynetServerDrainQuery) ExistsG() (bool, error) {
+	return q.Exists(boil.GetDB())
+}
+
+// Exists checks if the row exists in the table.
+func (q lbrynetServerDrainQuery) Exists(exec boil.Executor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if lbrynet_server_drains exists")
+	}
+
+	return count > 0, nil
+}
+
+// LbrynetServerDrains retrieves all the records using an executor.
+func LbrynetServerDrains(mods ...qm.QueryMod) lbrynetServerDrainQuery {
+	mods = append(mods, qm.From("\"lbrynet_server_drains\""))
+	return lbrynetServerDrainQuery{NewQuery(mods...)}
+}
+
+// FindLbrynetServerDrainG retrieves a single record by ID.
+func FindLbrynetServerDrainG(address string, selectCols ...string) (*LbrynetServerDrain, error) {
+	return FindLbrynetServerDrain(boil.GetDB(), address, selectCols...)
+}
+
+// FindLbrynetServerDrain retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindLbrynetServerDrain(exec boil.Executor, address string, selectCols ...string) (*LbrynetServerDrain, error) {
+	lbrynetServerDrainObj := &LbrynetServerDrain{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"lbrynet_server_drains\" where \"address\"=$1", sel,
+	)
+
+	q := queries.Raw(query, address)
+
+	err := q.Bind(nil, exec, lbrynetServerDrainObj)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from lbrynet_server_drains")
+	}
+
+	return lbrynetServerDrainObj, nil
+}
+
+// InsertG a single record. See Insert for whitelist behavior description.
+func (o *LbrynetServerDrain) InsertG(columns boil.Columns) error {
+	return o.Insert(boil.GetDB(), columns)
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *LbrynetServerDrain) Insert(exec boil.Executor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no lbrynet_server_drains provided for insertion")
+	}
+
+	var err error
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeInsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(lbrynetServerDrainColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	lbrynetServerDrainInsertCacheMut.RLock()
+	cache, cached := lbrynetServerDrainInsertCache[key]
+	lbrynetServerDrainInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			lbrynetServerDrainAllColumns,
+			lbrynetServerDrainColumnsWithDefault,
+			lbrynetServerDrainColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"lbrynet_server_drains\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"lbrynet_server_drains\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into lbrynet_server_drains")
+	}
+
+	if !cached {
+		lbrynetServerDrainInsertCacheMut.Lock()
+		lbrynetServerDrainInsertCache[key] = cache
+		lbrynetServerDrainInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(exec)
+}
+
+// UpdateG a single LbrynetServerDrain record using the global executor.
+// See Update for more documentation.
+func (o *LbrynetServerDrain) UpdateG(columns boil.Columns) (int64, error) {
+	return o.Update(boil.GetDB(), columns)
+}
+
+// Update uses an executor to update the LbrynetServerDrain.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *LbrynetServerDrain) Update(exec boil.Executor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	lbrynetServerDrainUpdateCacheMut.RLock()
+	cache, cached := lbrynetServerDrainUpdateCache[key]
+	lbrynetServerDrainUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			lbrynetServerDrainAllColumns,
+			lbrynetServerDrainPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update lbrynet_server_drains, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"lbrynet_server_drains\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, lbrynetServerDrainPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, append(wl, lbrynetServerDrainPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, values)
+	}
+
+	var result sql.Result
+	result, err = exec.Exec(cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update lbrynet_server_drains row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for lbrynet_server_drains")
+	}
+
+	if !cached {
+		lbrynetServerDrainUpdateCacheMut.Lock()
+		lbrynetServerDrainUpdateCache[key] = cache
+		lbrynetServerDrainUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(exec)
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (q lbrynetServerDrainQuery) UpdateAllG(cols M) (int64, error) {
+	return q.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q lbrynetServerDrainQuery) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for lbrynet_server_drains")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for lbrynet_server_drains")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (o LbrynetServerDrainSlice) UpdateAllG(cols M) (int64, error) {
+	return o.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o LbrynetServerDrainSlice) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), lbrynetServerDrainPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"lbrynet_server_drains\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, lbrynetServerDrainPrimaryKeyColumns, len(o)))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in lbrynetServerDrain slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all lbrynetServerDrain")
+	}
+	return rowsAff, nil
+}
+
+// UpsertG attempts an insert, and does an update or ignore on conflict.
+func (o *LbrynetServerDrain) UpsertG(updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	return o.Upsert(boil.GetDB(), updateOnConflict, conflictColumns, updateColumns, insertColumns)
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *LbrynetServerDrain) Upsert(exec boil.Executor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no lbrynet_server_drains provided for upsert")
+	}
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeUpsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(lbrynetServerDrainColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	lbrynetServerDrainUpsertCacheMut.RLock()
+	cache, cached := lbrynetServerDrainUpsertCache[key]
+	lbrynetServerDrainUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, ret := insertColumns.InsertColumnSet(
+			lbrynetServerDrainAllColumns,
+			lbrynetServerDrainColumnsWithDefault,
+			lbrynetServerDrainColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			lbrynetServerDrainAllColumns,
+			lbrynetServerDrainPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert lbrynet_server_drains, could not build update column list")
+		}
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(lbrynetServerDrainPrimaryKeyColumns))
+			copy(conflict, lbrynetServerDrainPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"lbrynet_server_drains\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(lbrynetServerDrainType, lbrynetServerDrainMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(returns...)
+		if err == sql.ErrNoRows {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert lbrynet_server_drains")
+	}
+
+	if !cached {
+		lbrynetServerDrainUpsertCacheMut.Lock()
+		lbrynetServerDrainUpsertCache[key] = cache
+		lbrynetServerDrainUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(exec)
+}
+
+// DeleteG deletes a single LbrynetServerDrain record.
+// DeleteG will match against the primary key column to find the record to delete.
+func (o *LbrynetServerDrain) DeleteG() (int64, error) {
+	return o.Delete(boil.GetDB())
+}
+
+// Delete deletes a single LbrynetServerDrain record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *LbrynetServerDrain) Delete(exec boil.Executor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no LbrynetServerDrain provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), lbrynetServerDrainPrimaryKeyMapping)
+	sql := "DELETE FROM \"lbrynet_server_drains\" WHERE \"address\"=$1"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from lbrynet_server_drains")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for lbrynet_server_drains")
+	}
+
+	if err := o.doAfterDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q lbrynetServerDrainQuery) DeleteAll(exec boil.Executor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no lbrynetServerDrainQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from lbrynet_server_drains")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for lbrynet_server_drains")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAllG deletes all rows in the slice.
+func (o LbrynetServerDrainSlice) DeleteAllG() (int64, error) {
+	return o.DeleteAll(boil.GetDB())
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o LbrynetServerDrainSlice) DeleteAll(exec boil.Executor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(lbrynetServerDrainBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), lbrynetServerDrainPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"lbrynet_server_drains\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, lbrynetServerDrainPrimaryKeyColumns, len(o))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from lbrynetServerDrain slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for lbrynet_server_drains")
+	}
+
+	if len(lbrynetServerDrainAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// ReloadG refetches the object from the database using the primary keys.
+func (o *LbrynetServerDrain) ReloadG() error {
+	if o == nil {
+		return errors.New("models: no LbrynetServerDrain provided for reload")
+	}
+
+	return o.Reload(boil.GetDB())
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *LbrynetServerDrain) Reload(exec boil.Executor) error {
+	ret, err := FindLbrynetServerDrain(exec, o.Address)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAllG refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *LbrynetServerDrainSlice) ReloadAllG() error {
+	if o == nil {
+		return errors.New("models: empty LbrynetServerDrainSlice provided for reload all")
+	}
+
+	return o.ReloadAll(boil.GetDB())
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *LbrynetServerDrainSlice) ReloadAll(exec boil.Executor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := LbrynetServerDrainSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), lbrynetServerDrainPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"lbrynet_server_drains\".* FROM \"lbrynet_server_drains\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, lbrynetServerDrainPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(nil, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in LbrynetServerDrainSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// LbrynetServerDrainExistsG checks if the LbrynetServerDrain row exists.
+func LbrynetServerDrainExistsG(address string) (bool, error) {
+	return LbrynetServerDrainExists(boil.GetDB(), address)
+}
+
+// LbrynetServerDrainExists checks if the LbrynetServerDrain row exists.
+func LbrynetServerDrainExists(exec boil.Executor, address string) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"lbrynet_server_drains\" where \"address\"=$1 limit 1)"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, address)
+	}
+
+	row := exec.QueryRow(sql, address)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if lbrynet_server_drains exists")
+	}
+
+	return exists, nil
+}
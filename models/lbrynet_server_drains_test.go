@@ -0,0 +1,713 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/randomize"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+var (
+	// Relationships sometimes use the reflection helper queries.Equal/queries.Assign
+	// so force a package dependency in case they don't.
+	_ = queries.Equal
+)
+
+func testLbrynetServerDrains(t *testing.T) {
+	t.Parallel()
+
+	query := LbrynetServerDrains()
+
+	if query.Query == nil {
+		t.Error("expected a query, got nothing")
+	}
+}
+
+func testLbrynetServerDrainsDelete(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := o.Delete(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testLbrynetServerDrainsQueryDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := LbrynetServerDrains().DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testLbrynetServerDrainsSliceDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := LbrynetServerDrainSlice{o}
+
+	if rowsAff, err := slice.DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testLbrynetServerDrainsExists(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	e, err := LbrynetServerDrainExists(tx, o.Address)
+	if err != nil {
+		t.Errorf("Unable to check if LbrynetServerDrain exists: %s", err)
+	}
+	if !e {
+		t.Errorf("Expected LbrynetServerDrainExists to return true, but got false.")
+	}
+}
+
+func testLbrynetServerDrainsFind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	lbrynetServerDrainFound, err := FindLbrynetServerDrain(tx, o.Address)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if lbrynetServerDrainFound == nil {
+		t.Error("want a record, got nil")
+	}
+}
+
+func testLbrynetServerDrainsBind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = LbrynetServerDrains().Bind(nil, tx, o); err != nil {
+		t.Error(err)
+	}
+}
+
+func testLbrynetServerDrainsOne(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if x, err := LbrynetServerDrains().One(tx); err != nil {
+		t.Error(err)
+	} else if x == nil {
+		t.Error("expected to get a non nil record")
+	}
+}
+
+func testLbrynetServerDrainsAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	lbrynetServerDrainOne := &LbrynetServerDrain{}
+	lbrynetServerDrainTwo := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, lbrynetServerDrainOne, lbrynetServerDrainDBTypes, false, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+	if err = randomize.Struct(seed, lbrynetServerDrainTwo, lbrynetServerDrainDBTypes, false, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = lbrynetServerDrainOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = lbrynetServerDrainTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := LbrynetServerDrains().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 2 {
+		t.Error("want 2 records, got:", len(slice))
+	}
+}
+
+func testLbrynetServerDrainsCount(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	seed := randomize.NewSeed()
+	lbrynetServerDrainOne := &LbrynetServerDrain{}
+	lbrynetServerDrainTwo := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, lbrynetServerDrainOne, lbrynetServerDrainDBTypes, false, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+	if err = randomize.Struct(seed, lbrynetServerDrainTwo, lbrynetServerDrainDBTypes, false, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = lbrynetServerDrainOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = lbrynetServerDrainTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 2 {
+		t.Error("want 2 records, got:", count)
+	}
+}
+
+func lbrynetServerDrainBeforeInsertHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainAfterInsertHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainAfterSelectHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainBeforeUpdateHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainAfterUpdateHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainBeforeDeleteHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainAfterDeleteHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainBeforeUpsertHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func lbrynetServerDrainAfterUpsertHook(e boil.Executor, o *LbrynetServerDrain) error {
+	*o = LbrynetServerDrain{}
+	return nil
+}
+
+func testLbrynetServerDrainsHooks(t *testing.T) {
+	t.Parallel()
+
+	var err error
+
+	empty := &LbrynetServerDrain{}
+	o := &LbrynetServerDrain{}
+
+	seed := randomize.NewSeed()
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, false); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain object: %s", err)
+	}
+
+	AddLbrynetServerDrainHook(boil.BeforeInsertHook, lbrynetServerDrainBeforeInsertHook)
+	if err = o.doBeforeInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeInsertHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainBeforeInsertHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.AfterInsertHook, lbrynetServerDrainAfterInsertHook)
+	if err = o.doAfterInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterInsertHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainAfterInsertHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.AfterSelectHook, lbrynetServerDrainAfterSelectHook)
+	if err = o.doAfterSelectHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterSelectHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterSelectHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainAfterSelectHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.BeforeUpdateHook, lbrynetServerDrainBeforeUpdateHook)
+	if err = o.doBeforeUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpdateHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainBeforeUpdateHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.AfterUpdateHook, lbrynetServerDrainAfterUpdateHook)
+	if err = o.doAfterUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpdateHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainAfterUpdateHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.BeforeDeleteHook, lbrynetServerDrainBeforeDeleteHook)
+	if err = o.doBeforeDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeDeleteHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainBeforeDeleteHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.AfterDeleteHook, lbrynetServerDrainAfterDeleteHook)
+	if err = o.doAfterDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterDeleteHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainAfterDeleteHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.BeforeUpsertHook, lbrynetServerDrainBeforeUpsertHook)
+	if err = o.doBeforeUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpsertHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainBeforeUpsertHooks = []LbrynetServerDrainHook{}
+
+	AddLbrynetServerDrainHook(boil.AfterUpsertHook, lbrynetServerDrainAfterUpsertHook)
+	if err = o.doAfterUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpsertHook function to empty object, but got: %#v", o)
+	}
+	lbrynetServerDrainAfterUpsertHooks = []LbrynetServerDrainHook{}
+}
+
+func testLbrynetServerDrainsInsert(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testLbrynetServerDrainsInsertWhitelist(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Whitelist(lbrynetServerDrainColumnsWithoutDefault...)); err != nil {
+		t.Error(err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testLbrynetServerDrainsReload(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = o.Reload(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testLbrynetServerDrainsReloadAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := LbrynetServerDrainSlice{o}
+
+	if err = slice.ReloadAll(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testLbrynetServerDrainsSelect(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := LbrynetServerDrains().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 1 {
+		t.Error("want one record, got:", len(slice))
+	}
+}
+
+var (
+	lbrynetServerDrainDBTypes = map[string]string{`Address`: `character varying`, `CreatedAt`: `timestamp without time zone`}
+	_                         = bytes.MinRead
+)
+
+func testLbrynetServerDrainsUpdate(t *testing.T) {
+	t.Parallel()
+
+	if 0 == len(lbrynetServerDrainPrimaryKeyColumns) {
+		t.Skip("Skipping table with no primary key columns")
+	}
+	if len(lbrynetServerDrainAllColumns) == len(lbrynetServerDrainPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	if rowsAff, err := o.Update(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only affect one row but affected", rowsAff)
+	}
+}
+
+func testLbrynetServerDrainsSliceUpdateAll(t *testing.T) {
+	t.Parallel()
+
+	if len(lbrynetServerDrainAllColumns) == len(lbrynetServerDrainPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &LbrynetServerDrain{}
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, lbrynetServerDrainDBTypes, true, lbrynetServerDrainPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	// Remove Primary keys and unique columns from what we plan to update
+	var fields []string
+	if strmangle.StringSliceMatch(lbrynetServerDrainAllColumns, lbrynetServerDrainPrimaryKeyColumns) {
+		fields = lbrynetServerDrainAllColumns
+	} else {
+		fields = strmangle.SetComplement(
+			lbrynetServerDrainAllColumns,
+			lbrynetServerDrainPrimaryKeyColumns,
+		)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	typ := reflect.TypeOf(o).Elem()
+	n := typ.NumField()
+
+	updateMap := M{}
+	for _, col := range fields {
+		for i := 0; i < n; i++ {
+			f := typ.Field(i)
+			if f.Tag.Get("boil") == col {
+				updateMap[col] = value.Field(i).Interface()
+			}
+		}
+	}
+
+	slice := LbrynetServerDrainSlice{o}
+	if rowsAff, err := slice.UpdateAll(tx, updateMap); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("wanted one record updated but got", rowsAff)
+	}
+}
+
+func testLbrynetServerDrainsUpsert(t *testing.T) {
+	t.Parallel()
+
+	if len(lbrynetServerDrainAllColumns) == len(lbrynetServerDrainPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	// Attempt the INSERT side of an UPSERT
+	o := LbrynetServerDrain{}
+	if err = randomize.Struct(seed, &o, lbrynetServerDrainDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Upsert(tx, false, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert LbrynetServerDrain: %s", err)
+	}
+
+	count, err := LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	// Attempt the UPDATE side of an UPSERT
+	if err = randomize.Struct(seed, &o, lbrynetServerDrainDBTypes, false, lbrynetServerDrainPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize LbrynetServerDrain struct: %s", err)
+	}
+
+	if err = o.Upsert(tx, true, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert LbrynetServerDrain: %s", err)
+	}
+
+	count, err = LbrynetServerDrains().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
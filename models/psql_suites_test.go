@@ -6,11 +6,21 @@ package models
 import "testing"
 
 func TestUpsert(t *testing.T) {
+	t.Run("DeadLetters", testDeadLettersUpsert)
+
 	t.Run("GorpMigrations", testGorpMigrationsUpsert)
 
+	t.Run("LbrynetServerDrains", testLbrynetServerDrainsUpsert)
+
 	t.Run("LbrynetServers", testLbrynetServersUpsert)
 
 	t.Run("QueryLogs", testQueryLogsUpsert)
 
+	t.Run("UserCosts", testUserCostsUpsert)
+
+	t.Run("UserEntitlements", testUserEntitlementsUpsert)
+
+	t.Run("UserSessions", testUserSessionsUpsert)
+
 	t.Run("Users", testUsersUpsert)
 }
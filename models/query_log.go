@@ -23,12 +23,13 @@ import (
 
 // QueryLog is an object representing the database table.
 type QueryLog struct {
-	ID        int       `boil:"id" json:"id" toml:"id" yaml:"id"`
-	Method    string    `boil:"method" json:"method" toml:"method" yaml:"method"`
-	Timestamp time.Time `boil:"timestamp" json:"timestamp" toml:"timestamp" yaml:"timestamp"`
-	UserID    null.Int  `boil:"user_id" json:"user_id,omitempty" toml:"user_id" yaml:"user_id,omitempty"`
-	RemoteIP  string    `boil:"remote_ip" json:"remote_ip" toml:"remote_ip" yaml:"remote_ip"`
-	Body      null.JSON `boil:"body" json:"body,omitempty" toml:"body" yaml:"body,omitempty"`
+	ID        int         `boil:"id" json:"id" toml:"id" yaml:"id"`
+	Method    string      `boil:"method" json:"method" toml:"method" yaml:"method"`
+	Timestamp time.Time   `boil:"timestamp" json:"timestamp" toml:"timestamp" yaml:"timestamp"`
+	UserID    null.Int    `boil:"user_id" json:"user_id,omitempty" toml:"user_id" yaml:"user_id,omitempty"`
+	RemoteIP  string      `boil:"remote_ip" json:"remote_ip" toml:"remote_ip" yaml:"remote_ip"`
+	Body      null.JSON   `boil:"body" json:"body,omitempty" toml:"body" yaml:"body,omitempty"`
+	Outcome   null.String `boil:"outcome" json:"outcome,omitempty" toml:"outcome" yaml:"outcome,omitempty"`
 
 	R *queryLogR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L queryLogL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -41,6 +42,7 @@ var QueryLogColumns = struct {
 	UserID    string
 	RemoteIP  string
 	Body      string
+	Outcome   string
 }{
 	ID:        "id",
 	Method:    "method",
@@ -48,6 +50,7 @@ var QueryLogColumns = struct {
 	UserID:    "user_id",
 	RemoteIP:  "remote_ip",
 	Body:      "body",
+	Outcome:   "outcome",
 }
 
 // Generated where
@@ -105,6 +108,7 @@ var QueryLogWhere = struct {
 	UserID    whereHelpernull_Int
 	RemoteIP  whereHelperstring
 	Body      whereHelpernull_JSON
+	Outcome   whereHelpernull_String
 }{
 	ID:        whereHelperint{field: "\"query_log\".\"id\""},
 	Method:    whereHelperstring{field: "\"query_log\".\"method\""},
@@ -112,6 +116,7 @@ var QueryLogWhere = struct {
 	UserID:    whereHelpernull_Int{field: "\"query_log\".\"user_id\""},
 	RemoteIP:  whereHelperstring{field: "\"query_log\".\"remote_ip\""},
 	Body:      whereHelpernull_JSON{field: "\"query_log\".\"body\""},
+	Outcome:   whereHelpernull_String{field: "\"query_log\".\"outcome\""},
 }
 
 // QueryLogRels is where relationship names are stored.
@@ -131,8 +136,8 @@ func (*queryLogR) NewStruct() *queryLogR {
 type queryLogL struct{}
 
 var (
-	queryLogAllColumns            = []string{"id", "method", "timestamp", "user_id", "remote_ip", "body"}
-	queryLogColumnsWithoutDefault = []string{"method", "user_id", "remote_ip", "body"}
+	queryLogAllColumns            = []string{"id", "method", "timestamp", "user_id", "remote_ip", "body", "outcome"}
+	queryLogColumnsWithoutDefault = []string{"method", "user_id", "remote_ip", "body", "outcome"}
 	queryLogColumnsWithDefault    = []string{"id", "timestamp"}
 	queryLogPrimaryKeyColumns     = []string{"id"}
 )
@@ -552,7 +552,7 @@ func testQueryLogsSelect(t *testing.T) {
 }
 
 var (
-	queryLogDBTypes = map[string]string{`ID`: `integer`, `Method`: `character varying`, `Timestamp`: `timestamp without time zone`, `UserID`: `integer`, `RemoteIP`: `character varying`, `Body`: `jsonb`}
+	queryLogDBTypes = map[string]string{`ID`: `integer`, `Method`: `character varying`, `Timestamp`: `timestamp without time zone`, `UserID`: `integer`, `RemoteIP`: `character varying`, `Body`: `jsonb`, `Outcome`: `character varying`}
 	_               = bytes.MinRead
 )
 
@@ -0,0 +1,942 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/queries/qmhelper"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+// UserCost is an object representing the database table.
+type UserCost struct {
+	UserID    int       `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	Total     int64     `boil:"total" json:"total" toml:"total" yaml:"total"`
+	UpdatedAt time.Time `boil:"updated_at" json:"updated_at" toml:"updated_at" yaml:"updated_at"`
+
+	R *userCostR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L userCostL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var UserCostColumns = struct {
+	UserID    string
+	Total     string
+	UpdatedAt string
+}{
+	UserID:    "user_id",
+	Total:     "total",
+	UpdatedAt: "updated_at",
+}
+
+// Generated where
+
+type whereHelperint64 struct{ field string }
+
+func (w whereHelperint64) EQ(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperint64) NEQ(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperint64) LT(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperint64) LTE(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperint64) GT(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperint64) GTE(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+
+var UserCostWhere = struct {
+	UserID    whereHelperint
+	Total     whereHelperint64
+	UpdatedAt whereHelpertime_Time
+}{
+	UserID:    whereHelperint{field: "\"user_costs\".\"user_id\""},
+	Total:     whereHelperint64{field: "\"user_costs\".\"total\""},
+	UpdatedAt: whereHelpertime_Time{field: "\"user_costs\".\"updated_at\""},
+}
+
+// UserCostRels is where relationship names are stored.
+var UserCostRels = struct {
+}{}
+
+// userCostR is where relationships are stored.
+type userCostR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*userCostR) NewStruct() *userCostR {
+	return &userCostR{}
+}
+
+// userCostL is where Load methods for each relationship are stored.
+type userCostL struct{}
+
+var (
+	userCostAllColumns            = []string{"user_id", "total", "updated_at"}
+	userCostColumnsWithoutDefault = []string{"user_id"}
+	userCostColumnsWithDefault    = []string{"total", "updated_at"}
+	userCostPrimaryKeyColumns     = []string{"user_id"}
+)
+
+type (
+	// UserCostSlice is an alias for a slice of pointers to UserCost.
+	// This should generally be used opposed to []UserCost.
+	UserCostSlice []*UserCost
+	// UserCostHook is the signature for custom UserCost hook methods
+	UserCostHook func(boil.Executor, *UserCost) error
+
+	userCostQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	userCostType                 = reflect.TypeOf(&UserCost{})
+	userCostMapping              = queries.MakeStructMapping(userCostType)
+	userCostPrimaryKeyMapping, _ = queries.BindMapping(userCostType, userCostMapping, userCostPrimaryKeyColumns)
+	userCostInsertCacheMut       sync.RWMutex
+	userCostInsertCache          = make(map[string]insertCache)
+	userCostUpdateCacheMut       sync.RWMutex
+	userCostUpdateCache          = make(map[string]updateCache)
+	userCostUpsertCacheMut       sync.RWMutex
+	userCostUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var userCostBeforeInsertHooks []UserCostHook
+var userCostBeforeUpdateHooks []UserCostHook
+var userCostBeforeDeleteHooks []UserCostHook
+var userCostBeforeUpsertHooks []UserCostHook
+
+var userCostAfterInsertHooks []UserCostHook
+var userCostAfterSelectHooks []UserCostHook
+var userCostAfterUpdateHooks []UserCostHook
+var userCostAfterDeleteHooks []UserCostHook
+var userCostAfterUpsertHooks []UserCostHook
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *UserCost) doBeforeInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostBeforeInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *UserCost) doBeforeUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostBeforeUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *UserCost) doBeforeDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostBeforeDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *UserCost) doBeforeUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostBeforeUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *UserCost) doAfterInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostAfterInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *UserCost) doAfterSelectHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostAfterSelectHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *UserCost) doAfterUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostAfterUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *UserCost) doAfterDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostAfterDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *UserCost) doAfterUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userCostAfterUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddUserCostHook registers your hook function for all future operations.
+func AddUserCostHook(hookPoint boil.HookPoint, userCostHook UserCostHook) {
+	switch hookPoint {
+	case boil.BeforeInsertHook:
+		userCostBeforeInsertHooks = append(userCostBeforeInsertHooks, userCostHook)
+	case boil.BeforeUpdateHook:
+		userCostBeforeUpdateHooks = append(userCostBeforeUpdateHooks, userCostHook)
+	case boil.BeforeDeleteHook:
+		userCostBeforeDeleteHooks = append(userCostBeforeDeleteHooks, userCostHook)
+	case boil.BeforeUpsertHook:
+		userCostBeforeUpsertHooks = append(userCostBeforeUpsertHooks, userCostHook)
+	case boil.AfterInsertHook:
+		userCostAfterInsertHooks = append(userCostAfterInsertHooks, userCostHook)
+	case boil.AfterSelectHook:
+		userCostAfterSelectHooks = append(userCostAfterSelectHooks, userCostHook)
+	case boil.AfterUpdateHook:
+		userCostAfterUpdateHooks = append(userCostAfterUpdateHooks, userCostHook)
+	case boil.AfterDeleteHook:
+		userCostAfterDeleteHooks = append(userCostAfterDeleteHooks, userCostHook)
+	case boil.AfterUpsertHook:
+		userCostAfterUpsertHooks = append(userCostAfterUpsertHooks, userCostHook)
+	}
+}
+
+// OneG returns a single userCost record from the query using the global executor.
+func (q userCostQuery) OneG() (*UserCost, error) {
+	return q.One(boil.GetDB())
+}
+
+// One returns a single userCost record from the query.
+func (q userCostQuery) One(exec boil.Executor) (*UserCost, error) {
+	o := &UserCost{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(nil, exec, o)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for user_costs")
+	}
+
+	if err := o.doAfterSelectHooks(exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// AllG returns all UserCost records from the query using the global executor.
+func (q userCostQuery) AllG() (UserCostSlice, error) {
+	return q.All(boil.GetDB())
+}
+
+// All returns all UserCost records from the query.
+func (q userCostQuery) All(exec boil.Executor) (UserCostSlice, error) {
+	var o []*UserCost
+
+	err := q.Bind(nil, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to UserCost slice")
+	}
+
+	if len(userCostAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// CountG returns the count of all UserCost records in the query, and panics on error.
+func (q userCostQuery) CountG() (int64, error) {
+	return q.Count(boil.GetDB())
+}
+
+// Count returns the count of all UserCost records in the query.
+func (q userCostQuery) Count(exec boil.Executor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count user_costs rows")
+	}
+
+	return count, nil
+}
+
+// ExistsG checks if the row exists in the table, and panics on error.
+func (q userCostQuery) ExistsG() (bool, error) {
+	return q.Exists(boil.GetDB())
+}
+
+// Exists checks if the row exists in the table.
+func (q userCostQuery) Exists(exec boil.Executor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if user_costs exists")
+	}
+
+	return count > 0, nil
+}
+
+// UserCosts retrieves all the records using an executor.
+func UserCosts(mods ...qm.QueryMod) userCostQuery {
+	mods = append(mods, qm.From("\"user_costs\""))
+	return userCostQuery{NewQuery(mods...)}
+}
+
+// FindUserCostG retrieves a single record by ID.
+func FindUserCostG(userID int, selectCols ...string) (*UserCost, error) {
+	return FindUserCost(boil.GetDB(), userID, selectCols...)
+}
+
+// FindUserCost retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindUserCost(exec boil.Executor, userID int, selectCols ...string) (*UserCost, error) {
+	userCostObj := &UserCost{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"user_costs\" where \"user_id\"=$1", sel,
+	)
+
+	q := queries.Raw(query, userID)
+
+	err := q.Bind(nil, exec, userCostObj)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from user_costs")
+	}
+
+	return userCostObj, nil
+}
+
+// InsertG a single record. See Insert for whitelist behavior description.
+func (o *UserCost) InsertG(columns boil.Columns) error {
+	return o.Insert(boil.GetDB(), columns)
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *UserCost) Insert(exec boil.Executor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_costs provided for insertion")
+	}
+
+	var err error
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.UpdatedAt.IsZero() {
+		o.UpdatedAt = currTime
+	}
+
+	if err := o.doBeforeInsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userCostColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	userCostInsertCacheMut.RLock()
+	cache, cached := userCostInsertCache[key]
+	userCostInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			userCostAllColumns,
+			userCostColumnsWithDefault,
+			userCostColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(userCostType, userCostMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(userCostType, userCostMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"user_costs\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"user_costs\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into user_costs")
+	}
+
+	if !cached {
+		userCostInsertCacheMut.Lock()
+		userCostInsertCache[key] = cache
+		userCostInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(exec)
+}
+
+// UpdateG a single UserCost record using the global executor.
+// See Update for more documentation.
+func (o *UserCost) UpdateG(columns boil.Columns) (int64, error) {
+	return o.Update(boil.GetDB(), columns)
+}
+
+// Update uses an executor to update the UserCost.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *UserCost) Update(exec boil.Executor, columns boil.Columns) (int64, error) {
+	currTime := time.Now().In(boil.GetLocation())
+
+	o.UpdatedAt = currTime
+
+	var err error
+	if err = o.doBeforeUpdateHooks(exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	userCostUpdateCacheMut.RLock()
+	cache, cached := userCostUpdateCache[key]
+	userCostUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			userCostAllColumns,
+			userCostPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update user_costs, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"user_costs\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, userCostPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(userCostType, userCostMapping, append(wl, userCostPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, values)
+	}
+
+	var result sql.Result
+	result, err = exec.Exec(cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update user_costs row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for user_costs")
+	}
+
+	if !cached {
+		userCostUpdateCacheMut.Lock()
+		userCostUpdateCache[key] = cache
+		userCostUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(exec)
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (q userCostQuery) UpdateAllG(cols M) (int64, error) {
+	return q.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q userCostQuery) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for user_costs")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for user_costs")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (o UserCostSlice) UpdateAllG(cols M) (int64, error) {
+	return o.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o UserCostSlice) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userCostPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"user_costs\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, userCostPrimaryKeyColumns, len(o)))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in userCost slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all userCost")
+	}
+	return rowsAff, nil
+}
+
+// UpsertG attempts an insert, and does an update or ignore on conflict.
+func (o *UserCost) UpsertG(updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	return o.Upsert(boil.GetDB(), updateOnConflict, conflictColumns, updateColumns, insertColumns)
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *UserCost) Upsert(exec boil.Executor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_costs provided for upsert")
+	}
+	currTime := time.Now().In(boil.GetLocation())
+
+	o.UpdatedAt = currTime
+
+	if err := o.doBeforeUpsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userCostColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	userCostUpsertCacheMut.RLock()
+	cache, cached := userCostUpsertCache[key]
+	userCostUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, ret := insertColumns.InsertColumnSet(
+			userCostAllColumns,
+			userCostColumnsWithDefault,
+			userCostColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			userCostAllColumns,
+			userCostPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert user_costs, could not build update column list")
+		}
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(userCostPrimaryKeyColumns))
+			copy(conflict, userCostPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"user_costs\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(userCostType, userCostMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(userCostType, userCostMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(returns...)
+		if err == sql.ErrNoRows {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert user_costs")
+	}
+
+	if !cached {
+		userCostUpsertCacheMut.Lock()
+		userCostUpsertCache[key] = cache
+		userCostUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(exec)
+}
+
+// DeleteG deletes a single UserCost record.
+// DeleteG will match against the primary key column to find the record to delete.
+func (o *UserCost) DeleteG() (int64, error) {
+	return o.Delete(boil.GetDB())
+}
+
+// Delete deletes a single UserCost record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *UserCost) Delete(exec boil.Executor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no UserCost provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), userCostPrimaryKeyMapping)
+	sql := "DELETE FROM \"user_costs\" WHERE \"user_id\"=$1"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from user_costs")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for user_costs")
+	}
+
+	if err := o.doAfterDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q userCostQuery) DeleteAll(exec boil.Executor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no userCostQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from user_costs")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_costs")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAllG deletes all rows in the slice.
+func (o UserCostSlice) DeleteAllG() (int64, error) {
+	return o.DeleteAll(boil.GetDB())
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o UserCostSlice) DeleteAll(exec boil.Executor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(userCostBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userCostPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"user_costs\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userCostPrimaryKeyColumns, len(o))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from userCost slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_costs")
+	}
+
+	if len(userCostAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// ReloadG refetches the object from the database using the primary keys.
+func (o *UserCost) ReloadG() error {
+	if o == nil {
+		return errors.New("models: no UserCost provided for reload")
+	}
+
+	return o.Reload(boil.GetDB())
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *UserCost) Reload(exec boil.Executor) error {
+	ret, err := FindUserCost(exec, o.UserID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAllG refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserCostSlice) ReloadAllG() error {
+	if o == nil {
+		return errors.New("models: empty UserCostSlice provided for reload all")
+	}
+
+	return o.ReloadAll(boil.GetDB())
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserCostSlice) ReloadAll(exec boil.Executor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := UserCostSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userCostPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"user_costs\".* FROM \"user_costs\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userCostPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(nil, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in UserCostSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// UserCostExistsG checks if the UserCost row exists.
+func UserCostExistsG(userID int) (bool, error) {
+	return UserCostExists(boil.GetDB(), userID)
+}
+
+// UserCostExists checks if the UserCost row exists.
+func UserCostExists(exec boil.Executor, userID int) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"user_costs\" where \"user_id\"=$1 limit 1)"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, userID)
+	}
+
+	row := exec.QueryRow(sql, userID)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if user_costs exists")
+	}
+
+	return exists, nil
+}
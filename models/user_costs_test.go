@@ -0,0 +1,713 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/randomize"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+var (
+	// Relationships sometimes use the reflection helper queries.Equal/queries.Assign
+	// so force a package dependency in case they don't.
+	_ = queries.Equal
+)
+
+func testUserCosts(t *testing.T) {
+	t.Parallel()
+
+	query := UserCosts()
+
+	if query.Query == nil {
+		t.Error("expected a query, got nothing")
+	}
+}
+
+func testUserCostsDelete(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := o.Delete(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserCostsQueryDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := UserCosts().DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserCostsSliceDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserCostSlice{o}
+
+	if rowsAff, err := slice.DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserCostsExists(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	e, err := UserCostExists(tx, o.UserID)
+	if err != nil {
+		t.Errorf("Unable to check if UserCost exists: %s", err)
+	}
+	if !e {
+		t.Errorf("Expected UserCostExists to return true, but got false.")
+	}
+}
+
+func testUserCostsFind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	userCostFound, err := FindUserCost(tx, o.UserID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if userCostFound == nil {
+		t.Error("want a record, got nil")
+	}
+}
+
+func testUserCostsBind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = UserCosts().Bind(nil, tx, o); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserCostsOne(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if x, err := UserCosts().One(tx); err != nil {
+		t.Error(err)
+	} else if x == nil {
+		t.Error("expected to get a non nil record")
+	}
+}
+
+func testUserCostsAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	userCostOne := &UserCost{}
+	userCostTwo := &UserCost{}
+	if err = randomize.Struct(seed, userCostOne, userCostDBTypes, false, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userCostTwo, userCostDBTypes, false, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userCostOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userCostTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserCosts().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 2 {
+		t.Error("want 2 records, got:", len(slice))
+	}
+}
+
+func testUserCostsCount(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	seed := randomize.NewSeed()
+	userCostOne := &UserCost{}
+	userCostTwo := &UserCost{}
+	if err = randomize.Struct(seed, userCostOne, userCostDBTypes, false, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userCostTwo, userCostDBTypes, false, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userCostOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userCostTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 2 {
+		t.Error("want 2 records, got:", count)
+	}
+}
+
+func userCostBeforeInsertHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostAfterInsertHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostAfterSelectHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostBeforeUpdateHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostAfterUpdateHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostBeforeDeleteHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostAfterDeleteHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostBeforeUpsertHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func userCostAfterUpsertHook(e boil.Executor, o *UserCost) error {
+	*o = UserCost{}
+	return nil
+}
+
+func testUserCostsHooks(t *testing.T) {
+	t.Parallel()
+
+	var err error
+
+	empty := &UserCost{}
+	o := &UserCost{}
+
+	seed := randomize.NewSeed()
+	if err = randomize.Struct(seed, o, userCostDBTypes, false); err != nil {
+		t.Errorf("Unable to randomize UserCost object: %s", err)
+	}
+
+	AddUserCostHook(boil.BeforeInsertHook, userCostBeforeInsertHook)
+	if err = o.doBeforeInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeInsertHook function to empty object, but got: %#v", o)
+	}
+	userCostBeforeInsertHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.AfterInsertHook, userCostAfterInsertHook)
+	if err = o.doAfterInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterInsertHook function to empty object, but got: %#v", o)
+	}
+	userCostAfterInsertHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.AfterSelectHook, userCostAfterSelectHook)
+	if err = o.doAfterSelectHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterSelectHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterSelectHook function to empty object, but got: %#v", o)
+	}
+	userCostAfterSelectHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.BeforeUpdateHook, userCostBeforeUpdateHook)
+	if err = o.doBeforeUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpdateHook function to empty object, but got: %#v", o)
+	}
+	userCostBeforeUpdateHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.AfterUpdateHook, userCostAfterUpdateHook)
+	if err = o.doAfterUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpdateHook function to empty object, but got: %#v", o)
+	}
+	userCostAfterUpdateHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.BeforeDeleteHook, userCostBeforeDeleteHook)
+	if err = o.doBeforeDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeDeleteHook function to empty object, but got: %#v", o)
+	}
+	userCostBeforeDeleteHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.AfterDeleteHook, userCostAfterDeleteHook)
+	if err = o.doAfterDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterDeleteHook function to empty object, but got: %#v", o)
+	}
+	userCostAfterDeleteHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.BeforeUpsertHook, userCostBeforeUpsertHook)
+	if err = o.doBeforeUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpsertHook function to empty object, but got: %#v", o)
+	}
+	userCostBeforeUpsertHooks = []UserCostHook{}
+
+	AddUserCostHook(boil.AfterUpsertHook, userCostAfterUpsertHook)
+	if err = o.doAfterUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpsertHook function to empty object, but got: %#v", o)
+	}
+	userCostAfterUpsertHooks = []UserCostHook{}
+}
+
+func testUserCostsInsert(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserCostsInsertWhitelist(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Whitelist(userCostColumnsWithoutDefault...)); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserCostsReload(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = o.Reload(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserCostsReloadAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserCostSlice{o}
+
+	if err = slice.ReloadAll(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserCostsSelect(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserCosts().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 1 {
+		t.Error("want one record, got:", len(slice))
+	}
+}
+
+var (
+	userCostDBTypes = map[string]string{`UserID`: `integer`, `Total`: `bigint`, `UpdatedAt`: `timestamp without time zone`}
+	_               = bytes.MinRead
+)
+
+func testUserCostsUpdate(t *testing.T) {
+	t.Parallel()
+
+	if 0 == len(userCostPrimaryKeyColumns) {
+		t.Skip("Skipping table with no primary key columns")
+	}
+	if len(userCostAllColumns) == len(userCostPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	if rowsAff, err := o.Update(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only affect one row but affected", rowsAff)
+	}
+}
+
+func testUserCostsSliceUpdateAll(t *testing.T) {
+	t.Parallel()
+
+	if len(userCostAllColumns) == len(userCostPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserCost{}
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userCostDBTypes, true, userCostPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	// Remove Primary keys and unique columns from what we plan to update
+	var fields []string
+	if strmangle.StringSliceMatch(userCostAllColumns, userCostPrimaryKeyColumns) {
+		fields = userCostAllColumns
+	} else {
+		fields = strmangle.SetComplement(
+			userCostAllColumns,
+			userCostPrimaryKeyColumns,
+		)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	typ := reflect.TypeOf(o).Elem()
+	n := typ.NumField()
+
+	updateMap := M{}
+	for _, col := range fields {
+		for i := 0; i < n; i++ {
+			f := typ.Field(i)
+			if f.Tag.Get("boil") == col {
+				updateMap[col] = value.Field(i).Interface()
+			}
+		}
+	}
+
+	slice := UserCostSlice{o}
+	if rowsAff, err := slice.UpdateAll(tx, updateMap); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("wanted one record updated but got", rowsAff)
+	}
+}
+
+func testUserCostsUpsert(t *testing.T) {
+	t.Parallel()
+
+	if len(userCostAllColumns) == len(userCostPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	// Attempt the INSERT side of an UPSERT
+	o := UserCost{}
+	if err = randomize.Struct(seed, &o, userCostDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Upsert(tx, false, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserCost: %s", err)
+	}
+
+	count, err := UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	// Attempt the UPDATE side of an UPSERT
+	if err = randomize.Struct(seed, &o, userCostDBTypes, false, userCostPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserCost struct: %s", err)
+	}
+
+	if err = o.Upsert(tx, true, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserCost: %s", err)
+	}
+
+	count, err = UserCosts().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
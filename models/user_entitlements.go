@@ -0,0 +1,931 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/queries/qmhelper"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+// UserEntitlement is an object representing the database table.
+type UserEntitlement struct {
+	UserID      int       `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	Entitlement string    `boil:"entitlement" json:"entitlement" toml:"entitlement" yaml:"entitlement"`
+	CreatedAt   time.Time `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+
+	R *userEntitlementR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L userEntitlementL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var UserEntitlementColumns = struct {
+	UserID      string
+	Entitlement string
+	CreatedAt   string
+}{
+	UserID:      "user_id",
+	Entitlement: "entitlement",
+	CreatedAt:   "created_at",
+}
+
+// Generated where
+
+var UserEntitlementWhere = struct {
+	UserID      whereHelperint
+	Entitlement whereHelperstring
+	CreatedAt   whereHelpertime_Time
+}{
+	UserID:      whereHelperint{field: "\"user_entitlements\".\"user_id\""},
+	Entitlement: whereHelperstring{field: "\"user_entitlements\".\"entitlement\""},
+	CreatedAt:   whereHelpertime_Time{field: "\"user_entitlements\".\"created_at\""},
+}
+
+// UserEntitlementRels is where relationship names are stored.
+var UserEntitlementRels = struct {
+}{}
+
+// userEntitlementR is where relationships are stored.
+type userEntitlementR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*userEntitlementR) NewStruct() *userEntitlementR {
+	return &userEntitlementR{}
+}
+
+// userEntitlementL is where Load methods for each relationship are stored.
+type userEntitlementL struct{}
+
+var (
+	userEntitlementAllColumns            = []string{"user_id", "entitlement", "created_at"}
+	userEntitlementColumnsWithoutDefault = []string{"user_id", "entitlement"}
+	userEntitlementColumnsWithDefault    = []string{"created_at"}
+	userEntitlementPrimaryKeyColumns     = []string{"user_id", "entitlement"}
+)
+
+type (
+	// UserEntitlementSlice is an alias for a slice of pointers to UserEntitlement.
+	// This should generally be used opposed to []UserEntitlement.
+	UserEntitlementSlice []*UserEntitlement
+	// UserEntitlementHook is the signature for custom UserEntitlement hook methods
+	UserEntitlementHook func(boil.Executor, *UserEntitlement) error
+
+	userEntitlementQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	userEntitlementType                 = reflect.TypeOf(&UserEntitlement{})
+	userEntitlementMapping              = queries.MakeStructMapping(userEntitlementType)
+	userEntitlementPrimaryKeyMapping, _ = queries.BindMapping(userEntitlementType, userEntitlementMapping, userEntitlementPrimaryKeyColumns)
+	userEntitlementInsertCacheMut       sync.RWMutex
+	userEntitlementInsertCache          = make(map[string]insertCache)
+	userEntitlementUpdateCacheMut       sync.RWMutex
+	userEntitlementUpdateCache          = make(map[string]updateCache)
+	userEntitlementUpsertCacheMut       sync.RWMutex
+	userEntitlementUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var userEntitlementBeforeInsertHooks []UserEntitlementHook
+var userEntitlementBeforeUpdateHooks []UserEntitlementHook
+var userEntitlementBeforeDeleteHooks []UserEntitlementHook
+var userEntitlementBeforeUpsertHooks []UserEntitlementHook
+
+var userEntitlementAfterInsertHooks []UserEntitlementHook
+var userEntitlementAfterSelectHooks []UserEntitlementHook
+var userEntitlementAfterUpdateHooks []UserEntitlementHook
+var userEntitlementAfterDeleteHooks []UserEntitlementHook
+var userEntitlementAfterUpsertHooks []UserEntitlementHook
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *UserEntitlement) doBeforeInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementBeforeInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *UserEntitlement) doBeforeUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementBeforeUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *UserEntitlement) doBeforeDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementBeforeDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *UserEntitlement) doBeforeUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementBeforeUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *UserEntitlement) doAfterInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementAfterInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *UserEntitlement) doAfterSelectHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementAfterSelectHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *UserEntitlement) doAfterUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementAfterUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *UserEntitlement) doAfterDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementAfterDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *UserEntitlement) doAfterUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userEntitlementAfterUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddUserEntitlementHook registers your hook function for all future operations.
+func AddUserEntitlementHook(hookPoint boil.HookPoint, userEntitlementHook UserEntitlementHook) {
+	switch hookPoint {
+	case boil.BeforeInsertHook:
+		userEntitlementBeforeInsertHooks = append(userEntitlementBeforeInsertHooks, userEntitlementHook)
+	case boil.BeforeUpdateHook:
+		userEntitlementBeforeUpdateHooks = append(userEntitlementBeforeUpdateHooks, userEntitlementHook)
+	case boil.BeforeDeleteHook:
+		userEntitlementBeforeDeleteHooks = append(userEntitlementBeforeDeleteHooks, userEntitlementHook)
+	case boil.BeforeUpsertHook:
+		userEntitlementBeforeUpsertHooks = append(userEntitlementBeforeUpsertHooks, userEntitlementHook)
+	case boil.AfterInsertHook:
+		userEntitlementAfterInsertHooks = append(userEntitlementAfterInsertHooks, userEntitlementHook)
+	case boil.AfterSelectHook:
+		userEntitlementAfterSelectHooks = append(userEntitlementAfterSelectHooks, userEntitlementHook)
+	case boil.AfterUpdateHook:
+		userEntitlementAfterUpdateHooks = append(userEntitlementAfterUpdateHooks, userEntitlementHook)
+	case boil.AfterDeleteHook:
+		userEntitlementAfterDeleteHooks = append(userEntitlementAfterDeleteHooks, userEntitlementHook)
+	case boil.AfterUpsertHook:
+		userEntitlementAfterUpsertHooks = append(userEntitlementAfterUpsertHooks, userEntitlementHook)
+	}
+}
+
+// OneG returns a single userEntitlement record from the query using the global executor.
+func (q userEntitlementQuery) OneG() (*UserEntitlement, error) {
+	return q.One(boil.GetDB())
+}
+
+// One returns a single userEntitlement record from the query.
+func (q userEntitlementQuery) One(exec boil.Executor) (*UserEntitlement, error) {
+	o := &UserEntitlement{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(nil, exec, o)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for user_entitlements")
+	}
+
+	if err := o.doAfterSelectHooks(exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// AllG returns all UserEntitlement records from the query using the global executor.
+func (q userEntitlementQuery) AllG() (UserEntitlementSlice, error) {
+	return q.All(boil.GetDB())
+}
+
+// All returns all UserEntitlement records from the query.
+func (q userEntitlementQuery) All(exec boil.Executor) (UserEntitlementSlice, error) {
+	var o []*UserEntitlement
+
+	err := q.Bind(nil, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to UserEntitlement slice")
+	}
+
+	if len(userEntitlementAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// CountG returns the count of all UserEntitlement records in the query, and panics on error.
+func (q userEntitlementQuery) CountG() (int64, error) {
+	return q.Count(boil.GetDB())
+}
+
+// Count returns the count of all UserEntitlement records in the query.
+func (q userEntitlementQuery) Count(exec boil.Executor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count user_entitlements rows")
+	}
+
+	return count, nil
+}
+
+// ExistsG checks if the row exists in the table, and panics on error.
+func (q userEntitlementQuery) ExistsG() (bool, error) {
+	return q.Exists(boil.GetDB())
+}
+
+// Exists checks if the row exists in the table.
+func (q userEntitlementQuery) Exists(exec boil.Executor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if user_entitlements exists")
+	}
+
+	return count > 0, nil
+}
+
+// UserEntitlements retrieves all the records using an executor.
+func UserEntitlements(mods ...qm.QueryMod) userEntitlementQuery {
+	mods = append(mods, qm.From("\"user_entitlements\""))
+	return userEntitlementQuery{NewQuery(mods...)}
+}
+
+// FindUserEntitlementG retrieves a single record by ID.
+func FindUserEntitlementG(userID int, entitlement string, selectCols ...string) (*UserEntitlement, error) {
+	return FindUserEntitlement(boil.GetDB(), userID, entitlement, selectCols...)
+}
+
+// FindUserEntitlement retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindUserEntitlement(exec boil.Executor, userID int, entitlement string, selectCols ...string) (*UserEntitlement, error) {
+	userEntitlementObj := &UserEntitlement{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"user_entitlements\" where \"user_id\"=$1 AND \"entitlement\"=$2", sel,
+	)
+
+	q := queries.Raw(query, userID, entitlement)
+
+	err := q.Bind(nil, exec, userEntitlementObj)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from user_entitlements")
+	}
+
+	return userEntitlementObj, nil
+}
+
+// InsertG a single record. See Insert for whitelist behavior description.
+func (o *UserEntitlement) InsertG(columns boil.Columns) error {
+	return o.Insert(boil.GetDB(), columns)
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *UserEntitlement) Insert(exec boil.Executor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_entitlements provided for insertion")
+	}
+
+	var err error
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeInsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userEntitlementColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	userEntitlementInsertCacheMut.RLock()
+	cache, cached := userEntitlementInsertCache[key]
+	userEntitlementInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			userEntitlementAllColumns,
+			userEntitlementColumnsWithDefault,
+			userEntitlementColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(userEntitlementType, userEntitlementMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(userEntitlementType, userEntitlementMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"user_entitlements\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"user_entitlements\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into user_entitlements")
+	}
+
+	if !cached {
+		userEntitlementInsertCacheMut.Lock()
+		userEntitlementInsertCache[key] = cache
+		userEntitlementInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(exec)
+}
+
+// UpdateG a single UserEntitlement record using the global executor.
+// See Update for more documentation.
+func (o *UserEntitlement) UpdateG(columns boil.Columns) (int64, error) {
+	return o.Update(boil.GetDB(), columns)
+}
+
+// Update uses an executor to update the UserEntitlement.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *UserEntitlement) Update(exec boil.Executor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	userEntitlementUpdateCacheMut.RLock()
+	cache, cached := userEntitlementUpdateCache[key]
+	userEntitlementUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			userEntitlementAllColumns,
+			userEntitlementPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update user_entitlements, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"user_entitlements\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, userEntitlementPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(userEntitlementType, userEntitlementMapping, append(wl, userEntitlementPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, values)
+	}
+
+	var result sql.Result
+	result, err = exec.Exec(cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update user_entitlements row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for user_entitlements")
+	}
+
+	if !cached {
+		userEntitlementUpdateCacheMut.Lock()
+		userEntitlementUpdateCache[key] = cache
+		userEntitlementUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(exec)
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (q userEntitlementQuery) UpdateAllG(cols M) (int64, error) {
+	return q.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q userEntitlementQuery) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for user_entitlements")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for user_entitlements")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (o UserEntitlementSlice) UpdateAllG(cols M) (int64, error) {
+	return o.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o UserEntitlementSlice) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userEntitlementPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"user_entitlements\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, userEntitlementPrimaryKeyColumns, len(o)))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in userEntitlement slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all userEntitlement")
+	}
+	return rowsAff, nil
+}
+
+// UpsertG attempts an insert, and does an update or ignore on conflict.
+func (o *UserEntitlement) UpsertG(updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	return o.Upsert(boil.GetDB(), updateOnConflict, conflictColumns, updateColumns, insertColumns)
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *UserEntitlement) Upsert(exec boil.Executor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_entitlements provided for upsert")
+	}
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeUpsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userEntitlementColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	userEntitlementUpsertCacheMut.RLock()
+	cache, cached := userEntitlementUpsertCache[key]
+	userEntitlementUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, ret := insertColumns.InsertColumnSet(
+			userEntitlementAllColumns,
+			userEntitlementColumnsWithDefault,
+			userEntitlementColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			userEntitlementAllColumns,
+			userEntitlementPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert user_entitlements, could not build update column list")
+		}
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(userEntitlementPrimaryKeyColumns))
+			copy(conflict, userEntitlementPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"user_entitlements\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(userEntitlementType, userEntitlementMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(userEntitlementType, userEntitlementMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(returns...)
+		if err == sql.ErrNoRows {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert user_entitlements")
+	}
+
+	if !cached {
+		userEntitlementUpsertCacheMut.Lock()
+		userEntitlementUpsertCache[key] = cache
+		userEntitlementUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(exec)
+}
+
+// DeleteG deletes a single UserEntitlement record.
+// DeleteG will match against the primary key column to find the record to delete.
+func (o *UserEntitlement) DeleteG() (int64, error) {
+	return o.Delete(boil.GetDB())
+}
+
+// Delete deletes a single UserEntitlement record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *UserEntitlement) Delete(exec boil.Executor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no UserEntitlement provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), userEntitlementPrimaryKeyMapping)
+	sql := "DELETE FROM \"user_entitlements\" WHERE \"user_id\"=$1 AND \"entitlement\"=$2"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from user_entitlements")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for user_entitlements")
+	}
+
+	if err := o.doAfterDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q userEntitlementQuery) DeleteAll(exec boil.Executor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no userEntitlementQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from user_entitlements")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_entitlements")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAllG deletes all rows in the slice.
+func (o UserEntitlementSlice) DeleteAllG() (int64, error) {
+	return o.DeleteAll(boil.GetDB())
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o UserEntitlementSlice) DeleteAll(exec boil.Executor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(userEntitlementBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userEntitlementPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"user_entitlements\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userEntitlementPrimaryKeyColumns, len(o))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from userEntitlement slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_entitlements")
+	}
+
+	if len(userEntitlementAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// ReloadG refetches the object from the database using the primary keys.
+func (o *UserEntitlement) ReloadG() error {
+	if o == nil {
+		return errors.New("models: no UserEntitlement provided for reload")
+	}
+
+	return o.Reload(boil.GetDB())
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *UserEntitlement) Reload(exec boil.Executor) error {
+	ret, err := FindUserEntitlement(exec, o.UserID, o.Entitlement)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAllG refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserEntitlementSlice) ReloadAllG() error {
+	if o == nil {
+		return errors.New("models: empty UserEntitlementSlice provided for reload all")
+	}
+
+	return o.ReloadAll(boil.GetDB())
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserEntitlementSlice) ReloadAll(exec boil.Executor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := UserEntitlementSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userEntitlementPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"user_entitlements\".* FROM \"user_entitlements\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userEntitlementPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(nil, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in UserEntitlementSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// UserEntitlementExistsG checks if the UserEntitlement row exists.
+func UserEntitlementExistsG(userID int, entitlement string) (bool, error) {
+	return UserEntitlementExists(boil.GetDB(), userID, entitlement)
+}
+
+// UserEntitlementExists checks if the UserEntitlement row exists.
+func UserEntitlementExists(exec boil.Executor, userID int, entitlement string) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"user_entitlements\" where \"user_id\"=$1 AND \"entitlement\"=$2 limit 1)"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, userID, entitlement)
+	}
+
+	row := exec.QueryRow(sql, userID, entitlement)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if user_entitlements exists")
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,713 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/randomize"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+var (
+	// Relationships sometimes use the reflection helper queries.Equal/queries.Assign
+	// so force a package dependency in case they don't.
+	_ = queries.Equal
+)
+
+func testUserEntitlements(t *testing.T) {
+	t.Parallel()
+
+	query := UserEntitlements()
+
+	if query.Query == nil {
+		t.Error("expected a query, got nothing")
+	}
+}
+
+func testUserEntitlementsDelete(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := o.Delete(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserEntitlementsQueryDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := UserEntitlements().DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserEntitlementsSliceDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserEntitlementSlice{o}
+
+	if rowsAff, err := slice.DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserEntitlementsExists(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	e, err := UserEntitlementExists(tx, o.UserID, o.Entitlement)
+	if err != nil {
+		t.Errorf("Unable to check if UserEntitlement exists: %s", err)
+	}
+	if !e {
+		t.Errorf("Expected UserEntitlementExists to return true, but got false.")
+	}
+}
+
+func testUserEntitlementsFind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	userEntitlementFound, err := FindUserEntitlement(tx, o.UserID, o.Entitlement)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if userEntitlementFound == nil {
+		t.Error("want a record, got nil")
+	}
+}
+
+func testUserEntitlementsBind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = UserEntitlements().Bind(nil, tx, o); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserEntitlementsOne(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if x, err := UserEntitlements().One(tx); err != nil {
+		t.Error(err)
+	} else if x == nil {
+		t.Error("expected to get a non nil record")
+	}
+}
+
+func testUserEntitlementsAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	userEntitlementOne := &UserEntitlement{}
+	userEntitlementTwo := &UserEntitlement{}
+	if err = randomize.Struct(seed, userEntitlementOne, userEntitlementDBTypes, false, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userEntitlementTwo, userEntitlementDBTypes, false, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userEntitlementOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userEntitlementTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserEntitlements().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 2 {
+		t.Error("want 2 records, got:", len(slice))
+	}
+}
+
+func testUserEntitlementsCount(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	seed := randomize.NewSeed()
+	userEntitlementOne := &UserEntitlement{}
+	userEntitlementTwo := &UserEntitlement{}
+	if err = randomize.Struct(seed, userEntitlementOne, userEntitlementDBTypes, false, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userEntitlementTwo, userEntitlementDBTypes, false, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userEntitlementOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userEntitlementTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 2 {
+		t.Error("want 2 records, got:", count)
+	}
+}
+
+func userEntitlementBeforeInsertHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementAfterInsertHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementAfterSelectHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementBeforeUpdateHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementAfterUpdateHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementBeforeDeleteHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementAfterDeleteHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementBeforeUpsertHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func userEntitlementAfterUpsertHook(e boil.Executor, o *UserEntitlement) error {
+	*o = UserEntitlement{}
+	return nil
+}
+
+func testUserEntitlementsHooks(t *testing.T) {
+	t.Parallel()
+
+	var err error
+
+	empty := &UserEntitlement{}
+	o := &UserEntitlement{}
+
+	seed := randomize.NewSeed()
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, false); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement object: %s", err)
+	}
+
+	AddUserEntitlementHook(boil.BeforeInsertHook, userEntitlementBeforeInsertHook)
+	if err = o.doBeforeInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeInsertHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementBeforeInsertHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.AfterInsertHook, userEntitlementAfterInsertHook)
+	if err = o.doAfterInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterInsertHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementAfterInsertHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.AfterSelectHook, userEntitlementAfterSelectHook)
+	if err = o.doAfterSelectHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterSelectHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterSelectHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementAfterSelectHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.BeforeUpdateHook, userEntitlementBeforeUpdateHook)
+	if err = o.doBeforeUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpdateHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementBeforeUpdateHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.AfterUpdateHook, userEntitlementAfterUpdateHook)
+	if err = o.doAfterUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpdateHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementAfterUpdateHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.BeforeDeleteHook, userEntitlementBeforeDeleteHook)
+	if err = o.doBeforeDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeDeleteHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementBeforeDeleteHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.AfterDeleteHook, userEntitlementAfterDeleteHook)
+	if err = o.doAfterDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterDeleteHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementAfterDeleteHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.BeforeUpsertHook, userEntitlementBeforeUpsertHook)
+	if err = o.doBeforeUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpsertHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementBeforeUpsertHooks = []UserEntitlementHook{}
+
+	AddUserEntitlementHook(boil.AfterUpsertHook, userEntitlementAfterUpsertHook)
+	if err = o.doAfterUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpsertHook function to empty object, but got: %#v", o)
+	}
+	userEntitlementAfterUpsertHooks = []UserEntitlementHook{}
+}
+
+func testUserEntitlementsInsert(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserEntitlementsInsertWhitelist(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Whitelist(userEntitlementColumnsWithoutDefault...)); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserEntitlementsReload(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = o.Reload(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserEntitlementsReloadAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserEntitlementSlice{o}
+
+	if err = slice.ReloadAll(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserEntitlementsSelect(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserEntitlements().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 1 {
+		t.Error("want one record, got:", len(slice))
+	}
+}
+
+var (
+	userEntitlementDBTypes = map[string]string{`UserID`: `integer`, `Entitlement`: `character varying`, `CreatedAt`: `timestamp without time zone`}
+	_                      = bytes.MinRead
+)
+
+func testUserEntitlementsUpdate(t *testing.T) {
+	t.Parallel()
+
+	if 0 == len(userEntitlementPrimaryKeyColumns) {
+		t.Skip("Skipping table with no primary key columns")
+	}
+	if len(userEntitlementAllColumns) == len(userEntitlementPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	if rowsAff, err := o.Update(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only affect one row but affected", rowsAff)
+	}
+}
+
+func testUserEntitlementsSliceUpdateAll(t *testing.T) {
+	t.Parallel()
+
+	if len(userEntitlementAllColumns) == len(userEntitlementPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserEntitlement{}
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userEntitlementDBTypes, true, userEntitlementPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	// Remove Primary keys and unique columns from what we plan to update
+	var fields []string
+	if strmangle.StringSliceMatch(userEntitlementAllColumns, userEntitlementPrimaryKeyColumns) {
+		fields = userEntitlementAllColumns
+	} else {
+		fields = strmangle.SetComplement(
+			userEntitlementAllColumns,
+			userEntitlementPrimaryKeyColumns,
+		)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	typ := reflect.TypeOf(o).Elem()
+	n := typ.NumField()
+
+	updateMap := M{}
+	for _, col := range fields {
+		for i := 0; i < n; i++ {
+			f := typ.Field(i)
+			if f.Tag.Get("boil") == col {
+				updateMap[col] = value.Field(i).Interface()
+			}
+		}
+	}
+
+	slice := UserEntitlementSlice{o}
+	if rowsAff, err := slice.UpdateAll(tx, updateMap); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("wanted one record updated but got", rowsAff)
+	}
+}
+
+func testUserEntitlementsUpsert(t *testing.T) {
+	t.Parallel()
+
+	if len(userEntitlementAllColumns) == len(userEntitlementPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	// Attempt the INSERT side of an UPSERT
+	o := UserEntitlement{}
+	if err = randomize.Struct(seed, &o, userEntitlementDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Upsert(tx, false, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserEntitlement: %s", err)
+	}
+
+	count, err := UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	// Attempt the UPDATE side of an UPSERT
+	if err = randomize.Struct(seed, &o, userEntitlementDBTypes, false, userEntitlementPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserEntitlement struct: %s", err)
+	}
+
+	if err = o.Upsert(tx, true, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserEntitlement: %s", err)
+	}
+
+	count, err = UserEntitlements().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
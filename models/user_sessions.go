@@ -0,0 +1,957 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"github.com/volatiletech/sqlboiler/queries/qmhelper"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+// UserSession is an object representing the database table.
+type UserSession struct {
+	ID         int       `boil:"id" json:"id" toml:"id" yaml:"id"`
+	UserID     int       `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	TokenHash  string    `boil:"token_hash" json:"token_hash" toml:"token_hash" yaml:"token_hash"`
+	CreatedAt  time.Time `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+	LastUsedAt time.Time `boil:"last_used_at" json:"last_used_at" toml:"last_used_at" yaml:"last_used_at"`
+	LastIP     string    `boil:"last_ip" json:"last_ip" toml:"last_ip" yaml:"last_ip"`
+	RevokedAt  null.Time `boil:"revoked_at" json:"revoked_at,omitempty" toml:"revoked_at" yaml:"revoked_at,omitempty"`
+	DeviceName string    `boil:"device_name" json:"device_name" toml:"device_name" yaml:"device_name"`
+
+	R *userSessionR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L userSessionL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var UserSessionColumns = struct {
+	ID         string
+	UserID     string
+	TokenHash  string
+	CreatedAt  string
+	LastUsedAt string
+	LastIP     string
+	RevokedAt  string
+	DeviceName string
+}{
+	ID:         "id",
+	UserID:     "user_id",
+	TokenHash:  "token_hash",
+	CreatedAt:  "created_at",
+	LastUsedAt: "last_used_at",
+	LastIP:     "last_ip",
+	RevokedAt:  "revoked_at",
+	DeviceName: "device_name",
+}
+
+// Generated where
+
+var UserSessionWhere = struct {
+	ID         whereHelperint
+	UserID     whereHelperint
+	TokenHash  whereHelperstring
+	CreatedAt  whereHelpertime_Time
+	LastUsedAt whereHelpertime_Time
+	LastIP     whereHelperstring
+	RevokedAt  whereHelpernull_Time
+	DeviceName whereHelperstring
+}{
+	ID:         whereHelperint{field: "\"user_sessions\".\"id\""},
+	UserID:     whereHelperint{field: "\"user_sessions\".\"user_id\""},
+	TokenHash:  whereHelperstring{field: "\"user_sessions\".\"token_hash\""},
+	CreatedAt:  whereHelpertime_Time{field: "\"user_sessions\".\"created_at\""},
+	LastUsedAt: whereHelpertime_Time{field: "\"user_sessions\".\"last_used_at\""},
+	LastIP:     whereHelperstring{field: "\"user_sessions\".\"last_ip\""},
+	RevokedAt:  whereHelpernull_Time{field: "\"user_sessions\".\"revoked_at\""},
+	DeviceName: whereHelperstring{field: "\"user_sessions\".\"device_name\""},
+}
+
+// UserSessionRels is where relationship names are stored.
+var UserSessionRels = struct {
+}{}
+
+// userSessionR is where relationships are stored.
+type userSessionR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*userSessionR) NewStruct() *userSessionR {
+	return &userSessionR{}
+}
+
+// userSessionL is where Load methods for each relationship are stored.
+type userSessionL struct{}
+
+var (
+	userSessionAllColumns            = []string{"id", "user_id", "token_hash", "created_at", "last_used_at", "last_ip", "revoked_at", "device_name"}
+	userSessionColumnsWithoutDefault = []string{"user_id", "token_hash", "revoked_at"}
+	userSessionColumnsWithDefault    = []string{"id", "created_at", "last_used_at", "last_ip", "device_name"}
+	userSessionPrimaryKeyColumns     = []string{"id"}
+)
+
+type (
+	// UserSessionSlice is an alias for a slice of pointers to UserSession.
+	// This should generally be used opposed to []UserSession.
+	UserSessionSlice []*UserSession
+	// UserSessionHook is the signature for custom UserSession hook methods
+	UserSessionHook func(boil.Executor, *UserSession) error
+
+	userSessionQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	userSessionType                 = reflect.TypeOf(&UserSession{})
+	userSessionMapping              = queries.MakeStructMapping(userSessionType)
+	userSessionPrimaryKeyMapping, _ = queries.BindMapping(userSessionType, userSessionMapping, userSessionPrimaryKeyColumns)
+	userSessionInsertCacheMut       sync.RWMutex
+	userSessionInsertCache          = make(map[string]insertCache)
+	userSessionUpdateCacheMut       sync.RWMutex
+	userSessionUpdateCache          = make(map[string]updateCache)
+	userSessionUpsertCacheMut       sync.RWMutex
+	userSessionUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var userSessionBeforeInsertHooks []UserSessionHook
+var userSessionBeforeUpdateHooks []UserSessionHook
+var userSessionBeforeDeleteHooks []UserSessionHook
+var userSessionBeforeUpsertHooks []UserSessionHook
+
+var userSessionAfterInsertHooks []UserSessionHook
+var userSessionAfterSelectHooks []UserSessionHook
+var userSessionAfterUpdateHooks []UserSessionHook
+var userSessionAfterDeleteHooks []UserSessionHook
+var userSessionAfterUpsertHooks []UserSessionHook
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *UserSession) doBeforeInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionBeforeInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *UserSession) doBeforeUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionBeforeUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *UserSession) doBeforeDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionBeforeDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *UserSession) doBeforeUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionBeforeUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *UserSession) doAfterInsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionAfterInsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *UserSession) doAfterSelectHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionAfterSelectHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *UserSession) doAfterUpdateHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionAfterUpdateHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *UserSession) doAfterDeleteHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionAfterDeleteHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *UserSession) doAfterUpsertHooks(exec boil.Executor) (err error) {
+	for _, hook := range userSessionAfterUpsertHooks {
+		if err := hook(exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddUserSessionHook registers your hook function for all future operations.
+func AddUserSessionHook(hookPoint boil.HookPoint, userSessionHook UserSessionHook) {
+	switch hookPoint {
+	case boil.BeforeInsertHook:
+		userSessionBeforeInsertHooks = append(userSessionBeforeInsertHooks, userSessionHook)
+	case boil.BeforeUpdateHook:
+		userSessionBeforeUpdateHooks = append(userSessionBeforeUpdateHooks, userSessionHook)
+	case boil.BeforeDeleteHook:
+		userSessionBeforeDeleteHooks = append(userSessionBeforeDeleteHooks, userSessionHook)
+	case boil.BeforeUpsertHook:
+		userSessionBeforeUpsertHooks = append(userSessionBeforeUpsertHooks, userSessionHook)
+	case boil.AfterInsertHook:
+		userSessionAfterInsertHooks = append(userSessionAfterInsertHooks, userSessionHook)
+	case boil.AfterSelectHook:
+		userSessionAfterSelectHooks = append(userSessionAfterSelectHooks, userSessionHook)
+	case boil.AfterUpdateHook:
+		userSessionAfterUpdateHooks = append(userSessionAfterUpdateHooks, userSessionHook)
+	case boil.AfterDeleteHook:
+		userSessionAfterDeleteHooks = append(userSessionAfterDeleteHooks, userSessionHook)
+	case boil.AfterUpsertHook:
+		userSessionAfterUpsertHooks = append(userSessionAfterUpsertHooks, userSessionHook)
+	}
+}
+
+// OneG returns a single userSession record from the query using the global executor.
+func (q userSessionQuery) OneG() (*UserSession, error) {
+	return q.One(boil.GetDB())
+}
+
+// One returns a single userSession record from the query.
+func (q userSessionQuery) One(exec boil.Executor) (*UserSession, error) {
+	o := &UserSession{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(nil, exec, o)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for user_sessions")
+	}
+
+	if err := o.doAfterSelectHooks(exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// AllG returns all UserSession records from the query using the global executor.
+func (q userSessionQuery) AllG() (UserSessionSlice, error) {
+	return q.All(boil.GetDB())
+}
+
+// All returns all UserSession records from the query.
+func (q userSessionQuery) All(exec boil.Executor) (UserSessionSlice, error) {
+	var o []*UserSession
+
+	err := q.Bind(nil, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to UserSession slice")
+	}
+
+	if len(userSessionAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// CountG returns the count of all UserSession records in the query, and panics on error.
+func (q userSessionQuery) CountG() (int64, error) {
+	return q.Count(boil.GetDB())
+}
+
+// Count returns the count of all UserSession records in the query.
+func (q userSessionQuery) Count(exec boil.Executor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count user_sessions rows")
+	}
+
+	return count, nil
+}
+
+// ExistsG checks if the row exists in the table, and panics on error.
+func (q userSessionQuery) ExistsG() (bool, error) {
+	return q.Exists(boil.GetDB())
+}
+
+// Exists checks if the row exists in the table.
+func (q userSessionQuery) Exists(exec boil.Executor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRow(exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if user_sessions exists")
+	}
+
+	return count > 0, nil
+}
+
+// UserSessions retrieves all the records using an executor.
+func UserSessions(mods ...qm.QueryMod) userSessionQuery {
+	mods = append(mods, qm.From("\"user_sessions\""))
+	return userSessionQuery{NewQuery(mods...)}
+}
+
+// FindUserSessionG retrieves a single record by ID.
+func FindUserSessionG(iD int, selectCols ...string) (*UserSession, error) {
+	return FindUserSession(boil.GetDB(), iD, selectCols...)
+}
+
+// FindUserSession retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindUserSession(exec boil.Executor, iD int, selectCols ...string) (*UserSession, error) {
+	userSessionObj := &UserSession{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"user_sessions\" where \"id\"=$1", sel,
+	)
+
+	q := queries.Raw(query, iD)
+
+	err := q.Bind(nil, exec, userSessionObj)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from user_sessions")
+	}
+
+	return userSessionObj, nil
+}
+
+// InsertG a single record. See Insert for whitelist behavior description.
+func (o *UserSession) InsertG(columns boil.Columns) error {
+	return o.Insert(boil.GetDB(), columns)
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *UserSession) Insert(exec boil.Executor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_sessions provided for insertion")
+	}
+
+	var err error
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeInsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userSessionColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	userSessionInsertCacheMut.RLock()
+	cache, cached := userSessionInsertCache[key]
+	userSessionInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			userSessionAllColumns,
+			userSessionColumnsWithDefault,
+			userSessionColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(userSessionType, userSessionMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(userSessionType, userSessionMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"user_sessions\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"user_sessions\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into user_sessions")
+	}
+
+	if !cached {
+		userSessionInsertCacheMut.Lock()
+		userSessionInsertCache[key] = cache
+		userSessionInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(exec)
+}
+
+// UpdateG a single UserSession record using the global executor.
+// See Update for more documentation.
+func (o *UserSession) UpdateG(columns boil.Columns) (int64, error) {
+	return o.Update(boil.GetDB(), columns)
+}
+
+// Update uses an executor to update the UserSession.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *UserSession) Update(exec boil.Executor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	userSessionUpdateCacheMut.RLock()
+	cache, cached := userSessionUpdateCache[key]
+	userSessionUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			userSessionAllColumns,
+			userSessionPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update user_sessions, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"user_sessions\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, userSessionPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(userSessionType, userSessionMapping, append(wl, userSessionPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, values)
+	}
+
+	var result sql.Result
+	result, err = exec.Exec(cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update user_sessions row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for user_sessions")
+	}
+
+	if !cached {
+		userSessionUpdateCacheMut.Lock()
+		userSessionUpdateCache[key] = cache
+		userSessionUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(exec)
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (q userSessionQuery) UpdateAllG(cols M) (int64, error) {
+	return q.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q userSessionQuery) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for user_sessions")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for user_sessions")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAllG updates all rows with the specified column values.
+func (o UserSessionSlice) UpdateAllG(cols M) (int64, error) {
+	return o.UpdateAll(boil.GetDB(), cols)
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o UserSessionSlice) UpdateAll(exec boil.Executor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userSessionPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"user_sessions\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, userSessionPrimaryKeyColumns, len(o)))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in userSession slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all userSession")
+	}
+	return rowsAff, nil
+}
+
+// UpsertG attempts an insert, and does an update or ignore on conflict.
+func (o *UserSession) UpsertG(updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	return o.Upsert(boil.GetDB(), updateOnConflict, conflictColumns, updateColumns, insertColumns)
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *UserSession) Upsert(exec boil.Executor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no user_sessions provided for upsert")
+	}
+	currTime := time.Now().In(boil.GetLocation())
+
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = currTime
+	}
+
+	if err := o.doBeforeUpsertHooks(exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(userSessionColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	userSessionUpsertCacheMut.RLock()
+	cache, cached := userSessionUpsertCache[key]
+	userSessionUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, ret := insertColumns.InsertColumnSet(
+			userSessionAllColumns,
+			userSessionColumnsWithDefault,
+			userSessionColumnsWithoutDefault,
+			nzDefaults,
+		)
+		update := updateColumns.UpdateColumnSet(
+			userSessionAllColumns,
+			userSessionPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("models: unable to upsert user_sessions, could not build update column list")
+		}
+
+		conflict := conflictColumns
+		if len(conflict) == 0 {
+			conflict = make([]string, len(userSessionPrimaryKeyColumns))
+			copy(conflict, userSessionPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"user_sessions\"", updateOnConflict, ret, update, conflict, insert)
+
+		cache.valueMapping, err = queries.BindMapping(userSessionType, userSessionMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(userSessionType, userSessionMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, cache.query)
+		fmt.Fprintln(boil.DebugWriter, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRow(cache.query, vals...).Scan(returns...)
+		if err == sql.ErrNoRows {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.Exec(cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert user_sessions")
+	}
+
+	if !cached {
+		userSessionUpsertCacheMut.Lock()
+		userSessionUpsertCache[key] = cache
+		userSessionUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(exec)
+}
+
+// DeleteG deletes a single UserSession record.
+// DeleteG will match against the primary key column to find the record to delete.
+func (o *UserSession) DeleteG() (int64, error) {
+	return o.Delete(boil.GetDB())
+}
+
+// Delete deletes a single UserSession record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *UserSession) Delete(exec boil.Executor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no UserSession provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), userSessionPrimaryKeyMapping)
+	sql := "DELETE FROM \"user_sessions\" WHERE \"id\"=$1"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args...)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from user_sessions")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for user_sessions")
+	}
+
+	if err := o.doAfterDeleteHooks(exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q userSessionQuery) DeleteAll(exec boil.Executor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no userSessionQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.Exec(exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from user_sessions")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_sessions")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAllG deletes all rows in the slice.
+func (o UserSessionSlice) DeleteAllG() (int64, error) {
+	return o.DeleteAll(boil.GetDB())
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o UserSessionSlice) DeleteAll(exec boil.Executor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(userSessionBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userSessionPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"user_sessions\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userSessionPrimaryKeyColumns, len(o))
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, args)
+	}
+
+	result, err := exec.Exec(sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from userSession slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for user_sessions")
+	}
+
+	if len(userSessionAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// ReloadG refetches the object from the database using the primary keys.
+func (o *UserSession) ReloadG() error {
+	if o == nil {
+		return errors.New("models: no UserSession provided for reload")
+	}
+
+	return o.Reload(boil.GetDB())
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *UserSession) Reload(exec boil.Executor) error {
+	ret, err := FindUserSession(exec, o.ID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAllG refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserSessionSlice) ReloadAllG() error {
+	if o == nil {
+		return errors.New("models: empty UserSessionSlice provided for reload all")
+	}
+
+	return o.ReloadAll(boil.GetDB())
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *UserSessionSlice) ReloadAll(exec boil.Executor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := UserSessionSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), userSessionPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"user_sessions\".* FROM \"user_sessions\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, userSessionPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(nil, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in UserSessionSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// UserSessionExistsG checks if the UserSession row exists.
+func UserSessionExistsG(iD int) (bool, error) {
+	return UserSessionExists(boil.GetDB(), iD)
+}
+
+// UserSessionExists checks if the UserSession row exists.
+func UserSessionExists(exec boil.Executor, iD int) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"user_sessions\" where \"id\"=$1 limit 1)"
+
+	if boil.DebugMode {
+		fmt.Fprintln(boil.DebugWriter, sql)
+		fmt.Fprintln(boil.DebugWriter, iD)
+	}
+
+	row := exec.QueryRow(sql, iD)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if user_sessions exists")
+	}
+
+	return exists, nil
+}
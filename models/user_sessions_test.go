@@ -0,0 +1,713 @@
+// Code generated by SQLBoiler (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/randomize"
+	"github.com/volatiletech/sqlboiler/strmangle"
+)
+
+var (
+	// Relationships sometimes use the reflection helper queries.Equal/queries.Assign
+	// so force a package dependency in case they don't.
+	_ = queries.Equal
+)
+
+func testUserSessions(t *testing.T) {
+	t.Parallel()
+
+	query := UserSessions()
+
+	if query.Query == nil {
+		t.Error("expected a query, got nothing")
+	}
+}
+
+func testUserSessionsDelete(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := o.Delete(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserSessionsQueryDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if rowsAff, err := UserSessions().DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserSessionsSliceDeleteAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserSessionSlice{o}
+
+	if rowsAff, err := slice.DeleteAll(tx); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only have deleted one row, but affected:", rowsAff)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 0 {
+		t.Error("want zero records, got:", count)
+	}
+}
+
+func testUserSessionsExists(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	e, err := UserSessionExists(tx, o.ID)
+	if err != nil {
+		t.Errorf("Unable to check if UserSession exists: %s", err)
+	}
+	if !e {
+		t.Errorf("Expected UserSessionExists to return true, but got false.")
+	}
+}
+
+func testUserSessionsFind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	userSessionFound, err := FindUserSession(tx, o.ID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if userSessionFound == nil {
+		t.Error("want a record, got nil")
+	}
+}
+
+func testUserSessionsBind(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = UserSessions().Bind(nil, tx, o); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserSessionsOne(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if x, err := UserSessions().One(tx); err != nil {
+		t.Error(err)
+	} else if x == nil {
+		t.Error("expected to get a non nil record")
+	}
+}
+
+func testUserSessionsAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	userSessionOne := &UserSession{}
+	userSessionTwo := &UserSession{}
+	if err = randomize.Struct(seed, userSessionOne, userSessionDBTypes, false, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userSessionTwo, userSessionDBTypes, false, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userSessionOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userSessionTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserSessions().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 2 {
+		t.Error("want 2 records, got:", len(slice))
+	}
+}
+
+func testUserSessionsCount(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	seed := randomize.NewSeed()
+	userSessionOne := &UserSession{}
+	userSessionTwo := &UserSession{}
+	if err = randomize.Struct(seed, userSessionOne, userSessionDBTypes, false, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+	if err = randomize.Struct(seed, userSessionTwo, userSessionDBTypes, false, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = userSessionOne.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+	if err = userSessionTwo.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 2 {
+		t.Error("want 2 records, got:", count)
+	}
+}
+
+func userSessionBeforeInsertHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionAfterInsertHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionAfterSelectHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionBeforeUpdateHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionAfterUpdateHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionBeforeDeleteHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionAfterDeleteHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionBeforeUpsertHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func userSessionAfterUpsertHook(e boil.Executor, o *UserSession) error {
+	*o = UserSession{}
+	return nil
+}
+
+func testUserSessionsHooks(t *testing.T) {
+	t.Parallel()
+
+	var err error
+
+	empty := &UserSession{}
+	o := &UserSession{}
+
+	seed := randomize.NewSeed()
+	if err = randomize.Struct(seed, o, userSessionDBTypes, false); err != nil {
+		t.Errorf("Unable to randomize UserSession object: %s", err)
+	}
+
+	AddUserSessionHook(boil.BeforeInsertHook, userSessionBeforeInsertHook)
+	if err = o.doBeforeInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeInsertHook function to empty object, but got: %#v", o)
+	}
+	userSessionBeforeInsertHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.AfterInsertHook, userSessionAfterInsertHook)
+	if err = o.doAfterInsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterInsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterInsertHook function to empty object, but got: %#v", o)
+	}
+	userSessionAfterInsertHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.AfterSelectHook, userSessionAfterSelectHook)
+	if err = o.doAfterSelectHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterSelectHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterSelectHook function to empty object, but got: %#v", o)
+	}
+	userSessionAfterSelectHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.BeforeUpdateHook, userSessionBeforeUpdateHook)
+	if err = o.doBeforeUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpdateHook function to empty object, but got: %#v", o)
+	}
+	userSessionBeforeUpdateHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.AfterUpdateHook, userSessionAfterUpdateHook)
+	if err = o.doAfterUpdateHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpdateHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpdateHook function to empty object, but got: %#v", o)
+	}
+	userSessionAfterUpdateHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.BeforeDeleteHook, userSessionBeforeDeleteHook)
+	if err = o.doBeforeDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeDeleteHook function to empty object, but got: %#v", o)
+	}
+	userSessionBeforeDeleteHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.AfterDeleteHook, userSessionAfterDeleteHook)
+	if err = o.doAfterDeleteHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterDeleteHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterDeleteHook function to empty object, but got: %#v", o)
+	}
+	userSessionAfterDeleteHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.BeforeUpsertHook, userSessionBeforeUpsertHook)
+	if err = o.doBeforeUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doBeforeUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected BeforeUpsertHook function to empty object, but got: %#v", o)
+	}
+	userSessionBeforeUpsertHooks = []UserSessionHook{}
+
+	AddUserSessionHook(boil.AfterUpsertHook, userSessionAfterUpsertHook)
+	if err = o.doAfterUpsertHooks(nil); err != nil {
+		t.Errorf("Unable to execute doAfterUpsertHooks: %s", err)
+	}
+	if !reflect.DeepEqual(o, empty) {
+		t.Errorf("Expected AfterUpsertHook function to empty object, but got: %#v", o)
+	}
+	userSessionAfterUpsertHooks = []UserSessionHook{}
+}
+
+func testUserSessionsInsert(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserSessionsInsertWhitelist(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Whitelist(userSessionColumnsWithoutDefault...)); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
+
+func testUserSessionsReload(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	if err = o.Reload(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserSessionsReloadAll(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice := UserSessionSlice{o}
+
+	if err = slice.ReloadAll(tx); err != nil {
+		t.Error(err)
+	}
+}
+
+func testUserSessionsSelect(t *testing.T) {
+	t.Parallel()
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	slice, err := UserSessions().All(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(slice) != 1 {
+		t.Error("want one record, got:", len(slice))
+	}
+}
+
+var (
+	userSessionDBTypes = map[string]string{`ID`: `integer`, `UserID`: `integer`, `TokenHash`: `character varying`, `CreatedAt`: `timestamp without time zone`, `LastUsedAt`: `timestamp without time zone`, `LastIP`: `character varying`, `RevokedAt`: `timestamp without time zone`, `DeviceName`: `character varying`}
+	_                  = bytes.MinRead
+)
+
+func testUserSessionsUpdate(t *testing.T) {
+	t.Parallel()
+
+	if 0 == len(userSessionPrimaryKeyColumns) {
+		t.Skip("Skipping table with no primary key columns")
+	}
+	if len(userSessionAllColumns) == len(userSessionPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	if rowsAff, err := o.Update(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("should only affect one row but affected", rowsAff)
+	}
+}
+
+func testUserSessionsSliceUpdateAll(t *testing.T) {
+	t.Parallel()
+
+	if len(userSessionAllColumns) == len(userSessionPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	o := &UserSession{}
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionColumnsWithDefault...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Insert(tx, boil.Infer()); err != nil {
+		t.Error(err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	if err = randomize.Struct(seed, o, userSessionDBTypes, true, userSessionPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	// Remove Primary keys and unique columns from what we plan to update
+	var fields []string
+	if strmangle.StringSliceMatch(userSessionAllColumns, userSessionPrimaryKeyColumns) {
+		fields = userSessionAllColumns
+	} else {
+		fields = strmangle.SetComplement(
+			userSessionAllColumns,
+			userSessionPrimaryKeyColumns,
+		)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	typ := reflect.TypeOf(o).Elem()
+	n := typ.NumField()
+
+	updateMap := M{}
+	for _, col := range fields {
+		for i := 0; i < n; i++ {
+			f := typ.Field(i)
+			if f.Tag.Get("boil") == col {
+				updateMap[col] = value.Field(i).Interface()
+			}
+		}
+	}
+
+	slice := UserSessionSlice{o}
+	if rowsAff, err := slice.UpdateAll(tx, updateMap); err != nil {
+		t.Error(err)
+	} else if rowsAff != 1 {
+		t.Error("wanted one record updated but got", rowsAff)
+	}
+}
+
+func testUserSessionsUpsert(t *testing.T) {
+	t.Parallel()
+
+	if len(userSessionAllColumns) == len(userSessionPrimaryKeyColumns) {
+		t.Skip("Skipping table with only primary key columns")
+	}
+
+	seed := randomize.NewSeed()
+	var err error
+	// Attempt the INSERT side of an UPSERT
+	o := UserSession{}
+	if err = randomize.Struct(seed, &o, userSessionDBTypes, true); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	tx := MustTx(boil.Begin())
+	defer func() { _ = tx.Rollback() }()
+	if err = o.Upsert(tx, false, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserSession: %s", err)
+	}
+
+	count, err := UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+
+	// Attempt the UPDATE side of an UPSERT
+	if err = randomize.Struct(seed, &o, userSessionDBTypes, false, userSessionPrimaryKeyColumns...); err != nil {
+		t.Errorf("Unable to randomize UserSession struct: %s", err)
+	}
+
+	if err = o.Upsert(tx, true, nil, boil.Infer(), boil.Infer()); err != nil {
+		t.Errorf("Unable to upsert UserSession: %s", err)
+	}
+
+	count, err = UserSessions().Count(tx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 1 {
+		t.Error("want one record, got:", count)
+	}
+}
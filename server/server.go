@@ -5,11 +5,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/lbryio/lbrytv/api"
+	"github.com/lbryio/lbrytv/app/query/cache"
 	"github.com/lbryio/lbrytv/app/sdkrouter"
+	"github.com/lbryio/lbrytv/apps/lbrytv/config"
+	"github.com/lbryio/lbrytv/internal/cachepins"
 	"github.com/lbryio/lbrytv/internal/monitor"
 
 	"github.com/gorilla/mux"
@@ -23,12 +27,17 @@ type Server struct {
 	listener *http.Server
 	stopChan chan os.Signal
 	stopWait time.Duration
+
+	sdkRouter     *sdkrouter.Router
+	queryCache    *cache.Cache
+	stopSnapshots chan struct{}
+	shutdownOnce  sync.Once
 }
 
 // NewServer returns a server initialized with settings from supplied options.
 func NewServer(address string, sdkRouter *sdkrouter.Router) *Server {
 	r := mux.NewRouter()
-	api.InstallRoutes(r, sdkRouter)
+	queryCache := api.InstallRoutes(r, sdkRouter)
 	r.Use(monitor.ErrorLoggingMiddleware)
 	r.Use(defaultHeadersMiddleware(map[string]string{
 		"Server":                       "api.lbry.tv",
@@ -36,19 +45,28 @@ func NewServer(address string, sdkRouter *sdkrouter.Router) *Server {
 		"Access-Control-Allow-Headers": "content-type", // Needed this to get any request to work
 	}))
 
+	timeouts := config.GetHTTPServer()
+	if timeouts.WriteTimeout > 0 && timeouts.WriteTimeout <= sdkrouter.RPCTimeout {
+		logger.Log().Warnf("http server write timeout %v is shorter than sdk rpc timeout %v, slow sdk calls will be cut off", timeouts.WriteTimeout, sdkrouter.RPCTimeout)
+	}
+
 	return &Server{
 		address:  address,
 		stopWait: 15 * time.Second,
 		stopChan: make(chan os.Signal),
+
+		sdkRouter:     sdkRouter,
+		queryCache:    queryCache,
+		stopSnapshots: make(chan struct{}),
 		listener: &http.Server{
 			Addr:    address,
 			Handler: r,
-			// We need this for long uploads
-			WriteTimeout: 0,
-			// prev WriteTimeout was (sdkrouter.RPCTimeout + (1 * time.Second)).
-			// It must be longer than rpc timeout to allow those timeouts to be handled
-			IdleTimeout:       0,
-			ReadHeaderTimeout: 10 * time.Second,
+			// Read and write timeouts are disabled by default for long uploads,
+			// write timeout must be longer than rpc timeout to allow those timeouts to be handled
+			ReadTimeout:       timeouts.ReadTimeout,
+			WriteTimeout:      timeouts.WriteTimeout,
+			IdleTimeout:       timeouts.IdleTimeout,
+			ReadHeaderTimeout: timeouts.ReadHeaderTimeout,
 		},
 	}
 }
@@ -79,6 +97,10 @@ func (s *Server) Start() error {
 		}
 	}()
 	logger.Log().Infof("http server listening on %v", s.listener.Addr)
+	if path := config.GetCacheSnapshotPath(); path != "" {
+		go s.queryCache.RunSnapshots(path, config.GetCacheSnapshotInterval(), s.stopSnapshots)
+	}
+	go cachepins.New(s.queryCache, s.sdkRouter).Run(config.GetCachePinsRefreshInterval())
 	return nil
 }
 
@@ -97,10 +119,23 @@ func (s *Server) ServeUntilShutdown() {
 	}
 }
 
-// Shutdown gracefully shuts down the peer server.
+// Shutdown gracefully shuts down the peer server, saving the query cache snapshot if snapshots are on.
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.stopWait)
 	defer cancel()
 	err := s.listener.Shutdown(ctx)
+	s.shutdownOnce.Do(func() {
+		close(s.stopSnapshots)
+		path := config.GetCacheSnapshotPath()
+		if path == "" {
+			return
+		}
+		n, err := s.queryCache.SaveSnapshot(path)
+		if err != nil {
+			logger.Log().Errorf("cannot save cache snapshot to %v: %v", path, err)
+			return
+		}
+		logger.Log().Infof("saved %v cache entries to %v", n, path)
+	})
 	return err
 }
@@ -97,3 +97,16 @@ func TestHeaders(t *testing.T) {
 
 	server.stopChan <- syscall.SIGINT
 }
+
+func TestTimeouts(t *testing.T) {
+	config.Config.Override("HTTPServer", map[string]string{
+		"ReadTimeout": "1h", "ReadHeaderTimeout": "3s", "WriteTimeout": "1h", "IdleTimeout": "30s",
+	})
+	defer config.Config.RestoreOverridden()
+
+	server := randomServer(sdkrouter.New(config.GetLbrynetServers()))
+	assert.Equal(t, time.Hour, server.listener.ReadTimeout)
+	assert.Equal(t, 3*time.Second, server.listener.ReadHeaderTimeout)
+	assert.Equal(t, time.Hour, server.listener.WriteTimeout)
+	assert.Equal(t, 30*time.Second, server.listener.IdleTimeout)
+}